@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/flags"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/server"
+	"tower-defense/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reloadConfig re-reads the game configuration and applies it to running games
+func reloadConfig(reload func() error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := reload(); err != nil {
+			logging.Errorw("config_reload_failed", "error", err)
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Config reloaded"})
+	}
+}
+
+// lookupGame resolves the :id route parameter, writing a 404 when the game does not exist
+func lookupGame(c *gin.Context, manager *game.Manager) (*game.Game, bool) {
+	g, err := manager.GetGame(c.Param("id"))
+	if err != nil {
+		api.Fail(c, err)
+		return nil, false
+	}
+	return g, true
+}
+
+// adminEndGame force-ends a running game
+func adminEndGame(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		if !g.ForceEnd("admin") {
+			api.Respond(c, http.StatusConflict, api.NewError(api.CodeConflict, "game is already over"))
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Game ended"})
+	}
+}
+
+// adminAdjustResources adds gold/lives deltas to a running game
+func adminAdjustResources(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.AdjustResourcesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, api.AdjustResourcesResponse{Success: true, State: g.AdjustResources(req.Gold, req.Lives)})
+	}
+}
+
+// adminDumpWorld returns the raw simulation state of a game
+func adminDumpWorld(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		data, err := g.SaveSimulation()
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, json.RawMessage(data))
+	}
+}
+
+// adminListCrashes returns the crash reports of a game, including games that
+// have since been removed
+func adminListCrashes(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		saves, err := repo.List(c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		crashes := make([]game.CrashReport, 0, len(saves))
+		for _, s := range saves {
+			var report game.CrashReport
+			if err := json.Unmarshal(s.Data, &report); err != nil {
+				api.Fail(c, err)
+				return
+			}
+			crashes = append(crashes, report)
+		}
+		c.JSON(http.StatusOK, api.CrashListResponse{Crashes: crashes})
+	}
+}
+
+// adminSaveStats returns the size of the save repository and its retention counters
+func adminSaveStats(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := repo.GetStats()
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// adminMemory returns the approximate memory held by each room
+func adminMemory(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.MemoryUsage())
+	}
+}
+
+// adminSetVerbose toggles verbose logging for a game
+func adminSetVerbose(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.SetVerboseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		g.SetVerbose(req.Enabled)
+		c.JSON(http.StatusOK, api.SetVerboseResponse{Success: true, Verbose: req.Enabled})
+	}
+}
+
+// adminListClients lists connected WebSocket clients
+func adminListClients(hub *server.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, api.ClientListResponse{Clients: hub.Clients()})
+	}
+}
+
+// adminListConnections lists the WebSocket connections with their metadata
+func adminListConnections(hub *server.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, api.ConnectionListResponse{Connections: hub.Connections(c.Query("gameId"))})
+	}
+}
+
+// adminKickClient disconnects a WebSocket client
+func adminKickClient(hub *server.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hub.Kick(c.Param("id")) {
+			api.Respond(c, http.StatusNotFound, api.NewError(api.CodeNotFound, "client not found"))
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Client kicked"})
+	}
+}
+
+// defaultAuditLimit is the number of audit entries returned without ?limit
+const defaultAuditLimit = 100
+
+// adminAuditLog returns the recent state-mutating requests, filtered by game,
+// player and time
+func adminAuditLog(log *server.AuditLog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := server.AuditFilter{GameID: c.Query("gameId"), PlayerID: c.Query("playerId"), Limit: defaultAuditLimit}
+		if v := c.Query("since"); v != "" {
+			since, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				api.BadRequest(c, fmt.Errorf("since must be an RFC 3339 time"))
+				return
+			}
+			filter.Since = since
+		}
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				api.BadRequest(c, fmt.Errorf("limit must be a positive integer"))
+				return
+			}
+			filter.Limit = n
+		}
+		c.JSON(http.StatusOK, api.AuditLogResponse{Entries: log.Query(filter)})
+	}
+}
+
+// adminListFlags lists the feature flags
+func adminListFlags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, api.FlagListResponse{Flags: flags.All()})
+	}
+}
+
+// adminSetFlag switches a feature flag on or off
+func adminSetFlag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.SetFlagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		state, err := flags.Set(c.Param("name"), req.Enabled)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
+
+// adminWebhookDeadLetters lists the webhook deliveries that gave up
+func adminWebhookDeadLetters(notifier *webhook.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, api.DeadLetterListResponse{DeadLetters: notifier.DeadLetters()})
+	}
+}
+
+// adminGetBalance returns the effective balance of new rooms, or of the
+// running room named by gameId, with its tuning history
+func adminGetBalance(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondBalance(c, manager, c.Query("gameId"))
+	}
+}
+
+// adminPatchBalance tunes the balance of new rooms, or of the running room
+// named by gameId, and records who changed it
+func adminPatchBalance(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.BalancePatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		change, err := manager.TuneBalance(game.BalanceChange{
+			GameID:    c.Query("gameId"),
+			Overrides: req,
+			RequestID: c.Writer.Header().Get("X-Request-ID"),
+			ClientIP:  c.ClientIP(),
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		logging.Infow("admin_balance_patched", "game_id", change.GameID, "change_id", change.ID, "ip", change.ClientIP)
+		respondBalance(c, manager, change.GameID)
+	}
+}
+
+// respondBalance writes the balance of new rooms or of a running room
+func respondBalance(c *gin.Context, manager *game.Manager, gameID string) {
+	cfg, overrides, history, err := manager.Balance(gameID)
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	tree, err := cfg.Tree()
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, api.BalanceResponse{GameID: gameID, Config: tree, Overrides: overrides, History: history})
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getHeatmap returns where enemies died, leaked and took damage in a game
+func getHeatmap(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, g.Heatmap())
+	}
+}
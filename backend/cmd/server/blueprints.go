@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listBlueprints returns a game's queued tower placements in build order
+func listBlueprints(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		blueprints := g.Blueprints()
+		if blueprints == nil {
+			blueprints = []game.Blueprint{}
+		}
+		c.JSON(http.StatusOK, api.BlueprintListResponse{Blueprints: blueprints})
+	}
+}
+
+// queueBlueprint queues a tower that is built once the game's gold covers it
+func queueBlueprint(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.AddTowerRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		towerType := req.TowerType
+		if towerType == "" {
+			towerType = "basic"
+		}
+		bp, built, err := g.QueueBlueprint(server.PlayerID(c), towerType, req.X, req.Y)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.QueueBlueprintResponse{Blueprint: bp, Built: built})
+	}
+}
+
+// cancelBlueprint takes a blueprint off a game's queue
+func cancelBlueprint(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		if err := g.CancelBlueprint(server.PlayerID(c), c.Param("blueprintId")); err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true})
+	}
+}
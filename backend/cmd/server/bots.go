@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game/bot"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addBot attaches a bot player to a game; the body is optional
+func addBot(bots *bot.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.AddBotRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			api.BadRequest(c, err)
+			return
+		}
+		p, err := bots.Attach(c.Param("id"), bot.Options{
+			PlayerID: req.PlayerID,
+			Strategy: req.Strategy,
+			Interval: time.Duration(req.IntervalMs) * time.Millisecond,
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.BotResponse{Success: true, Bot: p.Info()})
+	}
+}
+
+// listBots lists the bots playing in a game
+func listBots(bots *bot.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, api.BotListResponse{Bots: bots.List(c.Param("id"))})
+	}
+}
+
+// removeBot stops a bot
+func removeBot(bots *bot.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !bots.Detach(c.Param("id"), c.Param("playerId")) {
+			api.Respond(c, http.StatusNotFound, api.NewError(api.CodeNotFound, "bot not found"))
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Bot removed"})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/server"
+)
+
+// Minimum time between two state broadcasts of a room. Overloaded rooms are
+// broadcast on every other tick of the 100ms broadcast ticker.
+const (
+	broadcastGap         = 50 * time.Millisecond
+	degradedBroadcastGap = 150 * time.Millisecond
+)
+
+// broadcastPacer spaces out the state broadcasts of each room and, when
+// adaptive, slows down the rooms whose game is overloaded until it recovers.
+// It is only used by the broadcaster goroutine.
+type broadcastPacer struct {
+	adaptive bool
+	last     map[string]time.Time
+	degraded map[string]bool
+}
+
+func newBroadcastPacer(adaptive bool) *broadcastPacer {
+	return &broadcastPacer{adaptive: adaptive, last: make(map[string]time.Time), degraded: make(map[string]bool)}
+}
+
+// due reports whether the state of g should be broadcast now, switching the
+// room in or out of degraded broadcasting as its load changes
+func (p *broadcastPacer) due(g *game.Game, now time.Time) bool {
+	id := g.GetID()
+	overloaded := p.adaptive && g.Overloaded()
+	if overloaded != p.degraded[id] {
+		p.setDegraded(id, overloaded)
+	}
+	gap := broadcastGap
+	if overloaded {
+		gap = degradedBroadcastGap
+	}
+	return now.Sub(p.last[id]) >= gap
+}
+
+// sent records a broadcast of the room
+func (p *broadcastPacer) sent(gameID string, now time.Time) {
+	p.last[gameID] = now
+}
+
+// retain forgets the rooms not in gameIDs, e.g. once their last client left
+func (p *broadcastPacer) retain(gameIDs []string) {
+	keep := make(map[string]bool, len(gameIDs))
+	for _, id := range gameIDs {
+		keep[id] = true
+	}
+	for id := range p.last {
+		if !keep[id] {
+			delete(p.last, id)
+		}
+	}
+	for id := range p.degraded {
+		if !keep[id] {
+			p.setDegraded(id, false)
+		}
+	}
+}
+
+func (p *broadcastPacer) setDegraded(gameID string, degraded bool) {
+	if degraded {
+		p.degraded[gameID] = true
+		server.BroadcastDegradedRooms.Inc()
+		server.BroadcastDegradations.Inc()
+		logging.Warnw("broadcast_degraded", "game_id", gameID, "gap_ms", degradedBroadcastGap.Milliseconds())
+		return
+	}
+	delete(p.degraded, gameID)
+	server.BroadcastDegradedRooms.Dec()
+	logging.Infow("broadcast_restored", "game_id", gameID, "gap_ms", broadcastGap.Milliseconds())
+}
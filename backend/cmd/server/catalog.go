@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getCatalog returns the tower and enemy definitions, map and placement rules of the default game
+func getCatalog(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.GetOrCreateDefault().Catalog())
+	}
+}
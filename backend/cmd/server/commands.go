@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/cluster"
+	"tower-defense/internal/game"
+	"tower-defense/internal/server"
+)
+
+// forwardTimeout bounds how long a command forwarded to the owning node may take
+const forwardTimeout = 3 * time.Second
+
+// wsCommands handles game commands sent over WebSocket. Every command carrying a
+// requestId is answered with an ack or a nack so clients can render optimistically.
+// When node is set, commands for games owned by another instance are forwarded there.
+// Commands that reach the game, or are refused as too fast, are added to audit.
+func wsCommands(hub *server.Hub, manager *game.Manager, guard *server.CommandGuard, node *cluster.Node, audit *server.AuditLog) server.CommandHandler {
+	return func(c *server.Client, msg server.InboundMessage) {
+		start := time.Now()
+		if msg.Type != server.CmdPlaceTower && msg.Type != server.CmdPlaceWall && msg.Type != server.CmdReady && msg.Type != server.CmdTransfer {
+			hub.Send(c, server.MsgError, server.ErrorPayload{Code: server.ErrCodeUnknownCommand, Message: "unknown message type: " + msg.Type, RequestID: msg.RequestID})
+			return
+		}
+
+		client := wsClientKey(c)
+		if !guard.Check(client) {
+			audit.RecordCommand(c, msg, start, server.ErrCodeTooFast)
+			hub.Send(c, server.MsgNack, server.NackPayload{RequestID: msg.RequestID, Command: msg.Type, Code: server.ErrCodeTooFast, Message: "commands sent too fast"})
+			return
+		}
+
+		key, err := server.ScopedIdempotencyKey(client, msg.CommandID)
+		if err != nil {
+			hub.Send(c, server.MsgError, server.ErrorPayload{Code: server.ErrCodeBadRequest, Message: err.Error(), RequestID: msg.RequestID})
+			return
+		}
+
+		cmd := cluster.Command{GameID: c.GameID(), Type: msg.Type, PlayerID: c.PlayerID(), Payload: msg.Payload, IdempotencyKey: key, Client: client, Seq: msg.Seq}
+		var result cluster.CommandResult
+		if node != nil && !node.Owns(cmd.GameID) {
+			ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+			var err error
+			result, err = node.Forward(ctx, cmd)
+			cancel()
+			if err != nil {
+				result = cluster.CommandResult{Code: server.ErrCodeUnavailable, Message: err.Error()}
+			}
+		} else {
+			result = applyCommand(manager, cmd)
+		}
+		audit.RecordCommand(c, msg, start, result.Code)
+
+		var seq server.CommandSeq
+		if msg.Seq > 0 {
+			seq = server.CommandSeq{Seq: msg.Seq, Client: client, Tick: result.Tick}
+		}
+		switch {
+		case result.OK:
+			if msg.RequestID != "" || msg.Seq > 0 {
+				hub.Send(c, server.MsgAck, server.AckPayload{RequestID: msg.RequestID, Command: msg.Type, CommandSeq: seq})
+			}
+		case result.Code == server.ErrCodeBadRequest:
+			hub.Send(c, server.MsgError, server.ErrorPayload{Code: result.Code, Message: result.Message, RequestID: msg.RequestID, CommandSeq: seq})
+		default:
+			if result.Code == server.ErrCodeInvalidCoordinates || result.Code == server.ErrCodeOutOfBounds {
+				guard.Violation(client, server.ViolationInvalidInput, "payload", string(msg.Payload))
+			}
+			hub.Send(c, server.MsgNack, server.NackPayload{RequestID: msg.RequestID, Command: msg.Type, Code: result.Code, Message: result.Message, CommandSeq: seq})
+		}
+	}
+}
+
+// applyCommand executes a game command against a game owned by this instance.
+// A sequenced command is recorded as processed, accepted or not, so the
+// game's snapshots tell its client which predictions they already include.
+func applyCommand(manager *game.Manager, cmd cluster.Command) cluster.CommandResult {
+	result := runCommand(manager, cmd)
+	if cmd.Seq > 0 {
+		if g, err := manager.GetGame(cmd.GameID); err == nil {
+			result.Tick = g.CommandProcessed(cmd.Client, cmd.Seq)
+		}
+	}
+	return result
+}
+
+// runCommand dispatches a game command to the game it is for
+func runCommand(manager *game.Manager, cmd cluster.Command) cluster.CommandResult {
+	switch cmd.Type {
+	case server.CmdPlaceTower:
+		var req server.PlaceTowerPayload
+		if err := json.Unmarshal(cmd.Payload, &req); err != nil {
+			return cluster.CommandResult{Code: server.ErrCodeBadRequest, Message: err.Error()}
+		}
+		if req.TowerType == "" {
+			req.TowerType = "basic"
+		}
+		g, err := manager.GetGame(cmd.GameID)
+		if err == nil {
+			_, err = g.Idempotent(cmd.IdempotencyKey, func() error {
+				return g.AddTowerForPlayer(cmd.PlayerID, req.TowerType, req.X, req.Y)
+			})
+		}
+		if err != nil {
+			return cluster.CommandResult{Code: commandErrorCode(err), Message: err.Error()}
+		}
+		return cluster.CommandResult{OK: true}
+
+	case server.CmdPlaceWall:
+		var req server.PlaceWallPayload
+		if err := json.Unmarshal(cmd.Payload, &req); err != nil {
+			return cluster.CommandResult{Code: server.ErrCodeBadRequest, Message: err.Error()}
+		}
+		g, err := manager.GetGame(cmd.GameID)
+		if err == nil {
+			_, err = g.Idempotent(cmd.IdempotencyKey, func() error {
+				return g.AddWallForPlayer(cmd.PlayerID, req.X, req.Y)
+			})
+		}
+		if err != nil {
+			return cluster.CommandResult{Code: commandErrorCode(err), Message: err.Error()}
+		}
+		return cluster.CommandResult{OK: true}
+
+	case server.CmdReady:
+		var req server.ReadyPayload
+		if err := json.Unmarshal(cmd.Payload, &req); err != nil {
+			return cluster.CommandResult{Code: server.ErrCodeBadRequest, Message: err.Error()}
+		}
+		g, err := manager.GetGame(cmd.GameID)
+		if err == nil {
+			_, err = g.SetReady(cmd.PlayerID, req.Ready)
+		}
+		if err != nil {
+			return cluster.CommandResult{Code: commandErrorCode(err), Message: err.Error()}
+		}
+		return cluster.CommandResult{OK: true}
+
+	case server.CmdTransfer:
+		var req server.TransferPayload
+		if err := json.Unmarshal(cmd.Payload, &req); err != nil {
+			return cluster.CommandResult{Code: server.ErrCodeBadRequest, Message: err.Error()}
+		}
+		g, err := manager.GetGame(cmd.GameID)
+		if err == nil {
+			_, err = g.Idempotent(cmd.IdempotencyKey, func() error {
+				_, err := g.TransferGold(cmd.PlayerID, req.To, req.Amount)
+				return err
+			})
+		}
+		if err != nil {
+			return cluster.CommandResult{Code: commandErrorCode(err), Message: err.Error()}
+		}
+		return cluster.CommandResult{OK: true}
+
+	default:
+		return cluster.CommandResult{Code: server.ErrCodeUnknownCommand, Message: "unknown command: " + cmd.Type}
+	}
+}
+
+// wsClientKey identifies a WS client for anti-cheat bookkeeping, matching server.ClientKey
+func wsClientKey(c *server.Client) string {
+	if c.PlayerID() != "" {
+		return "player:" + c.PlayerID()
+	}
+	return "ws:" + c.ID()
+}
+
+// commandErrorCode maps game errors to stable nack codes, the same ones HTTP uses
+func commandErrorCode(err error) string {
+	_, e := api.FromError(err)
+	return e.Code
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tower-defense/internal/cluster"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/server"
+)
+
+// gameLoopStallAfter is how long a running game may go without a tick before
+// the instance reports itself not ready
+const gameLoopStallAfter = 2 * time.Second
+
+// readinessChecks lists what /readyz verifies; bridge is nil outside a cluster
+func readinessChecks(manager *game.Manager, hub *server.Hub, bridge *cluster.RedisBridge, repos ...interface{}) []server.HealthCheck {
+	checks := []server.HealthCheck{
+		{Name: "game_loop", Check: func(ctx context.Context) error {
+			if stalled := manager.StalledGames(gameLoopStallAfter); len(stalled) > 0 {
+				return fmt.Errorf("no tick for %v in games: %s", gameLoopStallAfter, strings.Join(stalled, ", "))
+			}
+			return nil
+		}},
+		{Name: "repository", Check: func(ctx context.Context) error {
+			for _, repo := range repos {
+				if err := repository.Ping(ctx, repo); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{Name: "hub", Check: hub.Ping},
+	}
+	if bridge != nil {
+		checks = append(checks, server.HealthCheck{Name: "cluster", Check: bridge.Ping})
+	}
+	return checks
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/i18n"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLocale returns the language to answer a request in: the preference of
+// the player in X-Player-ID if they set one, otherwise the best match of
+// Accept-Language, otherwise English
+func requestLocale(c *gin.Context, players repository.PlayerRepository) string {
+	var preferred string
+	if playerID := server.PlayerID(c); playerID != "" {
+		if p, err := players.Get(playerID); err == nil {
+			preferred = p.Locale
+		}
+	}
+	lang := i18n.Negotiate(preferred, c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	return lang
+}
+
+// setLocale stores the language a player wants server messages in. Only the
+// player themselves, identified by X-Player-ID, may change it.
+func setLocale(players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		if server.PlayerID(c) != playerID {
+			api.Respond(c, http.StatusForbidden, api.NewError(api.CodeForbidden, "X-Player-ID must match the player"))
+			return
+		}
+		var req api.LocaleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		locale := i18n.Match(req.Locale)
+		if req.Locale != "" && locale == "" {
+			api.BadRequest(c, fmt.Errorf("unsupported locale %q, supported: %s", req.Locale, strings.Join(i18n.Supported(), ", ")))
+			return
+		}
+		err := players.Update(playerID, func(p *repository.PlayerProgress) error {
+			p.Locale = locale
+			return nil
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.LocaleResponse{PlayerID: playerID, Locale: locale, Supported: i18n.Supported()})
+	}
+}
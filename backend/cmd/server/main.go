@@ -1,21 +1,43 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"tower-defense/internal/analytics"
+	"tower-defense/internal/api"
+	"tower-defense/internal/eventstream"
+	"tower-defense/internal/buildinfo"
+	"tower-defense/internal/cluster"
 	"tower-defense/internal/config"
+	"tower-defense/internal/flags"
 	"tower-defense/internal/game"
+	"tower-defense/internal/game/achievements"
+	"tower-defense/internal/game/research"
+	"tower-defense/internal/game/tournament"
+	"tower-defense/internal/game/bot"
+	"tower-defense/internal/game/events"
 	gameconfig "tower-defense/internal/game/config"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/game/stats"
+	"tower-defense/internal/grpcapi"
+	"tower-defense/internal/integrations"
 	"tower-defense/internal/logging"
 	"tower-defense/internal/server"
+	"tower-defense/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -24,8 +46,14 @@ func main() {
 	_ = logging.Init(cfg.LogLevel)
 	defer logging.Sync()
 
-	// Load game configuration
-	gameCfg, err := gameconfig.Load()
+	// Feature flags of this deployment, before anything checks them
+	if err := flags.Load(cfg.FeatureFlagsFile, cfg.FeatureFlags); err != nil {
+		logging.Errorw("failed_to_load_feature_flags", "error", err)
+		panic(err)
+	}
+
+	// Load game configuration (embedded defaults + optional overrides)
+	gameCfg, err := gameconfig.LoadWithOverrides(cfg.ConfigDir)
 	if err != nil {
 		logging.Errorw("failed_to_load_game_config", "error", err)
 		panic(err)
@@ -34,10 +62,256 @@ func main() {
 	// Initialize game manager (supports multi-room)
 	gameManager := game.NewManager(gameCfg)
 	defer gameManager.Shutdown()
+	gameManager.SetOverloadPolicy(game.OverloadPolicy{
+		Budget:   time.Duration(cfg.TickBudgetMs) * time.Millisecond,
+		Ticks:    cfg.OverloadTicks,
+		EnemyCap: cfg.OverloadCap,
+	})
+	gameManager.SetEntityLimits(game.EntityLimits{Enemies: cfg.MaxEnemies, Projectiles: cfg.MaxProjectiles})
+	gameManager.SetMemoryBudget(int64(cfg.MemoryBudgetMB * (1 << 20)))
+	gameManager.SetMaxRooms(cfg.MaxRooms)
+	gameManager.SetOnRoomRefused(func(reason string) { server.RoomsRefused.WithLabelValues(reason).Inc() })
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			server.ObserveMemory(gameManager.MemoryUsage())
+		}
+	}()
+
+	// Panicking game loops are recovered; their reports and world snapshots go here
+	var crashRepo repository.Repository = repository.NewMemoryRepository()
+	if cfg.CrashDir != "" {
+		fileRepo, err := repository.NewFileRepository(cfg.CrashDir)
+		if err != nil {
+			logging.Errorw("crash_repository_failed", "dir", cfg.CrashDir, "error", err)
+			panic(err)
+		}
+		crashRepo = fileRepo
+	}
+	gameManager.SetCrashRepository(crashRepo)
+	gameManager.AddEventListener(func(ev events.Event) {
+		switch ev.Type {
+		case events.GameCrashed:
+			server.EnginePanics.Inc()
+		case events.EntityCapReached:
+			server.EntityCapsReached.WithLabelValues(ev.Detail).Inc()
+		}
+	})
+
+	// Game saves and end-of-game summaries; with SQLITE_PATH player data is kept
+	// in the same file, otherwise it lives in memory
+	var saveRepo repository.Repository = repository.NewMemoryRepository()
+	var achievementRepo repository.AchievementRepository = repository.NewMemoryAchievementRepository()
+	var statsRepo repository.StatsRepository = repository.NewMemoryStatsRepository()
+	var playerRepo repository.PlayerRepository = repository.NewMemoryPlayerRepository()
+	switch {
+	case cfg.SQLitePath != "":
+		sqliteRepo, err := repository.NewSQLiteRepository(cfg.SQLitePath)
+		if err != nil {
+			logging.Errorw("save_repository_failed", "path", cfg.SQLitePath, "error", err)
+			panic(err)
+		}
+		defer sqliteRepo.Close()
+		saveRepo = sqliteRepo
+		achievementRepo = sqliteRepo.Achievements()
+		statsRepo = sqliteRepo.Stats()
+		playerRepo = sqliteRepo.Players()
+	case cfg.SaveDir != "":
+		fileRepo, err := repository.NewFileRepository(cfg.SaveDir)
+		if err != nil {
+			logging.Errorw("save_repository_failed", "dir", cfg.SaveDir, "error", err)
+			panic(err)
+		}
+		saveRepo = fileRepo
+	}
+	saveRepo.SetRetention(repository.RetentionPolicy{
+		MaxSaves: cfg.SaveMaxPerGame,
+		MaxBytes: cfg.SaveMaxBytesPerPlayer,
+		MaxAge:   time.Duration(cfg.SaveMaxAgeHours * float64(time.Hour)),
+	})
+	gameManager.SetSummaryRepository(saveRepo)
+	gameManager.SetReplayRepository(saveRepo)
+	gameManager.SetCheckpointRepository(saveRepo)
+
+	// Achievements are evaluated from the event stream of every game
+	achievementEngine := achievements.NewEngine(achievements.DefaultRules(), achievementRepo)
+	gameManager.AddEventListener(achievementEngine.HandleEvent)
+
+	// Lifetime player stats are folded in when games end
+	statsAggregator := stats.NewAggregator(statsRepo)
+	gameManager.AddEventListener(statsAggregator.HandleEvent)
+
+	// Finished games earn research points; research modifies the games players join
+	researchEngine := research.NewEngine(research.DefaultTree(), playerRepo)
+	gameManager.AddEventListener(researchEngine.HandleEvent)
+	gameManager.SetModifierSource(researchEngine)
+	gameManager.AddEventListener(recordTutorials(playerRepo))
+
+	// Webhooks for created rooms, finished games and new high scores, also
+	// announced in Discord/Slack channels
+	integrationCfg := integrations.Config{
+		DiscordURL: cfg.DiscordWebhookURL,
+		SlackURL:   cfg.SlackWebhookURL,
+		Events:     cfg.IntegrationEvents,
+		JoinURL:    cfg.IntegrationJoinURL,
+	}
+	if cfg.IntegrationTemplates != "" {
+		if integrationCfg.Templates, err = integrations.LoadTemplates(cfg.IntegrationTemplates); err != nil {
+			logging.Errorw("failed_to_load_integration_templates", "error", err)
+			panic(err)
+		}
+	}
+	chatTargets, err := integrations.Targets(integrationCfg)
+	if err != nil {
+		logging.Errorw("invalid_integration_config", "error", err)
+		panic(err)
+	}
+	webhooks := webhook.NewNotifier(webhook.Options{
+		URL:            cfg.WebhookURL,
+		Secret:         cfg.WebhookSecret,
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		DeadLetterPath: cfg.WebhookDeadLetters,
+		Targets:        chatTargets,
+		OnDelivery:     func(result string) { server.WebhookDeliveries.WithLabelValues(result).Inc() },
+	}, gameManager.GetGame)
+	defer webhooks.Close()
+	gameManager.AddEventListener(webhooks.HandleEvent)
+
+	// Gameplay analytics, batched to their own sink apart from the logs
+	if cfg.AnalyticsSink != "" {
+		sink, err := analytics.NewSink(cfg.AnalyticsSink, cfg.AnalyticsTarget, cfg.AnalyticsTopic)
+		if err != nil {
+			logging.Errorw("invalid_analytics_config", "error", err)
+			panic(err)
+		}
+		sampleRates, err := analytics.ParseSampleRates(cfg.AnalyticsSampleRates)
+		if err != nil {
+			logging.Errorw("invalid_analytics_config", "error", err)
+			panic(err)
+		}
+		pipeline := analytics.New(analytics.Options{
+			Sink:          sink,
+			BatchSize:     cfg.AnalyticsBatchSize,
+			FlushInterval: time.Duration(cfg.AnalyticsFlushMs) * time.Millisecond,
+			QueueSize:     cfg.AnalyticsQueueSize,
+			SampleRates:   sampleRates,
+			OnResult:      func(result string, n int) { server.AnalyticsEvents.WithLabelValues(result).Add(float64(n)) },
+		})
+		defer pipeline.Close()
+		gameManager.AddEventListener(pipeline.HandleEvent)
+		logging.Infow("analytics_enabled", "sink", sink.Name())
+	}
+
+	// Every game's events on a message broker, for services consuming them live
+	if cfg.EventStreamBroker != "" {
+		publisher, err := eventstream.NewPublisher(cfg.EventStreamBroker, cfg.EventStreamURL, cfg.EventStreamTopic)
+		if err != nil {
+			logging.Errorw("invalid_event_stream_config", "error", err)
+			panic(err)
+		}
+		opts := eventstream.Options{
+			Publisher: publisher,
+			NodeID:    cfg.NodeID,
+			OnResult:  func(result string, n int) { server.StreamMessages.WithLabelValues(result).Add(float64(n)) },
+		}
+		for _, t := range cfg.EventStreamEvents {
+			opts.Events = append(opts.Events, events.Type(t))
+		}
+		if cfg.EventStreamOutbox != "" {
+			if opts.Outbox, err = repository.NewFileRepository(cfg.EventStreamOutbox); err != nil {
+				logging.Errorw("event_stream_outbox_failed", "dir", cfg.EventStreamOutbox, "error", err)
+				panic(err)
+			}
+		}
+		stream := eventstream.New(opts)
+		defer stream.Close()
+		gameManager.AddEventListener(stream.HandleEvent)
+		logging.Infow("event_stream_enabled", "broker", publisher.Name(), "outbox", cfg.EventStreamOutbox != "")
+	}
+
+	// Hot reload: re-read overrides and push safe changes into running games
+	reloadGameConfig := func() error {
+		newCfg, err := gameconfig.LoadWithOverrides(cfg.ConfigDir)
+		if err != nil {
+			return err
+		}
+		gameManager.ReloadConfig(newCfg)
+		return nil
+	}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if cfg.ConfigDir != "" {
+		go gameconfig.Watch(watchCtx, cfg.ConfigDir, 2*time.Second, func() {
+			if err := reloadGameConfig(); err != nil {
+				logging.Errorw("config_reload_failed", "error", err)
+			}
+		})
+	}
 
-	// Get or create default game
+	// Optional cluster membership: games are owned by one instance, broadcasts and
+	// commands flow between instances over Redis pub/sub
+	var node *cluster.Node
+	var bridge *cluster.RedisBridge
+	if cfg.RedisURL != "" {
+		bridge, err = cluster.NewRedisBridge(cfg.RedisURL)
+		if err != nil {
+			logging.Errorw("cluster_connect_failed", "error", err)
+			panic(err)
+		}
+		defer bridge.Close()
+		node = cluster.NewNode(cfg.NodeID, bridge, bridge.Rooms(), 10*time.Second)
+	}
+
+	// Computer players attached through the API
+	bots := bot.NewRegistry(gameManager)
+	defer bots.StopAll()
+
+	// Tournament brackets, fed by the results of their match rooms
+	tournaments := tournament.NewRegistry(gameManager)
+	gameManager.AddEventListener(tournaments.HandleEvent)
+	tournaments.OnResult(func(res tournament.Result) {
+		statsAggregator.RecordMatch(res.Winner, res.Loser)
+	})
+	if cfg.MatchInactivityS > 0 {
+		timeout := time.Duration(cfg.MatchInactivityS * float64(time.Second))
+		tournaments.SetInactivityTimeout(timeout)
+		go func() {
+			ticker := time.NewTicker(max(min(timeout/4, 30*time.Second), time.Second))
+			defer ticker.Stop()
+			for now := range ticker.C {
+				tournaments.SweepInactive(now)
+			}
+		}()
+	}
+
+	// Resume the games that were running at the last shutdown
+	var restored []*game.Game
+	if cfg.RestoreOnStart {
+		restored, err = gameManager.RestoreAll(saveRepo)
+		if err != nil {
+			logging.Errorw("games_restore_failed", "error", err)
+		}
+	}
+
+	// Get or create default game; in a cluster only the owning instance runs it
 	defaultGame := gameManager.GetOrCreateDefault()
-	defaultGame.Start()
+	ownsDefault := true
+	if node != nil {
+		ownsDefault, err = node.Track(context.Background(), game.DefaultGameID)
+		if err != nil {
+			logging.Errorw("cluster_claim_failed", "error", err)
+			panic(err)
+		}
+	}
+	if ownsDefault {
+		defaultGame.Start()
+	}
+	for _, g := range restored {
+		if g.GetID() != game.DefaultGameID {
+			g.Start()
+		}
+	}
 
 	// Prepare websocket upgrader with origin check
 	upgrader := websocket.Upgrader{
@@ -50,46 +324,185 @@ func main() {
 		},
 	}
 
+	// Anti-cheat: flag implausibly fast or malformed commands per client
+	commandGuard := server.NewCommandGuard(time.Duration(cfg.CommandMinGap) * time.Millisecond)
+
+	// Audit log of state-mutating requests, for settling disputes in multiplayer games
+	var auditRepo repository.Repository
+	if cfg.AuditDir != "" {
+		fileRepo, err := repository.NewFileRepository(cfg.AuditDir)
+		if err != nil {
+			logging.Errorw("audit_repository_failed", "dir", cfg.AuditDir, "error", err)
+			panic(err)
+		}
+		auditRepo = fileRepo
+	}
+	auditLog := server.NewAuditLog(cfg.AuditLogSize, auditRepo)
+	defer auditLog.Close()
+
 	// Handlers
 	// WebSocket hub setup
 	hub := server.NewHub()
+	hub.SetCommandLimiter(server.NewRateLimiter(cfg.WSCommandRate, cfg.WSCommandBurst))
+	hub.SetStallTimeout(time.Duration(cfg.WSStallMs) * time.Millisecond)
+	hub.SetHeartbeat(time.Duration(cfg.WSHeartbeatMs)*time.Millisecond, cfg.ShareLatency)
+	hub.SetKeyframeInterval(time.Duration(cfg.WSKeyframeMs) * time.Millisecond)
+	hub.SetCommandHandler(wsCommands(hub, gameManager, commandGuard, node, auditLog))
 	go hub.Run()
 
+	// Rooms nobody has watched for ROOM_IDLE_TIMEOUT_S are removed, and rooms
+	// whose game ended ROOM_FINISHED_GRACE_S ago. Clients of a room may be
+	// connected to other instances, so a cluster keeps its idle rooms.
+	gameManager.SetClientCounter(hub.ClientCount)
+	idle := game.IdlePolicy{
+		After:    time.Duration(cfg.RoomIdleTimeoutS * float64(time.Second)),
+		Finished: time.Duration(cfg.RoomFinishedS * float64(time.Second)),
+	}
+	if node != nil {
+		idle.After = 0
+	}
+	if idle.After > 0 || idle.Finished > 0 {
+		gameManager.SetIdlePolicy(idle)
+		period := 20 * time.Second
+		for _, d := range []time.Duration{idle.After, idle.Finished} {
+			if d > 0 {
+				period = min(period, d)
+			}
+		}
+		go func() {
+			ticker := time.NewTicker(max(period/2, time.Second))
+			defer ticker.Stop()
+			for now := range ticker.C {
+				tournaments.RoomsClosed(gameManager.SweepIdle(now))
+			}
+		}()
+	}
+
+	clusterCtx, stopCluster := context.WithCancel(context.Background())
+	clusterDone := make(chan struct{})
+	if node != nil {
+		hub.SetRelay(func(typ server.MessageType, gameID string, payload []byte) {
+			ctx, cancel := context.WithTimeout(clusterCtx, time.Second)
+			defer cancel()
+			if err := node.Publish(ctx, string(typ), gameID, payload); err != nil {
+				logging.Warnw("cluster_publish_failed", "type", typ, "error", err)
+			}
+		})
+		node.SetMessageHandler(func(m cluster.Message) {
+			hub.DeliverRemote(server.MessageType(m.Type), m.GameID, m.Payload)
+		})
+		node.SetCommandHandler(func(cmd cluster.Command) cluster.CommandResult {
+			return applyCommand(gameManager, cmd)
+		})
+		node.SetOwnershipHandler(func(gameID string, owned bool) {
+			if gameID != game.DefaultGameID {
+				return
+			}
+			if owned {
+				defaultGame.Start()
+			} else {
+				defaultGame.Stop()
+			}
+		})
+		go func() {
+			node.Run(clusterCtx)
+			close(clusterDone)
+		}()
+	} else {
+		close(clusterDone)
+	}
+
 	// Broadcaster: encode state once and distribute to clients
 	go func() {
-		// base interval 100ms, adaptive: skip if previous broadcast is recent
+		// base interval 100ms; overloaded rooms are broadcast less often
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
-		var last time.Time
-		for range ticker.C {
-			if time.Since(last) < 50*time.Millisecond { // simple adaptive throttling
+		pacer := newBroadcastPacer(cfg.SlowBroadcast)
+		for now := range ticker.C {
+			// Other rooms are only encoded while someone watches them
+			rooms := hub.Rooms()
+			for _, gameID := range rooms {
+				if gameID == game.DefaultGameID || (node != nil && !node.Owns(gameID)) {
+					continue
+				}
+				if g, err := gameManager.GetGame(gameID); err == nil && pacer.due(g, now) {
+					if b, err := g.MarshalState(); err == nil {
+						hub.Broadcast(server.MsgSnapshot, gameID, b)
+						pacer.sent(gameID, now)
+					}
+				}
+			}
+			pacer.retain(append(rooms, game.DefaultGameID))
+			if node != nil && !node.Owns(game.DefaultGameID) {
+				continue // the owning instance broadcasts, we relay
+			}
+			if !pacer.due(defaultGame, now) {
 				continue
 			}
 			b, err := defaultGame.MarshalState()
 			if err != nil {
 				continue
 			}
-			hub.Broadcast(b)
-			last = time.Now()
+			hub.Broadcast(server.MsgSnapshot, game.DefaultGameID, b)
+			pacer.sent(game.DefaultGameID, now)
 		}
 	}()
 
+	// Relay gameplay events to WS clients watching the game
+	gameManager.AddEventListener(func(ev events.Event) {
+		if err := hub.BroadcastJSON(server.MsgEvent, ev.GameID, ev); err != nil {
+			logging.Warnw("ws_event_encode_failed", "type", ev.Type, "error", err)
+		}
+	})
+
+	// Push the end-of-game report to everyone watching; it is stored before listeners run
+	gameManager.AddEventListener(func(ev events.Event) {
+		if ev.Type != events.GameOver {
+			return
+		}
+		g, err := gameManager.GetGame(ev.GameID)
+		if err != nil {
+			return
+		}
+		if summary := g.Summary(); summary != nil {
+			if err := hub.BroadcastJSON(server.MsgSummary, ev.GameID, summary); err != nil {
+				logging.Warnw("ws_summary_encode_failed", "game_id", ev.GameID, "error", err)
+			}
+		}
+	})
+
 	wsHandler := gin.HandlerFunc(func(c *gin.Context) {
+		// ?gameId= watches a room hosted by this instance instead of the default game
+		g := gameManager.GetOrCreateDefault()
+		if id := c.Query("gameId"); id != "" && id != game.DefaultGameID {
+			var err error
+			if g, err = gameManager.GetGame(id); err != nil {
+				api.Fail(c, err)
+				return
+			}
+		}
+		// Rooms with a password or a player limit check them before the upgrade
+		if _, err := g.Admit(c.Query("playerId"), c.Query("password")); err != nil {
+			api.Fail(c, err)
+			return
+		}
 		server.WsConnections.Inc()
 		defer server.WsConnections.Dec()
-		serverHandler := hub.ServeWS(upgrader)
+		serverHandler := hub.ServeWS(upgrader, g.GetID())
 		serverHandler(c.Writer, c.Request)
 		return // no JSON write here
 		})
 
 	addTower := func(c *gin.Context) {
-		var req struct {
-			X         float64 `json:"x"`
-			Y         float64 `json:"y"`
-			TowerType string  `json:"towerType"`
+		var req api.AddTowerRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
 		}
-		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		
+		key, err := server.IdempotencyKey(c)
+		if err != nil {
+			api.BadRequest(c, err)
 			return
 		}
 		
@@ -99,35 +512,98 @@ func main() {
 			towerType = "basic"
 		}
 		
-		if err := defaultGame.AddTower(towerType, req.X, req.Y); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		replayed, err := defaultGame.Idempotent(key, func() error {
+			return defaultGame.AddTowerForPlayer(server.PlayerID(c), towerType, req.X, req.Y)
+		})
+		if replayed {
+			c.Header(server.IdempotentReplayedHeader, "true")
+		}
+		if err != nil {
+			if errors.Is(err, game.ErrInvalidCoordinates) || errors.Is(err, game.ErrOutOfBounds) {
+				commandGuard.Violation(server.ClientKey(c), server.ViolationInvalidInput, "x", req.X, "y", req.Y)
+			}
+			api.Fail(c, err)
 		} else {
-			c.JSON(http.StatusOK, gin.H{"success": true})
+			c.JSON(http.StatusOK, api.SuccessResponse{Success: true})
 		}
 	}
 
-	getState := func(c *gin.Context) {
-		c.JSON(http.StatusOK, defaultGame.GetState())
+	addWall := func(c *gin.Context) {
+		var req api.AddWallRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		
+		key, err := server.IdempotencyKey(c)
+		if err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		
+		replayed, err := defaultGame.Idempotent(key, func() error {
+			return defaultGame.AddWallForPlayer(server.PlayerID(c), req.X, req.Y)
+		})
+		if replayed {
+			c.Header(server.IdempotentReplayedHeader, "true")
+		}
+		if err != nil {
+			if errors.Is(err, game.ErrInvalidCoordinates) || errors.Is(err, game.ErrOutOfBounds) {
+				commandGuard.Violation(server.ClientKey(c), server.ViolationInvalidInput, "x", req.X, "y", req.Y)
+			}
+			api.Fail(c, err)
+		} else {
+			c.JSON(http.StatusOK, api.SuccessResponse{Success: true})
+		}
 	}
 
+	getState := pollState(gameManager)
+
 	reset := func(c *gin.Context) {
 		logging.Infow("game_reset")
 		defaultGame.Reset()
-		c.JSON(http.StatusOK, gin.H{"success": true, "message": "Game reset successfully"})
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Game reset successfully"})
 	}
 	
 	// Multi-room handlers
 	createGame := func(c *gin.Context) {
-		game, err := gameManager.CreateGame()
+		// The body is optional; an empty one creates a game with the standard rules
+		var req api.CreateGameRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				api.BadRequest(c, err)
+				return
+			}
+		}
+		if req.WebhookURL != "" {
+			if !cfg.WebhookRoomURLs {
+				api.BadRequest(c, errors.New("rooms can't choose a webhook URL on this server"))
+				return
+			}
+			if err := webhook.ValidateURL(req.WebhookURL); err != nil {
+				api.BadRequest(c, err)
+				return
+			}
+		}
+		opts := game.GameOptions{Mutators: req.Enabled(), TickRateMs: req.TickRateMs, MaxRollbacks: req.MaxRollbacks, VictoryWave: req.VictoryWave, Seed: req.Seed,
+			WavePacing: req.WavePacing, WebhookURL: req.WebhookURL, Private: req.Private, Lobby: req.Lobby, Wallets: req.Wallets,
+			MaxPlayers: req.MaxPlayers, Password: req.Password}
+		game, err := gameManager.CreateGameWithOptions(opts)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			api.Fail(c, err)
 			return
 		}
+		game.Join(server.PlayerID(c))
 		game.Start()
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"game_id": game.GetID(),
-			"message": "Game created",
+		c.JSON(http.StatusOK, api.CreateGameResponse{
+			Success:  true,
+			GameID:   game.GetID(),
+			Message:  "Game created",
+			Mutators: game.Mutators(),
+
+			TickRateMs: game.TickRateMs(),
+			Seed:       game.Seed(),
+			Code:       game.Code(),
 		})
 	}
 	
@@ -138,22 +614,44 @@ func main() {
 	
 	// Save/Load handlers
 	saveGame := func(c *gin.Context) {
-		data, err := defaultGame.SaveState()
+		// The body is optional; without one the slot is named after the wave
+		var req api.SaveGameRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			api.BadRequest(c, err)
+			return
+		}
+		
+		// Anonymous saves count against the caller's IP
+		slot, data, err := defaultGame.SaveToSlot(saveRepo, server.ClientKey(c), req.Name, req.Thumbnail)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			api.Fail(c, err)
 			return
 		}
 		
-		// For now, just return the data as base64
-		// In production, you'd save to repository
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Game saved",
-			"size": len(data),
-		})
+		resp := api.SaveGameResponse{
+			Success: true,
+			Message: "Game saved",
+			Size:    len(data),
+			Slot:    slot,
+		}
+		// Full-fidelity saves are also returned to the caller so they can be posted back to /load
+		if c.Query("format") == game.SimulationSaveFormat {
+			resp.Format = game.SimulationSaveFormat
+			resp.Data = json.RawMessage(data)
+		}
+		c.JSON(http.StatusOK, resp)
 	}
 	
 	loadGame := func(c *gin.Context) {
+		if slotID := c.Query("slot"); slotID != "" {
+			if err := defaultGame.LoadSlot(saveRepo, slotID); err != nil {
+				api.Fail(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Game loaded"})
+			return
+		}
+		
 		// Accept raw JSON state
 		var stateData []byte
 		var err error
@@ -161,48 +659,50 @@ func main() {
 		// Try to read raw body
 		stateData, err = c.GetRawData()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			api.BadRequest(c, err)
 			return
 		}
 		
-		if err := defaultGame.LoadFromState(stateData); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		load := defaultGame.LoadFromState
+		if c.Query("format") == game.SimulationSaveFormat {
+			load = defaultGame.LoadSimulation
+		}
+		
+		if err := load(stateData); err != nil {
+			api.BadRequest(c, err)
 			return
 		}
 		
-		c.JSON(http.StatusOK, gin.H{"success": true, "message": "Game loaded"})
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true, Message: "Game loaded"})
 	}
 	
 	// Map handlers
 	listMaps := func(c *gin.Context) {
 		mapIDs := gameconfig.ListMaps()
-		maps := make([]gin.H, 0, len(mapIDs))
+		maps := make([]api.MapSummary, 0, len(mapIDs))
 		
 		for _, id := range mapIDs {
 			mapCfg, err := gameconfig.GetMapConfig(id)
 			if err != nil {
 				continue
 			}
-			maps = append(maps, gin.H{
-				"id":          id,
-				"name":        mapCfg.Name,
-				"difficulty":  mapCfg.Difficulty,
-				"description": mapCfg.Description,
-				"pathLength":  len(mapCfg.Path),
+			maps = append(maps, api.MapSummary{
+				ID:          id,
+				Name:        mapCfg.Name,
+				Difficulty:  mapCfg.Difficulty,
+				Description: mapCfg.Description,
+				PathLength:  len(mapCfg.Path),
+				WavePacing:  cmp.Or(gameManager.Config().WavePacingFor(mapCfg).Mode, gameconfig.PacingTimer),
 			})
 		}
 		
-		c.JSON(http.StatusOK, gin.H{
-			"maps": maps,
-		})
+		c.JSON(http.StatusOK, api.MapListResponse{Maps: maps})
 	}
 	
 	changeMap := func(c *gin.Context) {
-		var req struct {
-			MapID string `json:"mapId"`
-		}
-		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		var req api.ChangeMapRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
 			return
 		}
 		
@@ -214,7 +714,7 @@ func main() {
 		defaultGame.Stop()
 		
 		// Create new game with selected map
-		newGame := game.NewGameWithMap("default", gameCfg, req.MapID)
+		newGame := game.NewGameWithMap(game.DefaultGameID, gameManager.Config(), req.MapID)
 		
 		// Replace the default game
 		gameManager.ReplaceDefaultGame(newGame)
@@ -222,32 +722,76 @@ func main() {
 		defaultGame.Start()
 		
 		// Update metrics hook
-		defaultGame.SetOnTick(func(st game.TickStats) {
-			server.TicksTotal.Inc()
-			server.EngineEnemies.Set(float64(st.Enemies))
-			server.EngineProjectiles.Set(float64(st.Projectiles))
-			server.EngineTowers.Set(float64(st.Towers))
-			server.EngineTickSeconds.Observe(st.Dt)
-		})
+		defaultGame.SetOnTick(server.ObserveTick)
 		
 		mapCfg, _ := gameconfig.GetMapConfig(req.MapID)
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Map changed successfully",
-			"map":     mapCfg.Name,
+		c.JSON(http.StatusOK, api.ChangeMapResponse{
+			Success: true,
+			Message: "Map changed successfully",
+			Map:     mapCfg.Name,
 		})
 	}
 
 	// wire Prometheus metrics via on-tick hook
-	defaultGame.SetOnTick(func(st game.TickStats) {
-		server.TicksTotal.Inc()
-		server.EngineEnemies.Set(float64(st.Enemies))
-		server.EngineProjectiles.Set(float64(st.Projectiles))
-		server.EngineTowers.Set(float64(st.Towers))
-		server.EngineTickSeconds.Observe(st.Dt)
-	})
+	defaultGame.SetOnTick(server.ObserveTick)
+
+	// Rate limit endpoints that mutate state or allocate rooms
+	limiter := server.NewRateLimiter(cfg.RateLimit, cfg.RateBurst)
+	addTower = server.RateLimited(limiter, server.Guarded(commandGuard, addTower))
+	addWall = server.RateLimited(limiter, server.Guarded(commandGuard, addWall))
+	saveGame = server.RateLimited(limiter, saveGame)
+	loadGame = server.RateLimited(limiter, loadGame)
+	roomQuota := newRoomQuota(cfg)
+	createGame = server.RateLimited(limiter, server.RoomQuotaGuarded(roomQuota, createGame))
 
-	r := server.NewRouter(wsHandler, addTower, getState, reset, saveGame, loadGame, createGame, listGames, listMaps, changeMap, cfg.AllowedOrigins)
+	r, err := server.NewRouter(wsHandler, addTower, getState, reset, saveGame, loadGame, createGame, listGames, listMaps, changeMap, cfg.AllowedOrigins, cfg.TrustedProxies, server.Audit(auditLog))
+	if err != nil {
+		logging.Errorw("trusted_proxies_invalid", "proxies", cfg.TrustedProxies, "error", err)
+		panic(err)
+	}
+	server.MountAdmin(r, cfg.AdminToken,
+		reloadConfig(reloadGameConfig),
+		adminEndGame(gameManager),
+		adminAdjustResources(gameManager),
+		adminDumpWorld(gameManager),
+		adminListCrashes(crashRepo),
+		adminSetVerbose(gameManager),
+		adminListClients(hub),
+		adminKickClient(hub),
+		adminSaveStats(saveRepo),
+		adminMemory(gameManager),
+		adminAuditLog(auditLog),
+		adminListConnections(hub),
+		adminWebhookDeadLetters(webhooks),
+		adminListFlags(),
+		adminSetFlag(),
+		adminForfeit(tournaments),
+		adminGetBalance(gameManager),
+		adminPatchBalance(gameManager),
+	)
+	server.MountWalls(r, addWall)
+	server.MountTowers(r, server.RateLimited(limiter, server.Guarded(commandGuard, resupplyTower(gameManager))),
+		server.RateLimited(limiter, server.Guarded(commandGuard, sellTower(gameManager))),
+		server.RateLimited(limiter, server.Guarded(commandGuard, sellAllTowers(gameManager))),
+		server.RateLimited(limiter, server.Guarded(commandGuard, applyTemplate(gameManager, playerRepo))))
+	server.MountBlueprints(r, listBlueprints(gameManager), server.RateLimited(limiter, server.Guarded(commandGuard, queueBlueprint(gameManager))),
+		cancelBlueprint(gameManager))
+	server.MountPlayers(r, getAchievements(achievementEngine, playerRepo), setLocale(playerRepo), getPlayerStats(statsAggregator),
+		getResearch(researchEngine), purchaseResearch(researchEngine),
+		listTemplates(playerRepo), saveTemplate(playerRepo), deleteTemplate(playerRepo))
+	server.MountBots(r, server.RateLimited(limiter, addBot(bots)), listBots(bots), removeBot(bots))
+	server.MountSummaries(r, getGameSummary(saveRepo), getReplay(saveRepo), server.RateLimited(limiter, replayFrames(gameManager, saveRepo)))
+	server.MountSaves(r, listSaveSlots(saveRepo))
+	server.MountAnalytics(r, getHeatmap(gameManager))
+	server.MountCatalog(r, getCatalog(gameManager))
+	server.MountWaves(r, previewWaves(gameManager), server.RateLimited(limiter, rollbackWaves(gameManager)),
+		server.RateLimited(limiter, simulateWave(gameManager)))
+	server.MountRooms(r, server.RateLimited(limiter, joinRoom(gameManager)),
+		server.RateLimited(limiter, server.Guarded(commandGuard, transferGold(gameManager))))
+	server.MountTournaments(r, server.RateLimited(limiter, createTournament(tournaments)), getTournament(tournaments),
+		server.RateLimited(limiter, surrenderMatch(tournaments)))
+	server.MountTutorial(r, server.RateLimited(limiter, server.RoomQuotaGuarded(roomQuota, startTutorial(gameManager))), getTutorial(gameManager, playerRepo), getTutorialCompletion(playerRepo))
+	server.MountHealth(r, readinessChecks(gameManager, hub, bridge, achievementRepo, statsRepo, crashRepo, saveRepo)...)
 	// plug request logger is already in router; nothing else needed here
 	// optional debug pprof
 	server.MountPprof(r, cfg.EnablePprof)
@@ -259,23 +803,65 @@ func main() {
 
 	// graceful shutdown
 	go func() {
-		logging.Infow("server_start", "port", cfg.Port)
+		logging.Infow("server_start", "port", cfg.Port, "version", buildinfo.Version, "commit", buildinfo.Commit)
 		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logging.Errorw("server_error", "error", err)
 		}
 	}()
 
+	// Optional gRPC API for bots and native clients
+	var grpcSrv *grpc.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCPort)
+		if err != nil {
+			logging.Errorw("grpc_listen_failed", "port", cfg.GRPCPort, "error", err)
+			panic(err)
+		}
+		grpcSrv = grpcapi.NewGRPCServer(grpcapi.NewServer(gameManager, commandGuard, limiter, roomQuota))
+		go func() {
+			logging.Infow("grpc_server_start", "port", cfg.GRPCPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				logging.Errorw("grpc_server_error", "error", err)
+			}
+		}()
+	}
+
 	// Wait for termination signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logging.Infow("server_shutdown")
 
+	// Tell clients before their games stop, so they can show a notice and reconnect later
+	if err := hub.NotifyAll(server.MsgShutdown, server.ShutdownPayload{
+		Message:  "server going down",
+		Restored: cfg.RestoreOnStart && (cfg.SaveDir != "" || cfg.SQLitePath != ""),
+	}); err != nil {
+		logging.Warnw("ws_shutdown_notice_failed", "error", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	// WebSocket connections are hijacked, so the HTTP server doesn't wait for them;
+	// the hub flushes the shutdown notice and closes them first
+	if err := hub.Shutdown(ctx); err != nil {
+		logging.Warnw("ws_hub_shutdown_error", "error", err)
+	}
 	if err := httpSrv.Shutdown(ctx); err != nil {
 		logging.Errorw("server_shutdown_error", "error", err)
 	}
+	if grpcSrv != nil {
+		grpcSrv.Stop() // streams never finish on their own, so don't wait for them
+	}
+	// Persist every running game; RESTORE_ON_START resumes them on the next start
+	saveCtx, cancelSave := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownSaveMs)*time.Millisecond)
+	if _, err := gameManager.SaveAll(saveCtx, saveRepo); err != nil {
+		logging.Errorw("games_shutdown_save_failed", "error", err)
+	}
+	cancelSave()
 	defaultGame.Stop()
+	// release cluster leases so another instance can take over right away
+	stopCluster()
+	<-clusterDone
 	logging.Infow("server_stopped")
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game/achievements"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/game/research"
+	"tower-defense/internal/game/stats"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getAchievements returns every achievement with the player's unlock status,
+// named in the caller's language
+func getAchievements(engine *achievements.Engine, players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		list, err := engine.PlayerAchievements(playerID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		lang := requestLocale(c, players)
+		for i := range list {
+			list[i] = list[i].Localized(lang)
+		}
+		c.JSON(http.StatusOK, api.AchievementsResponse{PlayerID: playerID, Achievements: list})
+	}
+}
+
+// getPlayerStats returns lifetime statistics for a player
+func getPlayerStats(aggregator *stats.Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ps, err := aggregator.Get(c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.PlayerStatsResponse{Stats: ps, FavoriteTower: ps.FavoriteTower()})
+	}
+}
+
+// getResearch returns a player's research points and tech tree
+func getResearch(engine *research.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		progress, err := engine.Progress(c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, progress)
+	}
+}
+
+// purchaseResearch spends a player's points on a tech tree node. Only the
+// player themselves, identified by X-Player-ID, may spend their points.
+func purchaseResearch(engine *research.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		if server.PlayerID(c) != playerID {
+			api.Respond(c, http.StatusForbidden, api.NewError(api.CodeForbidden, "X-Player-ID must match the player"))
+			return
+		}
+		progress, err := engine.Purchase(playerID, c.Param("node"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, progress)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/config"
+	"tower-defense/internal/game"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// joinRoom looks a room up by its code and admits the player named by X-Player-ID,
+// who then connects with /ws?gameId= and readies up there
+func joinRoom(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, err := manager.GameByCode(c.Param("code"))
+		if err == nil {
+			_, err = g.Admit(server.PlayerID(c), c.Query("password"))
+		}
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.RoomJoinResponse{
+			GameID: g.GetID(),
+			Code:   g.Code(),
+			Lobby:  g.Lobby(),
+
+			Players:    g.Players(),
+			MaxPlayers: g.MaxPlayers(),
+			Wallets:    g.Wallets(),
+
+			TickRateMs: g.TickRateMs(),
+			Mutators:   g.Mutators(),
+		})
+	}
+}
+
+// transferGold gives gold from the caller's wallet to another player of the room
+func transferGold(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.TransferRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		transfer, err := g.TransferGold(server.PlayerID(c), req.To, req.Amount)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, transfer)
+	}
+}
+
+// newRoomQuota builds the room creation quota shared by POST /api/v1/games,
+// POST /api/v1/tutorial and gRPC CreateGame. Room
+// tokens come from a CAPTCHA provider when one is configured, with the fixed
+// tokens accepted too, or else from the fixed tokens alone.
+func newRoomQuota(cfg config.Config) *server.RoomQuota {
+	opts := server.RoomQuotaOptions{
+		PerIP:          cfg.RoomQuotaPerIP,
+		PerPlayer:      cfg.RoomQuotaPerPlayer,
+		Window:         time.Duration(cfg.RoomQuotaWindowS * float64(time.Second)),
+		ChallengeAfter: cfg.RoomChallengeAfter,
+	}
+	tokens := server.NewTokenVerifier(cfg.RoomTokens)
+	switch {
+	case cfg.RoomCaptchaURL != "":
+		opts.Verifier = &server.CaptchaVerifier{
+			VerifyURL: cfg.RoomCaptchaURL,
+			Secret:    cfg.RoomCaptchaSecret,
+			Client:    &http.Client{Timeout: 5 * time.Second},
+			Tokens:    tokens,
+		}
+	case len(tokens) > 0:
+		opts.Verifier = tokens
+	}
+	quota, err := server.NewRoomQuota(opts)
+	if err != nil {
+		logging.Errorw("room_quota_invalid", "error", err)
+		panic(err)
+	}
+	return quota
+}
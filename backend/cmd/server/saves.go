@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listSaveSlots returns the named save slots of the default game
+func listSaveSlots(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slots, err := game.ListSlots(repo, game.DefaultGameID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.SaveSlotListResponse{Slots: slots})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Long-poll limits of GET /state?since=
+const (
+	defaultPollTimeout = 25 * time.Second
+	maxPollTimeout     = 60 * time.Second
+)
+
+// pollState returns the state of the default game. With ?since=<version> it waits until
+// the state is newer than that version and answers 304 if it isn't within the timeout.
+func pollState(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Looked up per request: changing the map replaces the default game
+		g := manager.GetOrCreateDefault()
+		sinceParam := c.Query("since")
+		if sinceParam == "" {
+			writeState(c, g)
+			return
+		}
+		since, err := strconv.ParseUint(sinceParam, 10, 64)
+		if err != nil {
+			api.BadRequest(c, fmt.Errorf("since must be a state version: %w", err))
+			return
+		}
+		timeout := defaultPollTimeout
+		if t := c.Query("timeout"); t != "" {
+			secs, err := strconv.ParseFloat(t, 64)
+			if err != nil || secs < 0 {
+				api.BadRequest(c, fmt.Errorf("timeout must be a non-negative number of seconds"))
+				return
+			}
+			timeout = min(time.Duration(secs*float64(time.Second)), maxPollTimeout)
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		if _, changed := g.WaitForChange(ctx, since); !changed {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		writeState(c, g)
+	}
+}
+
+// writeState responds with the state of g as the requesting player sees it,
+// reusing the encoding shared with broadcasts when there is no fog of war
+func writeState(c *gin.Context, g *game.Game) {
+	data, err := g.MarshalStateFor(server.PlayerID(c))
+	if err != nil {
+		api.Fail(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
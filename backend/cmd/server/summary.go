@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getGameSummary returns the stored report of a game's last finished run
+func getGameSummary(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := game.LoadSummary(repo, c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+	}
+}
+
+// getReplay returns the tick range of the replay of a game's last finished run
+func getReplay(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		replay, err := game.LoadReplay(repo, c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, replay.Info())
+	}
+}
+
+// replayFrames re-simulates ?from_tick= to ?to_tick= of a game's replay
+func replayFrames(manager *game.Manager, repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		replay, err := game.LoadReplay(repo, c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		from := replay.FirstTick
+		if v := c.Query("from_tick"); v != "" {
+			if from, err = strconv.ParseUint(v, 10, 64); err != nil {
+				api.BadRequest(c, fmt.Errorf("from_tick must be a tick number"))
+				return
+			}
+		}
+		to := min(from+game.MaxReplayFrames-1, replay.LastTick)
+		if v := c.Query("to_tick"); v != "" {
+			if to, err = strconv.ParseUint(v, 10, 64); err != nil {
+				api.BadRequest(c, fmt.Errorf("to_tick must be a tick number"))
+				return
+			}
+		}
+		frames, err := manager.ReplayFrames(replay, from, to)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.ReplayFramesResponse{GameID: replay.GameID, FromTick: from, ToTick: to, Frames: frames})
+	}
+}
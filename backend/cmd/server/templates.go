@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listTemplates returns a player's saved build templates
+func listTemplates(players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		p, err := players.Get(playerID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		templates := p.Templates
+		if templates == nil {
+			templates = []repository.BuildTemplate{}
+		}
+		c.JSON(http.StatusOK, api.TemplatesResponse{PlayerID: playerID, Templates: templates})
+	}
+}
+
+// saveTemplate creates or replaces a player's build template. Only the player
+// themselves, identified by X-Player-ID, may change their templates.
+func saveTemplate(players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		if server.PlayerID(c) != playerID {
+			api.Respond(c, http.StatusForbidden, api.NewError(api.CodeForbidden, "X-Player-ID must match the player"))
+			return
+		}
+		var req api.SaveTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		template := repository.BuildTemplate{Name: c.Param("name"), Towers: req.Towers}
+		if err := game.ValidateTemplate(template); err != nil {
+			api.Fail(c, err)
+			return
+		}
+		err := players.Update(playerID, func(p *repository.PlayerProgress) error {
+			i := slices.IndexFunc(p.Templates, func(t repository.BuildTemplate) bool { return t.Name == template.Name })
+			switch {
+			case i >= 0:
+				p.Templates[i] = template
+			case len(p.Templates) >= game.MaxTemplates:
+				return game.ErrTooManyTemplates
+			default:
+				p.Templates = append(p.Templates, template)
+			}
+			return nil
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// deleteTemplate removes one of a player's build templates
+func deleteTemplate(players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := c.Param("id")
+		if server.PlayerID(c) != playerID {
+			api.Respond(c, http.StatusForbidden, api.NewError(api.CodeForbidden, "X-Player-ID must match the player"))
+			return
+		}
+		name := c.Param("name")
+		err := players.Update(playerID, func(p *repository.PlayerProgress) error {
+			i := slices.IndexFunc(p.Templates, func(t repository.BuildTemplate) bool { return t.Name == name })
+			if i < 0 {
+				return game.ErrTemplateNotFound
+			}
+			p.Templates = slices.Delete(p.Templates, i, i+1)
+			return nil
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.SuccessResponse{Success: true})
+	}
+}
+
+// applyTemplate places one of the caller's build templates in a game, as many
+// of its towers as the gold allows
+func applyTemplate(manager *game.Manager, players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := server.PlayerID(c)
+		if playerID == "" {
+			api.BadRequest(c, errors.New("placing a template needs an X-Player-ID header"))
+			return
+		}
+		var req api.ApplyTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		p, err := players.Get(playerID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		template, ok := p.Template(req.Template)
+		if !ok {
+			api.Fail(c, game.ErrTemplateNotFound)
+			return
+		}
+
+		result := g.ApplyTemplate(playerID, template, req.X, req.Y)
+		resp := api.ApplyTemplateResponse{
+			Placed:     result.Placed,
+			Gold:       result.Gold,
+			Placements: make([]api.TemplatePlacementInfo, len(result.Placements)),
+		}
+		for i, placement := range result.Placements {
+			resp.Placements[i].TemplatePlacement = placement
+			if placement.Err != nil {
+				_, resp.Placements[i].Error = api.FromError(placement.Err)
+			}
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game/tournament"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createTournament sets up a bracket; its first matches start right away
+func createTournament(tournaments *tournament.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.CreateTournamentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		state, err := tournaments.Create(tournament.Options{
+			Name:        req.Name,
+			Players:     req.Players,
+			VictoryWave: req.VictoryWave,
+			Seed:        req.Seed,
+		})
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
+
+// getTournament returns the bracket of a tournament
+func getTournament(tournaments *tournament.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state, err := tournaments.Get(c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
+
+// surrenderMatch lets the player named by X-Player-ID give up a tournament match
+func surrenderMatch(tournaments *tournament.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := server.PlayerID(c)
+		if playerID == "" {
+			api.BadRequest(c, errors.New("surrendering needs an X-Player-ID header"))
+			return
+		}
+		state, err := tournaments.Surrender(c.Param("id"), playerID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
+
+// adminForfeit makes a player lose their current tournament match
+func adminForfeit(tournaments *tournament.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req api.ForfeitRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			api.BadRequest(c, err)
+			return
+		}
+		state, err := tournaments.Forfeit(c.Param("id"), req.PlayerID)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resupplyTower refills a tower's ammunition with the game's gold
+func resupplyTower(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		result, err := g.ResupplyTower(server.PlayerID(c), c.Param("towerId"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// sellTower sells one tower of a game for the caller
+func sellTower(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		sale, err := g.SellTower(server.PlayerID(c), c.Param("towerId"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, sale)
+	}
+}
+
+// sellAllTowers sells every tower the caller owns in a game
+func sellAllTowers(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		sale, err := g.SellAll(server.PlayerID(c))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, sale)
+	}
+}
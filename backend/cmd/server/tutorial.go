@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startTutorial creates a tutorial room for the player named by X-Player-ID
+func startTutorial(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		playerID := server.PlayerID(c)
+		if playerID == "" {
+			api.BadRequest(c, errors.New("the tutorial needs an X-Player-ID header"))
+			return
+		}
+		g, err := manager.CreateGame()
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		g.Join(playerID)
+		g.StartTutorial(playerID)
+		g.Start()
+		c.JSON(http.StatusOK, api.CreateGameResponse{
+			Success: true,
+			GameID:  g.GetID(),
+			Message: "Tutorial started",
+
+			TickRateMs: g.TickRateMs(),
+			Seed:       g.Seed(),
+		})
+	}
+}
+
+// getTutorial returns the tutorial progress of a game, prompting in the caller's language
+func getTutorial(manager *game.Manager, players repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		state := g.Tutorial()
+		if state == nil {
+			api.Respond(c, http.StatusNotFound, api.NewError(api.CodeNotFound, "game is not a tutorial"))
+			return
+		}
+		c.JSON(http.StatusOK, state.Localized(requestLocale(c, players)))
+	}
+}
+
+// getTutorialCompletion reports whether a player has finished the tutorial
+func getTutorialCompletion(repo repository.PlayerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := repo.Get(c.Param("id"))
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.TutorialCompletionResponse{
+			PlayerID:    p.PlayerID,
+			Completed:   p.TutorialCompletedAt != nil,
+			CompletedAt: p.TutorialCompletedAt,
+		})
+	}
+}
+
+// recordTutorials stores tutorial completions on the player's profile
+func recordTutorials(repo repository.PlayerRepository) events.Listener {
+	return func(ev events.Event) {
+		if ev.Type != events.TutorialCompleted || ev.PlayerID == "" {
+			return
+		}
+		err := repo.Update(ev.PlayerID, func(p *repository.PlayerProgress) error {
+			if p.TutorialCompletedAt == nil {
+				at := ev.Time
+				if at.IsZero() {
+					at = time.Now()
+				}
+				p.TutorialCompletedAt = &at
+			}
+			return nil
+		})
+		if err != nil {
+			logging.Errorw("tutorial_completion_save_failed", "player_id", ev.PlayerID, "game_id", ev.GameID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+
+	"github.com/gin-gonic/gin"
+)
+
+// previewWaves returns the composition and scaled HP of the next waves of a game
+func previewWaves(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count := game.DefaultWavePreviewCount
+		if v := c.Query("count"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > game.MaxWavePreviewCount {
+				api.BadRequest(c, fmt.Errorf("count must be between 1 and %d", game.MaxWavePreviewCount))
+				return
+			}
+			count = n
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, api.WavePreviewResponse{Waves: g.UpcomingWaves(count)})
+	}
+}
+
+// rollbackWaves restores the checkpoint taken at the start of the current wave,
+// or of an earlier one with ?waves=
+func rollbackWaves(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		waves := 1
+		if v := c.Query("waves"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > game.MaxCheckpoints {
+				api.BadRequest(c, fmt.Errorf("waves must be between 1 and %d", game.MaxCheckpoints))
+				return
+			}
+			waves = n
+		}
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		state, err := g.Rollback(waves)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, api.RollbackResponse{Success: true, State: state})
+	}
+}
+
+// simulateWave dry runs the next wave on a copy of a game and returns the
+// projected leaks and damage; the game itself is left as it is
+func simulateWave(manager *game.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := lookupGame(c, manager)
+		if !ok {
+			return
+		}
+		result, err := g.SimulateNextWave()
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,77 @@
+package engine
+
+import "fmt"
+
+// Command types; those players can also send over WebSocket have the same name
+const (
+	CmdJoin            = "join"
+	CmdPlaceTower      = "place_tower"
+	CmdPlaceWall       = "place_wall"
+	CmdSellTower       = "sell_tower"
+	CmdSellAll         = "sell_all"
+	CmdResupply        = "resupply"
+	CmdQueueBlueprint  = "queue_blueprint"
+	CmdCancelBlueprint = "cancel_blueprint"
+	CmdReady           = "ready"
+	CmdTransfer        = "transfer"
+)
+
+// Command is an action of a player. Only the fields of its type are read.
+type Command struct {
+	Type     string
+	PlayerID string // "" acts for nobody in particular, e.g. a single player spending the shared gold
+
+	TowerType string  // place_tower and queue_blueprint; "" = basic
+	X, Y      float64 // place_tower, place_wall and queue_blueprint
+
+	TowerID     string // sell_tower and resupply
+	BlueprintID string // cancel_blueprint
+
+	Ready bool // ready
+
+	To     string // transfer
+	Amount int    // transfer
+}
+
+// ApplyCommand carries out a player's command at the current tick. It fails
+// with the game's reason, e.g. ErrNotEnoughGold, and changes nothing then;
+// a queued blueprint that can't be built yet is not a failure.
+func (s *Session) ApplyCommand(cmd Command) error {
+	g := s.game
+	towerType := cmd.TowerType
+	if towerType == "" {
+		towerType = "basic"
+	}
+
+	switch cmd.Type {
+	case CmdJoin:
+		g.Join(cmd.PlayerID)
+		return nil
+	case CmdPlaceTower:
+		return g.AddTowerForPlayer(cmd.PlayerID, towerType, cmd.X, cmd.Y)
+	case CmdPlaceWall:
+		return g.AddWallForPlayer(cmd.PlayerID, cmd.X, cmd.Y)
+	case CmdSellTower:
+		_, err := g.SellTower(cmd.PlayerID, cmd.TowerID)
+		return err
+	case CmdSellAll:
+		_, err := g.SellAll(cmd.PlayerID)
+		return err
+	case CmdResupply:
+		_, err := g.ResupplyTower(cmd.PlayerID, cmd.TowerID)
+		return err
+	case CmdQueueBlueprint:
+		_, _, err := g.QueueBlueprint(cmd.PlayerID, towerType, cmd.X, cmd.Y)
+		return err
+	case CmdCancelBlueprint:
+		return g.CancelBlueprint(cmd.PlayerID, cmd.BlueprintID)
+	case CmdReady:
+		_, err := g.SetReady(cmd.PlayerID, cmd.Ready)
+		return err
+	case CmdTransfer:
+		_, err := g.TransferGold(cmd.PlayerID, cmd.To, cmd.Amount)
+		return err
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownCommand, cmd.Type)
+	}
+}
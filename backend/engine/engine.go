@@ -0,0 +1,140 @@
+// Package engine embeds the tower defense simulation in other Go programs,
+// such as bots, research experiments and tooling, without the HTTP and
+// WebSocket layers.
+// A Session is one game that only moves when it is stepped, on a clock of its
+// own, so it runs as fast as the caller likes and plays out the same for the
+// same seed, commands and steps:
+//
+//	seed := int64(42)
+//	s, err := engine.NewSession(engine.Options{MapID: "classic", Seed: &seed})
+//	if err != nil {
+//		return err
+//	}
+//	err = s.ApplyCommand(engine.Command{Type: engine.CmdPlaceTower, TowerType: "basic", X: 100, Y: 100})
+//	for !s.Snapshot().GameOver {
+//		s.Step(0.05)
+//	}
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/events"
+)
+
+// Types of the simulation, so embedding programs can name them
+type (
+	Config   = config.GameConfig
+	Snapshot = game.GameStateSnapshot
+	Event    = events.Event
+)
+
+// Errors commands fail with, for errors.Is
+var (
+	ErrUnknownCommand     = errors.New("unknown command")
+	ErrNotEnoughGold      = game.ErrNotEnoughGold
+	ErrInvalidPlacement   = game.ErrInvalidPlacement
+	ErrInvalidCoordinates = game.ErrInvalidCoordinates
+	ErrOutOfBounds        = game.ErrOutOfBounds
+	ErrMaxTowers          = game.ErrMaxTowers
+	ErrTowerNotFound      = game.ErrTowerNotFound
+	ErrGameFinished       = game.ErrGameFinished
+	ErrNotJoined          = game.ErrNotJoined
+	ErrRuleViolation      = game.ErrRuleViolation
+	ErrUnknownTowerType   = config.ErrUnknownTowerType
+)
+
+// LoadConfig loads the built-in balance and maps
+func LoadConfig() (*Config, error) {
+	return config.Load()
+}
+
+// LoadConfigWithOverrides loads the built-in balance and maps with the
+// balance.yaml and maps.yaml found in dir on top, like the server's CONFIG_DIR
+func LoadConfigWithOverrides(dir string) (*Config, error) {
+	return config.LoadWithOverrides(dir)
+}
+
+// Maps lists the IDs of the maps a session can be played on
+func Maps() []string {
+	return config.ListMaps()
+}
+
+// Options configures a Session; the zero value is a game on the classic map
+// with the built-in balance and a random seed
+type Options struct {
+	Config *Config // nil = LoadConfig
+
+	MapID    string   // see Maps; "" = classic
+	Mutators []string // custom rules by ID, see config.Mutators
+	Seed     *int64   // seed of the random streams; nil = random, see Session.Seed
+
+	VictoryWave *int // wave whose clearing wins the game, 0 = endless; nil = game.victory_wave
+	Lobby       bool // hold the first wave until every player who joined is ready
+	Wallets     bool // give every player gold of their own instead of a shared pool
+}
+
+// Session is one embedded game. Its methods are safe for concurrent use.
+type Session struct {
+	game *game.Game
+}
+
+// NewSession creates a game that waits for its first Step
+func NewSession(opts Options) (*Session, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		var err error
+		if cfg, err = LoadConfig(); err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+	}
+	g, err := game.NewManager(cfg).CreateGameWithOptions(game.GameOptions{
+		MapID:       opts.MapID,
+		Mutators:    opts.Mutators,
+		Seed:        opts.Seed,
+		VictoryWave: opts.VictoryWave,
+		Lobby:       opts.Lobby,
+		Wallets:     opts.Wallets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.StopClock()
+	return &Session{game: g}, nil
+}
+
+// Step advances the game by dt simulated seconds, in ticks of at most 50 ms,
+// and returns how many ticks ran; none once the game is over
+func (s *Session) Step(dt float64) int {
+	return s.game.Step(dt)
+}
+
+// Snapshot returns the game as clients of the server see it
+func (s *Session) Snapshot() Snapshot {
+	return s.game.GetState()
+}
+
+// OnEvent calls l with every gameplay event, after the step or command that
+// caused it
+func (s *Session) OnEvent(l func(Event)) {
+	s.game.AddEventListener(l)
+}
+
+// Seed returns the seed of the game's random streams, to run it again
+func (s *Session) Seed() int64 {
+	return s.game.Seed()
+}
+
+// Save returns the full simulation, to be restored with Load
+func (s *Session) Save() ([]byte, error) {
+	return s.game.SaveSimulation()
+}
+
+// Load restores a simulation returned by Save, from this or another session
+// on the same map
+func (s *Session) Load(data []byte) error {
+	return s.game.LoadSimulation(data)
+}
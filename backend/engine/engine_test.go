@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"tower-defense/internal/game"
+)
+
+// entityID matches the random IDs entities get
+var entityID = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// canonical encodes a snapshot with every entity ID replaced by the order it
+// first appears in and without the room code, so snapshots of two sessions
+// compare equal when their simulations are
+func canonical(t *testing.T, snap Snapshot) string {
+	t.Helper()
+	snap.Code = ""
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]string{}
+	return entityID.ReplaceAllStringFunc(string(data), func(id string) string {
+		if _, ok := ids[id]; !ok {
+			ids[id] = fmt.Sprintf("entity-%d", len(ids))
+		}
+		return ids[id]
+	})
+}
+
+// play runs a lobby game of two players on seed and returns its snapshot after
+// every second of game time. wait is spent in real time once the players are
+// ready, which must not change anything: the session has its own clock.
+func play(t *testing.T, seed int64, wait time.Duration) []string {
+	t.Helper()
+	s, err := NewSession(Options{MapID: "classic", Seed: &seed, Lobby: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := []Command{
+		{Type: CmdJoin, PlayerID: "p1"},
+		{Type: CmdJoin, PlayerID: "p2"},
+		{Type: CmdPlaceTower, PlayerID: "p1", TowerType: "basic", X: 150, Y: 200},
+		{Type: CmdPlaceTower, PlayerID: "p2", TowerType: "basic", X: 250, Y: 300},
+	}
+	for _, cmd := range commands {
+		if err := s.ApplyCommand(cmd); err != nil {
+			t.Fatalf("%s: %v", cmd.Type, err)
+		}
+	}
+	s.Step(0.05)
+	for _, player := range []string{"p1", "p2"} {
+		if err := s.ApplyCommand(Command{Type: CmdReady, PlayerID: player, Ready: true}); err != nil {
+			t.Fatalf("ready %s: %v", player, err)
+		}
+	}
+	time.Sleep(wait)
+
+	var snapshots []string
+	for range 60 {
+		for range 20 {
+			s.Step(0.05)
+		}
+		snapshots = append(snapshots, canonical(t, s.Snapshot()))
+	}
+	return snapshots
+}
+
+func TestSessionsWithSameSeedMatch(t *testing.T) {
+	const seed = 42
+	first := play(t, seed, 0)
+	// Outlast the lobby countdown, so the wall clock is ahead of the game's
+	// when the first wave is let in
+	second := play(t, seed, game.LobbyCountdown+500*time.Millisecond)
+
+	var last struct {
+		Wave int `json:"wave"`
+	}
+	json.Unmarshal([]byte(first[len(first)-1]), &last)
+	if last.Wave < 2 {
+		t.Fatalf("game only reached wave %d, want a few waves to compare", last.Wave)
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("snapshots differ after %d s of game time:\n%s\n%s", i+1, first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package analytics ships gameplay events to an analytics sink, apart from
+// the operational logs: a JSON lines file, an HTTP endpoint or a Kafka topic.
+// Events are sampled by kind, batched and written by a background worker;
+// when the sink can't keep up the queue fills and further events are dropped
+// rather than slowing the game down.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSampleRate = errors.New("invalid analytics sample rate")
+
+// Kinds of events shipped; other gameplay events are left out
+var Kinds = []events.Type{
+	events.TowerPlaced,
+	events.WallPlaced,
+	events.TowerSold,
+	events.EnemyKilled,
+	events.EnemyLeaked,
+	events.WaveStarted,
+	events.WaveCompleted,
+	events.GameOver,
+}
+
+// Defaults of Options
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 5 * time.Second
+	DefaultQueueSize     = 10000
+	DefaultMaxAttempts   = 3
+	DefaultBackoff       = time.Second
+	closeTimeout         = 5 * time.Second // for the last batches on Close
+)
+
+// Results of events, see Options.OnResult
+const (
+	ResultSent       = "sent"
+	ResultSampledOut = "sampled_out"
+	ResultDropped    = "dropped" // the queue was full
+	ResultFailed     = "failed"  // the sink gave up on their batch
+)
+
+// Options configure a Pipeline
+type Options struct {
+	Sink          Sink
+	BatchSize     int                     // events per write, 0 = DefaultBatchSize
+	FlushInterval time.Duration           // longest an event waits for its batch to fill, 0 = DefaultFlushInterval
+	QueueSize     int                     // events waiting for the sink before new ones are dropped, 0 = DefaultQueueSize
+	MaxAttempts   int                     // tries per batch, 0 = DefaultMaxAttempts
+	Backoff       time.Duration           // wait before the first retry, doubled after each one; 0 = DefaultBackoff
+	SampleRates   map[events.Type]float64 // share of each kind shipped, missing = all of them
+
+	OnResult func(result string, n int) // called with the outcome of every n events, e.g. for metrics
+}
+
+// Record is a shipped event. Consumers weigh it by 1/SampleRate to estimate
+// totals, and drop repeated IDs of batches a retry delivered twice.
+type Record struct {
+	ID         string  `json:"id"`
+	SampleRate float64 `json:"sampleRate"`
+	events.Event
+}
+
+// ParseSampleRates parses sample rates like "enemy_killed=0.1,tower_placed=1"
+// for Options.SampleRates; every kind must be one of Kinds and every rate
+// between 0 and 1
+func ParseSampleRates(s string) (map[events.Type]float64, error) {
+	rates := make(map[events.Type]float64)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not kind=rate", ErrInvalidSampleRate, pair)
+		}
+		kind = strings.TrimSpace(kind)
+		if !slices.Contains(Kinds, events.Type(kind)) {
+			return nil, fmt.Errorf("%w: %q is not an analytics event", ErrInvalidSampleRate, kind)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%w: %s must be between 0 and 1, got %q", ErrInvalidSampleRate, kind, value)
+		}
+		rates[events.Type(kind)] = rate
+	}
+	return rates, nil
+}
+
+// Pipeline turns game events into records and writes them to its sink in
+// batches. HandleEvent never blocks the game.
+type Pipeline struct {
+	opts   Options
+	kinds  map[events.Type]bool
+	queue  chan Record
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	closed atomic.Bool
+
+	closeOnce sync.Once
+}
+
+// New starts a pipeline writing to opts.Sink
+func New(opts Options) *Pipeline {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pipeline{
+		opts:   opts,
+		kinds:  make(map[events.Type]bool, len(Kinds)),
+		queue:  make(chan Record, opts.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	for _, k := range Kinds {
+		p.kinds[k] = true
+	}
+	go p.work()
+	return p
+}
+
+// HandleEvent is a game event listener that queues the events of Kinds
+func (p *Pipeline) HandleEvent(ev events.Event) {
+	if !p.kinds[ev.Type] || p.closed.Load() {
+		return
+	}
+	rate, ok := p.opts.SampleRates[ev.Type]
+	if !ok {
+		rate = 1
+	}
+	if rate < 1 && rand.Float64() >= rate {
+		p.report(ResultSampledOut, 1)
+		return
+	}
+	select {
+	case p.queue <- Record{ID: uuid.NewString(), SampleRate: rate, Event: ev}:
+	default:
+		p.report(ResultDropped, 1)
+	}
+}
+
+// Close stops taking events and writes those still queued, giving up on them
+// after a few seconds
+func (p *Pipeline) Close() {
+	p.closeOnce.Do(func() {
+		p.closed.Store(true)
+		timer := time.AfterFunc(closeTimeout, p.cancel)
+		defer timer.Stop()
+		close(p.done)
+		<-p.ctx.Done()
+	})
+}
+
+// work collects batches and writes them, a full batch at once and a partial
+// one after FlushInterval
+func (p *Pipeline) work() {
+	defer p.cancel()
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, p.opts.BatchSize)
+	for {
+		select {
+		case r := <-p.queue:
+			if batch = append(batch, r); len(batch) >= p.opts.BatchSize {
+				batch = p.flush(batch)
+			}
+		case <-ticker.C:
+			batch = p.flush(batch)
+		case <-p.done:
+			for {
+				select {
+				case r := <-p.queue:
+					if batch = append(batch, r); len(batch) >= p.opts.BatchSize {
+						batch = p.flush(batch)
+					}
+				default:
+					p.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch, retrying with exponential backoff, and returns it
+// emptied for reuse
+func (p *Pipeline) flush(batch []Record) []Record {
+	if len(batch) == 0 {
+		return batch
+	}
+	backoff := p.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := p.opts.Sink.Write(p.ctx, batch)
+		if err == nil {
+			p.report(ResultSent, len(batch))
+			return batch[:0]
+		}
+		if attempt >= p.opts.MaxAttempts || p.ctx.Err() != nil {
+			p.report(ResultFailed, len(batch))
+			logging.Warnw("analytics_batch_failed", "sink", p.opts.Sink.Name(), "events", len(batch), "attempts", attempt, "error", err)
+			return batch[:0]
+		}
+		logging.Debugw("analytics_retry", "sink", p.opts.Sink.Name(), "attempt", attempt, "error", err)
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (p *Pipeline) report(result string, n int) {
+	if p.opts.OnResult != nil {
+		p.opts.OnResult(result, n)
+	}
+}
@@ -0,0 +1,164 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink kinds, see NewSink
+const (
+	SinkFile  = "file"
+	SinkHTTP  = "http"
+	SinkKafka = "kafka"
+)
+
+// DefaultKafkaTopic is the topic of a Kafka sink without one
+const DefaultKafkaTopic = "td-analytics"
+
+// sinkTimeout bounds one write of a batch to an HTTP endpoint or Kafka
+const sinkTimeout = 10 * time.Second
+
+var (
+	ErrUnknownSink   = errors.New("unknown analytics sink")
+	ErrInvalidTarget = errors.New("invalid analytics sink target")
+)
+
+// Sink writes batches of records somewhere outside the server
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, batch []Record) error
+}
+
+// NewSink returns the sink of a kind: for SinkFile target is the path of a
+// JSON lines file, for SinkHTTP the URL batches are POSTed to, and for
+// SinkKafka the URL of a Kafka REST Proxy producing to topic
+func NewSink(kind, target, topic string) (Sink, error) {
+	switch kind {
+	case SinkFile:
+		if target == "" {
+			return nil, fmt.Errorf("%w: file sink needs a path", ErrInvalidTarget)
+		}
+		return &fileSink{path: target}, nil
+	case SinkHTTP, SinkKafka:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %s sink needs an absolute http or https URL, got %q", ErrInvalidTarget, kind, target)
+		}
+		client := &http.Client{Timeout: sinkTimeout}
+		if kind == SinkHTTP {
+			return &httpSink{url: target, client: client}, nil
+		}
+		if topic == "" {
+			topic = DefaultKafkaTopic
+		}
+		return &kafkaSink{url: strings.TrimSuffix(target, "/") + "/topics/" + url.PathEscape(topic), topic: topic, client: client}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSink, kind)
+	}
+}
+
+// fileSink appends records to a file, one JSON object per line
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) Name() string { return SinkFile }
+
+func (s *fileSink) Write(_ context.Context, batch []Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range batch {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// httpSink POSTs batches as {"events": [...]}
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Name() string { return SinkHTTP }
+
+func (s *httpSink) Write(ctx context.Context, batch []Record) error {
+	body, err := json.Marshal(struct {
+		Events []Record `json:"events"`
+	}{batch})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/json", body)
+}
+
+// kafkaSink produces batches to a topic through the Kafka REST Proxy (v2
+// API), keyed by game so the events of a game stay in order on one partition
+type kafkaSink struct {
+	url    string
+	topic  string
+	client *http.Client
+}
+
+func (s *kafkaSink) Name() string { return SinkKafka + ":" + s.topic }
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Record `json:"value"`
+}
+
+func (s *kafkaSink) Write(ctx context.Context, batch []Record) error {
+	records := make([]kafkaRecord, len(batch))
+	for i, r := range batch {
+		records[i] = kafkaRecord{Key: r.GameID, Value: r}
+	}
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{records})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", body)
+}
+
+// post sends body and fails on any response but a 2xx
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
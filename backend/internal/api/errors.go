@@ -0,0 +1,238 @@
+// Package api defines the wire types of the HTTP API: request and response bodies
+// for every endpoint, the error body shared by all of them, and the OpenAPI
+// document generated from those types.
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"tower-defense/internal/flags"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/bot"
+	gameconfig "tower-defense/internal/game/config"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/game/research"
+	"tower-defense/internal/game/tournament"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes are stable and meant for programs, e.g. to pick a localized
+// message; they are shared by HTTP error bodies and WebSocket error/nack
+// messages. Every code is listed in Codes.
+const (
+	CodeBadRequest     = "bad_request"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeRateLimited    = "rate_limited"
+	CodeTooFast        = "too_fast"
+	CodeInvalidConfig  = "invalid_config"
+	CodeInternal       = "internal_error"
+	CodeUnavailable    = "unavailable"
+	CodeUnknownCommand = "unknown_command" // WebSocket message of an unknown type
+
+	// game rule rejections
+	CodeNotEnoughGold      = "not_enough_gold"
+	CodeInvalidPlacement   = "invalid_placement"
+	CodeInvalidCoordinates = "invalid_coordinates"
+	CodeOutOfBounds        = "out_of_bounds"
+	CodeUnknownTowerType   = "unknown_tower_type"
+	CodeGameNotFound       = "game_not_found"
+	CodeTowerLocked        = "tower_locked"
+	CodeNotEnoughPoints    = "not_enough_points"
+	CodeUnknownMutator     = "unknown_mutator"
+	CodeTutorialStep       = "tutorial_step"
+	CodeAmmoDisabled       = "ammo_disabled"
+	CodeAmmoFull           = "ammo_full"
+	CodeCorruptSave        = "corrupt_save"
+	CodeInvalidSlot        = "invalid_slot"
+	CodeInvalidTickRate    = "invalid_tick_rate"
+	CodeNoCheckpoint       = "no_checkpoint"
+	CodeRollbackLimit      = "rollback_limit"
+	CodeGameOver           = "game_over"
+	CodeMaxTowers          = "max_towers"     // the game holds placement.max_towers towers
+	CodeRoomFull           = "room_full"      // the room has no free player slot
+	CodeSellClosed         = "sell_closed"    // selling is only allowed between waves
+	CodeWrongPassword      = "wrong_password" // the room's password is missing or wrong
+	CodeRuleViolation      = "rule_violation" // the game's rules don't allow the action right now
+
+	// room creation limits
+	CodeRoomQuota         = "room_quota"         // the client IP or player created its quota of rooms for now
+	CodeMaxRooms          = "max_rooms"          // the server holds as many rooms as it may
+	CodeChallengeRequired = "challenge_required" // send a valid X-Room-Token to create more rooms
+)
+
+// Codes is the enum of every error code, published in the OpenAPI document
+var Codes = []string{
+	CodeBadRequest, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeConflict, CodeRateLimited,
+	CodeTooFast, CodeInvalidConfig, CodeInternal, CodeUnavailable, CodeUnknownCommand,
+
+	CodeNotEnoughGold, CodeInvalidPlacement, CodeInvalidCoordinates, CodeOutOfBounds, CodeUnknownTowerType,
+	CodeGameNotFound, CodeTowerLocked, CodeNotEnoughPoints, CodeUnknownMutator, CodeTutorialStep,
+	CodeAmmoDisabled, CodeAmmoFull, CodeCorruptSave, CodeInvalidSlot, CodeInvalidTickRate,
+	CodeNoCheckpoint, CodeRollbackLimit, CodeGameOver, CodeMaxTowers, CodeRoomFull, CodeSellClosed,
+	CodeWrongPassword, CodeRuleViolation,
+
+	CodeRoomQuota, CodeMaxRooms, CodeChallengeRequired,
+}
+
+// Error is the body of every non-2xx response
+type Error struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Code + ": " + e.Message }
+
+// NewError creates an error body
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetails attaches structured details, e.g. the list of invalid config fields
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// FromError maps an error from the game packages to an HTTP status and error body
+func FromError(err error) (int, *Error) {
+	var apiErr *Error
+	var validationErr *gameconfig.ValidationError
+	var ruleErr *game.RuleViolation
+	switch {
+	case errors.As(err, &apiErr):
+		return http.StatusBadRequest, apiErr
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, NewError(CodeInvalidConfig, "invalid game config").WithDetails(validationErr.Errors)
+	case errors.Is(err, game.ErrNotEnoughGold):
+		return http.StatusBadRequest, NewError(CodeNotEnoughGold, err.Error())
+	case errors.Is(err, game.ErrInvalidPlacement):
+		return http.StatusBadRequest, NewError(CodeInvalidPlacement, err.Error())
+	case errors.Is(err, game.ErrInvalidCoordinates):
+		return http.StatusBadRequest, NewError(CodeInvalidCoordinates, err.Error())
+	case errors.Is(err, game.ErrOutOfBounds):
+		return http.StatusBadRequest, NewError(CodeOutOfBounds, err.Error())
+	case errors.Is(err, game.ErrTutorialStep):
+		return http.StatusBadRequest, NewError(CodeTutorialStep, err.Error())
+	case errors.Is(err, game.ErrTowerLocked):
+		return http.StatusBadRequest, NewError(CodeTowerLocked, err.Error())
+	case errors.Is(err, game.ErrAmmoDisabled):
+		return http.StatusBadRequest, NewError(CodeAmmoDisabled, err.Error())
+	case errors.Is(err, game.ErrAmmoFull):
+		return http.StatusConflict, NewError(CodeAmmoFull, err.Error())
+	case errors.Is(err, gameconfig.ErrUnknownTowerType):
+		return http.StatusBadRequest, NewError(CodeUnknownTowerType, err.Error())
+	case errors.Is(err, gameconfig.ErrUnknownMutator):
+		return http.StatusBadRequest, NewError(CodeUnknownMutator, err.Error())
+	case errors.Is(err, game.ErrInvalidTickRate):
+		return http.StatusBadRequest, NewError(CodeInvalidTickRate, err.Error())
+	case errors.Is(err, game.ErrMemoryBudget):
+		return http.StatusServiceUnavailable, NewError(CodeUnavailable, err.Error())
+	case errors.Is(err, game.ErrTooManyRooms):
+		return http.StatusTooManyRequests, NewError(CodeMaxRooms, err.Error())
+	case errors.Is(err, game.ErrInvalidRollbackLimit), errors.Is(err, game.ErrInvalidVictoryWave),
+		errors.Is(err, game.ErrInvalidWavePacing), errors.Is(err, game.ErrNoOverrides):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, game.ErrNoCheckpoint):
+		return http.StatusNotFound, NewError(CodeNoCheckpoint, err.Error())
+	case errors.Is(err, game.ErrRollbackLimit):
+		return http.StatusConflict, NewError(CodeRollbackLimit, err.Error())
+	case errors.Is(err, game.ErrMaxTowers):
+		return http.StatusConflict, NewError(CodeMaxTowers, err.Error())
+	case errors.Is(err, game.ErrNoNextWave):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrGameFinished):
+		return http.StatusConflict, NewError(CodeGameOver, err.Error())
+	case errors.Is(err, game.ErrGameNotFound):
+		return http.StatusNotFound, NewError(CodeGameNotFound, err.Error())
+	case errors.Is(err, game.ErrTowerNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, repository.ErrSaveNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, game.ErrInvalidSlot):
+		return http.StatusBadRequest, NewError(CodeInvalidSlot, err.Error())
+	case errors.Is(err, game.ErrIncompatibleSave):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, repository.ErrInvalidData):
+		return http.StatusInternalServerError, NewError(CodeCorruptSave, err.Error())
+	case errors.Is(err, research.ErrUnknownNode):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, research.ErrNotEnoughPoints):
+		return http.StatusBadRequest, NewError(CodeNotEnoughPoints, err.Error())
+	case errors.Is(err, research.ErrAlreadyUnlocked), errors.Is(err, research.ErrMissingPrerequisite):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrNotTowerOwner):
+		return http.StatusForbidden, NewError(CodeForbidden, err.Error())
+	case errors.Is(err, game.ErrSellClosed):
+		return http.StatusConflict, NewError(CodeSellClosed, err.Error())
+	case errors.As(err, &ruleErr):
+		return http.StatusConflict, NewError(CodeRuleViolation, err.Error()).WithDetails(ruleErr)
+	case errors.Is(err, game.ErrBlueprintNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, game.ErrTooManyBlueprints):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrNotBlueprintOwner):
+		return http.StatusForbidden, NewError(CodeForbidden, err.Error())
+	case errors.Is(err, game.ErrTemplateNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, game.ErrInvalidTemplate):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, game.ErrTooManyTemplates):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrRoomFull):
+		return http.StatusConflict, NewError(CodeRoomFull, err.Error())
+	case errors.Is(err, game.ErrWrongPassword):
+		return http.StatusForbidden, NewError(CodeWrongPassword, err.Error())
+	case errors.Is(err, game.ErrInvalidMaxPlayers), errors.Is(err, game.ErrInvalidPassword):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, game.ErrNoLobby), errors.Is(err, game.ErrLobbyClosed):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrNotJoined):
+		return http.StatusForbidden, NewError(CodeForbidden, err.Error())
+	case errors.Is(err, game.ErrNoWallets), errors.Is(err, game.ErrTransfersDisabled):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, game.ErrInvalidTransfer):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, game.ErrInvalidTickRange):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, tournament.ErrNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, tournament.ErrInvalid):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, tournament.ErrNotPlaying), errors.Is(err, tournament.ErrMatchOver):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	case errors.Is(err, tournament.ErrMatchNotFound):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, tournament.ErrNotInMatch):
+		return http.StatusForbidden, NewError(CodeForbidden, err.Error())
+	case errors.Is(err, flags.ErrUnknownFlag):
+		return http.StatusNotFound, NewError(CodeNotFound, err.Error())
+	case errors.Is(err, bot.ErrUnknownStrategy):
+		return http.StatusBadRequest, NewError(CodeBadRequest, err.Error())
+	case errors.Is(err, bot.ErrBotExists), errors.Is(err, bot.ErrTooManyBots):
+		return http.StatusConflict, NewError(CodeConflict, err.Error())
+	default:
+		return http.StatusInternalServerError, NewError(CodeInternal, err.Error())
+	}
+}
+
+// Respond aborts the request with status and an error body
+func Respond(c *gin.Context, status int, e *Error) {
+	c.AbortWithStatusJSON(status, e)
+}
+
+// Fail aborts the request with the status and body FromError picks for err
+func Fail(c *gin.Context, err error) {
+	status, e := FromError(err)
+	Respond(c, status, e)
+}
+
+// BadRequest aborts the request with 400 for a malformed body or parameter
+func BadRequest(c *gin.Context, err error) {
+	Respond(c, http.StatusBadRequest, NewError(CodeBadRequest, err.Error()))
+}
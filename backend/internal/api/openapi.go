@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is reported in the OpenAPI document
+const Version = "1.0.0"
+
+// schemaObject is a JSON Schema fragment as used by OpenAPI 3
+type schemaObject map[string]interface{}
+
+// schemaBuilder turns Go types into OpenAPI schemas, collecting named structs
+// under components/schemas
+type schemaBuilder struct {
+	components map[string]schemaObject
+	names      map[reflect.Type]string
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *schemaBuilder) schema(t reflect.Type) schemaObject {
+	switch {
+	case t == timeType:
+		return schemaObject{"type": "string", "format": "date-time"}
+	case t == rawType:
+		return schemaObject{} // any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := b.schema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return schemaObject{"allOf": []schemaObject{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Bool:
+		return schemaObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return schemaObject{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return schemaObject{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return schemaObject{"type": "number"}
+	case reflect.String:
+		return schemaObject{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return schemaObject{"type": "string", "format": "byte"}
+		}
+		return schemaObject{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return schemaObject{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		return b.ref(t)
+	default:
+		return schemaObject{}
+	}
+}
+
+// ref registers a struct under components/schemas and returns a reference to it
+func (b *schemaBuilder) ref(t reflect.Type) schemaObject {
+	name, ok := b.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := b.components[name]; taken || name == "" {
+			name = exportName(pkgName(t)) + t.Name()
+		}
+		b.names[t] = name // registered first so recursive types terminate
+		b.components[name] = b.object(t)
+	}
+	return schemaObject{"$ref": "#/components/schemas/" + name}
+}
+
+// object describes a struct's JSON fields; embedded structs are flattened like encoding/json does
+func (b *schemaBuilder) object(t reflect.Type) schemaObject {
+	props := schemaObject{}
+	var required []string
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = b.schema(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(t)
+
+	obj := schemaObject{"type": "object", "properties": props}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+func exportName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// openAPIPath converts gin's ":id" segments to OpenAPI's "{id}" and returns the parameter names
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// Spec builds the OpenAPI 3 document for routes
+func Spec(routes []Route) map[string]interface{} {
+	b := &schemaBuilder{components: map[string]schemaObject{}, names: map[reflect.Type]string{}}
+	errorRef := b.schema(reflect.TypeOf(Error{}))
+	b.components["Error"]["properties"].(schemaObject)["code"] = schemaObject{"type": "string", "enum": Codes} // so clients can switch on it
+
+	paths := map[string]schemaObject{}
+	for _, r := range routes {
+		path, pathParams := openAPIPath(r.Path)
+		var params []schemaObject
+		for _, p := range pathParams {
+			params = append(params, schemaObject{"name": p, "in": "path", "required": true, "schema": schemaObject{"type": "string"}})
+		}
+		for _, q := range r.Query {
+			params = append(params, schemaObject{"name": q.Name, "in": "query", "description": q.Description, "schema": schemaObject{"type": "string"}})
+		}
+		if r.Player {
+			params = append(params, schemaObject{"name": "X-Player-ID", "in": "header", "description": "Player identity", "schema": schemaObject{"type": "string"}})
+		}
+		if r.Idempotent {
+			params = append(params, schemaObject{"name": "Idempotency-Key", "in": "header", "description": "Retry key; a repeated key returns the first result without running the command again", "schema": schemaObject{"type": "string"}})
+		}
+
+		responses := schemaObject{}
+		if r.Response != nil {
+			responses["200"] = schemaObject{"description": "OK", "content": jsonContent(b.schema(reflect.TypeOf(r.Response)))}
+		} else {
+			responses["200"] = schemaObject{"description": "OK"}
+		}
+		if r.NotModified {
+			responses["304"] = schemaObject{"description": http.StatusText(http.StatusNotModified)}
+		}
+		errs := append([]int(nil), r.Errors...)
+		if r.Admin {
+			errs = append(errs, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		for _, status := range errs {
+			responses[strconv.Itoa(status)] = schemaObject{"description": http.StatusText(status), "content": jsonContent(errorRef)}
+		}
+
+		op := schemaObject{
+			"summary":     r.Summary,
+			"operationId": operationID(r.Method, path),
+			"tags":        []string{r.Tag},
+			"responses":   responses,
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if r.Request != nil {
+			op["requestBody"] = schemaObject{"required": true, "content": jsonContent(b.schema(reflect.TypeOf(r.Request)))}
+		}
+		if r.Admin {
+			op["security"] = []schemaObject{{"adminToken": []string{}}, {"bearer": []string{}}}
+		}
+
+		if paths[path] == nil {
+			paths[path] = schemaObject{}
+		}
+		paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": schemaObject{
+			"title":       "Tower Defense API",
+			"version":     Version,
+			"description": "Errors use the Error schema. Live updates are served over WebSocket at /ws and gRPC (api/game.proto).",
+		},
+		"paths": paths,
+		"components": schemaObject{
+			"schemas": b.components,
+			"securitySchemes": schemaObject{
+				"adminToken": schemaObject{"type": "apiKey", "in": "header", "name": "X-Admin-Token"},
+				"bearer":     schemaObject{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func jsonContent(schema schemaObject) schemaObject {
+	return schemaObject{"application/json": schemaObject{"schema": schema}}
+}
+
+// operationID derives a stable identifier such as "postAdminGamesIdEnd"
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, s := range strings.Split(strings.TrimPrefix(path, "/api/v1"), "/") {
+		s = strings.Trim(s, "{}")
+		for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			sb.WriteString(exportName(word))
+		}
+	}
+	return sb.String()
+}
+
+// OpenAPIHandler serves the document generated from Routes
+func OpenAPIHandler() gin.HandlerFunc {
+	var once sync.Once
+	var doc []byte
+	return func(c *gin.Context) {
+		once.Do(func() {
+			var err error
+			if doc, err = json.Marshal(Spec(Routes)); err != nil {
+				panic(err) // the spec only contains plain maps and strings
+			}
+		})
+		c.Data(http.StatusOK, "application/json; charset=utf-8", doc)
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Param documents a query parameter
+type Param struct {
+	Name        string
+	Description string
+}
+
+// Route documents one endpoint for the OpenAPI document. Request and Response are
+// zero values of the body types (nil = no body); path parameters are taken from
+// the ":name" segments of Path.
+type Route struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Query    []Param
+	Request  interface{}
+	Response interface{}
+	Errors   []int // documented error statuses, all with an Error body
+	Player   bool  // reads the X-Player-ID header
+	Admin    bool  // requires the admin token
+
+	NotModified bool // may answer 304 without a body
+	Idempotent  bool // honors the Idempotency-Key header
+}
+
+// Routes lists every /api/v1 endpoint. Keep it in sync with server.NewRouter and the Mount* functions.
+var Routes = []Route{
+	{Method: http.MethodGet, Path: "/api/v1/health", Tag: "system", Summary: "Deprecated alias of /api/v1/healthz", Response: HealthResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/healthz", Tag: "system", Summary: "Liveness check; also served at /healthz", Response: HealthResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/readyz", Tag: "system", Summary: "Readiness check of the game loop and dependencies; also served at /readyz",
+		Response: ReadinessResponse{}, Errors: []int{503}},
+	{Method: http.MethodGet, Path: "/api/v1/openapi.json", Tag: "system", Summary: "This document", Response: json.RawMessage{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/state", Tag: "game", Summary: "Current state of the default game; long-polls when since is given",
+		Query: []Param{
+			{Name: "since", Description: "state version the caller has; waits until the state is newer, 304 on timeout"},
+			{Name: "timeout", Description: "seconds to wait with since, default 25, at most 60"},
+		},
+		Response: GameState{}, Errors: []int{400}, NotModified: true},
+	{Method: http.MethodPost, Path: "/api/v1/tower", Tag: "game", Summary: "Place a tower in the default game",
+		Request: AddTowerRequest{}, Response: SuccessResponse{}, Errors: []int{400, 429}, Player: true, Idempotent: true},
+	{Method: http.MethodPost, Path: "/api/v1/wall", Tag: "game", Summary: "Build a wall on the path of the default game",
+		Request: AddWallRequest{}, Response: SuccessResponse{}, Errors: []int{400, 429}, Player: true, Idempotent: true},
+	{Method: http.MethodPost, Path: "/api/v1/reset", Tag: "game", Summary: "Restart the default game", Response: SuccessResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/save", Tag: "game", Summary: "Save the default game to a named slot",
+		Query:   []Param{{Name: "format", Description: `"simulation" returns a full-fidelity save in data`}},
+		Request: SaveGameRequest{}, Response: SaveGameResponse{}, Errors: []int{400, 429, 500}},
+	{Method: http.MethodGet, Path: "/api/v1/saves", Tag: "game", Summary: "Save slots of the default game with their metadata, newest first",
+		Response: SaveSlotListResponse{}, Errors: []int{500}},
+	{Method: http.MethodPost, Path: "/api/v1/load", Tag: "game", Summary: "Load a saved state or save slot into the default game",
+		Query: []Param{
+			{Name: "format", Description: `"simulation" for saves made with format=simulation`},
+			{Name: "slot", Description: "ID of a save slot to load instead of a body"},
+		},
+		Request: json.RawMessage{}, Response: SuccessResponse{}, Errors: []int{400, 404, 429}},
+	{Method: http.MethodGet, Path: "/api/v1/game-config", Tag: "game", Summary: "Tower and enemy catalog, map geometry and placement rules of the default game",
+		Response: GameConfig{}},
+	{Method: http.MethodGet, Path: "/api/v1/maps", Tag: "game", Summary: "List playable maps", Response: MapListResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/map", Tag: "game", Summary: "Restart the default game on another map",
+		Request: ChangeMapRequest{}, Response: ChangeMapResponse{}, Errors: []int{400}},
+
+	{Method: http.MethodPost, Path: "/api/v1/games", Tag: "rooms", Summary: "Create a game room, optionally with mutators and its own tick rate",
+		Request: CreateGameRequest{}, Response: CreateGameResponse{}, Errors: []int{400, 403, 429, 500, 503}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/games", Tag: "rooms", Summary: "List game rooms", Response: GameListResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/bot", Tag: "rooms", Summary: "Attach a bot player to a game",
+		Request: AddBotRequest{}, Response: BotResponse{}, Errors: []int{400, 404, 409, 429}},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/bots", Tag: "rooms", Summary: "List the bots in a game", Response: BotListResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/games/:id/bot/:playerId", Tag: "rooms", Summary: "Detach a bot",
+		Response: SuccessResponse{}, Errors: []int{404}},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/summary", Tag: "rooms", Summary: "Report of the game's last finished run",
+		Response: GameSummary{}, Errors: []int{404, 500}},
+	{Method: http.MethodGet, Path: "/api/v1/replays/:id", Tag: "rooms", Summary: "Tick range of the replay of the game's last finished run, for a scrub bar",
+		Response: ReplayInfo{}, Errors: []int{404, 500}},
+	{Method: http.MethodGet, Path: "/api/v1/replays/:id/frames", Tag: "rooms", Summary: "Re-simulate a tick range of a game's replay and return a snapshot after each tick",
+		Query: []Param{
+			{Name: "from_tick", Description: "first tick, default the replay's first"},
+			{Name: "to_tick", Description: "last tick, included; default 299 ticks after from_tick or the replay's last, at most 300 frames"},
+		},
+		Response: ReplayFramesResponse{}, Errors: []int{400, 404, 429, 500}},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/analytics/heatmap", Tag: "rooms", Summary: "Where enemies died, leaked and took damage in the current game",
+		Response: Heatmap{}, Errors: []int{404}},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/towers/:towerId/resupply", Tag: "rooms", Summary: "Refill a tower's ammunition with as many rounds as the game's gold buys",
+		Response: ResupplyResponse{}, Errors: []int{400, 404, 409, 429}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/towers/:towerId/sell", Tag: "rooms", Summary: "Sell a tower; in a rules.free_sell phase, or between waves with economy.between_wave_refunds, it returns its full price",
+		Response: SellResponse{}, Errors: []int{400, 403, 404, 409, 429}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/sell-all", Tag: "rooms", Summary: "Sell every tower of the X-Player-ID player, e.g. to rebuild between waves",
+		Response: SellResponse{}, Errors: []int{400, 404, 409, 429}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/blueprints", Tag: "rooms", Summary: "Towers queued until the game can afford them, in build order",
+		Response: BlueprintListResponse{}, Errors: []int{404}},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/blueprints", Tag: "rooms", Summary: "Queue a tower that is built as soon as the gold covers it and every blueprint queued before it",
+		Request: AddTowerRequest{}, Response: QueueBlueprintResponse{}, Errors: []int{400, 404, 409, 429}, Player: true},
+	{Method: http.MethodDelete, Path: "/api/v1/games/:id/blueprints/:blueprintId", Tag: "rooms", Summary: "Cancel a queued tower; blueprints with an owner only by that player",
+		Response: SuccessResponse{}, Errors: []int{403, 404}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/apply-template", Tag: "rooms", Summary: "Place the towers of one of the X-Player-ID player's build templates, as many as the gold allows",
+		Request: ApplyTemplateRequest{}, Response: ApplyTemplateResponse{}, Errors: []int{400, 404, 429, 500}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/rooms/:code/join", Tag: "rooms", Summary: "Join the room holding a code, e.g. a private one, as the player in X-Player-ID",
+		Query: []Param{{Name: "password", Description: "the room's password, if it has one"}}, Response: RoomJoinResponse{}, Errors: []int{403, 404, 409, 429}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/transfer", Tag: "rooms", Summary: "Give gold from the X-Player-ID player's wallet to another player of a room with wallets, minus economy.wallets.transfer_tax",
+		Request: TransferRequest{}, Response: TransferResponse{}, Errors: []int{400, 403, 404, 409, 429}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/tournaments", Tag: "rooms", Summary: "Create a single-elimination bracket and the rooms of its first round",
+		Request: CreateTournamentRequest{}, Response: Tournament{}, Errors: []int{400, 429}},
+	{Method: http.MethodGet, Path: "/api/v1/tournaments/:id", Tag: "rooms", Summary: "Bracket of a tournament: every match with its rooms, results and winner",
+		Response: Tournament{}, Errors: []int{404}},
+	{Method: http.MethodPost, Path: "/api/v1/match/:id/surrender", Tag: "rooms", Summary: "Give up a tournament match as the player in X-Player-ID; the opponent advances",
+		Response: Tournament{}, Errors: []int{400, 403, 404, 409, 429}, Player: true},
+	{Method: http.MethodPost, Path: "/api/v1/tutorial", Tag: "rooms", Summary: "Create a guided tutorial room for the player in X-Player-ID",
+		Response: CreateGameResponse{}, Errors: []int{400, 403, 429, 500, 503}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/tutorial", Tag: "rooms", Summary: "Tutorial progress of a game, the prompt in the player's language",
+		Response: TutorialState{}, Errors: []int{404}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/games/:id/waves/preview", Tag: "rooms", Summary: "Composition and scaled HP of the next waves",
+		Query: []Param{{Name: "count", Description: "waves to preview, default 5, at most 20"}}, Response: WavePreviewResponse{}, Errors: []int{400, 404}},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/rollback", Tag: "rooms", Summary: "Restore the checkpoint taken at the start of a wave, counted against the game's rollback limit",
+		Query:    []Param{{Name: "waves", Description: "1 retries the current wave, 2 the one before, and so on; default 1, at most 10"}},
+		Response: RollbackResponse{}, Errors: []int{400, 404, 409, 429}},
+	{Method: http.MethodPost, Path: "/api/v1/games/:id/simulate-wave", Tag: "rooms", Summary: "Dry run the next wave on a copy of the game and project leaks and damage by tower",
+		Response: WaveSimulation{}, Errors: []int{404, 409, 429}},
+
+	{Method: http.MethodGet, Path: "/api/v1/players/:id/achievements", Tag: "players", Summary: "Achievements of a player, named in the caller's language",
+		Response: AchievementsResponse{}, Errors: []int{500}, Player: true},
+	{Method: http.MethodPut, Path: "/api/v1/players/:id/locale", Tag: "players", Summary: "Set the language of server messages for a player; X-Player-ID must match the player",
+		Request: LocaleRequest{}, Response: LocaleResponse{}, Errors: []int{400, 403, 500}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/players/:id/stats", Tag: "players", Summary: "Lifetime stats of a player", Response: PlayerStatsResponse{}, Errors: []int{500}},
+	{Method: http.MethodGet, Path: "/api/v1/players/:id/tutorial", Tag: "players", Summary: "Whether a player has finished the tutorial", Response: TutorialCompletionResponse{}, Errors: []int{500}},
+	{Method: http.MethodGet, Path: "/api/v1/players/:id/research", Tag: "players", Summary: "Research points and tech tree of a player", Response: ResearchResponse{}, Errors: []int{500}},
+	{Method: http.MethodPost, Path: "/api/v1/players/:id/research/:node", Tag: "players", Summary: "Spend research points on a tech tree node; X-Player-ID must match the player",
+		Response: ResearchResponse{}, Errors: []int{400, 403, 404, 409}, Player: true},
+	{Method: http.MethodGet, Path: "/api/v1/players/:id/templates", Tag: "players", Summary: "Saved build templates of a player",
+		Response: TemplatesResponse{}, Errors: []int{500}},
+	{Method: http.MethodPut, Path: "/api/v1/players/:id/templates/:name", Tag: "players", Summary: "Save a build template, replacing one of the same name; X-Player-ID must match the player",
+		Request: SaveTemplateRequest{}, Response: BuildTemplate{}, Errors: []int{400, 403, 409, 500}, Player: true},
+	{Method: http.MethodDelete, Path: "/api/v1/players/:id/templates/:name", Tag: "players", Summary: "Delete a build template; X-Player-ID must match the player",
+		Response: SuccessResponse{}, Errors: []int{403, 404, 500}, Player: true},
+
+	{Method: http.MethodPost, Path: "/api/v1/admin/reload-config", Tag: "admin", Summary: "Reload game config overrides",
+		Response: SuccessResponse{}, Errors: []int{400}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/balance", Tag: "admin", Summary: "Effective balance of new rooms or a running room, with its tuning history",
+		Query:    []Param{{Name: "gameId", Description: "the running room to inspect; omitted = new rooms"}},
+		Response: BalanceResponse{}, Errors: []int{404, 500}, Admin: true},
+	{Method: http.MethodPatch, Path: "/api/v1/admin/balance", Tag: "admin", Summary: "Tune tower and enemy stats of new rooms or a running room",
+		Query:   []Param{{Name: "gameId", Description: "the running room to tune; omitted = new rooms"}},
+		Request: BalancePatchRequest{}, Response: BalanceResponse{}, Errors: []int{400, 404, 500}, Admin: true},
+	{Method: http.MethodPost, Path: "/api/v1/admin/games/:id/end", Tag: "admin", Summary: "Force-end a game",
+		Response: SuccessResponse{}, Errors: []int{404, 409}, Admin: true},
+	{Method: http.MethodPost, Path: "/api/v1/admin/games/:id/resources", Tag: "admin", Summary: "Add gold and lives to a game",
+		Request: AdjustResourcesRequest{}, Response: AdjustResourcesResponse{}, Errors: []int{400, 404}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/games/:id/world", Tag: "admin", Summary: "Dump the raw simulation state of a game",
+		Response: json.RawMessage{}, Errors: []int{404, 500}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/games/:id/crashes", Tag: "admin", Summary: "Crash reports of a game's loop",
+		Response: CrashListResponse{}, Errors: []int{500}, Admin: true},
+	{Method: http.MethodPost, Path: "/api/v1/admin/games/:id/verbose", Tag: "admin", Summary: "Toggle verbose logging for a game",
+		Request: SetVerboseRequest{}, Response: SetVerboseResponse{}, Errors: []int{400, 404}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/clients", Tag: "admin", Summary: "List connected WebSocket clients",
+		Response: ClientListResponse{}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/connections", Tag: "admin", Summary: "WebSocket connections with their room, subscription and message counters",
+		Query:    []Param{{Name: "gameId", Description: "only connections watching this game"}},
+		Response: ConnectionListResponse{}, Admin: true},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/clients/:id", Tag: "admin", Summary: "Disconnect a WebSocket client",
+		Response: SuccessResponse{}, Errors: []int{404}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/memory", Tag: "admin", Summary: "Approximate memory held by each room and the budget new rooms are refused above",
+		Response: MemoryResponse{}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/saves/stats", Tag: "admin", Summary: "Size of the save repository and what retention evicted",
+		Response: SaveStatsResponse{}, Errors: []int{500}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/webhooks/dead-letters", Tag: "admin", Summary: "Recent webhook deliveries that gave up, oldest first",
+		Response: DeadLetterListResponse{}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/flags", Tag: "admin", Summary: "Feature flags with their state and where it came from",
+		Response: FlagListResponse{}, Admin: true},
+	{Method: http.MethodPost, Path: "/api/v1/admin/flags/:name", Tag: "admin", Summary: "Switch a feature flag on or off until the next restart",
+		Request: SetFlagRequest{}, Response: FeatureFlag{}, Errors: []int{400, 404}, Admin: true},
+	{Method: http.MethodPost, Path: "/api/v1/admin/tournaments/:id/forfeit", Tag: "admin", Summary: "Make a player lose the tournament match they are playing",
+		Request: ForfeitRequest{}, Response: Tournament{}, Errors: []int{400, 404, 409}, Admin: true},
+	{Method: http.MethodGet, Path: "/api/v1/admin/audit", Tag: "admin", Summary: "Recent state-mutating HTTP requests and WebSocket commands, newest first",
+		Query: []Param{
+			{Name: "gameId", Description: "only requests acting on this game"},
+			{Name: "playerId", Description: "only requests of this player"},
+			{Name: "since", Description: "RFC 3339 time; only requests made at or after it"},
+			{Name: "limit", Description: "entries to return, default 100, at most the log size"},
+		},
+		Response: AuditLogResponse{}, Errors: []int{400}, Admin: true},
+}
@@ -0,0 +1,467 @@
+package api
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"tower-defense/internal/flags"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/achievements"
+	"tower-defense/internal/game/bot"
+	gameconfig "tower-defense/internal/game/config"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/game/research"
+	"tower-defense/internal/game/tournament"
+	"tower-defense/internal/webhook"
+)
+
+// SuccessResponse is returned by endpoints that only report completion
+type SuccessResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthResponse is returned by GET /healthz
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Status     string  `json:"status"` // "ok" or "fail"
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// ReadinessResponse is returned by GET /readyz. When a check fails the response is
+// a 503 unavailable error whose details hold the same checks map.
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// GameState is returned by GET /state
+type GameState = game.GameStateSnapshot
+
+// AddTowerRequest is the body of POST /tower
+type AddTowerRequest struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	TowerType string  `json:"towerType,omitempty"` // defaults to "basic"
+}
+
+// AddWallRequest is the body of POST /wall
+type AddWallRequest struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// GameSummary is returned by GET /games/:id/summary
+type GameSummary = game.Summary
+
+// Heatmap is returned by GET /games/:id/analytics/heatmap
+type Heatmap = game.Heatmap
+
+// WavePreviewResponse is returned by GET /games/:id/waves/preview
+type WavePreviewResponse struct {
+	Waves []game.WavePreview `json:"waves"`
+}
+
+// RollbackResponse is returned by POST /games/:id/rollback
+type RollbackResponse struct {
+	Success bool           `json:"success"`
+	State   game.GameState `json:"state"` // right after the rollback
+}
+
+// GameConfig is returned by GET /game-config
+type GameConfig = game.Catalog
+
+// CreateGameRequest is the optional body of POST /games
+type CreateGameRequest struct {
+	Mutators map[string]bool `json:"mutators,omitempty"` // custom rules by ID, e.g. {"half_tower_cost": true}
+
+	TickRateMs int `json:"tick_rate_ms,omitempty"` // simulation tick interval, 10-50 ms; 0 = the balance default
+
+	MaxRollbacks *int `json:"max_rollbacks,omitempty"` // waves the game may retry, -1 = unlimited; absent = the balance default
+	VictoryWave  *int `json:"victory_wave,omitempty"`  // clearing this wave wins, 0 = endless; absent = the balance default
+
+	WavePacing string `json:"wave_pacing,omitempty"` // timer, after_clear or hybrid; absent = the map's pacing
+
+	Seed *int64 `json:"seed,omitempty"` // replays the randomness of an earlier game; absent = random
+
+	WebhookURL string `json:"webhook_url,omitempty"` // also receives this room's webhooks; needs WEBHOOK_ROOM_URLS
+
+	Private bool `json:"private,omitempty"` // left out of GET /games; friends join with the room code
+	Lobby   bool `json:"lobby,omitempty"`   // hold the first wave until every player who joined is ready
+	Wallets bool `json:"wallets,omitempty"` // every player gets gold of their own instead of a shared pool
+
+	MaxPlayers int    `json:"max_players,omitempty"` // seats, 1-16; 0 = no limit
+	Password   string `json:"password,omitempty"`    // players joining need it; the creator is in already
+}
+
+// Enabled returns the IDs of the mutators switched on, sorted
+func (r CreateGameRequest) Enabled() []string {
+	ids := []string{}
+	for id, on := range r.Mutators {
+		if on {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CreateGameResponse is returned by POST /games
+type CreateGameResponse struct {
+	Success  bool     `json:"success"`
+	GameID   string   `json:"game_id"`
+	Message  string   `json:"message"`
+	Mutators []string `json:"mutators,omitempty"` // applied mutators, in application order
+
+	TickRateMs int   `json:"tick_rate_ms"`
+	Seed       int64 `json:"seed"` // reproduces the game's randomness, see CreateGameRequest.Seed
+
+	Code string `json:"code,omitempty"` // room code for GET /rooms/:code/join
+}
+
+// LobbyState is the lobby of a room created with "lobby": true
+type LobbyState = game.LobbyState
+
+// RoomJoinResponse is returned by GET /rooms/:code/join
+type RoomJoinResponse struct {
+	GameID string      `json:"game_id"` // for /ws?gameId= and the /games/:id routes
+	Code   string      `json:"code"`
+	Lobby  *LobbyState `json:"lobby,omitempty"` // nil when the room started without a lobby
+
+	Players    int  `json:"players"`               // including the caller
+	MaxPlayers int  `json:"max_players,omitempty"` // 0 = no limit
+	Wallets    bool `json:"wallets,omitempty"`     // players have gold of their own, see the snapshot's wallets
+
+	TickRateMs int      `json:"tick_rate_ms"`
+	Mutators   []string `json:"mutators,omitempty"`
+}
+
+// TransferRequest is the body of POST /games/:id/transfer
+type TransferRequest struct {
+	To     string `json:"to" binding:"required"` // player ID of the recipient
+	Amount int    `json:"amount"`                // gold taken from the sender, before tax
+}
+
+// TransferResponse is returned by POST /games/:id/transfer
+type TransferResponse = game.Transfer
+
+// GameListResponse is returned by GET /games
+type GameListResponse = game.ManagerStats
+
+// AddBotRequest is the body of POST /games/:id/bot; every field is optional
+type AddBotRequest struct {
+	PlayerID   string `json:"playerId,omitempty"`
+	Strategy   string `json:"strategy,omitempty"`   // "greedy" (default) or "chokepoint"
+	IntervalMs int    `json:"intervalMs,omitempty"` // time between decisions, default 500
+}
+
+// BotResponse is returned by POST /games/:id/bot
+type BotResponse struct {
+	Success bool     `json:"success"`
+	Bot     bot.Info `json:"bot"`
+}
+
+// BotListResponse is returned by GET /games/:id/bots
+type BotListResponse struct {
+	Bots []bot.Info `json:"bots"`
+}
+
+// SaveGameRequest is the optional body of POST /save
+type SaveGameRequest struct {
+	Name      string `json:"name,omitempty"`      // slot name, defaults to the wave
+	Thumbnail string `json:"thumbnail,omitempty"` // e.g. a data: URL, at most 64 KiB
+}
+
+// SaveGameResponse is returned by POST /save. Data is only set for the simulation format.
+type SaveGameResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Format  string          `json:"format,omitempty"`
+	Size    int             `json:"size"`
+	Data    json.RawMessage `json:"data,omitempty"`
+
+	Slot *game.SaveSlot `json:"slot,omitempty"`
+}
+
+// SaveSlotListResponse is returned by GET /saves
+type SaveSlotListResponse struct {
+	Slots []game.SaveSlot `json:"slots"`
+}
+
+// MapSummary describes a playable map
+type MapSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Difficulty  string `json:"difficulty"`
+	Description string `json:"description"`
+	PathLength  int    `json:"pathLength"`
+	WavePacing  string `json:"wavePacing"` // timer, after_clear or hybrid
+}
+
+// MapListResponse is returned by GET /maps
+type MapListResponse struct {
+	Maps []MapSummary `json:"maps"`
+}
+
+// ChangeMapRequest is the body of POST /map
+type ChangeMapRequest struct {
+	MapID string `json:"mapId,omitempty"` // defaults to "classic"
+}
+
+// ChangeMapResponse is returned by POST /map
+type ChangeMapResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Map     string `json:"map"`
+}
+
+// AchievementsResponse is returned by GET /players/:id/achievements
+type AchievementsResponse struct {
+	PlayerID     string                `json:"playerId"`
+	Achievements []achievements.Status `json:"achievements"`
+}
+
+// PlayerStatsResponse is returned by GET /players/:id/stats
+type PlayerStatsResponse struct {
+	Stats         *repository.PlayerStats `json:"stats"`
+	FavoriteTower string                  `json:"favoriteTower"`
+}
+
+// ResearchResponse is returned by GET /players/:id/research and POST /players/:id/research/:node
+type ResearchResponse = research.Progress
+
+// ResupplyResponse is returned by POST /games/:id/towers/:towerId/resupply
+type ResupplyResponse = game.Resupply
+
+// ReplayInfo is returned by GET /replays/:id
+type ReplayInfo = game.ReplayInfo
+
+// ReplayFramesResponse is returned by GET /replays/:id/frames
+type ReplayFramesResponse struct {
+	GameID   string      `json:"gameId"`
+	FromTick uint64      `json:"fromTick"`
+	ToTick   uint64      `json:"toTick"`
+	Frames   []GameState `json:"frames"` // one per tick, in order
+}
+
+// WaveSimulation is returned by POST /games/:id/simulate-wave
+type WaveSimulation = game.WaveSimulation
+
+// SellResponse is returned by POST /games/:id/towers/:towerId/sell and /games/:id/sell-all
+type SellResponse = game.Sale
+
+// Blueprint is a tower placement queued until the game can afford it
+type Blueprint = game.Blueprint
+
+// BlueprintListResponse is returned by GET /games/:id/blueprints
+type BlueprintListResponse struct {
+	Blueprints []Blueprint `json:"blueprints"` // in build order
+}
+
+// QueueBlueprintResponse is returned by POST /games/:id/blueprints
+type QueueBlueprintResponse struct {
+	Blueprint Blueprint `json:"blueprint"`
+	Built     bool      `json:"built"` // the gold already covered it, so it is a tower now
+}
+
+// TutorialState is returned by GET /games/:id/tutorial
+type TutorialState = game.TutorialState
+
+// LocaleRequest is the body of PUT /players/:id/locale
+type LocaleRequest struct {
+	Locale string `json:"locale"` // a supported language such as "pl", "" = follow Accept-Language
+}
+
+// LocaleResponse is returned by PUT /players/:id/locale
+type LocaleResponse struct {
+	PlayerID  string   `json:"playerId"`
+	Locale    string   `json:"locale,omitempty"`
+	Supported []string `json:"supported"` // languages with translated messages
+}
+
+// BuildTemplate is a saved tower layout of a player
+type BuildTemplate = repository.BuildTemplate
+
+// TemplatesResponse is returned by GET /players/:id/templates
+type TemplatesResponse struct {
+	PlayerID  string          `json:"playerId"`
+	Templates []BuildTemplate `json:"templates"`
+}
+
+// SaveTemplateRequest is the body of PUT /players/:id/templates/:name
+type SaveTemplateRequest struct {
+	Towers []repository.TemplateTower `json:"towers"` // placed in order, dx/dy in grid cells of placement.gridSize
+}
+
+// ApplyTemplateRequest is the body of POST /games/:id/apply-template
+type ApplyTemplateRequest struct {
+	Template string  `json:"template"` // name of one of the X-Player-ID player's templates
+	X        float64 `json:"x"`        // where the template's origin cell goes
+	Y        float64 `json:"y"`
+}
+
+// ApplyTemplateResponse is returned by POST /games/:id/apply-template
+type ApplyTemplateResponse struct {
+	Placed     int                     `json:"placed"` // towers placed
+	Gold       int                     `json:"gold"`   // gold spent
+	Placements []TemplatePlacementInfo `json:"placements"`
+}
+
+// TemplatePlacementInfo is the outcome of one tower of an applied template
+type TemplatePlacementInfo struct {
+	game.TemplatePlacement
+	Error *Error `json:"error,omitempty"` // why the tower was skipped
+}
+
+// TutorialCompletionResponse is returned by GET /players/:id/tutorial
+type TutorialCompletionResponse struct {
+	PlayerID    string     `json:"playerId"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// AdjustResourcesRequest is the body of POST /admin/games/:id/resources
+type AdjustResourcesRequest struct {
+	Gold  int `json:"gold"`
+	Lives int `json:"lives"`
+}
+
+// AdjustResourcesResponse is returned by POST /admin/games/:id/resources
+type AdjustResourcesResponse struct {
+	Success bool           `json:"success"`
+	State   game.GameState `json:"state"`
+}
+
+// CrashListResponse is returned by GET /admin/games/:id/crashes
+type CrashListResponse struct {
+	Crashes []game.CrashReport `json:"crashes"`
+}
+
+// MemoryResponse is returned by GET /admin/memory
+type MemoryResponse = game.MemoryReport
+
+// SaveStatsResponse is returned by GET /admin/saves/stats
+type SaveStatsResponse = repository.RepositoryStats
+
+// AuditEntry records one state-mutating request: an HTTP call or a WebSocket command
+type AuditEntry struct {
+	ID         uint64          `json:"id"`
+	Time       time.Time       `json:"time"`
+	RequestID  string          `json:"requestId,omitempty"`
+	PlayerID   string          `json:"playerId,omitempty"`
+	ClientIP   string          `json:"clientIp"`
+	GameID     string          `json:"gameId,omitempty"`
+	Method     string          `json:"method"` // HTTP method, or "WS" for WebSocket commands
+	Path       string          `json:"path"`   // route pattern, or the command type
+	Query      string          `json:"query,omitempty"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Truncated  bool            `json:"truncated,omitempty"` // params were too large or not JSON and were dropped
+	Status     int             `json:"status,omitempty"`    // HTTP status, unset for WebSocket commands
+	Code       string          `json:"code,omitempty"`      // error code of a failed request or nacked command
+	DurationMs float64         `json:"durationMs"`
+}
+
+// AuditLogResponse is returned by GET /admin/audit
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"` // newest first
+}
+
+// SetVerboseRequest is the body of POST /admin/games/:id/verbose
+type SetVerboseRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetVerboseResponse is returned by POST /admin/games/:id/verbose
+type SetVerboseResponse struct {
+	Success bool `json:"success"`
+	Verbose bool `json:"verbose"`
+}
+
+// ClientInfo describes a connected WebSocket client
+type ClientInfo struct {
+	ID          string    `json:"id"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// ConnectionInfo is what the hub knows about a WebSocket connection
+type ConnectionInfo struct {
+	ID              string     `json:"id"`
+	GameID          string     `json:"gameId"`
+	PlayerID        string     `json:"playerId,omitempty"`
+	RemoteAddr      string     `json:"remoteAddr"`
+	ConnectedAt     time.Time  `json:"connectedAt"`
+	Detail          string     `json:"detail"`                 // snapshot detail, "full" or "lite"
+	SnapshotRate    float64    `json:"snapshotRate,omitempty"` // snapshots/second subscribed to, 0 = every broadcast
+	MessagesSent    uint64     `json:"messagesSent"`
+	MessagesDropped uint64     `json:"messagesDropped"`   // skipped while the client was behind
+	Queued          int        `json:"queued"`            // messages waiting to be written
+	Stalled         bool       `json:"stalled,omitempty"` // behind; skipping messages until the next snapshot
+	RttMs           float64    `json:"rttMs,omitempty"`   // last heartbeat round trip, unset until measured
+	RttAvgMs        float64    `json:"rttAvgMs,omitempty"`
+	LastPong        *time.Time `json:"lastPong,omitempty"`
+}
+
+// ConnectionListResponse is returned by GET /admin/connections
+type ConnectionListResponse struct {
+	Connections []ConnectionInfo `json:"connections"`
+}
+
+// DeadLetterListResponse is returned by GET /admin/webhooks/dead-letters
+type DeadLetterListResponse struct {
+	DeadLetters []webhook.DeadLetter `json:"deadLetters"`
+}
+
+// FeatureFlag describes a feature flag; POST /admin/flags/:name returns it
+type FeatureFlag = flags.State
+
+// FlagListResponse is returned by GET /admin/flags
+type FlagListResponse struct {
+	Flags []FeatureFlag `json:"flags"`
+}
+
+// BalancePatchRequest is the body of PATCH /admin/balance; unset fields keep their value
+type BalancePatchRequest = gameconfig.BalanceOverrides
+
+// BalanceChange is an entry of the balance audit trail
+type BalanceChange = game.BalanceChange
+
+// BalanceResponse is returned by GET and PATCH /admin/balance
+type BalanceResponse struct {
+	GameID    string                      `json:"gameId,omitempty"` // "" for the balance of new rooms
+	Config    map[string]interface{}      `json:"config"`           // effective config, keyed like balance.yaml
+	Overrides gameconfig.BalanceOverrides `json:"overrides"`        // all tuning applied on top of the loaded config
+	History   []BalanceChange             `json:"history"`          // newest first
+}
+
+// SetFlagRequest is the body of POST /admin/flags/:name
+type SetFlagRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Tournament is a bracket, returned by the tournament endpoints
+type Tournament = tournament.State
+
+// CreateTournamentRequest is the body of POST /tournaments
+type CreateTournamentRequest struct {
+	Name        string   `json:"name,omitempty"`
+	Players     []string `json:"players" binding:"required"` // 2-32 player IDs in seed order, the strongest first
+	VictoryWave int      `json:"victoryWave,omitempty"`      // clearing this wave wins a match room, default 10
+	Seed        *int64   `json:"seed,omitempty"`             // seed of every match room; absent = random per match
+}
+
+// ForfeitRequest is the body of POST /admin/tournaments/:id/forfeit
+type ForfeitRequest struct {
+	PlayerID string `json:"playerId" binding:"required"` // loses the match they are playing
+}
+
+// ClientListResponse is returned by GET /admin/clients
+type ClientListResponse struct {
+	Clients []ClientInfo `json:"clients"`
+}
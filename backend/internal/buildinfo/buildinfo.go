@@ -0,0 +1,35 @@
+// Package buildinfo holds the version the server was built as. Release builds
+// set it through the linker:
+//
+//	go build -ldflags "-X tower-defense/internal/buildinfo.Version=v1.4.0 \
+//	  -X tower-defense/internal/buildinfo.Commit=$(git rev-parse --short HEAD)" ./cmd/server
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version = "dev"
+	Commit  = "" // falls back to the VCS revision Go stamped into the binary, if any
+)
+
+// GoVersion is the Go release the binary was built with
+var GoVersion = runtime.Version()
+
+func init() {
+	if Commit != "" {
+		return
+	}
+	Commit = "unknown"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			Commit = s.Value[:min(len(s.Value), 12)]
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package cluster lets several server instances share games.
+//
+// Each game is owned by exactly one node, recorded in a RoomTable with a TTL that
+// the owner keeps refreshing. The owner runs the simulation; WS messages it
+// broadcasts are published on a Bridge so every other node can relay them to its
+// own clients, and commands received by non-owners are forwarded to the owner.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Bridge carries messages between server instances
+type Bridge interface {
+	Publish(ctx context.Context, channel string, data []byte) error
+	// Subscribe calls handler for every message on channel until ctx is cancelled
+	Subscribe(ctx context.Context, channel string, handler func(data []byte)) error
+	Close() error
+}
+
+// RoomTable records which node owns each game
+type RoomTable interface {
+	// Claim makes nodeID the owner of gameID unless another node holds it, and
+	// refreshes the TTL when nodeID already owns it. It returns the current owner.
+	Claim(ctx context.Context, gameID, nodeID string, ttl time.Duration) (string, error)
+	// Release gives up ownership if nodeID is the owner
+	Release(ctx context.Context, gameID, nodeID string) error
+	// Owner returns the owning node, or "" if the game is unowned
+	Owner(ctx context.Context, gameID string) (string, error)
+}
+
+var (
+	ErrNoOwner        = errors.New("game has no owner node")
+	ErrCommandTimeout = errors.New("owner node did not answer in time")
+)
+
+// Message is a WS broadcast relayed between nodes
+type Message struct {
+	Origin  string          `json:"origin"`
+	Type    string          `json:"type"`
+	GameID  string          `json:"gameId"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Command is a client command forwarded to the node owning the game
+type Command struct {
+	ID       string          `json:"id"`
+	Origin   string          `json:"origin"`
+	GameID   string          `json:"gameId"`
+	Type     string          `json:"type"`
+	PlayerID string          `json:"playerId,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+
+	IdempotencyKey string `json:"idempotencyKey,omitempty"` // scoped to the sender, see game.Game.Idempotent
+
+	// Client and Seq number the sender's commands, see game.Game.CommandProcessed
+	Client string `json:"client,omitempty"`
+	Seq    uint64 `json:"seq,omitempty"`
+}
+
+// CommandResult is the owner's answer to a forwarded Command
+type CommandResult struct {
+	ID      string `json:"id"`
+	OK      bool   `json:"ok"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Tick    uint64 `json:"tick,omitempty"` // tick after which a sequenced command took effect
+}
+
+// Channel names
+const (
+	broadcastChannel  = "td:broadcast"
+	nodeChannelPrefix = "td:node:"
+)
+
+// nodeEnvelope is the payload of a node's private channel
+type nodeEnvelope struct {
+	Command *Command       `json:"command,omitempty"`
+	Result  *CommandResult `json:"result,omitempty"`
+}
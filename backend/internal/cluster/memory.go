@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBridge delivers messages within a single process. It is useful for running
+// several nodes in one binary (tests, demos) and as a no-network default.
+type MemoryBridge struct {
+	mu   sync.RWMutex
+	subs map[string][]*memorySubscription
+}
+
+type memorySubscription struct {
+	handler func(data []byte)
+}
+
+// NewMemoryBridge creates an in-process bridge
+func NewMemoryBridge() *MemoryBridge {
+	return &MemoryBridge{subs: make(map[string][]*memorySubscription)}
+}
+
+func (b *MemoryBridge) Publish(ctx context.Context, channel string, data []byte) error {
+	b.mu.RLock()
+	subs := append([]*memorySubscription(nil), b.subs[channel]...)
+	b.mu.RUnlock()
+	for _, sub := range subs {
+		sub.handler(data)
+	}
+	return nil
+}
+
+func (b *MemoryBridge) Subscribe(ctx context.Context, channel string, handler func(data []byte)) error {
+	sub := &memorySubscription{handler: handler}
+	b.mu.Lock()
+	b.subs[channel] = append(b.subs[channel], sub)
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := b.subs[channel]
+	for i, existing := range list {
+		if existing == sub {
+			b.subs[channel] = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBridge) Close() error { return nil }
+
+// MemoryRoomTable is an in-process RoomTable
+type MemoryRoomTable struct {
+	mu     sync.Mutex
+	owners map[string]memoryLease
+}
+
+type memoryLease struct {
+	node    string
+	expires time.Time
+}
+
+// NewMemoryRoomTable creates an in-process room table
+func NewMemoryRoomTable() *MemoryRoomTable {
+	return &MemoryRoomTable{owners: make(map[string]memoryLease)}
+}
+
+func (t *MemoryRoomTable) Claim(ctx context.Context, gameID, nodeID string, ttl time.Duration) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lease, ok := t.owners[gameID]
+	if !ok || time.Now().After(lease.expires) || lease.node == nodeID {
+		lease = memoryLease{node: nodeID, expires: time.Now().Add(ttl)}
+		t.owners[gameID] = lease
+	}
+	return lease.node, nil
+}
+
+func (t *MemoryRoomTable) Release(ctx context.Context, gameID, nodeID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.owners[gameID].node == nodeID {
+		delete(t.owners, gameID)
+	}
+	return nil
+}
+
+func (t *MemoryRoomTable) Owner(ctx context.Context, gameID string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lease, ok := t.owners[gameID]
+	if !ok || time.Now().After(lease.expires) {
+		return "", nil
+	}
+	return lease.node, nil
+}
@@ -0,0 +1,246 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Node is this server instance's membership in the cluster
+type Node struct {
+	id     string
+	bridge Bridge
+	rooms  RoomTable
+	ttl    time.Duration
+
+	mu          sync.Mutex
+	tracked     map[string]bool // games this node runs whenever it can own them
+	owned       map[string]bool
+	pending     map[string]chan CommandResult
+	onMessage   func(Message)
+	onCommand   func(Command) CommandResult
+	onOwnership func(gameID string, owned bool)
+}
+
+// NewNode creates a node. Leases in the room table expire after ttl unless refreshed.
+func NewNode(id string, bridge Bridge, rooms RoomTable, ttl time.Duration) *Node {
+	return &Node{
+		id:      id,
+		bridge:  bridge,
+		rooms:   rooms,
+		ttl:     ttl,
+		tracked: make(map[string]bool),
+		owned:   make(map[string]bool),
+		pending: make(map[string]chan CommandResult),
+	}
+}
+
+// ID returns the node ID
+func (n *Node) ID() string { return n.id }
+
+// SetMessageHandler receives broadcasts published by other nodes
+func (n *Node) SetMessageHandler(f func(Message)) { n.onMessage = f }
+
+// SetCommandHandler executes commands forwarded by other nodes for games this node owns
+func (n *Node) SetCommandHandler(f func(Command) CommandResult) { n.onCommand = f }
+
+// SetOwnershipHandler is called when the node gains or loses a tracked game
+func (n *Node) SetOwnershipHandler(f func(gameID string, owned bool)) { n.onOwnership = f }
+
+// Track makes the node try to own gameID now and whenever it becomes free.
+// It reports whether the node owns the game right away.
+func (n *Node) Track(ctx context.Context, gameID string) (bool, error) {
+	n.mu.Lock()
+	n.tracked[gameID] = true
+	n.mu.Unlock()
+
+	owner, err := n.rooms.Claim(ctx, gameID, n.id, n.ttl)
+	if err != nil {
+		return false, err
+	}
+	n.mu.Lock()
+	n.owned[gameID] = owner == n.id
+	n.mu.Unlock()
+	logging.Infow("cluster_room_claimed", "game_id", gameID, "node_id", n.id, "owner", owner)
+	return owner == n.id, nil
+}
+
+// Owns reports whether this node currently runs gameID
+func (n *Node) Owns(gameID string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.owned[gameID]
+}
+
+// Run subscribes to the bridge and keeps leases alive until ctx is cancelled.
+// Owned games are released on exit so another node can take over immediately.
+func (n *Node) Run(ctx context.Context) {
+	go n.subscribe(ctx, broadcastChannel, n.handleBroadcast)
+	go n.subscribe(ctx, nodeChannelPrefix+n.id, n.handleNodeMessage)
+
+	ticker := time.NewTicker(n.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			n.releaseAll()
+			return
+		case <-ticker.C:
+			n.refreshLeases(ctx)
+		}
+	}
+}
+
+// subscribe keeps a subscription open, retrying after bridge errors
+func (n *Node) subscribe(ctx context.Context, channel string, handler func([]byte)) {
+	for ctx.Err() == nil {
+		if err := n.bridge.Subscribe(ctx, channel, handler); err != nil {
+			logging.Warnw("cluster_subscribe_failed", "channel", channel, "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// refreshLeases renews owned games and picks up tracked games that became free
+func (n *Node) refreshLeases(ctx context.Context) {
+	n.mu.Lock()
+	games := make([]string, 0, len(n.tracked))
+	for gameID := range n.tracked {
+		games = append(games, gameID)
+	}
+	n.mu.Unlock()
+
+	for _, gameID := range games {
+		owner, err := n.rooms.Claim(ctx, gameID, n.id, n.ttl)
+		if err != nil {
+			logging.Warnw("cluster_lease_refresh_failed", "game_id", gameID, "error", err)
+			continue
+		}
+		owned := owner == n.id
+		n.mu.Lock()
+		changed := n.owned[gameID] != owned
+		n.owned[gameID] = owned
+		n.mu.Unlock()
+		if changed {
+			logging.Infow("cluster_ownership_changed", "game_id", gameID, "node_id", n.id, "owned", owned)
+			if n.onOwnership != nil {
+				n.onOwnership(gameID, owned)
+			}
+		}
+	}
+}
+
+func (n *Node) releaseAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for gameID, owned := range n.owned {
+		if owned {
+			if err := n.rooms.Release(ctx, gameID, n.id); err != nil {
+				logging.Warnw("cluster_release_failed", "game_id", gameID, "error", err)
+			}
+			n.owned[gameID] = false
+		}
+	}
+}
+
+// Publish relays a WS broadcast of a locally owned game to the other nodes
+func (n *Node) Publish(ctx context.Context, typ, gameID string, payload []byte) error {
+	data, err := json.Marshal(Message{Origin: n.id, Type: typ, GameID: gameID, Payload: payload})
+	if err != nil {
+		return err
+	}
+	return n.bridge.Publish(ctx, broadcastChannel, data)
+}
+
+func (n *Node) handleBroadcast(data []byte) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logging.Warnw("cluster_bad_message", "error", err)
+		return
+	}
+	if msg.Origin == n.id || n.onMessage == nil {
+		return
+	}
+	n.onMessage(msg)
+}
+
+// Forward sends cmd to the node owning its game and waits for the result
+func (n *Node) Forward(ctx context.Context, cmd Command) (CommandResult, error) {
+	owner, err := n.rooms.Owner(ctx, cmd.GameID)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	if owner == "" {
+		return CommandResult{}, ErrNoOwner
+	}
+
+	cmd.ID = uuid.New().String()
+	cmd.Origin = n.id
+	reply := make(chan CommandResult, 1)
+	n.mu.Lock()
+	n.pending[cmd.ID] = reply
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.pending, cmd.ID)
+		n.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(nodeEnvelope{Command: &cmd})
+	if err != nil {
+		return CommandResult{}, err
+	}
+	if err := n.bridge.Publish(ctx, nodeChannelPrefix+owner, data); err != nil {
+		return CommandResult{}, err
+	}
+
+	select {
+	case result := <-reply:
+		return result, nil
+	case <-ctx.Done():
+		return CommandResult{}, ErrCommandTimeout
+	}
+}
+
+func (n *Node) handleNodeMessage(data []byte) {
+	var env nodeEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		logging.Warnw("cluster_bad_message", "error", err)
+		return
+	}
+
+	if env.Result != nil {
+		n.mu.Lock()
+		reply, ok := n.pending[env.Result.ID]
+		n.mu.Unlock()
+		if ok {
+			reply <- *env.Result
+		}
+		return
+	}
+
+	if env.Command != nil && n.onCommand != nil {
+		cmd := *env.Command
+		go func() {
+			result := n.onCommand(cmd)
+			result.ID = cmd.ID
+			data, err := json.Marshal(nodeEnvelope{Result: &result})
+			if err != nil {
+				return
+			}
+			if err := n.bridge.Publish(context.Background(), nodeChannelPrefix+cmd.Origin, data); err != nil {
+				logging.Warnw("cluster_reply_failed", "origin", cmd.Origin, "error", err)
+			}
+		}()
+	}
+}
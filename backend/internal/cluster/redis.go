@@ -0,0 +1,113 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBridge implements Bridge with Redis pub/sub
+type RedisBridge struct {
+	client *redis.Client
+}
+
+// NewRedisBridge connects to the Redis server at url (redis://host:port/db)
+func NewRedisBridge(url string) (*RedisBridge, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisBridge{client: client}, nil
+}
+
+// Rooms returns a RoomTable stored in the same Redis instance
+func (b *RedisBridge) Rooms() *RedisRoomTable {
+	return &RedisRoomTable{client: b.client}
+}
+
+// Ping checks the connection to Redis
+func (b *RedisBridge) Ping(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+func (b *RedisBridge) Publish(ctx context.Context, channel string, data []byte) error {
+	return b.client.Publish(ctx, channel, data).Err()
+}
+
+func (b *RedisBridge) Subscribe(ctx context.Context, channel string, handler func(data []byte)) error {
+	sub := b.client.Subscribe(ctx, channel)
+	defer sub.Close()
+	// wait for the subscription to be confirmed so no early messages are missed
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			handler([]byte(msg.Payload))
+		}
+	}
+}
+
+func (b *RedisBridge) Close() error {
+	return b.client.Close()
+}
+
+// RedisRoomTable stores room owners as "td:room:<gameID>" keys with a TTL
+type RedisRoomTable struct {
+	client *redis.Client
+}
+
+const roomKeyPrefix = "td:room:"
+
+// claimScript sets the owner if the key is free and refreshes the TTL if the caller owns it
+var claimScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if not owner then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return ARGV[1]
+end
+if owner == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return owner
+`)
+
+// releaseScript deletes the key only if the caller owns it
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (t *RedisRoomTable) Claim(ctx context.Context, gameID, nodeID string, ttl time.Duration) (string, error) {
+	return claimScript.Run(ctx, t.client, []string{roomKeyPrefix + gameID}, nodeID, ttl.Milliseconds()).Text()
+}
+
+func (t *RedisRoomTable) Release(ctx context.Context, gameID, nodeID string) error {
+	return releaseScript.Run(ctx, t.client, []string{roomKeyPrefix + gameID}, nodeID).Err()
+}
+
+func (t *RedisRoomTable) Owner(ctx context.Context, gameID string) (string, error) {
+	owner, err := t.client.Get(ctx, roomKeyPrefix+gameID).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return owner, err
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 // Config holds runtime configuration for the server
@@ -13,11 +16,129 @@ type Config struct {
 	AllowedOrigins []string // CORS/WS allowed origins; ["*"] to allow all
 	EnablePprof    bool     // enable /debug/pprof endpoints
 	LogLevel       string   // debug, info, warn, error
+	ConfigDir      string   // optional directory with balance.yaml/maps.yaml overrides (watched for changes)
+	AdminToken     string   // bearer token for /api/v1/admin; admin API disabled when empty
+	RateLimit      float64  // HTTP requests/second per IP and per player on mutating endpoints, 0 = off
+	RateBurst      int      // HTTP burst size
+	TrustedProxies []string // proxies whose X-Forwarded-For gives the client IP; none = the peer address is the client
+
+	RoomQuotaPerIP     int     // rooms one client IP may create per RoomQuotaWindowS, 0 = no limit
+	RoomQuotaPerPlayer int     // rooms one player ID may create per RoomQuotaWindowS, 0 = no limit
+	RoomQuotaWindowS   float64 // sliding window of the room quotas
+	MaxRooms           int     // rooms the server holds at most, the default game included, 0 = no limit
+	RoomChallengeAfter int     // rooms per window after which creating one needs an X-Room-Token, 0 = never
+	RoomTokens         string  // comma separated tokens accepted as X-Room-Token
+	RoomCaptchaURL     string  // siteverify endpoint of a CAPTCHA provider checking X-Room-Token
+	RoomCaptchaSecret  string  // secret key for RoomCaptchaURL
+
+	WSCommandRate  float64  // inbound WS messages/second per connection, 0 = off
+	WSCommandBurst int      // WS burst size
+	WSStallMs      int      // ms a WS client may fall behind, skipping messages, before it is dropped
+	WSHeartbeatMs  int      // interval of WS heartbeats measuring client latency, 0 = off
+	WSKeyframeMs   int      // interval of full-detail keyframe snapshots to every WS client, 0 = off
+	ShareLatency   bool     // send each room the latency of its players
+	CommandMinGap  int      // minimum ms between game commands from one client (anti-cheat), 0 = off
+	RedisURL       string   // optional Redis for the multi-instance pub/sub bridge
+	NodeID         string   // this instance's ID in the cluster
+	GRPCPort       string   // listen address of the gRPC API, "" = off
+	TickBudgetMs   int      // time one engine tick may take, 0 = the tick interval
+	OverloadTicks  int      // consecutive ticks over budget before a game counts as overloaded
+	OverloadCap    int      // live enemies allowed while overloaded, 0 = spawn normally
+	SlowBroadcast  bool     // halve the state broadcast rate of rooms while their game is overloaded
+	MaxEnemies     int      // live enemies per game; wave spawns wait beyond it, 0 = no limit
+	MaxProjectiles int      // projectiles in flight per game; shots land at once beyond it, 0 = no limit
+	MemoryBudgetMB float64  // approximate memory all rooms may hold before new rooms are refused, 0 = no budget
+	CrashDir       string   // directory for crash reports of game loops, "" = keep them in memory
+	SaveDir        string   // directory for game saves and end-of-game summaries, "" = keep them in memory
+	SQLitePath     string   // single-file store for saves, summaries, player stats, achievements and research; overrides SaveDir, "" = off
+
+	ShutdownSaveMs int  // time allowed for saving running games on shutdown
+	RestoreOnStart bool // resume the games saved at the last shutdown
+
+	SaveMaxPerGame        int     // player saves kept per game, 0 = unlimited
+	SaveMaxBytesPerPlayer int64   // stored bytes of player saves kept per player, 0 = unlimited
+	SaveMaxAgeHours       float64 // age after which player saves are evicted, 0 = never
+
+	AuditLogSize int    // state-mutating requests kept in memory for GET /admin/audit
+	AuditDir     string // directory every audit entry is also written to, "" = memory only
+
+	RoomIdleTimeoutS float64 // seconds a room other than the default one may go without WS clients before it is removed, 0 = never
+	RoomFinishedS    float64 // seconds a room other than the default one is kept after its game ended, 0 = until idle
+
+	MatchInactivityS float64 // seconds a player may go without acting in a tournament match before forfeiting it, 0 = never
+
+	WebhookURL         string // receives game_created, game_over and high_score webhooks, "" = off
+	WebhookSecret      string // HMAC key signing webhooks, "" = unsigned
+	WebhookMaxAttempts int    // tries per webhook before it goes to the dead-letter log
+	WebhookDeadLetters string // JSON lines file of failed webhooks, "" = memory only
+	WebhookRoomURLs    bool   // let rooms choose their own webhook URL at creation
+
+	DiscordWebhookURL    string   // Discord channel announcing rooms and results, "" = off
+	SlackWebhookURL      string   // Slack channel announcing rooms and results, "" = off
+	IntegrationEvents    []string // webhook events announced in chat, nil = all
+	IntegrationTemplates string   // YAML file of message templates by event, "" = built-in ones
+	IntegrationJoinURL   string   // link to a room in announcements, "{gameId}" replaced by its ID
+
+	FeatureFlags     string // comma separated "name=bool" overrides of feature flags
+	FeatureFlagsFile string // YAML file of feature flag overrides, "" = none
+
+	AnalyticsSink        string // where gameplay analytics go: "file", "http" or "kafka"; "" = off
+	AnalyticsTarget      string // path of the file sink, URL of the HTTP endpoint or of the Kafka REST Proxy
+	AnalyticsTopic       string // Kafka topic, "" = td-analytics
+	AnalyticsSampleRates string // comma separated "kind=rate" shares of events shipped, "" = all
+	AnalyticsBatchSize   int    // events per write to the sink
+	AnalyticsFlushMs     int    // longest an event waits for its batch to fill
+	AnalyticsQueueSize   int    // events waiting for the sink before new ones are dropped
+
+	EventStreamBroker string   // broker game events are published to: "nats" or "kafka"; "" = off
+	EventStreamURL    string   // nats://host:port of NATS, or the URL of a Kafka REST Proxy (v2 API); Kafka brokers aren't dialed directly
+	EventStreamTopic  string   // NATS subject prefix or Kafka topic, "" = td.events / td-events
+	EventStreamEvents []string // event types published, nil = all
+	EventStreamOutbox string   // directory keeping batches the broker didn't take, "" = retry in memory
 }
 
 // FromEnv loads configuration from environment variables with sensible defaults.
 // PORT: string, default "8080"
 // ALLOWED_ORIGIN: string, default "*"
+// CONFIG_DIR: string, default "" (embedded game config only)
+// ADMIN_TOKEN: string, default "" (admin API disabled)
+// RATE_LIMIT_RPS / RATE_LIMIT_BURST: default 5 / 10
+// TRUSTED_PROXIES: comma separated IPs or CIDRs, default "" (X-Forwarded-For ignored)
+// ROOM_QUOTA_PER_IP / ROOM_QUOTA_PER_PLAYER / ROOM_QUOTA_WINDOW_S: default 20 / 10 / 3600
+// MAX_ROOMS: default 200, 0 = no limit; ROOM_CHALLENGE_AFTER: default 0 (never)
+// ROOM_TOKENS / ROOM_CAPTCHA_VERIFY_URL / ROOM_CAPTCHA_SECRET: default "" (none)
+// WS_COMMAND_RPS / WS_COMMAND_BURST: default 10 / 20
+// WS_STALL_TIMEOUT_MS: default 10000, 0 = drop slow clients at once
+// WS_HEARTBEAT_MS: default 5000, 0 = off; WS_SHARE_LATENCY: default false
+// COMMAND_MIN_INTERVAL_MS: default 50
+// REDIS_URL: string, default "" (single instance)
+// NODE_ID: string, default hostname plus a random suffix
+// GRPC_PORT: string, default "" (gRPC API off)
+// TICK_BUDGET_MS / OVERLOAD_TICKS: default 0 (tick interval) / 10
+// OVERLOAD_ENEMY_CAP: default 0 (off); OVERLOAD_SLOW_BROADCAST: default true
+// MAX_ENEMIES / MAX_PROJECTILES: default 1000 / 2000 per game, 0 = no limit
+// MEMORY_BUDGET_MB: default 0 (no budget)
+// CRASH_DIR: string, default "" (crash reports kept in memory)
+// SAVE_DIR: string, default "" (saves and summaries kept in memory)
+// SQLITE_PATH: string, default "" (no SQLite store)
+// SHUTDOWN_SAVE_TIMEOUT_MS: default 5000; RESTORE_ON_START: default false
+// SAVE_MAX_PER_GAME / SAVE_MAX_BYTES_PER_PLAYER / SAVE_MAX_AGE_HOURS: player save retention, default 0 (keep everything)
+// AUDIT_LOG_SIZE: default 1000; AUDIT_DIR: string, default "" (audit log kept in memory only)
+// ROOM_IDLE_TIMEOUT_S: default 600, 0 = rooms are never removed
+// ROOM_FINISHED_GRACE_S: default 300, 0 = finished rooms are removed once idle
+// WEBHOOK_URL / WEBHOOK_SECRET: string, default "" (no global webhook, unsigned)
+// WEBHOOK_MAX_ATTEMPTS: default 5; WEBHOOK_DEAD_LETTER_PATH: string, default "" (memory only)
+// WEBHOOK_ROOM_URLS: default false (rooms can't choose a webhook URL)
+// DISCORD_WEBHOOK_URL / SLACK_WEBHOOK_URL: string, default "" (no chat announcements)
+// INTEGRATION_EVENTS: comma separated, default "" (game_created, game_over and high_score)
+// INTEGRATION_TEMPLATES: string, default "" (built-in messages); INTEGRATION_JOIN_URL: string, default "" (no join link)
+// FEATURE_FLAGS: e.g. "parallel_systems=false", default "" (flag defaults); FEATURE_FLAGS_FILE: string, default ""
+// ANALYTICS_SINK: file, http or kafka, default "" (off); ANALYTICS_TARGET: path or URL; ANALYTICS_TOPIC: default "td-analytics"
+// ANALYTICS_SAMPLE_RATES: e.g. "enemy_killed=0.1", default "" (all events)
+// ANALYTICS_BATCH_SIZE / ANALYTICS_FLUSH_MS / ANALYTICS_QUEUE_SIZE: default 100 / 5000 / 10000
+// EVENT_STREAM_BROKER: nats or kafka, default "" (off); EVENT_STREAM_TOPIC: default by broker
+// EVENT_STREAM_URL: nats:// or tls:// URL of NATS; for kafka the http(s) URL of a Kafka REST Proxy, not a broker address
+// EVENT_STREAM_EVENTS: comma separated, default "" (all); EVENT_STREAM_OUTBOX_DIR: string, default "" (retry in memory)
 func FromEnv() Config {
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -46,11 +167,211 @@ func FromEnv() Config {
 	}
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel == "" { logLevel = "info" }
-	log.Printf("Config: PORT=%s ALLOWED_ORIGINS=%v ENABLE_PPROF=%v LOG_LEVEL=%s", port, allowed, enablePprof, logLevel)
+	configDir := os.Getenv("CONFIG_DIR")
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	rateLimit := envFloat("RATE_LIMIT_RPS", 5)
+	rateBurst := int(envFloat("RATE_LIMIT_BURST", 10))
+	var trustedProxies []string
+	for _, v := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			trustedProxies = append(trustedProxies, v)
+		}
+	}
+	roomQuotaPerIP := int(envFloat("ROOM_QUOTA_PER_IP", 20))
+	roomQuotaPerPlayer := int(envFloat("ROOM_QUOTA_PER_PLAYER", 10))
+	roomQuotaWindowS := envFloat("ROOM_QUOTA_WINDOW_S", 3600)
+	maxRooms := int(envFloat("MAX_ROOMS", 200))
+	roomChallengeAfter := int(envFloat("ROOM_CHALLENGE_AFTER", 0))
+	roomTokens := os.Getenv("ROOM_TOKENS")
+	roomCaptchaURL := os.Getenv("ROOM_CAPTCHA_VERIFY_URL")
+	roomCaptchaSecret := os.Getenv("ROOM_CAPTCHA_SECRET")
+	wsCommandRate := envFloat("WS_COMMAND_RPS", 10)
+	wsCommandBurst := int(envFloat("WS_COMMAND_BURST", 20))
+	wsStallMs := int(envFloat("WS_STALL_TIMEOUT_MS", 10000))
+	wsHeartbeatMs := int(envFloat("WS_HEARTBEAT_MS", 5000))
+	wsKeyframeMs := int(envFloat("WS_KEYFRAME_MS", 1000))
+	shareLatency := false
+	if v := os.Getenv("WS_SHARE_LATENCY"); v == "1" || v == "true" || v == "TRUE" {
+		shareLatency = true
+	}
+	commandMinGap := int(envFloat("COMMAND_MIN_INTERVAL_MS", 50))
+	redisURL := os.Getenv("REDIS_URL")
+	nodeID := os.Getenv("NODE_ID")
+	if nodeID == "" {
+		host, _ := os.Hostname()
+		nodeID = host + "-" + uuid.NewString()[:6]
+	}
+	tickBudgetMs := int(envFloat("TICK_BUDGET_MS", 0))
+	overloadTicks := int(envFloat("OVERLOAD_TICKS", 10))
+	overloadCap := int(envFloat("OVERLOAD_ENEMY_CAP", 0))
+	slowBroadcast := true
+	if v := os.Getenv("OVERLOAD_SLOW_BROADCAST"); v == "0" || v == "false" || v == "FALSE" {
+		slowBroadcast = false
+	}
+	maxEnemies := int(envFloat("MAX_ENEMIES", 1000))
+	maxProjectiles := int(envFloat("MAX_PROJECTILES", 2000))
+	memoryBudgetMB := envFloat("MEMORY_BUDGET_MB", 0)
+	crashDir := os.Getenv("CRASH_DIR")
+	saveDir := os.Getenv("SAVE_DIR")
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	shutdownSaveMs := int(envFloat("SHUTDOWN_SAVE_TIMEOUT_MS", 5000))
+	restoreOnStart := false
+	if v := os.Getenv("RESTORE_ON_START"); v == "1" || v == "true" || v == "TRUE" {
+		restoreOnStart = true
+	}
+	saveMaxPerGame := int(envFloat("SAVE_MAX_PER_GAME", 0))
+	saveMaxBytes := int64(envFloat("SAVE_MAX_BYTES_PER_PLAYER", 0))
+	saveMaxAgeHours := envFloat("SAVE_MAX_AGE_HOURS", 0)
+	auditLogSize := int(envFloat("AUDIT_LOG_SIZE", 1000))
+	auditDir := os.Getenv("AUDIT_DIR")
+	roomIdleTimeoutS := envFloat("ROOM_IDLE_TIMEOUT_S", 600)
+	roomFinishedS := envFloat("ROOM_FINISHED_GRACE_S", 300)
+	matchInactivityS := envFloat("MATCH_INACTIVITY_TIMEOUT_S", 300)
+	webhookURL := os.Getenv("WEBHOOK_URL")
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
+	webhookMaxAttempts := int(envFloat("WEBHOOK_MAX_ATTEMPTS", 5))
+	webhookDeadLetters := os.Getenv("WEBHOOK_DEAD_LETTER_PATH")
+	webhookRoomURLs := false
+	if v := os.Getenv("WEBHOOK_ROOM_URLS"); v == "1" || v == "true" || v == "TRUE" {
+		webhookRoomURLs = true
+	}
+	discordURL := os.Getenv("DISCORD_WEBHOOK_URL")
+	slackURL := os.Getenv("SLACK_WEBHOOK_URL")
+	var integrationEvents []string
+	for _, v := range strings.Split(os.Getenv("INTEGRATION_EVENTS"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			integrationEvents = append(integrationEvents, v)
+		}
+	}
+	integrationTemplates := os.Getenv("INTEGRATION_TEMPLATES")
+	integrationJoinURL := os.Getenv("INTEGRATION_JOIN_URL")
+	featureFlags := os.Getenv("FEATURE_FLAGS")
+	featureFlagsFile := os.Getenv("FEATURE_FLAGS_FILE")
+	analyticsSink := os.Getenv("ANALYTICS_SINK")
+	analyticsTarget := os.Getenv("ANALYTICS_TARGET")
+	analyticsTopic := os.Getenv("ANALYTICS_TOPIC")
+	analyticsSampleRates := os.Getenv("ANALYTICS_SAMPLE_RATES")
+	analyticsBatchSize := int(envFloat("ANALYTICS_BATCH_SIZE", 100))
+	analyticsFlushMs := int(envFloat("ANALYTICS_FLUSH_MS", 5000))
+	analyticsQueueSize := int(envFloat("ANALYTICS_QUEUE_SIZE", 10000))
+	eventStreamBroker := os.Getenv("EVENT_STREAM_BROKER")
+	eventStreamURL := os.Getenv("EVENT_STREAM_URL")
+	eventStreamTopic := os.Getenv("EVENT_STREAM_TOPIC")
+	var eventStreamEvents []string
+	for _, v := range strings.Split(os.Getenv("EVENT_STREAM_EVENTS"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			eventStreamEvents = append(eventStreamEvents, v)
+		}
+	}
+	eventStreamOutbox := os.Getenv("EVENT_STREAM_OUTBOX_DIR")
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort != "" {
+		grpcPort = ":" + grpcPort
+	}
+	log.Printf("Config: PORT=%s ALLOWED_ORIGINS=%v ENABLE_PPROF=%v LOG_LEVEL=%s CONFIG_DIR=%s ADMIN_API=%v RATE_LIMIT=%v/%d TRUSTED_PROXIES=%v ROOM_QUOTA=%d/%d/%vs MAX_ROOMS=%d ROOM_CHALLENGE_AFTER=%d ROOM_TOKENS=%v ROOM_CAPTCHA_VERIFY_URL=%s WS_COMMAND_RATE=%v/%d WS_STALL_TIMEOUT_MS=%d WS_HEARTBEAT_MS=%d WS_KEYFRAME_MS=%d WS_SHARE_LATENCY=%v COMMAND_MIN_INTERVAL_MS=%d CLUSTER=%v NODE_ID=%s GRPC_PORT=%s TICK_BUDGET_MS=%d OVERLOAD_TICKS=%d OVERLOAD_ENEMY_CAP=%d OVERLOAD_SLOW_BROADCAST=%v MAX_ENEMIES=%d MAX_PROJECTILES=%d MEMORY_BUDGET_MB=%v CRASH_DIR=%s SAVE_DIR=%s SQLITE_PATH=%s SHUTDOWN_SAVE_TIMEOUT_MS=%d RESTORE_ON_START=%v SAVE_RETENTION=%d/%d/%vh AUDIT_LOG_SIZE=%d AUDIT_DIR=%s ROOM_IDLE_TIMEOUT_S=%v ROOM_FINISHED_GRACE_S=%v MATCH_INACTIVITY_TIMEOUT_S=%v WEBHOOK=%v WEBHOOK_SIGNED=%v WEBHOOK_MAX_ATTEMPTS=%d WEBHOOK_ROOM_URLS=%v DISCORD=%v SLACK=%v INTEGRATION_EVENTS=%v FEATURE_FLAGS=%s FEATURE_FLAGS_FILE=%s ANALYTICS_SINK=%s ANALYTICS_SAMPLE_RATES=%s ANALYTICS_BATCH_SIZE=%d ANALYTICS_FLUSH_MS=%d ANALYTICS_QUEUE_SIZE=%d EVENT_STREAM_BROKER=%s EVENT_STREAM_TOPIC=%s EVENT_STREAM_EVENTS=%v EVENT_STREAM_OUTBOX_DIR=%s",
+		port, allowed, enablePprof, logLevel, configDir, adminToken != "", rateLimit, rateBurst, trustedProxies, roomQuotaPerIP, roomQuotaPerPlayer, roomQuotaWindowS, maxRooms, roomChallengeAfter, roomTokens != "", roomCaptchaURL, wsCommandRate, wsCommandBurst, wsStallMs, wsHeartbeatMs, wsKeyframeMs, shareLatency, commandMinGap, redisURL != "", nodeID, grpcPort,
+		tickBudgetMs, overloadTicks, overloadCap, slowBroadcast, maxEnemies, maxProjectiles, memoryBudgetMB, crashDir, saveDir, sqlitePath, shutdownSaveMs, restoreOnStart, saveMaxPerGame, saveMaxBytes, saveMaxAgeHours, auditLogSize, auditDir, roomIdleTimeoutS, roomFinishedS, matchInactivityS,
+		webhookURL != "", webhookSecret != "", webhookMaxAttempts, webhookRoomURLs,
+		discordURL != "", slackURL != "", integrationEvents, featureFlags, featureFlagsFile,
+		analyticsSink, analyticsSampleRates, analyticsBatchSize, analyticsFlushMs, analyticsQueueSize,
+		eventStreamBroker, eventStreamTopic, eventStreamEvents, eventStreamOutbox)
 	return Config{
 		Port:           ":" + port,
 		AllowedOrigins: allowed,
 		EnablePprof:    enablePprof,
 		LogLevel:       logLevel,
+		ConfigDir:      configDir,
+		AdminToken:     adminToken,
+		RateLimit:      rateLimit,
+		RateBurst:      rateBurst,
+		TrustedProxies: trustedProxies,
+
+		RoomQuotaPerIP:     roomQuotaPerIP,
+		RoomQuotaPerPlayer: roomQuotaPerPlayer,
+		RoomQuotaWindowS:   roomQuotaWindowS,
+		MaxRooms:           maxRooms,
+		RoomChallengeAfter: roomChallengeAfter,
+		RoomTokens:         roomTokens,
+		RoomCaptchaURL:     roomCaptchaURL,
+		RoomCaptchaSecret:  roomCaptchaSecret,
+
+		WSCommandRate:  wsCommandRate,
+		WSCommandBurst: wsCommandBurst,
+		WSStallMs:      wsStallMs,
+		WSHeartbeatMs:  wsHeartbeatMs,
+		WSKeyframeMs:   wsKeyframeMs,
+		ShareLatency:   shareLatency,
+		CommandMinGap:  commandMinGap,
+		RedisURL:       redisURL,
+		NodeID:         nodeID,
+		GRPCPort:       grpcPort,
+		TickBudgetMs:   tickBudgetMs,
+		OverloadTicks:  overloadTicks,
+		OverloadCap:    overloadCap,
+		SlowBroadcast:  slowBroadcast,
+		MaxEnemies:     maxEnemies,
+		MaxProjectiles: maxProjectiles,
+		MemoryBudgetMB: memoryBudgetMB,
+		CrashDir:       crashDir,
+		SaveDir:        saveDir,
+		SQLitePath:     sqlitePath,
+
+		ShutdownSaveMs: shutdownSaveMs,
+		RestoreOnStart: restoreOnStart,
+
+		SaveMaxPerGame:        saveMaxPerGame,
+		SaveMaxBytesPerPlayer: saveMaxBytes,
+		SaveMaxAgeHours:       saveMaxAgeHours,
+
+		AuditLogSize: auditLogSize,
+		AuditDir:     auditDir,
+
+		RoomIdleTimeoutS: roomIdleTimeoutS,
+		RoomFinishedS:    roomFinishedS,
+
+		MatchInactivityS: matchInactivityS,
+
+		WebhookURL:         webhookURL,
+		WebhookSecret:      webhookSecret,
+		WebhookMaxAttempts: webhookMaxAttempts,
+		WebhookDeadLetters: webhookDeadLetters,
+		WebhookRoomURLs:    webhookRoomURLs,
+
+		DiscordWebhookURL:    discordURL,
+		SlackWebhookURL:      slackURL,
+		IntegrationEvents:    integrationEvents,
+		IntegrationTemplates: integrationTemplates,
+		IntegrationJoinURL:   integrationJoinURL,
+
+		FeatureFlags:     featureFlags,
+		FeatureFlagsFile: featureFlagsFile,
+
+		AnalyticsSink:        analyticsSink,
+		AnalyticsTarget:      analyticsTarget,
+		AnalyticsTopic:       analyticsTopic,
+		AnalyticsSampleRates: analyticsSampleRates,
+		AnalyticsBatchSize:   analyticsBatchSize,
+		AnalyticsFlushMs:     analyticsFlushMs,
+		AnalyticsQueueSize:   analyticsQueueSize,
+
+		EventStreamBroker: eventStreamBroker,
+		EventStreamURL:    eventStreamURL,
+		EventStreamTopic:  eventStreamTopic,
+		EventStreamEvents: eventStreamEvents,
+		EventStreamOutbox: eventStreamOutbox,
+	}
+}
+
+// envFloat parses a numeric env var, falling back to def when unset or invalid
+func envFloat(name string, def float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("Config: invalid %s=%q, using %v", name, v, def)
+		return def
 	}
+	return f
 }
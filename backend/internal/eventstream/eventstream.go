@@ -0,0 +1,332 @@
+// Package eventstream publishes the gameplay events of every game to a
+// message broker, NATS JetStream or Kafka, so outside services such as stats,
+// anti-cheat or ML pipelines can consume game data as it happens.
+// Delivery is at least once: a batch is retried until the broker confirms
+// it, and with an outbox, batches the broker doesn't take are kept there and
+// published in order once it is back, also across restarts. Consumers drop
+// repeated envelope IDs.
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Schema and version of the envelopes. The version is bumped when a field
+// changes meaning or goes away; new fields may appear within a version.
+const (
+	Schema        = "td.game_event"
+	SchemaVersion = 1
+)
+
+// Defaults of Options
+const (
+	DefaultBatchSize   = 100
+	DefaultLinger      = 100 * time.Millisecond
+	DefaultQueueSize   = 10000
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = time.Second
+	maxBackoff         = 30 * time.Second
+	closeTimeout       = 5 * time.Second // for the last batches on Close
+	outboxKey          = "eventstream-outbox"
+)
+
+// Results of messages, see Options.OnResult
+const (
+	ResultPublished = "published"
+	ResultRetried   = "retried"
+	ResultSpilled   = "spilled" // kept in the outbox for later
+	ResultDropped   = "dropped" // the queue was full
+	ResultFailed    = "failed"  // lost: neither published nor kept
+)
+
+// Options configure a Stream
+type Options struct {
+	Publisher   Publisher
+	NodeID      string        // this instance, in every envelope
+	Events      []events.Type // kinds published, nil = all
+	BatchSize   int           // messages per publish, 0 = DefaultBatchSize
+	Linger      time.Duration // longest a message waits for its batch to fill, 0 = DefaultLinger
+	QueueSize   int           // messages waiting for the worker before new ones are dropped, 0 = DefaultQueueSize
+	MaxAttempts int           // tries of a batch before it goes to the outbox, 0 = DefaultMaxAttempts
+	Backoff     time.Duration // wait before the first retry, doubled after each one; 0 = DefaultBackoff
+
+	// Outbox keeps the batches the broker didn't take until it does; nil =
+	// retry them in memory while new messages queue up behind them
+	Outbox repository.Repository
+
+	OnResult func(result string, n int) // called with the outcome of every n messages, e.g. for metrics
+}
+
+// Envelope is the schema-versioned payload of every message
+type Envelope struct {
+	Schema  string       `json:"schema"`
+	Version int          `json:"version"`
+	ID      string       `json:"id"`   // the same for every delivery of the event
+	Node    string       `json:"node"` // instance that ran the game
+	Seq     uint64       `json:"seq"`  // per node, increasing in the order the events happened
+	Event   events.Event `json:"event"`
+}
+
+// Message is an envelope ready for the broker
+type Message struct {
+	ID     string          `json:"id"`
+	GameID string          `json:"gameId"`
+	Type   events.Type     `json:"type"`
+	Body   json.RawMessage `json:"body"` // the Envelope as JSON
+}
+
+// Publisher hands batches of messages to a broker; a nil error means the
+// broker has stored all of them
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, batch []Message) error
+	Close() error
+}
+
+// Stream turns game events into messages and publishes them in batches on a
+// background worker, so HandleEvent never blocks the game
+type Stream struct {
+	opts   Options
+	filter map[events.Type]bool // nil = all
+	queue  chan Message
+	seq    atomic.Uint64
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{} // closed by Close
+	exited chan struct{} // closed by the worker
+	closed atomic.Bool
+
+	closeOnce sync.Once
+
+	// Worker state
+	backlog   int // batches in the outbox
+	nextDrain time.Time
+	drainWait time.Duration
+	closing   bool
+}
+
+// New starts a stream publishing to opts.Publisher. Batches left in the
+// outbox by an earlier run are published before new ones.
+func New(opts Options) *Stream {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Linger <= 0 {
+		opts.Linger = DefaultLinger
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Stream{
+		opts:      opts,
+		queue:     make(chan Message, opts.QueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+		drainWait: opts.Backoff,
+	}
+	if opts.Events != nil {
+		s.filter = make(map[events.Type]bool, len(opts.Events))
+		for _, t := range opts.Events {
+			s.filter[t] = true
+		}
+	}
+	if opts.Outbox != nil {
+		if saves, err := opts.Outbox.List(outboxKey); err == nil && len(saves) > 0 {
+			s.backlog = len(saves)
+			logging.Infow("eventstream_outbox_found", "batches", s.backlog)
+		}
+	}
+	go s.work()
+	return s
+}
+
+// HandleEvent is a game event listener that queues every event of Options.Events
+func (s *Stream) HandleEvent(ev events.Event) {
+	if s.closed.Load() || (s.filter != nil && !s.filter[ev.Type]) {
+		return
+	}
+	env := Envelope{Schema: Schema, Version: SchemaVersion, ID: uuid.NewString(), Node: s.opts.NodeID, Seq: s.seq.Add(1), Event: ev}
+	body, err := json.Marshal(env)
+	if err != nil {
+		s.report(ResultFailed, 1)
+		return
+	}
+	select {
+	case s.queue <- Message{ID: env.ID, GameID: ev.GameID, Type: ev.Type, Body: body}:
+	default:
+		s.report(ResultDropped, 1)
+	}
+}
+
+// Close stops taking events and publishes those still queued; what the broker
+// doesn't take within a few seconds goes to the outbox
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		s.closed.Store(true)
+		timer := time.AfterFunc(closeTimeout, s.cancel)
+		defer timer.Stop()
+		close(s.done)
+		<-s.exited
+		s.cancel()
+		if err := s.opts.Publisher.Close(); err != nil {
+			logging.Warnw("eventstream_close_failed", "broker", s.opts.Publisher.Name(), "error", err)
+		}
+	})
+}
+
+// work collects batches and publishes them, a full batch at once and a
+// partial one after Linger, and retries the outbox while it has any
+func (s *Stream) work() {
+	defer close(s.exited)
+	ticker := time.NewTicker(s.opts.Linger)
+	defer ticker.Stop()
+
+	batch := make([]Message, 0, s.opts.BatchSize)
+	for {
+		select {
+		case m := <-s.queue:
+			if batch = append(batch, m); len(batch) >= s.opts.BatchSize {
+				batch = s.deliver(batch)
+			}
+		case <-ticker.C:
+			if s.backlog > 0 && !time.Now().Before(s.nextDrain) {
+				s.drainOutbox()
+			}
+			batch = s.deliver(batch)
+		case <-s.done:
+			s.closing = true
+			for {
+				select {
+				case m := <-s.queue:
+					if batch = append(batch, m); len(batch) >= s.opts.BatchSize {
+						batch = s.deliver(batch)
+					}
+				default:
+					s.deliver(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver publishes a batch, or puts it in the outbox behind older batches,
+// and returns it emptied for reuse
+func (s *Stream) deliver(batch []Message) []Message {
+	if len(batch) == 0 {
+		return batch
+	}
+	if s.backlog > 0 {
+		s.spill(batch)
+		return batch[:0]
+	}
+	attempts := 0 // until the broker takes it, with nowhere else to keep it
+	if s.opts.Outbox != nil || s.closing {
+		attempts = s.opts.MaxAttempts
+	}
+	if err := s.publish(batch, attempts); err != nil {
+		if s.opts.Outbox != nil {
+			s.spill(batch)
+		} else {
+			s.report(ResultFailed, len(batch))
+			logging.Warnw("eventstream_batch_lost", "broker", s.opts.Publisher.Name(), "messages", len(batch), "error", err)
+		}
+	}
+	return batch[:0]
+}
+
+// publish tries a batch up to attempts times, 0 = until the stream closes,
+// with exponential backoff
+func (s *Stream) publish(batch []Message, attempts int) error {
+	backoff := s.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		err := s.opts.Publisher.Publish(s.ctx, batch)
+		if err == nil {
+			s.report(ResultPublished, len(batch))
+			return nil
+		}
+		if (attempts > 0 && attempt >= attempts) || s.ctx.Err() != nil {
+			return err
+		}
+		s.report(ResultRetried, len(batch))
+		logging.Debugw("eventstream_retry", "broker", s.opts.Publisher.Name(), "attempt", attempt, "error", err)
+		select {
+		case <-s.ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// spill keeps a batch in the outbox
+func (s *Stream) spill(batch []Message) {
+	data, err := json.Marshal(batch)
+	if err == nil {
+		_, err = s.opts.Outbox.Save(outboxKey, data)
+	}
+	if err != nil {
+		s.report(ResultFailed, len(batch))
+		logging.Errorw("eventstream_outbox_write_failed", "messages", len(batch), "error", err)
+		return
+	}
+	if s.backlog == 0 {
+		logging.Warnw("eventstream_outbox_started", "broker", s.opts.Publisher.Name())
+		s.nextDrain = time.Now().Add(s.drainWait)
+	}
+	s.backlog++
+	s.report(ResultSpilled, len(batch))
+}
+
+// drainOutbox publishes the batches of the outbox, oldest first, until the
+// broker fails; then it waits longer before the next try
+func (s *Stream) drainOutbox() {
+	saves, err := s.opts.Outbox.List(outboxKey)
+	if err != nil {
+		logging.Errorw("eventstream_outbox_read_failed", "error", err)
+		return
+	}
+	slices.SortStableFunc(saves, func(a, b *repository.GameSave) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	s.backlog = len(saves)
+	for _, save := range saves {
+		var batch []Message
+		if err := json.Unmarshal(save.Data, &batch); err != nil {
+			logging.Errorw("eventstream_outbox_batch_unreadable", "save_id", save.ID, "error", err)
+		} else if err := s.publish(batch, 1); err != nil {
+			s.drainWait = min(s.drainWait*2, maxBackoff)
+			s.nextDrain = time.Now().Add(s.drainWait)
+			return
+		}
+		if err := s.opts.Outbox.Delete(save.ID); err != nil {
+			logging.Errorw("eventstream_outbox_delete_failed", "save_id", save.ID, "error", err)
+		}
+		s.backlog--
+	}
+	s.drainWait = s.opts.Backoff
+	logging.Infow("eventstream_outbox_drained", "broker", s.opts.Publisher.Name(), "batches", len(saves))
+}
+
+func (s *Stream) report(result string, n int) {
+	if s.opts.OnResult != nil {
+		s.opts.OnResult(result, n)
+	}
+}
@@ -0,0 +1,119 @@
+package eventstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Brokers, see NewPublisher
+const (
+	BrokerNATS  = "nats"
+	BrokerKafka = "kafka"
+)
+
+// DefaultKafkaTopic is the topic of a Kafka publisher without one
+const DefaultKafkaTopic = "td-events"
+
+var (
+	ErrUnknownBroker = errors.New("unknown event stream broker")
+	ErrInvalidURL    = errors.New("invalid event stream broker URL")
+)
+
+// NewPublisher returns the publisher of a broker: for BrokerNATS rawURL is
+// the NATS server and topic the subject prefix, for BrokerKafka rawURL is a
+// Kafka REST Proxy and topic the topic
+func NewPublisher(broker, rawURL, topic string) (Publisher, error) {
+	switch broker {
+	case BrokerNATS:
+		return NewNATS(rawURL, topic)
+	case BrokerKafka:
+		return NewKafka(rawURL, topic)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBroker, broker)
+	}
+}
+
+// kafkaPublisher produces to a topic through the Kafka REST Proxy (v2 API),
+// keyed by game so the events of a game stay in order on one partition.
+// The proxy answers once the brokers have the records.
+type kafkaPublisher struct {
+	url    string
+	topic  string
+	client *http.Client
+}
+
+// NewKafka returns a publisher for the Kafka REST Proxy at rawURL
+func NewKafka(rawURL, topic string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: Kafka needs the http or https URL of a REST Proxy, got %q", ErrInvalidURL, rawURL)
+	}
+	if topic == "" {
+		topic = DefaultKafkaTopic
+	}
+	return &kafkaPublisher{
+		url:    strings.TrimSuffix(rawURL, "/") + "/topics/" + url.PathEscape(topic),
+		topic:  topic,
+		client: &http.Client{Timeout: publishTimeout},
+	}, nil
+}
+
+func (p *kafkaPublisher) Name() string { return BrokerKafka + ":" + p.topic }
+
+func (p *kafkaPublisher) Publish(ctx context.Context, batch []Message) error {
+	type record struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	records := make([]record, len(batch))
+	for i, m := range batch {
+		records[i] = record{Key: m.GameID, Value: m.Body}
+	}
+	body, err := json.Marshal(struct {
+		Records []record `json:"records"`
+	}{records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	// A record the brokers refused fails the batch, to be produced again
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("unreadable REST Proxy response: %w", err)
+	}
+	for _, o := range result.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("record refused with error %d: %s", *o.ErrorCode, o.Error)
+		}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error { return nil }
@@ -0,0 +1,85 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKafkaPublish(t *testing.T) {
+	var path, contentType string
+	var body struct {
+		Records []struct {
+			Key   string          `json:"key"`
+			Value json.RawMessage `json:"value"`
+		} `json:"records"`
+	}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		io.WriteString(w, `{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`)
+	}))
+	defer proxy.Close()
+
+	p, err := NewKafka(proxy.URL+"/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Publish(context.Background(), batch()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/topics/td-events" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("POST %s as %s, want /topics/td-events as application/vnd.kafka.json.v2+json", path, contentType)
+	}
+	if len(body.Records) != 2 {
+		t.Fatalf("%d records, want 2", len(body.Records))
+	}
+	for i, m := range batch() {
+		if r := body.Records[i]; r.Key != m.GameID || string(r.Value) != string(m.Body) {
+			t.Errorf("record %d = key %q value %s, want key %q value %s", i, r.Key, r.Value, m.GameID, m.Body)
+		}
+	}
+}
+
+func TestKafkaPublishFailures(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+		want   string
+	}{
+		"proxy error":    {http.StatusInternalServerError, `{"error_code":50002,"message":"Kafka error"}`, "status 500"},
+		"no such topic":  {http.StatusNotFound, `{"error_code":40401,"message":"Topic not found"}`, "status 404"},
+		"refused record": {http.StatusOK, `{"offsets":[{"offset":1},{"error_code":2,"error":"record too large"}]}`, "record too large"},
+		"not the proxy":  {http.StatusOK, `<html></html>`, "unreadable REST Proxy response"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				io.WriteString(w, tc.body)
+			}))
+			defer proxy.Close()
+
+			p, err := NewKafka(proxy.URL, "games")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Publish(context.Background(), batch()); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Publish = %v, want an error with %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestKafkaNeedsRESTProxyURL(t *testing.T) {
+	for _, rawURL := range []string{"kafka:9092", "localhost:9092", "tcp://kafka:9092"} {
+		if _, err := NewKafka(rawURL, ""); err == nil {
+			t.Errorf("NewKafka(%q) accepted a broker address", rawURL)
+		}
+	}
+}
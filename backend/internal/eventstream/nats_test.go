@@ -0,0 +1,178 @@
+package eventstream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"tower-defense/internal/game/events"
+)
+
+// published is a message as the fake NATS server received it
+type published struct {
+	subject string
+	msgID   string
+	body    string
+}
+
+// fakeNATS speaks enough of the NATS protocol for JetStream publishing.
+// answer returns the reply to the nth message published, counting from 1;
+// "" answers that no stream takes the subject.
+type fakeNATS struct {
+	ln     net.Listener
+	answer func(n int) string
+
+	mu  sync.Mutex
+	got []published
+}
+
+func newFakeNATS(t *testing.T, answer func(n int) string) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeNATS{ln: ln, answer: answer}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeNATS) url() string { return "nats://" + s.ln.Addr().String() }
+
+func (s *fakeNATS) published() []published {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]published(nil), s.got...)
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"version\":\"2.10.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576}\r\n")
+	subs := make(map[string]string) // sid by subject prefix of a wildcard subscription
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			subs[strings.TrimSuffix(fields[1], "*")] = fields[len(fields)-1]
+		case "HPUB":
+			// HPUB <subject> <reply> <header bytes> <total bytes>
+			hdrLen, _ := strconv.Atoi(fields[3])
+			total, _ := strconv.Atoi(fields[4])
+			data := make([]byte, total+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			tp := textproto.NewReader(bufio.NewReader(strings.NewReader(string(data[:hdrLen]))))
+			tp.ReadLine() // NATS/1.0
+			header, _ := tp.ReadMIMEHeader()
+
+			s.mu.Lock()
+			s.got = append(s.got, published{subject: fields[1], msgID: header.Get("Nats-Msg-Id"), body: string(data[hdrLen:total])})
+			n := len(s.got)
+			s.mu.Unlock()
+
+			reply := fields[2]
+			var sid string
+			for prefix, id := range subs {
+				if strings.HasPrefix(reply, prefix) {
+					sid = id
+				}
+			}
+			if ack := s.answer(n); ack != "" {
+				fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, sid, len(ack), ack)
+			} else {
+				status := "NATS/1.0 503\r\n\r\n"
+				fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", reply, sid, len(status), len(status), status)
+			}
+		}
+	}
+}
+
+func ack(n int) string { return fmt.Sprintf(`{"stream":"EVENTS","seq":%d}`, n) }
+
+func batch() []Message {
+	return []Message{
+		{ID: "a", GameID: "g.1", Type: events.TowerPlaced, Body: []byte(`{"seq":1}`)},
+		{ID: "b", GameID: "g.1", Type: events.EnemyKilled, Body: []byte(`{"seq":2}`)},
+	}
+}
+
+func TestNATSPublish(t *testing.T) {
+	server := newFakeNATS(t, ack)
+	p, err := NewNATS(server.url(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Publish(context.Background(), batch()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []published{
+		{subject: "td.events.g_1.tower_placed", msgID: "a", body: `{"seq":1}`},
+		{subject: "td.events.g_1.enemy_killed", msgID: "b", body: `{"seq":2}`},
+	}
+	got := server.published()
+	if len(got) != len(want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNATSPublishRefused(t *testing.T) {
+	refused := newFakeNATS(t, func(n int) string {
+		if n == 2 {
+			return `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+		}
+		return ack(n)
+	})
+	p, err := NewNATS(refused.url(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Publish(context.Background(), batch()); err == nil || !strings.Contains(err.Error(), "maximum messages exceeded") {
+		t.Errorf("batch with a refused message: %v, want the JetStream error", err)
+	}
+
+	nobody := newFakeNATS(t, func(int) string { return "" })
+	p, err = NewNATS(nobody.url(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Publish(context.Background(), batch()); !errors.Is(err, errNoResponders) {
+		t.Errorf("batch without a stream: %v, want %v", err, errNoResponders)
+	}
+}
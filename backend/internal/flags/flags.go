@@ -0,0 +1,190 @@
+// Package flags switches experimental subsystems on and off per deployment.
+// A subsystem defines its flag in a package variable and checks it where the
+// feature kicks in; checking is a single atomic load, cheap enough for tick
+// loops.
+//
+// A flag starts at its default and can be overridden, in increasing order of
+// precedence, by the YAML file in FEATURE_FLAGS_FILE, by FEATURE_FLAGS and by
+// the admin API at runtime. Changes made through the admin API are lost on
+// restart.
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tower-defense/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Where the value of a flag came from
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceAdmin   = "admin"
+)
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flag is a feature that can be switched on and off at runtime
+type Flag struct {
+	name        string
+	description string
+	def         bool
+	enabled     atomic.Bool
+
+	mu        sync.Mutex // guards source and changedAt
+	source    string
+	changedAt *time.Time
+}
+
+// State describes a flag, e.g. for the admin API
+type State struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Enabled     bool       `json:"enabled"`
+	Default     bool       `json:"default"`
+	Source      string     `json:"source"`              // default, file, env or admin
+	ChangedAt   *time.Time `json:"changedAt,omitempty"` // nil while at the default
+}
+
+var (
+	mu       sync.Mutex
+	registry = map[string]*Flag{}
+)
+
+// Define registers a flag; it panics on a duplicate name, as flags are defined
+// in package variables at startup
+func Define(name, description string, def bool) *Flag {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("feature flag %q defined twice", name))
+	}
+	f := &Flag{name: name, description: description, def: def, source: SourceDefault}
+	f.enabled.Store(def)
+	registry[name] = f
+	return f
+}
+
+// Enabled reports whether the feature is switched on
+func (f *Flag) Enabled() bool {
+	return f.enabled.Load()
+}
+
+func (f *Flag) set(enabled bool, source string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	was := f.enabled.Swap(enabled)
+	f.source = source
+	now := time.Now()
+	f.changedAt = &now
+	if was != enabled {
+		logging.Infow("feature_flag_changed", "flag", f.name, "enabled", enabled, "source", source)
+	}
+}
+
+func (f *Flag) state() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return State{
+		Name:        f.name,
+		Description: f.description,
+		Enabled:     f.enabled.Load(),
+		Default:     f.def,
+		Source:      f.source,
+		ChangedAt:   f.changedAt,
+	}
+}
+
+func lookup(name string) (*Flag, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	f, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	return f, nil
+}
+
+// Set switches a flag on or off from the admin API
+func Set(name string, enabled bool) (State, error) {
+	f, err := lookup(name)
+	if err != nil {
+		return State{}, err
+	}
+	f.set(enabled, SourceAdmin)
+	return f.state(), nil
+}
+
+// All returns the state of every flag, by name
+func All() []State {
+	mu.Lock()
+	flags := make([]*Flag, 0, len(registry))
+	for _, f := range registry {
+		flags = append(flags, f)
+	}
+	mu.Unlock()
+
+	states := make([]State, len(flags))
+	for i, f := range flags {
+		states[i] = f.state()
+	}
+	slices.SortFunc(states, func(a, b State) int { return strings.Compare(a.Name, b.Name) })
+	return states
+}
+
+// Load applies the overrides of a deployment: the YAML file at path, a map of
+// flag names to booleans, then env, a comma separated list of "name=bool"
+// entries where a bare name means true. Either may be empty. Flags this build
+// doesn't define are logged and skipped, so one configuration can serve
+// builds from before and after a flag was added or removed.
+func Load(path, env string) error {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var values map[string]bool
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		for name, enabled := range values {
+			apply(name, enabled, SourceFile)
+		}
+	}
+	for _, entry := range strings.Split(env, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(entry, "=")
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("feature flag %s: %w", name, err)
+			}
+		}
+		apply(strings.TrimSpace(name), enabled, SourceEnv)
+	}
+	return nil
+}
+
+func apply(name string, enabled bool, source string) {
+	f, err := lookup(name)
+	if err != nil {
+		logging.Warnw("feature_flag_unknown", "flag", name, "source", source)
+		return
+	}
+	f.set(enabled, source)
+}
@@ -0,0 +1,211 @@
+package achievements
+
+import (
+	"sync"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/i18n"
+	"tower-defense/internal/logging"
+)
+
+// Kind describes how a rule is evaluated
+type Kind string
+
+const (
+	// KindKills unlocks after Count kills matching the optional tower/enemy type filters within one game
+	KindKills Kind = "kills"
+	// KindFlawlessWave unlocks when Wave is completed without losing a single life
+	KindFlawlessWave Kind = "flawless_wave"
+)
+
+// Rule defines an achievement and its unlock condition
+type Rule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        Kind   `json:"kind"`
+	TowerType   string `json:"towerType,omitempty"`
+	EnemyType   string `json:"enemyType,omitempty"`
+	Count       int    `json:"count,omitempty"`
+	Wave        int    `json:"wave,omitempty"`
+}
+
+// DefaultRules returns the built-in achievement set
+func DefaultRules() []Rule {
+	return []Rule{
+		{ID: "first_blood", Name: "First Blood", Description: "Kill your first enemy", Kind: KindKills, Count: 1},
+		{ID: "splash_master", Name: "Splash Master", Description: "Kill 100 enemies with splash towers in one game", Kind: KindKills, TowerType: "splash", Count: 100},
+		{ID: "sharpshooter", Name: "Sharpshooter", Description: "Kill 50 enemies with sniper towers in one game", Kind: KindKills, TowerType: "sniper", Count: 50},
+		{ID: "boss_slayer", Name: "Boss Slayer", Description: "Kill a boss", Kind: KindKills, EnemyType: "boss", Count: 1},
+		{ID: "untouchable", Name: "Untouchable", Description: "Finish wave 20 without losing a life", Kind: KindFlawlessWave, Wave: 20},
+	}
+}
+
+// Status is an achievement as seen by a single player
+type Status struct {
+	Rule
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlockedAt,omitempty"`
+}
+
+// Localized returns the status with its name and description in lang, from the
+// messages "achievement.<id>.name" and "achievement.<id>.description"; rules
+// without messages keep their own text
+func (s Status) Localized(lang string) Status {
+	if name, ok := i18n.Lookup(lang, "achievement."+s.ID+".name"); ok {
+		s.Name = name
+	}
+	if desc, ok := i18n.Lookup(lang, "achievement."+s.ID+".description"); ok {
+		s.Description = desc
+	}
+	return s
+}
+
+// session tracks rule progress for one game
+type session struct {
+	players   map[string]bool
+	kills     map[string]map[string]int // playerID -> ruleID -> count
+	livesLost int
+}
+
+// Engine evaluates achievement rules against game events
+type Engine struct {
+	mu       sync.Mutex
+	rules    []Rule
+	repo     repository.AchievementRepository
+	sessions map[string]*session // gameID -> progress
+}
+
+// NewEngine creates a new achievements engine
+func NewEngine(rules []Rule, repo repository.AchievementRepository) *Engine {
+	return &Engine{
+		rules:    rules,
+		repo:     repo,
+		sessions: make(map[string]*session),
+	}
+}
+
+// HandleEvent processes a game event; it is safe to register as an events.Listener
+func (e *Engine) HandleEvent(ev events.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch ev.Type {
+	case events.TowerPlaced:
+		if ev.PlayerID != "" {
+			e.session(ev.GameID).players[ev.PlayerID] = true
+		}
+
+	case events.EnemyKilled:
+		if ev.PlayerID == "" {
+			return
+		}
+		s := e.session(ev.GameID)
+		s.players[ev.PlayerID] = true
+		if s.kills[ev.PlayerID] == nil {
+			s.kills[ev.PlayerID] = make(map[string]int)
+		}
+		for _, rule := range e.rules {
+			if rule.Kind != KindKills || !matches(rule, ev) {
+				continue
+			}
+			s.kills[ev.PlayerID][rule.ID]++
+			if s.kills[ev.PlayerID][rule.ID] >= rule.Count {
+				e.unlock(rule, ev.PlayerID, ev.GameID)
+			}
+		}
+
+	case events.EnemyLeaked:
+		e.session(ev.GameID).livesLost += ev.Lives
+
+	case events.WaveCompleted:
+		s := e.session(ev.GameID)
+		if s.livesLost > 0 {
+			return
+		}
+		for _, rule := range e.rules {
+			if rule.Kind != KindFlawlessWave || ev.Wave < rule.Wave {
+				continue
+			}
+			for playerID := range s.players {
+				e.unlock(rule, playerID, ev.GameID)
+			}
+		}
+
+	case events.GameOver, events.GameReset:
+		delete(e.sessions, ev.GameID)
+	}
+}
+
+// Rules returns the configured achievement rules
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// PlayerAchievements returns every achievement with its unlock status for a player
+func (e *Engine) PlayerAchievements(playerID string) ([]Status, error) {
+	unlocked, err := e.repo.ListUnlocked(playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]time.Time, len(unlocked))
+	for _, u := range unlocked {
+		byID[u.AchievementID] = u.UnlockedAt
+	}
+
+	result := make([]Status, 0, len(e.rules))
+	for _, rule := range e.rules {
+		status := Status{Rule: rule}
+		if at, ok := byID[rule.ID]; ok {
+			at := at
+			status.Unlocked = true
+			status.UnlockedAt = &at
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// session returns the progress tracker for a game (caller must hold e.mu)
+func (e *Engine) session(gameID string) *session {
+	s, ok := e.sessions[gameID]
+	if !ok {
+		s = &session{
+			players: make(map[string]bool),
+			kills:   make(map[string]map[string]int),
+		}
+		e.sessions[gameID] = s
+	}
+	return s
+}
+
+// unlock persists an achievement for a player (caller must hold e.mu)
+func (e *Engine) unlock(rule Rule, playerID, gameID string) {
+	added, err := e.repo.Unlock(repository.UnlockedAchievement{
+		AchievementID: rule.ID,
+		PlayerID:      playerID,
+		GameID:        gameID,
+		UnlockedAt:    time.Now(),
+	})
+	if err != nil {
+		logging.Errorw("achievement_unlock_failed", "achievement", rule.ID, "player_id", playerID, "error", err)
+		return
+	}
+	if added {
+		logging.Infow("achievement_unlocked", "achievement", rule.ID, "player_id", playerID, "game_id", gameID)
+	}
+}
+
+// matches reports whether a kill event satisfies a rule's filters
+func matches(rule Rule, ev events.Event) bool {
+	if rule.TowerType != "" && rule.TowerType != ev.TowerType {
+		return false
+	}
+	if rule.EnemyType != "" && rule.EnemyType != ev.EnemyType {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package game
+
+import "time"
+
+// touch records that a player acted in the room (caller must hold g.mu)
+func (g *Game) touch(playerID string) {
+	if playerID == "" {
+		return
+	}
+	if g.activity == nil {
+		g.activity = make(map[string]time.Time)
+	}
+	g.activity[playerID] = time.Now()
+}
+
+// LastActive returns when a player last acted in the room: joined, got ready,
+// built, sold, resupplied or queued a blueprint. Failed attempts count, as the
+// player was there to make them. It is false for players who did none of this
+// since the last reset.
+func (g *Game) LastActive(playerID string) (time.Time, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	at, ok := g.activity[playerID]
+	return at, ok
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"time"
+
+	"tower-defense/internal/logging"
+)
+
+// ForceEnd ends the game immediately as if the last life was lost.
+// Returns false if the game was already over.
+func (g *Game) ForceEnd(reason string) bool {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	if !g.finish(OutcomeLost, reason) {
+		return false
+	}
+
+	logging.Infow("game_force_ended", "game_id", g.id, "reason", reason)
+	return true
+}
+
+// AdjustResources adds the given deltas to gold and lives for debugging.
+// Both are clamped at zero; dropping lives to zero ends the game.
+func (g *Game) AdjustResources(goldDelta, livesDelta int) GameState {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	g.state.Gold = max(g.state.Gold+goldDelta, 0)
+	g.state.Lives = max(g.state.Lives+livesDelta, 0)
+	if g.state.Lives == 0 {
+		g.finish(OutcomeLost, "admin")
+	}
+	g.markChanged()
+
+	logging.Infow("game_resources_adjusted", "game_id", g.id, "gold", g.state.Gold, "lives", g.state.Lives)
+	return g.state
+}
+
+// SetVerbose toggles per-game verbose logging of events and periodic tick summaries
+func (g *Game) SetVerbose(verbose bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.verbose = verbose
+	logging.Infow("game_verbose_logging", "game_id", g.id, "enabled", verbose)
+}
+
+// Verbose reports whether verbose logging is enabled for this game
+func (g *Game) Verbose() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.verbose
+}
+
+// verboseTickInterval throttles tick summaries in verbose mode
+const verboseTickInterval = time.Second
+
+// logVerboseTick writes a tick summary at most once per verboseTickInterval (caller must hold g.mu)
+func (g *Game) logVerboseTick(now time.Time, dt float64) {
+	if !g.verbose || now.Sub(g.lastVerboseLog) < verboseTickInterval {
+		return
+	}
+	g.lastVerboseLog = now
+	logging.Infow("game_tick",
+		"game_id", g.id,
+		"wave", g.state.Wave,
+		"gold", g.state.Gold,
+		"lives", g.state.Lives,
+		"enemies", len(g.world.GetEnemies()),
+		"projectiles", len(g.world.GetProjectiles()),
+		"towers", len(g.world.GetTowers()),
+		"dt", dt,
+	)
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"errors"
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+var (
+	ErrTowerNotFound = errors.New("tower not found")
+	ErrAmmoDisabled  = errors.New("tower does not use ammunition")
+	ErrAmmoFull      = errors.New("tower ammunition is already full")
+)
+
+// Resupply modes, reported in the Detail of TowerResupplied events
+const (
+	ResupplyAuto   = "auto"
+	ResupplyManual = "manual"
+)
+
+// Resupply is the outcome of refilling a tower's ammunition
+type Resupply struct {
+	TowerID string `json:"towerId"`
+	Rounds  int    `json:"rounds"` // rounds bought
+	Gold    int    `json:"gold"`   // gold paid
+	Ammo    int    `json:"ammo"`
+	MaxAmmo int    `json:"maxAmmo"`
+}
+
+// ResupplyTower refills a tower's ammunition with as many rounds as the gold
+// allows, on behalf of playerID
+func (g *Game) ResupplyTower(playerID, towerID string) (*Resupply, error) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	if g.state.GameOver {
+		return nil, ErrGameFinished
+	}
+	if err := g.checkRules(config.ActionResupply); err != nil {
+		return nil, err
+	}
+	entity, ok := g.world.GetEntity(towerID)
+	if !ok {
+		return nil, ErrTowerNotFound
+	}
+	tower, ok := entity.(*ecs.TowerEntity)
+	if !ok {
+		return nil, ErrTowerNotFound
+	}
+	if tower.MaxAmmo == 0 {
+		return nil, ErrAmmoDisabled
+	}
+	result, err := g.resupply(tower, playerID, ResupplyManual)
+	if err != nil {
+		return nil, err
+	}
+	g.markChanged()
+	return result, nil
+}
+
+// resupply buys rounds for a tower up to its magazine size, rounding the price
+// up to whole gold (caller must hold g.mu)
+func (g *Game) resupply(tower *ecs.TowerEntity, playerID, mode string) (*Resupply, error) {
+	missing := tower.MaxAmmo - tower.Ammo
+	if missing <= 0 {
+		return nil, ErrAmmoFull
+	}
+	rounds := missing
+	if price := g.config.Ammo.RoundCost; price > 0 {
+		rounds = min(rounds, int(float64(g.gold(playerID))/price))
+	}
+	if rounds <= 0 {
+		return nil, ErrNotEnoughGold
+	}
+	cost := int(math.Ceil(float64(rounds) * g.config.Ammo.RoundCost))
+
+	tower.Ammo += rounds
+	g.addGold(playerID, -cost)
+	g.emit(events.Event{
+		Type:      events.TowerResupplied,
+		Wave:      g.state.Wave,
+		PlayerID:  playerID,
+		TowerID:   tower.ID,
+		TowerType: tower.TowerType,
+		Gold:      cost,
+		Detail:    mode,
+	})
+	logging.Debugw("tower_resupplied",
+		"game_id", g.id,
+		"tower_id", tower.ID,
+		"mode", mode,
+		"rounds", rounds,
+		"gold", cost,
+		"gold_remaining", g.gold(playerID))
+
+	return &Resupply{TowerID: tower.ID, Rounds: rounds, Gold: cost, Ammo: tower.Ammo, MaxAmmo: tower.MaxAmmo}, nil
+}
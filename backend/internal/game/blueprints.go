@@ -0,0 +1,191 @@
+package game
+
+import (
+	"errors"
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// MaxBlueprints is the number of tower placements a game can hold queued
+const MaxBlueprints = 20
+
+var (
+	ErrBlueprintNotFound = errors.New("blueprint not found")
+	ErrTooManyBlueprints = errors.New("blueprint queue is full")
+	ErrNotBlueprintOwner = errors.New("blueprint belongs to another player")
+)
+
+// Blueprint is a tower placement queued until the game can afford it. Clients
+// draw blueprints as ghost towers.
+type Blueprint struct {
+	ID        string `json:"id"`
+	TowerType string `json:"towerType"`
+	Position  PosDTO `json:"position"`
+	OwnerID   string `json:"ownerId,omitempty"`
+	Cost      int    `json:"cost"` // gold the tower costs at the time it was queued
+}
+
+// QueueBlueprint queues a tower for playerID that is built as soon as the gold
+// allows, after every blueprint queued before it. The placement is checked
+// like a tower's except for the price, and must also keep its distance from
+// other blueprints. Returns the blueprint and whether it was built right away.
+func (g *Game) QueueBlueprint(playerID, towerType string, x, y float64) (Blueprint, bool, error) {
+	mods, err := g.member(playerID)
+	if err != nil {
+		return Blueprint{}, false, err
+	}
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	if err := g.checkRules(config.ActionBlueprint); err != nil {
+		return Blueprint{}, false, err
+	}
+	towerCfg, err := g.checkTowerRules(mods, towerType, x, y)
+	if err != nil {
+		return Blueprint{}, false, err
+	}
+	if len(g.blueprints) >= MaxBlueprints {
+		return Blueprint{}, false, ErrTooManyBlueprints
+	}
+	pos := ecs.Position{X: x, Y: y}
+	if !g.isValidPlacement(pos) || g.nearBlueprint(pos) {
+		return Blueprint{}, false, ErrInvalidPlacement
+	}
+
+	bp := Blueprint{
+		ID:        uuid.NewString(),
+		TowerType: towerType,
+		Position:  PosDTO{X: x, Y: y},
+		OwnerID:   playerID,
+		Cost:      towerCfg.Cost,
+	}
+	g.blueprints = append(g.blueprints, bp)
+	g.emit(events.Event{Type: events.BlueprintQueued, Wave: g.state.Wave, PlayerID: playerID, EntityID: bp.ID, TowerType: towerType, Gold: bp.Cost})
+	g.markChanged()
+	logging.Infow("blueprint_queued", "game_id", g.id, "player_id", playerID, "blueprint_id", bp.ID,
+		"tower_type", towerType, "x", x, "y", y, "queued", len(g.blueprints))
+
+	g.buildBlueprints()
+	return bp, !g.blueprintQueued(bp.ID), nil
+}
+
+// CancelBlueprint removes a queued blueprint. Only the player who queued it
+// may cancel a blueprint with an owner.
+func (g *Game) CancelBlueprint(playerID, id string) error {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	if err := g.checkRules(config.ActionBlueprint); err != nil {
+		return err
+	}
+	i := g.blueprintIndex(id)
+	if i < 0 {
+		return ErrBlueprintNotFound
+	}
+	bp := g.blueprints[i]
+	if bp.OwnerID != "" && bp.OwnerID != playerID {
+		return ErrNotBlueprintOwner
+	}
+	g.removeBlueprint(i, events.BlueprintCancelled, "")
+	logging.Infow("blueprint_cancelled", "game_id", g.id, "player_id", playerID, "blueprint_id", id)
+
+	// The blueprints behind it may be affordable now
+	g.buildBlueprints()
+	return nil
+}
+
+// Blueprints returns the queued blueprints in build order
+func (g *Game) Blueprints() []Blueprint {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.copyBlueprints()
+}
+
+// buildBlueprints turns queued blueprints into towers, in queue order, while
+// the gold covers the next one and the rules allow building. A blueprint its
+// owner can't pay for yet holds back the later ones paid from the same gold,
+// so with wallets each player's queue waits on its own. Blueprints that can no
+// longer be built, e.g. because a tower took the spot, are dropped (caller
+// must hold g.mu).
+func (g *Game) buildBlueprints() {
+	if g.checkRules(config.ActionBuild) != nil {
+		return
+	}
+	waiting := make(map[string]bool) // payers whose next blueprint is too expensive
+	for i := 0; i < len(g.blueprints) && !g.state.GameOver; {
+		bp := g.blueprints[i]
+		payer := g.payer(bp.OwnerID)
+		if waiting[payer] {
+			i++
+			continue
+		}
+		towerCfg, err := g.config.GetTowerConfig(bp.TowerType)
+		if err == nil && g.gold(bp.OwnerID) < towerCfg.Cost {
+			waiting[payer] = true
+			i++
+			continue
+		}
+		if err == nil {
+			err = g.placeTower(bp.OwnerID, g.players[bp.OwnerID], bp.TowerType, bp.Position.X, bp.Position.Y)
+		}
+		if err != nil {
+			g.removeBlueprint(i, events.BlueprintDropped, err.Error())
+			logging.Infow("blueprint_dropped", "game_id", g.id, "player_id", bp.OwnerID, "blueprint_id", bp.ID, "error", err)
+			continue
+		}
+		g.removeBlueprint(i, events.BlueprintBuilt, "")
+	}
+}
+
+// removeBlueprint takes the i-th blueprint off the queue, announcing why with
+// an event of type t (caller must hold g.mu)
+func (g *Game) removeBlueprint(i int, t events.Type, detail string) {
+	bp := g.blueprints[i]
+	g.blueprints = append(g.blueprints[:i:i], g.blueprints[i+1:]...)
+	g.emit(events.Event{Type: t, Wave: g.state.Wave, PlayerID: bp.OwnerID, EntityID: bp.ID, TowerType: bp.TowerType, Detail: detail})
+	g.markChanged()
+}
+
+// nearBlueprint reports whether pos is closer than the tower spacing to a
+// queued blueprint (caller must hold g.mu)
+func (g *Game) nearBlueprint(pos ecs.Position) bool {
+	for _, bp := range g.blueprints {
+		if math.Hypot(pos.X-bp.Position.X, pos.Y-bp.Position.Y) < g.config.Placement.MinTowerSpacing {
+			return true
+		}
+	}
+	return false
+}
+
+// blueprintIndex returns the queue position of a blueprint, or -1 (caller must hold g.mu)
+func (g *Game) blueprintIndex(id string) int {
+	for i, bp := range g.blueprints {
+		if bp.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// blueprintQueued reports whether a blueprint is still waiting to be built (caller must hold g.mu)
+func (g *Game) blueprintQueued(id string) bool {
+	return g.blueprintIndex(id) >= 0
+}
+
+// copyBlueprints returns a copy of the queue for snapshots and saves (caller must hold g.mu)
+func (g *Game) copyBlueprints() []Blueprint {
+	if len(g.blueprints) == 0 {
+		return nil
+	}
+	return append([]Blueprint(nil), g.blueprints...)
+}
@@ -0,0 +1,246 @@
+// Package bot implements computer-controlled players that build towers in a game,
+// for demos, co-op filler and automated balance runs.
+package bot
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownStrategy = errors.New("unknown bot strategy")
+	ErrBotExists       = errors.New("a bot with this player ID is already attached")
+	ErrTooManyBots     = errors.New("too many bots in this game")
+)
+
+const (
+	defaultInterval = 500 * time.Millisecond
+	minInterval     = 100 * time.Millisecond
+	maxBotsPerGame  = 4
+)
+
+// Options configures an AIPlayer
+type Options struct {
+	PlayerID string        // defaults to "bot-" plus a random suffix
+	Strategy string        // see NewStrategy
+	Interval time.Duration // time between decisions, default 500ms
+}
+
+// Info describes an attached bot
+type Info struct {
+	GameID       string `json:"gameId"`
+	PlayerID     string `json:"playerId"`
+	Strategy     string `json:"strategy"`
+	IntervalMs   int64  `json:"intervalMs"`
+	TowersPlaced int64  `json:"towersPlaced"`
+}
+
+// AIPlayer builds towers in one game on behalf of a bot player. The game is
+// looked up on every step, so the bot follows map changes of the default game and
+// stops by itself when the game is removed.
+type AIPlayer struct {
+	manager  *game.Manager
+	gameID   string
+	playerID string
+	strategy Strategy
+	interval time.Duration
+	placed   atomic.Int64
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a bot for gameID; call Start to run it
+func New(manager *game.Manager, gameID string, opts Options) (*AIPlayer, error) {
+	strategy, err := NewStrategy(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PlayerID == "" {
+		opts.PlayerID = "bot-" + uuid.NewString()[:6]
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	opts.Interval = max(opts.Interval, minInterval)
+	return &AIPlayer{
+		manager:  manager,
+		gameID:   gameID,
+		playerID: opts.PlayerID,
+		strategy: strategy,
+		interval: opts.Interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Info describes the bot
+func (p *AIPlayer) Info() Info {
+	return Info{
+		GameID:       p.gameID,
+		PlayerID:     p.playerID,
+		Strategy:     p.strategy.Name(),
+		IntervalMs:   p.interval.Milliseconds(),
+		TowersPlaced: p.placed.Load(),
+	}
+}
+
+// Start runs the bot in the background until Stop is called or the game is removed
+func (p *AIPlayer) Start() {
+	logging.Infow("bot_started", "game_id", p.gameID, "player_id", p.playerID, "strategy", p.strategy.Name())
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				if !p.step() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Stop halts the bot and waits for its current step to finish
+func (p *AIPlayer) Stop() {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	<-p.done
+}
+
+// step makes at most one move and reports whether the bot should keep running
+func (p *AIPlayer) step() bool {
+	g, err := p.manager.GetGame(p.gameID)
+	if err != nil {
+		logging.Infow("bot_stopped", "game_id", p.gameID, "player_id", p.playerID, "reason", err.Error())
+		return false
+	}
+	view := View{State: g.GetState(), Towers: g.TowerTypes(), Placement: g.PlacementRules()}
+	if view.State.GameOver {
+		return true // the game may be reset
+	}
+	move, ok := p.strategy.Choose(view)
+	if !ok {
+		return true
+	}
+	if err := g.AddTowerForPlayer(p.playerID, move.TowerType, move.X, move.Y); err != nil {
+		logging.Debugw("bot_move_rejected", "game_id", p.gameID, "player_id", p.playerID, "tower_type", move.TowerType, "error", err)
+		return true
+	}
+	p.placed.Add(1)
+	return true
+}
+
+// Registry tracks the bots attached to games
+type Registry struct {
+	manager *game.Manager
+	mu      sync.Mutex
+	bots    map[string]map[string]*AIPlayer // gameID -> playerID -> bot
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry(manager *game.Manager) *Registry {
+	return &Registry{manager: manager, bots: make(map[string]map[string]*AIPlayer)}
+}
+
+// Attach starts a bot in gameID
+func (r *Registry) Attach(gameID string, opts Options) (*AIPlayer, error) {
+	g, err := r.manager.GetGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := New(r.manager, gameID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(gameID)
+	bots := r.bots[gameID]
+	if bots == nil {
+		bots = make(map[string]*AIPlayer)
+		r.bots[gameID] = bots
+	}
+	if _, exists := bots[p.playerID]; exists {
+		return nil, ErrBotExists
+	}
+	if len(bots) >= maxBotsPerGame {
+		return nil, ErrTooManyBots
+	}
+	// Bots need no password but take a seat like any player
+	if err := g.Seat(p.playerID); err != nil {
+		return nil, err
+	}
+	bots[p.playerID] = p
+	p.Start()
+	return p, nil
+}
+
+// Detach stops and removes a bot; it reports whether the bot existed
+func (r *Registry) Detach(gameID, playerID string) bool {
+	r.mu.Lock()
+	p, ok := r.bots[gameID][playerID]
+	if ok {
+		delete(r.bots[gameID], playerID)
+	}
+	r.mu.Unlock()
+
+	if ok {
+		p.Stop()
+		logging.Infow("bot_detached", "game_id", gameID, "player_id", playerID, "towers_placed", p.placed.Load())
+	}
+	return ok
+}
+
+// List describes the bots in gameID
+func (r *Registry) List(gameID string) []Info {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(gameID)
+	infos := make([]Info, 0, len(r.bots[gameID]))
+	for _, p := range r.bots[gameID] {
+		infos = append(infos, p.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].PlayerID < infos[j].PlayerID })
+	return infos
+}
+
+// StopAll stops every bot, e.g. on shutdown
+func (r *Registry) StopAll() {
+	r.mu.Lock()
+	all := r.bots
+	r.bots = make(map[string]map[string]*AIPlayer)
+	r.mu.Unlock()
+
+	for _, bots := range all {
+		for _, p := range bots {
+			p.Stop()
+		}
+	}
+}
+
+// pruneLocked forgets bots of gameID that stopped by themselves (caller holds r.mu)
+func (r *Registry) pruneLocked(gameID string) {
+	for id, p := range r.bots[gameID] {
+		select {
+		case <-p.done:
+			delete(r.bots[gameID], id)
+		default:
+		}
+	}
+}
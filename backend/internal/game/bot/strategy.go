@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"math"
+	"sort"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/config"
+)
+
+// View is what a strategy sees of the game when choosing a move
+type View struct {
+	State     game.GameStateSnapshot
+	Towers    map[string]config.TowerConfig
+	Placement config.PlacementConfig
+}
+
+// Move places one tower
+type Move struct {
+	TowerType string
+	X, Y      float64
+}
+
+// Strategy decides where the bot builds next
+type Strategy interface {
+	Name() string
+	// Choose returns the next tower to build, or false to wait (e.g. to save gold)
+	Choose(v View) (Move, bool)
+}
+
+// Built-in strategies
+const (
+	StrategyGreedy     = "greedy"     // most damage per gold, placed where it covers the most path
+	StrategyChokepoint = "chokepoint" // strongest tower on the spot covering the most path, saving up if needed
+)
+
+// NewStrategy returns a built-in strategy by name; "" selects greedy
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case "", StrategyGreedy:
+		return greedy{}, nil
+	case StrategyChokepoint:
+		return chokepoint{}, nil
+	default:
+		return nil, ErrUnknownStrategy
+	}
+}
+
+// dps estimates a tower's damage per second. Splash towers hit groups, so their
+// damage is scaled by the splash radius relative to typical enemy spacing.
+func dps(tc config.TowerConfig) float64 {
+	d := float64(tc.Damage) * tc.FireRate
+	if tc.SplashRadius > 0 {
+		d *= 1 + tc.SplashRadius/20
+	}
+	return d
+}
+
+// greedy buys the tower type with the best damage per gold it can afford right now
+type greedy struct{}
+
+func (greedy) Name() string { return StrategyGreedy }
+
+func (greedy) Choose(v View) (Move, bool) {
+	best, bestValue := "", 0.0
+	for _, name := range sortedTowerTypes(v.Towers) {
+		tc := v.Towers[name]
+		if tc.Cost <= 0 || tc.Cost > v.State.Gold {
+			continue
+		}
+		if value := dps(tc) / float64(tc.Cost); value > bestValue {
+			best, bestValue = name, value
+		}
+	}
+	if best == "" {
+		return Move{}, false
+	}
+	spot, coverage := bestSpot(v, v.Towers[best].Range)
+	if coverage == 0 {
+		return Move{}, false
+	}
+	return Move{TowerType: best, X: spot.X, Y: spot.Y}, true
+}
+
+// chokepoint builds the tower with the highest damage output over the path it
+// covers at the best remaining spot, waiting for gold instead of settling for less
+type chokepoint struct{}
+
+func (chokepoint) Name() string { return StrategyChokepoint }
+
+func (chokepoint) Choose(v View) (Move, bool) {
+	var best Move
+	bestValue := 0.0
+	for _, name := range sortedTowerTypes(v.Towers) {
+		tc := v.Towers[name]
+		spot, coverage := bestSpot(v, tc.Range)
+		if value := dps(tc) * coverage; value > bestValue {
+			best, bestValue = Move{TowerType: name, X: spot.X, Y: spot.Y}, value
+		}
+	}
+	if bestValue == 0 || v.Towers[best.TowerType].Cost > v.State.Gold {
+		return Move{}, false
+	}
+	return best, true
+}
+
+func sortedTowerTypes(towers map[string]config.TowerConfig) []string {
+	names := make([]string, 0, len(towers))
+	for name := range towers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const (
+	pathSampleStep = 5.0  // distance between path samples used to measure coverage
+	gridStep       = 20.0 // spacing of candidate tower spots
+	spotMargin     = 1.0  // keep candidates clear of the exact placement limits
+)
+
+// bestSpot returns the free spot whose tower range covers the most path length
+func bestSpot(v View, towerRange float64) (game.PosDTO, float64) {
+	paths := [][]game.PosDTO{v.State.Path}
+	paths = append(paths, v.State.Entrances...)
+	var samples, segments []game.PosDTO
+	for _, path := range paths {
+		for i := 0; i+1 < len(path); i++ {
+			a, b := path[i], path[i+1]
+			segments = append(segments, a, b)
+			n := int(math.Ceil(dist(a, b) / pathSampleStep))
+			for j := 0; j < n; j++ {
+				t := float64(j) / float64(n)
+				samples = append(samples, game.PosDTO{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t})
+			}
+		}
+	}
+
+	var best game.PosDTO
+	bestCoverage := 0.0
+	for y := gridStep; y < float64(v.State.MapHeight); y += gridStep {
+		for x := gridStep; x < float64(v.State.MapWidth); x += gridStep {
+			p := game.PosDTO{X: x, Y: y}
+			if !free(v, p, segments) {
+				continue
+			}
+			covered := 0
+			for _, s := range samples {
+				if dist(p, s) <= towerRange {
+					covered++
+				}
+			}
+			if coverage := float64(covered) * pathSampleStep; coverage > bestCoverage {
+				best, bestCoverage = p, coverage
+			}
+		}
+	}
+	return best, bestCoverage
+}
+
+// free mirrors the game's placement rules so the bot rarely proposes a rejected spot
+func free(v View, p game.PosDTO, segments []game.PosDTO) bool {
+	for i := 0; i+1 < len(segments); i += 2 {
+		if distToSegment(p, segments[i], segments[i+1]) < v.Placement.MinDistanceFromPath+spotMargin {
+			return false
+		}
+	}
+	for _, t := range v.State.Towers {
+		if dist(p, t.Position) < v.Placement.MinTowerSpacing+spotMargin {
+			return false
+		}
+	}
+	return true
+}
+
+func dist(a, b game.PosDTO) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+func distToSegment(p, a, b game.PosDTO) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return dist(p, a)
+	}
+	t := math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSq))
+	return dist(p, game.PosDTO{X: a.X + t*dx, Y: a.Y + t*dy})
+}
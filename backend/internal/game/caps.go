@@ -0,0 +1,123 @@
+package game
+
+import (
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// A saturated kind of entity clears once its count is below capClearRatio of
+// the cap and nothing was held back for capClearAfter simulated seconds, so a
+// game hovering at the cap doesn't flap between the two events
+const (
+	capClearRatio = 0.9
+	capClearAfter = 3.0
+)
+
+// Entity kinds in EntityCapReached and EntityCapCleared events
+const (
+	capEnemies     = "enemies"
+	capProjectiles = "projectiles"
+)
+
+// EntityLimits are hard caps on the entities of one game, so a runaway room
+// can't take the server's memory with it; 0 = no limit
+type EntityLimits struct {
+	Enemies     int `json:"enemies"`     // alive at once; wave spawns wait while the game is at the cap
+	Projectiles int `json:"projectiles"` // in flight at once; shots at the cap land at once instead
+}
+
+// saturation tracks which limits a game is running into (guarded by Game.mu)
+type saturation struct {
+	limits      EntityLimits
+	enemies     bool
+	projectiles bool
+
+	// Game time a spawn was last held or a shot resolved on the spot
+	lastHeld    float64
+	lastHitscan float64
+
+	// Of the last tick, for TickStats
+	spawnsHeld int
+	hitscans   int
+}
+
+// SetEntityLimits replaces the game's entity limits
+func (g *Game) SetEntityLimits(l EntityLimits) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.applyEntityLimits(l)
+}
+
+// EntityLimits returns the game's entity limits
+func (g *Game) EntityLimits() EntityLimits {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.saturation.limits
+}
+
+// applyEntityLimits hands the limits to the systems; a limit lifted also
+// clears its saturation (caller must hold g.mu)
+func (g *Game) applyEntityLimits(l EntityLimits) {
+	g.saturation.limits = l
+	g.waveSystem.SetMaxEnemies(l.Enemies)
+	g.combatSystem.SetMaxProjectiles(l.Projectiles, g.projectileSystem.Resolve)
+	if l.Enemies <= 0 && g.saturation.enemies {
+		g.clearSaturation(capEnemies, &g.saturation.enemies)
+	}
+	if l.Projectiles <= 0 && g.saturation.projectiles {
+		g.clearSaturation(capProjectiles, &g.saturation.projectiles)
+	}
+}
+
+// checkSaturation collects what the systems held back this tick and signals
+// when a game starts or stops running into a limit (caller must hold g.mu)
+func (g *Game) checkSaturation() {
+	s := &g.saturation
+	s.spawnsHeld = g.waveSystem.TakeCapHolds()
+	s.hitscans = g.combatSystem.TakeHitscans()
+	now := g.clock.Time
+
+	if s.spawnsHeld > 0 {
+		s.lastHeld = now
+		if !s.enemies {
+			s.enemies = true
+			g.reachCap(capEnemies, s.limits.Enemies, g.world.EnemyCount())
+		}
+	} else if s.enemies && now-s.lastHeld >= capClearAfter &&
+		float64(g.world.EnemyCount()) < capClearRatio*float64(s.limits.Enemies) {
+		g.clearSaturation(capEnemies, &s.enemies)
+	}
+
+	if s.hitscans > 0 {
+		s.lastHitscan = now
+		if !s.projectiles {
+			s.projectiles = true
+			g.reachCap(capProjectiles, s.limits.Projectiles, len(g.world.GetProjectiles()))
+		}
+	} else if s.projectiles && now-s.lastHitscan >= capClearAfter &&
+		float64(len(g.world.GetProjectiles())) < capClearRatio*float64(s.limits.Projectiles) {
+		g.clearSaturation(capProjectiles, &s.projectiles)
+	}
+}
+
+// Saturated reports whether the game is at one of its entity limits
+func (g *Game) Saturated() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.saturation.enemies || g.saturation.projectiles
+}
+
+// reachCap signals that the game hit the limit of kind (caller must hold g.mu)
+func (g *Game) reachCap(kind string, limit, count int) {
+	g.emit(events.Event{Type: events.EntityCapReached, Wave: g.state.Wave, Detail: kind})
+	logging.Warnw("entity_cap_reached", "game_id", g.id, "kind", kind, "limit", limit, "count", count)
+}
+
+// clearSaturation signals that the game is back under the limit of kind
+// (caller must hold g.mu)
+func (g *Game) clearSaturation(kind string, flag *bool) {
+	*flag = false
+	g.emit(events.Event{Type: events.EntityCapCleared, Wave: g.state.Wave, Detail: kind})
+	logging.Infow("entity_cap_cleared", "game_id", g.id, "kind", kind)
+}
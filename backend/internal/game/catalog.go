@@ -0,0 +1,153 @@
+package game
+
+import "tower-defense/internal/game/config"
+
+// Catalog describes the rules of a game that clients need to draw the map and
+// offer build options, so they don't hardcode balance data
+type Catalog struct {
+	Towers    map[string]TowerInfo `json:"towers"`
+	Enemies   map[string]EnemyInfo `json:"enemies"`
+	Map       MapInfo              `json:"map"`
+	Placement PlacementInfo        `json:"placement"`
+	Walls     WallInfo             `json:"walls"`
+	Ammo      *AmmoInfo            `json:"ammo,omitempty"` // nil unless towers use ammunition
+}
+
+// TowerInfo is one buildable tower type
+type TowerInfo struct {
+	Cost         int       `json:"cost"`
+	Range        float64   `json:"range"`
+	Damage       int       `json:"damage"`
+	FireRate     float64   `json:"fireRate"`
+	SplashRadius float64   `json:"splashRadius,omitempty"`
+	Projectile   string    `json:"projectile,omitempty"` // projectile behavior: homing, beam, arc, pierce or chain
+	Aura         *AuraInfo `json:"aura,omitempty"`       // support towers only
+	Ammo         int       `json:"ammo,omitempty"`       // magazine size under the ammo rule
+}
+
+// AuraInfo is the buff a support tower grants towers within Radius
+type AuraInfo struct {
+	Radius   float64 `json:"radius"`
+	Damage   float64 `json:"damage,omitempty"`
+	FireRate float64 `json:"fireRate,omitempty"`
+	Range    float64 `json:"range,omitempty"`
+}
+
+// AmmoInfo is the price of ammunition and whether empty towers refill by themselves
+type AmmoInfo struct {
+	RoundCost    float64 `json:"roundCost"`
+	AutoResupply bool    `json:"autoResupply"`
+}
+
+// EnemyInfo is the base stats of one enemy type, before per-wave HP scaling
+type EnemyInfo struct {
+	HP          int     `json:"hp"`
+	Speed       float64 `json:"speed"`
+	GoldReward  int     `json:"goldReward"`
+	ScoreReward int     `json:"scoreReward"`
+}
+
+// MapInfo is the geometry of the map a game is played on
+type MapInfo struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Width         int           `json:"width"`
+	Height        int           `json:"height"`
+	Path          []PosDTO      `json:"path"`
+	PathHalfWidth float64       `json:"pathHalfWidth"`
+	Entrances     [][]PosDTO    `json:"entrances,omitempty"` // extra spawn paths
+	Zones         []ZoneDTO     `json:"zones,omitempty"`     // terrain zones
+	Obstacles     []ObstacleDTO `json:"obstacles,omitempty"` // blocking terrain
+}
+
+// PlacementInfo is the tower placement constraints
+type PlacementInfo struct {
+	MinDistanceFromPath float64 `json:"minDistanceFromPath"`
+	MinTowerSpacing     float64 `json:"minTowerSpacing"`
+	MaxTowers           int     `json:"maxTowers"`
+	GridSize            float64 `json:"gridSize"` // cell size of build template offsets
+}
+
+// WallInfo is the cost and limits of walls; MaxWalls 0 means walls are disabled
+type WallInfo struct {
+	Cost       int     `json:"cost"`
+	HP         int     `json:"hp"`
+	MaxWalls   int     `json:"maxWalls"`
+	MinSpacing float64 `json:"minSpacing"`
+}
+
+// Catalog returns the tower and enemy definitions, map geometry and placement
+// rules in effect for this game, including hot-reloaded costs
+func (g *Game) Catalog() Catalog {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	cfg := g.config
+	catalog := Catalog{
+		Towers:  make(map[string]TowerInfo, len(cfg.Towers)),
+		Enemies: make(map[string]EnemyInfo, len(cfg.Enemies)),
+		Map: MapInfo{
+			ID:            g.mapID,
+			Name:          cfg.Map.Name,
+			Width:         cfg.Map.Width,
+			Height:        cfg.Map.Height,
+			Path:          posDTOs(cfg.Map.Path),
+			PathHalfWidth: cfg.Map.PathHalfWidth,
+			Zones:         zoneDTOs(cfg.Map.Zones),
+			Obstacles:     obstacleDTOs(cfg.Map.Obstacles),
+		},
+		Placement: PlacementInfo{
+			MinDistanceFromPath: cfg.Placement.MinDistanceFromPath,
+			MinTowerSpacing:     cfg.Placement.MinTowerSpacing,
+			MaxTowers:           cfg.Placement.MaxTowers,
+			GridSize:            cfg.Placement.GridSize,
+		},
+		Walls: WallInfo{
+			Cost:       cfg.Walls.Cost,
+			HP:         cfg.Walls.HP,
+			MaxWalls:   cfg.Walls.MaxWalls,
+			MinSpacing: cfg.Walls.MinSpacing,
+		},
+	}
+	if cfg.Ammo.Enabled {
+		catalog.Ammo = &AmmoInfo{RoundCost: cfg.Ammo.RoundCost, AutoResupply: cfg.Ammo.AutoResupply}
+	}
+	for _, e := range cfg.Map.Entrances {
+		catalog.Map.Entrances = append(catalog.Map.Entrances, posDTOs(e.Path))
+	}
+
+	for name, tc := range cfg.Towers {
+		info := TowerInfo{
+			Cost:         tc.Cost,
+			Range:        tc.Range,
+			Damage:       tc.Damage,
+			FireRate:     tc.FireRate,
+			SplashRadius: tc.SplashRadius,
+		}
+		if tc.Aura == nil && cfg.Ammo.Enabled {
+			info.Ammo = cfg.MagazineSize(name)
+		}
+		if tc.Aura != nil {
+			info.Aura = &AuraInfo{Radius: tc.Aura.Radius, Damage: tc.Aura.Damage, FireRate: tc.Aura.FireRate, Range: tc.Aura.Range}
+		} else if pc, ok := cfg.Projectiles[name]; ok {
+			info.Projectile = pc.Behavior
+			if info.Projectile == "" {
+				info.Projectile = config.ProjectileHoming
+			}
+		}
+		catalog.Towers[name] = info
+	}
+	for name, ec := range cfg.Enemies {
+		catalog.Enemies[name] = EnemyInfo{HP: ec.HP, Speed: ec.Speed, GoldReward: ec.GoldReward, ScoreReward: ec.ScoreReward}
+	}
+	return catalog
+}
+
+// posDTOs converts config path points to DTOs
+func posDTOs(path []config.Position) []PosDTO {
+	dtos := make([]PosDTO, len(path))
+	for i, p := range path {
+		dtos[i] = PosDTO{X: p.X, Y: p.Y}
+	}
+	return dtos
+}
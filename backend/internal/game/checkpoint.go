@@ -0,0 +1,155 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// MaxCheckpoints is the number of wave checkpoints kept per game, and so the
+// furthest a game can be rolled back
+const MaxCheckpoints = 10
+
+var (
+	ErrNoCheckpoint         = errors.New("no checkpoint to roll back to")
+	ErrRollbackLimit        = errors.New("rollback limit reached")
+	ErrInvalidRollbackLimit = errors.New("invalid rollback limit")
+)
+
+// checkpointKey is the repository key of a game's wave checkpoints
+func checkpointKey(gameID string) string {
+	return gameID + ".checkpoints"
+}
+
+// newTimeline names a run of a game; checkpoints of other runs, e.g. before a
+// reset or load, are never rolled back to
+func newTimeline() string {
+	return uuid.NewString()
+}
+
+// storedCheckpoint is the simulation save taken when a wave started
+type storedCheckpoint struct {
+	Timeline string          `json:"timeline"`
+	Wave     int             `json:"wave"`
+	Save     json.RawMessage `json:"save"`
+}
+
+// SetCheckpointRepository sets where the wave checkpoints of this game are
+// stored; nil disables checkpoints and rollbacks
+func (g *Game) SetCheckpointRepository(repo repository.Repository) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.checkpointRepo = repo
+}
+
+// takeCheckpoint saves the simulation at the start of a wave, to be written
+// to the repository by storeCheckpoint (caller must hold g.mu)
+func (g *Game) takeCheckpoint() {
+	if g.checkpointRepo == nil {
+		return
+	}
+	save, err := json.Marshal(g.simulationSave(time.Now()))
+	if err == nil {
+		g.unsavedCheckpoint, err = json.Marshal(storedCheckpoint{Timeline: g.timeline, Wave: g.state.Wave, Save: save})
+	}
+	if err != nil {
+		logging.Errorw("game_checkpoint_failed", "game_id", g.id, "wave", g.state.Wave, "error", err)
+	}
+}
+
+// storeCheckpoint writes a just-taken checkpoint to the repository and drops
+// the oldest ones past MaxCheckpoints (caller must not hold g.mu)
+func (g *Game) storeCheckpoint() {
+	g.mu.Lock()
+	data, repo := g.unsavedCheckpoint, g.checkpointRepo
+	g.unsavedCheckpoint = nil
+	g.mu.Unlock()
+
+	if data == nil || repo == nil {
+		return
+	}
+	if _, err := repo.Save(checkpointKey(g.id), data); err != nil {
+		logging.Errorw("game_checkpoint_save_failed", "game_id", g.id, "error", err)
+		return
+	}
+	saves, err := checkpoints(repo, g.id)
+	if err != nil {
+		return
+	}
+	for _, s := range saves[min(len(saves), MaxCheckpoints):] {
+		repo.Delete(s.ID)
+	}
+}
+
+// checkpoints lists the checkpoints of a game, newest first
+func checkpoints(repo repository.Repository, gameID string) ([]*repository.GameSave, error) {
+	saves, err := repo.List(checkpointKey(gameID))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(saves, func(i, j int) bool { return saves[i].CreatedAt.After(saves[j].CreatedAt) })
+	return saves, nil
+}
+
+// Rollback restores the checkpoint taken when a wave started: waves = 1 retries
+// the current wave, 2 the one before it, and so on. It fails with
+// ErrRollbackLimit once the game used up game.max_rollbacks and with
+// ErrNoCheckpoint when the wave has no checkpoint, e.g. it started before the
+// last reset or load.
+func (g *Game) Rollback(waves int) (GameState, error) {
+	g.mu.RLock()
+	repo, timeline, from := g.checkpointRepo, g.timeline, g.state.Wave
+	g.mu.RUnlock()
+
+	target := from - waves + 1
+	if repo == nil || waves < 1 || target < 1 {
+		return GameState{}, fmt.Errorf("%w: wave %d", ErrNoCheckpoint, target)
+	}
+	saves, err := checkpoints(repo, g.id)
+	if err != nil {
+		return GameState{}, err
+	}
+	var found *storedCheckpoint
+	for _, s := range saves {
+		var cp storedCheckpoint
+		if json.Unmarshal(s.Data, &cp) == nil && cp.Timeline == timeline && cp.Wave == target {
+			found = &cp
+			break
+		}
+	}
+	if found == nil {
+		return GameState{}, fmt.Errorf("%w: wave %d", ErrNoCheckpoint, target)
+	}
+	var save SimulationSave
+	if err := json.Unmarshal(found.Save, &save); err != nil {
+		return GameState{}, fmt.Errorf("%w: %v", repository.ErrInvalidData, err)
+	}
+
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	if g.timeline != timeline {
+		return GameState{}, fmt.Errorf("%w: wave %d", ErrNoCheckpoint, target) // reset or loaded meanwhile
+	}
+	rollbacks := g.state.Rollbacks
+	if limit := g.config.Game.MaxRollbacks; limit >= 0 && rollbacks >= limit {
+		return GameState{}, fmt.Errorf("%w: %d of %d used", ErrRollbackLimit, rollbacks, limit)
+	}
+	if err := g.restoreSimulation(save, time.Now()); err != nil {
+		return GameState{}, err
+	}
+	g.state.Rollbacks = rollbacks + 1
+	g.emit(events.Event{Type: events.RolledBack, Wave: target})
+
+	logging.Infow("game_rolled_back", "game_id", g.id, "from_wave", from, "to_wave", target, "rollbacks", g.state.Rollbacks)
+	return g.state, nil
+}
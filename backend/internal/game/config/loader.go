@@ -1,8 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"embed"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,13 +18,25 @@ var configFS embed.FS
 
 // GameConfig represents the entire game configuration
 type GameConfig struct {
-	Game       GameSettings       `yaml:"game"`
-	Towers     map[string]TowerConfig     `yaml:"towers"`
-	Enemies    map[string]EnemyConfig     `yaml:"enemies"`
+	Game        GameSettings                `yaml:"game"`
+	Towers      map[string]TowerConfig      `yaml:"towers"`
+	Enemies     map[string]EnemyConfig      `yaml:"enemies"`
 	Projectiles map[string]ProjectileConfig `yaml:"projectiles"`
-	Waves      WaveConfig         `yaml:"waves"`
-	Map        MapConfig          `yaml:"map"`
-	Placement  PlacementConfig    `yaml:"placement"`
+	Waves       WaveConfig                  `yaml:"waves"`
+	Map         MapConfig                   `yaml:"map"`
+	Placement   PlacementConfig             `yaml:"placement"`
+	Economy     EconomyConfig               `yaml:"economy"`
+	Bosses      map[string]BossConfig       `yaml:"bosses"`
+	Auras       AuraRules                   `yaml:"auras"`
+	Walls       WallConfig                  `yaml:"walls"`
+	Accuracy    AccuracyConfig              `yaml:"accuracy"`
+	Analytics   AnalyticsConfig             `yaml:"analytics"`
+	Lives       LivesConfig                 `yaml:"lives"`
+	Visibility  VisibilityConfig            `yaml:"visibility"`
+	Ammo        AmmoConfig                  `yaml:"ammo"`
+	Terrain     TerrainConfig               `yaml:"terrain"`
+	Elites      EliteConfig                 `yaml:"elites"`
+	Rules       RulesConfig                 `yaml:"rules"`
 }
 
 type GameSettings struct {
@@ -26,55 +44,433 @@ type GameSettings struct {
 	StartingLives       int `yaml:"starting_lives"`
 	TickRateMs          int `yaml:"tick_rate_ms"`
 	BroadcastIntervalMs int `yaml:"broadcast_interval_ms"`
+
+	// How long removed entities stay listed in snapshots, so clients can play
+	// death animations; 0 disables the list
+	CorpseGraceMs int `yaml:"corpse_grace_ms"`
+
+	// Waves a game may retry from its checkpoints, -1 = unlimited; ranked
+	// rooms set a limit at creation
+	MaxRollbacks int `yaml:"max_rollbacks"`
+
+	// Wave whose clearing wins the game, 0 = endless; no later wave starts
+	VictoryWave int `yaml:"victory_wave"`
 }
 
 type TowerConfig struct {
-	Cost         int     `yaml:"cost"`
-	Range        float64 `yaml:"range"`
-	Damage       int     `yaml:"damage"`
-	FireRate     float64 `yaml:"fire_rate"`
-	SplashRadius float64 `yaml:"splash_radius,omitempty"`
+	Cost         int         `yaml:"cost"`
+	Range        float64     `yaml:"range"`
+	Damage       int         `yaml:"damage"`
+	FireRate     float64     `yaml:"fire_rate"`
+	SplashRadius float64     `yaml:"splash_radius,omitempty"`
+	Aura         *AuraConfig `yaml:"aura,omitempty"` // support tower: buffs nearby towers instead of shooting
+
+	// Per-tower overrides of the global accuracy section; unset fields use the global value
+	CritChance     *float64 `yaml:"crit_chance,omitempty"`
+	CritMultiplier *float64 `yaml:"crit_multiplier,omitempty"`
+	MissChance     *float64 `yaml:"miss_chance,omitempty"`
+
+	Ammo int `yaml:"ammo,omitempty"` // magazine size under the ammo rule; 0 = ammo.capacity
 }
 
-type EnemyConfig struct {
+// AccuracyConfig controls critical hits and misses, resolved when a projectile lands
+type AccuracyConfig struct {
+	CritChance     float64 `yaml:"crit_chance"`     // probability of a critical hit, 0-1
+	CritMultiplier float64 `yaml:"crit_multiplier"` // damage multiplier of a critical hit
+	MissChance     float64 `yaml:"miss_chance"`     // probability the projectile deals no damage, 0-1
+}
+
+// AnalyticsConfig controls the per-game analytics collected for players and designers
+type AnalyticsConfig struct {
+	HeatmapCellSize float64 `yaml:"heatmap_cell_size"` // side of a heatmap grid cell, in map units
+}
+
+// GetAccuracy returns the accuracy of a tower type: its overrides on top of the global section
+func (c *GameConfig) GetAccuracy(towerType string) AccuracyConfig {
+	acc := c.Accuracy
+	t := c.Towers[towerType]
+	if t.CritChance != nil {
+		acc.CritChance = *t.CritChance
+	}
+	if t.CritMultiplier != nil {
+		acc.CritMultiplier = *t.CritMultiplier
+	}
+	if t.MissChance != nil {
+		acc.MissChance = *t.MissChance
+	}
+	return acc
+}
+
+// AuraConfig is the buff a support tower grants to every shooting tower within Radius.
+// Bonuses are fractions of the base stat, e.g. 0.25 = +25%.
+type AuraConfig struct {
+	Radius   float64 `yaml:"radius"`
+	Damage   float64 `yaml:"damage,omitempty"`
+	FireRate float64 `yaml:"fire_rate,omitempty"`
+	Range    float64 `yaml:"range,omitempty"`
+}
+
+// WallConfig controls the walls players build on the path to hold enemies up.
+// Enemies stop in front of a wall and attack it until it breaks.
+type WallConfig struct {
+	Cost        int     `yaml:"cost"`
 	HP          int     `yaml:"hp"`
-	Speed       float64 `yaml:"speed"`
-	GoldReward  int     `yaml:"gold_reward"`
-	ScoreReward int     `yaml:"score_reward"`
+	Radius      float64 `yaml:"radius"`       // enemies are stopped at this distance from the wall
+	MaxWalls    int     `yaml:"max_walls"`    // 0 disables walls
+	MinSpacing  float64 `yaml:"min_spacing"`  // minimum distance between walls
+	EnemyDamage float64 `yaml:"enemy_damage"` // default damage per second an enemy deals to a wall
+}
+
+// Aura stacking modes
+const (
+	AuraStackHighest  = "highest"  // only the strongest aura counts, per stat
+	AuraStackAdditive = "additive" // bonuses of overlapping auras add up
+)
+
+// AuraRules controls how overlapping auras combine
+type AuraRules struct {
+	Stacking string  `yaml:"stacking"`  // highest (default) or additive
+	MaxBonus float64 `yaml:"max_bonus"` // cap per stat after stacking, 0 = uncapped
+}
+
+type EnemyConfig struct {
+	HP          int            `yaml:"hp"`
+	Speed       float64        `yaml:"speed"`
+	GoldReward  int            `yaml:"gold_reward"`
+	ScoreReward int            `yaml:"score_reward"`
+	WallDamage  float64        `yaml:"wall_damage,omitempty"` // damage per second to walls, 0 = walls.enemy_damage
+	LifeReward  int            `yaml:"life_reward,omitempty"` // lives dropped when killed
+	Abilities   EnemyAbilities `yaml:"abilities,omitempty"`
+}
+
+// EnemyAbilities are the behaviors of an enemy type beyond walking
+type EnemyAbilities struct {
+	Heal         *PulseAbility `yaml:"heal,omitempty"`           // restores HP of nearby enemies
+	Shield       *PulseAbility `yaml:"shield,omitempty"`         // tops up the shield of nearby enemies
+	SpawnOnDeath *SummonConfig `yaml:"spawn_on_death,omitempty"` // units released when killed
+}
+
+// Any reports whether the enemy type has at least one ability
+func (a EnemyAbilities) Any() bool {
+	return a.Heal != nil || a.Shield != nil || a.SpawnOnDeath != nil
+}
+
+// PulseAbility affects every enemy within Radius, including the caster, every Interval seconds
+type PulseAbility struct {
+	Radius   float64 `yaml:"radius"`
+	Amount   int     `yaml:"amount"`
+	Interval float64 `yaml:"interval"`
 }
 
 type ProjectileConfig struct {
-	Speed float64 `yaml:"speed"`
+	Speed    float64 `yaml:"speed"`
+	Behavior string  `yaml:"behavior,omitempty"` // homing (default), beam, arc, pierce or chain
+
+	Duration  float64 `yaml:"duration,omitempty"`   // beam/chain: seconds the spent shot stays visible
+	HitRadius float64 `yaml:"hit_radius,omitempty"` // pierce: how close an enemy must pass to be hit
+	MaxHits   int     `yaml:"max_hits,omitempty"`   // pierce: enemies hit before the shot stops, 0 = unlimited
+
+	Jumps      int     `yaml:"jumps,omitempty"`       // chain: additional enemies hit after the target
+	JumpRadius float64 `yaml:"jump_radius,omitempty"` // chain: max distance between consecutive enemies
+	Falloff    float64 `yaml:"falloff,omitempty"`     // chain: fraction of damage lost on each jump
+
+	Lead        bool    `yaml:"lead,omitempty"`         // homing/arc/pierce: aim where the target will be, not where it is
+	MaxLifetime float64 `yaml:"max_lifetime,omitempty"` // seconds in flight before the shot expires, 0 = no limit
 }
 
+// Projectile behaviors
+const (
+	ProjectileHoming = "homing" // chases its target, fizzles if the target dies
+	ProjectileBeam   = "beam"   // hits instantly
+	ProjectileArc    = "arc"    // lobbed at the target's position, damages the splash area on landing
+	ProjectilePierce = "pierce" // flies straight to the end of the tower range hitting everything on the line
+	ProjectileChain  = "chain"  // hits instantly, then jumps to nearby enemies with decreasing damage
+)
+
+
 type WaveConfig struct {
-	SpawnIntervalTicks       int     `yaml:"spawn_interval_ticks"`
-	EnemiesPerWaveBase       int     `yaml:"enemies_per_wave_base"`
-	EnemiesPerWaveMultiplier float64 `yaml:"enemies_per_wave_multiplier"`
-	HPScalePerWave           float64 `yaml:"hp_scale_per_wave"`
+	SpawnIntervalTicks       int             `yaml:"spawn_interval_ticks"`
+	EnemiesPerWaveBase       int             `yaml:"enemies_per_wave_base"`
+	EnemiesPerWaveMultiplier float64         `yaml:"enemies_per_wave_multiplier"`
+	HPScalePerWave           float64         `yaml:"hp_scale_per_wave"`
 	EarlyWaves               WaveComposition `yaml:"early_waves"`
 	MidWaves                 WaveComposition `yaml:"mid_waves"`
 	LateWaves                WaveComposition `yaml:"late_waves"`
 	BossWaves                WaveComposition `yaml:"boss_waves"`
+	SpawnPatterns            []SpawnPattern  `yaml:"spawn_patterns"`
+
+	Modifiers WaveModifierRules `yaml:"modifiers"`
+	Pacing    WavePacing        `yaml:"pacing"` // when the next wave starts; maps may override it
+}
+
+// Wave pacing modes
+const (
+	PacingTimer      = "timer"       // a wave starts every interval, cleared or not
+	PacingAfterClear = "after_clear" // the next wave starts as soon as the last one is cleared
+	PacingHybrid     = "hybrid"      // the interval counts from the moment the last wave is cleared
+)
+
+// PacingModes lists the wave pacing modes
+var PacingModes = []string{PacingTimer, PacingAfterClear, PacingHybrid}
+
+// WavePacing controls when the next wave starts. Under a map, zero values keep
+// those of the balance.
+type WavePacing struct {
+	Mode           string `yaml:"mode"`            // timer, after_clear or hybrid; "" = timer
+	IntervalMs     int    `yaml:"interval_ms"`     // between waves; after_clear only waits it before the first wave
+	IntermissionMs int    `yaml:"intermission_ms"` // build time after a cleared wave before the next may start
+}
+
+// WavePacingFor returns the wave pacing of a map: the balance's, with the
+// values the map sets itself
+func (c *GameConfig) WavePacingFor(m MapConfig) WavePacing {
+	p := c.Waves.Pacing
+	if o := m.WavePacing; o != nil {
+		if o.Mode != "" {
+			p.Mode = o.Mode
+		}
+		if o.IntervalMs > 0 {
+			p.IntervalMs = o.IntervalMs
+		}
+		if o.IntermissionMs > 0 {
+			p.IntermissionMs = o.IntermissionMs
+		}
+	}
+	return p
+}
+
+// WaveModifierRules controls the modifiers that change a whole wave, announced
+// one wave in advance. From StartWave on each wave has Chance of getting one
+// modifier from Registry, picked by weight.
+type WaveModifierRules struct {
+	StartWave int                     `yaml:"start_wave"` // 0 = waves are never modified
+	Chance    float64                 `yaml:"chance"`
+	Registry  map[string]WaveModifier `yaml:"registry"` // keyed by modifier ID
+}
+
+// WaveModifier is one entry of the wave modifier registry. Stats are
+// multipliers applied to every enemy of the wave; 0 leaves a stat unchanged.
+type WaveModifier struct {
+	Description string  `yaml:"description"` // shown to players when the modifier is announced
+	Weight      int     `yaml:"weight"`
+	Speed       float64 `yaml:"speed,omitempty"`
+	HP          float64 `yaml:"hp,omitempty"`
+	Count       float64 `yaml:"count,omitempty"` // enemies of each type in the wave
+	Gold        float64 `yaml:"gold,omitempty"`  // kill bounty
+}
+
+// Factor returns a modifier multiplier, treating 0 as unchanged
+func Factor(multiplier float64) float64 {
+	if multiplier == 0 {
+		return 1
+	}
+	return multiplier
+}
+
+// ScaleCount applies a count multiplier to the enemies of one type in a wave
+func ScaleCount(n int, multiplier float64) int {
+	return int(math.Round(float64(n) * Factor(multiplier)))
+}
+
+// WaveModifierFor returns the ID of the modifier of a wave, or "" for a plain
+// wave. The choice depends only on the wave and seed, so upcoming waves can be
+// announced before they start.
+func (c *GameConfig) WaveModifierFor(wave int, seed int64) string {
+	rules := c.Waves.Modifiers
+	if rules.StartWave <= 0 || wave < rules.StartWave || len(rules.Registry) == 0 {
+		return ""
+	}
+	r := rand.New(rand.NewSource(seed ^ int64(wave)*0x5851F42D4C957F2D))
+	if r.Float64() >= rules.Chance {
+		return ""
+	}
+
+	ids := make([]string, 0, len(rules.Registry))
+	total := 0
+	for id, m := range rules.Registry {
+		ids = append(ids, id)
+		total += m.Weight
+	}
+	sort.Strings(ids)
+	target := r.Intn(total)
+	for _, id := range ids {
+		target -= rules.Registry[id].Weight
+		if target < 0 {
+			return id
+		}
+	}
+	return ids[len(ids)-1]
+}
+
+// Spawn pattern types
+const (
+	SpawnBurst     = "burst"     // whole wave in quick succession
+	SpawnTrickle   = "trickle"   // steady stream with optional jitter
+	SpawnAlternate = "alternate" // trickle that alternates between entrances
+	SpawnSquads    = "squads"    // groups separated by a longer gap
+)
+
+// Entrance selection modes
+const (
+	EntranceSingle    = "single"
+	EntranceAlternate = "alternate"
+	EntranceRandom    = "random"
+)
+
+// SpawnPattern controls the rhythm of enemy spawns within a wave
+type SpawnPattern struct {
+	FromWave   int    `yaml:"from_wave"`       // applies from this wave until the next pattern
+	Waves      []int  `yaml:"waves,omitempty"` // explicit waves; take precedence over from_wave ranges
+	Type       string `yaml:"type"`            // burst, trickle, alternate, squads
+	IntervalMs int    `yaml:"interval_ms"`     // delay between enemies (within a squad)
+	JitterMs   int    `yaml:"jitter_ms"`       // random extra delay added to interval
+	SquadSize  int    `yaml:"squad_size"`      // enemies per squad
+	SquadGapMs int    `yaml:"squad_gap_ms"`    // delay between squads
+	Entrances  string `yaml:"entrances"`       // single, alternate, random
+}
+
+// EntranceMode returns how enemies are distributed over map entrances
+func (p SpawnPattern) EntranceMode() string {
+	if p.Entrances != "" {
+		return p.Entrances
+	}
+	if p.Type == SpawnAlternate {
+		return EntranceAlternate
+	}
+	return EntranceSingle
 }
 
 type WaveComposition struct {
-	Basic int `yaml:"basic,omitempty"`
-	Fast  int `yaml:"fast,omitempty"`
-	Tank  int `yaml:"tank,omitempty"`
-	Boss  int `yaml:"boss,omitempty"`
+	Basic    int `yaml:"basic,omitempty"`
+	Fast     int `yaml:"fast,omitempty"`
+	Tank     int `yaml:"tank,omitempty"`
+	Healer   int `yaml:"healer,omitempty"`
+	Shielder int `yaml:"shielder,omitempty"`
+	Carrier  int `yaml:"carrier,omitempty"`
+	Boss     int `yaml:"boss,omitempty"`
 }
 
+// TypeWeight is the share of one enemy type in a wave composition
+type TypeWeight struct {
+	EnemyType string
+	Weight    int
+}
+
+// Weights lists every enemy type of the composition with its weight. Rounding
+// leftovers go to the first type with a positive weight, so the order matters.
+func (w WaveComposition) Weights() []TypeWeight {
+	return []TypeWeight{
+		{"basic", w.Basic},
+		{"fast", w.Fast},
+		{"tank", w.Tank},
+		{"healer", w.Healer},
+		{"shielder", w.Shielder},
+		{"carrier", w.Carrier},
+		{"boss", w.Boss},
+	}
+}
+
+
 type MapConfig struct {
-	Name          string         `yaml:"name"`
-	Difficulty    string         `yaml:"difficulty"`
-	Description   string         `yaml:"description"`
-	Width         int            `yaml:"width"`
-	Height        int            `yaml:"height"`
-	Path          []Position     `yaml:"path"`
-	PathHalfWidth float64        `yaml:"path_half_width"`
-	StartingGold  int            `yaml:"starting_gold"`
-	StartingLives int            `yaml:"starting_lives"`
+	Name          string           `yaml:"name"`
+	Difficulty    string           `yaml:"difficulty"`
+	Description   string           `yaml:"description"`
+	Width         int              `yaml:"width"`
+	Height        int              `yaml:"height"`
+	Path          []Position       `yaml:"path"`
+	PathHalfWidth float64          `yaml:"path_half_width"`
+	Entrances     []EntranceConfig `yaml:"entrances,omitempty"`
+	StartingGold  int              `yaml:"starting_gold"`
+	StartingLives int              `yaml:"starting_lives"`
+
+	Zones     []ZoneConfig     `yaml:"zones,omitempty"`     // terrain areas, see TerrainConfig
+	Obstacles []ObstacleConfig `yaml:"obstacles,omitempty"` // terrain towers can't shoot through
+
+	WavePacing *WavePacing `yaml:"wave_pacing,omitempty"` // overrides waves.pacing on this map
+}
+
+// Terrain zone types
+const (
+	ZoneMud        = "mud"         // slows enemies walking through it
+	ZoneHighGround = "high_ground" // extends the range of towers built on it
+)
+
+// ZoneConfig is a rectangular terrain area of a map; X and Y are its top-left corner
+type ZoneConfig struct {
+	Type   string  `yaml:"type"`
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Width  float64 `yaml:"width"`
+	Height float64 `yaml:"height"`
+}
+
+// Contains reports whether the point lies inside the zone
+func (z ZoneConfig) Contains(x, y float64) bool {
+	return x >= z.X && x <= z.X+z.Width && y >= z.Y && y <= z.Y+z.Height
+}
+
+// ObstacleConfig is a rectangular piece of blocking terrain, such as a rock or a
+// building; X and Y are its top-left corner. Towers can't be built on it or
+// shoot through it, while enemies walk past it unhindered.
+type ObstacleConfig struct {
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Width  float64 `yaml:"width"`
+	Height float64 `yaml:"height"`
+}
+
+// Contains reports whether the point lies inside the obstacle
+func (o ObstacleConfig) Contains(x, y float64) bool {
+	return x >= o.X && x <= o.X+o.Width && y >= o.Y && y <= o.Y+o.Height
+}
+
+// Blocks reports whether the segment from (x1, y1) to (x2, y2) passes through
+// the obstacle, i.e. whether it blocks the line of sight between the two points
+func (o ObstacleConfig) Blocks(x1, y1, x2, y2 float64) bool {
+	// Clip the segment against each slab of the rectangle (Liang-Barsky)
+	lo, hi := 0.0, 1.0
+	clip := func(p, q float64) bool {
+		switch {
+		case p == 0:
+			return q >= 0
+		case p < 0:
+			lo = max(lo, q/p)
+		default:
+			hi = min(hi, q/p)
+		}
+		return lo <= hi
+	}
+	dx, dy := x2-x1, y2-y1
+	return clip(-dx, x1-o.X) && clip(dx, o.X+o.Width-x1) &&
+		clip(-dy, y1-o.Y) && clip(dy, o.Y+o.Height-y1)
+}
+
+// LineOfSight reports whether no obstacle of the map blocks the segment from
+// (x1, y1) to (x2, y2)
+func (m MapConfig) LineOfSight(x1, y1, x2, y2 float64) bool {
+	for _, o := range m.Obstacles {
+		if o.Blocks(x1, y1, x2, y2) {
+			return false
+		}
+	}
+	return true
+}
+
+// EntranceConfig is an additional spawn path; it must end at the exit like the main path
+type EntranceConfig struct {
+	Name string     `yaml:"name"`
+	Path []Position `yaml:"path"`
+}
+
+// AllPaths returns the main path followed by every entrance path
+func (m MapConfig) AllPaths() [][]Position {
+	paths := [][]Position{m.Path}
+	for _, e := range m.Entrances {
+		if len(e.Path) > 0 {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
 }
 
 type MapsConfig struct {
@@ -90,40 +486,264 @@ type PlacementConfig struct {
 	MinDistanceFromPath float64 `yaml:"min_distance_from_path"`
 	MinTowerSpacing     float64 `yaml:"min_tower_spacing"`
 	MaxTowers           int     `yaml:"max_towers"`
+	GridSize            float64 `yaml:"grid_size"` // side of a build template grid cell, in map units
+}
+
+type EconomyConfig struct {
+	InterestRate float64 `yaml:"interest_rate"` // fraction of unspent gold paid at the end of each wave
+	InterestCap  int     `yaml:"interest_cap"`  // max interest per wave, 0 = uncapped
+
+	WaveBonus int           `yaml:"wave_bonus"` // flat gold for completing a wave
+	LifeBonus int           `yaml:"life_bonus"` // gold per remaining life for completing a wave
+	Bounty    BountyScaling `yaml:"bounty"`
+
+	SellRefund         float64 `yaml:"sell_refund"`          // share of its price a sold tower returns, 0 = sell only between waves
+	BetweenWaveRefunds bool    `yaml:"between_wave_refunds"` // towers sold between a cleared wave and the next return their full price
+
+	Wallets WalletConfig `yaml:"wallets"`
+}
+
+// WalletConfig controls gold transfers in rooms where every player has a wallet of their own
+type WalletConfig struct {
+	Transfers   bool    `yaml:"transfers"`    // players may give gold to each other
+	TransferTax float64 `yaml:"transfer_tax"` // share of each transfer that is lost, rounded down
+}
+
+// LivesConfig controls how lives are won back
+type LivesConfig struct {
+	MaxLives    int `yaml:"max_lives"`    // lives can't be gained beyond this, 0 = uncapped
+	RegenEvery  int `yaml:"regen_every"`  // a life regeneration every this many completed waves, 0 = off
+	RegenAmount int `yaml:"regen_amount"` // lives restored by each regeneration
+}
+
+// Elite affixes
+const (
+	AffixRegenerating    = "regenerating"     // heals a share of max HP every second
+	AffixSplashResistant = "splash_resistant" // takes reduced splash damage
+	AffixPhasing         = "phasing"          // periodically can't be targeted by towers
+)
+
+// EliteConfig controls elite enemies in the endless late game: from StartWave on,
+// each spawned enemy has Chance of rolling one affix, picked by weight
+type EliteConfig struct {
+	StartWave int                    `yaml:"start_wave"` // 0 = no elites
+	Chance    float64                `yaml:"chance"`
+	Affixes   map[string]AffixConfig `yaml:"affixes"` // keyed by the Affix* names
+}
+
+// AffixConfig is the weight and strength of one elite affix; only the fields
+// of its own affix are used
+type AffixConfig struct {
+	Weight        int     `yaml:"weight"`
+	Regen         float64 `yaml:"regen,omitempty"`          // regenerating: fraction of max HP healed per second
+	SplashResist  float64 `yaml:"splash_resist,omitempty"`  // splash_resistant: fraction of splash damage ignored
+	PhaseEvery    float64 `yaml:"phase_every,omitempty"`    // phasing: seconds between phases
+	PhaseDuration float64 `yaml:"phase_duration,omitempty"` // phasing: seconds a phase lasts
+}
+
+// PickAffix returns the affix a roll in [0, 1) lands on, weighted by the
+// configured weights; affixes are taken in name order so rolls are reproducible
+func (e EliteConfig) PickAffix(roll float64) string {
+	names := make([]string, 0, len(e.Affixes))
+	total := 0
+	for name, a := range e.Affixes {
+		names = append(names, name)
+		total += a.Weight
+	}
+	sort.Strings(names)
+	target := roll * float64(total)
+	for _, name := range names {
+		target -= float64(e.Affixes[name].Weight)
+		if target < 0 {
+			return name
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return names[len(names)-1]
+}
+
+// VisibilityConfig controls fog of war: while enabled, players only see the
+// enemies within sight of their own towers and walls
+type VisibilityConfig struct {
+	Enabled    bool    `yaml:"enabled"`     // usually switched on per room with the fog_of_war mutator
+	TowerSight float64 `yaml:"tower_sight"` // towers see this many times their range
+	WallSight  float64 `yaml:"wall_sight"`  // walls scout this far around them, in map units
+}
+
+// AmmoConfig controls the hardcore ammunition rule: every shot uses a round and
+// empty towers stop firing until they are resupplied with gold
+type AmmoConfig struct {
+	Enabled      bool    `yaml:"enabled"`       // usually switched on per room with the ammo mutator
+	Capacity     int     `yaml:"capacity"`      // rounds a tower holds unless its type sets ammo
+	RoundCost    float64 `yaml:"round_cost"`    // gold per round; a resupply is rounded up to whole gold
+	AutoResupply bool    `yaml:"auto_resupply"` // refill empty towers automatically while gold lasts
+}
+
+// Player actions the rules can restrict
+const (
+	ActionBuild     = "build"     // placing towers, directly or from a template, and building queued blueprints
+	ActionSell      = "sell"      // selling one or all towers
+	ActionWall      = "wall"      // building walls
+	ActionResupply  = "resupply"  // refilling a tower's ammunition by hand
+	ActionBlueprint = "blueprint" // queueing and cancelling blueprints
+	ActionTransfer  = "transfer"  // giving gold to another player
+)
+
+// Actions lists every action the rules can restrict
+var Actions = []string{ActionBuild, ActionSell, ActionWall, ActionResupply, ActionBlueprint, ActionTransfer}
+
+// Phases a game moves through: Building until the first wave, then WaveActive
+// and Intermission in turn as waves start and are cleared
+const (
+	PhaseBuilding     = "building"     // before the first wave
+	PhaseWaveActive   = "wave_active"  // a wave is spawning or its enemies are on the field
+	PhaseIntermission = "intermission" // the last wave is cleared, the next one hasn't started
+)
+
+// Phases lists the phases of a game in order
+var Phases = []string{PhaseBuilding, PhaseWaveActive, PhaseIntermission}
+
+// RulesConfig restricts player actions by phase of the game. Combat lasts while
+// enemies are on the field or still to spawn.
+type RulesConfig struct {
+	DuringCombat []string            `yaml:"during_combat,omitempty"` // actions not allowed in combat
+	Phases       map[string][]string `yaml:"phases,omitempty"`        // actions not allowed, by phase
+	FreeSell     []string            `yaml:"free_sell,omitempty"`     // phases in which sold towers return their full price
+}
+
+// Weather kinds
+const (
+	WeatherRain = "rain" // lowers the fire rate of every tower
+)
+
+// TerrainConfig sets the strength of map terrain zones and the weather cycle
+type TerrainConfig struct {
+	MudSlow         float64       `yaml:"mud_slow"`          // fraction of speed enemies lose in mud
+	HighGroundRange float64       `yaml:"high_ground_range"` // fraction of range towers gain on high ground
+	Weather         WeatherConfig `yaml:"weather"`
+}
+
+// WeatherConfig schedules weather events: after every Every seconds of clear
+// skies it rains for Duration seconds
+type WeatherConfig struct {
+	Every        float64 `yaml:"every"`          // 0 = always clear
+	Duration     float64 `yaml:"duration"`
+	RainFireRate float64 `yaml:"rain_fire_rate"` // fraction of fire rate towers lose in the rain
+}
+
+// MagazineSize returns how many rounds a tower type holds under the ammo rule
+func (c *GameConfig) MagazineSize(towerType string) int {
+	if n := c.Towers[towerType].Ammo; n > 0 {
+		return n
+	}
+	return c.Ammo.Capacity
+}
+
+// BountyScaling shrinks kill gold in the late game. From StartWave on, every
+// wave keeps 1-Decay of the previous wave's bounty, never less than Floor of
+// the enemy's configured gold_reward.
+type BountyScaling struct {
+	StartWave int     `yaml:"start_wave"` // 0 = bounties never shrink
+	Decay     float64 `yaml:"decay"`
+	Floor     float64 `yaml:"floor"`
+}
+
+// BossConfig scripts the behavior of a boss enemy type
+type BossConfig struct {
+	Phases []BossPhase `yaml:"phases"`
+}
+
+// BossPhase is triggered once when the boss HP fraction drops to HPThreshold
+type BossPhase struct {
+	Name        string            `yaml:"name"`
+	HPThreshold float64           `yaml:"hp_threshold"`
+	Summon      *SummonConfig     `yaml:"summon,omitempty"`
+	Shield      *ShieldConfig     `yaml:"shield,omitempty"`
+	SpeedBurst  *SpeedBurstConfig `yaml:"speed_burst,omitempty"`
+}
+
+type SummonConfig struct {
+	EnemyType string `yaml:"enemy_type"`
+	Count     int    `yaml:"count"`
+}
+
+type ShieldConfig struct {
+	Amount   int     `yaml:"amount"`   // damage absorbed
+	Duration float64 `yaml:"duration"` // seconds
+}
+
+type SpeedBurstConfig struct {
+	Multiplier float64 `yaml:"multiplier"`
+	Duration   float64 `yaml:"duration"` // seconds
+}
+
+// GetBossConfig returns the boss script for an enemy type, if any
+func (c *GameConfig) GetBossConfig(enemyType string) (BossConfig, bool) {
+	cfg, ok := c.Bosses[enemyType]
+	return cfg, ok
 }
 
+// ErrUnknownTowerType is returned for tower types missing from the config
+var ErrUnknownTowerType = errors.New("unknown tower type")
+
 // Global config instance
 var Config *GameConfig
 var Maps *MapsConfig
 
-// Load reads and parses the balance configuration
+// mapsMu guards the globals against concurrent reloads
+var mapsMu sync.RWMutex
+
+// Load reads and parses the embedded balance configuration
 func Load() (*GameConfig, error) {
-	data, err := configFS.ReadFile("balance.yaml")
+	return LoadWithOverrides("")
+}
+
+// LoadWithOverrides reads the embedded configuration and overlays balance.yaml and
+// maps.yaml from dir when present. Override files only need the keys they change.
+// The merged result is validated; on failure the previously loaded config is kept.
+func LoadWithOverrides(dir string) (*GameConfig, error) {
+	data, err := readLayered("balance.yaml", dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var cfg GameConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := decodeStrict(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	// Load maps configuration
-	mapsData, err := configFS.ReadFile("maps.yaml")
+	mapsData, err := readLayered("maps.yaml", dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read maps file: %w", err)
 	}
 
 	var mapsCfg MapsConfig
-	if err := yaml.Unmarshal(mapsData, &mapsCfg); err != nil {
+	if err := decodeStrict(mapsData, &mapsCfg); err != nil {
 		return nil, fmt.Errorf("failed to parse maps: %w", err)
 	}
 
+	// Reject broken configs up front rather than failing mid-game
+	if err := Validate(&cfg, &mapsCfg); err != nil {
+		return nil, err
+	}
+
+	mapsMu.Lock()
 	Config = &cfg
 	Maps = &mapsCfg
+	mapsMu.Unlock()
 	return &cfg, nil
 }
 
+// decodeStrict unmarshals YAML and rejects unknown keys so typos are reported
+func decodeStrict(data []byte, out interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(out)
+}
+
 // MustLoad loads config or panics
 func MustLoad() *GameConfig {
 	cfg, err := Load()
@@ -137,7 +757,7 @@ func MustLoad() *GameConfig {
 func (c *GameConfig) GetTowerConfig(towerType string) (TowerConfig, error) {
 	cfg, ok := c.Towers[towerType]
 	if !ok {
-		return TowerConfig{}, fmt.Errorf("unknown tower type: %s", towerType)
+		return TowerConfig{}, fmt.Errorf("%w: %s", ErrUnknownTowerType, towerType)
 	}
 	return cfg, nil
 }
@@ -172,6 +792,28 @@ func (c *GameConfig) GetWaveComposition(wave int) WaveComposition {
 	return c.Waves.LateWaves
 }
 
+// defaultSpawnPattern reproduces the original 120-300ms random trickle
+var defaultSpawnPattern = SpawnPattern{Type: SpawnTrickle, IntervalMs: 120, JitterMs: 180}
+
+// GetSpawnPattern returns the spawn pattern for a given wave number
+func (c *GameConfig) GetSpawnPattern(wave int) SpawnPattern {
+	for _, p := range c.Waves.SpawnPatterns {
+		for _, w := range p.Waves {
+			if w == wave {
+				return p
+			}
+		}
+	}
+
+	pattern, best := defaultSpawnPattern, 0
+	for _, p := range c.Waves.SpawnPatterns {
+		if len(p.Waves) == 0 && p.FromWave <= wave && p.FromWave >= best {
+			pattern, best = p, p.FromWave
+		}
+	}
+	return pattern
+}
+
 // CalculateEnemiesForWave returns number of enemies to spawn for a wave
 func (c *GameConfig) CalculateEnemiesForWave(wave int) int {
 	base := float64(c.Waves.EnemiesPerWaveBase)
@@ -180,6 +822,51 @@ func (c *GameConfig) CalculateEnemiesForWave(wave int) int {
 	return int(count)
 }
 
+// TypeCount is how many enemies of one type a wave spawns
+type TypeCount struct {
+	EnemyType string
+	Count     int
+}
+
+// WaveEnemyCounts splits the enemies of a wave between the types of its
+// composition, in composition order. Types with no enemies are left out.
+func (c *GameConfig) WaveEnemyCounts(wave int) []TypeCount {
+	total := c.CalculateEnemiesForWave(wave)
+	weights := c.GetWaveComposition(wave).Weights()
+	totalWeight := 0
+	for _, tw := range weights {
+		totalWeight += tw.Weight
+	}
+	if totalWeight == 0 {
+		totalWeight = 100 // Default if not specified
+		weights = []TypeWeight{{EnemyType: "basic", Weight: 100}}
+	}
+
+	counts := make([]int, len(weights))
+	assigned := 0
+	for i, tw := range weights {
+		counts[i] = (total * tw.Weight) / totalWeight
+		assigned += counts[i]
+	}
+	// The rounding remainder goes to the first type in the composition
+	if assigned < total {
+		for i, tw := range weights {
+			if tw.Weight > 0 {
+				counts[i] += total - assigned
+				break
+			}
+		}
+	}
+
+	var result []TypeCount
+	for i, tw := range weights {
+		if counts[i] > 0 {
+			result = append(result, TypeCount{EnemyType: tw.EnemyType, Count: counts[i]})
+		}
+	}
+	return result
+}
+
 // ScaleEnemyHP scales enemy HP based on wave number
 func (c *GameConfig) ScaleEnemyHP(baseHP int, wave int) int {
 	if wave <= 1 {
@@ -192,6 +879,9 @@ func (c *GameConfig) ScaleEnemyHP(baseHP int, wave int) int {
 
 // GetMapConfig returns config for a map by ID
 func GetMapConfig(mapID string) (MapConfig, error) {
+	mapsMu.RLock()
+	defer mapsMu.RUnlock()
+	
 	if Maps == nil {
 		return MapConfig{}, fmt.Errorf("maps not loaded")
 	}
@@ -205,6 +895,9 @@ func GetMapConfig(mapID string) (MapConfig, error) {
 
 // ListMaps returns all available map IDs
 func ListMaps() []string {
+	mapsMu.RLock()
+	defer mapsMu.RUnlock()
+	
 	if Maps == nil {
 		return []string{}
 	}
@@ -215,3 +908,33 @@ func ListMaps() []string {
 	}
 	return maps
 }
+
+// Clone returns a copy of the config that can be modified without affecting the original.
+// Maps and the spawn pattern list are copied; map geometry is shared as it is never mutated.
+func (c *GameConfig) Clone() *GameConfig {
+	clone := *c
+	clone.Towers = make(map[string]TowerConfig, len(c.Towers))
+	for k, v := range c.Towers {
+		clone.Towers[k] = v
+	}
+	clone.Enemies = make(map[string]EnemyConfig, len(c.Enemies))
+	for k, v := range c.Enemies {
+		clone.Enemies[k] = v
+	}
+	clone.Projectiles = make(map[string]ProjectileConfig, len(c.Projectiles))
+	for k, v := range c.Projectiles {
+		clone.Projectiles[k] = v
+	}
+	clone.Bosses = make(map[string]BossConfig, len(c.Bosses))
+	for k, v := range c.Bosses {
+		clone.Bosses[k] = v
+	}
+	clone.Waves.SpawnPatterns = append([]SpawnPattern(nil), c.Waves.SpawnPatterns...)
+	clone.Rules.DuringCombat = append([]string(nil), c.Rules.DuringCombat...)
+	clone.Rules.Phases = make(map[string][]string, len(c.Rules.Phases))
+	for k, v := range c.Rules.Phases {
+		clone.Rules.Phases[k] = append([]string(nil), v...)
+	}
+	clone.Rules.FreeSell = append([]string(nil), c.Rules.FreeSell...)
+	return &clone
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownMutator is returned for a mutator ID that is not in the registry
+var ErrUnknownMutator = errors.New("unknown mutator")
+
+// Mutator is a custom rule picked when a room is created. It is applied as an
+// overlay on that game's copy of the config, so other games are unaffected.
+type Mutator struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	apply       func(*GameConfig)
+}
+
+// mutators is the registry of supported mutators, in the order they are applied
+var mutators = []Mutator{
+	{ID: "double_enemy_speed", Description: "Enemies move twice as fast", apply: func(c *GameConfig) {
+		for enemyType, ec := range c.Enemies {
+			ec.Speed *= 2
+			c.Enemies[enemyType] = ec
+		}
+	}},
+	{ID: "half_tower_cost", Description: "Towers cost half as much", apply: func(c *GameConfig) {
+		for towerType, tc := range c.Towers {
+			tc.Cost = max(tc.Cost/2, 1)
+			c.Towers[towerType] = tc
+		}
+	}},
+	{ID: "fog_of_war", Description: "Players only see enemies near their own towers and walls", apply: func(c *GameConfig) {
+		c.Visibility.Enabled = true
+	}},
+	{ID: "ammo", Description: "Towers use ammunition and must be resupplied with gold", apply: func(c *GameConfig) {
+		c.Ammo.Enabled = true
+	}},
+	{ID: "no_sniper", Description: "Sniper towers can't be built", apply: func(c *GameConfig) {
+		delete(c.Towers, "sniper")
+	}},
+	{ID: "free_rebuilds", Description: "Towers sold between waves return their full price", apply: func(c *GameConfig) {
+		c.Economy.BetweenWaveRefunds = true
+	}},
+	{ID: "no_combat_selling", Description: "Towers can't be sold while enemies are on the field", apply: func(c *GameConfig) {
+		if !slices.Contains(c.Rules.DuringCombat, ActionSell) {
+			c.Rules.DuringCombat = append(c.Rules.DuringCombat, ActionSell)
+		}
+	}},
+}
+
+// Mutators returns the supported mutators
+func Mutators() []Mutator {
+	return slices.Clone(mutators)
+}
+
+// WithMutators returns a copy of c with the given mutators applied in registry
+// order, along with their IDs in that order. Unknown IDs are rejected.
+func (c *GameConfig) WithMutators(ids []string) (*GameConfig, []string, error) {
+	for _, id := range ids {
+		if !slices.ContainsFunc(mutators, func(m Mutator) bool { return m.ID == id }) {
+			supported := make([]string, len(mutators))
+			for i, m := range mutators {
+				supported[i] = m.ID
+			}
+			return nil, nil, fmt.Errorf("%w %q, supported: %s", ErrUnknownMutator, id, strings.Join(supported, ", "))
+		}
+	}
+
+	clone := c.Clone()
+	applied := []string{}
+	for _, m := range mutators {
+		if slices.Contains(ids, m.ID) {
+			m.apply(clone)
+			applied = append(applied, m.ID)
+		}
+	}
+	return clone, applied, nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readLayered returns the embedded file deep-merged with the same file from dir, if any
+func readLayered(name, dir string) ([]byte, error) {
+	base, err := configFS.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return base, nil
+	}
+
+	override, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return base, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseTree, overrideTree map[string]interface{}
+	if err := yaml.Unmarshal(base, &baseTree); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(override, &overrideTree); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(mergeTrees(baseTree, overrideTree))
+}
+
+// mergeTrees overlays src onto dst recursively; non-map values (including lists) replace
+func mergeTrees(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{})
+	}
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[k] = mergeTrees(dstMap, srcMap)
+		} else {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// Watch polls the override files in dir and calls onChange when either is modified.
+// It returns when ctx is cancelled.
+func Watch(ctx context.Context, dir string, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := modTime(dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if t := modTime(dir); t != last {
+				last = t
+				onChange()
+			}
+		}
+	}
+}
+
+// modTime returns the latest modification time of the override files in dir
+func modTime(dir string) (latest int64) {
+	for _, name := range []string{"balance.yaml", "maps.yaml"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if t := info.ModTime().UnixNano(); t > latest {
+			latest = t
+		}
+	}
+	return latest
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BalanceOverrides are partial changes to the balance made while the server
+// runs, e.g. by a designer tuning a live room. Unset fields keep their value.
+type BalanceOverrides struct {
+	Towers  map[string]TowerOverride `json:"towers,omitempty"`  // by tower type
+	Enemies map[string]EnemyOverride `json:"enemies,omitempty"` // by enemy type
+	Waves   *WaveOverride            `json:"waves,omitempty"`
+}
+
+// TowerOverride changes the stats of a tower type
+type TowerOverride struct {
+	Cost     *int     `json:"cost,omitempty"`
+	Damage   *int     `json:"damage,omitempty"`
+	Range    *float64 `json:"range,omitempty"`
+	FireRate *float64 `json:"fire_rate,omitempty"`
+}
+
+// EnemyOverride changes the stats of an enemy type
+type EnemyOverride struct {
+	HP         *int     `json:"hp,omitempty"` // before wave scaling
+	Speed      *float64 `json:"speed,omitempty"`
+	GoldReward *int     `json:"gold_reward,omitempty"`
+}
+
+// WaveOverride changes how waves grow
+type WaveOverride struct {
+	HPScalePerWave *float64 `json:"hp_scale_per_wave,omitempty"`
+}
+
+// Empty reports whether the overrides change nothing
+func (o BalanceOverrides) Empty() bool {
+	return len(o.Towers) == 0 && len(o.Enemies) == 0 && (o.Waves == nil || o.Waves.HPScalePerWave == nil)
+}
+
+// Merge returns o with the fields set in next on top
+func (o BalanceOverrides) Merge(next BalanceOverrides) BalanceOverrides {
+	merged := BalanceOverrides{Towers: maps.Clone(o.Towers), Enemies: maps.Clone(o.Enemies), Waves: o.Waves}
+	for name, t := range next.Towers {
+		if merged.Towers == nil {
+			merged.Towers = make(map[string]TowerOverride)
+		}
+		m := merged.Towers[name]
+		m.Cost = or(t.Cost, m.Cost)
+		m.Damage = or(t.Damage, m.Damage)
+		m.Range = or(t.Range, m.Range)
+		m.FireRate = or(t.FireRate, m.FireRate)
+		merged.Towers[name] = m
+	}
+	for name, e := range next.Enemies {
+		if merged.Enemies == nil {
+			merged.Enemies = make(map[string]EnemyOverride)
+		}
+		m := merged.Enemies[name]
+		m.HP = or(e.HP, m.HP)
+		m.Speed = or(e.Speed, m.Speed)
+		m.GoldReward = or(e.GoldReward, m.GoldReward)
+		merged.Enemies[name] = m
+	}
+	if next.Waves != nil && next.Waves.HPScalePerWave != nil {
+		merged.Waves = &WaveOverride{HPScalePerWave: next.Waves.HPScalePerWave}
+	}
+	return merged
+}
+
+// or returns v if set, else fallback
+func or[T any](v, fallback *T) *T {
+	if v != nil {
+		return v
+	}
+	return fallback
+}
+
+// WithOverrides returns a copy of c with the overrides applied. Tower and
+// enemy types c doesn't have, and values the simulation can't run with, fail
+// with a *ValidationError naming the fields.
+func (c *GameConfig) WithOverrides(o BalanceOverrides) (*GameConfig, error) {
+	v := &validator{}
+	tuned := c.Clone()
+	for _, name := range sortedKeys(o.Towers) {
+		t, ok := tuned.Towers[name]
+		if !ok {
+			v.add("towers."+name, "unknown tower type")
+			continue
+		}
+		override := o.Towers[name]
+		set(&t.Cost, override.Cost)
+		set(&t.Damage, override.Damage)
+		set(&t.Range, override.Range)
+		set(&t.FireRate, override.FireRate)
+		tuned.Towers[name] = t
+	}
+	for _, name := range sortedKeys(o.Enemies) {
+		e, ok := tuned.Enemies[name]
+		if !ok {
+			v.add("enemies."+name, "unknown enemy type")
+			continue
+		}
+		override := o.Enemies[name]
+		set(&e.HP, override.HP)
+		set(&e.Speed, override.Speed)
+		set(&e.GoldReward, override.GoldReward)
+		tuned.Enemies[name] = e
+	}
+	if o.Waves != nil {
+		set(&tuned.Waves.HPScalePerWave, o.Waves.HPScalePerWave)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := tuned.Validate(); err != nil {
+		return nil, err
+	}
+	return tuned, nil
+}
+
+// set copies *v to dst if v is set
+func set[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// Tree returns the config as balance.yaml spells it, to be shown or encoded as JSON
+func (c *GameConfig) Tree() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
@@ -0,0 +1,522 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a single invalid value, located by its YAML field path
+type FieldError struct {
+	Field   string `json:"field"` // e.g. "towers.sniper.fire_rate" or "maps.spiral.path[3]"
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError aggregates every problem found in a configuration
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		lines[i] = "  - " + fe.Error()
+	}
+	return fmt.Sprintf("invalid game config (%d problems):\n%s", len(e.Errors), strings.Join(lines, "\n"))
+}
+
+// validator collects field errors so that all problems are reported at once
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) positive(field string, value float64) {
+	if value <= 0 {
+		v.add(field, "must be positive, got %v", value)
+	}
+}
+
+func (v *validator) nonNegative(field string, value float64) {
+	if value < 0 {
+		v.add(field, "must not be negative, got %v", value)
+	}
+}
+
+func (v *validator) probability(field string, value float64) {
+	if value < 0 || value > 1 {
+		v.add(field, "must be between 0 and 1, got %v", value)
+	}
+}
+
+// fraction accepts a share of a stat that may be taken away, 0 up to but excluding 1
+func (v *validator) fraction(field string, value float64) {
+	if value < 0 || value >= 1 {
+		v.add(field, "must be at least 0 and below 1, got %v", value)
+	}
+}
+
+func (v *validator) atLeastOne(field string, value float64) {
+	if value < 1 {
+		v.add(field, "must be at least 1, got %v", value)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// sortedKeys gives deterministic error ordering for map-keyed sections
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks the invariants the simulation relies on. maps may be nil, in which
+// case only the balance config (including its default map) is checked.
+// The returned error is a *ValidationError listing every problem found.
+func Validate(cfg *GameConfig, maps *MapsConfig) error {
+	v := &validator{}
+
+	v.positive("game.tick_rate_ms", float64(cfg.Game.TickRateMs))
+	v.positive("game.broadcast_interval_ms", float64(cfg.Game.BroadcastIntervalMs))
+	v.nonNegative("game.starting_gold", float64(cfg.Game.StartingGold))
+	v.positive("game.starting_lives", float64(cfg.Game.StartingLives))
+	v.nonNegative("game.corpse_grace_ms", float64(cfg.Game.CorpseGraceMs))
+	v.nonNegative("game.victory_wave", float64(cfg.Game.VictoryWave))
+	if cfg.Game.MaxRollbacks < -1 {
+		v.add("game.max_rollbacks", "must be -1 (unlimited) or more, got %d", cfg.Game.MaxRollbacks)
+	}
+
+	if len(cfg.Towers) == 0 {
+		v.add("towers", "at least one tower type is required")
+	}
+	for _, name := range sortedKeys(cfg.Towers) {
+		t, field := cfg.Towers[name], "towers."+name
+		v.nonNegative(field+".cost", float64(t.Cost))
+		v.positive(field+".range", t.Range)
+		v.nonNegative(field+".damage", float64(t.Damage))
+		v.positive(field+".fire_rate", t.FireRate)
+		v.nonNegative(field+".splash_radius", t.SplashRadius)
+		if t.CritChance != nil {
+			v.probability(field+".crit_chance", *t.CritChance)
+		}
+		if t.CritMultiplier != nil {
+			v.atLeastOne(field+".crit_multiplier", *t.CritMultiplier)
+		}
+		if t.MissChance != nil {
+			v.probability(field+".miss_chance", *t.MissChance)
+		}
+		v.nonNegative(field+".ammo", float64(t.Ammo))
+		if a := t.Aura; a != nil {
+			v.positive(field+".aura.radius", a.Radius)
+			v.nonNegative(field+".aura.damage", a.Damage)
+			v.nonNegative(field+".aura.fire_rate", a.FireRate)
+			v.nonNegative(field+".aura.range", a.Range)
+			if a.Damage == 0 && a.FireRate == 0 && a.Range == 0 {
+				v.add(field+".aura", "must grant at least one of damage, fire_rate or range")
+			}
+			continue
+		}
+		// towers fire projectiles of their own type
+		if _, ok := cfg.Projectiles[name]; !ok {
+			v.add(field, "no projectile type %q defined under projectiles", name)
+		}
+	}
+
+	switch cfg.Auras.Stacking {
+	case "", AuraStackHighest, AuraStackAdditive:
+	default:
+		v.add("auras.stacking", "unknown stacking mode %q (want highest or additive)", cfg.Auras.Stacking)
+	}
+	v.nonNegative("auras.max_bonus", cfg.Auras.MaxBonus)
+
+	for _, name := range sortedKeys(cfg.Enemies) {
+		e, field := cfg.Enemies[name], "enemies."+name
+		v.positive(field+".hp", float64(e.HP))
+		v.positive(field+".speed", e.Speed)
+		v.nonNegative(field+".gold_reward", float64(e.GoldReward))
+		v.nonNegative(field+".score_reward", float64(e.ScoreReward))
+		v.nonNegative(field+".wall_damage", e.WallDamage)
+		v.nonNegative(field+".life_reward", float64(e.LifeReward))
+		validateAbilities(v, cfg, field+".abilities", e.Abilities)
+	}
+
+	for _, name := range sortedKeys(cfg.Projectiles) {
+		p, field := cfg.Projectiles[name], "projectiles."+name
+		v.positive(field+".speed", p.Speed)
+		v.nonNegative(field+".max_lifetime", p.MaxLifetime)
+		switch p.Behavior {
+		case "", ProjectileHoming, ProjectileArc:
+		case ProjectileBeam:
+			v.nonNegative(field+".duration", p.Duration)
+		case ProjectilePierce:
+			v.positive(field+".hit_radius", p.HitRadius)
+			v.nonNegative(field+".max_hits", float64(p.MaxHits))
+		case ProjectileChain:
+			v.nonNegative(field+".duration", p.Duration)
+			v.nonNegative(field+".jumps", float64(p.Jumps))
+			v.positive(field+".jump_radius", p.JumpRadius)
+			v.probability(field+".falloff", p.Falloff)
+		default:
+			v.add(field+".behavior", "unknown behavior %q (want homing, beam, arc, pierce or chain)", p.Behavior)
+		}
+		// arcs only damage the area they land in
+		if t, ok := cfg.Towers[name]; ok && p.Behavior == ProjectileArc && t.SplashRadius <= 0 {
+			v.add("towers."+name+".splash_radius", "must be positive for towers firing arc projectiles")
+		}
+	}
+
+	validateWaves(v, cfg)
+	validateBosses(v, cfg)
+
+	validateWalls(v, cfg.Walls)
+
+	v.probability("accuracy.crit_chance", cfg.Accuracy.CritChance)
+	if cfg.Accuracy.CritChance > 0 {
+		v.atLeastOne("accuracy.crit_multiplier", cfg.Accuracy.CritMultiplier)
+	}
+	v.probability("accuracy.miss_chance", cfg.Accuracy.MissChance)
+
+	v.positive("analytics.heatmap_cell_size", cfg.Analytics.HeatmapCellSize)
+
+	v.nonNegative("economy.interest_rate", cfg.Economy.InterestRate)
+	v.nonNegative("economy.interest_cap", float64(cfg.Economy.InterestCap))
+	v.nonNegative("economy.wave_bonus", float64(cfg.Economy.WaveBonus))
+	v.nonNegative("economy.life_bonus", float64(cfg.Economy.LifeBonus))
+	v.nonNegative("economy.bounty.start_wave", float64(cfg.Economy.Bounty.StartWave))
+	v.probability("economy.bounty.decay", cfg.Economy.Bounty.Decay)
+	v.probability("economy.bounty.floor", cfg.Economy.Bounty.Floor)
+	v.probability("economy.sell_refund", cfg.Economy.SellRefund)
+	v.fraction("economy.wallets.transfer_tax", cfg.Economy.Wallets.TransferTax)
+
+	v.nonNegative("lives.max_lives", float64(cfg.Lives.MaxLives))
+	v.nonNegative("lives.regen_every", float64(cfg.Lives.RegenEvery))
+	if cfg.Lives.RegenEvery > 0 {
+		v.positive("lives.regen_amount", float64(cfg.Lives.RegenAmount))
+	}
+	if cfg.Lives.MaxLives > 0 && cfg.Game.StartingLives > cfg.Lives.MaxLives {
+		v.add("lives.max_lives", "must be at least game.starting_lives (%d), got %d", cfg.Game.StartingLives, cfg.Lives.MaxLives)
+	}
+
+	v.positive("visibility.tower_sight", cfg.Visibility.TowerSight)
+	v.nonNegative("visibility.wall_sight", cfg.Visibility.WallSight)
+
+	v.fraction("terrain.mud_slow", cfg.Terrain.MudSlow)
+	v.nonNegative("terrain.high_ground_range", cfg.Terrain.HighGroundRange)
+	v.nonNegative("terrain.weather.every", cfg.Terrain.Weather.Every)
+	if cfg.Terrain.Weather.Every > 0 {
+		v.positive("terrain.weather.duration", cfg.Terrain.Weather.Duration)
+	}
+	v.fraction("terrain.weather.rain_fire_rate", cfg.Terrain.Weather.RainFireRate)
+
+	validateElites(v, cfg.Elites)
+
+	v.positive("ammo.capacity", float64(cfg.Ammo.Capacity))
+	v.nonNegative("ammo.round_cost", cfg.Ammo.RoundCost)
+
+	for i, action := range cfg.Rules.DuringCombat {
+		if !slices.Contains(Actions, action) {
+			v.add(fmt.Sprintf("rules.during_combat[%d]", i), "unknown action %q (want one of %s)", action, strings.Join(Actions, ", "))
+		}
+	}
+	for _, phase := range sortedKeys(cfg.Rules.Phases) {
+		if !slices.Contains(Phases, phase) {
+			v.add("rules.phases."+phase, "unknown phase (want one of %s)", strings.Join(Phases, ", "))
+			continue
+		}
+		for i, action := range cfg.Rules.Phases[phase] {
+			if !slices.Contains(Actions, action) {
+				v.add(fmt.Sprintf("rules.phases.%s[%d]", phase, i), "unknown action %q (want one of %s)", action, strings.Join(Actions, ", "))
+			}
+		}
+	}
+	for i, phase := range cfg.Rules.FreeSell {
+		// Full refunds last until the next wave, which a wave in progress has no end for
+		if phase != PhaseBuilding && phase != PhaseIntermission {
+			v.add(fmt.Sprintf("rules.free_sell[%d]", i), "must be %q or %q, got %q", PhaseBuilding, PhaseIntermission, phase)
+		}
+	}
+
+	v.nonNegative("placement.min_distance_from_path", cfg.Placement.MinDistanceFromPath)
+	v.nonNegative("placement.min_tower_spacing", cfg.Placement.MinTowerSpacing)
+	v.positive("placement.max_towers", float64(cfg.Placement.MaxTowers))
+	v.positive("placement.grid_size", cfg.Placement.GridSize)
+
+	validateMap(v, "map", cfg.Map, cfg.Placement, false)
+	if maps != nil {
+		if len(maps.Maps) == 0 {
+			v.add("maps", "at least one map is required")
+		}
+		for _, id := range sortedKeys(maps.Maps) {
+			validateMap(v, "maps."+id, maps.Maps[id], cfg.Placement, true)
+		}
+	}
+
+	return v.err()
+}
+
+// Validate checks the balance config on its own; see the package-level Validate
+func (c *GameConfig) Validate() error {
+	return Validate(c, nil)
+}
+
+func validateWaves(v *validator, cfg *GameConfig) {
+	w := cfg.Waves
+	v.positive("waves.enemies_per_wave_base", float64(w.EnemiesPerWaveBase))
+	v.positive("waves.enemies_per_wave_multiplier", w.EnemiesPerWaveMultiplier)
+	v.positive("waves.hp_scale_per_wave", w.HPScalePerWave)
+
+	compositions := []struct {
+		field string
+		comp  WaveComposition
+	}{
+		{"waves.early_waves", w.EarlyWaves},
+		{"waves.mid_waves", w.MidWaves},
+		{"waves.late_waves", w.LateWaves},
+		{"waves.boss_waves", w.BossWaves},
+	}
+	for _, c := range compositions {
+		total := 0
+		for _, tw := range c.comp.Weights() {
+			enemyType, weight := tw.EnemyType, tw.Weight
+			if weight < 0 {
+				v.add(c.field+"."+enemyType, "weight must not be negative, got %d", weight)
+				continue
+			}
+			if _, ok := cfg.Enemies[enemyType]; weight > 0 && !ok {
+				v.add(c.field+"."+enemyType, "enemy type %q is not defined under enemies", enemyType)
+			}
+			total += weight
+		}
+		if total <= 0 {
+			v.add(c.field, "weights must sum to a positive value")
+		}
+	}
+
+	for i, p := range w.SpawnPatterns {
+		field := fmt.Sprintf("waves.spawn_patterns[%d]", i)
+		switch p.Type {
+		case SpawnBurst, SpawnTrickle, SpawnAlternate, SpawnSquads:
+		default:
+			v.add(field+".type", "unknown spawn type %q (want burst, trickle, alternate or squads)", p.Type)
+		}
+		switch p.Entrances {
+		case "", EntranceSingle, EntranceAlternate, EntranceRandom:
+		default:
+			v.add(field+".entrances", "unknown entrance mode %q (want single, alternate or random)", p.Entrances)
+		}
+		if len(p.Waves) == 0 {
+			v.positive(field+".from_wave", float64(p.FromWave))
+		}
+		for j, wave := range p.Waves {
+			v.positive(fmt.Sprintf("%s.waves[%d]", field, j), float64(wave))
+		}
+		v.nonNegative(field+".interval_ms", float64(p.IntervalMs))
+		v.nonNegative(field+".jitter_ms", float64(p.JitterMs))
+		v.nonNegative(field+".squad_gap_ms", float64(p.SquadGapMs))
+		if p.Type == SpawnSquads {
+			v.positive(field+".squad_size", float64(p.SquadSize))
+		}
+	}
+
+	m := w.Modifiers
+	v.nonNegative("waves.modifiers.start_wave", float64(m.StartWave))
+	v.probability("waves.modifiers.chance", m.Chance)
+	if m.StartWave > 0 && len(m.Registry) == 0 {
+		v.add("waves.modifiers.registry", "at least one modifier is required when waves.modifiers.start_wave is set")
+	}
+	for _, id := range sortedKeys(m.Registry) {
+		mod, field := m.Registry[id], "waves.modifiers.registry."+id
+		v.positive(field+".weight", float64(mod.Weight))
+		v.nonNegative(field+".speed", mod.Speed)
+		v.nonNegative(field+".hp", mod.HP)
+		v.nonNegative(field+".count", mod.Count)
+		v.nonNegative(field+".gold", mod.Gold)
+		if mod.Description == "" {
+			v.add(field+".description", "is required")
+		}
+	}
+
+	validatePacing(v, "waves.pacing", w.Pacing)
+	v.positive("waves.pacing.interval_ms", float64(w.Pacing.IntervalMs))
+}
+
+// validatePacing checks a wave pacing; its interval may be left out
+func validatePacing(v *validator, field string, p WavePacing) {
+	if p.Mode != "" && !slices.Contains(PacingModes, p.Mode) {
+		v.add(field+".mode", "unknown pacing mode %q (want %s)", p.Mode, strings.Join(PacingModes, ", "))
+	}
+	v.nonNegative(field+".interval_ms", float64(p.IntervalMs))
+	v.nonNegative(field+".intermission_ms", float64(p.IntermissionMs))
+}
+
+func validateAbilities(v *validator, cfg *GameConfig, field string, a EnemyAbilities) {
+	pulses := []struct {
+		field string
+		pulse *PulseAbility
+	}{
+		{field + ".heal", a.Heal},
+		{field + ".shield", a.Shield},
+	}
+	for _, p := range pulses {
+		if p.pulse == nil {
+			continue
+		}
+		v.positive(p.field+".radius", p.pulse.Radius)
+		v.positive(p.field+".amount", float64(p.pulse.Amount))
+		v.positive(p.field+".interval", p.pulse.Interval)
+	}
+	if s := a.SpawnOnDeath; s != nil {
+		if _, ok := cfg.Enemies[s.EnemyType]; !ok {
+			v.add(field+".spawn_on_death.enemy_type", "enemy type %q is not defined under enemies", s.EnemyType)
+		} else if cfg.Enemies[s.EnemyType].Abilities.SpawnOnDeath != nil {
+			v.add(field+".spawn_on_death.enemy_type", "enemy type %q spawns units itself", s.EnemyType)
+		}
+		v.positive(field+".spawn_on_death.count", float64(s.Count))
+	}
+}
+
+func validateWalls(v *validator, w WallConfig) {
+	v.nonNegative("walls.max_walls", float64(w.MaxWalls))
+	if w.MaxWalls == 0 {
+		return // walls disabled, the rest is unused
+	}
+	v.nonNegative("walls.cost", float64(w.Cost))
+	v.positive("walls.hp", float64(w.HP))
+	v.positive("walls.radius", w.Radius)
+	v.nonNegative("walls.min_spacing", w.MinSpacing)
+	v.positive("walls.enemy_damage", w.EnemyDamage)
+}
+
+func validateBosses(v *validator, cfg *GameConfig) {
+	for _, name := range sortedKeys(cfg.Bosses) {
+		field := "bosses." + name
+		if _, ok := cfg.Enemies[name]; !ok {
+			v.add(field, "enemy type %q is not defined under enemies", name)
+		}
+		for i, phase := range cfg.Bosses[name].Phases {
+			pf := fmt.Sprintf("%s.phases[%d]", field, i)
+			if phase.HPThreshold <= 0 || phase.HPThreshold >= 1 {
+				v.add(pf+".hp_threshold", "must be between 0 and 1 (exclusive), got %v", phase.HPThreshold)
+			}
+			if s := phase.Summon; s != nil {
+				if _, ok := cfg.Enemies[s.EnemyType]; !ok {
+					v.add(pf+".summon.enemy_type", "enemy type %q is not defined under enemies", s.EnemyType)
+				}
+				v.positive(pf+".summon.count", float64(s.Count))
+			}
+			if s := phase.Shield; s != nil {
+				v.positive(pf+".shield.amount", float64(s.Amount))
+				v.positive(pf+".shield.duration", s.Duration)
+			}
+			if s := phase.SpeedBurst; s != nil {
+				v.positive(pf+".speed_burst.multiplier", s.Multiplier)
+				v.positive(pf+".speed_burst.duration", s.Duration)
+			}
+		}
+	}
+}
+
+// validateMap checks map geometry. Entries under maps.yaml also carry their own
+// starting resources, which the default map in balance.yaml does not.
+func validateElites(v *validator, e EliteConfig) {
+	v.nonNegative("elites.start_wave", float64(e.StartWave))
+	v.probability("elites.chance", e.Chance)
+	if e.StartWave > 0 && len(e.Affixes) == 0 {
+		v.add("elites.affixes", "at least one affix is required when elites.start_wave is set")
+	}
+	for _, name := range sortedKeys(e.Affixes) {
+		a, field := e.Affixes[name], "elites.affixes."+name
+		v.positive(field+".weight", float64(a.Weight))
+		switch name {
+		case AffixRegenerating:
+			v.positive(field+".regen", a.Regen)
+		case AffixSplashResistant:
+			v.probability(field+".splash_resist", a.SplashResist)
+		case AffixPhasing:
+			v.positive(field+".phase_every", a.PhaseEvery)
+			v.positive(field+".phase_duration", a.PhaseDuration)
+		default:
+			v.add(field, "unknown affix, must be one of %s, %s, %s", AffixRegenerating, AffixSplashResistant, AffixPhasing)
+		}
+	}
+}
+
+func validateMap(v *validator, field string, m MapConfig, placement PlacementConfig, standalone bool) {
+	v.positive(field+".width", float64(m.Width))
+	v.positive(field+".height", float64(m.Height))
+	v.positive(field+".path_half_width", m.PathHalfWidth)
+	if standalone {
+		v.nonNegative(field+".starting_gold", float64(m.StartingGold))
+		v.positive(field+".starting_lives", float64(m.StartingLives))
+	}
+
+	if m.Width > 0 && m.Height > 0 {
+		limit := float64(m.Width)
+		if m.Height < m.Width {
+			limit = float64(m.Height)
+		}
+		if placement.MinTowerSpacing > limit {
+			v.add(field, "placement.min_tower_spacing (%v) exceeds the smallest map dimension (%v)", placement.MinTowerSpacing, limit)
+		}
+	}
+
+	validatePath(v, field+".path", m.Path, m)
+	if m.WavePacing != nil {
+		validatePacing(v, field+".wave_pacing", *m.WavePacing)
+	}
+	for i, z := range m.Zones {
+		zf := fmt.Sprintf("%s.zones[%d]", field, i)
+		if z.Type != ZoneMud && z.Type != ZoneHighGround {
+			v.add(zf+".type", "must be %q or %q, got %q", ZoneMud, ZoneHighGround, z.Type)
+		}
+		v.positive(zf+".width", z.Width)
+		v.positive(zf+".height", z.Height)
+		if z.X < 0 || z.Y < 0 || z.X+z.Width > float64(m.Width) || z.Y+z.Height > float64(m.Height) {
+			v.add(zf, "extends outside the %dx%d map", m.Width, m.Height)
+		}
+	}
+	for i, o := range m.Obstacles {
+		of := fmt.Sprintf("%s.obstacles[%d]", field, i)
+		v.positive(of+".width", o.Width)
+		v.positive(of+".height", o.Height)
+		if o.X < 0 || o.Y < 0 || o.X+o.Width > float64(m.Width) || o.Y+o.Height > float64(m.Height) {
+			v.add(of, "extends outside the %dx%d map", m.Width, m.Height)
+		}
+	}
+	for i, e := range m.Entrances {
+		ef := fmt.Sprintf("%s.entrances[%d].path", field, i)
+		validatePath(v, ef, e.Path, m)
+		if len(e.Path) > 0 && len(m.Path) > 0 && e.Path[len(e.Path)-1] != m.Path[len(m.Path)-1] {
+			v.add(ef, "must end at the main path exit (%v, %v)", m.Path[len(m.Path)-1].X, m.Path[len(m.Path)-1].Y)
+		}
+	}
+}
+
+func validatePath(v *validator, field string, path []Position, m MapConfig) {
+	if len(path) < 2 {
+		v.add(field, "needs at least 2 waypoints, got %d", len(path))
+		return
+	}
+	for i, p := range path {
+		if p.X < 0 || p.Y < 0 || p.X > float64(m.Width) || p.Y > float64(m.Height) {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "(%v, %v) is outside the %dx%d map", p.X, p.Y, m.Width, m.Height)
+		}
+	}
+}
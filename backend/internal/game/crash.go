@@ -0,0 +1,124 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+const (
+	maxCrashes  = 3           // panics within crashWindow after which the game stops for good
+	crashWindow = time.Minute // crashes older than this are forgiven
+)
+
+// CrashReport is written to the crash repository when a tick panics
+type CrashReport struct {
+	GameID   string          `json:"gameId"`
+	Time     time.Time       `json:"time"`
+	Panic    string          `json:"panic"`
+	Stack    string          `json:"stack"`
+	Resumed  bool            `json:"resumed"`            // false if the game was stopped as errored
+	Snapshot json.RawMessage `json:"snapshot,omitempty"` // SimulationSave of the world at the time of the panic
+}
+
+// SetCrashRepository sets where crash reports of this game are stored; nil only logs them
+func (g *Game) SetCrashRepository(repo repository.Repository) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.crashRepo = repo
+}
+
+// Errored reports whether the game loop was stopped after repeated panics
+func (g *Game) Errored() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.errored
+}
+
+// tick runs one Update and recovers from a panic in it. It reports whether the
+// game loop should keep running.
+func (g *Game) tick() (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = g.recoverCrash(r, debug.Stack())
+		}
+	}()
+	g.Update()
+	return true
+}
+
+// recoverCrash records a panic of the game loop and decides whether to resume.
+// Update releases g.mu while unwinding, so the lock is free here.
+func (g *Game) recoverCrash(r interface{}, stack []byte) bool {
+	now := time.Now()
+	report := CrashReport{GameID: g.id, Time: now, Panic: fmt.Sprint(r), Stack: string(stack)}
+	report.Snapshot = g.crashSnapshot()
+
+	g.mu.Lock()
+	recent := g.crashes[:0]
+	for _, t := range g.crashes {
+		if now.Sub(t) < crashWindow {
+			recent = append(recent, t)
+		}
+	}
+	g.crashes = append(recent, now)
+	crashCount := len(g.crashes)
+	report.Resumed = crashCount < maxCrashes
+	if !report.Resumed {
+		g.errored = true
+		g.running = false
+		if g.ticker != nil {
+			g.ticker.Stop()
+		}
+	}
+	// The panicking tick did not finish; don't replay its time on resume
+	g.lastUpdate = now
+	detail := "resumed"
+	if !report.Resumed {
+		detail = "stopped"
+	}
+	g.emit(events.Event{Type: events.GameCrashed, Wave: g.state.Wave, Detail: detail})
+	repo := g.crashRepo
+	g.mu.Unlock()
+	g.flushEvents()
+
+	logging.Errorw("game_panic",
+		"game_id", g.id,
+		"panic", report.Panic,
+		"stack", report.Stack,
+		"recent_crashes", crashCount,
+		"resumed", report.Resumed,
+	)
+	if repo != nil {
+		data, err := json.Marshal(report)
+		if err == nil {
+			_, err = repo.Save(g.id, data)
+		}
+		if err != nil {
+			logging.Errorw("crash_report_save_failed", "game_id", g.id, "error", err)
+		}
+	}
+	return report.Resumed
+}
+
+// crashSnapshot serializes the world for a crash report. The world may be the
+// reason for the panic, so a failure here only loses the snapshot.
+func (g *Game) crashSnapshot() (snapshot json.RawMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Errorw("crash_snapshot_failed", "game_id", g.id, "panic", fmt.Sprint(r))
+			snapshot = nil
+		}
+	}()
+	data, err := g.SaveSimulation()
+	if err != nil {
+		logging.Errorw("crash_snapshot_failed", "game_id", g.id, "error", err)
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,65 @@
+package ecs
+
+import (
+	"slices"
+	"time"
+)
+
+// Clone returns an independent copy of the world: every entity is deep-copied,
+// so the copy can be simulated without touching the original. The copy's clock
+// stands still at the original's current time until SetClock is called on it,
+// and the copy lists its entities in the same order.
+func (w *World) Clone() *World {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	clone := NewWorld()
+	now := w.clock()
+	clone.clock = func() time.Time { return now }
+	for _, id := range w.order {
+		switch e := w.entities[id].(type) {
+		case *TowerEntity:
+			clone.addLocked(e.Clone())
+		case *EnemyEntity:
+			clone.addLocked(e.Clone())
+		case *ProjectileEntity:
+			clone.addLocked(e.Clone())
+		case *WallEntity:
+			clone.addLocked(e.Clone())
+		}
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the tower
+func (t *TowerEntity) Clone() *TowerEntity {
+	c := *t
+	if t.Aura != nil {
+		aura := *t.Aura
+		c.Aura = &aura
+	}
+	c.Buff.Sources = slices.Clone(t.Buff.Sources)
+	return &c
+}
+
+// Clone returns a deep copy of the enemy
+func (e *EnemyEntity) Clone() *EnemyEntity {
+	c := *e
+	c.VisibleTo = slices.Clone(e.VisibleTo)
+	c.Affixes = slices.Clone(e.Affixes)
+	return &c
+}
+
+// Clone returns a deep copy of the projectile
+func (p *ProjectileEntity) Clone() *ProjectileEntity {
+	c := *p
+	c.Pierced = slices.Clone(p.Pierced)
+	c.Chain = slices.Clone(p.Chain)
+	return &c
+}
+
+// Clone returns a copy of the wall
+func (w *WallEntity) Clone() *WallEntity {
+	c := *w
+	return &c
+}
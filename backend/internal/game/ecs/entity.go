@@ -1,6 +1,10 @@
 package ecs
 
-import "time"
+import (
+	"math"
+	"slices"
+	"time"
+)
 
 // EntityType represents the type of game entity
 type EntityType string
@@ -9,6 +13,7 @@ const (
 	EntityTypeTower      EntityType = "tower"
 	EntityTypeEnemy      EntityType = "enemy"
 	EntityTypeProjectile EntityType = "projectile"
+	EntityTypeWall       EntityType = "wall"
 )
 
 // Entity is the base interface for all game entities
@@ -63,32 +68,157 @@ type TowerEntity struct {
 	Damage       int       `json:"damage"`
 	FireRate     float64   `json:"fireRate"`
 	SplashRadius float64   `json:"splashRadius,omitempty"`
+	OwnerID      string    `json:"ownerId,omitempty"`
 	LastShot     time.Time `json:"-"`
+
+	// Support towers carry an aura and never shoot
+	Aura *Aura `json:"aura,omitempty"`
+	// Buff is the bonus currently received from nearby auras, recomputed every tick
+	Buff Buff `json:"buff,omitempty"`
+	// Stats is what the tower has cost and achieved so far
+	Stats TowerStats `json:"stats"`
+
+	// Rounds left and magazine size under the ammo rule; MaxAmmo 0 = unlimited
+	Ammo    int `json:"ammo,omitempty"`
+	MaxAmmo int `json:"maxAmmo,omitempty"`
+
+	// Terrain and weather modifiers as fractions of the base stat, recomputed
+	// every tick by the TerrainSystem
+	TerrainRange    float64 `json:"-"` // high ground bonus
+	WeatherFireRate float64 `json:"-"` // negative while it rains
+}
+
+// TowerStats are a tower's lifetime statistics
+type TowerStats struct {
+	Value      int `json:"value"`      // gold paid for the tower
+	Shots      int `json:"shots"`      // projectiles fired
+	Damage     int `json:"damage"`     // damage landed, shields included, overkill not
+	Kills      int `json:"kills"`      // enemies it dealt the killing blow to
+	GoldEarned int `json:"goldEarned"` // bounties of its kills
+}
+
+// Aura is the buff a support tower grants to towers within Radius.
+// Bonuses are fractions of the base stat, e.g. 0.25 = +25%.
+type Aura struct {
+	Radius   float64 `json:"radius"`
+	Damage   float64 `json:"damage,omitempty"`
+	FireRate float64 `json:"fireRate,omitempty"`
+	Range    float64 `json:"range,omitempty"`
+}
+
+// Buff is the combined bonus a tower receives from the auras covering it
+type Buff struct {
+	Damage   float64  `json:"damage,omitempty"`
+	FireRate float64  `json:"fireRate,omitempty"`
+	Range    float64  `json:"range,omitempty"`
+	Sources  []string `json:"sources,omitempty"` // IDs of the support towers in range
 }
 
 func (t *TowerEntity) Update(dt float64) {
 	// Towers are stationary, no update needed
 }
 
-func (t *TowerEntity) CanShoot() bool {
-	elapsed := time.Since(t.LastShot).Seconds()
-	return elapsed >= 1.0/t.FireRate
+// IsSupport reports whether the tower buffs others instead of shooting
+func (t *TowerEntity) IsSupport() bool {
+	return t.Aura != nil
+}
+
+// EffectiveRange is the tower range including aura and terrain bonuses
+func (t *TowerEntity) EffectiveRange() float64 {
+	return t.Range * (1 + t.Buff.Range + t.TerrainRange)
+}
+
+// EffectiveDamage is the tower damage including aura bonuses
+func (t *TowerEntity) EffectiveDamage() int {
+	return int(math.Round(float64(t.Damage) * (1 + t.Buff.Damage)))
 }
 
-func (t *TowerEntity) Shoot() {
-	t.LastShot = time.Now()
+// EffectiveFireRate is the shots per second including aura bonuses and weather
+func (t *TowerEntity) EffectiveFireRate() float64 {
+	return t.FireRate * (1 + t.Buff.FireRate + t.WeatherFireRate)
+}
+
+// CanShoot reports whether the tower has reloaded by now
+func (t *TowerEntity) CanShoot(now time.Time) bool {
+	if t.IsSupport() {
+		return false
+	}
+	if t.OutOfAmmo() {
+		return false
+	}
+	elapsed := now.Sub(t.LastShot).Seconds()
+	return elapsed >= 1.0/t.EffectiveFireRate()
+}
+
+func (t *TowerEntity) Shoot(now time.Time) {
+	t.LastShot = now
+	if t.MaxAmmo > 0 {
+		t.Ammo--
+	}
+}
+
+// OutOfAmmo reports whether the tower uses ammunition and has none left
+func (t *TowerEntity) OutOfAmmo() bool {
+	return t.MaxAmmo > 0 && t.Ammo <= 0
 }
 
 // EnemyEntity represents an enemy
+
+
 type EnemyEntity struct {
 	BaseEntity
-	EnemyType string  `json:"enemyType"`
-	HP        int     `json:"hp"`
-	MaxHP     int     `json:"maxHp"`
-	Speed     float64 `json:"speed"`
-	PathIndex int     `json:"pathIndex"`
-	GoldReward  int   `json:"-"`
-	ScoreReward int   `json:"-"`
+	EnemyType   string  `json:"enemyType"`
+	HP          int     `json:"hp"`
+	MaxHP       int     `json:"maxHp"`
+	Speed       float64 `json:"speed"`
+	PathIndex   int     `json:"pathIndex"`
+	PathID      int     `json:"pathId"` // which map path (entrance) the enemy follows
+	GoldReward  int     `json:"-"`
+	ScoreReward int     `json:"-"`
+	LastHitBy   string  `json:"-"` // ID of the tower that dealt the latest damage
+
+	// Wall state
+	WallDamage float64 `json:"-"`                   // damage per second dealt to a blocking wall
+	BlockedBy  string  `json:"blockedBy,omitempty"` // ID of the wall the enemy is attacking
+
+	// Ability cooldowns, in seconds until the next pulse
+	HealCooldown   float64 `json:"-"`
+	ShieldCooldown float64 `json:"-"`
+
+	// Boss state
+	BossPhase       int     `json:"bossPhase,omitempty"` // number of phases triggered
+	Shield          int     `json:"shield,omitempty"`    // damage absorbed before HP
+	ShieldTimer     float64 `json:"-"`
+	BaseSpeed       float64 `json:"-"` // speed to restore after a burst
+	SpeedBurstTimer float64 `json:"-"`
+
+	// Fog of war: owners of the towers and walls that see the enemy, "" for
+	// unowned ones. Maintained by the VisibilitySystem while fog of war is on.
+	VisibleTo []string `json:"-"`
+
+	// Fraction of speed lost to the terrain under the enemy, set by the TerrainSystem
+	TerrainSlow float64 `json:"-"`
+
+	// Distance moved per 1/60 s along the path, zero while blocked; set by the
+	// MovementSystem and used by towers to lead their shots
+	Velocity Position `json:"-"`
+
+	// Elite affixes rolled at spawn, run by the AffixSystem
+	Affixes      []string `json:"affixes,omitempty"`
+	SplashResist float64  `json:"-"`                // fraction of splash damage ignored
+	RegenCarry   float64  `json:"-"`                // healing below 1 HP carried to the next tick
+	PhaseTimer   float64  `json:"-"`                // seconds until Phased flips
+	Phased       bool     `json:"phased,omitempty"` // towers can't target the enemy
+}
+
+// HasAffix reports whether the enemy rolled the given elite affix
+func (e *EnemyEntity) HasAffix(affix string) bool {
+	return slices.Contains(e.Affixes, affix)
+}
+
+// EffectiveSpeed is the enemy speed after terrain slows
+func (e *EnemyEntity) EffectiveSpeed() float64 {
+	return e.Speed * (1 - e.TerrainSlow)
 }
 
 func (e *EnemyEntity) Update(dt float64) {
@@ -96,10 +226,19 @@ func (e *EnemyEntity) Update(dt float64) {
 }
 
 func (e *EnemyEntity) TakeDamage(damage int) {
+	// Shields absorb damage first
+	if e.Shield > 0 {
+		absorbed := damage
+		if absorbed > e.Shield {
+			absorbed = e.Shield
+		}
+		e.Shield -= absorbed
+		damage -= absorbed
+	}
 	e.HP -= damage
 	if e.HP <= 0 {
 		e.HP = 0
-		// Don't set Alive to false here - let RewardSystem detect death first
+		// The system dealing the damage marks the enemy dead and publishes the kill
 	}
 }
 
@@ -118,12 +257,68 @@ type ProjectileEntity struct {
 	Speed          float64 `json:"speed"`
 	Damage         int     `json:"damage"`
 	SplashRadius   float64 `json:"splashRadius,omitempty"`
+	SourceID       string  `json:"sourceId,omitempty"` // ID of the tower that fired it
+
+	// Travel model, see the projectile behaviors in the config package
+	Behavior  string   `json:"behavior,omitempty"`
+	Origin    Position `json:"origin"`            // where the shot was fired from
+	Impact    Position `json:"impact"`            // beam/arc/chain: landing point; pierce: end of the line
+	HitRadius float64  `json:"-"`                 // pierce
+	MaxHits   int      `json:"-"`                 // pierce
+	Pierced   []string `json:"pierced,omitempty"` // IDs of the enemies a piercing or chain shot already hit
+	TTL       float64  `json:"-"`                 // beam/chain: seconds left to display once spent
+	Spent     bool     `json:"-"`                 // beam/chain: damage already dealt
+
+	// Chain lightning, see ProjectileConfig
+	Jumps      int        `json:"-"`
+	JumpRadius float64    `json:"-"`
+	Falloff    float64    `json:"-"`
+	Chain      []Position `json:"chain,omitempty"` // positions of the enemies hit, in order
+
+	// Aiming and flight time, see ProjectileConfig
+	Lead        bool    `json:"-"`
+	MaxLifetime float64 `json:"-"` // 0 = no limit
+	Age         float64 `json:"-"` // seconds since the shot was fired
+
+	// Accuracy of the firing tower, rolled on impact
+	CritChance     float64 `json:"-"`
+	CritMultiplier float64 `json:"-"`
+	MissChance     float64 `json:"-"`
 }
 
 func (p *ProjectileEntity) Update(dt float64) {
 	// Movement handled by ProjectileSystem
 }
 
+// Expired reports whether the shot has been in flight longer than its max lifetime
+func (p *ProjectileEntity) Expired() bool {
+	return p.MaxLifetime > 0 && p.Age >= p.MaxLifetime
+}
+
+// WallEntity is a player-built obstacle on the path. Enemies that reach it stop
+// and attack it until it breaks.
+type WallEntity struct {
+	BaseEntity
+	HP      float64 `json:"hp"`
+	MaxHP   float64 `json:"maxHp"`
+	Radius  float64 `json:"radius"`
+	OwnerID string  `json:"ownerId,omitempty"`
+}
+
+func (w *WallEntity) Update(dt float64) {
+	// Walls are damaged by the WallSystem
+}
+
+// HasPierced reports whether a piercing shot already hit the enemy
+func (p *ProjectileEntity) HasPierced(enemyID string) bool {
+	for _, id := range p.Pierced {
+		if id == enemyID {
+			return true
+		}
+	}
+	return false
+}
+
 // Damageable represents entities that can take damage
 type Damageable interface {
 	TakeDamage(damage int)
@@ -132,6 +327,6 @@ type Damageable interface {
 
 // Shooter represents entities that can shoot
 type Shooter interface {
-	CanShoot() bool
-	Shoot()
+	CanShoot(now time.Time) bool
+	Shoot(now time.Time)
 }
@@ -38,6 +38,13 @@ func (f *EntityFactory) CreateTower(towerType string, pos Position) (*TowerEntit
 		FireRate:     cfg.FireRate,
 		SplashRadius: cfg.SplashRadius,
 		LastShot:     time.Now().Add(-time.Hour), // Can shoot immediately
+		Stats:        TowerStats{Value: cfg.Cost},
+	}
+	if a := cfg.Aura; a != nil {
+		tower.Aura = &Aura{Radius: a.Radius, Damage: a.Damage, FireRate: a.FireRate, Range: a.Range}
+	} else if f.config.Ammo.Enabled {
+		tower.MaxAmmo = f.config.MagazineSize(towerType)
+		tower.Ammo = tower.MaxAmmo
 	}
 	
 	return tower, nil
@@ -67,6 +74,10 @@ func (f *EntityFactory) CreateEnemy(enemyType string, pos Position, wave int) (*
 		PathIndex:   0,
 		GoldReward:  cfg.GoldReward,
 		ScoreReward: cfg.ScoreReward,
+		WallDamage:  cfg.WallDamage,
+	}
+	if enemy.WallDamage == 0 {
+		enemy.WallDamage = f.config.Walls.EnemyDamage
 	}
 	
 	return enemy, nil
@@ -91,78 +102,51 @@ func (f *EntityFactory) CreateProjectile(projType string, pos Position, targetID
 		Speed:          cfg.Speed,
 		Damage:         damage,
 		SplashRadius:   splashRadius,
+		Behavior:       cfg.Behavior,
+		Origin:         pos,
+		HitRadius:      cfg.HitRadius,
+		MaxHits:        cfg.MaxHits,
+		TTL:            cfg.Duration,
+		Jumps:          cfg.Jumps,
+		JumpRadius:     cfg.JumpRadius,
+		Falloff:        cfg.Falloff,
+		Lead:           cfg.Lead,
+		MaxLifetime:    cfg.MaxLifetime,
+	}
+	if projectile.Behavior == "" {
+		projectile.Behavior = gameconfig.ProjectileHoming
 	}
 	
 	return projectile, nil
 }
 
+// CreateWall creates a new wall entity
+func (f *EntityFactory) CreateWall(pos Position) *WallEntity {
+	cfg := f.config.Walls
+	return &WallEntity{
+		BaseEntity: BaseEntity{
+			ID:       uuid.New().String(),
+			Type:     EntityTypeWall,
+			Position: pos,
+			Alive:    true,
+		},
+		HP:     float64(cfg.HP),
+		MaxHP:  float64(cfg.HP),
+		Radius: cfg.Radius,
+	}
+}
+
 // CreateEnemiesForWave creates all enemies for a given wave
 func (f *EntityFactory) CreateEnemiesForWave(wave int, startPos Position) ([]*EnemyEntity, error) {
-	// Calculate total number of enemies for this wave
-	totalEnemies := f.config.CalculateEnemiesForWave(wave)
-	composition := f.config.GetWaveComposition(wave)
 	enemies := []*EnemyEntity{}
-	
-	// Calculate total weight from composition percentages
-	totalWeight := composition.Basic + composition.Fast + composition.Tank + composition.Boss
-	if totalWeight == 0 {
-		totalWeight = 100 // Default if not specified
-		composition.Basic = 100
-	}
-	
-	// Calculate actual count for each enemy type based on percentages
-	basicCount := (totalEnemies * composition.Basic) / totalWeight
-	fastCount := (totalEnemies * composition.Fast) / totalWeight
-	tankCount := (totalEnemies * composition.Tank) / totalWeight
-	bossCount := (totalEnemies * composition.Boss) / totalWeight
-	
-	// Ensure at least totalEnemies are created (handle rounding)
-	currentTotal := basicCount + fastCount + tankCount + bossCount
-	if currentTotal < totalEnemies {
-		// Add remaining to the most common type
-		if composition.Basic > 0 {
-			basicCount += totalEnemies - currentTotal
-		} else if composition.Fast > 0 {
-			fastCount += totalEnemies - currentTotal
-		} else if composition.Tank > 0 {
-			tankCount += totalEnemies - currentTotal
-		} else {
-			bossCount += totalEnemies - currentTotal
-		}
-	}
-	
-	// Helper to create N enemies of a type
-	createN := func(enemyType string, count int) error {
-		for i := 0; i < count; i++ {
-			enemy, err := f.CreateEnemy(enemyType, startPos, wave)
+	for _, tc := range f.config.WaveEnemyCounts(wave) {
+		for n := 0; n < tc.Count; n++ {
+			enemy, err := f.CreateEnemy(tc.EnemyType, startPos, wave)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			enemies = append(enemies, enemy)
 		}
-		return nil
-	}
-	
-	// Create enemies based on calculated counts
-	if basicCount > 0 {
-		if err := createN("basic", basicCount); err != nil {
-			return nil, err
-		}
-	}
-	if fastCount > 0 {
-		if err := createN("fast", fastCount); err != nil {
-			return nil, err
-		}
-	}
-	if tankCount > 0 {
-		if err := createN("tank", tankCount); err != nil {
-			return nil, err
-		}
-	}
-	if bossCount > 0 {
-		if err := createN("boss", bossCount); err != nil {
-			return nil, err
-		}
 	}
 	
 	if len(enemies) == 0 {
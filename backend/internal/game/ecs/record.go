@@ -0,0 +1,275 @@
+package ecs
+
+import "time"
+
+// TowerRecord is the full-fidelity serializable form of a TowerEntity.
+// Cooldowns are stored relative to the save time so they survive restarts.
+type TowerRecord struct {
+	ID            string   `json:"id"`
+	Position      Position `json:"position"`
+	TowerType     string   `json:"towerType"`
+	Range         float64  `json:"range"`
+	Damage        int      `json:"damage"`
+	FireRate      float64  `json:"fireRate"`
+	SplashRadius  float64  `json:"splashRadius,omitempty"`
+	OwnerID       string   `json:"ownerId,omitempty"`
+	SinceLastShot float64  `json:"sinceLastShot"` // seconds
+	Aura          *Aura    `json:"aura,omitempty"`
+
+	Stats TowerStats `json:"stats"`
+
+	Ammo    int `json:"ammo,omitempty"`
+	MaxAmmo int `json:"maxAmmo,omitempty"`
+}
+
+// Record captures the tower state relative to now
+func (t *TowerEntity) Record(now time.Time) TowerRecord {
+	return TowerRecord{
+		ID:            t.ID,
+		Position:      t.Position,
+		TowerType:     t.TowerType,
+		Range:         t.Range,
+		Damage:        t.Damage,
+		FireRate:      t.FireRate,
+		SplashRadius:  t.SplashRadius,
+		OwnerID:       t.OwnerID,
+		SinceLastShot: now.Sub(t.LastShot).Seconds(),
+		Aura:          t.Aura,
+		Stats:         t.Stats,
+		Ammo:          t.Ammo,
+		MaxAmmo:       t.MaxAmmo,
+	}
+}
+
+// Entity rebuilds the tower relative to now
+func (r TowerRecord) Entity(now time.Time) *TowerEntity {
+	return &TowerEntity{
+		BaseEntity:   BaseEntity{ID: r.ID, Type: EntityTypeTower, Position: r.Position, Alive: true},
+		TowerType:    r.TowerType,
+		Range:        r.Range,
+		Damage:       r.Damage,
+		FireRate:     r.FireRate,
+		SplashRadius: r.SplashRadius,
+		OwnerID:      r.OwnerID,
+		LastShot:     now.Add(-time.Duration(r.SinceLastShot * float64(time.Second))),
+		Aura:         r.Aura,
+		Stats:        r.Stats,
+		Ammo:         r.Ammo,
+		MaxAmmo:      r.MaxAmmo,
+	}
+}
+
+// EnemyRecord is the full-fidelity serializable form of an EnemyEntity
+type EnemyRecord struct {
+	ID              string   `json:"id"`
+	Position        Position `json:"position"`
+	EnemyType       string   `json:"enemyType"`
+	HP              int      `json:"hp"`
+	MaxHP           int      `json:"maxHp"`
+	Speed           float64  `json:"speed"`
+	PathIndex       int      `json:"pathIndex"`
+	PathID          int      `json:"pathId"`
+	GoldReward      int      `json:"goldReward"`
+	ScoreReward     int      `json:"scoreReward"`
+	LastHitBy       string   `json:"lastHitBy,omitempty"`
+	WallDamage      float64  `json:"wallDamage,omitempty"`
+	HealCooldown    float64  `json:"healCooldown,omitempty"`
+	ShieldCooldown  float64  `json:"shieldCooldown,omitempty"`
+	BossPhase       int      `json:"bossPhase,omitempty"`
+	Shield          int      `json:"shield,omitempty"`
+	ShieldTimer     float64  `json:"shieldTimer,omitempty"`
+	BaseSpeed       float64  `json:"baseSpeed,omitempty"`
+	SpeedBurstTimer float64  `json:"speedBurstTimer,omitempty"`
+
+	Affixes      []string `json:"affixes,omitempty"`
+	SplashResist float64  `json:"splashResist,omitempty"`
+	PhaseTimer   float64  `json:"phaseTimer,omitempty"`
+	Phased       bool     `json:"phased,omitempty"`
+}
+
+// Record captures the enemy state
+func (e *EnemyEntity) Record() EnemyRecord {
+	return EnemyRecord{
+		ID:              e.ID,
+		Position:        e.Position,
+		EnemyType:       e.EnemyType,
+		HP:              e.HP,
+		MaxHP:           e.MaxHP,
+		Speed:           e.Speed,
+		PathIndex:       e.PathIndex,
+		PathID:          e.PathID,
+		GoldReward:      e.GoldReward,
+		ScoreReward:     e.ScoreReward,
+		LastHitBy:       e.LastHitBy,
+		WallDamage:      e.WallDamage,
+		HealCooldown:    e.HealCooldown,
+		ShieldCooldown:  e.ShieldCooldown,
+		BossPhase:       e.BossPhase,
+		Shield:          e.Shield,
+		ShieldTimer:     e.ShieldTimer,
+		BaseSpeed:       e.BaseSpeed,
+		SpeedBurstTimer: e.SpeedBurstTimer,
+
+		Affixes:      e.Affixes,
+		SplashResist: e.SplashResist,
+		PhaseTimer:   e.PhaseTimer,
+		Phased:       e.Phased,
+	}
+}
+
+// Entity rebuilds the enemy
+func (r EnemyRecord) Entity() *EnemyEntity {
+	return &EnemyEntity{
+		BaseEntity:      BaseEntity{ID: r.ID, Type: EntityTypeEnemy, Position: r.Position, Alive: true},
+		EnemyType:       r.EnemyType,
+		HP:              r.HP,
+		MaxHP:           r.MaxHP,
+		Speed:           r.Speed,
+		PathIndex:       r.PathIndex,
+		PathID:          r.PathID,
+		GoldReward:      r.GoldReward,
+		ScoreReward:     r.ScoreReward,
+		LastHitBy:       r.LastHitBy,
+		WallDamage:      r.WallDamage,
+		HealCooldown:    r.HealCooldown,
+		ShieldCooldown:  r.ShieldCooldown,
+		BossPhase:       r.BossPhase,
+		Shield:          r.Shield,
+		ShieldTimer:     r.ShieldTimer,
+		BaseSpeed:       r.BaseSpeed,
+		SpeedBurstTimer: r.SpeedBurstTimer,
+
+		Affixes:      r.Affixes,
+		SplashResist: r.SplashResist,
+		PhaseTimer:   r.PhaseTimer,
+		Phased:       r.Phased,
+	}
+}
+
+// ProjectileRecord is the full-fidelity serializable form of a ProjectileEntity
+type ProjectileRecord struct {
+	ID             string   `json:"id"`
+	Position       Position `json:"position"`
+	ProjectileType string   `json:"projectileType"`
+	Target         string   `json:"target"`
+	Speed          float64  `json:"speed"`
+	Damage         int      `json:"damage"`
+	SplashRadius   float64  `json:"splashRadius,omitempty"`
+	SourceID       string   `json:"sourceId,omitempty"`
+	CritChance     float64  `json:"critChance,omitempty"`
+	CritMultiplier float64  `json:"critMultiplier,omitempty"`
+	MissChance     float64  `json:"missChance,omitempty"`
+	Behavior       string   `json:"behavior,omitempty"`
+	Origin         Position `json:"origin"`
+	Impact         Position `json:"impact"`
+	HitRadius      float64  `json:"hitRadius,omitempty"`
+	MaxHits        int      `json:"maxHits,omitempty"`
+	Pierced        []string `json:"pierced,omitempty"`
+	TTL            float64  `json:"ttl,omitempty"`
+	Spent          bool     `json:"spent,omitempty"`
+
+	Jumps      int        `json:"jumps,omitempty"`
+	JumpRadius float64    `json:"jumpRadius,omitempty"`
+	Falloff    float64    `json:"falloff,omitempty"`
+	Chain      []Position `json:"chain,omitempty"`
+
+	Lead        bool    `json:"lead,omitempty"`
+	MaxLifetime float64 `json:"maxLifetime,omitempty"`
+	Age         float64 `json:"age,omitempty"`
+}
+
+// Record captures the projectile state
+func (p *ProjectileEntity) Record() ProjectileRecord {
+	return ProjectileRecord{
+		ID:             p.ID,
+		Position:       p.Position,
+		ProjectileType: p.ProjectileType,
+		Target:         p.Target,
+		Speed:          p.Speed,
+		Damage:         p.Damage,
+		SplashRadius:   p.SplashRadius,
+		SourceID:       p.SourceID,
+		CritChance:     p.CritChance,
+		CritMultiplier: p.CritMultiplier,
+		MissChance:     p.MissChance,
+		Behavior:       p.Behavior,
+		Origin:         p.Origin,
+		Impact:         p.Impact,
+		HitRadius:      p.HitRadius,
+		MaxHits:        p.MaxHits,
+		Pierced:        p.Pierced,
+		TTL:            p.TTL,
+		Spent:          p.Spent,
+		Jumps:          p.Jumps,
+		JumpRadius:     p.JumpRadius,
+		Falloff:        p.Falloff,
+		Chain:          p.Chain,
+		Lead:           p.Lead,
+		MaxLifetime:    p.MaxLifetime,
+		Age:            p.Age,
+	}
+}
+
+// Entity rebuilds the projectile
+func (r ProjectileRecord) Entity() *ProjectileEntity {
+	return &ProjectileEntity{
+		BaseEntity:     BaseEntity{ID: r.ID, Type: EntityTypeProjectile, Position: r.Position, Alive: true},
+		ProjectileType: r.ProjectileType,
+		Target:         r.Target,
+		Speed:          r.Speed,
+		Damage:         r.Damage,
+		SplashRadius:   r.SplashRadius,
+		SourceID:       r.SourceID,
+		CritChance:     r.CritChance,
+		CritMultiplier: r.CritMultiplier,
+		MissChance:     r.MissChance,
+		Behavior:       r.Behavior,
+		Origin:         r.Origin,
+		Impact:         r.Impact,
+		HitRadius:      r.HitRadius,
+		MaxHits:        r.MaxHits,
+		Pierced:        r.Pierced,
+		TTL:            r.TTL,
+		Spent:          r.Spent,
+		Jumps:          r.Jumps,
+		JumpRadius:     r.JumpRadius,
+		Falloff:        r.Falloff,
+		Chain:          r.Chain,
+		Lead:           r.Lead,
+		MaxLifetime:    r.MaxLifetime,
+		Age:            r.Age,
+	}
+}
+
+// WallRecord is the full-fidelity serializable form of a WallEntity
+type WallRecord struct {
+	ID       string   `json:"id"`
+	Position Position `json:"position"`
+	HP       float64  `json:"hp"`
+	MaxHP    float64  `json:"maxHp"`
+	Radius   float64  `json:"radius"`
+	OwnerID  string   `json:"ownerId,omitempty"`
+}
+
+// Record captures the wall state
+func (w *WallEntity) Record() WallRecord {
+	return WallRecord{
+		ID:       w.ID,
+		Position: w.Position,
+		HP:       w.HP,
+		MaxHP:    w.MaxHP,
+		Radius:   w.Radius,
+		OwnerID:  w.OwnerID,
+	}
+}
+
+// Entity rebuilds the wall
+func (r WallRecord) Entity() *WallEntity {
+	return &WallEntity{
+		BaseEntity: BaseEntity{ID: r.ID, Type: EntityTypeWall, Position: r.Position, Alive: true},
+		HP:         r.HP,
+		MaxHP:      r.MaxHP,
+		Radius:     r.Radius,
+		OwnerID:    r.OwnerID,
+	}
+}
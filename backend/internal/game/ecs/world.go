@@ -1,18 +1,31 @@
 package ecs
 
 import (
+	"slices"
 	"sync"
+	"time"
 )
 
-// World manages all entities and provides queries
+// World manages all entities and provides queries. Queries list entities in
+// the order they were added, so snapshots built from them come out the same
+// every tick and diffs and checksums stay stable.
 type World struct {
 	mu       sync.RWMutex
 	entities map[string]Entity
+	order    []string         // entity IDs in insertion order
+	clock    func() time.Time // the time systems see, see Now
 	
 	// Indexed by type for fast queries
 	towers      map[string]*TowerEntity
 	enemies     map[string]*EnemyEntity
 	projectiles map[string]*ProjectileEntity
+	walls       map[string]*WallEntity
+
+	// The same entities by type in insertion order
+	towerList      []*TowerEntity
+	enemyList      []*EnemyEntity
+	projectileList []*ProjectileEntity
+	wallList       []*WallEntity
 }
 
 // NewWorld creates a new ECS world
@@ -22,25 +35,56 @@ func NewWorld() *World {
 		towers:      make(map[string]*TowerEntity),
 		enemies:     make(map[string]*EnemyEntity),
 		projectiles: make(map[string]*ProjectileEntity),
+		walls:       make(map[string]*WallEntity),
+		clock:       time.Now,
 	}
 }
 
+// Now returns the current time of the simulation: the wall clock, unless a
+// dry run set its own clock
+func (w *World) Now() time.Time {
+	return w.clock()
+}
+
+// SetClock replaces the clock systems read, e.g. to run a copy of the world
+// faster than real time
+func (w *World) SetClock(now func() time.Time) {
+	w.clock = now
+}
+
 // AddEntity adds an entity to the world
 func (w *World) AddEntity(entity Entity) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	
+	w.addLocked(entity)
+}
+
+// addLocked adds an entity and indexes it by type (caller must hold w.mu or
+// own a world nobody else can see yet). An entity re-added under an ID already
+// in use replaces the old one and moves to the end of the order.
+func (w *World) addLocked(entity Entity) {
 	id := entity.GetID()
+	if _, ok := w.entities[id]; ok {
+		w.unindexLocked(id)
+		w.compactLocked()
+	}
 	w.entities[id] = entity
+	w.order = append(w.order, id)
 	
 	// Add to type-specific index
 	switch e := entity.(type) {
 	case *TowerEntity:
 		w.towers[id] = e
+		w.towerList = append(w.towerList, e)
 	case *EnemyEntity:
 		w.enemies[id] = e
+		w.enemyList = append(w.enemyList, e)
 	case *ProjectileEntity:
 		w.projectiles[id] = e
+		w.projectileList = append(w.projectileList, e)
+	case *WallEntity:
+		w.walls[id] = e
+		w.wallList = append(w.wallList, e)
 	}
 }
 
@@ -49,11 +93,17 @@ func (w *World) RemoveEntity(id string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	
-	entity, ok := w.entities[id]
-	if !ok {
+	if _, ok := w.entities[id]; !ok {
 		return
 	}
-	
+	w.unindexLocked(id)
+	w.compactLocked()
+}
+
+// unindexLocked drops an entity from the maps; the ordered lists keep it until
+// compactLocked runs (caller must hold w.mu)
+func (w *World) unindexLocked(id string) {
+	entity := w.entities[id]
 	delete(w.entities, id)
 	
 	// Remove from type-specific index
@@ -64,9 +114,35 @@ func (w *World) RemoveEntity(id string) {
 		delete(w.enemies, id)
 	case EntityTypeProjectile:
 		delete(w.projectiles, id)
+	case EntityTypeWall:
+		delete(w.walls, id)
 	}
 }
 
+// compactLocked drops the entities no longer in the maps from the ordered
+// lists, keeping the order of the rest (caller must hold w.mu)
+func (w *World) compactLocked() {
+	w.order = slices.DeleteFunc(w.order, func(id string) bool {
+		_, ok := w.entities[id]
+		return !ok
+	})
+	w.towerList = compact(w.towerList, w.towers)
+	w.enemyList = compact(w.enemyList, w.enemies)
+	w.projectileList = compact(w.projectileList, w.projectiles)
+	w.wallList = compact(w.wallList, w.walls)
+}
+
+// compact drops the entities of list that index no longer holds
+func compact[E interface {
+	comparable
+	Entity
+}](list []E, index map[string]E) []E {
+	return slices.DeleteFunc(list, func(e E) bool {
+		indexed, ok := index[e.GetID()]
+		return !ok || indexed != e
+	})
+}
+
 // GetEntity retrieves an entity by ID
 func (w *World) GetEntity(id string) (Entity, bool) {
 	w.mu.RLock()
@@ -81,7 +157,7 @@ func (w *World) GetTowers() []*TowerEntity {
 	defer w.mu.RUnlock()
 	
 	towers := make([]*TowerEntity, 0, len(w.towers))
-	for _, t := range w.towers {
+	for _, t := range w.towerList {
 		if t.Alive {
 			towers = append(towers, t)
 		}
@@ -95,7 +171,7 @@ func (w *World) GetEnemies() []*EnemyEntity {
 	defer w.mu.RUnlock()
 	
 	enemies := make([]*EnemyEntity, 0, len(w.enemies))
-	for _, e := range w.enemies {
+	for _, e := range w.enemyList {
 		if e.Alive {
 			enemies = append(enemies, e)
 		}
@@ -109,7 +185,7 @@ func (w *World) GetProjectiles() []*ProjectileEntity {
 	defer w.mu.RUnlock()
 	
 	projectiles := make([]*ProjectileEntity, 0, len(w.projectiles))
-	for _, p := range w.projectiles {
+	for _, p := range w.projectileList {
 		if p.Alive {
 			projectiles = append(projectiles, p)
 		}
@@ -117,6 +193,20 @@ func (w *World) GetProjectiles() []*ProjectileEntity {
 	return projectiles
 }
 
+// GetWalls returns all standing walls
+func (w *World) GetWalls() []*WallEntity {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	
+	walls := make([]*WallEntity, 0, len(w.walls))
+	for _, wall := range w.wallList {
+		if wall.Alive {
+			walls = append(walls, wall)
+		}
+	}
+	return walls
+}
+
 // GetEnemy retrieves a specific enemy by ID
 func (w *World) GetEnemy(id string) (*EnemyEntity, bool) {
 	w.mu.RLock()
@@ -125,29 +215,23 @@ func (w *World) GetEnemy(id string) (*EnemyEntity, bool) {
 	return enemy, ok
 }
 
-// CleanupDeadEntities removes all dead entities
-func (w *World) CleanupDeadEntities() []string {
+// CleanupDeadEntities removes all dead entities and returns them in the order
+// they were added
+func (w *World) CleanupDeadEntities() []Entity {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	
-	var removed []string
+	var removed []Entity
 	
-	for id, entity := range w.entities {
-		if !entity.IsAlive() {
-			delete(w.entities, id)
-			
-			switch entity.GetType() {
-			case EntityTypeTower:
-				delete(w.towers, id)
-			case EntityTypeEnemy:
-				delete(w.enemies, id)
-			case EntityTypeProjectile:
-				delete(w.projectiles, id)
-			}
-			
-			removed = append(removed, id)
+	for _, id := range w.order {
+		if entity := w.entities[id]; !entity.IsAlive() {
+			w.unindexLocked(id)
+			removed = append(removed, entity)
 		}
 	}
+	if len(removed) > 0 {
+		w.compactLocked()
+	}
 	
 	return removed
 }
@@ -161,6 +245,12 @@ func (w *World) Clear() {
 	w.towers = make(map[string]*TowerEntity)
 	w.enemies = make(map[string]*EnemyEntity)
 	w.projectiles = make(map[string]*ProjectileEntity)
+	w.walls = make(map[string]*WallEntity)
+	w.order = nil
+	w.towerList = nil
+	w.enemyList = nil
+	w.projectileList = nil
+	w.wallList = nil
 }
 
 // EntityCount returns the total number of entities
@@ -182,3 +272,29 @@ func (w *World) TowerCount() int {
 	}
 	return count
 }
+
+// WallCount returns the number of standing walls
+func (w *World) WallCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	count := 0
+	for _, wall := range w.walls {
+		if wall.Alive {
+			count++
+		}
+	}
+	return count
+}
+
+// EnemyCount returns the number of living enemies
+func (w *World) EnemyCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	count := 0
+	for _, e := range w.enemies {
+		if e.Alive {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"tower-defense/internal/game/config"
+)
+
+// benchSizes are the entity counts every benchmark runs at
+var benchSizes = []int{100, 1000, 10000}
+
+// benchScene is a world of n entities: a tenth towers, half enemies and the
+// rest homing projectiles, spread over the map with a fixed seed
+type benchScene struct {
+	world   *World
+	enemies []*EnemyEntity
+}
+
+func newBenchScene(b *testing.B, n int) *benchScene {
+	b.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		b.Fatal(err)
+	}
+	factory := NewEntityFactory(cfg)
+	r := rand.New(rand.NewSource(1))
+	randomPos := func() Position {
+		return Position{X: r.Float64() * float64(cfg.Map.Width), Y: r.Float64() * float64(cfg.Map.Height)}
+	}
+
+	s := &benchScene{world: NewWorld()}
+	towers, enemies := n/10, n/2
+	for range towers {
+		t, err := factory.CreateTower("basic", randomPos())
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.world.AddEntity(t)
+	}
+	for range enemies {
+		e, err := factory.CreateEnemy("basic", randomPos(), 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.enemies = append(s.enemies, e)
+		s.world.AddEntity(e)
+	}
+	for range n - towers - enemies {
+		p, err := factory.CreateProjectile("basic", randomPos(), s.enemies[r.Intn(len(s.enemies))].ID, 1, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.world.AddEntity(p)
+	}
+	return s
+}
+
+// benchSized runs fn as a sub-benchmark per scene size
+func benchSized(b *testing.B, fn func(b *testing.B, s *benchScene)) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			s := newBenchScene(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			fn(b, s)
+		})
+	}
+}
+
+func BenchmarkWorldGetEnemies(b *testing.B) {
+	benchSized(b, func(b *testing.B, s *benchScene) {
+		for range b.N {
+			s.world.GetEnemies()
+		}
+	})
+}
+
+func BenchmarkWorldGetTowers(b *testing.B) {
+	benchSized(b, func(b *testing.B, s *benchScene) {
+		for range b.N {
+			s.world.GetTowers()
+		}
+	})
+}
+
+func BenchmarkWorldGetEnemy(b *testing.B) {
+	benchSized(b, func(b *testing.B, s *benchScene) {
+		for i := range b.N {
+			s.world.GetEnemy(s.enemies[i%len(s.enemies)].ID)
+		}
+	})
+}
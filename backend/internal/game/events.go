@@ -0,0 +1,69 @@
+package game
+
+import (
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// AddEventListener registers a listener for gameplay events emitted by this game.
+// Listeners are invoked outside the game lock, after the operation that produced the events.
+func (g *Game) AddEventListener(l events.Listener) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.listeners = append(g.listeners, l)
+}
+
+// emit queues an event for delivery and runs the plugin hooks watching it
+// (caller must hold g.mu)
+func (g *Game) emit(ev events.Event) {
+	ev.GameID = g.id
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Tick, ev.GameTime = g.clock.Tick, g.clock.Time
+	g.pendingEvents = append(g.pendingEvents, ev)
+	g.recordSummary(ev)
+	g.runHooks(ev)
+	if g.verbose {
+		logging.Infow("game_event", "game_id", g.id, "type", ev.Type, "wave", ev.Wave, "entity_id", ev.EntityID, "detail", ev.Detail)
+	}
+}
+
+// flushEvents delivers queued events to listeners (caller must not hold g.mu).
+// A summary finished by a queued game over is stored first, so listeners can read it.
+func (g *Game) flushEvents() {
+	g.storeSummary()
+	g.storeCheckpoint()
+	g.storeReplay()
+
+	g.mu.Lock()
+	pending := g.pendingEvents
+	g.pendingEvents = nil
+	listeners := g.listeners
+	g.mu.Unlock()
+
+	for _, ev := range pending {
+		for _, l := range listeners {
+			l(ev)
+		}
+	}
+}
+
+// trackWaveProgress syncs the wave number and the phase and emits wave start
+// events (caller must hold g.mu)
+func (g *Game) trackWaveProgress() {
+	wave := g.waveSystem.GetCurrentWave()
+	if wave != g.state.Wave {
+		g.state.Wave = wave
+		g.takeCheckpoint()
+		g.emit(events.Event{Type: events.WaveStarted, Wave: wave, Detail: g.waveSystem.CurrentModifier()})
+		// Announce the next wave's modifier a whole wave ahead
+		if next := g.waveSystem.ModifierFor(wave + 1); next != "" {
+			g.emit(events.Event{Type: events.WaveModifier, Wave: wave + 1, Detail: next})
+		}
+		g.tutorialWaveStarted()
+	}
+	g.updatePhase()
+}
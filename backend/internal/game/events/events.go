@@ -0,0 +1,76 @@
+package events
+
+import "time"
+
+// Type identifies a gameplay event
+type Type string
+
+const (
+	TowerPlaced   Type = "tower_placed"
+	WallPlaced    Type = "wall_placed"
+	WallDestroyed Type = "wall_destroyed" // EnemyType is the enemy that broke it
+	EnemyKilled   Type = "enemy_killed"
+	Hit           Type = "hit" // a projectile landed on its target; Detail is "crit", "miss" or empty
+	EnemyLeaked   Type = "enemy_leaked"
+	WaveStarted   Type = "wave_started" // Detail is the wave modifier, if any
+	WaveCompleted Type = "wave_completed"
+	InterestPaid  Type = "interest_paid"
+	WaveBonus     Type = "wave_bonus"
+	LivesGained   Type = "lives_gained" // Lives is the number gained, Detail is "regen" or "drop"
+	BossPhase     Type = "boss_phase"
+	GameOver      Type = "game_over"
+	GameReset     Type = "game_reset"
+	GameCreated   Type = "game_created" // a room was created; announced with the game's first flushed events
+	GameCrashed   Type = "game_crashed" // the game loop panicked; Detail is "resumed" or "stopped"
+	RolledBack    Type = "rolled_back"  // the game went back to the start of Wave, see Game.Rollback
+
+	TutorialStep      Type = "tutorial_step"      // Detail is the step the player is prompted for
+	TutorialCompleted Type = "tutorial_completed" // PlayerID finished every tutorial step
+
+	PlayerReady Type = "player_ready" // PlayerID is ready in the lobby, Detail is "ready" or "not_ready"
+	LobbyPhase  Type = "lobby_phase"  // Detail is the lobby's new phase, e.g. "starting"
+
+	TowerResupplied Type = "tower_resupplied" // Gold is the cost, Detail is "auto" or "manual"
+	TowerSold       Type = "tower_sold"       // Gold is the refund, Detail is "full" within the between-waves window
+	WeatherStarted  Type = "weather_started"  // Detail is the weather, e.g. "rain"
+	WeatherEnded    Type = "weather_ended"    // Detail is the weather that cleared
+	WaveModifier    Type = "wave_modifier"    // announces that Wave, the next one, gets modifier Detail
+
+	BlueprintQueued    Type = "blueprint_queued"    // EntityID is the blueprint, Gold the tower's cost
+	BlueprintBuilt     Type = "blueprint_built"     // the blueprint EntityID became a tower, see TowerPlaced
+	BlueprintCancelled Type = "blueprint_cancelled" // PlayerID took EntityID off the queue
+	BlueprintDropped   Type = "blueprint_dropped"   // EntityID can no longer be built; Detail says why
+
+	GoldTransferred Type = "gold_transferred" // PlayerID gave gold to player Detail, who received Gold after tax
+
+	EntityCapReached Type = "entity_cap_reached" // Detail is "enemies" (spawns held) or "projectiles" (shots resolved on the spot)
+	EntityCapCleared Type = "entity_cap_cleared" // the Detail entities are back under their cap
+
+	PhaseChanged Type = "phase_changed" // Detail is the game's new phase, e.g. "intermission"
+)
+
+// Event is a gameplay event emitted by a game instance.
+// Only the fields relevant to the event type are populated.
+type Event struct {
+	Type      Type      `json:"type"`
+	GameID    string    `json:"gameId"`
+	Time      time.Time `json:"time"`
+	Wave      int       `json:"wave,omitempty"`
+	PlayerID  string    `json:"playerId,omitempty"`
+	EntityID  string    `json:"entityId,omitempty"`
+	EnemyType string    `json:"enemyType,omitempty"`
+	TowerID   string    `json:"towerId,omitempty"`
+	TowerType string    `json:"towerType,omitempty"`
+	Damage    int       `json:"damage,omitempty"`
+	Gold      int       `json:"gold,omitempty"`
+	Score     int       `json:"score,omitempty"`
+	Lives     int       `json:"lives,omitempty"`
+	Detail    string    `json:"detail,omitempty"`  // free-form qualifier, e.g. boss phase name
+	Outcome   string    `json:"outcome,omitempty"` // of a game over: "won" or "lost"
+
+	Tick     uint64  `json:"tick"`     // simulation tick the event happened in, see Game snapshots
+	GameTime float64 `json:"gameTime"` // simulated seconds at that tick
+}
+
+// Listener receives events emitted by a game
+type Listener func(Event)
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"sync"
+	"testing"
+
+	"tower-defense/internal/game/config"
+)
+
+// TestForkOwnsItsClock steps a game and its fork at the same time; run with
+// -race to catch the fork reading the game's clock
+func TestForkOwnsItsClock(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame("fork", cfg)
+	if err := g.AddTower("basic", 150, 200); err != nil {
+		t.Fatal(err)
+	}
+	g.Step(1)
+	f := g.Fork()
+	if got, want := f.world.Now(), g.world.Now(); !got.Equal(want) {
+		t.Fatalf("fork starts at %v, want the game's time %v", got, want)
+	}
+
+	var wg sync.WaitGroup
+	for _, game := range []*Game{g, f} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				game.Step(0.05)
+			}
+		}()
+	}
+	wg.Wait()
+
+	gs, fs := g.GetState(), f.GetState()
+	if gs.Tick != fs.Tick || gs.Wave != fs.Wave || gs.Gold != fs.Gold || gs.Lives != fs.Lives {
+		t.Errorf("fork diverged: game tick %d wave %d gold %d lives %d, fork tick %d wave %d gold %d lives %d",
+			gs.Tick, gs.Wave, gs.Gold, gs.Lives, fs.Tick, fs.Wave, fs.Gold, fs.Lives)
+	}
+	if !f.world.Now().Equal(g.world.Now()) {
+		t.Errorf("fork clock at %v, game clock at %v", f.world.Now(), g.world.Now())
+	}
+}
@@ -2,11 +2,16 @@ package game
 
 import (
 	"encoding/json"
+	"math"
 	"sync"
 	"time"
 
 	"tower-defense/internal/game/config"
 	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/plugins"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/game/rng"
 	"tower-defense/internal/game/systems"
 	"tower-defense/internal/logging"
 )
@@ -18,31 +23,162 @@ type GameState struct {
 	Lives    int  `json:"lives"`
 	Score    int  `json:"score"`
 	GameOver bool `json:"gameOver"`
+
+	Rollbacks int `json:"rollbacks,omitempty"` // waves retried from a checkpoint, see Game.Rollback
+
+	Outcome string `json:"outcome,omitempty"` // OutcomeWon or OutcomeLost once the game is over
 }
 
 // Game represents a single game instance using ECS architecture
 type Game struct {
-	mu              sync.RWMutex
-	id              string
-	config          *config.GameConfig
-	world           *ecs.World
-	factory         *ecs.EntityFactory
-	systemManager   *systems.SystemManager
-	state           GameState
-	running         bool
-	ticker          *time.Ticker
-	lastUpdate      time.Time
-	
+	mu            sync.RWMutex
+	id            string
+	mapID         string
+	config        *config.GameConfig
+	world         *ecs.World
+	factory       *ecs.EntityFactory
+	systemManager *systems.SystemManager
+	state         GameState
+	running       bool
+	ticker        *time.Ticker
+	lastUpdate    time.Time
+	lastTick      time.Time // when the loop last called Update, even if the game is over
+
+	// Authoritative simulation clock, stamped on snapshots and events
+	clock gameClock
+
+	// Named random streams, all derived from the game's seed
+	rng *rng.Service
+
+	// Extension hooks, see the plugins package
+	plugins *plugins.Chain
+	forked  bool // made by Fork
+
+	// Entities removed recently, listed in snapshots for death animations
+	removals removalLog
+
+	// Last encoded snapshot, shared by broadcasts, GET /state and saves
+	encoded stateCache
+
 	// Systems
-	movementSystem  *systems.MovementSystem
-	combatSystem    *systems.CombatSystem
+	movementSystem   *systems.MovementSystem
+	auraSystem       *systems.AuraSystem
+	combatSystem     *systems.CombatSystem
 	projectileSystem *systems.ProjectileSystem
-	waveSystem      *systems.WaveSystem
-	rewardSystem    *systems.RewardSystem
-	lifecycleSystem *systems.LifecycleSystem
-	
+	wallSystem       *systems.WallSystem
+	waveSystem       *systems.WaveSystem
+	economySystem    *systems.EconomySystem
+	bossSystem       *systems.BossSystem
+	abilitySystem    *systems.EnemyAbilitySystem
+	rewardSystem     *systems.RewardSystem
+	lifecycleSystem  *systems.LifecycleSystem
+
+	visibilitySystem *systems.VisibilitySystem
+	ammoSystem       *systems.AmmoSystem
+	terrainSystem    *systems.TerrainSystem
+	affixSystem      *systems.AffixSystem
+
 	// Callbacks
-	onTick          func(TickStats)
+	onTick func(TickStats)
+
+	// Events
+	listeners     []events.Listener
+	pendingEvents []events.Event
+
+	// Debugging
+	verbose        bool
+	lastVerboseLog time.Time
+
+	// Tick budget
+	overload   overloadGuard
+	saturation saturation // against EntityLimits
+
+	// Crash recovery
+	crashRepo repository.Repository
+	crashes   []time.Time // recent panics of the game loop
+	errored   bool        // stopped after too many panics
+
+	endedAt time.Time // when the game was last finished, see EndedAt
+
+	// End-of-game report
+	summary     *Summary // of the game in progress
+	finished    *Summary // of the last game that ended
+	unsaved     *Summary // finished but not yet written to summaryRepo
+	summaryRepo repository.Repository
+
+	// Wave checkpoints, see Rollback
+	checkpointRepo    repository.Repository
+	unsavedCheckpoint []byte // taken at the last wave start, not yet stored
+	timeline          string // renewed on reset and load, so older checkpoints are ignored
+
+	// Recording of the run in progress, see ReplayFrames
+	replay        replayRecorder
+	unsavedReplay *Replay // finished but not yet written to replayRepo
+	replayRepo    repository.Repository
+
+	// Analytics
+	heatmap *Heatmap
+
+	// State version for long polling, see WaitForChange
+	version uint64
+	changed chan struct{} // closed and replaced by markChanged
+
+	// Results of recent commands by idempotency key
+	idempotency *idempotencyCache
+
+	// Custom rules chosen at creation, already applied to config
+	mutators []string
+
+	// Actions the config restricts, checked by every mutating method
+	rules *RulesEngine
+
+	// Building, WaveActive or Intermission, see updatePhase
+	phase gamePhase
+
+	// Room's own webhook URL, chosen at creation
+	webhookURL string
+
+	// Code friends join the room with, "" for the default game; private rooms
+	// aren't listed
+	code    string
+	private bool
+
+	// Seats and password of the room, checked by Admit; 0 and nil for none.
+	// Both are fixed at creation.
+	maxPlayers   int
+	passwordHash []byte
+
+	// When each player last acted, see LastActive
+	activity map[string]time.Time
+
+	// Last sequenced command of each client, see CommandProcessed. Kept
+	// across resets, as clients keep numbering their commands.
+	commandSeqs map[string]commandSeq
+
+	// Balance the room was tuned to while running, see TuneBalance
+	overrides config.BalanceOverrides
+
+	// Ready-up before the first wave, nil for rooms that start right away
+	lobby *lobby
+
+	// Tower placements waiting for gold, in build order
+	blueprints []Blueprint
+
+	// Guided tutorial, nil for regular games
+	tutorial *tutorial
+
+	// Modifiers of the players who joined, fixed until the next reset
+	modifierSource ModifierSource
+	players        map[string]PlayerModifiers
+
+	// Gold of each player in rooms with wallets, nil when the players share
+	// state.Gold; see enableWallets
+	wallets      map[string]int
+	startingGold int // the map's, given to each new wallet
+
+	// Simulated time of a game moved by Step instead of its ticker, zero
+	// until the first Step
+	stepClock time.Time
 }
 
 // TickStats contains statistics about the current tick
@@ -51,6 +187,23 @@ type TickStats struct {
 	Projectiles int
 	Towers      int
 	Dt          float64
+	Duration    time.Duration          // time spent computing the tick
+	Systems     []systems.SystemTiming // time spent in each system, in update order
+	Overloaded  bool                   // ticks have been running over budget, see OverloadPolicy
+	SpawnsHeld  int                    // wave spawns held at the enemy limit this tick, see EntityLimits
+	Hitscans    int                    // shots resolved on the spot at the projectile limit this tick
+	Saturated   bool                   // the game is at one of its entity limits
+
+	Tick     uint64  // number of this tick, see gameClock
+	GameTime float64 // simulated seconds after this tick
+}
+
+// gameClock counts simulated ticks and seconds since the game was created.
+// Neither goes back on reset or load, so clients can order snapshots, deltas
+// and events by tick and interpolate positions by game time.
+type gameClock struct {
+	Tick uint64
+	Time float64 // seconds, the sum of the clamped tick deltas
 }
 
 // NewGame creates a new game instance
@@ -60,6 +213,9 @@ func NewGame(id string, cfg *config.GameConfig) *Game {
 
 // NewGameWithMap creates a new game instance with a specific map
 func NewGameWithMap(id string, cfg *config.GameConfig, mapID string) *Game {
+	// Each game owns its config so map selection and hot reloads stay local
+	cfg = cfg.Clone()
+	
 	world := ecs.NewWorld()
 	factory := ecs.NewEntityFactory(cfg)
 	systemManager := systems.NewSystemManager()
@@ -74,14 +230,16 @@ func NewGameWithMap(id string, cfg *config.GameConfig, mapID string) *Game {
 		cfg.Map = mapCfg
 	}
 	
-	// Get start position from map
-	startPos := ecs.Position{X: 0, Y: 250}
-	if len(mapCfg.Path) > 0 {
-		startPos = ecs.Position{
-			X: mapCfg.Path[0].X,
-			Y: mapCfg.Path[0].Y,
+	// Get start position of every path (main path first)
+	entrances := []ecs.Position{}
+	for _, p := range mapCfg.AllPaths() {
+		if len(p) > 0 {
+			entrances = append(entrances, ecs.Position{X: p[0].X, Y: p[0].Y})
 		}
 	}
+	if len(entrances) == 0 {
+		entrances = append(entrances, ecs.Position{X: 0, Y: 250})
+	}
 	
 	// Use map-specific starting values or fall back to config defaults
 	startingGold := mapCfg.StartingGold
@@ -96,6 +254,7 @@ func NewGameWithMap(id string, cfg *config.GameConfig, mapID string) *Game {
 	
 	game := &Game{
 		id:            id,
+		mapID:         mapID,
 		config:        cfg,
 		world:         world,
 		factory:       factory,
@@ -108,37 +267,203 @@ func NewGameWithMap(id string, cfg *config.GameConfig, mapID string) *Game {
 			GameOver: false,
 		},
 		lastUpdate: time.Now(),
+		summary:    newSummary(id, mapID),
+		changed:    make(chan struct{}),
+		timeline:   newTimeline(),
+
+		idempotency: newIdempotencyCache(),
+		players:     make(map[string]PlayerModifiers),
+		rng:         rng.NewService(rng.RandomSeed()),
+		plugins:     plugins.NewChain(plugins.Registered()),
+		rules:       NewRulesEngine(cfg.Rules),
+		phase:       gamePhase{name: config.PhaseBuilding},
+
+		startingGold: startingGold,
 	}
+	game.resetHeatmap()
+	
+	// Systems publish simulation events on the bus; rewards, lifecycle and the
+	// external event log react to them
+	bus := systems.NewBus()
 	
 	// Initialize systems
-	game.movementSystem = systems.NewMovementSystem(cfg)
+	game.movementSystem = systems.NewMovementSystem(cfg, bus)
+	game.auraSystem = systems.NewAuraSystem(cfg)
+	game.visibilitySystem = systems.NewVisibilitySystem(cfg)
 	game.combatSystem = systems.NewCombatSystem(cfg, factory)
-	game.projectileSystem = systems.NewProjectileSystem()
-	game.waveSystem = systems.NewWaveSystem(cfg, factory, startPos)
+	game.terrainSystem = systems.NewTerrainSystem(cfg, func(weather string, started bool) {
+		// Called from Update() which already holds the lock
+		ev := events.Event{Type: events.WeatherEnded, Wave: game.state.Wave, Detail: weather}
+		if started {
+			ev.Type = events.WeatherStarted
+		}
+		game.emit(ev)
+	})
+	game.ammoSystem = systems.NewAmmoSystem(cfg, func(tower *ecs.TowerEntity) {
+		// Called from Update() which already holds the lock; towers stay empty
+		// until the next tick that can pay for a round
+		game.resupply(tower, tower.OwnerID, ResupplyAuto)
+	})
+	game.projectileSystem = systems.NewProjectileSystem(bus, game.rng)
+	game.projectileSystem.SetDamageModifier(game.modifyDamage)
+	game.wallSystem = systems.NewWallSystem(bus)
+	game.waveSystem = systems.NewWaveSystem(cfg, factory, entrances, bus, game.rng)
 	
-	game.rewardSystem = systems.NewRewardSystem(func(gold, score int) {
+	game.economySystem = systems.NewEconomySystem(cfg.Economy, func(gold int) {
 		// Note: This callback is called from Update() which already holds the lock
-		// So we don't lock again to avoid deadlock
-		game.state.Gold += gold
+		game.addGold("", gold)
+		game.emit(events.Event{Type: events.InterestPaid, Wave: game.state.Wave, Gold: gold})
+	})
+	
+	game.economySystem.SetOnWaveBonus(game.payWaveBonus)
+	
+	bus.Subscribe(systems.WaveCompleted, func(m systems.Message) {
+		game.emit(events.Event{Type: events.WaveCompleted, Wave: m.Wave, Lives: game.state.Lives})
+		game.payInterest()
+		game.economySystem.PayWaveBonus(game.state.Lives)
+		game.advanceTutorial(TutorialSurviveWave)
+		game.checkVictory(m.Wave)
+	})
+	
+	game.bossSystem = systems.NewBossSystem(cfg, factory, game.waveSystem.GetCurrentWave)
+	game.bossSystem.SetOnPhase(func(boss *ecs.EnemyEntity, phase config.BossPhase) {
+		game.emit(events.Event{
+			Type:      events.BossPhase,
+			Wave:      game.state.Wave,
+			EntityID:  boss.ID,
+			EnemyType: boss.EnemyType,
+			Detail:    phase.Name,
+		})
+	})
+	
+	game.affixSystem = systems.NewAffixSystem(cfg)
+	game.abilitySystem = systems.NewEnemyAbilitySystem(cfg, factory, game.waveSystem.GetCurrentWave, bus)
+	
+	game.rewardSystem = systems.NewRewardSystem(bus, func(gold, score int, towerID string) {
+		// Note: This callback is called from Update() which already holds the lock
+		// So we don't lock again to avoid deadlock. Kill gold goes to the
+		// wallet of the tower's owner, if the room has wallets.
+		game.addGold(game.towerOwner(towerID), game.economySystem.Bounty(gold, game.waveSystem.GetCurrentWave()))
 		game.state.Score += score
 	})
 	
-	game.lifecycleSystem = systems.NewLifecycleSystem(len(cfg.Map.Path), func(lives int) {
+	game.lifecycleSystem = systems.NewLifecycleSystem(cfg, bus, func(lives int) {
 		// Note: This callback is called from Update() which already holds the lock
 		// So we don't lock again to avoid deadlock
 		game.state.Lives -= lives
 		if game.state.Lives <= 0 {
-			game.state.GameOver = true
+			game.finish(OutcomeLost, "")
+		}
+	})
+	game.lifecycleSystem.SetOnLifeGained(func(lives int, reason string, enemy *ecs.EnemyEntity) {
+		if game.state.GameOver {
+			return
+		}
+		if limit := cfg.Lives.MaxLives; limit > 0 {
+			lives = min(lives, limit-game.state.Lives)
+		}
+		if lives <= 0 {
+			return
+		}
+		game.state.Lives += lives
+		ev := events.Event{Type: events.LivesGained, Wave: game.state.Wave, Lives: lives, Detail: reason}
+		if enemy != nil {
+			ev.EntityID = enemy.ID
+			ev.EnemyType = enemy.EnemyType
+		}
+		game.emit(ev)
+	})
+	game.lifecycleSystem.SetOnRemoved(game.recordRemovals)
+	
+	// Subscribed after rewards and lifecycle, so events report their outcome.
+	// The killing tower is credited with the bounty here, where it is scaled.
+	bus.Subscribe(systems.EnemyKilled, func(m systems.Message) {
+		enemy := m.Enemy
+		game.heatmap.add(game.heatmap.Deaths, enemy.Position, 1)
+		game.removals.mark(enemy.ID, RemovalKilled)
+		ev := events.Event{
+			Type:      events.EnemyKilled,
+			Wave:      game.state.Wave,
+			EntityID:  enemy.ID,
+			EnemyType: enemy.EnemyType,
+			TowerID:   enemy.LastHitBy,
+			Gold:      game.economySystem.Bounty(enemy.GoldReward, game.waveSystem.GetCurrentWave()),
+			Score:     enemy.ScoreReward,
+		}
+		if entity, ok := game.world.GetEntity(enemy.LastHitBy); ok {
+			if tower, ok := entity.(*ecs.TowerEntity); ok {
+				ev.TowerType = tower.TowerType
+				ev.PlayerID = tower.OwnerID
+				tower.Stats.GoldEarned += ev.Gold
+			}
+		}
+		game.emit(ev)
+	})
+	
+	// All damage counts toward the summary, but only primary hits become events:
+	// splash hits would multiply the event volume for little value
+	bus.Subscribe(systems.ProjectileHit, func(m systems.Message) {
+		game.recordDamage(m.Projectile.SourceID, m.Damage)
+		game.heatmap.add(game.heatmap.Damage, m.Enemy.Position, m.Damage)
+		if m.Splash {
+			return
+		}
+		ev := events.Event{
+			Type:      events.Hit,
+			Wave:      game.state.Wave,
+			EntityID:  m.Enemy.ID,
+			EnemyType: m.Enemy.EnemyType,
+			TowerID:   m.Projectile.SourceID,
+			Damage:    m.Damage,
 		}
+		switch {
+		case m.Miss:
+			ev.Detail = "miss"
+		case m.Crit:
+			ev.Detail = "crit"
+		}
+		game.emit(ev)
+	})
+	
+	bus.Subscribe(systems.EnemyLeaked, func(m systems.Message) {
+		game.heatmap.add(game.heatmap.Leaks, m.Enemy.Position, 1)
+		game.removals.mark(m.Enemy.ID, RemovalLeaked)
+		game.emit(events.Event{
+			Type:      events.EnemyLeaked,
+			Wave:      game.state.Wave,
+			EntityID:  m.Enemy.ID,
+			EnemyType: m.Enemy.EnemyType,
+			Lives:     1,
+		})
 	})
 	
-	// Register systems in order
+	bus.Subscribe(systems.WallDestroyed, func(m systems.Message) {
+		game.removals.mark(m.Wall.ID, RemovalKilled)
+		game.emit(events.Event{
+			Type:      events.WallDestroyed,
+			Wave:      game.state.Wave,
+			PlayerID:  m.Wall.OwnerID,
+			EntityID:  m.Wall.ID,
+			EnemyType: m.Enemy.EnemyType,
+		})
+	})
+	
+	// Register systems in order. Terrain and auras write different fields and
+	// neither reads what the other writes, so they share a phase.
 	systemManager.AddSystem(game.waveSystem)
+	systemManager.AddSystem(game.economySystem)
+	systemManager.AddPhase(game.terrainSystem, game.auraSystem)
 	systemManager.AddSystem(game.movementSystem)
+	systemManager.AddSystem(game.wallSystem)
 	systemManager.AddSystem(game.combatSystem)
+	systemManager.AddSystem(game.ammoSystem)
 	systemManager.AddSystem(game.projectileSystem)
+	systemManager.AddSystem(game.bossSystem)
+	systemManager.AddSystem(game.abilitySystem)
+	systemManager.AddSystem(game.affixSystem)
 	systemManager.AddSystem(game.rewardSystem)
 	systemManager.AddSystem(game.lifecycleSystem)
+	systemManager.AddSystem(game.visibilitySystem)
 	
 	return game
 }
@@ -146,12 +471,13 @@ func NewGameWithMap(id string, cfg *config.GameConfig, mapID string) *Game {
 // Start starts the game loop
 func (g *Game) Start() {
 	g.mu.Lock()
-	if g.running {
+	if g.running || g.errored || g.stepped() {
 		g.mu.Unlock()
 		return
 	}
 	g.running = true
 	g.lastUpdate = time.Now()
+	g.lastTick = g.lastUpdate
 	g.mu.Unlock()
 	
 	tickRate := time.Duration(g.config.Game.TickRateMs) * time.Millisecond
@@ -163,10 +489,9 @@ func (g *Game) Start() {
 			running := g.running
 			g.mu.RUnlock()
 			
-			if !running {
+			if !running || !g.tick() {
 				return
 			}
-			g.Update()
 		}
 	}()
 	
@@ -190,33 +515,37 @@ func (g *Game) Stop() {
 	logging.Infow("game_stopped", "game_id", g.id)
 }
 
+// maxTickDt is the longest a tick simulates, in seconds; a late tick doesn't catch up
+const maxTickDt = 0.05
+
 // Update processes one game tick
 func (g *Game) Update() {
 	g.mu.Lock()
+	defer g.flushEvents()
 	defer g.mu.Unlock()
 	
+	now := time.Now()
+	g.lastTick = now
 	if g.state.GameOver {
 		return
 	}
 	
-	now := time.Now()
-	dt := now.Sub(g.lastUpdate).Seconds()
+	elapsed := now.Sub(g.lastUpdate)
+	dt := elapsed.Seconds()
 	
 	// Clamp dt to prevent large jumps
 	if dt < 0 {
 		dt = 0
 	}
-	if dt > 0.05 {
-		dt = 0.05
+	if dt > maxTickDt {
+		dt = maxTickDt
 	}
 	
 	g.lastUpdate = now
+	g.advance(now, elapsed, dt)
 	
-	// Update wave number from wave system
-	g.state.Wave = g.waveSystem.GetCurrentWave()
-	
-	// Run all systems
-	g.systemManager.Update(g.world, dt)
+	took := time.Since(now)
+	g.observeTick(now, took)
 	
 	// Send tick stats
 	if g.onTick != nil {
@@ -225,24 +554,77 @@ func (g *Game) Update() {
 			Projectiles: len(g.world.GetProjectiles()),
 			Towers:      len(g.world.GetTowers()),
 			Dt:          dt,
+			Duration:    took,
+			Systems:     g.systemManager.Timings(),
+			Overloaded:  g.overload.overloaded,
+			SpawnsHeld:  g.saturation.spawnsHeld,
+			Hitscans:    g.saturation.hitscans,
+			Saturated:   g.saturation.enemies || g.saturation.projectiles,
+
+			Tick:     g.clock.Tick,
+			GameTime: g.clock.Time,
 		}
 		g.onTick(stats)
 	}
 }
 
+// advance runs one tick of dt simulated seconds that started at now and took
+// elapsed, for Update and Step (caller must hold g.mu)
+func (g *Game) advance(now time.Time, elapsed time.Duration, dt float64) {
+	g.clock.Tick++
+	g.clock.Time += dt
+	g.updateLobby(now)
+	g.recordReplayTick(now, elapsed)
+	g.simulate(dt)
+	g.endReplayTick()
+	g.logVerboseTick(now, dt)
+}
+
+// simulate runs the systems for a tick of dt seconds and takes in what they
+// did; live ticks and replays both step through it (caller must hold g.mu)
+func (g *Game) simulate(dt float64) {
+	// Run all systems
+	g.systemManager.Update(g.world, dt)
+	g.checkSaturation()
+
+	// Update wave number from wave system
+	g.trackWaveProgress()
+	g.buildBlueprints()
+	g.markChanged()
+}
+
 // AddTower attempts to place a tower at the given position
 func (g *Game) AddTower(towerType string, x, y float64) error {
+	return g.AddTowerForPlayer("", towerType, x, y)
+}
+
+// AddTowerForPlayer places a tower owned by the given player.
+// An empty player ID places an unowned tower.
+func (g *Game) AddTowerForPlayer(playerID, towerType string, x, y float64) error {
+	mods, err := g.member(playerID)
+	if err != nil {
+		return err
+	}
 	g.mu.Lock()
+	defer g.flushEvents()
 	defer g.mu.Unlock()
-	
-	// Get tower config
-	towerCfg, err := g.config.GetTowerConfig(towerType)
+	g.touch(playerID)
+	if err := g.checkRules(config.ActionBuild); err != nil {
+		return err
+	}
+	return g.placeTower(playerID, mods, towerType, x, y)
+}
+
+// placeTower validates and places one tower of playerID, whose research
+// modifiers are mods (caller must hold g.mu)
+func (g *Game) placeTower(playerID string, mods PlayerModifiers, towerType string, x, y float64) error {
+	towerCfg, err := g.checkTowerRules(mods, towerType, x, y)
 	if err != nil {
 		return err
 	}
 	
 	// Check if player has enough gold
-	if g.state.Gold < towerCfg.Cost {
+	if g.gold(playerID) < towerCfg.Cost {
 		return ErrNotEnoughGold
 	}
 	
@@ -258,36 +640,93 @@ func (g *Game) AddTower(towerType string, x, y float64) error {
 		return err
 	}
 	
+	tower.OwnerID = playerID
+	if mods.DamageBonus > 0 {
+		tower.Damage = int(math.Round(float64(tower.Damage) * (1 + mods.DamageBonus)))
+	}
 	g.world.AddEntity(tower)
-	g.state.Gold -= towerCfg.Cost
+	g.addGold(playerID, -towerCfg.Cost)
+	g.emit(events.Event{
+		Type:      events.TowerPlaced,
+		Wave:      g.state.Wave,
+		PlayerID:  playerID,
+		TowerID:   tower.ID,
+		TowerType: towerType,
+		Gold:      towerCfg.Cost,
+	})
+	g.advanceTutorial(TutorialPlaceTower)
+	g.markChanged()
 	
 	logging.Infow("tower_placed", 
 		"game_id", g.id, 
+		"player_id", playerID,
 		"tower_type", towerType,
 		"x", x, "y", y, 
-		"gold_remaining", g.state.Gold)
+		"gold_remaining", g.gold(playerID))
+	
+	return nil
+}
+
+// checkTowerRules checks everything about building a tower other than its
+// price and spot, returning its config (caller must hold g.mu)
+func (g *Game) checkTowerRules(mods PlayerModifiers, towerType string, x, y float64) (config.TowerConfig, error) {
+	if g.state.GameOver {
+		return config.TowerConfig{}, ErrGameFinished
+	}
+	// Reject garbage input before touching any game rules
+	if err := g.validateCoordinates(x, y); err != nil {
+		return config.TowerConfig{}, err
+	}
 	
+	// Get tower config
+	towerCfg, err := g.config.GetTowerConfig(towerType)
+	if err != nil {
+		return config.TowerConfig{}, err
+	}
+	if mods.LockedTowers[towerType] {
+		return config.TowerConfig{}, ErrTowerLocked
+	}
+	if err := g.checkTutorial(TutorialPlaceTower); err != nil {
+		return config.TowerConfig{}, err
+	}
+	if g.world.TowerCount() >= g.config.Placement.MaxTowers {
+		return config.TowerConfig{}, ErrMaxTowers
+	}
+	return towerCfg, nil
+}
+
+// validateCoordinates rejects non-finite and off-map positions (caller must hold g.mu)
+func (g *Game) validateCoordinates(x, y float64) error {
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return ErrInvalidCoordinates
+	}
+	if x < 0 || y < 0 || x > float64(g.config.Map.Width) || y > float64(g.config.Map.Height) {
+		return ErrOutOfBounds
+	}
 	return nil
 }
 
 // isValidPlacement checks if a tower can be placed at the given position
 func (g *Game) isValidPlacement(pos ecs.Position) bool {
-	// Check tower count limit
-	if g.world.TowerCount() >= g.config.Placement.MaxTowers {
-		return false
+	// Nothing is built on blocking terrain
+	for _, o := range g.config.Map.Obstacles {
+		if o.Contains(pos.X, pos.Y) {
+			return false
+		}
 	}
-	
-	// Check distance from path
-	path := g.movementSystem.GetPath()
+
+	// Check distance from every path
 	minDistFromPath := g.config.Placement.MinDistanceFromPath
 	
-	for i := 0; i < len(path)-1; i++ {
-		p1 := path[i]
-		p2 := path[i+1]
-		
-		dist := distanceToSegment(pos, p1, p2)
-		if dist < minDistFromPath {
-			return false
+	for _, path := range g.movementSystem.GetPaths() {
+		for i := 0; i < len(path)-1; i++ {
+			p1 := path[i]
+			p2 := path[i+1]
+			
+			dist := distanceToSegment(pos, p1, p2)
+			if dist < minDistFromPath {
+				return false
+			}
 		}
 	}
 	
@@ -319,30 +758,76 @@ func (g *Game) GetState() GameStateSnapshot {
 		path[i] = PosDTO{X: p.X, Y: p.Y}
 	}
 	
+	var entrances [][]PosDTO
+	for _, e := range g.config.Map.Entrances {
+		ep := make([]PosDTO, len(e.Path))
+		for i, p := range e.Path {
+			ep[i] = PosDTO{X: p.X, Y: p.Y}
+		}
+		entrances = append(entrances, ep)
+	}
+	
 	return GameStateSnapshot{
-		Towers:      g.convertTowers(),
-		Enemies:     g.convertEnemies(),
-		Projectiles: g.convertProjectiles(),
-		Wave:        g.state.Wave,
-		Gold:        g.state.Gold,
-		Lives:       g.state.Lives,
-		Score:       g.state.Score,
-		GameOver:    g.state.GameOver,
-		Path:        path,
-		MapWidth:    g.config.Map.Width,
-		MapHeight:   g.config.Map.Height,
+		Towers:            g.convertTowers(),
+		Enemies:           g.convertEnemies(),
+		Projectiles:       g.convertProjectiles(),
+		Walls:             g.convertWalls(),
+		Blueprints:        g.copyBlueprints(),
+		Wave:              g.state.Wave,
+		Gold:              g.state.Gold,
+		Lives:             g.state.Lives,
+		Score:             g.state.Score,
+		GameOver:          g.state.GameOver,
+		Rollbacks:         g.state.Rollbacks,
+		Outcome:           g.state.Outcome,
+		Errored:           g.errored,
+		ProjectedInterest: g.economySystem.ProjectedInterest(g.state.Gold),
+		RefundWindow:      g.refundWindow(g.world.Now()).Seconds(),
+		Path:              path,
+		Entrances:         entrances,
+		MapWidth:          g.config.Map.Width,
+		MapHeight:         g.config.Map.Height,
+
+		Version:  g.version,
+		Tick:     g.clock.Tick,
+		GameTime: g.clock.Time,
+		Mutators: g.mutators,
+		Tutorial: g.tutorialState(),
+		Code:     g.code,
+		Lobby:    g.lobbyState(g.world.Now()),
+		FogOfWar: g.config.Visibility.Enabled,
+
+		Removed: g.convertRemovals(),
+
+		Restricted: g.rules.Restricted(g.ruleContext()),
+		Wallets:    g.walletBalances(),
+		Processed:  g.processedCommands(),
+
+		Phase:       g.phase.name,
+		PhaseEndsIn: g.phaseEndsIn(g.world.Now()).Seconds(),
+
+		Zones:     zoneDTOs(g.config.Map.Zones),
+		Obstacles: obstacleDTOs(g.config.Map.Obstacles),
+		Weather:   g.weatherState(),
+
+		WaveProgress: WaveProgressDTO{
+			NextWaveIn:       g.waveSystem.NextWaveIn(g.world.Now()).Seconds(),
+			Pacing:           g.wavePacing(),
+			RemainingToSpawn: g.waveSystem.RemainingInWave(),
+			EnemiesAlive:     g.world.EnemyCount(),
+			Composition:      g.waveSystem.Composition(),
+
+			Modifier: g.waveModifierInfo(g.waveSystem.CurrentModifier()),
+			Next:     g.previewWave(g.waveSystem.GetCurrentWave() + 1),
+		},
 	}
 }
 
-// MarshalState returns the game state as JSON
-func (g *Game) MarshalState() ([]byte, error) {
-	state := g.GetState()
-	return json.Marshal(state)
-}
 
 // Reset resets the game to initial state
 func (g *Game) Reset() {
 	g.mu.Lock()
+	defer g.flushEvents()
 	defer g.mu.Unlock()
 	
 	// Clear world
@@ -358,7 +843,27 @@ func (g *Game) Reset() {
 	}
 	
 	// Reset wave system
-	g.waveSystem.Reset()
+	g.waveSystem.Reset(g.world.Now())
+	g.phase = gamePhase{name: config.PhaseBuilding}
+	g.terrainSystem.Reset()
+	g.rng.Reset()
+	g.markChanged()
+	g.summary = newSummary(g.id, g.mapID)
+	g.resetHeatmap()
+	g.removals.reset()
+	g.blueprints = nil
+	// Players pick up research bought since they joined
+	g.players = make(map[string]PlayerModifiers)
+	g.activity = nil
+	if g.wallets != nil {
+		g.enableWallets() // every player starts over as they join again
+	}
+	g.timeline = newTimeline()
+	g.unsavedCheckpoint = nil
+	g.replay = replayRecorder{}
+	g.emit(events.Event{Type: events.GameReset})
+	g.resetTutorial()
+	g.resetLobby()
 	
 	logging.Infow("game_reset", "game_id", g.id)
 }
@@ -370,11 +875,80 @@ func (g *Game) SetOnTick(f func(TickStats)) {
 	g.onTick = f
 }
 
+// Running reports whether the game loop is started
+func (g *Game) Running() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.running
+}
+
+// LastTick returns when the game loop last ran, or when it was started
+func (g *Game) LastTick() time.Time {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.lastTick
+}
+
+// Mutators returns the IDs of the mutators the game was created with
+func (g *Game) Mutators() []string {
+	return g.mutators
+}
+
+// TickRateMs returns the interval between the game's ticks, which rooms can
+// choose at creation
+func (g *Game) TickRateMs() int {
+	return g.config.Game.TickRateMs
+}
+
+// MapID returns the ID of the map the game is played on
+func (g *Game) MapID() string {
+	return g.mapID
+}
+
+// WebhookURL returns the room's own webhook URL, "" if it has none
+func (g *Game) WebhookURL() string {
+	return g.webhookURL
+}
+
+// announceCreated queues the game_created event; it reaches listeners with the
+// first events the game flushes, outside the manager's lock
+func (g *Game) announceCreated() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.emit(events.Event{Type: events.GameCreated})
+}
+
+// Seed returns the seed all of the game's randomness derives from; a game
+// created with it and given the same commands plays out the same way
+func (g *Game) Seed() int64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.rng.Seed()
+}
+
 // GetID returns the game ID
 func (g *Game) GetID() string {
 	return g.id
 }
 
+// TowerTypes returns a copy of the tower stats and costs in effect for this game
+func (g *Game) TowerTypes() map[string]config.TowerConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	towers := make(map[string]config.TowerConfig, len(g.config.Towers))
+	for name, tc := range g.config.Towers {
+		towers[name] = tc
+	}
+	return towers
+}
+
+// PlacementRules returns the tower placement constraints of this game
+func (g *Game) PlacementRules() config.PlacementConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.config.Placement
+}
+
 // SaveState saves the current game state and returns the serialized data
 func (g *Game) SaveState() ([]byte, error) {
 	return g.MarshalState()
@@ -392,6 +966,11 @@ func (g *Game) LoadFromState(data []byte) error {
 	
 	// Clear current world
 	g.world.Clear()
+	g.markChanged()
+	g.summary = newSummary(g.id, g.mapID)
+	g.resetHeatmap()
+	g.removals.reset()
+	g.timeline = newTimeline()
 	
 	// Restore basic state
 	g.state.Wave = snapshot.Wave
@@ -399,6 +978,9 @@ func (g *Game) LoadFromState(data []byte) error {
 	g.state.Lives = snapshot.Lives
 	g.state.Score = snapshot.Score
 	g.state.GameOver = snapshot.GameOver
+	g.state.Rollbacks = snapshot.Rollbacks
+	g.state.Outcome = snapshot.Outcome
+	g.endedAt = time.Now()
 	
 	// Restore towers
 	for _, towerDTO := range snapshot.Towers {
@@ -414,7 +996,21 @@ func (g *Game) LoadFromState(data []byte) error {
 			Damage:       towerDTO.Damage,
 			FireRate:     towerDTO.FireRate,
 			SplashRadius: towerDTO.SplashRadius,
+			OwnerID:      towerDTO.OwnerID,
 			LastShot:     time.Now(),
+			Stats: ecs.TowerStats{
+				Value:      towerDTO.Stats.Value,
+				Shots:      towerDTO.Stats.Shots,
+				Damage:     towerDTO.Stats.Damage,
+				Kills:      towerDTO.Stats.Kills,
+				GoldEarned: towerDTO.Stats.GoldEarned,
+			},
+		}
+		if towerDTO.AuraRadius > 0 {
+			// Aura bonuses are not part of the snapshot; they come from the config
+			if a := g.config.Towers[towerDTO.Type].Aura; a != nil {
+				tower.Aura = &ecs.Aura{Radius: a.Radius, Damage: a.Damage, FireRate: a.FireRate, Range: a.Range}
+			}
 		}
 		g.world.AddEntity(tower)
 	}
@@ -433,6 +1029,7 @@ func (g *Game) LoadFromState(data []byte) error {
 			MaxHP:     enemyDTO.MaxHP,
 			Speed:     enemyDTO.Speed,
 			PathIndex: enemyDTO.PathIndex,
+			PathID:    enemyDTO.PathID,
 		}
 		g.world.AddEntity(enemy)
 	}
@@ -451,12 +1048,48 @@ func (g *Game) LoadFromState(data []byte) error {
 			Speed:          projDTO.Speed,
 			Damage:         projDTO.Damage,
 			SplashRadius:   projDTO.SplashRadius,
+			Behavior:       projDTO.Behavior,
+		}
+		if projDTO.Origin != nil && projDTO.Impact != nil {
+			projCfg := g.config.Projectiles[projDTO.Type]
+			projectile.Origin = ecs.Position{X: projDTO.Origin.X, Y: projDTO.Origin.Y}
+			projectile.Impact = ecs.Position{X: projDTO.Impact.X, Y: projDTO.Impact.Y}
+			projectile.HitRadius = projCfg.HitRadius
+			projectile.MaxHits = projCfg.MaxHits
+			projectile.TTL = projCfg.Duration
+			projectile.Jumps = projCfg.Jumps
+			projectile.JumpRadius = projCfg.JumpRadius
+			projectile.Falloff = projCfg.Falloff
+		}
+		// A chain in the snapshot has already struck; only its display remains
+		for _, link := range projDTO.Chain {
+			projectile.Chain = append(projectile.Chain, ecs.Position{X: link.X, Y: link.Y})
+			projectile.Spent = true
 		}
 		g.world.AddEntity(projectile)
 	}
 	
+	// Restore walls
+	for _, wallDTO := range snapshot.Walls {
+		g.world.AddEntity(&ecs.WallEntity{
+			BaseEntity: ecs.BaseEntity{
+				ID:       wallDTO.ID,
+				Type:     ecs.EntityTypeWall,
+				Position: ecs.Position{X: wallDTO.Position.X, Y: wallDTO.Position.Y},
+				Alive:    true,
+			},
+			HP:      wallDTO.HP,
+			MaxHP:   wallDTO.MaxHP,
+			Radius:  wallDTO.Radius,
+			OwnerID: wallDTO.OwnerID,
+		})
+	}
+	
+	g.blueprints = append([]Blueprint(nil), snapshot.Blueprints...)
+	
 	// Update wave system
 	g.waveSystem.SetCurrentWave(snapshot.Wave)
+	g.resumePhase()
 	
 	logging.Infow("game_loaded", "game_id", g.id, "wave", snapshot.Wave, "gold", snapshot.Gold)
 	
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"math"
+
+	"tower-defense/internal/game/ecs"
+)
+
+// Heatmap counts where things happen on the map over one game, on a grid of
+// CellSize squares. Grids are indexed [row][column]; row 0 is the top of the map.
+type Heatmap struct {
+	CellSize float64 `json:"cellSize"`
+	Columns  int     `json:"columns"`
+	Rows     int     `json:"rows"`
+	Deaths   [][]int `json:"deaths"` // enemies killed in the cell
+	Leaks    [][]int `json:"leaks"`  // enemies that reached the exit, by where they left the map
+	Damage   [][]int `json:"damage"` // damage dealt to enemies in the cell, splash included
+}
+
+// newHeatmap creates an empty heatmap covering a width x height map
+func newHeatmap(width, height int, cellSize float64) *Heatmap {
+	h := &Heatmap{
+		CellSize: cellSize,
+		Columns:  max(int(math.Ceil(float64(width)/cellSize)), 1),
+		Rows:     max(int(math.Ceil(float64(height)/cellSize)), 1),
+	}
+	h.Deaths = h.grid()
+	h.Leaks = h.grid()
+	h.Damage = h.grid()
+	return h
+}
+
+func (h *Heatmap) grid() [][]int {
+	g := make([][]int, h.Rows)
+	for i := range g {
+		g[i] = make([]int, h.Columns)
+	}
+	return g
+}
+
+// add increments the cell of grid containing pos; positions off the map count toward the nearest edge cell
+func (h *Heatmap) add(grid [][]int, pos ecs.Position, amount int) {
+	col := min(max(int(pos.X/h.CellSize), 0), h.Columns-1)
+	row := min(max(int(pos.Y/h.CellSize), 0), h.Rows-1)
+	grid[row][col] += amount
+}
+
+// copy returns a deep copy safe to hand out of the game lock
+func (h *Heatmap) copy() *Heatmap {
+	c := *h
+	c.Deaths = copyGrid(h.Deaths)
+	c.Leaks = copyGrid(h.Leaks)
+	c.Damage = copyGrid(h.Damage)
+	return &c
+}
+
+func copyGrid(g [][]int) [][]int {
+	c := make([][]int, len(g))
+	for i, row := range g {
+		c[i] = append([]int(nil), row...)
+	}
+	return c
+}
+
+// resetHeatmap starts a new heatmap for the current map (caller must hold g.mu)
+func (g *Game) resetHeatmap() {
+	g.heatmap = newHeatmap(g.config.Map.Width, g.config.Map.Height, g.config.Analytics.HeatmapCellSize)
+}
+
+// Heatmap returns where enemies died, leaked and took damage in the game so far
+func (g *Game) Heatmap() *Heatmap {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.heatmap.copy()
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/plugins"
+)
+
+// AddPlugin adds a plugin to this game on top of the registered ones. It fails
+// with plugins.ErrDuplicate if the game already runs a plugin of that name.
+func (g *Game) AddPlugin(p plugins.Plugin) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.plugins.Add(p)
+}
+
+// Plugins returns the plugins this game runs, in run order
+func (g *Game) Plugins() []plugins.Plugin {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.plugins.Plugins()
+}
+
+// pluginContext describes the game to plugin hooks (caller must hold g.mu)
+func (g *Game) pluginContext() plugins.Context {
+	return plugins.Context{GameID: g.id, Wave: g.state.Wave, Fork: g.forked}
+}
+
+// runHooks hands an emitted event to the plugin hooks that watch its type
+// (caller must hold g.mu)
+func (g *Game) runHooks(ev events.Event) {
+	switch ev.Type {
+	case events.WaveStarted:
+		g.plugins.WaveStarted(g.pluginContext(), ev.Wave)
+	case events.EnemyKilled:
+		g.plugins.EnemyKilled(g.pluginContext(), ev)
+	case events.TowerPlaced:
+		g.plugins.TowerPlaced(g.pluginContext(), ev)
+	}
+}
+
+// modifyDamage lets plugins change the damage of a hit; the projectile system
+// calls it during Update, under g.mu
+func (g *Game) modifyDamage(proj *ecs.ProjectileEntity, enemy *ecs.EnemyEntity, damage int, splash, crit bool) int {
+	hit := plugins.Hit{
+		TowerID:   proj.SourceID,
+		EnemyID:   enemy.ID,
+		EnemyType: enemy.EnemyType,
+		Damage:    damage,
+		Crit:      crit,
+		Splash:    splash,
+	}
+	if entity, ok := g.world.GetEntity(proj.SourceID); ok {
+		if tower, ok := entity.(*ecs.TowerEntity); ok {
+			hit.TowerType = tower.TowerType
+		}
+	}
+	return g.plugins.ModifyDamage(g.pluginContext(), hit)
+}
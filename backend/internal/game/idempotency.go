@@ -0,0 +1,58 @@
+package game
+
+import (
+	"container/list"
+	"sync"
+)
+
+// idempotencyCacheSize is how many command keys a game remembers
+const idempotencyCacheSize = 512
+
+// idempotencyCache remembers the results of recently executed commands by key,
+// evicting the least recently used
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used
+}
+
+type idempotentResult struct {
+	key  string
+	done chan struct{} // closed once err is set
+	err  error
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// Idempotent runs fn once per key and returns its result. A repeated key gets the
+// result of the first call without running fn again, waiting for it if it is still
+// running; replayed reports that. An empty key always runs fn.
+func (g *Game) Idempotent(key string, fn func() error) (replayed bool, err error) {
+	if key == "" {
+		return false, fn()
+	}
+
+	c := g.idempotency
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		res := el.Value.(*idempotentResult)
+		c.mu.Unlock()
+		<-res.done
+		return true, res.err
+	}
+	res := &idempotentResult{key: key, done: make(chan struct{})}
+	c.entries[key] = c.order.PushFront(res)
+	if c.order.Len() > idempotencyCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*idempotentResult).key)
+	}
+	c.mu.Unlock()
+
+	defer close(res.done)
+	res.err = fn()
+	return false, res.err
+}
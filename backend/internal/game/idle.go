@@ -0,0 +1,109 @@
+package game
+
+import (
+	"time"
+
+	"tower-defense/internal/logging"
+)
+
+// ClientCounter reports how many clients are connected to a game
+type ClientCounter func(gameID string) int
+
+// IdlePolicy decides when a room nobody is connected to, or whose game is
+// over, is closed
+type IdlePolicy struct {
+	After    time.Duration // time without clients before a room is removed, 0 = never
+	Finished time.Duration // time a room is kept after its game ended, 0 = until idle
+}
+
+// SetClientCounter sets how the manager counts the clients of its games, for
+// idle detection and GetStats
+func (m *Manager) SetClientCounter(c ClientCounter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients = c
+}
+
+// SetIdlePolicy sets when rooms without clients are removed, see SweepIdle
+func (m *Manager) SetIdlePolicy(p IdlePolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.idle = p
+}
+
+// SweepIdle removes the games other than the default one that have had no
+// clients for the idle policy's After, or that ended its Finished ago, and
+// returns their IDs. A game counts as idle from the first sweep that finds it
+// without clients, so call it well within After.
+func (m *Manager) SweepIdle(now time.Time) []string {
+	m.mu.RLock()
+	counter, policy := m.clients, m.idle
+	m.mu.RUnlock()
+	if counter == nil {
+		policy.After = 0
+	}
+	if policy.After <= 0 && policy.Finished <= 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	if policy.After > 0 {
+		for _, id := range m.ListGames() {
+			if id != DefaultGameID {
+				counts[id] = counter(id)
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.idleSince {
+		if _, ok := m.games[id]; !ok {
+			delete(m.idleSince, id) // removed otherwise
+		}
+	}
+	var removed []string
+	for id, n := range counts {
+		game, ok := m.games[id]
+		if !ok || n > 0 {
+			delete(m.idleSince, id)
+			continue
+		}
+		since, ok := m.idleSince[id]
+		if !ok {
+			m.idleSince[id] = now
+			continue
+		}
+		if now.Sub(since) < policy.After {
+			continue
+		}
+		m.removeSwept(id, game)
+		removed = append(removed, id)
+		logging.Infow("game_idle_removed", "game_id", id, "idle_s", now.Sub(since).Seconds(), "remaining_games", len(m.games))
+	}
+	if policy.Finished > 0 {
+		for id, game := range m.games {
+			if id == DefaultGameID {
+				continue
+			}
+			ended, over := game.EndedAt()
+			if !over || now.Sub(ended) < policy.Finished {
+				continue
+			}
+			m.removeSwept(id, game)
+			removed = append(removed, id)
+			logging.Infow("game_finished_removed", "game_id", id, "ended_s", now.Sub(ended).Seconds(), "remaining_games", len(m.games))
+		}
+	}
+	return removed
+}
+
+// removeSwept stops and removes a game found by SweepIdle (caller must hold m.mu)
+func (m *Manager) removeSwept(id string, game *Game) {
+	game.Stop()
+	delete(m.games, id)
+	delete(m.idleSince, id)
+}
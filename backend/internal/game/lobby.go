@@ -0,0 +1,173 @@
+package game
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// Phases of a room's lobby
+const (
+	LobbyWaiting    = "waiting"     // players join and get ready; no waves start
+	LobbyStarting   = "starting"    // everyone is ready, the first wave comes after LobbyCountdown
+	LobbyInProgress = "in_progress" // the game runs as usual
+)
+
+// LobbyCountdown is how long a lobby stays starting once everyone is ready,
+// so a player can still take their ready back
+const LobbyCountdown = 3 * time.Second
+
+var (
+	ErrNoLobby     = errors.New("room has no lobby")
+	ErrLobbyClosed = errors.New("game already started")
+	ErrNotJoined   = errors.New("player has not joined the room")
+)
+
+// LobbyState is the lobby of a room, included in its snapshots
+type LobbyState struct {
+	Phase    string   `json:"phase"`              // one of the Lobby* phases
+	Players  []string `json:"players"`            // players who joined the room, sorted
+	Ready    []string `json:"ready"`              // players who are ready, sorted
+	StartsIn float64  `json:"startsIn,omitempty"` // seconds until the first wave while starting
+}
+
+// lobby holds back a room's waves until every player who joined is ready
+type lobby struct {
+	phase    string
+	ready    map[string]bool
+	startsAt time.Time
+}
+
+func newLobby() *lobby {
+	return &lobby{phase: LobbyWaiting, ready: make(map[string]bool)}
+}
+
+func (l *lobby) copy() *lobby {
+	c := *l
+	c.ready = maps.Clone(l.ready)
+	return &c
+}
+
+// openLobby holds the game's waves until its players are ready (caller must hold g.mu)
+func (g *Game) openLobby() {
+	g.lobby = newLobby()
+	g.waveSystem.SetHeld(true, g.world.Now())
+}
+
+// Lobby returns the room's lobby, or nil if it started without one
+func (g *Game) Lobby() *LobbyState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.lobbyState(g.world.Now())
+}
+
+// lobbyState returns the lobby or nil (caller must hold g.mu)
+func (g *Game) lobbyState(now time.Time) *LobbyState {
+	l := g.lobby
+	if l == nil {
+		return nil
+	}
+	s := &LobbyState{
+		Phase:   l.phase,
+		Players: slices.Sorted(maps.Keys(g.players)),
+		Ready:   []string{},
+	}
+	for _, id := range s.Players {
+		if l.ready[id] {
+			s.Ready = append(s.Ready, id)
+		}
+	}
+	if l.phase == LobbyStarting {
+		s.StartsIn = max(l.startsAt.Sub(now).Round(time.Millisecond).Seconds(), 0)
+	}
+	return s
+}
+
+// SetReady marks a player who joined the room as ready or not. Once every
+// player is ready the lobby starts counting down; anyone taking their ready
+// back before the first wave stops the countdown. It fails with ErrNoLobby,
+// ErrLobbyClosed once the game runs and ErrNotJoined for strangers.
+func (g *Game) SetReady(playerID string, ready bool) (*LobbyState, error) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	g.touch(playerID)
+	l := g.lobby
+	switch {
+	case l == nil:
+		return nil, ErrNoLobby
+	case l.phase == LobbyInProgress:
+		return nil, ErrLobbyClosed
+	}
+	if _, joined := g.players[playerID]; !joined || playerID == "" {
+		return nil, ErrNotJoined
+	}
+
+	now := g.world.Now()
+	if l.ready[playerID] != ready {
+		l.ready[playerID] = ready
+		detail := "ready"
+		if !ready {
+			detail = "not_ready"
+		}
+		g.emit(events.Event{Type: events.PlayerReady, PlayerID: playerID, Detail: detail})
+	}
+	g.checkLobby(now)
+	g.markChanged()
+	return g.lobbyState(now), nil
+}
+
+// checkLobby starts the countdown when every player is ready and stops it when
+// one isn't (caller must hold g.mu)
+func (g *Game) checkLobby(now time.Time) {
+	l := g.lobby
+	allReady := len(g.players) > 0
+	for id := range g.players {
+		allReady = allReady && l.ready[id]
+	}
+	switch {
+	case l.phase == LobbyWaiting && allReady:
+		l.startsAt = now.Add(LobbyCountdown)
+		g.setLobbyPhase(LobbyStarting)
+	case l.phase == LobbyStarting && !allReady:
+		l.startsAt = time.Time{}
+		g.setLobbyPhase(LobbyWaiting)
+	}
+}
+
+// updateLobby lets the first wave in once the countdown is over (caller must hold g.mu)
+func (g *Game) updateLobby(now time.Time) {
+	l := g.lobby
+	if l == nil || l.phase == LobbyInProgress {
+		return
+	}
+	// Players joining during the countdown aren't ready yet
+	g.checkLobby(now)
+	if l.phase == LobbyStarting && !now.Before(l.startsAt) {
+		g.setLobbyPhase(LobbyInProgress)
+		g.waveSystem.SetHeld(false, now)
+	}
+}
+
+// setLobbyPhase moves the lobby to a phase and announces it (caller must hold g.mu)
+func (g *Game) setLobbyPhase(phase string) {
+	g.lobby.phase = phase
+	g.emit(events.Event{Type: events.LobbyPhase, Detail: phase})
+	logging.Infow("lobby_phase", "game_id", g.id, "phase", phase, "players", len(g.players))
+}
+
+// resetLobby sends a room back to its lobby after a game reset, as its
+// players are gone (caller must hold g.mu)
+func (g *Game) resetLobby() {
+	if g.lobby == nil {
+		return
+	}
+	g.lobby = newLobby()
+	g.waveSystem.SetHeld(true, g.world.Now())
+	g.emit(events.Event{Type: events.LobbyPhase, Detail: LobbyWaiting})
+}
@@ -1,43 +1,351 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/plugins"
+	"tower-defense/internal/game/repository"
 	"tower-defense/internal/logging"
 	"github.com/google/uuid"
 )
 
+// DefaultGameID is the ID of the game served by the legacy single-room endpoints
+const DefaultGameID = "default"
+
+// Bounds of a room's tick rate. Update clamps steps to 50 ms, so slower ticks
+// would slow the simulation down.
+const (
+	MinTickRateMs = 10
+	MaxTickRateMs = 50
+)
+
+// ErrInvalidTickRate is returned for a room tick rate outside the bounds
+var ErrInvalidTickRate = errors.New("invalid tick rate")
+
+// GameOptions are the settings a room can be created with
+type GameOptions struct {
+	MapID      string   // see config.ListMaps; "" = classic
+	Mutators   []string // custom rules by ID, see config.Mutators
+	TickRateMs int      // simulation tick interval, 0 = game.tick_rate_ms
+
+	MaxRollbacks *int // waves the game may retry from checkpoints, -1 = unlimited; nil = game.max_rollbacks
+	VictoryWave  *int // wave whose clearing wins the game, 0 = endless; nil = game.victory_wave
+
+	WavePacing string // when the next wave starts, one of config.PacingModes; "" = as the map says
+
+	Seed *int64 // seed of the game's random streams, to reproduce a run; nil = random
+
+	WebhookURL string // also receives the game's webhooks, see the webhook package; "" = only the global URL
+
+	Private bool // leave the room out of the room list; players join with its code
+	Lobby   bool // hold the first wave until every player who joined is ready, see Game.SetReady
+	Wallets bool // give every player gold of their own instead of a shared pool, see Game.TransferGold
+
+	MaxPlayers int    // seats of the room, up to MaxRoomPlayers; 0 = no limit
+	Password   string // players joining need it, see Game.Admit; "" = none
+}
+
 // Manager manages multiple game instances (multi-room support)
 type Manager struct {
-	mu     sync.RWMutex
-	games  map[string]*Game
-	config *config.GameConfig
+	mu          sync.RWMutex
+	games       map[string]*Game
+	codes       map[string]string // game ID by room code
+	config      *config.GameConfig
+	listeners   []events.Listener
+	plugins     []plugins.Plugin // added with AddPlugin, on top of the registered ones
+	overload    OverloadPolicy
+	limits      EntityLimits
+	crashRepo   repository.Repository
+	summaryRepo repository.Repository
+
+	checkpointRepo repository.Repository
+	replayRepo     repository.Repository
+
+	modifierSource ModifierSource
+
+	memoryBudget  int64 // bytes all rooms may hold before new ones are refused, see SetMemoryBudget
+	memoryRefused atomic.Uint64
+	maxRooms      int                 // see SetMaxRooms
+	onRefused     func(reason string) // see SetOnRoomRefused
+
+	clients   ClientCounter
+	idle      IdlePolicy
+	idleSince map[string]time.Time // games seen without clients, see SweepIdle
+
+	// Balance tuned for new rooms and the audit trail of tuning, see TuneBalance
+	overrides  config.BalanceOverrides
+	balanceLog []BalanceChange
+	balanceSeq uint64
 }
 
 // NewManager creates a new game manager
 func NewManager(cfg *config.GameConfig) *Manager {
 	return &Manager{
-		games:  make(map[string]*Game),
-		config: cfg,
+		games:     make(map[string]*Game),
+		codes:     make(map[string]string),
+		config:    cfg,
+		idleSince: make(map[string]time.Time),
 	}
 }
 
 // CreateGame creates a new game instance with a unique ID
 func (m *Manager) CreateGame() (*Game, error) {
+	return m.CreateGameWithMutators(nil)
+}
+
+// CreateGameWithMutators creates a new game whose config has the given mutators
+// applied; it fails with config.ErrUnknownMutator for unsupported IDs
+func (m *Manager) CreateGameWithMutators(mutators []string) (*Game, error) {
+	return m.CreateGameWithOptions(GameOptions{Mutators: mutators})
+}
+
+// CreateGameWithOptions creates a new game with the settings chosen for its room.
+// It fails with config.ErrUnknownMutator for unsupported mutator IDs and with
+// ErrInvalidTickRate for a tick rate outside MinTickRateMs-MaxTickRateMs.
+func (m *Manager) CreateGameWithOptions(opts GameOptions) (*Game, error) {
+	if opts.TickRateMs != 0 && (opts.TickRateMs < MinTickRateMs || opts.TickRateMs > MaxTickRateMs) {
+		return nil, fmt.Errorf("%w: %d ms, must be between %d and %d", ErrInvalidTickRate, opts.TickRateMs, MinTickRateMs, MaxTickRateMs)
+	}
+	if opts.MaxRollbacks != nil && *opts.MaxRollbacks < -1 {
+		return nil, fmt.Errorf("%w: %d, must be -1 (unlimited) or more", ErrInvalidRollbackLimit, *opts.MaxRollbacks)
+	}
+	if opts.VictoryWave != nil && *opts.VictoryWave < 0 {
+		return nil, fmt.Errorf("%w: %d, must be 0 (endless) or more", ErrInvalidVictoryWave, *opts.VictoryWave)
+	}
+	if err := checkWavePacing(opts.WavePacing); err != nil {
+		return nil, err
+	}
+	if opts.MaxPlayers < 0 || opts.MaxPlayers > MaxRoomPlayers {
+		return nil, fmt.Errorf("%w: %d, must be between 0 (no limit) and %d", ErrInvalidMaxPlayers, opts.MaxPlayers, MaxRoomPlayers)
+	}
+	if opts.MapID == "" {
+		opts.MapID = "classic"
+	} else if _, err := config.GetMapConfig(opts.MapID); err != nil {
+		return nil, err
+	}
+	if err := m.checkMemoryBudget(); err != nil {
+		return nil, err
+	}
+	// Hash before taking the lock; bcrypt is slow on purpose
+	passwordHash, err := hashRoomPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
+	
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
+	if m.maxRooms > 0 && len(m.games) >= m.maxRooms {
+		if m.onRefused != nil {
+			m.onRefused(RefusedMaxRooms)
+		}
+		logging.Warnw("room_creation_refused", "reason", RefusedMaxRooms, "rooms", len(m.games), "max_rooms", m.maxRooms)
+		return nil, fmt.Errorf("%w: %d", ErrTooManyRooms, m.maxRooms)
+	}
+	cfg, applied, err := m.config.WithMutators(opts.Mutators)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TickRateMs != 0 {
+		cfg.Game.TickRateMs = opts.TickRateMs
+	}
+	if opts.MaxRollbacks != nil {
+		cfg.Game.MaxRollbacks = *opts.MaxRollbacks
+	}
+	if opts.VictoryWave != nil {
+		cfg.Game.VictoryWave = *opts.VictoryWave
+	}
+	
 	gameID := uuid.New().String()
-	game := NewGame(gameID, m.config)
+	game := NewGameWithMap(gameID, cfg, opts.MapID)
+	game.mutators = applied
+	game.setWavePacing(opts.WavePacing)
+	if opts.Seed != nil {
+		game.rng.Reseed(*opts.Seed)
+	}
+	game.webhookURL = opts.WebhookURL
+	game.private = opts.Private
+	game.maxPlayers = opts.MaxPlayers
+	game.passwordHash = passwordHash
+	if opts.Lobby {
+		game.openLobby()
+	}
+	if opts.Wallets {
+		game.enableWallets()
+	}
+	game.code = m.newRoomCode()
+	m.codes[game.code] = gameID
+	m.adopt(game)
+	game.announceCreated()
 	m.games[gameID] = game
 	
-	logging.Infow("game_created", "game_id", gameID, "total_games", len(m.games))
+	logging.Infow("game_created", "game_id", gameID, "map_id", opts.MapID, "mutators", applied, "tick_rate_ms", cfg.Game.TickRateMs, "max_rollbacks", cfg.Game.MaxRollbacks, "victory_wave", cfg.Game.VictoryWave, "wave_pacing", game.wavePacing(), "seed", game.rng.Seed(), "code", game.code, "private", opts.Private, "lobby", opts.Lobby, "wallets", opts.Wallets, "max_players", opts.MaxPlayers, "password", passwordHash != nil, "total_games", len(m.games))
 	
 	return game, nil
 }
 
+// AddEventListener registers a listener that is attached to every game created by the manager
+func (m *Manager) AddEventListener(l events.Listener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.listeners = append(m.listeners, l)
+	for _, game := range m.games {
+		game.AddEventListener(l)
+	}
+}
+
+// AddPlugin adds a plugin to every current and future game, on top of the
+// registered ones. It fails with plugins.ErrDuplicate if one has its name.
+func (m *Manager) AddPlugin(p plugins.Plugin) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := plugins.NewChain(append(plugins.Registered(), m.plugins...)).Add(p); err != nil {
+		return err
+	}
+	m.plugins = append(m.plugins, p)
+	for _, game := range m.games {
+		if err := game.AddPlugin(p); err != nil {
+			logging.Warnw("plugin_not_added", "game_id", game.id, "plugin", p.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+// SetOverloadPolicy sets the overload policy of every current and future game
+func (m *Manager) SetOverloadPolicy(p OverloadPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.overload = p
+	for _, game := range m.games {
+		game.SetOverloadPolicy(p)
+	}
+}
+
+// SetEntityLimits sets the entity limits of every current and future game
+func (m *Manager) SetEntityLimits(l EntityLimits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.limits = l
+	for _, game := range m.games {
+		game.SetEntityLimits(l)
+	}
+}
+
+// SetCrashRepository sets where every current and future game stores crash reports
+func (m *Manager) SetCrashRepository(repo repository.Repository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.crashRepo = repo
+	for _, game := range m.games {
+		game.SetCrashRepository(repo)
+	}
+}
+
+// SetSummaryRepository sets where every current and future game stores its end-of-game summaries
+func (m *Manager) SetSummaryRepository(repo repository.Repository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.summaryRepo = repo
+	for _, game := range m.games {
+		game.SetSummaryRepository(repo)
+	}
+}
+
+// SetCheckpointRepository sets where every current and future game stores its wave checkpoints
+func (m *Manager) SetCheckpointRepository(repo repository.Repository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.checkpointRepo = repo
+	for _, game := range m.games {
+		game.SetCheckpointRepository(repo)
+	}
+}
+
+// SetReplayRepository sets where every current and future game stores the replays of its finished runs
+func (m *Manager) SetReplayRepository(repo repository.Repository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.replayRepo = repo
+	for _, game := range m.games {
+		game.SetReplayRepository(repo)
+	}
+}
+
+// SetModifierSource sets where every current and future game looks up the modifiers of joining players
+func (m *Manager) SetModifierSource(src ModifierSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.modifierSource = src
+	for _, game := range m.games {
+		game.SetModifierSource(src)
+	}
+}
+
+// adopt wires manager-level listeners and settings into a game (caller must hold m.mu)
+func (m *Manager) adopt(game *Game) {
+	for _, l := range m.listeners {
+		game.AddEventListener(l)
+	}
+	for _, p := range m.plugins {
+		if err := game.AddPlugin(p); err != nil {
+			logging.Warnw("plugin_not_added", "game_id", game.id, "plugin", p.Name, "error", err)
+		}
+	}
+	game.SetOverloadPolicy(m.overload)
+	game.SetEntityLimits(m.limits)
+	game.SetCrashRepository(m.crashRepo)
+	game.SetSummaryRepository(m.summaryRepo)
+	game.SetCheckpointRepository(m.checkpointRepo)
+	game.SetReplayRepository(m.replayRepo)
+	game.SetModifierSource(m.modifierSource)
+}
+
+// Config returns the configuration used for new games
+func (m *Manager) Config() *config.GameConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
+}
+
+// ReloadConfig makes cfg the configuration for new games and hot-applies
+// the safe subset (costs, rewards, economy) to running games
+func (m *Manager) ReloadConfig(cfg *config.GameConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	// Keep the balance tuned for new rooms
+	if !m.overrides.Empty() {
+		if tuned, err := cfg.WithOverrides(m.overrides); err == nil {
+			cfg = tuned
+		} else {
+			logging.Warnw("balance_overrides_dropped", "error", err)
+			m.overrides = config.BalanceOverrides{}
+		}
+	}
+	m.config = cfg
+	for _, game := range m.games {
+		game.ApplyBalance(cfg)
+	}
+	
+	logging.Infow("config_reloaded", "game_count", len(m.games))
+}
+
 // GetGame retrieves a game by ID
 func (m *Manager) GetGame(gameID string) (*Game, error) {
 	m.mu.RLock()
@@ -53,7 +361,7 @@ func (m *Manager) GetGame(gameID string) (*Game, error) {
 
 // GetOrCreateDefault gets the default game or creates it if it doesn't exist
 func (m *Manager) GetOrCreateDefault() *Game {
-	defaultID := "default"
+	defaultID := DefaultGameID
 	
 	m.mu.RLock()
 	game, exists := m.games[defaultID]
@@ -73,6 +381,7 @@ func (m *Manager) GetOrCreateDefault() *Game {
 	}
 	
 	game = NewGame(defaultID, m.config)
+	m.adopt(game)
 	m.games[defaultID] = game
 	
 	logging.Infow("default_game_created", "game_id", defaultID)
@@ -85,13 +394,14 @@ func (m *Manager) ReplaceDefaultGame(newGame *Game) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	defaultID := "default"
+	defaultID := DefaultGameID
 	
 	// Stop old game if exists
 	if oldGame, exists := m.games[defaultID]; exists {
 		oldGame.Stop()
 	}
 	
+	m.adopt(newGame)
 	m.games[defaultID] = newGame
 	logging.Infow("default_game_replaced", "game_id", defaultID)
 }
@@ -110,6 +420,7 @@ func (m *Manager) RemoveGame(gameID string) error {
 	game.Stop()
 	
 	delete(m.games, gameID)
+	delete(m.codes, game.code)
 	
 	logging.Infow("game_removed", "game_id", gameID, "remaining_games", len(m.games))
 	
@@ -129,6 +440,25 @@ func (m *Manager) ListGames() []string {
 	return ids
 }
 
+// StalledGames returns the IDs of running games that have not ticked for longer than after
+func (m *Manager) StalledGames(after time.Duration) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var stalled []string
+	now := time.Now()
+	for id, game := range m.games {
+		if !game.Running() {
+			continue
+		}
+		if now.Sub(game.LastTick()) > after {
+			stalled = append(stalled, id)
+		}
+	}
+	sort.Strings(stalled)
+	return stalled
+}
+
 // GetGameCount returns the number of active games
 func (m *Manager) GetGameCount() int {
 	m.mu.RLock()
@@ -162,6 +492,9 @@ func (m *Manager) GetStats() ManagerStats {
 	}
 	
 	for id, game := range m.games {
+		if game.Private() {
+			continue
+		}
 		state := game.GetState()
 		stats.Games = append(stats.Games, GameStats{
 			ID:       id,
@@ -169,7 +502,20 @@ func (m *Manager) GetStats() ManagerStats {
 			Lives:    state.Lives,
 			Score:    state.Score,
 			GameOver: state.GameOver,
+			Outcome:  state.Outcome,
+
+			TickRateMs: game.TickRateMs(),
+			Overloaded: game.Overloaded(),
+			Saturated:  game.Saturated(),
+			Code:       game.Code(),
+			Lobby:      lobbyPhase(state.Lobby),
+			Players:    game.Players(),
+			MaxPlayers: game.MaxPlayers(),
+			Locked:     game.HasPassword(),
 		})
+		if m.clients != nil {
+			stats.Games[len(stats.Games)-1].Clients = m.clients(id)
+		}
 	}
 	
 	return stats
@@ -177,7 +523,7 @@ func (m *Manager) GetStats() ManagerStats {
 
 // ManagerStats contains statistics about the game manager
 type ManagerStats struct {
-	TotalGames int         `json:"total_games"`
+	TotalGames int         `json:"total_games"` // private rooms included, though they aren't listed
 	Games      []GameStats `json:"games"`
 }
 
@@ -188,6 +534,26 @@ type GameStats struct {
 	Lives    int    `json:"lives"`
 	Score    int    `json:"score"`
 	GameOver bool   `json:"game_over"`
+	Outcome  string `json:"outcome,omitempty"` // "won" or "lost" once the game is over
+
+	TickRateMs int  `json:"tick_rate_ms"`
+	Overloaded bool `json:"overloaded,omitempty"` // ticks run over budget; state is broadcast less often
+	Saturated  bool `json:"saturated,omitempty"`  // at an entity limit; spawns wait or shots land at once
+	Clients    int  `json:"clients"`              // WebSocket clients connected to this instance
+
+	Code  string `json:"code,omitempty"`  // room code to join with
+	Lobby string `json:"lobby,omitempty"` // lobby phase of rooms created with a lobby
+
+	Players    int  `json:"players"`               // players who joined since the last reset
+	MaxPlayers int  `json:"max_players,omitempty"` // seats, 0 = no limit
+	Locked     bool `json:"locked,omitempty"`      // joining needs the room's password
+}
+
+func lobbyPhase(l *LobbyState) string {
+	if l == nil {
+		return ""
+	}
+	return l.Phase
 }
 
 // ValidateGameID checks if a game ID is valid
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"unsafe"
+
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// ErrMemoryBudget is returned for a room that would be created while the rooms
+// already hold more memory than the budget, see Manager.SetMemoryBudget
+var ErrMemoryBudget = errors.New("rooms use up the server's memory budget")
+
+// Bookkeeping the world keeps per entity on top of the entity itself: its
+// entries in the ID map and the ordered list, and the interface header
+const entityOverhead = 96
+
+// Approximate in-memory size of the values a game accumulates
+var (
+	towerSize      = int64(unsafe.Sizeof(ecs.TowerEntity{})) + entityOverhead
+	enemySize      = int64(unsafe.Sizeof(ecs.EnemyEntity{})) + entityOverhead
+	projectileSize = int64(unsafe.Sizeof(ecs.ProjectileEntity{})) + entityOverhead
+	wallSize       = int64(unsafe.Sizeof(ecs.WallEntity{})) + entityOverhead
+	eventSize      = int64(unsafe.Sizeof(events.Event{}))
+	removalSize    = int64(unsafe.Sizeof(RemovalDTO{})) + 64 // and its entry in the reasons map
+	keyframeSize   = int64(unsafe.Sizeof(ReplayKeyframe{}))
+)
+
+// MemoryUsage is the approximate memory a game holds, in bytes. It counts what
+// grows with play: entities, queued events, the cached snapshot and the
+// replay being recorded; config and systems are shared or fixed and left out.
+type MemoryUsage struct {
+	GameID      string `json:"gameId"`
+	Towers      int    `json:"towers"`
+	Enemies     int    `json:"enemies"`
+	Projectiles int    `json:"projectiles"`
+	Walls       int    `json:"walls"`
+
+	EntityBytes   int64 `json:"entityBytes"`
+	EventBytes    int64 `json:"eventBytes"`    // events not yet delivered and the removal log
+	SnapshotBytes int64 `json:"snapshotBytes"` // the last encoded state, see MarshalState
+	ReplayBytes   int64 `json:"replayBytes"`   // keyframes of the run in progress
+	OtherBytes    int64 `json:"otherBytes"`    // heatmap and the checkpoint waiting to be stored
+	TotalBytes    int64 `json:"totalBytes"`
+}
+
+// MemoryUsage estimates the memory the game holds
+func (g *Game) MemoryUsage() MemoryUsage {
+	g.mu.RLock()
+	u := MemoryUsage{GameID: g.id}
+	u.Towers = g.world.TowerCount()
+	u.EntityBytes += int64(u.Towers) * towerSize
+	for _, e := range g.world.GetEnemies() {
+		u.Enemies++
+		u.EntityBytes += enemySize + int64(cap(e.Affixes)+cap(e.VisibleTo))*16
+	}
+	for _, p := range g.world.GetProjectiles() {
+		u.Projectiles++
+		u.EntityBytes += projectileSize + int64(cap(p.Pierced))*16 + int64(cap(p.Chain))*int64(unsafe.Sizeof(ecs.Position{}))
+	}
+	u.Walls = g.world.WallCount()
+	u.EntityBytes += int64(u.Walls) * wallSize
+
+	u.EventBytes = int64(cap(g.pendingEvents))*eventSize + int64(len(g.removals.removals))*removalSize
+	for _, k := range g.replay.keyframes {
+		u.ReplayBytes += keyframeSize + int64(cap(k.Steps))*8 + simulationSaveBytes(&k.Save)
+	}
+	if h := g.heatmap; h != nil {
+		u.OtherBytes += int64(h.Columns*h.Rows) * 3 * 8
+	}
+	u.OtherBytes += int64(cap(g.unsavedCheckpoint))
+	g.mu.RUnlock()
+
+	// The cache has its own lock, taken by MarshalState before g.mu
+	g.encoded.mu.Lock()
+	u.SnapshotBytes = int64(cap(g.encoded.data))
+	g.encoded.mu.Unlock()
+
+	u.TotalBytes = u.EntityBytes + u.EventBytes + u.SnapshotBytes + u.ReplayBytes + u.OtherBytes
+	return u
+}
+
+// simulationSaveBytes estimates the memory of a decoded save
+func simulationSaveBytes(s *SimulationSave) int64 {
+	return int64(unsafe.Sizeof(*s)) +
+		int64(cap(s.Towers))*int64(unsafe.Sizeof(ecs.TowerRecord{})) +
+		int64(cap(s.Enemies))*int64(unsafe.Sizeof(ecs.EnemyRecord{})) +
+		int64(cap(s.Projectiles))*int64(unsafe.Sizeof(ecs.ProjectileRecord{})) +
+		int64(cap(s.Walls))*int64(unsafe.Sizeof(ecs.WallRecord{})) +
+		int64(cap(s.Blueprints))*int64(unsafe.Sizeof(Blueprint{}))
+}
+
+// MemoryReport is the approximate memory of all rooms of the manager
+type MemoryReport struct {
+	Rooms       []MemoryUsage `json:"rooms"` // largest first
+	TotalBytes  int64         `json:"totalBytes"`
+	BudgetBytes int64         `json:"budgetBytes,omitempty"` // new rooms are refused above it, 0 = no budget
+	Refused     uint64        `json:"refused"`               // rooms refused for the budget since the start
+}
+
+// SetMemoryBudget sets how much memory, as estimated by MemoryUsage, all rooms
+// together may hold before new rooms are refused with ErrMemoryBudget; 0 = no budget
+func (m *Manager) SetMemoryBudget(bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.memoryBudget = bytes
+}
+
+// MemoryUsage estimates the memory held by every room, private ones included
+func (m *Manager) MemoryUsage() MemoryReport {
+	m.mu.RLock()
+	games := make([]*Game, 0, len(m.games))
+	for _, game := range m.games {
+		games = append(games, game)
+	}
+	report := MemoryReport{BudgetBytes: m.memoryBudget, Refused: m.memoryRefused.Load()}
+	m.mu.RUnlock()
+
+	report.Rooms = make([]MemoryUsage, 0, len(games))
+	for _, game := range games {
+		u := game.MemoryUsage()
+		report.Rooms = append(report.Rooms, u)
+		report.TotalBytes += u.TotalBytes
+	}
+	slices.SortFunc(report.Rooms, func(a, b MemoryUsage) int {
+		return cmp.Or(cmp.Compare(b.TotalBytes, a.TotalBytes), cmp.Compare(a.GameID, b.GameID))
+	})
+	return report
+}
+
+// checkMemoryBudget fails with ErrMemoryBudget while the rooms hold more than
+// the budget (caller must not hold m.mu)
+func (m *Manager) checkMemoryBudget() error {
+	m.mu.RLock()
+	budget, onRefused := m.memoryBudget, m.onRefused
+	m.mu.RUnlock()
+	if budget <= 0 {
+		return nil
+	}
+	report := m.MemoryUsage()
+	if report.TotalBytes <= budget {
+		return nil
+	}
+	m.memoryRefused.Add(1)
+	if onRefused != nil {
+		onRefused(RefusedMemoryBudget)
+	}
+	logging.Warnw("room_creation_refused", "reason", RefusedMemoryBudget, "rooms", len(report.Rooms),
+		"total_bytes", report.TotalBytes, "budget_bytes", budget)
+	return fmt.Errorf("%w: %d of %d bytes in use", ErrMemoryBudget, report.TotalBytes, budget)
+}
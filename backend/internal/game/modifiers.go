@@ -0,0 +1,80 @@
+package game
+
+import (
+	"tower-defense/internal/logging"
+)
+
+// PlayerModifiers are the persistent bonuses a player brings into a game, e.g. from research
+type PlayerModifiers struct {
+	LockedTowers map[string]bool `json:"lockedTowers,omitempty"` // tower types the player may not build
+	DamageBonus  float64         `json:"damageBonus,omitempty"`  // fraction added to the damage of the player's towers
+}
+
+// ModifierSource looks up the modifiers of a player when they join a game
+type ModifierSource interface {
+	PlayerModifiers(playerID string) (PlayerModifiers, error)
+}
+
+// SetModifierSource sets where the modifiers of joining players come from; nil gives everyone none
+func (g *Game) SetModifierSource(src ModifierSource) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.modifierSource = src
+}
+
+// Join looks up a player's modifiers and applies them to this game until it is reset.
+// Joining again returns the modifiers fixed at the first join; an empty player ID has none.
+// Join lets the player in regardless of the room's password and player limit; players
+// coming through the front door use Admit.
+func (g *Game) Join(playerID string) PlayerModifiers {
+	mods, _ := g.join(playerID, false)
+	return mods
+}
+
+// join adds a player to the game; with limited, it fails with ErrRoomFull
+// when the room's player limit is reached
+func (g *Game) join(playerID string, limited bool) (PlayerModifiers, error) {
+	if playerID == "" {
+		return PlayerModifiers{}, nil
+	}
+
+	g.mu.RLock()
+	mods, joined := g.players[playerID]
+	src := g.modifierSource
+	full := limited && g.full()
+	g.mu.RUnlock()
+	if joined {
+		return mods, nil
+	}
+	if full {
+		return PlayerModifiers{}, ErrRoomFull
+	}
+
+	// Look the player up outside the lock; the source may hit storage
+	if src != nil {
+		var err error
+		if mods, err = src.PlayerModifiers(playerID); err != nil {
+			logging.Errorw("player_modifiers_failed", "game_id", g.id, "player_id", playerID, "error", err)
+			mods = PlayerModifiers{}
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.players[playerID]; ok {
+		return existing, nil
+	}
+	// Someone else may have taken the last seat during the lookup
+	if limited && g.full() {
+		return PlayerModifiers{}, ErrRoomFull
+	}
+	g.players[playerID] = mods
+	g.openWallet(playerID)
+	g.touch(playerID)
+	if g.lobby != nil {
+		g.markChanged() // the lobby lists the new player
+	}
+	logging.Infow("player_joined", "game_id", g.id, "player_id", playerID,
+		"locked_towers", len(mods.LockedTowers), "damage_bonus", mods.DamageBonus)
+	return mods, nil
+}
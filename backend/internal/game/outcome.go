@@ -0,0 +1,54 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// Outcomes of a finished game
+const (
+	OutcomeWon  = "won"  // the game.victory_wave wave was cleared
+	OutcomeLost = "lost" // the last life was lost, or the game was ended early
+)
+
+var (
+	ErrGameFinished       = errors.New("game is over") // for commands sent to a finished game
+	ErrInvalidVictoryWave = errors.New("invalid victory wave")
+)
+
+// finish ends the game with outcome: the simulation stops, commands are
+// rejected and the final game over event is emitted, which also completes the
+// summary. reason says why a game was ended early, e.g. "admin". Returns false
+// if the game was already over (caller must hold g.mu).
+func (g *Game) finish(outcome, reason string) bool {
+	if g.state.GameOver {
+		return false
+	}
+	g.state.GameOver = true
+	g.state.Outcome = outcome
+	g.endedAt = time.Now()
+	g.emit(events.Event{Type: events.GameOver, Wave: g.state.Wave, Score: g.state.Score, Detail: reason, Outcome: outcome})
+	g.markChanged()
+	g.takeReplay()
+
+	logging.Infow("game_finished", "game_id", g.id, "outcome", outcome, "reason", reason, "wave", g.state.Wave, "score", g.state.Score)
+	return true
+}
+
+// checkVictory wins the game once its last wave is cleared (caller must hold g.mu)
+func (g *Game) checkVictory(clearedWave int) {
+	if last := g.config.Game.VictoryWave; last > 0 && clearedWave >= last && g.state.Lives > 0 {
+		g.finish(OutcomeWon, "")
+	}
+}
+
+// EndedAt returns when the game ended, and false while it is still going. A
+// finished game restored from a save counts as ended when it was loaded.
+func (g *Game) EndedAt() (time.Time, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.endedAt, g.state.GameOver
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"time"
+
+	"tower-defense/internal/logging"
+)
+
+// defaultOverloadTicks is how many consecutive ticks must miss (or meet) the
+// budget before a game enters (or leaves) the overloaded state
+const defaultOverloadTicks = 10
+
+// OverloadPolicy decides when a game counts as overloaded and what it gives up then
+type OverloadPolicy struct {
+	Budget   time.Duration // time one tick may take, 0 = the configured tick interval
+	Ticks    int           // consecutive ticks over (or under) budget to enter (or leave) overload, 0 = 10
+	EnemyCap int           // while overloaded, hold wave spawns once this many enemies are alive, 0 = off
+}
+
+// overloadGuard tracks tick durations against the budget (guarded by Game.mu)
+type overloadGuard struct {
+	policy     OverloadPolicy
+	over       int // consecutive ticks over budget
+	under      int // consecutive ticks within budget while overloaded
+	overloaded bool
+	since      time.Time
+}
+
+// SetOverloadPolicy replaces the game's overload policy
+func (g *Game) SetOverloadPolicy(p OverloadPolicy) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.overload.policy = p
+	if g.overload.overloaded {
+		g.waveSystem.SetSpawnCap(p.EnemyCap)
+	}
+}
+
+// Overloaded reports whether the game's ticks are currently running over budget
+func (g *Game) Overloaded() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.overload.overloaded
+}
+
+// tickBudget returns the time one tick may take (caller must hold g.mu)
+func (g *Game) tickBudget() time.Duration {
+	if g.overload.policy.Budget > 0 {
+		return g.overload.policy.Budget
+	}
+	return time.Duration(g.config.Game.TickRateMs) * time.Millisecond
+}
+
+// observeTick feeds one tick duration to the overload guard, switching the game
+// in or out of overload after enough consecutive ticks (caller must hold g.mu)
+func (g *Game) observeTick(now time.Time, took time.Duration) {
+	o := &g.overload
+	budget := g.tickBudget()
+	ticks := o.policy.Ticks
+	if ticks <= 0 {
+		ticks = defaultOverloadTicks
+	}
+
+	if took > budget {
+		o.over++
+		o.under = 0
+	} else {
+		o.over = 0
+		o.under++
+	}
+
+	switch {
+	case !o.overloaded && o.over >= ticks:
+		o.overloaded = true
+		o.since = now
+		g.waveSystem.SetSpawnCap(o.policy.EnemyCap)
+		logging.Warnw("tick_overload",
+			"game_id", g.id,
+			"tick_ms", took.Seconds()*1000,
+			"budget_ms", budget.Seconds()*1000,
+			"consecutive_ticks", o.over,
+			"enemies", g.world.EnemyCount(),
+			"projectiles", len(g.world.GetProjectiles()),
+			"enemy_cap", o.policy.EnemyCap,
+		)
+	case o.overloaded && o.under >= ticks:
+		o.overloaded = false
+		g.waveSystem.SetSpawnCap(0)
+		logging.Infow("tick_overload_cleared", "game_id", g.id, "duration_ms", now.Sub(o.since).Milliseconds())
+	}
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"tower-defense/internal/game/config"
+)
+
+// ErrInvalidWavePacing is returned for a room's wave pacing that is not one of config.PacingModes
+var ErrInvalidWavePacing = errors.New("invalid wave pacing")
+
+// checkWavePacing fails with ErrInvalidWavePacing for an unknown mode; "" keeps the map's
+func checkWavePacing(mode string) error {
+	if mode != "" && !slices.Contains(config.PacingModes, mode) {
+		return fmt.Errorf("%w %q, supported: %s", ErrInvalidWavePacing, mode, strings.Join(config.PacingModes, ", "))
+	}
+	return nil
+}
+
+// setWavePacing makes the room pace its waves by mode instead of as its map
+// does; "" changes nothing (caller must hold g.mu or own the game)
+func (g *Game) setWavePacing(mode string) {
+	if mode == "" {
+		return
+	}
+	p := g.waveSystem.Pacing()
+	p.Mode = mode
+	g.waveSystem.SetPacing(p)
+}
+
+// WavePacing returns the mode that decides when the game's next wave starts
+func (g *Game) WavePacing() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.wavePacing()
+}
+
+// wavePacing returns the game's pacing mode (caller must hold g.mu)
+func (g *Game) wavePacing() string {
+	if mode := g.waveSystem.Pacing().Mode; mode != "" {
+		return mode
+	}
+	return config.PacingTimer
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// gamePhase is where a game is in its cycle of waves, see config.Phases
+type gamePhase struct {
+	name string
+	wave int // the wave current when WaveActive began, or the one cleared before Intermission
+}
+
+// Phase returns the game's phase, one of config.Phases
+func (g *Game) Phase() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.phase.name
+}
+
+// updatePhase moves the game on as waves start and are cleared: Building
+// until the first wave, WaveActive until the field is clear, Intermission
+// until the next wave. A wave that is cleared and followed by the next within
+// one tick still passes through Intermission (caller must hold g.mu).
+func (g *Game) updatePhase() {
+	for {
+		switch g.phase.name {
+		case config.PhaseBuilding, config.PhaseIntermission:
+			if g.state.Wave <= g.phase.wave {
+				return
+			}
+			g.setPhase(gamePhase{name: config.PhaseWaveActive, wave: g.state.Wave})
+		case config.PhaseWaveActive:
+			cleared := g.waveSystem.LastCompletedWave()
+			if cleared < g.phase.wave {
+				return
+			}
+			g.setPhase(gamePhase{name: config.PhaseIntermission, wave: cleared})
+		default:
+			return
+		}
+	}
+}
+
+// setPhase moves the game to a phase and announces it (caller must hold g.mu)
+func (g *Game) setPhase(p gamePhase) {
+	g.phase = p
+	g.emit(events.Event{Type: events.PhaseChanged, Wave: g.state.Wave, Detail: p.name})
+	logging.Debugw("game_phase", "game_id", g.id, "phase", p.name, "wave", g.state.Wave)
+}
+
+// resumePhase puts the game in the phase its restored waves are in, without
+// announcing it (caller must hold g.mu)
+func (g *Game) resumePhase() {
+	wave := g.waveSystem.GetCurrentWave()
+	switch {
+	case wave == 0:
+		g.phase = gamePhase{name: config.PhaseBuilding}
+	case g.waveSystem.Cleared():
+		g.phase = gamePhase{name: config.PhaseIntermission, wave: wave}
+	default:
+		g.phase = gamePhase{name: config.PhaseWaveActive, wave: wave}
+	}
+}
+
+// phaseEndsIn returns how long the building time or intermission has left,
+// 0 while a wave is active or the game is over (caller must hold g.mu)
+func (g *Game) phaseEndsIn(now time.Time) time.Duration {
+	if g.phase.name == config.PhaseWaveActive || g.state.GameOver {
+		return 0
+	}
+	return g.waveSystem.NextWaveIn(now)
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"slices"
+	"testing"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/events"
+)
+
+// phaseGame is a stepped game whose phase changes are recorded
+type phaseGame struct {
+	*Game
+	changes []events.Event
+}
+
+func newPhaseGame(t *testing.T, pacing config.WavePacing) *phaseGame {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Waves.Pacing = pacing
+	cfg.Map.WavePacing = nil
+	g := &phaseGame{Game: NewGame("phase", cfg)}
+	g.StopClock()
+	g.AddEventListener(func(ev events.Event) {
+		if ev.Type == events.PhaseChanged {
+			g.changes = append(g.changes, ev)
+		}
+	})
+	return g
+}
+
+// stepUntil runs single ticks, removing every enemy that spawns so waves
+// clear, until cond holds or a minute of game time has passed
+func (g *phaseGame) stepUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for range int(60 / maxTickDt) {
+		if cond() {
+			return
+		}
+		g.Step(maxTickDt)
+		g.mu.Lock()
+		for _, e := range g.world.GetEnemies() {
+			g.world.RemoveEntity(e.ID)
+		}
+		g.mu.Unlock()
+	}
+	t.Fatalf("timed out waiting for %s in phase %s", what, g.Phase())
+}
+
+func (g *phaseGame) phases() []string {
+	var phases []string
+	for _, ev := range g.changes {
+		phases = append(phases, ev.Detail)
+	}
+	return phases
+}
+
+func TestPhaseCycle(t *testing.T) {
+	g := newPhaseGame(t, config.WavePacing{Mode: config.PacingTimer, IntervalMs: 1000, IntermissionMs: 30000})
+	if p := g.Phase(); p != config.PhaseBuilding {
+		t.Fatalf("new game in phase %s, want building", p)
+	}
+
+	g.stepUntil(t, "wave 1", func() bool { return g.GetState().Wave == 1 })
+	if p := g.Phase(); p != config.PhaseWaveActive {
+		t.Fatalf("phase after wave 1 started = %s, want wave_active", p)
+	}
+	g.stepUntil(t, "wave 1 to clear", func() bool { return g.Phase() == config.PhaseIntermission })
+
+	// the intermission holds wave 2 past the interval
+	g.Step(2)
+	if p, wave := g.Phase(), g.GetState().Wave; p != config.PhaseIntermission || wave != 1 {
+		t.Errorf("2s into the intermission: phase %s wave %d, want intermission wave 1", p, wave)
+	}
+	want := []string{config.PhaseWaveActive, config.PhaseIntermission}
+	if got := g.phases(); !slices.Equal(got, want) {
+		t.Errorf("phase changes = %v, want %v", got, want)
+	}
+}
+
+func TestPhaseClearAndNextWaveInOneTick(t *testing.T) {
+	g := newPhaseGame(t, config.WavePacing{Mode: config.PacingAfterClear, IntervalMs: 1000})
+	g.stepUntil(t, "wave 1", func() bool { return g.GetState().Wave == 1 })
+	g.stepUntil(t, "wave 2", func() bool {
+		if p := g.Phase(); p != config.PhaseWaveActive {
+			t.Fatalf("phase %s seen between ticks, want wave_active throughout", p)
+		}
+		return g.GetState().Wave == 2
+	})
+
+	want := []string{config.PhaseWaveActive, config.PhaseIntermission, config.PhaseWaveActive}
+	if got := g.phases(); !slices.Equal(got, want) {
+		t.Fatalf("phase changes = %v, want %v", got, want)
+	}
+	if cleared, next := g.changes[1], g.changes[2]; cleared.Tick != next.Tick || cleared.Wave != next.Wave {
+		t.Errorf("intermission at tick %d wave %d, wave_active at tick %d wave %d, want the same tick and wave",
+			cleared.Tick, cleared.Wave, next.Tick, next.Wave)
+	}
+}
+
+func TestResumePhaseAfterLoad(t *testing.T) {
+	pacing := config.WavePacing{Mode: config.PacingTimer, IntervalMs: 1000, IntermissionMs: 30000}
+	g := newPhaseGame(t, pacing)
+	saves := make(map[string][]byte)
+	save := func() {
+		data, err := g.SaveSimulation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		saves[g.Phase()] = data
+	}
+	save()
+	g.stepUntil(t, "wave 1", func() bool { return g.GetState().Wave == 1 })
+	save()
+	g.stepUntil(t, "wave 1 to clear", func() bool { return g.Phase() == config.PhaseIntermission })
+	save()
+
+	for _, phase := range config.Phases {
+		loaded := newPhaseGame(t, pacing)
+		loaded.stepUntil(t, "wave 1", func() bool { return loaded.GetState().Wave == 1 })
+		if err := loaded.LoadSimulation(saves[phase]); err != nil {
+			t.Fatalf("load %s save: %v", phase, err)
+		}
+		if p := loaded.Phase(); p != phase {
+			t.Errorf("game loaded from a %s save is in phase %s", phase, p)
+		}
+		loaded.mu.Lock()
+		for _, ev := range loaded.pendingEvents {
+			if ev.Type == events.PhaseChanged {
+				t.Errorf("load of a %s save announced %s", phase, ev.Detail)
+			}
+		}
+		loaded.mu.Unlock()
+	}
+}
@@ -0,0 +1,214 @@
+// Package plugins lets optional subsystems hook into the simulation without
+// editing the core systems. A plugin registers its hooks once, usually from an
+// init function of a package the server imports for its side effects, and
+// every game created afterwards runs them.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// MaxErrors is how many failed hook calls disable a plugin for one game
+const MaxErrors = 10
+
+var (
+	ErrUnnamed   = errors.New("plugin needs a name")
+	ErrDuplicate = errors.New("plugin already registered")
+)
+
+// Context describes the game a hook runs for
+type Context struct {
+	GameID string
+	Wave   int
+	Fork   bool // a copy of a game, e.g. a wave dry run or a replay; skip effects outside the game
+}
+
+// Hit is a projectile hit about to be applied to an enemy
+type Hit struct {
+	TowerID   string
+	TowerType string
+	EnemyID   string
+	EnemyType string
+	Damage    int
+	Crit      bool
+	Splash    bool
+}
+
+// Plugin is a set of hooks; any of them may be nil. Hooks run synchronously
+// inside the tick, under the game lock, so they see the simulation as it is
+// and must not call back into the game. A hook that returns an error or
+// panics is logged and skipped; after MaxErrors failures the plugin is
+// disabled for that game.
+type Plugin struct {
+	Name  string
+	Order int // lower runs first; plugins with the same order run in registration order
+
+	OnWaveStart   func(ctx Context, wave int) error
+	OnEnemyKilled func(ctx Context, ev events.Event) error
+	OnTowerPlaced func(ctx Context, ev events.Event) error
+
+	// ModifyDamage returns the damage a hit deals instead of hit.Damage; plugins
+	// see the damage as changed by the ones before them
+	ModifyDamage func(ctx Context, hit Hit) (int, error)
+}
+
+var (
+	mu       sync.Mutex
+	registry []Plugin
+)
+
+// Register adds a plugin to every game created from now on. It panics on a
+// missing or duplicate name, as registration happens at startup.
+func Register(p Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := check(registry, p); err != nil {
+		panic(err)
+	}
+	registry = append(registry, p)
+	logging.Infow("plugin_registered", "plugin", p.Name, "order", p.Order)
+}
+
+// Registered returns the registered plugins in run order
+func Registered() []Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	return sorted(registry)
+}
+
+func check(ps []Plugin, p Plugin) error {
+	if p.Name == "" {
+		return ErrUnnamed
+	}
+	if slices.ContainsFunc(ps, func(q Plugin) bool { return q.Name == p.Name }) {
+		return fmt.Errorf("%w: %s", ErrDuplicate, p.Name)
+	}
+	return nil
+}
+
+func sorted(ps []Plugin) []Plugin {
+	ps = slices.Clone(ps)
+	slices.SortStableFunc(ps, func(a, b Plugin) int { return a.Order - b.Order })
+	return ps
+}
+
+// Chain runs the plugins of one game in order, isolating their failures. Like
+// the game state it is not safe for concurrent use; games call it under their lock.
+type Chain struct {
+	entries []*entry
+}
+
+type entry struct {
+	Plugin
+	errors   int
+	disabled bool
+}
+
+// NewChain creates a chain running ps in their order
+func NewChain(ps []Plugin) *Chain {
+	c := &Chain{}
+	for _, p := range sorted(ps) {
+		c.entries = append(c.entries, &entry{Plugin: p})
+	}
+	return c
+}
+
+// Add inserts a plugin at its place in the run order
+func (c *Chain) Add(p Plugin) error {
+	if err := check(c.Plugins(), p); err != nil {
+		return err
+	}
+	c.entries = append(c.entries, &entry{Plugin: p})
+	slices.SortStableFunc(c.entries, func(a, b *entry) int { return a.Order - b.Order })
+	return nil
+}
+
+// Plugins returns the plugins of the chain in run order
+func (c *Chain) Plugins() []Plugin {
+	ps := make([]Plugin, len(c.entries))
+	for i, e := range c.entries {
+		ps[i] = e.Plugin
+	}
+	return ps
+}
+
+// Clone returns a chain of the same plugins with their failures forgotten
+func (c *Chain) Clone() *Chain {
+	return NewChain(c.Plugins())
+}
+
+// WaveStarted runs the OnWaveStart hooks
+func (c *Chain) WaveStarted(ctx Context, wave int) {
+	for _, e := range c.entries {
+		if e.OnWaveStart != nil {
+			c.call(ctx, e, "wave_start", func() error { return e.OnWaveStart(ctx, wave) })
+		}
+	}
+}
+
+// EnemyKilled runs the OnEnemyKilled hooks
+func (c *Chain) EnemyKilled(ctx Context, ev events.Event) {
+	for _, e := range c.entries {
+		if e.OnEnemyKilled != nil {
+			c.call(ctx, e, "enemy_killed", func() error { return e.OnEnemyKilled(ctx, ev) })
+		}
+	}
+}
+
+// TowerPlaced runs the OnTowerPlaced hooks
+func (c *Chain) TowerPlaced(ctx Context, ev events.Event) {
+	for _, e := range c.entries {
+		if e.OnTowerPlaced != nil {
+			c.call(ctx, e, "tower_placed", func() error { return e.OnTowerPlaced(ctx, ev) })
+		}
+	}
+}
+
+// ModifyDamage passes a hit through the ModifyDamage hooks and returns the
+// damage it deals. A failing hook leaves the damage as it was; it never goes
+// below zero.
+func (c *Chain) ModifyDamage(ctx Context, hit Hit) int {
+	for _, e := range c.entries {
+		if e.ModifyDamage == nil {
+			continue
+		}
+		c.call(ctx, e, "modify_damage", func() error {
+			damage, err := e.ModifyDamage(ctx, hit)
+			if err == nil {
+				hit.Damage = max(damage, 0)
+			}
+			return err
+		})
+	}
+	return hit.Damage
+}
+
+// call runs one hook of a plugin, recovering from panics and counting failures
+func (c *Chain) call(ctx Context, e *entry, hook string, fn func() error) {
+	if e.disabled {
+		return
+	}
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return fn()
+	}()
+	if err == nil {
+		return
+	}
+	e.errors++
+	logging.Warnw("plugin_error", "game_id", ctx.GameID, "plugin", e.Name, "hook", hook, "errors", e.errors, "error", err)
+	if e.errors >= MaxErrors {
+		e.disabled = true
+		logging.Errorw("plugin_disabled", "game_id", ctx.GameID, "plugin", e.Name, "errors", e.errors)
+	}
+}
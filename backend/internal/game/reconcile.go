@@ -0,0 +1,61 @@
+package game
+
+import "time"
+
+// maxCommandClients bounds the clients whose last command a game remembers;
+// the one that was heard from longest ago is forgotten first
+const maxCommandClients = 64
+
+// commandSeq is the last sequenced command of a client
+type commandSeq struct {
+	seq uint64
+	at  time.Time
+}
+
+// CommandProcessed records that the command a client numbered seq has been
+// processed, whether the game accepted it or not, and returns the tick after
+// which it took effect. Snapshots list the highest seq of every client under
+// processed, so a client predicting its own commands drops those a snapshot
+// already reflects and replays the rest on top of it. A seq of 0 or an empty
+// client records nothing.
+func (g *Game) CommandProcessed(client string, seq uint64) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if client == "" || seq == 0 || seq <= g.commandSeqs[client].seq {
+		return g.clock.Tick
+	}
+	if g.commandSeqs == nil {
+		g.commandSeqs = make(map[string]commandSeq)
+	}
+	if _, ok := g.commandSeqs[client]; !ok && len(g.commandSeqs) >= maxCommandClients {
+		g.forgetOldestCommandClient()
+	}
+	g.commandSeqs[client] = commandSeq{seq: seq, at: time.Now()}
+	g.markChanged()
+	return g.clock.Tick
+}
+
+// forgetOldestCommandClient drops the client heard from longest ago (caller must hold g.mu)
+func (g *Game) forgetOldestCommandClient() {
+	oldest := ""
+	for client, c := range g.commandSeqs {
+		if oldest == "" || c.at.Before(g.commandSeqs[oldest].at) {
+			oldest = client
+		}
+	}
+	delete(g.commandSeqs, oldest)
+}
+
+// processedCommands returns the last processed seq of every client, nil if
+// none sent a sequenced command (caller must hold g.mu)
+func (g *Game) processedCommands() map[string]uint64 {
+	if len(g.commandSeqs) == 0 {
+		return nil
+	}
+	processed := make(map[string]uint64, len(g.commandSeqs))
+	for client, c := range g.commandSeqs {
+		processed[client] = c.seq
+	}
+	return processed
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/logging"
+)
+
+// ApplyBalance hot-applies the settings of cfg that are safe to change mid-game:
+// tower costs, enemy rewards (for enemies spawned from now on), the economy,
+// the price of ammunition and the rules restricting player actions.
+// Geometry, stats of existing entities and wave structure are left untouched.
+func (g *Game) ApplyBalance(cfg *config.GameConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Keep the game's custom rules on top of the new balance
+	if len(g.mutators) > 0 {
+		if mutated, _, err := cfg.WithMutators(g.mutators); err == nil {
+			cfg = mutated
+		}
+	}
+	// and its live tuning on top of those
+	if !g.overrides.Empty() {
+		if tuned, err := cfg.WithOverrides(g.overrides); err == nil {
+			cfg = tuned
+		}
+	}
+
+	for towerType, tc := range g.config.Towers {
+		if updated, ok := cfg.Towers[towerType]; ok {
+			tc.Cost = updated.Cost
+			g.config.Towers[towerType] = tc
+		}
+	}
+	for enemyType, ec := range g.config.Enemies {
+		if updated, ok := cfg.Enemies[enemyType]; ok {
+			ec.GoldReward = updated.GoldReward
+			ec.ScoreReward = updated.ScoreReward
+			g.config.Enemies[enemyType] = ec
+		}
+	}
+	g.config.Economy = cfg.Economy
+	g.config.Ammo.RoundCost = cfg.Ammo.RoundCost
+	g.economySystem.SetConfig(cfg.Economy)
+	g.config.Rules = cfg.Rules
+	g.rules = NewRulesEngine(cfg.Rules)
+	g.replay.rebalanced = true
+	g.markChanged()
+
+	logging.Infow("game_balance_applied", "game_id", g.id)
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"tower-defense/internal/game/ecs"
+)
+
+// Why an entity left the world, as reported in RemovalDTO.Reason
+const (
+	RemovalKilled  = "killed"  // an enemy died or a wall was broken
+	RemovalLeaked  = "leaked"  // an enemy reached the end of its path
+	RemovalExpired = "expired" // anything else, e.g. a projectile that landed or fizzled
+	RemovalSold    = "sold"    // a player sold the tower
+)
+
+// removalLog remembers the entities removed from the world during the last
+// game.corpse_grace_ms, so snapshots keep listing them after they are gone
+type removalLog struct {
+	reasons  map[string]string // entity ID -> reason, until the entity is cleaned up
+	removals []RemovalDTO      // oldest first
+}
+
+// mark records why an entity died; it is removed at the end of the tick
+func (l *removalLog) mark(id, reason string) {
+	if l.reasons == nil {
+		l.reasons = make(map[string]string)
+	}
+	l.reasons[id] = reason
+}
+
+// reset forgets every removal, e.g. when the world is cleared
+func (l *removalLog) reset() {
+	l.reasons = nil
+	l.removals = nil
+}
+
+// recordRemovals logs the entities the lifecycle system removed this tick and
+// drops the removals past the grace period
+func (g *Game) recordRemovals(removed []ecs.Entity) {
+	grace := float64(g.config.Game.CorpseGraceMs) / 1000
+	if grace <= 0 {
+		g.removals.reset()
+		return
+	}
+	for _, e := range removed {
+		reason, ok := g.removals.reasons[e.GetID()]
+		if !ok {
+			reason = RemovalExpired
+		}
+		delete(g.removals.reasons, e.GetID())
+
+		pos := e.GetPosition()
+		r := RemovalDTO{
+			ID:       e.GetID(),
+			Kind:     string(e.GetType()),
+			Reason:   reason,
+			Position: PosDTO{X: pos.X, Y: pos.Y},
+			Tick:     g.clock.Tick,
+			GameTime: g.clock.Time,
+		}
+		if enemy, ok := e.(*ecs.EnemyEntity); ok {
+			r.EnemyType = enemy.EnemyType
+			r.VisibleTo = enemy.VisibleTo
+		}
+		g.removals.removals = append(g.removals.removals, r)
+	}
+
+	expired := 0
+	for expired < len(g.removals.removals) && g.clock.Time-g.removals.removals[expired].GameTime > grace {
+		expired++
+	}
+	g.removals.removals = g.removals.removals[expired:]
+}
+
+// convertRemovals lists the removals still within the grace period; older ones
+// are only dropped when the next entities are removed
+func (g *Game) convertRemovals() []RemovalDTO {
+	grace := float64(g.config.Game.CorpseGraceMs) / 1000
+	var dtos []RemovalDTO
+	for _, r := range g.removals.removals {
+		if g.clock.Time-r.GameTime <= grace {
+			dtos = append(dtos, r)
+		}
+	}
+	return dtos
+}
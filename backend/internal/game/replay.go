@@ -0,0 +1,311 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/plugins"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// Limits of replay recording and scrubbing
+const (
+	ReplayKeyframeEvery = 600  // ticks between keyframes, and so the most re-simulated to reach a frame
+	MaxReplayKeyframes  = 1000 // recording stops after this many keyframes
+	MaxReplayFrames     = 300  // frames returned by one ReplayFrames call
+)
+
+// ErrInvalidTickRange is returned for a frame range outside a replay or too long
+var ErrInvalidTickRange = errors.New("invalid tick range")
+
+// Replay is the recording of a finished game. Instead of every frame it keeps
+// keyframes, full simulation saves taken now and then, and how long each tick
+// after them took; ReplayFrames re-simulates any tick from the keyframe
+// before it.
+type Replay struct {
+	GameID    string           `json:"gameId"`
+	MapID     string           `json:"mapId"`
+	Mutators  []string         `json:"mutators,omitempty"`
+	Pacing    string           `json:"pacing,omitempty"` // wave pacing mode of the run
+	Seed      int64            `json:"seed"`
+	Limits    EntityLimits     `json:"limits"`
+	FirstTick uint64           `json:"firstTick"` // earliest tick with a frame
+	LastTick  uint64           `json:"lastTick"`  // the tick the game ended in
+	Keyframes []ReplayKeyframe `json:"keyframes"`
+}
+
+// ReplayKeyframe is the simulation as the systems of tick Tick found it.
+// Besides every ReplayKeyframeEvery ticks, a keyframe is taken whenever the
+// game changed between two ticks, e.g. a tower was placed, so the ticks after
+// it re-simulate without having to replay commands. The first keyframe and
+// any taken after the balance was tuned or reloaded carry the config the
+// game ran on from then on.
+type ReplayKeyframe struct {
+	Tick     uint64             `json:"tick"`
+	GameTime float64            `json:"gameTime"` // simulated seconds at the end of tick Tick
+	Held     bool               `json:"held,omitempty"`
+	Config   *config.GameConfig `json:"config,omitempty"`
+	Save     SimulationSave     `json:"save"`
+	Steps    []int64            `json:"steps"` // wall clock microseconds of tick Tick and each one after it
+}
+
+// ReplayInfo describes a stored replay without its keyframes
+type ReplayInfo struct {
+	GameID    string   `json:"gameId"`
+	MapID     string   `json:"mapId"`
+	Mutators  []string `json:"mutators,omitempty"`
+	Seed      int64    `json:"seed"`
+	FirstTick uint64   `json:"firstTick"`
+	LastTick  uint64   `json:"lastTick"`
+	Keyframes int      `json:"keyframes"`
+}
+
+// Info describes the replay
+func (r *Replay) Info() ReplayInfo {
+	return ReplayInfo{GameID: r.GameID, MapID: r.MapID, Mutators: r.Mutators, Seed: r.Seed,
+		FirstTick: r.FirstTick, LastTick: r.LastTick, Keyframes: len(r.Keyframes)}
+}
+
+// replayRecorder records the game in progress
+type replayRecorder struct {
+	keyframes  []ReplayKeyframe
+	version    uint64 // of the game after the last recorded tick
+	held       bool   // whether waves were held in the last recorded tick
+	rebalanced bool   // the config changed since the last keyframe
+	full       bool   // MaxReplayKeyframes reached; nothing more is recorded
+}
+
+// replayKey is the repository key of a game's replays
+func replayKey(gameID string) string {
+	return gameID + ".replay"
+}
+
+// SetReplayRepository sets where the replays of this game's finished runs are stored; nil records none
+func (g *Game) SetReplayRepository(repo repository.Repository) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.replayRepo = repo
+}
+
+// recordReplayTick adds a tick that took elapsed to the recording, taking a
+// keyframe first if the game changed since the last tick. Called at now, the
+// start of the tick, before its systems run (caller must hold g.mu).
+func (g *Game) recordReplayTick(now time.Time, elapsed time.Duration) {
+	r := &g.replay
+	if g.replayRepo == nil || r.full {
+		return
+	}
+	n := len(r.keyframes)
+	held := g.waveSystem.Held()
+	if n == 0 || g.version != r.version || held != r.held || r.rebalanced || len(r.keyframes[n-1].Steps) >= ReplayKeyframeEvery {
+		if n == MaxReplayKeyframes {
+			r.full = true
+			logging.Warnw("replay_recording_stopped", "game_id", g.id, "tick", g.clock.Tick, "keyframes", n)
+			return
+		}
+		kf := ReplayKeyframe{
+			Tick:     g.clock.Tick,
+			GameTime: g.clock.Time,
+			Held:     held,
+			Save:     g.simulationSave(now),
+		}
+		if n == 0 || r.rebalanced {
+			kf.Config = g.config.Clone()
+			r.rebalanced = false
+		}
+		r.keyframes = append(r.keyframes, kf)
+		n++
+	}
+	kf := &r.keyframes[n-1]
+	kf.Steps = append(kf.Steps, elapsed.Microseconds())
+	r.held = held
+}
+
+// endReplayTick notes the version a tick left the game at, so changes made
+// before the next tick are spotted (caller must hold g.mu)
+func (g *Game) endReplayTick() {
+	g.replay.version = g.version
+}
+
+// takeReplay hands the recording of a just-finished game to storeReplay and
+// starts over (caller must hold g.mu)
+func (g *Game) takeReplay() {
+	r := g.replay
+	g.replay = replayRecorder{}
+	if g.replayRepo == nil || len(r.keyframes) == 0 {
+		return
+	}
+	last := r.keyframes[len(r.keyframes)-1]
+	g.unsavedReplay = &Replay{
+		GameID:    g.id,
+		MapID:     g.mapID,
+		Mutators:  slices.Clone(g.mutators),
+		Pacing:    g.wavePacing(),
+		Seed:      g.rng.Seed(),
+		Limits:    g.saturation.limits,
+		FirstTick: r.keyframes[0].Tick,
+		LastTick:  last.Tick + uint64(len(last.Steps)) - 1,
+		Keyframes: r.keyframes,
+	}
+}
+
+// storeReplay writes a just-finished replay to the repository (caller must not hold g.mu)
+func (g *Game) storeReplay() {
+	g.mu.Lock()
+	replay, repo := g.unsavedReplay, g.replayRepo
+	g.unsavedReplay = nil
+	g.mu.Unlock()
+
+	if replay == nil || repo == nil {
+		return
+	}
+	data, err := json.Marshal(replay)
+	if err == nil {
+		_, err = repo.Save(replayKey(replay.GameID), data)
+	}
+	if err != nil {
+		logging.Errorw("game_replay_save_failed", "game_id", replay.GameID, "error", err)
+		return
+	}
+	logging.Infow("game_replay_saved", "game_id", replay.GameID, "ticks", replay.LastTick-replay.FirstTick+1,
+		"keyframes", len(replay.Keyframes), "bytes", len(data))
+}
+
+// LoadReplay returns the replay of a game's last finished run
+func LoadReplay(repo repository.Repository, gameID string) (*Replay, error) {
+	save, err := repo.LoadLatest(replayKey(gameID))
+	if err != nil {
+		return nil, err
+	}
+	var replay Replay
+	if err := json.Unmarshal(save.Data, &replay); err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidData, err)
+	}
+	return &replay, nil
+}
+
+// ReplayFrames re-simulates the ticks from..to of a replay, both included, and
+// returns the snapshot taken after each. The replay runs on the config the
+// game was recorded with, with the manager's plugins.
+func (m *Manager) ReplayFrames(replay *Replay, from, to uint64) ([]GameStateSnapshot, error) {
+	if from < replay.FirstTick || to > replay.LastTick || from > to {
+		return nil, fmt.Errorf("%w: %d-%d, the replay has ticks %d-%d", ErrInvalidTickRange, from, to, replay.FirstTick, replay.LastTick)
+	}
+	if to-from >= MaxReplayFrames {
+		return nil, fmt.Errorf("%w: %d frames asked for, at most %d", ErrInvalidTickRange, to-from+1, MaxReplayFrames)
+	}
+
+	// The keyframe the range starts in
+	i, found := slices.BinarySearchFunc(replay.Keyframes, from, func(kf ReplayKeyframe, tick uint64) int {
+		switch {
+		case kf.Tick < tick:
+			return -1
+		case kf.Tick > tick:
+			return 1
+		}
+		return 0
+	})
+	if !found {
+		i--
+	}
+
+	// and the config the game ran on then
+	var cfg *config.GameConfig
+	for _, kf := range slices.Backward(replay.Keyframes[:i+1]) {
+		if kf.Config != nil {
+			cfg = kf.Config
+			break
+		}
+	}
+	if cfg == nil {
+		// Recorded before replays kept their config
+		logging.Warnw("replay_config_unknown", "game_id", replay.GameID)
+		mutated, _, err := m.Config().WithMutators(replay.Mutators)
+		if err != nil {
+			return nil, err
+		}
+		cfg = mutated
+	}
+	m.mu.RLock()
+	extra := slices.Clone(m.plugins)
+	m.mu.RUnlock()
+
+	started := time.Now()
+	sim := replay.newSim(cfg, extra)
+	frames := make([]GameStateSnapshot, 0, to-from+1)
+	for _, kf := range replay.Keyframes[i:] {
+		if kf.Tick > to {
+			break
+		}
+		if kf.Config != nil && kf.Config != cfg {
+			// The balance was tuned or reloaded here
+			cfg = kf.Config
+			sim = replay.newSim(cfg, extra)
+		}
+		if err := sim.replayKeyframe(kf, from, to, &frames); err != nil {
+			return nil, err
+		}
+	}
+	logging.Debugw("replay_frames", "game_id", replay.GameID, "from_tick", from, "to_tick", to,
+		"frames", len(frames), "took_ms", time.Since(started).Milliseconds())
+	return frames, nil
+}
+
+// newSim returns a game that re-simulates the replay on cfg, set up like the
+// recorded one with extra, the plugins of its manager, on top of the registered ones
+func (r *Replay) newSim(cfg *config.GameConfig, extra []plugins.Plugin) *Game {
+	sim := NewGameWithMap(r.GameID+":replay", cfg, r.MapID)
+	if checkWavePacing(r.Pacing) == nil {
+		sim.setWavePacing(r.Pacing)
+	}
+	for _, p := range extra {
+		if err := sim.plugins.Add(p); err != nil {
+			logging.Warnw("plugin_not_added", "game_id", sim.id, "plugin", p.Name, "error", err)
+		}
+	}
+	sim.applyEntityLimits(r.Limits)
+	sim.forked = true
+	return sim
+}
+
+// replayKeyframe restores a keyframe on a game of its own and re-simulates its
+// ticks, appending a snapshot of those within from..to to frames
+func (g *Game) replayKeyframe(kf ReplayKeyframe, from, to uint64, frames *[]GameStateSnapshot) error {
+	clock := time.Now()
+	g.world.SetClock(func() time.Time { return clock })
+	if kf.Save.Wallets != nil && g.wallets == nil {
+		g.wallets = make(map[string]int)
+	}
+	if err := g.restoreSimulation(kf.Save, clock); err != nil {
+		return err
+	}
+	g.waveSystem.SetHeld(kf.Held, g.world.Now())
+	g.clock = gameClock{Tick: kf.Tick, Time: kf.GameTime}
+
+	for i, step := range kf.Steps {
+		elapsed := time.Duration(step) * time.Microsecond
+		dt := min(max(elapsed.Seconds(), 0), maxTickDt)
+		if i > 0 {
+			// The keyframe was taken once tick Tick had started
+			clock = clock.Add(elapsed)
+			g.clock.Tick++
+			g.clock.Time += dt
+		}
+		// Replays have no lobby; the keyframes carry the waves it held
+		g.simulate(dt)
+		g.pendingEvents = nil
+
+		if g.clock.Tick >= from {
+			*frames = append(*frames, g.GetState())
+		}
+		if g.clock.Tick >= to {
+			break
+		}
+	}
+	return nil
+}
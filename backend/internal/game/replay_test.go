@@ -0,0 +1,90 @@
+package game
+
+import (
+	"testing"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/plugins"
+	"tower-defense/internal/game/repository"
+)
+
+// TestReplayMatchesRun re-simulates a run whose balance was tuned halfway and
+// whose damage a plugin changes, and expects the frames the run produced
+func TestReplayMatchesRun(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(cfg)
+	forks := 0
+	double := plugins.Plugin{Name: "double", ModifyDamage: func(ctx plugins.Context, hit plugins.Hit) (int, error) {
+		if ctx.Fork {
+			forks++
+		}
+		return hit.Damage * 2, nil
+	}}
+	if err := m.AddPlugin(double); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGame("replay", cfg)
+	g.StopClock()
+	if err := g.AddPlugin(double); err != nil {
+		t.Fatal(err)
+	}
+	g.SetReplayRepository(repository.NewMemoryRepository())
+	if err := g.AddTower("basic", 150, 200); err != nil {
+		t.Fatal(err)
+	}
+
+	live := make(map[uint64]GameStateSnapshot)
+	step := func(ticks int) {
+		for range ticks {
+			g.Step(0.05)
+			state := g.GetState()
+			live[state.Tick] = state
+		}
+	}
+	step(400)
+	speed := 3.0
+	overrides := config.BalanceOverrides{Enemies: map[string]config.EnemyOverride{}}
+	for name := range cfg.Enemies {
+		overrides.Enemies[name] = config.EnemyOverride{Speed: &speed}
+	}
+	if err := g.TuneBalance(overrides); err != nil {
+		t.Fatal(err)
+	}
+	tunedAt := g.GetState().Tick
+	step(400)
+	g.mu.Lock()
+	g.finish(OutcomeLost, "test")
+	g.mu.Unlock()
+	g.storeReplay()
+
+	replay, err := LoadReplay(g.replayRepo, "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := m.ReplayFrames(replay, tunedAt-100, tunedAt+150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 251 {
+		t.Fatalf("%d frames, want 251", len(frames))
+	}
+	for _, got := range frames {
+		want := live[got.Tick]
+		if got.Gold != want.Gold || got.Score != want.Score || len(got.Enemies) != len(want.Enemies) {
+			t.Fatalf("tick %d: replay gold %d score %d enemies %d, run gold %d score %d enemies %d",
+				got.Tick, got.Gold, got.Score, len(got.Enemies), want.Gold, want.Score, len(want.Enemies))
+		}
+		for i, e := range got.Enemies {
+			if w := want.Enemies[i]; e.Type != w.Type || e.HP != w.HP || e.Position != w.Position {
+				t.Fatalf("tick %d: replay enemy %+v, run enemy %+v", got.Tick, e, w)
+			}
+		}
+	}
+	if forks == 0 {
+		t.Errorf("the replay ran without the manager's plugins, or not as a fork")
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// UnlockedAchievement records an achievement unlocked by a player
+type UnlockedAchievement struct {
+	AchievementID string    `json:"achievement_id"`
+	PlayerID      string    `json:"player_id"`
+	GameID        string    `json:"game_id"`
+	UnlockedAt    time.Time `json:"unlocked_at"`
+}
+
+// AchievementRepository defines the interface for achievement persistence
+type AchievementRepository interface {
+	// Unlock records an achievement for a player; returns false if it was already unlocked
+	Unlock(a UnlockedAchievement) (bool, error)
+
+	// ListUnlocked returns all achievements unlocked by a player
+	ListUnlocked(playerID string) ([]UnlockedAchievement, error)
+}
+
+// MemoryAchievementRepository implements in-memory achievement persistence
+type MemoryAchievementRepository struct {
+	mu       sync.RWMutex
+	unlocked map[string][]UnlockedAchievement // playerID -> achievements
+}
+
+// NewMemoryAchievementRepository creates a new in-memory achievement repository
+func NewMemoryAchievementRepository() *MemoryAchievementRepository {
+	return &MemoryAchievementRepository{
+		unlocked: make(map[string][]UnlockedAchievement),
+	}
+}
+
+// Unlock records an achievement for a player
+func (r *MemoryAchievementRepository) Unlock(a UnlockedAchievement) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.unlocked[a.PlayerID] {
+		if existing.AchievementID == a.AchievementID {
+			return false, nil
+		}
+	}
+
+	r.unlocked[a.PlayerID] = append(r.unlocked[a.PlayerID], a)
+	return true, nil
+}
+
+// ListUnlocked returns all achievements unlocked by a player
+func (r *MemoryAchievementRepository) ListUnlocked(playerID string) ([]UnlockedAchievement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]UnlockedAchievement, len(r.unlocked[playerID]))
+	copy(result, r.unlocked[playerID])
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Save encodings, recorded in the header of stored save data
+const (
+	EncodingIdentity = "identity" // uncompressed; also used for saves written before headers existed
+	EncodingGzip     = "gzip"
+)
+
+// saveMagic starts every encoded save. The header is the magic, one encoding
+// byte and the SHA-256 of the uncompressed data, followed by the payload.
+var saveMagic = []byte("TDS1")
+
+const saveHeaderSize = 4 + 1 + sha256.Size
+
+var encodingBytes = map[string]byte{EncodingIdentity: 0, EncodingGzip: 1}
+
+// encodeSave compresses data with gzip and prepends the save header
+func encodeSave(data []byte) ([]byte, string, error) {
+	sum := sha256.Sum256(data)
+
+	var buf bytes.Buffer
+	buf.Grow(saveHeaderSize + len(data)/4)
+	buf.Write(saveMagic)
+	buf.WriteByte(encodingBytes[EncodingGzip])
+	buf.Write(sum[:])
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to compress save: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to compress save: %w", err)
+	}
+	return buf.Bytes(), hex.EncodeToString(sum[:]), nil
+}
+
+// decodeSave reverses encodeSave and verifies the checksum, returning the
+// uncompressed data, its encoding and checksum. Data without a header is a
+// save from before compression and is returned as is, unverified.
+func decodeSave(stored []byte) ([]byte, string, string, error) {
+	if !bytes.HasPrefix(stored, saveMagic) {
+		return stored, EncodingIdentity, "", nil
+	}
+	if len(stored) < saveHeaderSize {
+		return nil, "", "", fmt.Errorf("%w: truncated header", ErrInvalidData)
+	}
+	code := stored[len(saveMagic)]
+	want := stored[len(saveMagic)+1 : saveHeaderSize]
+	payload := stored[saveHeaderSize:]
+
+	var data []byte
+	var encoding string
+	switch code {
+	case encodingBytes[EncodingIdentity]:
+		data, encoding = payload, EncodingIdentity
+	case encodingBytes[EncodingGzip]:
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, "", "", fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		encoding = EncodingGzip
+	default:
+		return nil, "", "", fmt.Errorf("%w: unknown encoding %d", ErrInvalidData, code)
+	}
+
+	sum := sha256.Sum256(data)
+	if !bytes.Equal(sum[:], want) {
+		return nil, "", "", fmt.Errorf("%w: checksum mismatch", ErrInvalidData)
+	}
+	return data, encoding, hex.EncodeToString(sum[:]), nil
+}
+
+// decode replaces the stored data of a save read from storage with its
+// uncompressed form and fills in Encoding and Checksum
+func (s *GameSave) decode() error {
+	data, encoding, checksum, err := decodeSave(s.Data)
+	if err != nil {
+		return fmt.Errorf("save %s: %w", s.ID, err)
+	}
+	s.Data, s.Encoding, s.Checksum = data, encoding, checksum
+	return nil
+}
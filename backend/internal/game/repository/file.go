@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,10 +13,15 @@ import (
 	"github.com/google/uuid"
 )
 
-// FileRepository implements file-based game persistence
+// FileRepository implements file-based game persistence. Saves are looked up
+// through an index file kept in each game directory.
 type FileRepository struct {
 	mu      sync.RWMutex
 	baseDir string
+	index   *fileIndex
+
+	retention RetentionPolicy
+	evicted   evictionCounter
 }
 
 // NewFileRepository creates a new file-based repository
@@ -24,25 +31,53 @@ func NewFileRepository(baseDir string) (*FileRepository, error) {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
 	}
 	
-	return &FileRepository{
+	r := &FileRepository{
 		baseDir: baseDir,
-	}, nil
+	}
+	if err := r.loadIndex(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Ping checks that the base directory still exists
+func (r *FileRepository) Ping(ctx context.Context) error {
+	info, err := os.Stat(r.baseDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", r.baseDir)
+	}
+	return nil
 }
 
 // Save stores a game state to disk
 func (r *FileRepository) Save(gameID string, data []byte) (string, error) {
+	return r.SaveOwned(gameID, "", data)
+}
+
+// SaveOwned stores a player's save to disk and enforces the retention policy
+func (r *FileRepository) SaveOwned(gameID, owner string, data []byte) (string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
 	saveID := uuid.New().String()
 	now := time.Now()
 	
+	encoded, checksum, err := encodeSave(data)
+	if err != nil {
+		return "", err
+	}
 	save := &GameSave{
 		ID:        saveID,
 		GameID:    gameID,
-		Data:      data,
+		Data:      encoded,
 		CreatedAt: now,
 		UpdatedAt: now,
+		Owner:     owner,
+		Encoding:  EncodingGzip,
+		Checksum:  checksum,
 	}
 	
 	// Create game directory if it doesn't exist
@@ -52,153 +87,194 @@ func (r *FileRepository) Save(gameID string, data []byte) (string, error) {
 	}
 	
 	// Write save file
-	savePath := filepath.Join(gameDir, fmt.Sprintf("%s.json", saveID))
+	file := filepath.Join(gameID, fmt.Sprintf("%s.json", saveID))
+	savePath := filepath.Join(r.baseDir, file)
 	saveData, err := json.Marshal(save)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal save: %w", err)
 	}
 	
-	if err := os.WriteFile(savePath, saveData, 0644); err != nil {
+	if err := writeFileAtomic(gameDir, filepath.Base(file), saveData); err != nil {
 		return "", fmt.Errorf("failed to write save file: %w", err)
 	}
 	
+	// A save is only stored once the index lists it
+	r.index.add(gameID, indexEntry{ID: saveID, File: file, Size: int64(len(saveData)), CreatedAt: now, UpdatedAt: now, Owner: owner})
+	if err := r.writeIndex(gameID); err != nil {
+		r.index.remove(saveID)
+		os.Remove(savePath)
+		return "", err
+	}
+	if owner != "" {
+		r.enforceRetention(gameID, owner, now)
+	}
+	
 	return saveID, nil
 }
 
-// Load retrieves a game state from disk
-func (r *FileRepository) Load(saveID string) (*GameSave, error) {
+// SetRetention sets the retention policy enforced by later player saves
+func (r *FileRepository) SetRetention(p RetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retention = p
+}
+
+// enforceRetention evicts expired player saves and trims gameID and owner to
+// the policy. Eviction is best effort: a file that can't be removed stays
+// indexed and is tried again on the next save. (caller must hold r.mu)
+func (r *FileRepository) enforceRetention(gameID, owner string, now time.Time) {
+	var evict []indexEntry
+	var game, owned []storedSave
+	for key, entries := range r.index.Games {
+		for _, e := range entries {
+			if e.Owner == "" {
+				continue
+			}
+			if r.retention.expired(e.UpdatedAt, now) {
+				evict = append(evict, e)
+				continue
+			}
+			if key == gameID {
+				game = append(game, e.stored())
+			}
+			if e.Owner == owner {
+				owned = append(owned, e.stored())
+			}
+		}
+	}
+	sortStored(owned)
+	for _, s := range r.retention.evictions(game, owned, now) {
+		_, e, _ := r.index.find(s.id)
+		evict = append(evict, e)
+	}
+	
+	if len(evict) == 0 {
+		return
+	}
+	touched := make(map[string]bool)
+	for _, e := range evict {
+		if err := os.Remove(filepath.Join(r.baseDir, e.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		touched[r.index.games[e.ID]] = true
+		r.index.remove(e.ID)
+		r.evicted.add(e.stored())
+	}
+	for key := range touched {
+		r.writeIndex(key)
+	}
+}
+
+// GetStats returns statistics about the repository
+func (r *FileRepository) GetStats() (RepositoryStats, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
-	// Search for save file in all game directories
-	var savePath string
-	err := filepath.Walk(r.baseDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && filepath.Base(path) == fmt.Sprintf("%s.json", saveID) {
-			savePath = path
-			return filepath.SkipAll
+	stats := RepositoryStats{TotalGames: len(r.index.Games)}
+	for _, entries := range r.index.Games {
+		for _, e := range entries {
+			stats.TotalSaves++
+			stats.TotalBytes += int(e.Size)
 		}
-		return nil
-	})
-	
-	if err != nil {
-		return nil, fmt.Errorf("failed to search for save: %w", err)
 	}
+	r.evicted.fill(&stats, r.retention)
+	return stats, nil
+}
+
+// Load retrieves a game state from disk
+func (r *FileRepository) Load(saveID string) (*GameSave, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	
-	if savePath == "" {
+	_, entry, ok := r.index.find(saveID)
+	if !ok {
 		return nil, ErrSaveNotFound
 	}
 	
-	// Read and unmarshal save
-	data, err := os.ReadFile(savePath)
+	save, err := r.readSave(entry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read save file: %w", err)
+		return nil, err
 	}
-	
-	var save GameSave
-	if err := json.Unmarshal(data, &save); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal save: %w", err)
+	if err := save.decode(); err != nil {
+		return nil, err
 	}
 	
-	return &save, nil
+	return save, nil
 }
 
-// LoadLatest retrieves the latest save for a game
+// LoadLatest retrieves the latest save for a game; a corrupted latest save is
+// reported rather than skipped
 func (r *FileRepository) LoadLatest(gameID string) (*GameSave, error) {
-	saves, err := r.List(gameID)
-	if err != nil {
-		return nil, err
-	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	
-	if len(saves) == 0 {
+	entry, ok := r.index.latest(gameID)
+	if !ok {
 		return nil, ErrSaveNotFound
 	}
 	
-	// Find the latest save
-	var latest *GameSave
-	for _, save := range saves {
-		if latest == nil || save.UpdatedAt.After(latest.UpdatedAt) {
-			latest = save
-		}
+	latest, err := r.readSave(entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := latest.decode(); err != nil {
+		return nil, err
 	}
 	
 	return latest, nil
 }
 
-// List returns all saves for a game
+// List returns all saves for a game, oldest first, skipping unreadable and
+// corrupted ones
 func (r *FileRepository) List(gameID string) ([]*GameSave, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
-	gameDir := filepath.Join(r.baseDir, gameID)
-	
-	// Check if game directory exists
-	if _, err := os.Stat(gameDir); os.IsNotExist(err) {
-		return []*GameSave{}, nil
-	}
-	
-	// Read all save files
-	entries, err := os.ReadDir(gameDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read game directory: %w", err)
-	}
-	
+	entries := r.index.Games[gameID]
 	saves := make([]*GameSave, 0, len(entries))
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+		save, err := r.readSave(entry)
+		if err != nil || save.decode() != nil {
 			continue
 		}
-		
-		savePath := filepath.Join(gameDir, entry.Name())
-		data, err := os.ReadFile(savePath)
-		if err != nil {
-			continue // Skip files we can't read
-		}
-		
-		var save GameSave
-		if err := json.Unmarshal(data, &save); err != nil {
-			continue // Skip invalid saves
-		}
-		
-		saves = append(saves, &save)
+		saves = append(saves, save)
 	}
-	
 	return saves, nil
 }
 
+// readSave reads the file of an indexed save, not yet decoded (caller must hold r.mu)
+func (r *FileRepository) readSave(entry indexEntry) (*GameSave, error) {
+	data, err := os.ReadFile(filepath.Join(r.baseDir, entry.File))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSaveNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save file: %w", err)
+	}
+	
+	var save GameSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return &save, nil
+}
+
 // Delete removes a save from disk
 func (r *FileRepository) Delete(saveID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
-	// Search for and delete save file
-	var savePath string
-	err := filepath.Walk(r.baseDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && filepath.Base(path) == fmt.Sprintf("%s.json", saveID) {
-			savePath = path
-			return filepath.SkipAll
-		}
-		return nil
-	})
-	
-	if err != nil {
-		return fmt.Errorf("failed to search for save: %w", err)
-	}
-	
-	if savePath == "" {
+	gameID, entry, ok := r.index.find(saveID)
+	if !ok {
 		return ErrSaveNotFound
 	}
 	
-	if err := os.Remove(savePath); err != nil {
+	if err := os.Remove(filepath.Join(r.baseDir, entry.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("failed to delete save file: %w", err)
 	}
+	r.index.remove(saveID)
 	
-	return nil
+	return r.writeIndex(gameID)
 }
 
 // DeleteAll removes all saves for a game
@@ -208,15 +284,10 @@ func (r *FileRepository) DeleteAll(gameID string) error {
 	
 	gameDir := filepath.Join(r.baseDir, gameID)
 	
-	// Check if game directory exists
-	if _, err := os.Stat(gameDir); os.IsNotExist(err) {
-		return nil
-	}
-	
-	// Remove entire game directory
+	// Remove entire game directory, its index included
 	if err := os.RemoveAll(gameDir); err != nil {
 		return fmt.Errorf("failed to delete game directory: %w", err)
 	}
-	
+	r.index.removeGame(gameID)
 	return nil
 }
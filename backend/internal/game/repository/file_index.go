@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// indexFile is the name of the index of a game's saves in its directory
+const indexFile = "index.json"
+
+// gameIndex is what the index file of a game directory holds
+type gameIndex struct {
+	GameID string       `json:"game_id"`
+	Saves  []indexEntry `json:"saves"` // oldest first
+}
+
+// indexEntry is one save as listed in the index
+type indexEntry struct {
+	ID        string    `json:"id"`
+	File      string    `json:"file"` // relative to the base directory
+	Size      int64     `json:"size"` // bytes on disk
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Owner     string    `json:"owner,omitempty"` // see GameSave.Owner
+}
+
+func (e indexEntry) stored() storedSave {
+	return storedSave{id: e.ID, size: e.Size, updatedAt: e.UpdatedAt}
+}
+
+// fileIndex maps save IDs to files and lists every game's saves, so lookups
+// don't walk the directory tree. Each game's part is kept in an index file in
+// the game's directory, so a change only rewrites the index of its game.
+type fileIndex struct {
+	Games map[string][]indexEntry // gameID -> saves, oldest first
+
+	games map[string]string // saveID -> gameID
+}
+
+func newFileIndex() *fileIndex {
+	return &fileIndex{Games: make(map[string][]indexEntry), games: make(map[string]string)}
+}
+
+// find returns the game and entry of a save
+func (x *fileIndex) find(saveID string) (string, indexEntry, bool) {
+	gameID, ok := x.games[saveID]
+	if !ok {
+		return "", indexEntry{}, false
+	}
+	for _, e := range x.Games[gameID] {
+		if e.ID == saveID {
+			return gameID, e, true
+		}
+	}
+	return "", indexEntry{}, false
+}
+
+// latest returns the most recently updated save of a game
+func (x *fileIndex) latest(gameID string) (indexEntry, bool) {
+	entries := x.Games[gameID]
+	if len(entries) == 0 {
+		return indexEntry{}, false
+	}
+	return entries[len(entries)-1], true
+}
+
+func (x *fileIndex) add(gameID string, e indexEntry) {
+	x.Games[gameID] = append(x.Games[gameID], e)
+	x.games[e.ID] = gameID
+}
+
+func (x *fileIndex) remove(saveID string) {
+	gameID, ok := x.games[saveID]
+	if !ok {
+		return
+	}
+	delete(x.games, saveID)
+	entries := x.Games[gameID]
+	for i, e := range entries {
+		if e.ID == saveID {
+			entries = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(x.Games, gameID)
+		return
+	}
+	x.Games[gameID] = entries
+}
+
+func (x *fileIndex) removeGame(gameID string) {
+	for _, e := range x.Games[gameID] {
+		delete(x.games, e.ID)
+	}
+	delete(x.Games, gameID)
+}
+
+// loadIndex reads the index file of every game directory. A game without a
+// readable one is indexed from its save files again (caller must hold r.mu).
+func (r *FileRepository) loadIndex() error {
+	dirs, err := os.ReadDir(r.baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	index := newFileIndex()
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(r.baseDir, d.Name(), indexFile))
+		var game gameIndex
+		if err == nil && json.Unmarshal(data, &game) == nil && game.GameID != "" {
+			for _, e := range game.Saves {
+				index.add(game.GameID, e)
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read index: %w", err)
+		}
+		if err := r.indexGameDir(index, d.Name()); err != nil {
+			return err
+		}
+	}
+	// Earlier versions kept one index of all games in the base directory
+	os.Remove(filepath.Join(r.baseDir, indexFile))
+	r.index = index
+	return nil
+}
+
+// RebuildIndex recreates the index from the save files on disk, e.g. after
+// saves were copied in or removed by hand
+func (r *FileRepository) RebuildIndex() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rebuildIndex()
+}
+
+// rebuildIndex indexes every game directory from the save files found there
+// (caller must hold r.mu)
+func (r *FileRepository) rebuildIndex() error {
+	dirs, err := os.ReadDir(r.baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	index := newFileIndex()
+	for _, d := range dirs {
+		if d.IsDir() {
+			if err := r.indexGameDir(index, d.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	r.index = index
+	return nil
+}
+
+// indexGameDir adds the save files of a game directory to index and writes
+// the directory's index file; unreadable files are skipped (caller must hold r.mu)
+func (r *FileRepository) indexGameDir(index *fileIndex, dir string) error {
+	files, err := os.ReadDir(filepath.Join(r.baseDir, dir))
+	if err != nil {
+		return fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	games := make(map[string]bool)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" || f.Name() == indexFile {
+			continue
+		}
+		rel := filepath.Join(dir, f.Name())
+		data, err := os.ReadFile(filepath.Join(r.baseDir, rel))
+		if err != nil {
+			continue
+		}
+		var save GameSave
+		if err := json.Unmarshal(data, &save); err != nil || save.ID == "" {
+			continue
+		}
+		index.add(save.GameID, indexEntry{ID: save.ID, File: rel, Size: int64(len(data)), CreatedAt: save.CreatedAt, UpdatedAt: save.UpdatedAt, Owner: save.Owner})
+		games[save.GameID] = true
+	}
+	for gameID := range games {
+		entries := index.Games[gameID]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].UpdatedAt.Before(entries[j].UpdatedAt) })
+		if err := writeGameIndex(r.baseDir, gameID, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIndex replaces the index file of a game with its current saves, or
+// removes it once the game has none (caller must hold r.mu)
+func (r *FileRepository) writeIndex(gameID string) error {
+	return writeGameIndex(r.baseDir, gameID, r.index.Games[gameID])
+}
+
+func writeGameIndex(baseDir, gameID string, entries []indexEntry) error {
+	dir := filepath.Join(baseDir, gameID)
+	if len(entries) == 0 {
+		if err := os.Remove(filepath.Join(dir, indexFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(gameIndex{GameID: gameID, Saves: entries})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := writeFileAtomic(dir, indexFile, data); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces dir/name by renaming a fully written temporary file
+// over it, synced first, so neither readers nor a crash leave a partial file
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	// The rename itself is only durable once the directory is synced
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
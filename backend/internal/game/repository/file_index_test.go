@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIndexPerGame(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewFileRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, _ := r.Save("a", []byte(`{"wave":1}`))
+	a2, _ := r.Save("a", []byte(`{"wave":2}`))
+	b1, _ := r.Save("b", []byte(`{"wave":1}`))
+
+	// a change to b leaves a's index alone
+	aIndex := filepath.Join(dir, "a", indexFile)
+	before, err := os.Stat(aIndex)
+	if err != nil {
+		t.Fatalf("index of a: %v", err)
+	}
+	if _, err := r.Save("b", []byte(`{"wave":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(b1); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.Stat(aIndex); !os.SameFile(before, after) {
+		t.Errorf("index of a rewritten by changes to b")
+	}
+	if err := r.Delete(a1); err != nil {
+		t.Fatal(err)
+	}
+
+	// b is indexed from its save files again once its index is gone
+	if err := os.Remove(filepath.Join(dir, "b", indexFile)); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewFileRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for game, want := range map[string]int{"a": 1, "b": 1} {
+		if n := count(t, reopened, game); n != want {
+			t.Errorf("%s has %d saves after reopening, want %d", game, n, want)
+		}
+	}
+	if _, err := reopened.Load(a2); err != nil {
+		t.Errorf("load %s: %v", a2, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b", indexFile)); err != nil {
+		t.Errorf("index of b not rewritten: %v", err)
+	}
+
+	if err := reopened.DeleteAll("a"); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, reopened, "a"); n != 0 {
+		t.Errorf("a has %d saves after DeleteAll", n)
+	}
+}
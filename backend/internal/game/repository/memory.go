@@ -12,6 +12,9 @@ type MemoryRepository struct {
 	mu    sync.RWMutex
 	saves map[string]*GameSave
 	index map[string][]string // gameID -> []saveID
+
+	retention RetentionPolicy
+	evicted   evictionCounter
 }
 
 // NewMemoryRepository creates a new in-memory repository
@@ -24,6 +27,11 @@ func NewMemoryRepository() *MemoryRepository {
 
 // Save stores a game state
 func (r *MemoryRepository) Save(gameID string, data []byte) (string, error) {
+	return r.SaveOwned(gameID, "", data)
+}
+
+// SaveOwned stores a player's save and enforces the retention policy
+func (r *MemoryRepository) SaveOwned(gameID, owner string, data []byte) (string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
@@ -36,11 +44,15 @@ func (r *MemoryRepository) Save(gameID string, data []byte) (string, error) {
 		Data:      make([]byte, len(data)),
 		CreatedAt: now,
 		UpdatedAt: now,
+		Owner:     owner,
 	}
 	copy(save.Data, data)
 	
 	r.saves[saveID] = save
 	r.index[gameID] = append(r.index[gameID], saveID)
+	if owner != "" {
+		r.enforceRetention(gameID, owner, now)
+	}
 	
 	return saveID, nil
 }
@@ -62,6 +74,7 @@ func (r *MemoryRepository) Load(saveID string) (*GameSave, error) {
 		Data:      make([]byte, len(save.Data)),
 		CreatedAt: save.CreatedAt,
 		UpdatedAt: save.UpdatedAt,
+		Owner:     save.Owner,
 	}
 	copy(result.Data, save.Data)
 	
@@ -89,6 +102,7 @@ func (r *MemoryRepository) LoadLatest(gameID string) (*GameSave, error) {
 		Data:      make([]byte, len(save.Data)),
 		CreatedAt: save.CreatedAt,
 		UpdatedAt: save.UpdatedAt,
+		Owner:     save.Owner,
 	}
 	copy(result.Data, save.Data)
 	
@@ -115,6 +129,7 @@ func (r *MemoryRepository) List(gameID string) ([]*GameSave, error) {
 				Data:      make([]byte, len(save.Data)),
 				CreatedAt: save.CreatedAt,
 				UpdatedAt: save.UpdatedAt,
+				Owner:     save.Owner,
 			}
 			copy(copied.Data, save.Data)
 			result = append(result, copied)
@@ -133,23 +148,67 @@ func (r *MemoryRepository) Delete(saveID string) error {
 	if !exists {
 		return ErrSaveNotFound
 	}
+	r.remove(save)
 	
-	// Remove from saves map
-	delete(r.saves, saveID)
+	return nil
+}
+
+// remove deletes a save from the saves map and the index (caller must hold r.mu)
+func (r *MemoryRepository) remove(save *GameSave) {
+	delete(r.saves, save.ID)
 	
-	// Remove from index
 	gameID := save.GameID
 	if saveIDs, exists := r.index[gameID]; exists {
 		newIDs := make([]string, 0, len(saveIDs)-1)
 		for _, id := range saveIDs {
-			if id != saveID {
+			if id != save.ID {
 				newIDs = append(newIDs, id)
 			}
 		}
 		r.index[gameID] = newIDs
 	}
+}
+
+// SetRetention sets the retention policy enforced by later player saves
+func (r *MemoryRepository) SetRetention(p RetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retention = p
+}
+
+// enforceRetention evicts expired player saves and trims gameID and owner to
+// the policy (caller must hold r.mu)
+func (r *MemoryRepository) enforceRetention(gameID, owner string, now time.Time) {
+	stored := func(save *GameSave) storedSave {
+		return storedSave{id: save.ID, size: int64(len(save.Data)), updatedAt: save.UpdatedAt}
+	}
+	evict := func(save *GameSave) {
+		r.remove(save)
+		r.evicted.add(stored(save))
+	}
+	for _, save := range r.saves {
+		if save.Owner != "" && r.retention.expired(save.UpdatedAt, now) {
+			evict(save)
+		}
+	}
 	
-	return nil
+	var game, owned []storedSave
+	for _, id := range r.index[gameID] {
+		if save := r.saves[id]; save.Owner != "" {
+			game = append(game, stored(save))
+		}
+	}
+	for _, ids := range r.index {
+		for _, id := range ids {
+			if save := r.saves[id]; save.Owner == owner {
+				owned = append(owned, stored(save))
+			}
+		}
+	}
+	sortStored(owned)
+	for _, s := range r.retention.evictions(game, owned, now) {
+		evict(r.saves[s.id])
+	}
 }
 
 // DeleteAll removes all saves for a game
@@ -174,7 +233,7 @@ func (r *MemoryRepository) DeleteAll(gameID string) error {
 }
 
 // GetStats returns statistics about the repository
-func (r *MemoryRepository) GetStats() RepositoryStats {
+func (r *MemoryRepository) GetStats() (RepositoryStats, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
@@ -182,17 +241,18 @@ func (r *MemoryRepository) GetStats() RepositoryStats {
 	for _, save := range r.saves {
 		totalSize += len(save.Data)
 	}
+	games := 0
+	for _, saveIDs := range r.index {
+		if len(saveIDs) > 0 {
+			games++
+		}
+	}
 	
-	return RepositoryStats{
+	stats := RepositoryStats{
 		TotalSaves: len(r.saves),
-		TotalGames: len(r.index),
+		TotalGames: games,
 		TotalBytes: totalSize,
 	}
-}
-
-// RepositoryStats contains statistics about the repository
-type RepositoryStats struct {
-	TotalSaves int `json:"total_saves"`
-	TotalGames int `json:"total_games"`
-	TotalBytes int `json:"total_bytes"`
+	r.evicted.fill(&stats, r.retention)
+	return stats, nil
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// PlayerProgress is a player's meta-progression carried between games
+type PlayerProgress struct {
+	PlayerID  string    `json:"player_id"`
+	Points    int       `json:"points"`   // research points not spent yet
+	Unlocked  []string  `json:"unlocked"` // research nodes bought, in purchase order
+	UpdatedAt time.Time `json:"updated_at"`
+
+	TutorialCompletedAt *time.Time `json:"tutorial_completed_at,omitempty"`
+
+	Locale string `json:"locale,omitempty"` // language of server messages for the player, "" = Accept-Language
+
+	Templates []BuildTemplate `json:"templates,omitempty"` // saved build templates, by name
+}
+
+// BuildTemplate is a named tower layout a player can place in any game at once
+type BuildTemplate struct {
+	Name   string          `json:"name"`
+	Towers []TemplateTower `json:"towers"` // placed in this order
+}
+
+// TemplateTower is one tower of a build template, DX and DY grid cells from the
+// point the template is placed at
+type TemplateTower struct {
+	TowerType string `json:"towerType"`
+	DX        int    `json:"dx"`
+	DY        int    `json:"dy"`
+}
+
+// Template returns the player's build template called name
+func (p *PlayerProgress) Template(name string) (BuildTemplate, bool) {
+	for _, t := range p.Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return BuildTemplate{}, false
+}
+
+// PlayerRepository defines the interface for player progression persistence
+type PlayerRepository interface {
+	// Get returns the progress of a player, or empty progress if none is recorded
+	Get(playerID string) (*PlayerProgress, error)
+
+	// Update applies fn to the player's progress and stores the result atomically;
+	// nothing is stored if fn returns an error
+	Update(playerID string, fn func(*PlayerProgress) error) error
+}
+
+// MemoryPlayerRepository implements in-memory player progression persistence
+type MemoryPlayerRepository struct {
+	mu      sync.RWMutex
+	players map[string]*PlayerProgress
+}
+
+// NewMemoryPlayerRepository creates a new in-memory player repository
+func NewMemoryPlayerRepository() *MemoryPlayerRepository {
+	return &MemoryPlayerRepository{
+		players: make(map[string]*PlayerProgress),
+	}
+}
+
+// Get returns a copy of the progress of a player
+func (r *MemoryPlayerRepository) Get(playerID string) (*PlayerProgress, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	progress, exists := r.players[playerID]
+	if !exists {
+		return &PlayerProgress{PlayerID: playerID, Unlocked: []string{}}, nil
+	}
+	return copyProgress(progress), nil
+}
+
+// Update applies fn to a copy of the player's progress and keeps it if fn succeeds
+func (r *MemoryPlayerRepository) Update(playerID string, fn func(*PlayerProgress) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress := &PlayerProgress{PlayerID: playerID, Unlocked: []string{}}
+	if existing, exists := r.players[playerID]; exists {
+		progress = copyProgress(existing)
+	}
+	if err := fn(progress); err != nil {
+		return err
+	}
+	progress.UpdatedAt = time.Now()
+	r.players[playerID] = progress
+	return nil
+}
+
+func copyProgress(p *PlayerProgress) *PlayerProgress {
+	result := *p
+	result.Unlocked = append([]string{}, p.Unlocked...)
+	if p.TutorialCompletedAt != nil {
+		at := *p.TutorialCompletedAt
+		result.TutorialCompletedAt = &at
+	}
+	if p.Templates != nil {
+		result.Templates = make([]BuildTemplate, len(p.Templates))
+		for i, t := range p.Templates {
+			result.Templates[i] = BuildTemplate{Name: t.Name, Towers: append([]TemplateTower{}, t.Towers...)}
+		}
+	}
+	return &result
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"time"
@@ -18,12 +19,40 @@ type GameSave struct {
 	Data      []byte    `json:"data"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Who a player save counts against, see SaveOwned; "" for saves the server
+	// keeps for itself
+	Owner string `json:"owner,omitempty"`
+
+	// Set by repositories that write saves to storage: how Data is compressed
+	// there and the hex SHA-256 of the uncompressed data, verified on load
+	Encoding string `json:"encoding,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Pinger is implemented by repositories backed by an external store, so
+// readiness probes can check that the store is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks repo if it implements Pinger; in-memory repositories are always reachable
+func Ping(ctx context.Context, repo interface{}) error {
+	if p, ok := repo.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
 }
 
 // Repository defines the interface for game persistence
 type Repository interface {
-	// Save stores a game state
+	// Save stores a game state the server keeps for itself, e.g. a checkpoint
+	// or summary; retention never evicts it
 	Save(gameID string, data []byte) (string, error)
+
+	// SaveOwned stores a save a player made, counted against owner by the
+	// retention policy
+	SaveOwned(gameID, owner string, data []byte) (string, error)
 	
 	// Load retrieves a game state by save ID
 	Load(saveID string) (*GameSave, error)
@@ -39,6 +68,12 @@ type Repository interface {
 	
 	// DeleteAll removes all saves for a game
 	DeleteAll(gameID string) error
+
+	// SetRetention sets the retention policy enforced by later player saves
+	SetRetention(p RetentionPolicy)
+
+	// GetStats returns the size of the repository and what retention evicted
+	GetStats() (RepositoryStats, error)
 }
 
 // SaveMetadata contains metadata about a game save
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// RetentionPolicy bounds the player saves a repository keeps, i.e. those
+// stored with SaveOwned; zero fields are unlimited. Saves the server keeps for
+// itself, such as checkpoints, summaries and replays, are never evicted. It
+// is enforced on SaveOwned: the saved game key is trimmed to MaxSaves and the
+// owner's saves to MaxBytes, oldest first, never evicting the newest save of
+// either, and player saves older than MaxAge are evicted.
+type RetentionPolicy struct {
+	MaxSaves int           // player saves kept per game key
+	MaxBytes int64         // stored bytes of player saves kept per owner
+	MaxAge   time.Duration // age after which a player save is evicted
+}
+
+// expired reports whether a save last updated at updatedAt is past MaxAge
+func (p RetentionPolicy) expired(updatedAt, now time.Time) bool {
+	return p.MaxAge > 0 && now.Sub(updatedAt) > p.MaxAge
+}
+
+// storedSave is what retention needs to know about one stored player save
+type storedSave struct {
+	id        string
+	size      int64
+	updatedAt time.Time
+}
+
+// evictions returns the player saves to evict besides the expired ones after
+// an owner saved to a game key, given the player saves of the key and of the
+// owner, oldest first: the oldest of the key's over MaxSaves, then the oldest
+// of the owner's over MaxBytes
+func (p RetentionPolicy) evictions(game, owner []storedSave, now time.Time) []storedSave {
+	evict := trim(p.live(game, nil, now), p.MaxSaves, 0)
+	evicted := make(map[string]bool, len(evict))
+	for _, s := range evict {
+		evicted[s.id] = true
+	}
+	return append(evict, trim(p.live(owner, evicted, now), 0, p.MaxBytes)...)
+}
+
+// live returns the saves that are neither expired nor in skip
+func (p RetentionPolicy) live(saves []storedSave, skip map[string]bool, now time.Time) []storedSave {
+	var live []storedSave
+	for _, s := range saves {
+		if !skip[s.id] && !p.expired(s.updatedAt, now) {
+			live = append(live, s)
+		}
+	}
+	return live
+}
+
+// sortStored orders saves of several game keys oldest first
+func sortStored(saves []storedSave) {
+	sort.SliceStable(saves, func(i, j int) bool { return saves[i].updatedAt.Before(saves[j].updatedAt) })
+}
+
+// trim returns the oldest of saves, listed oldest first, to drop so the rest
+// fit in maxCount saves and maxBytes bytes, 0 = unlimited; the newest is
+// always kept
+func trim(saves []storedSave, maxCount int, maxBytes int64) []storedSave {
+	count := len(saves)
+	var bytes int64
+	for _, s := range saves {
+		bytes += s.size
+	}
+
+	var evict []storedSave
+	for _, s := range saves[:max(len(saves)-1, 0)] {
+		overCount := maxCount > 0 && count > maxCount
+		overBytes := maxBytes > 0 && bytes > maxBytes
+		if !overCount && !overBytes {
+			break
+		}
+		evict = append(evict, s)
+		count--
+		bytes -= s.size
+	}
+	return evict
+}
+
+// evictionCounter counts the saves a repository evicted since it was created
+type evictionCounter struct {
+	saves atomic.Int64
+	bytes atomic.Int64
+}
+
+func (c *evictionCounter) add(s storedSave) {
+	c.saves.Add(1)
+	c.bytes.Add(s.size)
+}
+
+// fill copies the policy and eviction counts into stats
+func (c *evictionCounter) fill(stats *RepositoryStats, p RetentionPolicy) {
+	stats.EvictedSaves = int(c.saves.Load())
+	stats.EvictedBytes = int(c.bytes.Load())
+	stats.MaxSaves = p.MaxSaves
+	stats.MaxBytes = int(p.MaxBytes)
+	stats.MaxAgeSeconds = p.MaxAge.Seconds()
+}
+
+// RepositoryStats contains statistics about the repository and its retention
+type RepositoryStats struct {
+	TotalSaves int `json:"total_saves"`
+	TotalGames int `json:"total_games"`
+	TotalBytes int `json:"total_bytes"` // as stored, i.e. compressed for file and SQLite saves
+
+	EvictedSaves  int     `json:"evicted_saves"` // since the server started
+	EvictedBytes  int     `json:"evicted_bytes"`
+	MaxSaves      int     `json:"max_saves,omitempty"`
+	MaxBytes      int     `json:"max_bytes,omitempty"`
+	MaxAgeSeconds float64 `json:"max_age_seconds,omitempty"`
+}
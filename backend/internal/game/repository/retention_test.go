@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// backends returns a fresh repository of every kind
+func backends(t *testing.T) map[string]Repository {
+	t.Helper()
+	file, err := NewFileRepository(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "saves.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Repository{"memory": NewMemoryRepository(), "file": file, "sqlite": db}
+}
+
+func count(t *testing.T, repo Repository, gameID string) int {
+	t.Helper()
+	saves, err := repo.List(gameID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(saves)
+}
+
+func save(t *testing.T, repo Repository, gameID, owner string) string {
+	t.Helper()
+	id, err := repo.SaveOwned(gameID, owner, []byte(`{"wave":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRetentionSparesInternalSaves(t *testing.T) {
+	for name, repo := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo.SetRetention(RetentionPolicy{MaxSaves: 2, MaxBytes: 1})
+			for range 3 {
+				save(t, repo, "g1.checkpoints", "")
+			}
+			for range 3 {
+				save(t, repo, "g1.slots", "player:alice")
+			}
+			if n := count(t, repo, "g1.checkpoints"); n != 3 {
+				t.Errorf("checkpoints = %d, want 3", n)
+			}
+			if n := count(t, repo, "g1.slots"); n != 1 {
+				t.Errorf("slots = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestRetentionTrimsGame(t *testing.T) {
+	for name, repo := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo.SetRetention(RetentionPolicy{MaxSaves: 2})
+			save(t, repo, "g1.slots", "player:alice")
+			save(t, repo, "g1.slots", "player:bob")
+			newest := save(t, repo, "g1.slots", "player:alice")
+			if n := count(t, repo, "g1.slots"); n != 2 {
+				t.Errorf("slots = %d, want 2", n)
+			}
+			if _, err := repo.Load(newest); err != nil {
+				t.Errorf("newest save evicted: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetentionBytesPerOwner(t *testing.T) {
+	for name, repo := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo.SetRetention(RetentionPolicy{MaxBytes: 1})
+			save(t, repo, "g1.slots", "player:bob")
+			save(t, repo, "g1.slots", "player:alice")
+			newest := save(t, repo, "g2.slots", "player:alice")
+
+			// alice's older save in another game went over her quota; bob's is his own
+			if n := count(t, repo, "g1.slots"); n != 1 {
+				t.Errorf("g1 slots = %d, want bob's only", n)
+			}
+			if _, err := repo.Load(newest); err != nil {
+				t.Errorf("newest save evicted: %v", err)
+			}
+			saves, err := repo.List("g1.slots")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(saves) == 1 && saves[0].Owner != "player:bob" {
+				t.Errorf("g1 slot owner = %q, want player:bob", saves[0].Owner)
+			}
+		})
+	}
+}
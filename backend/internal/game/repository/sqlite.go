@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite" // cgo-free driver, registered as "sqlite"
+)
+
+// sqliteSchema creates the tables of every repository kept in the database
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS saves (
+	id         TEXT PRIMARY KEY,
+	game_id    TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	owner      TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS saves_game ON saves (game_id, updated_at);
+
+CREATE TABLE IF NOT EXISTS player_stats (
+	player_id TEXT PRIMARY KEY,
+	data      TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS player_progress (
+	player_id TEXT PRIMARY KEY,
+	data      TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS achievements (
+	player_id      TEXT NOT NULL,
+	achievement_id TEXT NOT NULL,
+	game_id        TEXT NOT NULL,
+	unlocked_at    INTEGER NOT NULL,
+	PRIMARY KEY (player_id, achievement_id)
+);
+`
+
+// SQLiteRepository implements game persistence in a single SQLite file, a
+// middle ground between the in-memory and file repositories for small
+// self-hosted deployments. Player stats, progression and achievements live in
+// the same file, see Stats, Players and Achievements.
+type SQLiteRepository struct {
+	db *sql.DB
+
+	mu        sync.Mutex // guards retention
+	retention RetentionPolicy
+	evicted   evictionCounter
+}
+
+// NewSQLiteRepository opens (creating if needed) the database at path
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows one writer at a time; a single connection serializes
+	// access instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	if err := migrateSaveOwner(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+// migrateSaveOwner adds the owner column to saves tables created before
+// player saves were told apart, and indexes it
+func migrateSaveOwner(db *sql.DB) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('saves') WHERE name = 'owner'`).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := db.Exec(`ALTER TABLE saves ADD COLUMN owner TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS saves_owner ON saves (owner, updated_at)`)
+	return err
+}
+
+// Close closes the database
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// Ping checks that the database can be reached
+func (r *SQLiteRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Save stores a game state
+func (r *SQLiteRepository) Save(gameID string, data []byte) (string, error) {
+	return r.SaveOwned(gameID, "", data)
+}
+
+// SaveOwned stores a player's save and enforces the retention policy
+func (r *SQLiteRepository) SaveOwned(gameID, owner string, data []byte) (string, error) {
+	saveID := uuid.New().String()
+	now := time.Now().UnixNano()
+	encoded, _, err := encodeSave(data)
+	if err != nil {
+		return "", err
+	}
+	_, err = r.db.Exec(`INSERT INTO saves (id, game_id, data, created_at, updated_at, owner) VALUES (?, ?, ?, ?, ?, ?)`,
+		saveID, gameID, encoded, now, now, owner)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert save: %w", err)
+	}
+	if owner != "" {
+		r.enforceRetention(gameID, owner, time.Unix(0, now))
+	}
+	return saveID, nil
+}
+
+// SetRetention sets the retention policy enforced by later player saves
+func (r *SQLiteRepository) SetRetention(p RetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retention = p
+}
+
+// enforceRetention evicts expired player saves and trims gameID and owner to
+// the policy. Eviction is best effort: a failed delete is tried again on the
+// next save.
+func (r *SQLiteRepository) enforceRetention(gameID, owner string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.retention
+
+	var evict []storedSave
+	var cutoff int64 // saves updated before are expired
+	if p.MaxAge > 0 {
+		cutoff = now.Add(-p.MaxAge).UnixNano()
+		expired, err := r.storedSaves(`SELECT id, length(data), updated_at FROM saves
+			WHERE owner != '' AND updated_at < ?`, cutoff)
+		if err != nil {
+			return
+		}
+		evict = append(evict, expired...)
+	}
+	game, err := r.storedSaves(`SELECT id, length(data), updated_at FROM saves
+		WHERE game_id = ? AND owner != '' AND updated_at >= ? ORDER BY updated_at`, gameID, cutoff)
+	if err != nil {
+		return
+	}
+	owned, err := r.storedSaves(`SELECT id, length(data), updated_at FROM saves
+		WHERE owner = ? AND updated_at >= ? ORDER BY updated_at`, owner, cutoff)
+	if err != nil {
+		return
+	}
+	evict = append(evict, p.evictions(game, owned, now)...)
+
+	for _, s := range evict {
+		if _, err := r.db.Exec(`DELETE FROM saves WHERE id = ?`, s.id); err == nil {
+			r.evicted.add(s)
+		}
+	}
+}
+
+// storedSaves lists the id, stored size and update time of the saves query selects
+func (r *SQLiteRepository) storedSaves(query string, args ...any) ([]storedSave, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saves []storedSave
+	for rows.Next() {
+		var s storedSave
+		var updated int64
+		if err := rows.Scan(&s.id, &s.size, &updated); err != nil {
+			return nil, err
+		}
+		s.updatedAt = time.Unix(0, updated)
+		saves = append(saves, s)
+	}
+	return saves, rows.Err()
+}
+
+// GetStats returns statistics about the repository
+func (r *SQLiteRepository) GetStats() (RepositoryStats, error) {
+	var stats RepositoryStats
+	err := r.db.QueryRow(`SELECT COUNT(*), COUNT(DISTINCT game_id), COALESCE(SUM(length(data)), 0) FROM saves`).
+		Scan(&stats.TotalSaves, &stats.TotalGames, &stats.TotalBytes)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read stats: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evicted.fill(&stats, r.retention)
+	return stats, nil
+}
+
+// Load retrieves a game state by save ID
+func (r *SQLiteRepository) Load(saveID string) (*GameSave, error) {
+	row := r.db.QueryRow(`SELECT id, game_id, data, created_at, updated_at, owner FROM saves WHERE id = ?`, saveID)
+	return scanSave(row)
+}
+
+// LoadLatest retrieves the latest save for a game
+func (r *SQLiteRepository) LoadLatest(gameID string) (*GameSave, error) {
+	row := r.db.QueryRow(`SELECT id, game_id, data, created_at, updated_at, owner FROM saves
+		WHERE game_id = ? ORDER BY updated_at DESC LIMIT 1`, gameID)
+	return scanSave(row)
+}
+
+// List returns all saves for a game, oldest first
+func (r *SQLiteRepository) List(gameID string) ([]*GameSave, error) {
+	rows, err := r.db.Query(`SELECT id, game_id, data, created_at, updated_at, owner FROM saves
+		WHERE game_id = ? ORDER BY updated_at`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saves: %w", err)
+	}
+	defer rows.Close()
+
+	saves := []*GameSave{}
+	for rows.Next() {
+		save, err := scanSave(rows)
+		if errors.Is(err, ErrInvalidData) {
+			continue // skip corrupted saves, like the file repository
+		}
+		if err != nil {
+			return nil, err
+		}
+		saves = append(saves, save)
+	}
+	return saves, rows.Err()
+}
+
+// Delete removes a save
+func (r *SQLiteRepository) Delete(saveID string) error {
+	res, err := r.db.Exec(`DELETE FROM saves WHERE id = ?`, saveID)
+	if err != nil {
+		return fmt.Errorf("failed to delete save: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSaveNotFound
+	}
+	return nil
+}
+
+// DeleteAll removes all saves for a game
+func (r *SQLiteRepository) DeleteAll(gameID string) error {
+	if _, err := r.db.Exec(`DELETE FROM saves WHERE game_id = ?`, gameID); err != nil {
+		return fmt.Errorf("failed to delete saves: %w", err)
+	}
+	return nil
+}
+
+// scanSave reads one saves row from a *sql.Row or *sql.Rows
+func scanSave(row interface{ Scan(...any) error }) (*GameSave, error) {
+	var save GameSave
+	var created, updated int64
+	err := row.Scan(&save.ID, &save.GameID, &save.Data, &created, &updated, &save.Owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSaveNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save: %w", err)
+	}
+	save.CreatedAt = time.Unix(0, created)
+	save.UpdatedAt = time.Unix(0, updated)
+	if err := save.decode(); err != nil {
+		return nil, err
+	}
+	return &save, nil
+}
+
+// Stats returns the player statistics repository stored in the same database
+func (r *SQLiteRepository) Stats() *SQLiteStatsRepository {
+	return &SQLiteStatsRepository{db: r.db}
+}
+
+// Players returns the player progression repository stored in the same database
+func (r *SQLiteRepository) Players() *SQLitePlayerRepository {
+	return &SQLitePlayerRepository{db: r.db}
+}
+
+// Achievements returns the achievement repository stored in the same database
+func (r *SQLiteRepository) Achievements() *SQLiteAchievementRepository {
+	return &SQLiteAchievementRepository{db: r.db}
+}
+
+// SQLiteStatsRepository implements player statistics persistence in SQLite
+type SQLiteStatsRepository struct {
+	db *sql.DB
+}
+
+// Ping checks that the database can be reached
+func (r *SQLiteStatsRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Get returns the stats for a player, or zero stats if none are recorded
+func (r *SQLiteStatsRepository) Get(playerID string) (*PlayerStats, error) {
+	stats := &PlayerStats{PlayerID: playerID, TowersPlaced: map[string]int{}}
+	if err := getJSON(r.db, `SELECT data FROM player_stats WHERE player_id = ?`, playerID, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Update applies fn to the player's stats and stores the result in one transaction
+func (r *SQLiteStatsRepository) Update(playerID string, fn func(*PlayerStats)) error {
+	return updateJSON(r.db, `SELECT data FROM player_stats WHERE player_id = ?`,
+		`INSERT INTO player_stats (player_id, data) VALUES (?, ?)
+		ON CONFLICT (player_id) DO UPDATE SET data = excluded.data`,
+		playerID,
+		&PlayerStats{PlayerID: playerID, TowersPlaced: map[string]int{}},
+		func(stats *PlayerStats) error {
+			fn(stats)
+			if stats.TowersPlaced == nil {
+				stats.TowersPlaced = map[string]int{}
+			}
+			stats.UpdatedAt = time.Now()
+			return nil
+		})
+}
+
+// SQLitePlayerRepository implements player progression persistence in SQLite
+type SQLitePlayerRepository struct {
+	db *sql.DB
+}
+
+// Get returns the progress of a player, or empty progress if none is recorded
+func (r *SQLitePlayerRepository) Get(playerID string) (*PlayerProgress, error) {
+	progress := &PlayerProgress{PlayerID: playerID, Unlocked: []string{}}
+	if err := getJSON(r.db, `SELECT data FROM player_progress WHERE player_id = ?`, playerID, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// Update applies fn to the player's progress and stores it if fn succeeds
+func (r *SQLitePlayerRepository) Update(playerID string, fn func(*PlayerProgress) error) error {
+	return updateJSON(r.db, `SELECT data FROM player_progress WHERE player_id = ?`,
+		`INSERT INTO player_progress (player_id, data) VALUES (?, ?)
+		ON CONFLICT (player_id) DO UPDATE SET data = excluded.data`,
+		playerID,
+		&PlayerProgress{PlayerID: playerID, Unlocked: []string{}},
+		func(progress *PlayerProgress) error {
+			if err := fn(progress); err != nil {
+				return err
+			}
+			progress.UpdatedAt = time.Now()
+			return nil
+		})
+}
+
+// SQLiteAchievementRepository implements achievement persistence in SQLite
+type SQLiteAchievementRepository struct {
+	db *sql.DB
+}
+
+// Ping checks that the database can be reached
+func (r *SQLiteAchievementRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Unlock records an achievement for a player; returns false if it was already unlocked
+func (r *SQLiteAchievementRepository) Unlock(a UnlockedAchievement) (bool, error) {
+	res, err := r.db.Exec(`INSERT OR IGNORE INTO achievements (player_id, achievement_id, game_id, unlocked_at) VALUES (?, ?, ?, ?)`,
+		a.PlayerID, a.AchievementID, a.GameID, a.UnlockedAt.UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("failed to record achievement: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListUnlocked returns all achievements unlocked by a player, in unlock order
+func (r *SQLiteAchievementRepository) ListUnlocked(playerID string) ([]UnlockedAchievement, error) {
+	rows, err := r.db.Query(`SELECT achievement_id, player_id, game_id, unlocked_at FROM achievements
+		WHERE player_id = ? ORDER BY unlocked_at`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list achievements: %w", err)
+	}
+	defer rows.Close()
+
+	result := []UnlockedAchievement{}
+	for rows.Next() {
+		var a UnlockedAchievement
+		var unlocked int64
+		if err := rows.Scan(&a.AchievementID, &a.PlayerID, &a.GameID, &unlocked); err != nil {
+			return nil, fmt.Errorf("failed to read achievement: %w", err)
+		}
+		a.UnlockedAt = time.Unix(0, unlocked)
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// getJSON decodes the JSON document selected by query into v, leaving v
+// untouched when there is no row
+func getJSON(db *sql.DB, query, key string, v any) error {
+	var data string
+	err := db.QueryRow(query, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return nil
+}
+
+// updateJSON reads the JSON document of key into empty, applies fn and writes
+// it back with upsert, all in one transaction; nothing is written if fn fails
+func updateJSON[T any](db *sql.DB, query, upsert, key string, empty *T, fn func(*T) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var data string
+	err = tx.QueryRow(query, key).Scan(&data)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	default:
+		if err := json.Unmarshal([]byte(data), empty); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+	}
+
+	if err := fn(empty); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(empty)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(upsert, key, string(encoded)); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"sync"
+	"time"
+)
+
+// PlayerStats contains lifetime statistics for a player
+type PlayerStats struct {
+	PlayerID      string         `json:"player_id"`
+	GamesPlayed   int            `json:"games_played"`
+	WavesSurvived int            `json:"waves_survived"`
+	BestWave      int            `json:"best_wave"`
+	EnemiesKilled int            `json:"enemies_killed"`
+	GoldEarned    int            `json:"gold_earned"`
+	TowersPlaced  map[string]int `json:"towers_placed"`
+	MatchesWon    int            `json:"matches_won"`  // tournament matches
+	MatchesLost   int            `json:"matches_lost"` // tournament matches, surrenders and forfeits included
+	Rating        int            `json:"rating"`       // Elo rating from tournament matches, 0 = no match played yet
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// FavoriteTower returns the tower type the player placed most often
+func (s *PlayerStats) FavoriteTower() string {
+	favorite, best := "", 0
+	for towerType, count := range s.TowersPlaced {
+		if count > best || (count == best && towerType < favorite) {
+			favorite, best = towerType, count
+		}
+	}
+	return favorite
+}
+
+// StatsRepository defines the interface for player statistics persistence
+type StatsRepository interface {
+	// Get returns the stats for a player, or zero stats if none are recorded
+	Get(playerID string) (*PlayerStats, error)
+
+	// Update applies fn to the player's stats and stores the result atomically
+	Update(playerID string, fn func(*PlayerStats)) error
+}
+
+// MemoryStatsRepository implements in-memory player statistics persistence
+type MemoryStatsRepository struct {
+	mu    sync.RWMutex
+	stats map[string]*PlayerStats
+}
+
+// NewMemoryStatsRepository creates a new in-memory stats repository
+func NewMemoryStatsRepository() *MemoryStatsRepository {
+	return &MemoryStatsRepository{
+		stats: make(map[string]*PlayerStats),
+	}
+}
+
+// Get returns a copy of the stats for a player
+func (r *MemoryStatsRepository) Get(playerID string) (*PlayerStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats, exists := r.stats[playerID]
+	if !exists {
+		return &PlayerStats{PlayerID: playerID, TowersPlaced: map[string]int{}}, nil
+	}
+	return copyStats(stats), nil
+}
+
+// Update applies fn to the player's stats
+func (r *MemoryStatsRepository) Update(playerID string, fn func(*PlayerStats)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, exists := r.stats[playerID]
+	if !exists {
+		stats = &PlayerStats{PlayerID: playerID, TowersPlaced: map[string]int{}}
+		r.stats[playerID] = stats
+	}
+	fn(stats)
+	stats.UpdatedAt = time.Now()
+	return nil
+}
+
+func copyStats(s *PlayerStats) *PlayerStats {
+	result := *s
+	result.TowersPlaced = make(map[string]int, len(s.TowersPlaced))
+	for k, v := range s.TowersPlaced {
+		result.TowersPlaced[k] = v
+	}
+	return &result
+}
@@ -0,0 +1,239 @@
+// Package research implements the tech tree players advance between games:
+// finished games earn research points, and points buy nodes that unlock tower
+// types and add global damage bonuses in later games.
+package research
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// PointsPerWave is the number of research points a player earns for every wave
+// survived in a finished game they took part in
+const PointsPerWave = 1
+
+var (
+	ErrUnknownNode         = errors.New("unknown research node")
+	ErrAlreadyUnlocked     = errors.New("research node already unlocked")
+	ErrMissingPrerequisite = errors.New("research node requires another node first")
+	ErrNotEnoughPoints     = errors.New("not enough research points")
+)
+
+// Node is one purchasable step of the tech tree
+type Node struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Cost        int      `json:"cost"`                  // research points
+	Requires    []string `json:"requires,omitempty"`    // nodes that must be unlocked first
+	UnlockTower string   `json:"unlockTower,omitempty"` // tower type locked until this node is bought
+	DamageBonus float64  `json:"damageBonus,omitempty"` // fraction added to the damage of the player's towers
+}
+
+// DefaultTree returns the built-in tech tree
+func DefaultTree() []Node {
+	return []Node{
+		{ID: "ballistics_1", Name: "Ballistics I", Description: "+5% damage for all your towers", Cost: 5, DamageBonus: 0.05},
+		{ID: "ballistics_2", Name: "Ballistics II", Description: "+10% damage for all your towers", Cost: 15, Requires: []string{"ballistics_1"}, DamageBonus: 0.10},
+		{ID: "lancer", Name: "Lancer", Description: "Unlock the lancer tower", Cost: 10, UnlockTower: "lancer"},
+		{ID: "tesla", Name: "Tesla Coil", Description: "Unlock the tesla tower", Cost: 20, Requires: []string{"lancer"}, UnlockTower: "tesla"},
+	}
+}
+
+// Status is a node as seen by a single player
+type Status struct {
+	Node
+	Unlocked  bool `json:"unlocked"`
+	Available bool `json:"available"` // prerequisites met and not unlocked yet
+}
+
+// Progress is a player's research: unspent points and the state of every node
+type Progress struct {
+	PlayerID string   `json:"playerId"`
+	Points   int      `json:"points"`
+	Nodes    []Status `json:"nodes"`
+}
+
+// session tracks who played a game and how far it got
+type session struct {
+	players        map[string]bool
+	wavesCompleted int
+}
+
+// Engine awards research points for finished games, sells tree nodes and turns
+// a player's unlocked nodes into game modifiers
+type Engine struct {
+	mu       sync.Mutex
+	tree     []Node
+	byID     map[string]Node
+	repo     repository.PlayerRepository
+	sessions map[string]*session // gameID -> participants
+}
+
+// NewEngine creates a new research engine
+func NewEngine(tree []Node, repo repository.PlayerRepository) *Engine {
+	byID := make(map[string]Node, len(tree))
+	for _, node := range tree {
+		byID[node.ID] = node
+	}
+	return &Engine{
+		tree:     tree,
+		byID:     byID,
+		repo:     repo,
+		sessions: make(map[string]*session),
+	}
+}
+
+// HandleEvent processes a game event; it is safe to register as an events.Listener
+func (e *Engine) HandleEvent(ev events.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch ev.Type {
+	case events.TowerPlaced, events.WallPlaced, events.EnemyKilled:
+		if ev.PlayerID != "" {
+			e.session(ev.GameID).players[ev.PlayerID] = true
+		}
+
+	case events.WaveCompleted:
+		s := e.session(ev.GameID)
+		if ev.Wave > s.wavesCompleted {
+			s.wavesCompleted = ev.Wave
+		}
+
+	case events.GameOver:
+		e.award(ev.GameID)
+
+	case events.GameReset:
+		// Abandoned games earn nothing
+		delete(e.sessions, ev.GameID)
+	}
+}
+
+// Tree returns the configured tech tree
+func (e *Engine) Tree() []Node {
+	return e.tree
+}
+
+// Progress returns a player's unspent points and the state of every node
+func (e *Engine) Progress(playerID string) (*Progress, error) {
+	p, err := e.repo.Get(playerID)
+	if err != nil {
+		return nil, err
+	}
+	return e.progress(p), nil
+}
+
+// Purchase spends a player's points on a node
+func (e *Engine) Purchase(playerID, nodeID string) (*Progress, error) {
+	node, ok := e.byID[nodeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNode, nodeID)
+	}
+
+	var result *Progress
+	err := e.repo.Update(playerID, func(p *repository.PlayerProgress) error {
+		if slices.Contains(p.Unlocked, nodeID) {
+			return fmt.Errorf("%w: %s", ErrAlreadyUnlocked, nodeID)
+		}
+		for _, req := range node.Requires {
+			if !slices.Contains(p.Unlocked, req) {
+				return fmt.Errorf("%w: %s needs %s", ErrMissingPrerequisite, nodeID, req)
+			}
+		}
+		if p.Points < node.Cost {
+			return fmt.Errorf("%w: %s costs %d, have %d", ErrNotEnoughPoints, nodeID, node.Cost, p.Points)
+		}
+		p.Points -= node.Cost
+		p.Unlocked = append(p.Unlocked, nodeID)
+		result = e.progress(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.Infow("research_purchased", "player_id", playerID, "node", nodeID, "points_left", result.Points)
+	return result, nil
+}
+
+// PlayerModifiers returns the game modifiers of a player's research; it
+// implements game.ModifierSource
+func (e *Engine) PlayerModifiers(playerID string) (game.PlayerModifiers, error) {
+	p, err := e.repo.Get(playerID)
+	if err != nil {
+		return game.PlayerModifiers{}, err
+	}
+
+	mods := game.PlayerModifiers{}
+	for _, node := range e.tree {
+		unlocked := slices.Contains(p.Unlocked, node.ID)
+		if node.UnlockTower != "" && !unlocked {
+			if mods.LockedTowers == nil {
+				mods.LockedTowers = make(map[string]bool)
+			}
+			mods.LockedTowers[node.UnlockTower] = true
+		}
+		if unlocked {
+			mods.DamageBonus += node.DamageBonus
+		}
+	}
+	return mods, nil
+}
+
+// progress builds the view of a player's research from stored progress
+func (e *Engine) progress(p *repository.PlayerProgress) *Progress {
+	result := &Progress{PlayerID: p.PlayerID, Points: p.Points, Nodes: make([]Status, 0, len(e.tree))}
+	for _, node := range e.tree {
+		status := Status{Node: node, Unlocked: slices.Contains(p.Unlocked, node.ID)}
+		status.Available = !status.Unlocked
+		for _, req := range node.Requires {
+			if !slices.Contains(p.Unlocked, req) {
+				status.Available = false
+			}
+		}
+		result.Nodes = append(result.Nodes, status)
+	}
+	return result
+}
+
+// session returns the participants tracker for a game (caller must hold e.mu)
+func (e *Engine) session(gameID string) *session {
+	s, ok := e.sessions[gameID]
+	if !ok {
+		s = &session{players: make(map[string]bool)}
+		e.sessions[gameID] = s
+	}
+	return s
+}
+
+// award credits the players of a finished game with research points (caller must hold e.mu)
+func (e *Engine) award(gameID string) {
+	s, ok := e.sessions[gameID]
+	if !ok {
+		return
+	}
+	delete(e.sessions, gameID)
+
+	points := s.wavesCompleted * PointsPerWave
+	if points == 0 {
+		return
+	}
+	for playerID := range s.players {
+		err := e.repo.Update(playerID, func(p *repository.PlayerProgress) error {
+			p.Points += points
+			return nil
+		})
+		if err != nil {
+			logging.Errorw("research_points_award_failed", "player_id", playerID, "game_id", gameID, "error", err)
+			continue
+		}
+		logging.Infow("research_points_awarded", "player_id", playerID, "game_id", gameID, "points", points)
+	}
+}
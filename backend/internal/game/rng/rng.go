@@ -0,0 +1,61 @@
+package rng
+
+import "math/rand"
+
+// State captures a source position so a sequence can be resumed exactly
+type State struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+// Source is a seeded math/rand source that counts draws so its state can be saved.
+// It is not safe for concurrent use, like the sources it wraps.
+type Source struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+// NewSource creates a counting source with the given seed
+func NewSource(seed int64) *Source {
+	return &Source{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+}
+
+// Restore recreates a source at a previously saved state
+func Restore(st State) *Source {
+	s := NewSource(st.Seed)
+	for s.draws < st.Draws {
+		s.Uint64()
+	}
+	return s
+}
+
+// New returns a *rand.Rand drawing from a counting source with the given seed
+func New(seed int64) (*rand.Rand, *Source) {
+	src := NewSource(seed)
+	return rand.New(src), src
+}
+
+// Int63 implements rand.Source
+func (s *Source) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+// Uint64 implements rand.Source64
+func (s *Source) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+// Seed implements rand.Source and resets the draw counter
+func (s *Source) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
+
+// State returns the current seed and draw count
+func (s *Source) State() State {
+	return State{Seed: s.seed, Draws: s.draws}
+}
@@ -0,0 +1,118 @@
+package rng
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// Named streams of a game. Each draws from its own sequence, so adding rolls
+// to one part of the simulation does not shift the others.
+const (
+	StreamSpawning = "spawning" // wave order, entrances and spawn jitter
+	StreamCrits    = "crits"    // projectile misses and critical hits
+	StreamAffixes  = "affixes"  // elite rolls and their affixes
+)
+
+// ServiceState captures the seed and the position of every stream of a Service
+type ServiceState struct {
+	Seed    int64            `json:"seed"`
+	Streams map[string]State `json:"streams"`
+}
+
+// Service hands out named random streams that all derive from one seed, so a
+// single seed reproduces a whole run. Like Source it is not safe for
+// concurrent use; a game uses it under its own lock.
+type Service struct {
+	seed    int64
+	streams map[string]*stream
+}
+
+// stream wraps the source of a named stream so it can be replaced in place:
+// the *rand.Rand handed out keeps working across Reseed and Restore
+type stream struct {
+	src  *Source
+	rand *rand.Rand
+}
+
+func (s *stream) Int63() int64    { return s.src.Int63() }
+func (s *stream) Uint64() uint64  { return s.src.Uint64() }
+func (s *stream) Seed(seed int64) { s.src.Seed(seed) }
+
+// maxSeed keeps random seeds within the integers a JSON number holds exactly,
+// so clients can hand them back unchanged
+const maxSeed = 1<<53 - 1
+
+// RandomSeed returns a seed for games that weren't given one
+func RandomSeed() int64 {
+	return time.Now().UnixNano() & maxSeed
+}
+
+// NewService creates a service whose streams derive from seed
+func NewService(seed int64) *Service {
+	return &Service{seed: seed, streams: make(map[string]*stream)}
+}
+
+// StreamSeed is the seed of a named stream of a service seeded with seed
+func StreamSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return seed ^ int64(h.Sum64())
+}
+
+// Seed returns the seed every stream derives from
+func (s *Service) Seed() int64 {
+	return s.seed
+}
+
+// Stream returns the named stream, starting it on first use
+func (s *Service) Stream(name string) *rand.Rand {
+	return s.stream(name).rand
+}
+
+func (s *Service) stream(name string) *stream {
+	st, ok := s.streams[name]
+	if !ok {
+		st = &stream{src: NewSource(StreamSeed(s.seed, name))}
+		st.rand = rand.New(st)
+		s.streams[name] = st
+	}
+	return st
+}
+
+// Reseed restarts every stream from a new seed
+func (s *Service) Reseed(seed int64) {
+	s.seed = seed
+	for name, st := range s.streams {
+		st.src = NewSource(StreamSeed(seed, name))
+	}
+}
+
+// Reset restarts every stream from the current seed, replaying the same run
+func (s *Service) Reset() {
+	s.Reseed(s.seed)
+}
+
+// State returns the seed and the position of every stream in use
+func (s *Service) State() ServiceState {
+	st := ServiceState{Seed: s.seed, Streams: make(map[string]State, len(s.streams))}
+	for name, stream := range s.streams {
+		st.Streams[name] = stream.src.State()
+	}
+	return st
+}
+
+// Restore resumes every stream from a saved state; streams missing from it
+// start over from the saved seed
+func (s *Service) Restore(st ServiceState) {
+	s.Reseed(st.Seed)
+	for name, pos := range st.Streams {
+		s.RestoreStream(name, pos)
+	}
+}
+
+// RestoreStream resumes one stream from a saved position, e.g. from saves
+// taken before streams had names
+func (s *Service) RestoreStream(name string, pos State) {
+	s.stream(name).src = Restore(pos)
+}
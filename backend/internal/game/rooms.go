@@ -0,0 +1,187 @@
+package game
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tower-defense/internal/logging"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RoomCodeLength is the number of characters of a room code
+const RoomCodeLength = 6
+
+// MaxRoomPlayers bounds the player limit a room can be created with
+const MaxRoomPlayers = 16
+
+// maxPasswordLength is the longest password bcrypt hashes in full
+const maxPasswordLength = 72
+
+var (
+	ErrRoomFull          = errors.New("room is full")
+	ErrWrongPassword     = errors.New("wrong room password")
+	ErrInvalidMaxPlayers = errors.New("invalid player limit")
+	ErrInvalidPassword   = errors.New("invalid room password")
+	ErrTooManyRooms      = errors.New("the server holds as many rooms as it may")
+)
+
+// Reasons a room was refused, see Manager.SetOnRoomRefused
+const (
+	RefusedMaxRooms     = "max_rooms"
+	RefusedMemoryBudget = "memory_budget"
+)
+
+// SetMaxRooms sets how many rooms, the default game included, the manager
+// holds at most; creating one more fails with ErrTooManyRooms. 0 = no limit.
+func (m *Manager) SetMaxRooms(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxRooms = n
+}
+
+// SetOnRoomRefused sets a function called with the reason of every room the
+// manager refuses to create, e.g. for metrics
+func (m *Manager) SetOnRoomRefused(fn func(reason string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRefused = fn
+}
+
+// roomCodeAlphabet leaves out 0/O and 1/I, which are easy to mix up when a
+// code is read out; its 32 letters divide 256, so every letter is as likely
+const roomCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// newRoomCode returns a code no room holds (caller must hold m.mu for writing)
+func (m *Manager) newRoomCode() string {
+	buf := make([]byte, RoomCodeLength)
+	for {
+		rand.Read(buf)
+		for i, b := range buf {
+			buf[i] = roomCodeAlphabet[int(b)%len(roomCodeAlphabet)]
+		}
+		if code := string(buf); m.codes[code] == "" {
+			return code
+		}
+	}
+}
+
+// NormalizeRoomCode turns a code as typed by a player, e.g. "abc 234", into
+// the form rooms are looked up by
+func NormalizeRoomCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// GameByCode returns the room holding a code; it fails with ErrGameNotFound
+func (m *Manager) GameByCode(code string) (*Game, error) {
+	code = NormalizeRoomCode(code)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	game, ok := m.games[m.codes[code]]
+	if !ok {
+		return nil, fmt.Errorf("%w: room code %s", ErrGameNotFound, code)
+	}
+	return game, nil
+}
+
+// registerCode indexes a restored room under the code it had before the
+// restart. Rooms saved without one, or whose code another room took since,
+// get a new one (caller must hold m.mu for writing).
+func (m *Manager) registerCode(game *Game, code string) {
+	if owner := m.codes[code]; code == "" || owner != "" && owner != game.id {
+		if code != "" {
+			logging.Warnw("room_code_taken", "game_id", game.id, "code", code, "taken_by", owner)
+		}
+		code = m.newRoomCode()
+	}
+	game.code = code
+	m.codes[code] = game.id
+}
+
+// Code returns the code friends join the room with, "" for the default game
+func (g *Game) Code() string {
+	return g.code
+}
+
+// Private reports whether the room is left out of the room list, so only
+// players given its code or ID can join
+func (g *Game) Private() bool {
+	return g.private
+}
+
+// MaxPlayers returns the room's player limit, 0 for none
+func (g *Game) MaxPlayers() int {
+	return g.maxPlayers
+}
+
+// HasPassword reports whether joining the room needs a password
+func (g *Game) HasPassword() bool {
+	return g.passwordHash != nil
+}
+
+// Players returns the number of players who joined the room since its last reset
+func (g *Game) Players() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.players)
+}
+
+// hashRoomPassword hashes a room password for storage; "" means no password
+func hashRoomPassword(password string) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	if len(password) > maxPasswordLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidPassword, maxPasswordLength)
+	}
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// Admit lets a player in through the front door, i.e. the join endpoint or a
+// WebSocket connection. Players who already joined are let back in; anyone
+// else needs the room's password, if it has one, and a free seat. An empty
+// player ID only watches, so it needs the password but no seat. Admit fails
+// with ErrWrongPassword or ErrRoomFull.
+func (g *Game) Admit(playerID, password string) (PlayerModifiers, error) {
+	g.mu.RLock()
+	mods, joined := g.players[playerID]
+	hash := g.passwordHash
+	g.mu.RUnlock()
+	if joined {
+		return mods, nil
+	}
+	if hash != nil && bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		logging.Warnw("room_password_rejected", "game_id", g.id, "player_id", playerID)
+		return PlayerModifiers{}, ErrWrongPassword
+	}
+	return g.join(playerID, true)
+}
+
+// Seat joins a player the server brings in itself, such as a bot: it needs a
+// free seat but no password
+func (g *Game) Seat(playerID string) error {
+	_, err := g.join(playerID, true)
+	return err
+}
+
+// member returns the modifiers of a player acting in the room. Rooms with a
+// password or a player limit only take orders from players who were admitted;
+// others fail with ErrNotJoined. Elsewhere acting joins the player.
+func (g *Game) member(playerID string) (PlayerModifiers, error) {
+	g.mu.RLock()
+	_, joined := g.players[playerID]
+	restricted := g.passwordHash != nil || g.maxPlayers > 0
+	g.mu.RUnlock()
+	if restricted && !joined && playerID != "" {
+		return PlayerModifiers{}, ErrNotJoined
+	}
+	return g.Join(playerID), nil
+}
+
+// full reports whether every seat of the room is taken (caller must hold g.mu)
+func (g *Game) full() bool {
+	return g.maxPlayers > 0 && len(g.players) >= g.maxPlayers
+}
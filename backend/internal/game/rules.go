@@ -0,0 +1,109 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"tower-defense/internal/game/config"
+)
+
+// Rule names, reported in RuleViolation
+const (
+	RuleDuringCombat = "during_combat" // the action waits until the field is clear
+	RuleDuringPhase  = "during_phase"  // the action waits for another phase of the game
+)
+
+// ErrRuleViolation matches every RuleViolation with errors.Is
+var ErrRuleViolation = errors.New("action not allowed by the game's rules")
+
+// RuleViolation is returned for an action the game's rules don't allow right now
+type RuleViolation struct {
+	Action string `json:"action"`          // one of the config actions, e.g. "sell"
+	Rule   string `json:"rule"`            // the rule that forbids it, e.g. "during_combat"
+	Phase  string `json:"phase,omitempty"` // the phase it isn't allowed in, for during_phase
+}
+
+func (v *RuleViolation) Error() string {
+	switch v.Rule {
+	case RuleDuringCombat:
+		return fmt.Sprintf("%s is not allowed while enemies are on the field", v.Action)
+	case RuleDuringPhase:
+		return fmt.Sprintf("%s is not allowed during the %s phase", v.Action, v.Phase)
+	default:
+		return fmt.Sprintf("%s is not allowed (%s)", v.Action, v.Rule)
+	}
+}
+
+// Is makes errors.Is(err, ErrRuleViolation) true for every violation
+func (v *RuleViolation) Is(target error) bool {
+	return target == ErrRuleViolation
+}
+
+// RuleContext is what the rules look at when checking an action
+type RuleContext struct {
+	InCombat bool   // enemies are on the field or still to spawn
+	Phase    string // one of config.Phases
+}
+
+// RulesEngine decides which player actions a game allows at a given moment,
+// from the rules section of its config. Every mutating Game method asks it
+// before changing anything.
+type RulesEngine struct {
+	duringCombat []string
+	byPhase      map[string][]string
+	freeSell     []string
+}
+
+// NewRulesEngine creates the rules engine for a game's rules
+func NewRulesEngine(cfg config.RulesConfig) *RulesEngine {
+	r := &RulesEngine{
+		duringCombat: slices.Clone(cfg.DuringCombat),
+		byPhase:      make(map[string][]string, len(cfg.Phases)),
+		freeSell:     slices.Clone(cfg.FreeSell),
+	}
+	for phase, actions := range cfg.Phases {
+		r.byPhase[phase] = slices.Clone(actions)
+	}
+	return r
+}
+
+// Check returns a *RuleViolation if the action isn't allowed in ctx, else nil
+func (r *RulesEngine) Check(action string, ctx RuleContext) error {
+	if ctx.InCombat && slices.Contains(r.duringCombat, action) {
+		return &RuleViolation{Action: action, Rule: RuleDuringCombat}
+	}
+	if slices.Contains(r.byPhase[ctx.Phase], action) {
+		return &RuleViolation{Action: action, Rule: RuleDuringPhase, Phase: ctx.Phase}
+	}
+	return nil
+}
+
+// Restricted lists the actions not allowed in ctx, for clients
+func (r *RulesEngine) Restricted(ctx RuleContext) []string {
+	var restricted []string
+	if ctx.InCombat {
+		restricted = slices.Clone(r.duringCombat)
+	}
+	for _, action := range r.byPhase[ctx.Phase] {
+		if !slices.Contains(restricted, action) {
+			restricted = append(restricted, action)
+		}
+	}
+	return restricted
+}
+
+// FreeSell reports whether towers sold in ctx return their full price
+func (r *RulesEngine) FreeSell(ctx RuleContext) bool {
+	return slices.Contains(r.freeSell, ctx.Phase)
+}
+
+// checkRules asks the rules engine about an action right now (caller must hold g.mu)
+func (g *Game) checkRules(action string) error {
+	return g.rules.Check(action, g.ruleContext())
+}
+
+// ruleContext describes the game for the rules engine (caller must hold g.mu)
+func (g *Game) ruleContext() RuleContext {
+	return RuleContext{InCombat: !g.waveSystem.Cleared() || g.world.EnemyCount() > 0, Phase: g.phase.name}
+}
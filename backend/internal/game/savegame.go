@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/rng"
+	"tower-defense/internal/game/systems"
+	"tower-defense/internal/logging"
+)
+
+// SimulationSaveFormat identifies full-fidelity saves
+const (
+	SimulationSaveFormat  = "simulation"
+	SimulationSaveVersion = 1
+)
+
+var ErrIncompatibleSave = errors.New("incompatible simulation save")
+
+// SimulationSave captures everything needed to resume a game exactly where it left off.
+// Unlike GameStateSnapshot it is not meant for rendering.
+type SimulationSave struct {
+	Format      string                 `json:"format"`
+	Version     int                    `json:"version"`
+	GameID      string                 `json:"gameId"`
+	MapID       string                 `json:"mapId"`
+	SavedAt     time.Time              `json:"savedAt"`
+	State       GameState              `json:"state"`
+	Towers      []ecs.TowerRecord      `json:"towers"`
+	Enemies     []ecs.EnemyRecord      `json:"enemies"`
+	Projectiles []ecs.ProjectileRecord `json:"projectiles"`
+	Walls       []ecs.WallRecord       `json:"walls,omitempty"`
+	Blueprints  []Blueprint            `json:"blueprints,omitempty"`
+	Wallets     map[string]int         `json:"wallets,omitempty"` // gold of each player, in rooms with wallets
+	Waves       systems.WaveState      `json:"waves"`
+	RNG         *rng.ServiceState      `json:"rngStreams,omitempty"` // seed and random streams; absent in older saves
+	HitRNG      *rng.State             `json:"hitRng,omitempty"`     // miss and crit rolls of older saves
+}
+
+// SaveSimulation serializes the full simulation state
+func (g *Game) SaveSimulation() ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return json.Marshal(g.simulationSave(g.world.Now()))
+}
+
+// simulationSave captures the simulation as of now (caller must hold g.mu)
+func (g *Game) simulationSave(now time.Time) SimulationSave {
+	save := SimulationSave{
+		Format:  SimulationSaveFormat,
+		Version: SimulationSaveVersion,
+		GameID:  g.id,
+		MapID:   g.mapID,
+		SavedAt: now,
+		State:   g.state,
+		Waves:   g.waveSystem.State(now),
+	}
+	rngState := g.rng.State()
+	save.RNG = &rngState
+	for _, t := range g.world.GetTowers() {
+		save.Towers = append(save.Towers, t.Record(now))
+	}
+	for _, e := range g.world.GetEnemies() {
+		save.Enemies = append(save.Enemies, e.Record())
+	}
+	for _, p := range g.world.GetProjectiles() {
+		save.Projectiles = append(save.Projectiles, p.Record())
+	}
+	for _, w := range g.world.GetWalls() {
+		save.Walls = append(save.Walls, w.Record())
+	}
+	save.Blueprints = g.copyBlueprints()
+	save.Wallets = g.walletBalances()
+	return save
+}
+
+// LoadSimulation restores a game from a full-fidelity save taken on the same map
+func (g *Game) LoadSimulation(data []byte) error {
+	var save SimulationSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		return err
+	}
+	if save.Format != SimulationSaveFormat || save.Version != SimulationSaveVersion {
+		return fmt.Errorf("%w: format %q version %d", ErrIncompatibleSave, save.Format, save.Version)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.restoreSimulation(save, g.world.Now()); err != nil {
+		return err
+	}
+	g.timeline = newTimeline()
+
+	logging.Infow("game_simulation_loaded", "game_id", g.id, "wave", save.State.Wave, "saved_at", save.SavedAt)
+
+	return nil
+}
+
+// restoreSimulation replaces the simulation with a save taken on the same map,
+// resuming its timers at now (caller must hold g.mu)
+func (g *Game) restoreSimulation(save SimulationSave, now time.Time) error {
+	if save.MapID != g.mapID {
+		return fmt.Errorf("%w: saved on map %q, game uses %q", ErrIncompatibleSave, save.MapID, g.mapID)
+	}
+
+	g.world.Clear()
+	g.markChanged()
+	g.summary = newSummary(g.id, g.mapID)
+	g.resetHeatmap()
+	g.removals.reset()
+	g.state = save.State
+	for _, r := range save.Towers {
+		g.world.AddEntity(r.Entity(now))
+	}
+	for _, r := range save.Enemies {
+		g.world.AddEntity(r.Entity())
+	}
+	for _, r := range save.Projectiles {
+		g.world.AddEntity(r.Entity())
+	}
+	for _, r := range save.Walls {
+		g.world.AddEntity(r.Entity())
+	}
+	g.blueprints = append([]Blueprint(nil), save.Blueprints...)
+	g.restoreWallets(save.Wallets)
+	g.endedAt = now
+	g.waveSystem.Restore(save.Waves, now)
+	g.resumePhase()
+	g.restoreRNG(save)
+	g.lastUpdate = now
+	return nil
+}
+
+// restoreRNG resumes the random streams of a save. Older saves had a single
+// wave RNG, whose seed becomes the game seed, and a separate hit RNG
+// (caller must hold g.mu).
+func (g *Game) restoreRNG(save SimulationSave) {
+	switch {
+	case save.RNG != nil:
+		g.rng.Restore(*save.RNG)
+	case save.Waves.RNG != nil:
+		g.rng.Reseed(save.Waves.RNG.Seed)
+		g.rng.RestoreStream(rng.StreamSpawning, *save.Waves.RNG)
+		if save.HitRNG != nil {
+			g.rng.RestoreStream(rng.StreamCrits, *save.HitRNG)
+		}
+	}
+}
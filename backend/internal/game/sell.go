@@ -0,0 +1,140 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+var (
+	ErrNotTowerOwner = errors.New("tower belongs to another player")
+	ErrSellClosed    = errors.New("towers can only be sold between waves")
+)
+
+// Sale is the outcome of selling towers
+type Sale struct {
+	TowerIDs   []string `json:"towerIds"`
+	Refund     int      `json:"refund"`     // gold returned
+	FullRefund bool     `json:"fullRefund"` // sold within the between-waves refund window
+}
+
+// SellTower sells a tower on behalf of playerID; a tower with an owner can
+// only be sold by that player
+func (g *Game) SellTower(playerID, towerID string) (*Sale, error) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	full, err := g.checkSell()
+	if err != nil {
+		return nil, err
+	}
+	entity, ok := g.world.GetEntity(towerID)
+	if !ok {
+		return nil, ErrTowerNotFound
+	}
+	tower, ok := entity.(*ecs.TowerEntity)
+	if !ok || !tower.Alive {
+		return nil, ErrTowerNotFound
+	}
+	if tower.OwnerID != "" && tower.OwnerID != playerID {
+		return nil, ErrNotTowerOwner
+	}
+
+	sale := &Sale{TowerIDs: []string{}, FullRefund: full}
+	g.sell(tower, playerID, sale)
+	return sale, nil
+}
+
+// SellAll sells every tower of playerID at once, or every unowned tower for an
+// empty player ID, e.g. to rebuild a layout from scratch between waves
+func (g *Game) SellAll(playerID string) (*Sale, error) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	full, err := g.checkSell()
+	if err != nil {
+		return nil, err
+	}
+	sale := &Sale{TowerIDs: []string{}, FullRefund: full}
+	for _, tower := range g.world.GetTowers() {
+		if tower.OwnerID == playerID {
+			g.sell(tower, playerID, sale)
+		}
+	}
+	return sale, nil
+}
+
+// checkSell rejects sales the game's rules don't allow right now and reports
+// whether they are refunded in full (caller must hold g.mu)
+func (g *Game) checkSell() (bool, error) {
+	if g.state.GameOver {
+		return false, ErrGameFinished
+	}
+	if err := g.checkRules(config.ActionSell); err != nil {
+		return false, err
+	}
+	if err := g.checkTutorial(TutorialDone); err != nil {
+		return false, err
+	}
+	full := g.refundWindow(g.world.Now()) > 0
+	if !full && g.config.Economy.SellRefund <= 0 {
+		return false, ErrSellClosed
+	}
+	return full, nil
+}
+
+// sell removes a tower and pays back its price, in full or its sell_refund
+// share, adding it to sale (caller must hold g.mu)
+func (g *Game) sell(tower *ecs.TowerEntity, playerID string, sale *Sale) {
+	refund := tower.Stats.Value
+	detail := "full"
+	if !sale.FullRefund {
+		refund = int(math.Floor(float64(tower.Stats.Value) * g.config.Economy.SellRefund))
+		detail = ""
+	}
+
+	g.world.RemoveEntity(tower.ID)
+	g.removals.mark(tower.ID, RemovalSold)
+	g.recordRemovals([]ecs.Entity{tower})
+	g.addGold(tower.OwnerID, refund) // refunds go to whoever paid for the tower
+	g.emit(events.Event{
+		Type:      events.TowerSold,
+		Wave:      g.state.Wave,
+		PlayerID:  playerID,
+		TowerID:   tower.ID,
+		TowerType: tower.TowerType,
+		Gold:      refund,
+		Detail:    detail,
+	})
+	g.markChanged()
+
+	sale.TowerIDs = append(sale.TowerIDs, tower.ID)
+	sale.Refund += refund
+	logging.Infow("tower_sold",
+		"game_id", g.id,
+		"player_id", playerID,
+		"tower_id", tower.ID,
+		"tower_type", tower.TowerType,
+		"refund", refund,
+		"full_refund", sale.FullRefund,
+		"gold_remaining", g.gold(tower.OwnerID))
+}
+
+// refundWindow is how long towers can still be sold for their full price: the
+// rest of the building time or intermission, if between-wave refunds are on or
+// the rules make the phase free to sell in (caller must hold g.mu)
+func (g *Game) refundWindow(now time.Time) time.Duration {
+	if !g.config.Economy.BetweenWaveRefunds && !g.rules.FreeSell(g.ruleContext()) {
+		return 0
+	}
+	return g.phaseEndsIn(now)
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// shutdownIndexKey is the repository key listing the games saved at the last shutdown
+const shutdownIndexKey = "shutdown"
+
+// shutdownKey is the repository key of a game's shutdown save, kept apart from player saves
+func shutdownKey(gameID string) string {
+	return gameID + ".shutdown"
+}
+
+// shutdownIndex records which games were running when the server went down
+type shutdownIndex struct {
+	SavedAt time.Time       `json:"savedAt"`
+	Games   []shutdownEntry `json:"games"`
+}
+
+type shutdownEntry struct {
+	GameID   string   `json:"gameId"`
+	MapID    string   `json:"mapId"`
+	Mutators []string `json:"mutators,omitempty"`
+
+	TickRateMs int    `json:"tickRateMs,omitempty"` // the room's own tick rate survives restarts
+	WavePacing string `json:"wavePacing,omitempty"` // and so does its wave pacing
+
+	// Friends keep joining with the same code after a restart
+	Code    string `json:"code,omitempty"`
+	Private bool   `json:"private,omitempty"`
+	Lobby   string `json:"lobby,omitempty"` // phase of the room's lobby, if it has one
+	Wallets bool   `json:"wallets,omitempty"`
+
+	MaxPlayers   int    `json:"maxPlayers,omitempty"`
+	PasswordHash []byte `json:"passwordHash,omitempty"` // bcrypt, never the password itself
+}
+
+// SaveAll stops every running game and writes a simulation save of each to repo,
+// so RestoreAll can resume them on the next start. It gives up when ctx is done
+// and returns the number of games saved.
+func (m *Manager) SaveAll(ctx context.Context, repo repository.Repository) (int, error) {
+	m.mu.RLock()
+	var running []*Game
+	for _, game := range m.games {
+		if game.Running() {
+			running = append(running, game)
+		}
+	}
+	m.mu.RUnlock()
+
+	// Stop first so the saved state is the final one
+	for _, game := range running {
+		game.Stop()
+	}
+
+	index := shutdownIndex{SavedAt: time.Now()}
+	var errs []error
+	for _, game := range running {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		data, err := game.SaveSimulation()
+		if err == nil {
+			_, err = repo.Save(shutdownKey(game.id), data)
+		}
+		if err != nil {
+			logging.Errorw("game_shutdown_save_failed", "game_id", game.id, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		entry := shutdownEntry{GameID: game.id, MapID: game.mapID, Mutators: game.mutators, TickRateMs: game.TickRateMs(), WavePacing: game.WavePacing(),
+			Code: game.code, Private: game.private, Wallets: game.Wallets(), MaxPlayers: game.maxPlayers, PasswordHash: game.passwordHash}
+		if l := game.Lobby(); l != nil {
+			entry.Lobby = l.Phase
+		}
+		index.Games = append(index.Games, entry)
+	}
+
+	data, err := json.Marshal(index)
+	if err == nil {
+		_, err = repo.Save(shutdownIndexKey, data)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	logging.Infow("games_saved_on_shutdown", "saved", len(index.Games), "running", len(running))
+	return len(index.Games), errors.Join(errs...)
+}
+
+// RestoreAll recreates the games saved by the last SaveAll and loads their state.
+// The games are not started. The shutdown saves are removed afterwards, so a
+// later crash doesn't resume the same state twice. Returns the restored games.
+func (m *Manager) RestoreAll(repo repository.Repository) ([]*Game, error) {
+	save, err := repo.LoadLatest(shutdownIndexKey)
+	if errors.Is(err, repository.ErrSaveNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index shutdownIndex
+	if err := json.Unmarshal(save.Data, &index); err != nil {
+		return nil, err
+	}
+
+	var restored []*Game
+	var errs []error
+	for _, entry := range index.Games {
+		game, err := m.restoreGame(repo, entry)
+		if err != nil {
+			logging.Errorw("game_restore_failed", "game_id", entry.GameID, "error", err)
+			errs = append(errs, err)
+		} else {
+			restored = append(restored, game)
+		}
+		if err := repo.DeleteAll(shutdownKey(entry.GameID)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := repo.DeleteAll(shutdownIndexKey); err != nil {
+		errs = append(errs, err)
+	}
+
+	logging.Infow("games_restored", "restored", len(restored), "saved", len(index.Games), "saved_at", index.SavedAt)
+	return restored, errors.Join(errs...)
+}
+
+// restoreGame recreates one game from its shutdown save
+func (m *Manager) restoreGame(repo repository.Repository, entry shutdownEntry) (*Game, error) {
+	save, err := repo.LoadLatest(shutdownKey(entry.GameID))
+	if err != nil {
+		return nil, err
+	}
+	cfg, applied, err := m.Config().WithMutators(entry.Mutators)
+	if err != nil {
+		return nil, err
+	}
+	if entry.TickRateMs >= MinTickRateMs && entry.TickRateMs <= MaxTickRateMs {
+		cfg.Game.TickRateMs = entry.TickRateMs
+	}
+	game := NewGameWithMap(entry.GameID, cfg, entry.MapID)
+	game.mutators = applied
+	if checkWavePacing(entry.WavePacing) == nil {
+		game.setWavePacing(entry.WavePacing)
+	}
+	game.private = entry.Private
+	game.maxPlayers = entry.MaxPlayers
+	game.passwordHash = entry.PasswordHash
+	if entry.Wallets {
+		game.enableWallets() // filled from the save
+	}
+	if err := game.LoadSimulation(save.Data); err != nil {
+		return nil, err
+	}
+	switch entry.Lobby {
+	case "":
+	case LobbyInProgress:
+		game.lobby = newLobby()
+		game.lobby.phase = LobbyInProgress
+	default:
+		// Players reconnect after the restart and get ready again
+		game.openLobby()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.games[entry.GameID]; ok {
+		old.Stop()
+		delete(m.codes, old.code)
+	}
+	if entry.GameID != DefaultGameID {
+		m.registerCode(game, entry.Code)
+	}
+	m.adopt(game)
+	m.games[entry.GameID] = game
+	return game, nil
+}
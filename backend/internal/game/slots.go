@@ -0,0 +1,132 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// Limits of what a player can attach to a save slot
+const (
+	MaxSlotNameLength = 64       // runes
+	MaxThumbnailBytes = 64 << 10 // e.g. a small data: URL of a screenshot
+)
+
+// ErrInvalidSlot is returned for a slot name or thumbnail over the limits
+var ErrInvalidSlot = errors.New("invalid save slot")
+
+// slotKey is the repository key of a game's named save slots, kept apart from its other saves
+func slotKey(gameID string) string {
+	return gameID + ".slots"
+}
+
+// SaveSlot is a named save of a game, listed with what it holds so players
+// can pick one without loading it
+type SaveSlot struct {
+	ID        string                   `json:"id"`
+	GameID    string                   `json:"gameId"`
+	MapID     string                   `json:"mapId"`
+	Name      string                   `json:"name"`
+	Metadata  *repository.SaveMetadata `json:"metadata"`
+	Thumbnail string                   `json:"thumbnail,omitempty"` // as provided by the client
+}
+
+// storedSlot is what a slot keeps in the repository: its description and a
+// simulation save to resume from
+type storedSlot struct {
+	SaveSlot
+	Save json.RawMessage `json:"save"`
+}
+
+// SaveToSlot stores the full simulation of the game in repo as a named slot
+// counted against owner's retention quota, and returns the slot and the
+// simulation save. An empty name defaults to the wave.
+func (g *Game) SaveToSlot(repo repository.Repository, owner, name, thumbnail string) (*SaveSlot, []byte, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > MaxSlotNameLength {
+		return nil, nil, fmt.Errorf("%w: name longer than %d characters", ErrInvalidSlot, MaxSlotNameLength)
+	}
+	if len(thumbnail) > MaxThumbnailBytes {
+		return nil, nil, fmt.Errorf("%w: thumbnail larger than %d bytes", ErrInvalidSlot, MaxThumbnailBytes)
+	}
+
+	data, err := g.SaveSimulation()
+	if err != nil {
+		return nil, nil, err
+	}
+	var save SimulationSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, nil, err
+	}
+	state, err := json.Marshal(save.State)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata, err := repository.ExtractMetadata(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata.SavedAt = save.SavedAt
+	if name == "" {
+		name = fmt.Sprintf("Wave %d", save.State.Wave)
+	}
+
+	slot := storedSlot{
+		SaveSlot: SaveSlot{GameID: g.id, MapID: save.MapID, Name: name, Metadata: metadata, Thumbnail: thumbnail},
+		Save:     data,
+	}
+	stored, err := json.Marshal(slot)
+	if err != nil {
+		return nil, nil, err
+	}
+	slot.ID, err = repo.SaveOwned(slotKey(g.id), owner, stored)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &slot.SaveSlot, data, nil
+}
+
+// ListSlots returns the save slots of a game, newest first
+func ListSlots(repo repository.Repository, gameID string) ([]SaveSlot, error) {
+	saves, err := repo.List(slotKey(gameID))
+	if err != nil {
+		return nil, err
+	}
+	slots := make([]SaveSlot, 0, len(saves))
+	for _, s := range saves {
+		var slot storedSlot
+		if err := json.Unmarshal(s.Data, &slot); err != nil {
+			continue // not a slot
+		}
+		slot.ID = s.ID
+		slots = append(slots, slot.SaveSlot)
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].Metadata.SavedAt.After(slots[j].Metadata.SavedAt) })
+	return slots, nil
+}
+
+// LoadSlot resumes the game from one of its save slots
+func (g *Game) LoadSlot(repo repository.Repository, slotID string) error {
+	s, err := repo.Load(slotID)
+	if err != nil {
+		return err
+	}
+	if s.GameID != slotKey(g.id) {
+		return repository.ErrSaveNotFound
+	}
+	var slot storedSlot
+	if err := json.Unmarshal(s.Data, &slot); err != nil {
+		return fmt.Errorf("%w: %v", repository.ErrInvalidData, err)
+	}
+	if err := g.LoadSimulation(slot.Save); err != nil {
+		return err
+	}
+	logging.Infow("game_slot_loaded", "game_id", g.id, "slot_id", slotID, "name", slot.Name)
+	return nil
+}
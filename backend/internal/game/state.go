@@ -1,48 +1,177 @@
 package game
 
-import "errors"
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
 
 var (
-	ErrNotEnoughGold    = errors.New("not enough gold")
-	ErrInvalidPlacement = errors.New("invalid tower placement")
-	ErrGameNotFound     = errors.New("game not found")
+	ErrNotEnoughGold      = errors.New("not enough gold")
+	ErrInvalidPlacement   = errors.New("invalid tower placement")
+	ErrGameNotFound       = errors.New("game not found")
+	ErrInvalidCoordinates = errors.New("coordinates must be finite numbers")
+	ErrOutOfBounds        = errors.New("coordinates outside the map")
+	ErrTowerLocked        = errors.New("tower type not unlocked")
+	ErrMaxTowers          = errors.New("tower limit reached")
 )
 
 // GameStateSnapshot represents a snapshot of the game state for serialization
 type GameStateSnapshot struct {
-	Towers      []TowerDTO      `json:"towers"`
-	Enemies     []EnemyDTO      `json:"enemies"`
-	Projectiles []ProjectileDTO `json:"projectiles"`
-	Wave        int             `json:"wave"`
-	Gold        int             `json:"gold"`
-	Lives       int             `json:"lives"`
-	Score       int             `json:"score"`
-	GameOver    bool            `json:"gameOver"`
-	Path        []PosDTO        `json:"path"`
-	MapWidth    int             `json:"mapWidth"`
-	MapHeight   int             `json:"mapHeight"`
+	Towers            []TowerDTO      `json:"towers"`
+	Enemies           []EnemyDTO      `json:"enemies"`
+	Projectiles       []ProjectileDTO `json:"projectiles"`
+	Walls             []WallDTO       `json:"walls,omitempty"`
+	Blueprints        []Blueprint     `json:"blueprints,omitempty"` // queued towers, drawn as ghosts, in build order
+	Wave              int             `json:"wave"`
+	Gold              int             `json:"gold"`
+	Lives             int             `json:"lives"`
+	Score             int             `json:"score"`
+	GameOver          bool            `json:"gameOver"`
+	Rollbacks         int             `json:"rollbacks,omitempty"` // waves retried from a checkpoint
+	Outcome           string          `json:"outcome,omitempty"`   // "won" or "lost" once the game is over
+	Errored           bool            `json:"errored,omitempty"`   // the game loop stopped after repeated crashes
+	ProjectedInterest int             `json:"projectedInterest"`
+	RefundWindow      float64         `json:"refundWindow,omitempty"` // seconds left to sell towers for their full price
+	Path              []PosDTO        `json:"path"`
+	Entrances         [][]PosDTO      `json:"entrances,omitempty"` // extra spawn paths
+	MapWidth          int             `json:"mapWidth"`
+	MapHeight         int             `json:"mapHeight"`
+
+	Version  uint64   `json:"version"`            // increases with every change, see Game.WaitForChange
+	Tick     uint64   `json:"tick"`               // simulation tick the snapshot was taken after
+	GameTime float64  `json:"gameTime"`           // simulated seconds at that tick
+	Mutators []string `json:"mutators,omitempty"` // custom rules chosen when the room was created
+
+	Tutorial *TutorialState `json:"tutorial,omitempty"` // progress of a tutorial game
+	Code     string         `json:"code,omitempty"`     // room code friends join with
+	Lobby    *LobbyState    `json:"lobby,omitempty"`    // ready-up of rooms created with a lobby
+	FogOfWar bool           `json:"fogOfWar,omitempty"` // enemies carry visibleTo; see VisibleTo
+
+	// Entities removed within game.corpse_grace_ms, so clients can animate deaths
+	Removed []RemovalDTO `json:"removed,omitempty"`
+
+	Restricted []string `json:"restricted,omitempty"` // actions the game's rules don't allow right now
+
+	// Gold of each player, keyed by player ID, in rooms with wallets; Gold is
+	// then the shared pool of gold nobody owns
+	Wallets map[string]int `json:"wallets,omitempty"`
+
+	// Highest command seq processed for each client that numbers its commands,
+	// keyed like acks' client; see Game.CommandProcessed
+	Processed map[string]uint64 `json:"processed,omitempty"`
+
+	Phase       string  `json:"phase"`                 // building, wave_active or intermission
+	PhaseEndsIn float64 `json:"phaseEndsIn,omitempty"` // seconds of building time or intermission left
+
+	Zones     []ZoneDTO     `json:"zones,omitempty"`     // terrain zones of the map
+	Obstacles []ObstacleDTO `json:"obstacles,omitempty"` // terrain towers can't shoot through
+	Weather   *WeatherDTO   `json:"weather,omitempty"`   // nil while the skies are clear
+
+	WaveProgress WaveProgressDTO `json:"waveProgress"`
+}
+
+// WaveProgressDTO tells players what the current wave still holds and when the next one comes
+type WaveProgressDTO struct {
+	NextWaveIn       float64        `json:"nextWaveIn"`       // seconds; see Pacing for what the next wave also waits for
+	Pacing           string         `json:"pacing"`           // timer: the current wave to finish spawning; after_clear, hybrid: it to be cleared
+	RemainingToSpawn int            `json:"remainingToSpawn"` // enemies of the current wave not spawned yet
+	EnemiesAlive     int            `json:"enemiesAlive"`
+	Composition      map[string]int `json:"composition"` // enemies of the current wave by type
+
+	Modifier *WaveModifierInfo `json:"modifier,omitempty"` // modifier of the current wave
+	Next     WavePreview       `json:"next"`               // the wave after the current one
+}
+
+// ZoneDTO is a rectangular terrain zone; X and Y are its top-left corner
+type ZoneDTO struct {
+	Type   string  `json:"type"` // "mud" or "high_ground"
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ObstacleDTO is a rectangle of blocking terrain; X and Y are its top-left corner
+type ObstacleDTO struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// WeatherDTO is the current weather event
+type WeatherDTO struct {
+	Type      string  `json:"type"`      // "rain"
+	Remaining float64 `json:"remaining"` // seconds until it clears
 }
 
 // TowerDTO is the data transfer object for towers
 type TowerDTO struct {
-	ID           string  `json:"id"`
-	Type         string  `json:"towerType"`
-	Position     PosDTO  `json:"position"`
-	Range        float64 `json:"range"`
-	Damage       int     `json:"damage"`
-	FireRate     float64 `json:"fireRate"`
-	SplashRadius float64 `json:"splashRadius,omitempty"`
+	ID           string   `json:"id"`
+	Type         string   `json:"towerType"`
+	Position     PosDTO   `json:"position"`
+	Range        float64  `json:"range"`
+	Damage       int      `json:"damage"`
+	FireRate     float64  `json:"fireRate"`
+	SplashRadius float64  `json:"splashRadius,omitempty"`
+	OwnerID      string   `json:"ownerId,omitempty"`
+	AuraRadius   float64  `json:"auraRadius,omitempty"` // support towers only
+	Buff         *BuffDTO `json:"buff,omitempty"`       // active aura bonuses, nil when unbuffed
+
+	Stats TowerStatsDTO `json:"stats"`
+	Ammo  *AmmoDTO      `json:"ammo,omitempty"` // nil unless the tower uses ammunition
+}
+
+// AmmoDTO is a tower's ammunition under the ammo rule
+type AmmoDTO struct {
+	Rounds   int `json:"rounds"`
+	Capacity int `json:"capacity"`
+}
+
+// TowerStatsDTO is what a tower has cost and achieved so far
+type TowerStatsDTO struct {
+	Value      int `json:"value"` // gold paid
+	Shots      int `json:"shots"`
+	Damage     int `json:"damage"`
+	Kills      int `json:"kills"`
+	GoldEarned int `json:"goldEarned"`
+}
+
+// BuffDTO lists the aura bonuses a tower currently receives, as fractions of its base stats
+type BuffDTO struct {
+	Damage   float64  `json:"damage,omitempty"`
+	FireRate float64  `json:"fireRate,omitempty"`
+	Range    float64  `json:"range,omitempty"`
+	Sources  []string `json:"sources"` // support tower IDs
 }
 
 // EnemyDTO is the data transfer object for enemies
 type EnemyDTO struct {
-	ID        string  `json:"id"`
-	Type      string  `json:"enemyType"`
-	Position  PosDTO  `json:"position"`
-	HP        int     `json:"hp"`
-	MaxHP     int     `json:"maxHp"`
-	Speed     float64 `json:"speed"`
-	PathIndex int     `json:"pathIndex"`
+	ID         string   `json:"id"`
+	Type       string   `json:"enemyType"`
+	Position   PosDTO   `json:"position"`
+	HP         int      `json:"hp"`
+	MaxHP      int      `json:"maxHp"`
+	Speed      float64  `json:"speed"`
+	PathIndex  int      `json:"pathIndex"`
+	PathID     int      `json:"pathId,omitempty"`
+	Blocked    bool     `json:"blocked,omitempty"` // stopped by a wall
+	IsBoss     bool     `json:"isBoss,omitempty"`
+	BossPhase  int      `json:"bossPhase,omitempty"`
+	Shield     int      `json:"shield,omitempty"`
+	SpeedBurst bool     `json:"speedBurst,omitempty"`
+	Abilities  []string `json:"abilities,omitempty"` // "heal", "shield", "spawn_on_death"
+	Affixes    []string `json:"affixes,omitempty"`   // elite affixes: "regenerating", "splash_resistant", "phasing"
+	Phased     bool     `json:"phased,omitempty"`    // phasing elite that towers can't target right now
+
+	Velocity VelocityDTO `json:"velocity"`           // zero while blocked
+	Waypoint *PosDTO     `json:"waypoint,omitempty"` // where the enemy turns next; don't extrapolate past it
+
+	VisibleTo []string `json:"visibleTo,omitempty"` // fog of war: players who see the enemy, "" for unowned structures
 }
 
 // ProjectileDTO is the data transfer object for projectiles
@@ -54,6 +183,38 @@ type ProjectileDTO struct {
 	Speed        float64 `json:"speed"`
 	Damage       int     `json:"damage"`
 	SplashRadius float64 `json:"splashRadius,omitempty"`
+	Behavior     string  `json:"behavior,omitempty"` // homing, beam, arc, pierce or chain
+	Origin       *PosDTO `json:"origin,omitempty"`   // non-homing shots: where it was fired from
+	Impact       *PosDTO `json:"impact,omitempty"`   // beam/arc/chain: landing point; pierce: end of the line
+	Progress     float64 `json:"progress,omitempty"` // arc: fraction of the flight done, for the shell height
+
+	Chain []PosDTO `json:"chain,omitempty"` // chain: enemies hit in order, starting with the target
+
+	Velocity VelocityDTO `json:"velocity"` // zero for instant beams and chains
+}
+
+// WallDTO is the data transfer object for walls
+type WallDTO struct {
+	ID       string  `json:"id"`
+	Position PosDTO  `json:"position"`
+	HP       float64 `json:"hp"`
+	MaxHP    float64 `json:"maxHp"`
+	Radius   float64 `json:"radius"`
+	OwnerID  string  `json:"ownerId,omitempty"`
+}
+
+// RemovalDTO reports an entity that left the world, why and when. It stays in
+// snapshots for the corpse grace period after the tick it was removed in.
+type RemovalDTO struct {
+	ID        string  `json:"id"`
+	Kind      string  `json:"kind"`   // tower, enemy, projectile or wall
+	Reason    string  `json:"reason"` // killed, leaked or expired
+	Position  PosDTO  `json:"position"`
+	EnemyType string  `json:"enemyType,omitempty"`
+	Tick      uint64  `json:"tick"`     // tick the entity was removed in
+	GameTime  float64 `json:"gameTime"` // simulated seconds at that tick
+
+	VisibleTo []string `json:"visibleTo,omitempty"` // fog of war: players who last saw the enemy
 }
 
 // PosDTO is the data transfer object for positions
@@ -62,13 +223,65 @@ type PosDTO struct {
 	Y float64 `json:"y"`
 }
 
+// VelocityDTO is a velocity in map units per second, so clients can
+// extrapolate positions between snapshots
+type VelocityDTO struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// velocityTowards is the velocity of something at pos moving towards to at
+// speed, given like entity speeds in units per 1/60 s; zero once it is there
+func velocityTowards(pos, to ecs.Position, speed float64) VelocityDTO {
+	dx, dy := to.X-pos.X, to.Y-pos.Y
+	distance := math.Hypot(dx, dy)
+	if distance < 1e-9 || speed <= 0 {
+		return VelocityDTO{}
+	}
+	scale := speed * 60 / distance
+	return VelocityDTO{X: dx * scale, Y: dy * scale}
+}
+
+// zoneDTOs converts the terrain zones of a map to DTOs
+func zoneDTOs(zones []config.ZoneConfig) []ZoneDTO {
+	if len(zones) == 0 {
+		return nil
+	}
+	dtos := make([]ZoneDTO, len(zones))
+	for i, z := range zones {
+		dtos[i] = ZoneDTO{Type: z.Type, X: z.X, Y: z.Y, Width: z.Width, Height: z.Height}
+	}
+	return dtos
+}
+
+// obstacleDTOs converts the obstacles of a map to DTOs
+func obstacleDTOs(obstacles []config.ObstacleConfig) []ObstacleDTO {
+	if len(obstacles) == 0 {
+		return nil
+	}
+	dtos := make([]ObstacleDTO, len(obstacles))
+	for i, o := range obstacles {
+		dtos[i] = ObstacleDTO{X: o.X, Y: o.Y, Width: o.Width, Height: o.Height}
+	}
+	return dtos
+}
+
+// weatherState returns the current weather or nil for clear skies (caller must hold g.mu)
+func (g *Game) weatherState() *WeatherDTO {
+	weather, remaining := g.terrainSystem.Weather()
+	if weather == "" {
+		return nil
+	}
+	return &WeatherDTO{Type: weather, Remaining: remaining}
+}
+
 // Convert ECS entities to DTOs
 func (g *Game) convertTowers() []TowerDTO {
 	towers := g.world.GetTowers()
 	dtos := make([]TowerDTO, 0, len(towers))
 	
 	for _, t := range towers {
-		dtos = append(dtos, TowerDTO{
+		dto := TowerDTO{
 			ID:           t.ID,
 			Type:         t.TowerType,
 			Position:     PosDTO{X: t.Position.X, Y: t.Position.Y},
@@ -76,7 +289,30 @@ func (g *Game) convertTowers() []TowerDTO {
 			Damage:       t.Damage,
 			FireRate:     t.FireRate,
 			SplashRadius: t.SplashRadius,
-		})
+			OwnerID:      t.OwnerID,
+			Stats: TowerStatsDTO{
+				Value:      t.Stats.Value,
+				Shots:      t.Stats.Shots,
+				Damage:     t.Stats.Damage,
+				Kills:      t.Stats.Kills,
+				GoldEarned: t.Stats.GoldEarned,
+			},
+		}
+		if t.Aura != nil {
+			dto.AuraRadius = t.Aura.Radius
+		}
+		if t.MaxAmmo > 0 {
+			dto.Ammo = &AmmoDTO{Rounds: t.Ammo, Capacity: t.MaxAmmo}
+		}
+		if len(t.Buff.Sources) > 0 {
+			dto.Buff = &BuffDTO{
+				Damage:   t.Buff.Damage,
+				FireRate: t.Buff.FireRate,
+				Range:    t.Buff.Range,
+				Sources:  t.Buff.Sources,
+			}
+		}
+		dtos = append(dtos, dto)
 	}
 	
 	return dtos
@@ -87,26 +323,69 @@ func (g *Game) convertEnemies() []EnemyDTO {
 	dtos := make([]EnemyDTO, 0, len(enemies))
 	
 	for _, e := range enemies {
-		dtos = append(dtos, EnemyDTO{
-			ID:        e.ID,
-			Type:      e.EnemyType,
-			Position:  PosDTO{X: e.Position.X, Y: e.Position.Y},
-			HP:        e.HP,
-			MaxHP:     e.MaxHP,
-			Speed:     e.Speed,
-			PathIndex: e.PathIndex,
-		})
+		_, isBoss := g.config.GetBossConfig(e.EnemyType)
+		dto := EnemyDTO{
+			ID:         e.ID,
+			Type:       e.EnemyType,
+			Position:   PosDTO{X: e.Position.X, Y: e.Position.Y},
+			HP:         e.HP,
+			MaxHP:      e.MaxHP,
+			Speed:      e.EffectiveSpeed(),
+			PathIndex:  e.PathIndex,
+			PathID:     e.PathID,
+			Blocked:    e.BlockedBy != "",
+			IsBoss:     isBoss,
+			BossPhase:  e.BossPhase,
+			Shield:     e.Shield,
+			SpeedBurst: e.SpeedBurstTimer > 0,
+			Abilities:  abilityNames(g.config.Enemies[e.EnemyType].Abilities),
+			Affixes:    e.Affixes,
+			Phased:     e.Phased,
+			VisibleTo:  e.VisibleTo,
+		}
+		if next, ok := g.movementSystem.NextWaypoint(e); ok {
+			dto.Waypoint = &PosDTO{X: next.X, Y: next.Y}
+			if e.BlockedBy == "" {
+				dto.Velocity = velocityTowards(e.Position, next, e.EffectiveSpeed())
+			}
+		}
+		dtos = append(dtos, dto)
 	}
 	
 	return dtos
 }
 
+// VisibleTo returns the snapshot as playerID sees it under fog of war: only the
+// enemies in sight of the player's structures, or of unowned ones for an empty
+// player ID, and only the removals of those enemies. Snapshots without fog of war are returned unchanged.
+func (s GameStateSnapshot) VisibleTo(playerID string) GameStateSnapshot {
+	if !s.FogOfWar {
+		return s
+	}
+	visible := make([]EnemyDTO, 0, len(s.Enemies))
+	for _, e := range s.Enemies {
+		if slices.Contains(e.VisibleTo, playerID) {
+			visible = append(visible, e)
+		}
+	}
+	s.Enemies = visible
+
+	var removed []RemovalDTO
+	for _, r := range s.Removed {
+		if r.Kind != string(ecs.EntityTypeEnemy) || slices.Contains(r.VisibleTo, playerID) {
+			removed = append(removed, r)
+		}
+	}
+	s.Removed = removed
+	return s
+}
+
 func (g *Game) convertProjectiles() []ProjectileDTO {
 	projectiles := g.world.GetProjectiles()
 	dtos := make([]ProjectileDTO, 0, len(projectiles))
 	
 	for _, p := range projectiles {
-		dtos = append(dtos, ProjectileDTO{
+		dto := ProjectileDTO{
 			ID:           p.ID,
 			Type:         p.ProjectileType,
 			Position:     PosDTO{X: p.Position.X, Y: p.Position.Y},
@@ -114,8 +393,50 @@ func (g *Game) convertProjectiles() []ProjectileDTO {
 			Speed:        p.Speed,
 			Damage:       p.Damage,
 			SplashRadius: p.SplashRadius,
-		})
+			Behavior:     p.Behavior,
+		}
+		if p.Behavior != "" && p.Behavior != config.ProjectileHoming {
+			dto.Origin = &PosDTO{X: p.Origin.X, Y: p.Origin.Y}
+			dto.Impact = &PosDTO{X: p.Impact.X, Y: p.Impact.Y}
+		}
+		for _, link := range p.Chain {
+			dto.Chain = append(dto.Chain, PosDTO{X: link.X, Y: link.Y})
+		}
+		switch p.Behavior {
+		case config.ProjectileArc, config.ProjectilePierce:
+			dto.Velocity = velocityTowards(p.Position, p.Impact, p.Speed)
+		case config.ProjectileBeam, config.ProjectileChain:
+		default:
+			if target, ok := g.world.GetEnemy(p.Target); ok && target.Alive {
+				dto.Velocity = velocityTowards(p.Position, target.Position, p.Speed)
+			}
+		}
+		if p.Behavior == config.ProjectileArc {
+			total := math.Hypot(p.Impact.X-p.Origin.X, p.Impact.Y-p.Origin.Y)
+			if total > 0 {
+				dto.Progress = math.Hypot(p.Position.X-p.Origin.X, p.Position.Y-p.Origin.Y) / total
+			}
+		}
+		dtos = append(dtos, dto)
 	}
 	
 	return dtos
 }
+
+// abilityNames lists the abilities of an enemy type for clients
+func abilityNames(a config.EnemyAbilities) []string {
+	if !a.Any() {
+		return nil
+	}
+	var names []string
+	if a.Heal != nil {
+		names = append(names, "heal")
+	}
+	if a.Shield != nil {
+		names = append(names, "shield")
+	}
+	if a.SpawnOnDeath != nil {
+		names = append(names, "spawn_on_death")
+	}
+	return names
+}
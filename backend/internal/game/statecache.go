@@ -0,0 +1,49 @@
+package game
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// stateCache holds the snapshot last encoded by MarshalState with the tick and
+// state version it was taken at. The next Update or command changes the
+// version, so a stale encoding is never returned.
+type stateCache struct {
+	mu      sync.Mutex // serializes encoding, so concurrent callers encode once
+	tick    uint64
+	version uint64
+	data    []byte
+}
+
+// MarshalState returns the game state as JSON. The encoding is cached until
+// the state changes, so callers must not modify the returned bytes.
+func (g *Game) MarshalState() ([]byte, error) {
+	g.mu.RLock()
+	tick, version := g.clock.Tick, g.version
+	g.mu.RUnlock()
+
+	c := &g.encoded
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data != nil && c.tick == tick && c.version == version {
+		return c.data, nil
+	}
+
+	state := g.GetState()
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	c.tick, c.version, c.data = state.Tick, state.Version, data
+	return data, nil
+}
+
+// MarshalStateFor returns the game state as playerID sees it under fog of war,
+// see GameStateSnapshot.VisibleTo. Without fog of war every player sees the
+// same, cached state.
+func (g *Game) MarshalStateFor(playerID string) ([]byte, error) {
+	if !g.config.Visibility.Enabled {
+		return g.MarshalState()
+	}
+	return json.Marshal(g.GetState().VisibleTo(playerID))
+}
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// benchSizes are the entity counts every benchmark runs at
+var benchSizes = []int{100, 1000, 10000}
+
+// benchGame returns a game holding n entities, loaded from a simulation save: a
+// tenth towers, half enemies and the rest homing projectiles, spread over the
+// map with a fixed seed
+func benchGame(b *testing.B, n int) *Game {
+	b.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		b.Fatal(err)
+	}
+	g := NewGame("bench", cfg)
+	data, err := g.SaveSimulation()
+	if err != nil {
+		b.Fatal(err)
+	}
+	var save SimulationSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		b.Fatal(err)
+	}
+
+	factory := ecs.NewEntityFactory(cfg)
+	r := rand.New(rand.NewSource(1))
+	randomPos := func() ecs.Position {
+		return ecs.Position{X: r.Float64() * float64(cfg.Map.Width), Y: r.Float64() * float64(cfg.Map.Height)}
+	}
+	now := time.Now()
+	towers, enemies := n/10, n/2
+	for range towers {
+		t, err := factory.CreateTower("basic", randomPos())
+		if err != nil {
+			b.Fatal(err)
+		}
+		save.Towers = append(save.Towers, t.Record(now))
+	}
+	for range enemies {
+		e, err := factory.CreateEnemy("basic", randomPos(), 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		save.Enemies = append(save.Enemies, e.Record())
+	}
+	for range n - towers - enemies {
+		target := save.Enemies[r.Intn(len(save.Enemies))].ID
+		p, err := factory.CreateProjectile("basic", randomPos(), target, 1, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		save.Projectiles = append(save.Projectiles, p.Record())
+	}
+
+	if data, err = json.Marshal(save); err != nil {
+		b.Fatal(err)
+	}
+	if err := g.LoadSimulation(data); err != nil {
+		b.Fatal(err)
+	}
+	return g
+}
+
+// benchSized runs fn as a sub-benchmark per game size
+func benchSized(b *testing.B, fn func(b *testing.B, g *Game)) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := benchGame(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			fn(b, g)
+		})
+	}
+}
+
+// BenchmarkMarshalState measures MarshalState once the state changed, i.e.
+// taking and encoding a snapshot; the change is made untimed
+func BenchmarkMarshalState(b *testing.B) {
+	benchSized(b, func(b *testing.B, g *Game) {
+		for range b.N {
+			b.StopTimer()
+			g.mu.Lock()
+			g.markChanged()
+			g.mu.Unlock()
+			b.StartTimer()
+
+			if _, err := g.MarshalState(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMarshalStateCached measures MarshalState while the state is
+// unchanged, e.g. for the second of two broadcasts in the same tick
+func BenchmarkMarshalStateCached(b *testing.B) {
+	benchSized(b, func(b *testing.B, g *Game) {
+		for range b.N {
+			if _, err := g.MarshalState(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"math"
+	"sync"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// playerTally accumulates one player's contribution to a single game
+type playerTally struct {
+	kills  int
+	gold   int
+	towers map[string]int
+}
+
+// session accumulates per-player stats for one game until it ends
+type session struct {
+	players        map[string]*playerTally
+	wavesCompleted int
+}
+
+// Aggregator folds game events into lifetime player statistics
+type Aggregator struct {
+	mu       sync.Mutex
+	repo     repository.StatsRepository
+	sessions map[string]*session // gameID -> tallies
+}
+
+// NewAggregator creates a new stats aggregator
+func NewAggregator(repo repository.StatsRepository) *Aggregator {
+	return &Aggregator{
+		repo:     repo,
+		sessions: make(map[string]*session),
+	}
+}
+
+// HandleEvent processes a game event; it is safe to register as an events.Listener
+func (a *Aggregator) HandleEvent(ev events.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch ev.Type {
+	case events.TowerPlaced:
+		if ev.PlayerID == "" {
+			return
+		}
+		a.tally(ev.GameID, ev.PlayerID).towers[ev.TowerType]++
+
+	case events.EnemyKilled:
+		if ev.PlayerID == "" {
+			return
+		}
+		t := a.tally(ev.GameID, ev.PlayerID)
+		t.kills++
+		t.gold += ev.Gold
+
+	case events.WaveCompleted:
+		s := a.session(ev.GameID)
+		if ev.Wave > s.wavesCompleted {
+			s.wavesCompleted = ev.Wave
+		}
+
+	case events.GameOver, events.GameReset:
+		a.commit(ev.GameID)
+	}
+}
+
+// Get returns lifetime stats for a player
+func (a *Aggregator) Get(playerID string) (*repository.PlayerStats, error) {
+	return a.repo.Get(playerID)
+}
+
+// commit writes the tallies of a finished game to the repository (caller must hold a.mu)
+func (a *Aggregator) commit(gameID string) {
+	s, ok := a.sessions[gameID]
+	if !ok {
+		return
+	}
+	delete(a.sessions, gameID)
+
+	for playerID, t := range s.players {
+		err := a.repo.Update(playerID, func(ps *repository.PlayerStats) {
+			ps.GamesPlayed++
+			ps.WavesSurvived += s.wavesCompleted
+			if s.wavesCompleted > ps.BestWave {
+				ps.BestWave = s.wavesCompleted
+			}
+			ps.EnemiesKilled += t.kills
+			ps.GoldEarned += t.gold
+			for towerType, count := range t.towers {
+				ps.TowersPlaced[towerType] += count
+			}
+		})
+		if err != nil {
+			logging.Errorw("player_stats_update_failed", "player_id", playerID, "game_id", gameID, "error", err)
+		}
+	}
+	logging.Infow("player_stats_committed", "game_id", gameID, "players", len(s.players))
+}
+
+// session returns the accumulator for a game (caller must hold a.mu)
+func (a *Aggregator) session(gameID string) *session {
+	s, ok := a.sessions[gameID]
+	if !ok {
+		s = &session{players: make(map[string]*playerTally)}
+		a.sessions[gameID] = s
+	}
+	return s
+}
+
+// tally returns the accumulator for a player in a game (caller must hold a.mu)
+func (a *Aggregator) tally(gameID, playerID string) *playerTally {
+	s := a.session(gameID)
+	t, ok := s.players[playerID]
+	if !ok {
+		t = &playerTally{towers: make(map[string]int)}
+		s.players[playerID] = t
+	}
+	return t
+}
+
+// Ratings start at InitialRating and move by up to ratingK points per match
+const (
+	InitialRating = 1000
+	ratingK       = 32
+	minRating     = 100
+)
+
+// RecordMatch counts a decided match for both players and updates their Elo
+// ratings
+func (a *Aggregator) RecordMatch(winner, loser string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ratings := [2]int{InitialRating, InitialRating}
+	for i, id := range []string{winner, loser} {
+		ps, err := a.repo.Get(id)
+		if err != nil {
+			logging.Errorw("player_stats_read_failed", "player_id", id, "error", err)
+			return
+		}
+		if ps.Rating > 0 {
+			ratings[i] = ps.Rating
+		}
+	}
+	expected := 1 / (1 + math.Pow(10, float64(ratings[1]-ratings[0])/400))
+	delta := int(math.Round(ratingK * (1 - expected)))
+
+	update := func(id string, won bool, rating int) {
+		err := a.repo.Update(id, func(ps *repository.PlayerStats) {
+			if won {
+				ps.MatchesWon++
+			} else {
+				ps.MatchesLost++
+			}
+			ps.Rating = max(rating, minRating)
+		})
+		if err != nil {
+			logging.Errorw("player_stats_update_failed", "player_id", id, "error", err)
+		}
+	}
+	update(winner, true, ratings[0]+delta)
+	update(loser, false, ratings[1]-delta)
+	logging.Infow("player_rating_updated", "winner", winner, "loser", loser, "delta", delta,
+		"winner_rating", ratings[0]+delta, "loser_rating", max(ratings[1]-delta, minRating))
+}
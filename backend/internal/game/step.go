@@ -0,0 +1,63 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// Step advances a game that was never started by dt simulated seconds, split
+// into ticks of equal length no longer than a live tick can be, and returns
+// how many ticks ran. The first Step stops the game's clock from following
+// the wall clock: wave timers, cooldowns and the lobby countdown only move
+// with Step from then on, so runs with the same seed, commands and steps play
+// out the same. A stepped game can't be started, and a running or finished
+// one isn't stepped.
+func (g *Game) Step(dt float64) int {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	if g.running || g.state.GameOver || !(dt > 0) {
+		return 0
+	}
+	if !g.stepped() {
+		g.stopClock()
+	}
+
+	n := int(math.Ceil(dt / maxTickDt))
+	tickDt := dt / float64(n)
+	elapsed := time.Duration(tickDt * float64(time.Second))
+	ticks := 0
+	for ; ticks < n && !g.state.GameOver; ticks++ {
+		g.stepClock = g.stepClock.Add(elapsed)
+		g.advance(g.stepClock, elapsed, tickDt)
+	}
+	return ticks
+}
+
+// StopClock stops the game's clock before the first Step, so commands given
+// before it happen at the same game time in every run. Call it right after
+// creating a game that will be stepped; it does nothing once the game runs or
+// was stepped.
+func (g *Game) StopClock() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running || g.stepped() {
+		return
+	}
+	g.stopClock()
+	// Nothing happened yet, so this only restarts the wave timers that the
+	// wall clock set when the game was created
+	g.waveSystem.Reset(g.stepClock)
+}
+
+// stopClock makes Step the only thing that moves the game's clock (caller must hold g.mu)
+func (g *Game) stopClock() {
+	g.stepClock = time.Now()
+	g.world.SetClock(func() time.Time { return g.stepClock })
+}
+
+// stepped reports whether the game is moved by Step (caller must hold g.mu)
+func (g *Game) stepped() bool {
+	return !g.stepClock.IsZero()
+}
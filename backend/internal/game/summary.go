@@ -0,0 +1,173 @@
+package game
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// Summary is the report of a finished game, stored in the save repository and
+// pushed to WebSocket clients when the game ends
+type Summary struct {
+	GameID            string         `json:"gameId"`
+	MapID             string         `json:"mapId"`
+	StartedAt         time.Time      `json:"startedAt"`
+	EndedAt           time.Time      `json:"endedAt"`
+	Reason            string         `json:"reason,omitempty"`  // why a game was ended early, e.g. "admin"
+	Outcome           string         `json:"outcome,omitempty"` // "won" or "lost"
+	Seed              int64          `json:"seed"`              // replays the run's randomness, see GameOptions.Seed
+	WavesSurvived     int            `json:"wavesSurvived"`
+	Score             int            `json:"score"`
+	GoldEarned        int            `json:"goldEarned"` // bounties, interest and wave bonuses
+	GoldSpent         int            `json:"goldSpent"`
+	DamageByTowerType map[string]int `json:"damageByTowerType"`
+	KillsByEnemyType  map[string]int `json:"killsByEnemyType"`
+	Placements        []Placement    `json:"placements"`
+}
+
+// Placement is one tower or wall built during a game
+type Placement struct {
+	Time      time.Time `json:"time"`
+	Wave      int       `json:"wave"`
+	Kind      string    `json:"kind"` // "tower" or "wall"
+	TowerType string    `json:"towerType,omitempty"`
+	PlayerID  string    `json:"playerId,omitempty"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	Cost      int       `json:"cost"`
+}
+
+// summaryKey is the repository key of a game's summaries, kept apart from its saves
+func summaryKey(gameID string) string {
+	return gameID + ".summary"
+}
+
+// LoadSummary returns the latest stored summary of a game
+func LoadSummary(repo repository.Repository, gameID string) (*Summary, error) {
+	save, err := repo.LoadLatest(summaryKey(gameID))
+	if err != nil {
+		return nil, err
+	}
+	var summary Summary
+	if err := json.Unmarshal(save.Data, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// newSummary starts the report of a game from scratch
+func newSummary(gameID, mapID string) *Summary {
+	return &Summary{
+		GameID:            gameID,
+		MapID:             mapID,
+		StartedAt:         time.Now(),
+		DamageByTowerType: map[string]int{},
+		KillsByEnemyType:  map[string]int{},
+		Placements:        []Placement{},
+	}
+}
+
+// copy returns a deep copy of the summary
+func (s *Summary) copy() *Summary {
+	c := *s
+	c.DamageByTowerType = maps.Clone(s.DamageByTowerType)
+	c.KillsByEnemyType = maps.Clone(s.KillsByEnemyType)
+	c.Placements = slices.Clone(s.Placements)
+	return &c
+}
+
+// SetSummaryRepository sets where the summaries of this game are stored; nil keeps only the latest in memory
+func (g *Game) SetSummaryRepository(repo repository.Repository) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.summaryRepo = repo
+}
+
+// Summary returns the report of the last finished game, or nil if it hasn't ended yet
+func (g *Game) Summary() *Summary {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.finished
+}
+
+// recordSummary folds an emitted event into the running summary (caller must hold g.mu)
+func (g *Game) recordSummary(ev events.Event) {
+	s := g.summary
+	switch ev.Type {
+	case events.TowerPlaced:
+		s.GoldSpent += ev.Gold
+		g.recordPlacement(ev, "tower", ev.TowerID)
+	case events.WallPlaced:
+		s.GoldSpent += ev.Gold
+		g.recordPlacement(ev, "wall", ev.EntityID)
+	case events.TowerResupplied:
+		s.GoldSpent += ev.Gold
+	case events.EnemyKilled:
+		s.KillsByEnemyType[ev.EnemyType]++
+		s.GoldEarned += ev.Gold
+	case events.InterestPaid, events.WaveBonus:
+		s.GoldEarned += ev.Gold
+	case events.WaveCompleted:
+		if ev.Wave > s.WavesSurvived {
+			s.WavesSurvived = ev.Wave
+		}
+	case events.GameOver:
+		s.EndedAt = ev.Time
+		s.Reason = ev.Detail
+		s.Outcome = ev.Outcome
+		s.Score = g.state.Score
+		s.Seed = g.rng.Seed()
+		g.finished = s
+		g.unsaved = s
+		g.summary = newSummary(g.id, g.mapID)
+	}
+}
+
+// recordPlacement adds a built tower or wall to the timeline (caller must hold g.mu)
+func (g *Game) recordPlacement(ev events.Event, kind, entityID string) {
+	p := Placement{Time: ev.Time, Wave: ev.Wave, Kind: kind, TowerType: ev.TowerType, PlayerID: ev.PlayerID, Cost: ev.Gold}
+	if entity, ok := g.world.GetEntity(entityID); ok {
+		pos := entity.GetPosition()
+		p.X, p.Y = pos.X, pos.Y
+	}
+	g.summary.Placements = append(g.summary.Placements, p)
+}
+
+// recordDamage credits damage dealt by a tower to its type, splash included (caller must hold g.mu)
+func (g *Game) recordDamage(towerID string, damage int) {
+	if damage <= 0 {
+		return
+	}
+	if entity, ok := g.world.GetEntity(towerID); ok {
+		if tower, ok := entity.(*ecs.TowerEntity); ok {
+			g.summary.DamageByTowerType[tower.TowerType] += damage
+		}
+	}
+}
+
+// storeSummary writes a just-finished summary to the repository (caller must not hold g.mu)
+func (g *Game) storeSummary() {
+	g.mu.Lock()
+	summary, repo := g.unsaved, g.summaryRepo
+	g.unsaved = nil
+	g.mu.Unlock()
+
+	if summary == nil || repo == nil {
+		return
+	}
+	data, err := json.Marshal(summary)
+	if err == nil {
+		_, err = repo.Save(summaryKey(summary.GameID), data)
+	}
+	if err != nil {
+		logging.Errorw("game_summary_save_failed", "game_id", summary.GameID, "error", err)
+		return
+	}
+	logging.Infow("game_summary_saved", "game_id", summary.GameID, "waves", summary.WavesSurvived, "score", summary.Score)
+}
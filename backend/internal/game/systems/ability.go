@@ -0,0 +1,103 @@
+package systems
+
+import (
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/logging"
+)
+
+// EnemyAbilitySystem runs the per-type enemy abilities configured under
+// enemies.<type>.abilities: healing and shielding pulses, and units released on death
+type EnemyAbilitySystem struct {
+	config      *config.GameConfig
+	factory     *ecs.EntityFactory
+	currentWave func() int
+	dead        []*ecs.EnemyEntity // killed enemies that release units on death
+}
+
+// NewEnemyAbilitySystem creates a new ability system that watches kills on bus
+func NewEnemyAbilitySystem(cfg *config.GameConfig, factory *ecs.EntityFactory, currentWave func() int, bus *Bus) *EnemyAbilitySystem {
+	s := &EnemyAbilitySystem{
+		config:      cfg,
+		factory:     factory,
+		currentWave: currentWave,
+	}
+	bus.Subscribe(EnemyKilled, s.handleKill)
+	return s
+}
+
+// Update releases units of enemies killed since the last update and fires ability pulses
+func (s *EnemyAbilitySystem) Update(world *ecs.World, dt float64) {
+	for _, enemy := range s.dead {
+		s.spawnOnDeath(world, enemy)
+	}
+	s.dead = s.dead[:0]
+
+	enemies := world.GetEnemies()
+	for _, enemy := range enemies {
+		if !enemy.Alive {
+			continue
+		}
+		abilities := s.config.Enemies[enemy.EnemyType].Abilities
+
+		if heal := abilities.Heal; heal != nil && pulse(&enemy.HealCooldown, heal.Interval, dt) {
+			for _, ally := range inRadius(enemies, enemy.Position, heal.Radius) {
+				ally.HP = min(ally.HP+heal.Amount, ally.MaxHP)
+			}
+		}
+		if shield := abilities.Shield; shield != nil && pulse(&enemy.ShieldCooldown, shield.Interval, dt) {
+			for _, ally := range inRadius(enemies, enemy.Position, shield.Radius) {
+				ally.Shield = max(ally.Shield, shield.Amount)
+			}
+		}
+	}
+}
+
+// handleKill remembers killed enemies that release units, to spawn them in Update
+func (s *EnemyAbilitySystem) handleKill(m Message) {
+	if s.config.Enemies[m.Enemy.EnemyType].Abilities.SpawnOnDeath != nil {
+		s.dead = append(s.dead, m.Enemy)
+	}
+}
+
+// spawnOnDeath releases the units of a killed enemy where it died
+func (s *EnemyAbilitySystem) spawnOnDeath(world *ecs.World, carrier *ecs.EnemyEntity) {
+	spawn := s.config.Enemies[carrier.EnemyType].Abilities.SpawnOnDeath
+	for i := 0; i < spawn.Count; i++ {
+		unit, err := s.factory.CreateEnemy(spawn.EnemyType, carrier.Position, s.currentWave())
+		if err != nil {
+			logging.Errorw("enemy_spawn_on_death_error", "type", spawn.EnemyType, "error", err)
+			return
+		}
+		// Units continue along the carrier's path
+		unit.PathID = carrier.PathID
+		unit.PathIndex = carrier.PathIndex
+		world.AddEntity(unit)
+	}
+}
+
+// pulse counts down an ability cooldown and reports whether the ability fires this tick
+func pulse(cooldown *float64, interval, dt float64) bool {
+	*cooldown -= dt
+	if *cooldown > 0 {
+		return false
+	}
+	*cooldown += interval
+	if *cooldown < 0 {
+		*cooldown = interval
+	}
+	return true
+}
+
+// inRadius returns the living enemies within radius of pos
+func inRadius(enemies []*ecs.EnemyEntity, pos ecs.Position, radius float64) []*ecs.EnemyEntity {
+	var found []*ecs.EnemyEntity
+	for _, e := range enemies {
+		if e.Alive && math.Hypot(e.Position.X-pos.X, e.Position.Y-pos.Y) <= radius {
+			found = append(found, e)
+		}
+	}
+	return found
+}
@@ -0,0 +1,44 @@
+package systems
+
+import (
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// AffixSystem runs the elite affixes that act over time: regeneration and
+// phasing. Splash resistance is applied where splash damage lands.
+type AffixSystem struct {
+	config *config.GameConfig
+}
+
+// NewAffixSystem creates a new affix system
+func NewAffixSystem(cfg *config.GameConfig) *AffixSystem {
+	return &AffixSystem{config: cfg}
+}
+
+// Update heals regenerating elites and flips phasing elites in and out of phase
+func (s *AffixSystem) Update(world *ecs.World, dt float64) {
+	affixes := s.config.Elites.Affixes
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive || len(enemy.Affixes) == 0 {
+			continue
+		}
+		if enemy.HasAffix(config.AffixRegenerating) && enemy.HP > 0 && enemy.HP < enemy.MaxHP {
+			enemy.RegenCarry += float64(enemy.MaxHP) * affixes[config.AffixRegenerating].Regen * dt
+			healed := int(enemy.RegenCarry)
+			enemy.RegenCarry -= float64(healed)
+			enemy.HP = min(enemy.HP+healed, enemy.MaxHP)
+		}
+		if enemy.HasAffix(config.AffixPhasing) {
+			rules := affixes[config.AffixPhasing]
+			enemy.PhaseTimer -= dt
+			if enemy.PhaseTimer <= 0 {
+				enemy.Phased = !enemy.Phased
+				enemy.PhaseTimer = rules.PhaseEvery
+				if enemy.Phased {
+					enemy.PhaseTimer = rules.PhaseDuration
+				}
+			}
+		}
+	}
+}
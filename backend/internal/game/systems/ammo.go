@@ -0,0 +1,32 @@
+package systems
+
+import (
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// AmmoSystem hands towers that ran out of ammunition to the resupply callback
+// while the ammo rule and automatic resupply are on
+type AmmoSystem struct {
+	config  *config.GameConfig
+	onEmpty func(tower *ecs.TowerEntity)
+}
+
+// NewAmmoSystem creates a new ammo system
+func NewAmmoSystem(cfg *config.GameConfig, onEmpty func(tower *ecs.TowerEntity)) *AmmoSystem {
+	return &AmmoSystem{config: cfg, onEmpty: onEmpty}
+}
+
+// Update requests a resupply for every empty tower. Towers stay empty when the
+// callback can't pay for any rounds and are retried on the next tick.
+func (s *AmmoSystem) Update(world *ecs.World, dt float64) {
+	rules := s.config.Ammo
+	if !rules.Enabled || !rules.AutoResupply || s.onEmpty == nil {
+		return
+	}
+	for _, tower := range world.GetTowers() {
+		if tower.OutOfAmmo() {
+			s.onEmpty(tower)
+		}
+	}
+}
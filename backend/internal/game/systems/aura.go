@@ -0,0 +1,65 @@
+package systems
+
+import (
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// AuraSystem recomputes the buffs support towers grant to shooting towers in their radius
+type AuraSystem struct {
+	config *config.GameConfig
+}
+
+// NewAuraSystem creates a new aura system
+func NewAuraSystem(cfg *config.GameConfig) *AuraSystem {
+	return &AuraSystem{config: cfg}
+}
+
+// Update clears every tower's buff and reapplies the auras covering it.
+// Support towers don't buff each other.
+func (s *AuraSystem) Update(world *ecs.World, dt float64) {
+	towers := world.GetTowers()
+
+	var supports []*ecs.TowerEntity
+	for _, tower := range towers {
+		tower.Buff = ecs.Buff{}
+		if tower.IsSupport() {
+			supports = append(supports, tower)
+		}
+	}
+	if len(supports) == 0 {
+		return
+	}
+
+	rules := s.config.Auras
+	for _, tower := range towers {
+		if tower.IsSupport() {
+			continue
+		}
+		for _, support := range supports {
+			dx := tower.Position.X - support.Position.X
+			dy := tower.Position.Y - support.Position.Y
+			if math.Sqrt(dx*dx+dy*dy) > support.Aura.Radius {
+				continue
+			}
+			tower.Buff.Damage = stack(rules, tower.Buff.Damage, support.Aura.Damage)
+			tower.Buff.FireRate = stack(rules, tower.Buff.FireRate, support.Aura.FireRate)
+			tower.Buff.Range = stack(rules, tower.Buff.Range, support.Aura.Range)
+			tower.Buff.Sources = append(tower.Buff.Sources, support.ID)
+		}
+	}
+}
+
+// stack combines the bonus accumulated so far with one more aura's bonus
+func stack(rules config.AuraRules, current, bonus float64) float64 {
+	combined := math.Max(current, bonus)
+	if rules.Stacking == config.AuraStackAdditive {
+		combined = current + bonus
+	}
+	if rules.MaxBonus > 0 && combined > rules.MaxBonus {
+		combined = rules.MaxBonus
+	}
+	return combined
+}
@@ -0,0 +1,133 @@
+package systems
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/rng"
+)
+
+// benchSizes are the entity counts every benchmark runs at
+var benchSizes = []int{100, 1000, 10000}
+
+// benchScene is a world of n entities: a tenth towers, half enemies and the
+// rest homing projectiles, spread over the map with a fixed seed. Enemies
+// survive every hit, so each iteration does the same work.
+type benchScene struct {
+	cfg         *config.GameConfig
+	world       *ecs.World
+	towers      []*ecs.TowerEntity
+	projectiles []*ecs.ProjectileEntity
+}
+
+func newBenchScene(b *testing.B, n int) *benchScene {
+	b.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		b.Fatal(err)
+	}
+	factory := ecs.NewEntityFactory(cfg)
+	r := rand.New(rand.NewSource(1))
+	randomPos := func() ecs.Position {
+		return ecs.Position{X: r.Float64() * float64(cfg.Map.Width), Y: r.Float64() * float64(cfg.Map.Height)}
+	}
+
+	s := &benchScene{cfg: cfg, world: ecs.NewWorld()}
+	towers, enemies := n/10, n/2
+	for range towers {
+		t, err := factory.CreateTower("basic", randomPos())
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.towers = append(s.towers, t)
+		s.world.AddEntity(t)
+	}
+	var targets []*ecs.EnemyEntity
+	for range enemies {
+		e, err := factory.CreateEnemy("basic", randomPos(), 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		e.HP, e.MaxHP = 1<<30, 1<<30
+		targets = append(targets, e)
+		s.world.AddEntity(e)
+	}
+	for range n - towers - enemies {
+		p, err := factory.CreateProjectile("basic", randomPos(), targets[r.Intn(len(targets))].ID, 1, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s.projectiles = append(s.projectiles, p)
+		s.world.AddEntity(p)
+	}
+	return s
+}
+
+// benchSized runs fn as a sub-benchmark per scene size
+func benchSized(b *testing.B, fn func(b *testing.B, s *benchScene)) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			s := newBenchScene(b, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			fn(b, s)
+		})
+	}
+}
+
+// BenchmarkCombatTargetAcquisition measures a combat update in which every
+// tower is ready to shoot, i.e. scans all enemies; the shots it fires are
+// removed untimed
+func BenchmarkCombatTargetAcquisition(b *testing.B) {
+	benchSized(b, func(b *testing.B, s *benchScene) {
+		for _, p := range s.projectiles {
+			p.Alive = false
+		}
+		s.world.CleanupDeadEntities()
+		combat := NewCombatSystem(s.cfg, ecs.NewEntityFactory(s.cfg))
+		combat.SetPool(NewPool(0))
+
+		for range b.N {
+			b.StopTimer()
+			for _, t := range s.towers {
+				t.LastShot = time.Time{}
+			}
+			for _, p := range s.world.GetProjectiles() {
+				p.Alive = false
+			}
+			s.world.CleanupDeadEntities()
+			b.StartTimer()
+
+			combat.Update(s.world, 1.0/60)
+		}
+	})
+}
+
+// BenchmarkProjectileUpdate measures a projectile update; projectiles are put
+// back where they started, untimed, so none of them run out
+func BenchmarkProjectileUpdate(b *testing.B) {
+	benchSized(b, func(b *testing.B, s *benchScene) {
+		start := make([]ecs.Position, len(s.projectiles))
+		for i, p := range s.projectiles {
+			start[i] = p.Position
+		}
+		projectiles := NewProjectileSystem(NewBus(), rng.NewService(1))
+		projectiles.SetPool(NewPool(0))
+
+		for range b.N {
+			b.StopTimer()
+			for i, p := range s.projectiles {
+				p.Alive = true
+				p.Position = start[i]
+				p.Age = 0
+			}
+			b.StartTimer()
+
+			projectiles.Update(s.world, 1.0/60)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package systems
+
+import (
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/logging"
+)
+
+// BossSystem runs scripted boss behaviors: phase changes, summons, shields and speed bursts
+type BossSystem struct {
+	config      *config.GameConfig
+	factory     *ecs.EntityFactory
+	currentWave func() int
+	onPhase     func(boss *ecs.EnemyEntity, phase config.BossPhase)
+}
+
+// NewBossSystem creates a new boss system
+func NewBossSystem(cfg *config.GameConfig, factory *ecs.EntityFactory, currentWave func() int) *BossSystem {
+	return &BossSystem{
+		config:      cfg,
+		factory:     factory,
+		currentWave: currentWave,
+	}
+}
+
+// SetOnPhase sets a callback invoked when a boss enters a new phase
+func (s *BossSystem) SetOnPhase(f func(boss *ecs.EnemyEntity, phase config.BossPhase)) {
+	s.onPhase = f
+}
+
+// Update advances ability timers and triggers phases for bosses below their HP thresholds
+func (s *BossSystem) Update(world *ecs.World, dt float64) {
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive || enemy.HP <= 0 {
+			continue
+		}
+		bossCfg, ok := s.config.GetBossConfig(enemy.EnemyType)
+		if !ok {
+			continue
+		}
+
+		s.tickTimers(enemy, dt)
+
+		// Phases trigger in order; a big hit may skip straight through several
+		for enemy.BossPhase < len(bossCfg.Phases) {
+			phase := bossCfg.Phases[enemy.BossPhase]
+			if enemy.GetHealthPercent() > phase.HPThreshold {
+				break
+			}
+			enemy.BossPhase++
+			s.enterPhase(world, enemy, phase)
+		}
+	}
+}
+
+// tickTimers expires shields and speed bursts
+func (s *BossSystem) tickTimers(boss *ecs.EnemyEntity, dt float64) {
+	if boss.ShieldTimer > 0 {
+		boss.ShieldTimer -= dt
+		if boss.ShieldTimer <= 0 {
+			boss.ShieldTimer = 0
+			boss.Shield = 0
+		}
+	}
+	if boss.SpeedBurstTimer > 0 {
+		boss.SpeedBurstTimer -= dt
+		if boss.SpeedBurstTimer <= 0 {
+			boss.SpeedBurstTimer = 0
+			boss.Speed = boss.BaseSpeed
+		}
+	}
+}
+
+// enterPhase applies the abilities of a boss phase
+func (s *BossSystem) enterPhase(world *ecs.World, boss *ecs.EnemyEntity, phase config.BossPhase) {
+	if phase.Shield != nil {
+		boss.Shield = phase.Shield.Amount
+		boss.ShieldTimer = phase.Shield.Duration
+	}
+
+	if phase.SpeedBurst != nil {
+		if boss.SpeedBurstTimer <= 0 {
+			boss.BaseSpeed = boss.Speed
+		}
+		boss.Speed = boss.BaseSpeed * phase.SpeedBurst.Multiplier
+		boss.SpeedBurstTimer = phase.SpeedBurst.Duration
+	}
+
+	if phase.Summon != nil {
+		for i := 0; i < phase.Summon.Count; i++ {
+			minion, err := s.factory.CreateEnemy(phase.Summon.EnemyType, boss.Position, s.currentWave())
+			if err != nil {
+				logging.Errorw("boss_summon_error", "type", phase.Summon.EnemyType, "error", err)
+				break
+			}
+			// Minions continue along the path from the boss position
+			minion.PathIndex = boss.PathIndex
+			world.AddEntity(minion)
+		}
+	}
+
+	logging.Infow("boss_phase", "enemy_id", boss.ID, "phase", phase.Name, "hp", boss.HP)
+	if s.onPhase != nil {
+		s.onPhase(boss, phase)
+	}
+}
@@ -0,0 +1,54 @@
+package systems
+
+import (
+	"tower-defense/internal/game/ecs"
+)
+
+// Topic identifies a simulation event on the Bus
+type Topic int
+
+const (
+	EnemyKilled   Topic = iota // an enemy's HP reached zero; Enemy.LastHitBy is the killing tower
+	EnemyLeaked                // an enemy reached the end of its path
+	ProjectileHit              // a projectile dealt Damage to Enemy, Splash for area hits; Crit and Miss report the roll
+	WaveCompleted              // every enemy of Wave spawned and left the field
+	WallDestroyed              // Enemy broke Wall
+)
+
+// Message is a simulation event; only the fields relevant to its Topic are set
+type Message struct {
+	Topic      Topic
+	Enemy      *ecs.EnemyEntity
+	Projectile *ecs.ProjectileEntity
+	Wall       *ecs.WallEntity
+	Damage     int
+	Splash     bool
+	Crit       bool
+	Miss       bool // the projectile landed without dealing damage; Damage is 0
+	Wave       int
+}
+
+// Bus delivers simulation events from the system that causes them to the systems
+// and game hooks that react to them. Delivery is synchronous and in subscription
+// order. The bus is only used during a tick, under the game lock, so it has no
+// locking of its own.
+type Bus struct {
+	handlers map[Topic][]func(Message)
+}
+
+// NewBus creates a bus without subscribers
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Topic][]func(Message))}
+}
+
+// Subscribe registers handler for every message on topic
+func (b *Bus) Subscribe(topic Topic, handler func(Message)) {
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+// Publish delivers m to the subscribers of its topic
+func (b *Bus) Publish(m Message) {
+	for _, h := range b.handlers[m.Topic] {
+		h(m)
+	}
+}
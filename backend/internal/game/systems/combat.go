@@ -2,6 +2,7 @@ package systems
 
 import (
 	"math"
+	"time"
 
 	"tower-defense/internal/game/config"
 	"tower-defense/internal/game/ecs"
@@ -11,6 +12,13 @@ import (
 type CombatSystem struct {
 	config  *config.GameConfig
 	factory *ecs.EntityFactory
+	pool    *Pool
+
+	// Projectiles in flight allowed, 0 = no limit; at the limit shots are
+	// resolved on the spot instead, see SetMaxProjectiles
+	maxProjectiles int
+	resolve        func(world *ecs.World, proj *ecs.ProjectileEntity)
+	hitscans       int // shots resolved on the spot, see TakeHitscans
 }
 
 // NewCombatSystem creates a new combat system
@@ -21,61 +29,135 @@ func NewCombatSystem(cfg *config.GameConfig, factory *ecs.EntityFactory) *Combat
 	}
 }
 
-// Update processes tower shooting logic
+// Update processes tower shooting logic. Towers scan for targets
+// concurrently, then fire one after the other.
 func (s *CombatSystem) Update(world *ecs.World, dt float64) {
 	towers := world.GetTowers()
 	enemies := world.GetEnemies()
+	now := world.Now()
 
-	for _, tower := range towers {
-		if !tower.Alive {
-			continue
-		}
+	inFlight := 0
+	if s.maxProjectiles > 0 {
+		inFlight = len(world.GetProjectiles())
+	}
 
-		// Check if tower can shoot
-		if !tower.CanShoot() {
-			continue
+	targets := make([]*ecs.EnemyEntity, len(towers))
+	s.pool.For(len(towers), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			targets[i] = closestTarget(towers[i], enemies, now, s.config.Map)
 		}
+	})
 
-		// Find closest enemy in range
-		var closestEnemy *ecs.EnemyEntity
-		minDist := tower.Range
+	for i, tower := range towers {
+		closestEnemy := targets[i]
 
-		for _, enemy := range enemies {
-			if !enemy.Alive {
-				continue
-			}
-
-			dx := enemy.Position.X - tower.Position.X
-			dy := enemy.Position.Y - tower.Position.Y
-			dist := math.Sqrt(dx*dx + dy*dy)
-
-			if dist < minDist {
-				minDist = dist
-				closestEnemy = enemy
-			}
-		}
-
-		// Shoot at closest enemy
-		if closestEnemy != nil {
-			// Determine projectile type based on tower type
+		// Shoot at closest enemy; one killed by an earlier shot this update
+		// needs no more when shots land at once
+		hitscan := s.maxProjectiles > 0 && inFlight >= s.maxProjectiles
+		if closestEnemy != nil && (!hitscan || closestEnemy.Alive) {
+			// Towers fire projectiles of their own type, falling back to basic
 			projType := "basic"
-			if tower.TowerType == "sniper" {
-				projType = "sniper"
-			} else if tower.TowerType == "splash" {
-				projType = "splash"
+			if _, ok := s.config.Projectiles[tower.TowerType]; ok {
+				projType = tower.TowerType
 			}
 
 			projectile, err := s.factory.CreateProjectile(
 				projType,
 				tower.Position,
 				closestEnemy.ID,
-				tower.Damage,
+				tower.EffectiveDamage(),
 				tower.SplashRadius,
 			)
 			if err == nil {
-				world.AddEntity(projectile)
-				tower.Shoot()
+				acc := s.config.GetAccuracy(tower.TowerType)
+				projectile.CritChance = acc.CritChance
+				projectile.CritMultiplier = acc.CritMultiplier
+				projectile.MissChance = acc.MissChance
+				projectile.SourceID = tower.ID
+				projectile.Impact = aim(projectile, closestEnemy, tower.EffectiveRange())
+				if hitscan {
+					s.resolve(world, projectile)
+					s.hitscans++
+				} else {
+					world.AddEntity(projectile)
+					inFlight++
+				}
+				tower.Shoot(now)
+				tower.Stats.Shots++
 			}
 		}
 	}
 }
+
+// SetMaxProjectiles limits the projectiles in flight to n, 0 for no limit.
+// Shots fired at the limit never enter the world: resolve lands them at once,
+// so towers keep dealing damage while the game allocates no more entities.
+func (s *CombatSystem) SetMaxProjectiles(n int, resolve func(world *ecs.World, proj *ecs.ProjectileEntity)) {
+	s.maxProjectiles = n
+	s.resolve = resolve
+}
+
+// TakeHitscans returns how many shots were resolved on the spot since the
+// last call, and starts counting again
+func (s *CombatSystem) TakeHitscans() int {
+	n := s.hitscans
+	s.hitscans = 0
+	return n
+}
+
+// SetPool sets the worker pool towers scan for targets on
+func (s *CombatSystem) SetPool(pool *Pool) {
+	s.pool = pool
+}
+
+// closestTarget returns the closest enemy in range and in sight of a tower that
+// is ready to shoot, or nil. It only reads, so towers can scan concurrently.
+func closestTarget(tower *ecs.TowerEntity, enemies []*ecs.EnemyEntity, now time.Time, m config.MapConfig) *ecs.EnemyEntity {
+	// Check if tower can shoot (support towers never do)
+	if !tower.Alive || !tower.CanShoot(now) {
+		return nil
+	}
+
+	// Find closest enemy in range
+	var closestEnemy *ecs.EnemyEntity
+	minDist := tower.EffectiveRange()
+
+	for _, enemy := range enemies {
+		if !enemy.Alive || enemy.Phased {
+			continue
+		}
+
+		dx := enemy.Position.X - tower.Position.X
+		dy := enemy.Position.Y - tower.Position.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+
+		if dist < minDist && m.LineOfSight(tower.Position.X, tower.Position.Y, enemy.Position.X, enemy.Position.Y) {
+			minDist = dist
+			closestEnemy = enemy
+		}
+	}
+	return closestEnemy
+}
+
+// aim returns where a shot is headed when fired: the target's current position,
+// or where the shot meets it for leading shots, and for piercing shots the
+// point at the end of the tower range in that direction
+func aim(proj *ecs.ProjectileEntity, target *ecs.EnemyEntity, towerRange float64) ecs.Position {
+	to := target.Position
+	if proj.Lead {
+		to = intercept(proj.Origin, proj.Speed, target)
+	}
+	if proj.Behavior != config.ProjectilePierce {
+		return to
+	}
+	dx := to.X - proj.Origin.X
+	dy := to.Y - proj.Origin.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist == 0 {
+		return to
+	}
+	return ecs.Position{
+		X: proj.Origin.X + dx/dist*towerRange,
+		Y: proj.Origin.Y + dy/dist*towerRange,
+	}
+}
@@ -0,0 +1,100 @@
+package systems
+
+import (
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/logging"
+)
+
+// EconomySystem handles interest on unspent gold and wave completion bonuses
+// between waves, and late-game bounty scaling
+type EconomySystem struct {
+	config      config.EconomyConfig
+	onInterest  func(gold int)
+	onWaveBonus func(gold int)
+}
+
+// NewEconomySystem creates a new economy system
+func NewEconomySystem(cfg config.EconomyConfig, onInterest func(gold int)) *EconomySystem {
+	return &EconomySystem{
+		config:     cfg,
+		onInterest: onInterest,
+	}
+}
+
+// SetConfig replaces the economy settings (used by config hot reload)
+func (s *EconomySystem) SetConfig(cfg config.EconomyConfig) {
+	s.config = cfg
+}
+
+// SetOnWaveBonus registers the callback that grants wave completion bonuses
+func (s *EconomySystem) SetOnWaveBonus(fn func(gold int)) {
+	s.onWaveBonus = fn
+}
+
+// Update is a no-op; interest and bonuses are paid from the WaveSystem end-of-wave hook
+func (s *EconomySystem) Update(world *ecs.World, dt float64) {}
+
+// ProjectedInterest returns the interest that would be paid on the given gold
+func (s *EconomySystem) ProjectedInterest(gold int) int {
+	if s.config.InterestRate <= 0 || gold <= 0 {
+		return 0
+	}
+	interest := int(float64(gold) * s.config.InterestRate)
+	if s.config.InterestCap > 0 && interest > s.config.InterestCap {
+		interest = s.config.InterestCap
+	}
+	return interest
+}
+
+// PayInterest grants interest on the given unspent gold
+func (s *EconomySystem) PayInterest(gold int) {
+	interest := s.ProjectedInterest(gold)
+	if interest <= 0 || s.onInterest == nil {
+		return
+	}
+	s.onInterest(interest)
+	logging.Debugw("interest_paid", "gold", gold, "interest", interest)
+}
+
+// WaveBonus returns the gold for completing a wave with the given lives left
+func (s *EconomySystem) WaveBonus(lives int) int {
+	if lives < 0 {
+		lives = 0
+	}
+	return s.config.WaveBonus + s.config.LifeBonus*lives
+}
+
+// PayWaveBonus grants the wave completion bonus
+func (s *EconomySystem) PayWaveBonus(lives int) {
+	bonus := s.WaveBonus(lives)
+	if bonus <= 0 || s.onWaveBonus == nil {
+		return
+	}
+	s.onWaveBonus(bonus)
+	logging.Debugw("wave_bonus_paid", "lives", lives, "bonus", bonus)
+}
+
+// BountyMultiplier returns the fraction of kill gold paid out in the given wave
+func (s *EconomySystem) BountyMultiplier(wave int) float64 {
+	b := s.config.Bounty
+	if b.StartWave <= 0 || wave < b.StartWave {
+		return 1
+	}
+	return math.Max(b.Floor, math.Pow(1-b.Decay, float64(wave-b.StartWave+1)))
+}
+
+// Bounty scales the gold reward of a kill in the given wave; a bounty never
+// drops to zero
+func (s *EconomySystem) Bounty(gold, wave int) int {
+	if gold <= 0 {
+		return gold
+	}
+	scaled := int(math.Round(float64(gold) * s.BountyMultiplier(wave)))
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
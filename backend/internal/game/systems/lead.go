@@ -0,0 +1,45 @@
+package systems
+
+import (
+	"math"
+
+	"tower-defense/internal/game/ecs"
+)
+
+// intercept returns where a shot fired from from at speed meets the target if
+// the target keeps its current velocity, both in units per 1/60 s. Targets
+// that stand still or outrun the shot are aimed at where they are.
+func intercept(from ecs.Position, speed float64, target *ecs.EnemyEntity) ecs.Position {
+	dx := target.Position.X - from.X
+	dy := target.Position.Y - from.Y
+	vx, vy := target.Velocity.X, target.Velocity.Y
+	if vx == 0 && vy == 0 {
+		return target.Position
+	}
+
+	// Solve |d + v*t| = speed*t for the earliest t > 0
+	a := vx*vx + vy*vy - speed*speed
+	b := 2 * (dx*vx + dy*vy)
+	c := dx*dx + dy*dy
+	t := -1.0
+	if math.Abs(a) < 1e-9 {
+		if b < 0 {
+			t = -c / b
+		}
+	} else if disc := b*b - 4*a*c; disc >= 0 {
+		root := math.Sqrt(disc)
+		t1, t2 := (-b-root)/(2*a), (-b+root)/(2*a)
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > 0 {
+			t = t1
+		} else if t2 > 0 {
+			t = t2
+		}
+	}
+	if t <= 0 {
+		return target.Position
+	}
+	return ecs.Position{X: target.Position.X + vx*t, Y: target.Position.Y + vy*t}
+}
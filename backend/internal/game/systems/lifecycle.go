@@ -1,44 +1,82 @@
 package systems
 
 import (
+	"tower-defense/internal/game/config"
 	"tower-defense/internal/game/ecs"
 	"tower-defense/internal/logging"
 )
 
-// LifecycleSystem handles entity cleanup and life loss
+// Reasons passed to the life gain callback
+const (
+	LifeRegen = "regen" // restored after every lives.regen_every waves
+	LifeDrop  = "drop"  // dropped by a killed enemy with a life_reward
+)
+
+// LifecycleSystem handles entity cleanup, life loss and life gains
 type LifecycleSystem struct {
-	onLifeLost func(lives int)
-	pathLength int
+	config       *config.GameConfig
+	onLifeLost   func(lives int)
+	onLifeGained func(lives int, reason string, enemy *ecs.EnemyEntity)
+	onRemoved    func(removed []ecs.Entity)
 }
 
-// NewLifecycleSystem creates a new lifecycle system
-func NewLifecycleSystem(pathLength int, onLifeLost func(lives int)) *LifecycleSystem {
-	return &LifecycleSystem{
+// NewLifecycleSystem creates a new lifecycle system subscribed to leaks, kills
+// and cleared waves on bus
+func NewLifecycleSystem(cfg *config.GameConfig, bus *Bus, onLifeLost func(lives int)) *LifecycleSystem {
+	s := &LifecycleSystem{
+		config:     cfg,
 		onLifeLost: onLifeLost,
-		pathLength: pathLength,
 	}
+	bus.Subscribe(EnemyLeaked, s.handleLeak)
+	bus.Subscribe(EnemyKilled, s.handleKill)
+	bus.Subscribe(WaveCompleted, s.handleWaveCompleted)
+	return s
 }
 
-// Update cleans up dead entities and handles enemies reaching the end
-func (s *LifecycleSystem) Update(world *ecs.World, dt float64) {
-	enemies := world.GetEnemies()
-
-	// Check for enemies that reached the end
-	for _, enemy := range enemies {
-		// Enemy reaches end when at or past the last path point (PathIndex is 0-based)
-		// pathLength is the number of points, so last valid index is pathLength-1
-		if enemy.Alive && enemy.PathIndex >= s.pathLength-1 {
-			enemy.Alive = false
-			if s.onLifeLost != nil {
-				s.onLifeLost(1)
-				logging.Warnw("enemy_reached_end", "enemy_id", enemy.ID, "path_index", enemy.PathIndex)
-			}
-		}
-	}
+// SetOnLifeGained sets the callback for regenerated and dropped lives. The
+// callback enforces lives.max_lives; enemy is nil for regenerations.
+func (s *LifecycleSystem) SetOnLifeGained(f func(lives int, reason string, enemy *ecs.EnemyEntity)) {
+	s.onLifeGained = f
+}
+
+// SetOnRemoved sets the callback for the dead entities removed from the world
+// each tick
+func (s *LifecycleSystem) SetOnRemoved(f func(removed []ecs.Entity)) {
+	s.onRemoved = f
+}
 
-	// Clean up dead entities
+// Update cleans up dead entities
+func (s *LifecycleSystem) Update(world *ecs.World, dt float64) {
 	removed := world.CleanupDeadEntities()
 	if len(removed) > 0 {
 		logging.Debugw("entities_cleaned", "count", len(removed))
+		if s.onRemoved != nil {
+			s.onRemoved(removed)
+		}
+	}
+}
+
+// handleLeak takes a life for an enemy that reached the end of its path
+func (s *LifecycleSystem) handleLeak(m Message) {
+	logging.Warnw("enemy_reached_end", "enemy_id", m.Enemy.ID, "path_index", m.Enemy.PathIndex)
+	if s.onLifeLost != nil {
+		s.onLifeLost(1)
+	}
+}
+
+// handleKill grants the lives dropped by a killed enemy
+func (s *LifecycleSystem) handleKill(m Message) {
+	lives := s.config.Enemies[m.Enemy.EnemyType].LifeReward
+	if lives > 0 && s.onLifeGained != nil {
+		s.onLifeGained(lives, LifeDrop, m.Enemy)
+	}
+}
+
+// handleWaveCompleted regenerates lives every lives.regen_every waves
+func (s *LifecycleSystem) handleWaveCompleted(m Message) {
+	every := s.config.Lives.RegenEvery
+	if every <= 0 || m.Wave%every != 0 || s.onLifeGained == nil {
+		return
 	}
+	s.onLifeGained(s.config.Lives.RegenAmount, LifeRegen, nil)
 }
@@ -10,70 +10,150 @@ import (
 // MovementSystem handles enemy movement along the path
 type MovementSystem struct {
 	config *config.GameConfig
-	path   []ecs.Position
+	bus    *Bus
+	paths  [][]ecs.Position // main path first, then entrance paths
+	pool   *Pool
 }
 
-// NewMovementSystem creates a new movement system
-func NewMovementSystem(cfg *config.GameConfig) *MovementSystem {
+// NewMovementSystem creates a new movement system that publishes leaks on bus
+func NewMovementSystem(cfg *config.GameConfig, bus *Bus) *MovementSystem {
 	// Convert config positions to ecs positions
-	path := make([]ecs.Position, len(cfg.Map.Path))
-	for i, p := range cfg.Map.Path {
-		path[i] = ecs.Position{X: p.X, Y: p.Y}
+	mapPaths := cfg.Map.AllPaths()
+	paths := make([][]ecs.Position, len(mapPaths))
+	for i, mp := range mapPaths {
+		paths[i] = make([]ecs.Position, len(mp))
+		for j, p := range mp {
+			paths[i][j] = ecs.Position{X: p.X, Y: p.Y}
+		}
 	}
 	
 	return &MovementSystem{
 		config: cfg,
-		path:   path,
+		bus:    bus,
+		paths:  paths,
 	}
 }
 
-// Update moves all enemies along the path
+// Update moves all enemies along the path. Enemies with a wall right ahead of
+// them stay put and record it in BlockedBy for the WallSystem. Enemies move
+// concurrently; leaks are published afterwards.
 func (s *MovementSystem) Update(world *ecs.World, dt float64) {
 	enemies := world.GetEnemies()
+	walls := world.GetWalls()
 	
-	for _, enemy := range enemies {
-		if !enemy.Alive {
-			continue
-		}
-		
-		// Check if enemy is beyond the path (let LifecycleSystem handle this)
-		if enemy.PathIndex >= len(s.path)-1 {
-			// Don't set Alive = false here - let LifecycleSystem handle life loss
-			continue
-		}
-		
-		target := s.path[enemy.PathIndex+1]
-		current := enemy.Position
-		
-		// Calculate direction
-		dx := target.X - current.X
-		dy := target.Y - current.Y
-		distance := math.Sqrt(dx*dx + dy*dy)
-		
-		if distance < 1.0 {
-			// Reached waypoint, move to next
-			enemy.PathIndex++
-			// Don't set Alive = false here - let LifecycleSystem handle it
-			continue
+	leaked := make([]bool, len(enemies))
+	s.pool.For(len(enemies), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			leaked[i] = s.move(enemies[i], walls, dt)
 		}
-		
-		// Move towards target
-		moveDistance := enemy.Speed * dt * 60.0 // Normalize to 60 FPS
-		if moveDistance > distance {
-			moveDistance = distance
+	})
+	for i, enemy := range enemies {
+		if leaked[i] {
+			s.leak(enemy)
 		}
-		
-		ratio := moveDistance / distance
-		newPos := ecs.Position{
-			X: current.X + dx*ratio,
-			Y: current.Y + dy*ratio,
+	}
+}
+
+// SetPool sets the worker pool enemies are moved on
+func (s *MovementSystem) SetPool(pool *Pool) {
+	s.pool = pool
+}
+
+// move advances one enemy and reports whether it reached the end of its path.
+// It only writes to the enemy, so enemies can be moved concurrently.
+func (s *MovementSystem) move(enemy *ecs.EnemyEntity, walls []*ecs.WallEntity, dt float64) bool {
+	if !enemy.Alive {
+		return false
+	}
+	
+	path := s.pathFor(enemy)
+	
+	// An enemy at the last waypoint has leaked
+	if enemy.PathIndex >= len(path)-1 {
+		return true
+	}
+	
+	target := path[enemy.PathIndex+1]
+	current := enemy.Position
+	
+	// Calculate direction
+	dx := target.X - current.X
+	dy := target.Y - current.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+	
+	if distance < 1.0 {
+		// Reached waypoint, move to next
+		enemy.PathIndex++
+		return enemy.PathIndex >= len(path)-1
+	}
+	
+	enemy.BlockedBy = ""
+	if wall := blockingWall(walls, enemy, dx, dy); wall != nil {
+		enemy.BlockedBy = wall.ID
+		enemy.Velocity = ecs.Position{}
+		return false
+	}
+	
+	// Move towards target
+	enemy.Velocity = ecs.Position{X: dx / distance * enemy.EffectiveSpeed(), Y: dy / distance * enemy.EffectiveSpeed()}
+	moveDistance := enemy.EffectiveSpeed() * dt * 60.0 // Normalize to 60 FPS
+	if moveDistance > distance {
+		moveDistance = distance
+	}
+	
+	ratio := moveDistance / distance
+	newPos := ecs.Position{
+		X: current.X + dx*ratio,
+		Y: current.Y + dy*ratio,
+	}
+	
+	enemy.SetPosition(newPos)
+	return false
+}
+
+// leak removes an enemy that reached the end of its path and publishes EnemyLeaked
+func (s *MovementSystem) leak(enemy *ecs.EnemyEntity) {
+	enemy.Alive = false
+	s.bus.Publish(Message{Topic: EnemyLeaked, Enemy: enemy})
+}
+
+// blockingWall returns the wall within reach in the enemy's direction of travel (dx, dy), if any.
+// Walls behind the enemy, e.g. built on top of it, don't block.
+func blockingWall(walls []*ecs.WallEntity, enemy *ecs.EnemyEntity, dx, dy float64) *ecs.WallEntity {
+	for _, wall := range walls {
+		wx := wall.Position.X - enemy.Position.X
+		wy := wall.Position.Y - enemy.Position.Y
+		if wx*wx+wy*wy <= wall.Radius*wall.Radius && wx*dx+wy*dy > 0 {
+			return wall
 		}
-		
-		enemy.SetPosition(newPos)
 	}
+	return nil
 }
 
-// GetPath returns the path for external use
+// GetPath returns the main path for external use
 func (s *MovementSystem) GetPath() []ecs.Position {
-	return s.path
+	return s.paths[0]
+}
+
+// GetPaths returns every path (main path first) for external use
+func (s *MovementSystem) GetPaths() [][]ecs.Position {
+	return s.paths
+}
+
+// NextWaypoint returns the waypoint an enemy is heading for, or false once it is
+// at the end of its path
+func (s *MovementSystem) NextWaypoint(enemy *ecs.EnemyEntity) (ecs.Position, bool) {
+	path := s.pathFor(enemy)
+	if enemy.PathIndex+1 >= len(path) {
+		return ecs.Position{}, false
+	}
+	return path[enemy.PathIndex+1], true
+}
+
+// pathFor returns the path an enemy follows, falling back to the main path
+func (s *MovementSystem) pathFor(enemy *ecs.EnemyEntity) []ecs.Position {
+	if enemy.PathID > 0 && enemy.PathID < len(s.paths) {
+		return s.paths[enemy.PathID]
+	}
+	return s.paths[0]
 }
@@ -0,0 +1,111 @@
+package systems
+
+import (
+	"runtime"
+	"sync"
+
+	"tower-defense/internal/flags"
+)
+
+// ParallelSystems switches every pool to running its tasks on the calling
+// goroutine while off, e.g. to rule out a data race in production
+var ParallelSystems = flags.Define("parallel_systems",
+	"Run ECS systems and their entity loops on the shared worker pool", true)
+
+// minChunk is the fewest entities worth handing to a worker in Pool.For
+const minChunk = 64
+
+// Pool runs the parallel parts of ticks on a fixed set of worker goroutines.
+// A task is only handed to an idle worker; when all are busy the caller runs it
+// itself, so ticks of many games can share one pool and nested use can't
+// deadlock. A nil pool, and any pool while ParallelSystems is off, runs
+// everything on the calling goroutine.
+type Pool struct {
+	workers int
+	tasks   chan func()
+}
+
+// NewPool starts a pool of workers goroutines, GOMAXPROCS for workers <= 0.
+// The calling goroutine counts as one of them.
+func NewPool(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	p := &Pool{workers: workers, tasks: make(chan func())}
+	for i := 1; i < workers; i++ {
+		go func() {
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// sharedPool is the pool of every SystemManager, sized to GOMAXPROCS
+var sharedPool = sync.OnceValue(func() *Pool { return NewPool(0) })
+
+// Workers returns the number of goroutines the pool runs tasks on
+func (p *Pool) Workers() int {
+	if p == nil || !ParallelSystems.Enabled() {
+		return 1
+	}
+	return p.workers
+}
+
+// Run runs the tasks concurrently and returns once all of them are done. A
+// panic in a task is raised again on the calling goroutine, where the game
+// loop recovers it.
+func (p *Pool) Run(tasks ...func()) {
+	if p.Workers() == 1 || len(tasks) < 2 {
+		for _, task := range tasks {
+			task()
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked any
+	wg.Add(len(tasks) - 1)
+	for _, task := range tasks[1:] {
+		done := func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = r })
+				}
+			}()
+			task()
+		}
+		select {
+		case p.tasks <- done:
+		default:
+			done()
+		}
+	}
+	func() {
+		defer wg.Wait()
+		tasks[0]()
+	}()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// For splits [0, n) into contiguous chunks and calls fn for each of them
+// concurrently. fn must only write to what belongs to its own chunk.
+func (p *Pool) For(n int, fn func(lo, hi int)) {
+	chunks := min(p.Workers(), (n+minChunk-1)/minChunk)
+	if chunks <= 1 {
+		fn(0, n)
+		return
+	}
+	size := (n + chunks - 1) / chunks
+	tasks := make([]func(), 0, chunks)
+	for lo := 0; lo < n; lo += size {
+		hi := min(lo+size, n)
+		tasks = append(tasks, func() { fn(lo, hi) })
+	}
+	p.Run(tasks...)
+}
@@ -0,0 +1,161 @@
+package systems
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/rng"
+)
+
+// tickResult is what a run of the simulation leaves behind: the entities still
+// alive and every message published on the bus
+type tickResult struct {
+	Towers      []ecs.TowerRecord
+	Enemies     []ecs.EnemyRecord
+	Projectiles []ecs.ProjectileRecord
+	Messages    []string
+}
+
+// newScene returns a world of towers of every type along the main path and
+// enemies spread over it, placed with a fixed seed
+func newScene(t *testing.T, cfg *config.GameConfig, towers, enemies int) *ecs.World {
+	t.Helper()
+	factory := ecs.NewEntityFactory(cfg)
+	r := rand.New(rand.NewSource(7))
+	path := cfg.Map.AllPaths()[0]
+	types := []string{"basic", "sniper", "splash", "lancer", "tesla", "beacon"}
+
+	world := ecs.NewWorld()
+	for i := range towers {
+		p := path[r.Intn(len(path))]
+		pos := ecs.Position{X: p.X + r.Float64()*80 - 40, Y: p.Y + r.Float64()*80 - 40}
+		tower, err := factory.CreateTower(types[i%len(types)], pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		world.AddEntity(tower)
+	}
+	for range enemies {
+		index := r.Intn(len(path) - 1)
+		p := path[index]
+		enemy, err := factory.CreateEnemy("basic", ecs.Position{X: p.X, Y: p.Y}, 1+r.Intn(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		enemy.PathIndex = index
+		world.AddEntity(enemy)
+	}
+	return world
+}
+
+// simulate runs ticks of the combat pipeline on world with a pool of workers,
+// the way Game lays out its phases, on a clock that starts at now and advances
+// by the tick
+func simulate(cfg *config.GameConfig, world *ecs.World, workers, ticks int, seed int64, now time.Time) tickResult {
+	const dt = 1.0 / 60
+	world.SetClock(func() time.Time { return now })
+
+	var result tickResult
+	bus := NewBus()
+	for _, topic := range []Topic{EnemyKilled, EnemyLeaked, ProjectileHit} {
+		bus.Subscribe(topic, func(m Message) {
+			source := ""
+			if m.Projectile != nil {
+				source = m.Projectile.SourceID
+			}
+			result.Messages = append(result.Messages, fmt.Sprintf("%d %s %s %d %t %t %t",
+				m.Topic, m.Enemy.ID, source, m.Damage, m.Splash, m.Crit, m.Miss))
+		})
+	}
+
+	factory := ecs.NewEntityFactory(cfg)
+	projectiles := NewProjectileSystem(bus, rng.NewService(seed))
+	sm := &SystemManager{pool: NewPool(workers)}
+	sm.AddPhase(NewTerrainSystem(cfg, func(string, bool) {}), NewAuraSystem(cfg))
+	sm.AddSystem(NewMovementSystem(cfg, bus))
+	sm.AddSystem(NewCombatSystem(cfg, factory))
+	sm.AddSystem(projectiles)
+
+	for range ticks {
+		sm.Update(world, dt)
+		world.CleanupDeadEntities()
+		now = now.Add(time.Second / 60)
+	}
+
+	for _, tower := range world.GetTowers() {
+		result.Towers = append(result.Towers, tower.Record(now))
+	}
+	for _, enemy := range world.GetEnemies() {
+		result.Enemies = append(result.Enemies, enemy.Record())
+	}
+	for _, proj := range world.GetProjectiles() {
+		record := proj.Record()
+		record.ID = "" // new shots get random IDs
+		result.Projectiles = append(result.Projectiles, record)
+	}
+	return result
+}
+
+// TestParallelTicksMatchSequential runs the same scene on one worker and on
+// several; run with -race to also catch systems writing outside their chunk
+func TestParallelTicksMatchSequential(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scene := newScene(t, cfg, 120, 600)
+
+	const ticks, seed = 120, 42
+	start := time.Now().Add(time.Minute) // every tower has cooled down
+	sequential := simulate(cfg, scene.Clone(), 1, ticks, seed, start)
+	if len(sequential.Messages) == 0 || len(sequential.Projectiles) == 0 {
+		t.Fatalf("scene saw no combat: %d messages, %d projectiles", len(sequential.Messages), len(sequential.Projectiles))
+	}
+
+	for _, workers := range []int{2, 8} {
+		parallel := simulate(cfg, scene.Clone(), workers, ticks, seed, start)
+		if !reflect.DeepEqual(sequential.Towers, parallel.Towers) {
+			t.Errorf("%d workers: towers differ from sequential run", workers)
+		}
+		if !reflect.DeepEqual(sequential.Enemies, parallel.Enemies) {
+			t.Errorf("%d workers: enemies differ from sequential run", workers)
+		}
+		if !reflect.DeepEqual(sequential.Projectiles, parallel.Projectiles) {
+			t.Errorf("%d workers: projectiles differ from sequential run", workers)
+		}
+		if !reflect.DeepEqual(sequential.Messages, parallel.Messages) {
+			t.Errorf("%d workers: bus messages differ from sequential run", workers)
+		}
+	}
+}
+
+func TestPoolRunRaisesPanic(t *testing.T) {
+	p := NewPool(4)
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v, want boom", r)
+		}
+	}()
+	p.Run(func() {}, func() { panic("boom") }, func() {})
+}
+
+func TestPoolForCoversRange(t *testing.T) {
+	p := NewPool(4)
+	const n = 1000
+	hits := make([]int, n)
+	p.For(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			hits[i]++
+		}
+	})
+	for i, h := range hits {
+		if h != 1 {
+			t.Fatalf("index %d visited %d times", i, h)
+		}
+	}
+}
@@ -2,76 +2,379 @@ package systems
 
 import (
 	"math"
+	"math/rand"
 
+	"tower-defense/internal/game/config"
 	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/rng"
 )
 
-// ProjectileSystem handles projectile movement and collision
-type ProjectileSystem struct{}
+// ProjectileSystem handles projectile movement and collision; it publishes hits and kills on its bus.
+// Misses and critical hits are rolled on impact with the game's crits stream.
+type ProjectileSystem struct {
+	bus  *Bus
+	rng  *rand.Rand
+	pool *Pool
 
-// NewProjectileSystem creates a new projectile system
-func NewProjectileSystem() *ProjectileSystem {
-	return &ProjectileSystem{}
+	modifyDamage func(proj *ecs.ProjectileEntity, enemy *ecs.EnemyEntity, damage int, splash, crit bool) int
 }
 
-// Update processes projectile movement and hits
+// NewProjectileSystem creates a new projectile system rolling hits on the crits stream of rngs
+func NewProjectileSystem(bus *Bus, rngs *rng.Service) *ProjectileSystem {
+	return &ProjectileSystem{bus: bus, rng: rngs.Stream(rng.StreamCrits)}
+}
+
+// Update processes projectile movement and hits according to each projectile's
+// behavior. Homing and arcing shots that stay in flight advance concurrently;
+// the rest is resolved one projectile after the other.
 func (s *ProjectileSystem) Update(world *ecs.World, dt float64) {
 	projectiles := world.GetProjectiles()
 
-	for _, proj := range projectiles {
-		if !proj.Alive {
+	inFlight := make([]bool, len(projectiles))
+	s.pool.For(len(projectiles), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			inFlight[i] = advance(world, projectiles[i], dt)
+		}
+	})
+
+	for i, proj := range projectiles {
+		if !proj.Alive || inFlight[i] {
+			continue
+		}
+		if proj.Expired() {
+			// A stray shot that never reached anything
+			proj.Alive = false
 			continue
 		}
 
-		// Find target enemy
+		switch proj.Behavior {
+		case config.ProjectileBeam:
+			s.updateBeam(world, proj, dt)
+		case config.ProjectileArc:
+			s.updateArc(world, proj, dt)
+		case config.ProjectilePierce:
+			s.updatePierce(world, proj, dt)
+		case config.ProjectileChain:
+			s.updateChain(world, proj, dt)
+		default:
+			s.updateHoming(world, proj, dt)
+		}
+	}
+}
+
+// Resolve lands a shot at once, as if it reached its target or the end of
+// its path the moment it was fired, without it entering the world. Homing
+// shots at a target that died are lost, as they would fizzle in flight.
+func (s *ProjectileSystem) Resolve(world *ecs.World, proj *ecs.ProjectileEntity) {
+	switch proj.Behavior {
+	case config.ProjectileBeam:
+		s.updateBeam(world, proj, 0)
+	case config.ProjectileChain:
+		s.updateChain(world, proj, 0)
+	case config.ProjectileArc:
+		proj.SetPosition(proj.Impact)
+		s.updateArc(world, proj, 0)
+	case config.ProjectilePierce:
+		s.updatePierce(world, proj, math.Inf(1))
+	default:
+		if target, ok := world.GetEnemy(proj.Target); ok && target.Alive {
+			s.impact(world, proj, target)
+		}
+	}
+	proj.Alive = false
+}
+
+// SetDamageModifier sets a function that may change the damage of every hit
+// just before it lands, after misses, crits and splash resistance
+func (s *ProjectileSystem) SetDamageModifier(fn func(proj *ecs.ProjectileEntity, enemy *ecs.EnemyEntity, damage int, splash, crit bool) int) {
+	s.modifyDamage = fn
+}
+
+// SetPool sets the worker pool projectiles advance on
+func (s *ProjectileSystem) SetPool(pool *Pool) {
+	s.pool = pool
+}
+
+// advance ages a projectile and moves a homing or arcing one that doesn't
+// arrive this update, reporting whether it did; arrivals, fizzles, expired
+// shots and the other behaviors are left to Update. Leading homing shots fly
+// towards where they will meet their target but still hit on contact. It only
+// writes to the projectile, so projectiles can advance concurrently.
+func advance(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) bool {
+	if !proj.Alive {
+		return false
+	}
+	proj.Age += dt
+	if proj.Expired() {
+		return false
+	}
+	moveDistance := proj.Speed * dt * 60.0
+	var to ecs.Position
+	switch proj.Behavior {
+	case config.ProjectileArc:
+		to = proj.Impact
+	case config.ProjectileBeam, config.ProjectilePierce, config.ProjectileChain:
+		return false
+	default:
 		target, exists := world.GetEnemy(proj.Target)
 		if !exists || !target.Alive {
-			// Target is dead or missing, remove projectile
+			return false
+		}
+		if math.Hypot(target.Position.X-proj.Position.X, target.Position.Y-proj.Position.Y) <= moveDistance {
+			return false
+		}
+		to = target.Position
+		if proj.Lead {
+			to = intercept(proj.Position, proj.Speed, target)
+		}
+	}
+
+	dx := to.X - proj.Position.X
+	dy := to.Y - proj.Position.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+	if distance <= moveDistance {
+		if proj.Behavior == config.ProjectileArc {
+			return false
+		}
+		// A leading shot at the meeting point ahead of its target waits there
+		proj.SetPosition(to)
+		return true
+	}
+	ratio := moveDistance / distance
+	proj.SetPosition(ecs.Position{X: proj.Position.X + dx*ratio, Y: proj.Position.Y + dy*ratio})
+	return true
+}
+
+// updateHoming chases the target and hits it on contact; the shot fizzles if the target dies first
+func (s *ProjectileSystem) updateHoming(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) {
+	// Find target enemy
+	target, exists := world.GetEnemy(proj.Target)
+	if !exists || !target.Alive {
+		// Target is dead or missing, remove projectile
+		proj.Alive = false
+		return
+	}
+
+	// Calculate distance to target
+	dx := target.Position.X - proj.Position.X
+	dy := target.Position.Y - proj.Position.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+
+	// Move projectile
+	moveDistance := proj.Speed * dt * 60.0 // Normalize to 60 FPS
+
+	if distance <= moveDistance {
+		proj.Alive = false
+		s.impact(world, proj, target)
+	} else {
+		// Move towards target
+		ratio := moveDistance / distance
+		newPos := ecs.Position{
+			X: proj.Position.X + dx*ratio,
+			Y: proj.Position.Y + dy*ratio,
+		}
+		proj.SetPosition(newPos)
+	}
+}
+
+// updateBeam hits the target on the first update, then keeps the spent beam
+// around for its TTL so clients can draw it
+func (s *ProjectileSystem) updateBeam(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) {
+	if proj.Spent {
+		proj.TTL -= dt
+		if proj.TTL <= 0 {
 			proj.Alive = false
+		}
+		return
+	}
+
+	proj.Spent = true
+	target, exists := world.GetEnemy(proj.Target)
+	if !exists || !target.Alive {
+		proj.Alive = false
+		return
+	}
+	proj.Impact = target.Position
+	s.impact(world, proj, target)
+	if proj.TTL <= 0 {
+		proj.Alive = false
+	}
+}
+
+// updateArc flies to the ground point aimed at when fired and damages every
+// enemy within the splash radius there, whether or not the target still lives
+func (s *ProjectileSystem) updateArc(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) {
+	dx := proj.Impact.X - proj.Position.X
+	dy := proj.Impact.Y - proj.Position.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+	moveDistance := proj.Speed * dt * 60.0
+
+	if distance > moveDistance {
+		ratio := moveDistance / distance
+		proj.SetPosition(ecs.Position{X: proj.Position.X + dx*ratio, Y: proj.Position.Y + dy*ratio})
+		return
+	}
+
+	proj.SetPosition(proj.Impact)
+	proj.Alive = false
+	damage, crit, miss := s.roll(proj)
+	if miss {
+		return
+	}
+
+	// The enemy closest to the impact takes the primary hit, the rest splash hits
+	var victims []*ecs.EnemyEntity
+	var closest *ecs.EnemyEntity
+	closestDist := math.Inf(1)
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive {
+			continue
+		}
+		d := math.Hypot(enemy.Position.X-proj.Impact.X, enemy.Position.Y-proj.Impact.Y)
+		if d > proj.SplashRadius {
 			continue
 		}
+		victims = append(victims, enemy)
+		if d < closestDist {
+			closest, closestDist = enemy, d
+		}
+	}
+	for _, enemy := range victims {
+		s.hit(world, proj, enemy, damage, enemy != closest, crit)
+	}
+}
 
-		// Calculate distance to target
-		dx := target.Position.X - proj.Position.X
-		dy := target.Position.Y - proj.Position.Y
-		distance := math.Sqrt(dx*dx + dy*dy)
+// updatePierce flies in a straight line from the tower towards Impact and hits
+// every enemy it passes within HitRadius once, up to MaxHits
+func (s *ProjectileSystem) updatePierce(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) {
+	dx := proj.Impact.X - proj.Position.X
+	dy := proj.Impact.Y - proj.Position.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+	moveDistance := proj.Speed * dt * 60.0
 
-		// Move projectile
-		moveDistance := proj.Speed * dt * 60.0 // Normalize to 60 FPS
+	from := proj.Position
+	to := proj.Impact
+	if distance > moveDistance {
+		ratio := moveDistance / distance
+		to = ecs.Position{X: from.X + dx*ratio, Y: from.Y + dy*ratio}
+	}
+	proj.SetPosition(to)
 
-		if distance <= moveDistance {
-			// Hit target
-			target.TakeDamage(proj.Damage)
-			proj.Alive = false
-			
-			// Apply splash damage if projectile has splash radius
-			if proj.SplashRadius > 0 {
-				s.applySplashDamage(world, target.Position, proj.SplashRadius, proj.Damage, target.ID)
-			}
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive || proj.HasPierced(enemy.ID) {
+			continue
+		}
+		if segmentDistance(enemy.Position, from, to) > proj.HitRadius {
+			continue
+		}
+		proj.Pierced = append(proj.Pierced, enemy.ID)
+		damage, crit, miss := s.roll(proj)
+		if miss {
+			s.bus.Publish(Message{Topic: ProjectileHit, Enemy: enemy, Projectile: proj, Miss: true})
 		} else {
-			// Move towards target
-			ratio := moveDistance / distance
-			newPos := ecs.Position{
-				X: proj.Position.X + dx*ratio,
-				Y: proj.Position.Y + dy*ratio,
-			}
-			proj.SetPosition(newPos)
+			s.hit(world, proj, enemy, damage, false, crit)
+		}
+		if proj.MaxHits > 0 && len(proj.Pierced) >= proj.MaxHits {
+			proj.Alive = false
+			return
+		}
+	}
+
+	if distance <= moveDistance {
+		proj.Alive = false
+	}
+}
+
+// updateChain hits the target instantly, then jumps up to Jumps times to the
+// nearest enemy not yet hit within JumpRadius of the last one, losing Falloff
+// of its damage on each jump. A miss ends the chain; a crit carries along it.
+// The spent chain stays around for its TTL so clients can draw it.
+func (s *ProjectileSystem) updateChain(world *ecs.World, proj *ecs.ProjectileEntity, dt float64) {
+	if proj.Spent {
+		proj.TTL -= dt
+		if proj.TTL <= 0 {
+			proj.Alive = false
+		}
+		return
+	}
+
+	proj.Spent = true
+	target, exists := world.GetEnemy(proj.Target)
+	if !exists || !target.Alive {
+		proj.Alive = false
+		return
+	}
+	proj.Impact = target.Position
+
+	damage, crit, miss := s.roll(proj)
+	if miss {
+		s.bus.Publish(Message{Topic: ProjectileHit, Enemy: target, Projectile: proj, Miss: true})
+	}
+	current := target
+	for jump := 0; !miss && current != nil; jump++ {
+		proj.Pierced = append(proj.Pierced, current.ID)
+		proj.Chain = append(proj.Chain, current.Position)
+		s.hit(world, proj, current, damage, false, crit)
+
+		if jump >= proj.Jumps {
+			break
 		}
+		damage = int(math.Round(float64(damage) * (1 - proj.Falloff)))
+		if damage < 1 {
+			break
+		}
+		current = s.nextLink(world, proj, current.Position)
+	}
+
+	if proj.TTL <= 0 {
+		proj.Alive = false
 	}
 }
 
-// applySplashDamage applies area damage to enemies near the impact point
-func (s *ProjectileSystem) applySplashDamage(world *ecs.World, impactPos ecs.Position, radius float64, damage int, primaryTargetID string) {
+// nextLink returns the closest living enemy within the chain's jump radius of
+// from that the chain hasn't hit yet, or nil
+func (s *ProjectileSystem) nextLink(world *ecs.World, proj *ecs.ProjectileEntity, from ecs.Position) *ecs.EnemyEntity {
+	var next *ecs.EnemyEntity
+	best := proj.JumpRadius
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive || proj.HasPierced(enemy.ID) {
+			continue
+		}
+		if d := math.Hypot(enemy.Position.X-from.X, enemy.Position.Y-from.Y); d <= best {
+			next, best = enemy, d
+		}
+	}
+	return next
+}
+
+// impact resolves a projectile landing on its target: the miss/crit roll, the hit and any splash
+func (s *ProjectileSystem) impact(world *ecs.World, proj *ecs.ProjectileEntity, target *ecs.EnemyEntity) {
+	damage, crit, miss := s.roll(proj)
+	if miss {
+		s.bus.Publish(Message{Topic: ProjectileHit, Enemy: target, Projectile: proj, Miss: true})
+		return
+	}
+	s.hit(world, proj, target, damage, false, crit)
+
+	// Apply splash damage if projectile has splash radius
+	if proj.SplashRadius > 0 {
+		s.applySplashDamage(world, proj, target, damage, crit)
+	}
+}
+
+// applySplashDamage applies area damage to enemies near the primary target
+func (s *ProjectileSystem) applySplashDamage(world *ecs.World, proj *ecs.ProjectileEntity, primary *ecs.EnemyEntity, damage int, crit bool) {
 	enemies := world.GetEnemies()
+	impactPos := primary.Position
 	
-	// Splash damage is 50% of primary damage
+	// Splash damage is 50% of primary damage, including critical hits
 	splashDamage := damage / 2
 	if splashDamage < 1 {
 		splashDamage = 1
 	}
 	
 	for _, enemy := range enemies {
-		if !enemy.Alive || enemy.ID == primaryTargetID {
+		if !enemy.Alive || enemy.ID == primary.ID {
 			continue
 		}
 		
@@ -81,8 +384,69 @@ func (s *ProjectileSystem) applySplashDamage(world *ecs.World, impactPos ecs.Pos
 		dist := math.Sqrt(dx*dx + dy*dy)
 		
 		// Apply damage if within splash radius
-		if dist <= radius {
-			enemy.TakeDamage(splashDamage)
+		if dist <= proj.SplashRadius {
+			s.hit(world, proj, enemy, splashDamage, true, crit)
 		}
 	}
 }
+
+// hit damages an enemy on behalf of the projectile's tower. The hit that takes
+// the enemy's HP to zero kills it, so every kill is published exactly once.
+// The tower is credited with the damage that landed, shields included but not
+// overkill, and with the kill.
+func (s *ProjectileSystem) hit(world *ecs.World, proj *ecs.ProjectileEntity, enemy *ecs.EnemyEntity, damage int, splash, crit bool) {
+	if splash && enemy.SplashResist > 0 {
+		// Splash-resistant elites shrug off part of the blast
+		damage = int(math.Round(float64(damage) * (1 - enemy.SplashResist)))
+	}
+	if s.modifyDamage != nil {
+		damage = s.modifyDamage(proj, enemy, damage, splash, crit)
+	}
+	before := enemy.HP + enemy.Shield
+	enemy.LastHitBy = proj.SourceID
+	enemy.TakeDamage(damage)
+	s.bus.Publish(Message{Topic: ProjectileHit, Enemy: enemy, Projectile: proj, Damage: damage, Splash: splash, Crit: crit})
+
+	tower := sourceTower(world, proj)
+	if tower != nil {
+		tower.Stats.Damage += before - max(enemy.HP, 0) - enemy.Shield
+	}
+	if enemy.HP <= 0 {
+		enemy.Alive = false
+		if tower != nil {
+			tower.Stats.Kills++
+		}
+		s.bus.Publish(Message{Topic: EnemyKilled, Enemy: enemy})
+	}
+}
+
+// sourceTower returns the tower that fired a projectile, or nil if it is gone
+func sourceTower(world *ecs.World, proj *ecs.ProjectileEntity) *ecs.TowerEntity {
+	entity, ok := world.GetEntity(proj.SourceID)
+	if !ok {
+		return nil
+	}
+	tower, _ := entity.(*ecs.TowerEntity)
+	return tower
+}
+
+// roll resolves whether a landing projectile misses or hits critically, and its damage
+func (s *ProjectileSystem) roll(proj *ecs.ProjectileEntity) (damage int, crit, miss bool) {
+	if proj.MissChance > 0 && s.rng.Float64() < proj.MissChance {
+		return 0, false, true
+	}
+	if proj.CritChance > 0 && s.rng.Float64() < proj.CritChance {
+		return int(math.Round(float64(proj.Damage) * proj.CritMultiplier)), true, false
+	}
+	return proj.Damage, false, false
+}
+
+// segmentDistance returns the distance from p to the segment a-b
+func segmentDistance(p, a, b ecs.Position) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSq))
+	}
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
@@ -5,35 +5,32 @@ import (
 	"tower-defense/internal/logging"
 )
 
-// RewardSystem handles giving gold and score when enemies die
+// RewardSystem gives gold and score for every EnemyKilled event
 type RewardSystem struct {
-	onReward func(gold, score int)
+	onReward func(gold, score int, towerID string)
 }
 
-// NewRewardSystem creates a new reward system
-func NewRewardSystem(onReward func(gold, score int)) *RewardSystem {
-	return &RewardSystem{
+// NewRewardSystem creates a new reward system subscribed to kills on bus.
+// onReward gets the tower that landed the killing hit, "" if there was none.
+func NewRewardSystem(bus *Bus, onReward func(gold, score int, towerID string)) *RewardSystem {
+	s := &RewardSystem{
 		onReward: onReward,
 	}
+	bus.Subscribe(EnemyKilled, s.handleKill)
+	return s
 }
 
-// Update processes dead enemies and grants rewards
-func (s *RewardSystem) Update(world *ecs.World, dt float64) {
-	enemies := world.GetEnemies()
+// Update is a no-op; rewards are granted as kills are published
+func (s *RewardSystem) Update(world *ecs.World, dt float64) {}
 
-	for _, enemy := range enemies {
-		// Check if enemy just died (HP <= 0 but still marked alive)
-		if enemy.HP <= 0 && enemy.Alive {
-			// Grant rewards
-			if s.onReward != nil {
-				s.onReward(enemy.GoldReward, enemy.ScoreReward)
-				logging.Debugw("enemy_killed", 
-					"enemy_id", enemy.ID, 
-					"gold", enemy.GoldReward, 
-					"score", enemy.ScoreReward)
-			}
-			// Mark as dead after granting rewards
-			enemy.Alive = false
-		}
+// handleKill grants the rewards of a killed enemy
+func (s *RewardSystem) handleKill(m Message) {
+	if s.onReward == nil {
+		return
 	}
+	s.onReward(m.Enemy.GoldReward, m.Enemy.ScoreReward, m.Enemy.LastHitBy)
+	logging.Debugw("enemy_killed",
+		"enemy_id", m.Enemy.ID,
+		"gold", m.Enemy.GoldReward,
+		"score", m.Enemy.ScoreReward)
 }
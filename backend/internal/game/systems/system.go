@@ -1,6 +1,10 @@
 package systems
 
 import (
+	"reflect"
+	"strings"
+	"time"
+
 	"tower-defense/internal/game/ecs"
 )
 
@@ -9,26 +13,89 @@ type System interface {
 	Update(world *ecs.World, dt float64)
 }
 
-// SystemManager manages and updates all systems
+// SystemTiming is the time one system spent in the last update
+type SystemTiming struct {
+	Name     string // e.g. "movement" for MovementSystem
+	Duration time.Duration
+}
+
+// PoolUser is implemented by systems that split their work over the entities
+// they update; the SystemManager hands them its worker pool
+type PoolUser interface {
+	SetPool(pool *Pool)
+}
+
+// SystemManager manages and updates all systems. Systems are grouped in phases
+// that run in order; the systems of one phase run concurrently, so they must
+// not touch the same entity fields, the bus or the game state.
 type SystemManager struct {
 	systems []System
+	phases  [][]int // indexes into systems
+	timings []SystemTiming
+	pool    *Pool
 }
 
-// NewSystemManager creates a new system manager
+// NewSystemManager creates a new system manager using the shared worker pool
 func NewSystemManager() *SystemManager {
 	return &SystemManager{
 		systems: make([]System, 0),
+		pool:    sharedPool(),
 	}
 }
 
-// AddSystem adds a system to the manager
+// AddSystem adds a system to the manager as a phase of its own
 func (sm *SystemManager) AddSystem(system System) {
-	sm.systems = append(sm.systems, system)
+	sm.AddPhase(system)
+}
+
+// AddPhase adds systems that run concurrently, after the systems added before
+// and before those added after them
+func (sm *SystemManager) AddPhase(systems ...System) {
+	phase := make([]int, len(systems))
+	for i, system := range systems {
+		if user, ok := system.(PoolUser); ok {
+			user.SetPool(sm.pool)
+		}
+		phase[i] = len(sm.systems)
+		sm.systems = append(sm.systems, system)
+		sm.timings = append(sm.timings, SystemTiming{Name: systemName(system)})
+	}
+	sm.phases = append(sm.phases, phase)
 }
 
-// Update updates all systems in order
+// Update updates all systems phase by phase, timing each one
 func (sm *SystemManager) Update(world *ecs.World, dt float64) {
-	for _, system := range sm.systems {
-		system.Update(world, dt)
+	for _, phase := range sm.phases {
+		if len(phase) == 1 {
+			sm.update(phase[0], world, dt)
+			continue
+		}
+		tasks := make([]func(), len(phase))
+		for i, index := range phase {
+			tasks[i] = func() { sm.update(index, world, dt) }
+		}
+		sm.pool.Run(tasks...)
+	}
+}
+
+// update runs one system and records how long it took
+func (sm *SystemManager) update(index int, world *ecs.World, dt float64) {
+	start := time.Now()
+	sm.systems[index].Update(world, dt)
+	sm.timings[index].Duration = time.Since(start)
+}
+
+// Timings returns a copy of the per-system durations of the last update, in
+// the order the systems were added
+func (sm *SystemManager) Timings() []SystemTiming {
+	return append([]SystemTiming(nil), sm.timings...)
+}
+
+// systemName derives a short metric-friendly name from the system's type
+func systemName(system System) string {
+	t := reflect.TypeOf(system)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
 	}
+	return strings.ToLower(strings.TrimSuffix(t.Name(), "System"))
 }
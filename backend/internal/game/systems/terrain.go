@@ -0,0 +1,117 @@
+package systems
+
+import (
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// TerrainSystem applies the map's terrain zones and the weather cycle as
+// modifiers: mud slows enemies, high ground extends tower range and rain
+// lowers tower fire rate
+type TerrainSystem struct {
+	config    *config.GameConfig
+	weather   string  // current weather, "" for clear skies
+	timer     float64 // seconds until the weather changes
+	onWeather func(weather string, started bool)
+}
+
+// NewTerrainSystem creates a new terrain system
+func NewTerrainSystem(cfg *config.GameConfig, onWeather func(weather string, started bool)) *TerrainSystem {
+	s := &TerrainSystem{config: cfg, onWeather: onWeather}
+	s.Reset()
+	return s
+}
+
+// Reset clears the skies and restarts the weather cycle
+func (s *TerrainSystem) Reset() {
+	s.weather = ""
+	s.timer = s.config.Terrain.Weather.Every
+}
+
+// Weather returns the current weather and the seconds until it clears, or "" when the skies are clear
+func (s *TerrainSystem) Weather() (string, float64) {
+	if s.weather == "" {
+		return "", 0
+	}
+	return s.weather, s.timer
+}
+
+// Update advances the weather and recomputes the terrain and weather modifiers
+// of every tower and enemy
+func (s *TerrainSystem) Update(world *ecs.World, dt float64) {
+	s.advanceWeather(dt)
+
+	rules := s.config.Terrain
+	var fireRate float64
+	if s.weather == config.WeatherRain {
+		fireRate = -rules.Weather.RainFireRate
+	}
+	for _, tower := range world.GetTowers() {
+		tower.TerrainRange = 0
+		if s.inZone(config.ZoneHighGround, tower.Position) {
+			tower.TerrainRange = rules.HighGroundRange
+		}
+		tower.WeatherFireRate = fireRate
+	}
+	for _, enemy := range world.GetEnemies() {
+		enemy.TerrainSlow = 0
+		if s.inZone(config.ZoneMud, enemy.Position) {
+			enemy.TerrainSlow = rules.MudSlow
+		}
+	}
+}
+
+// TerrainState is the weather cycle of a TerrainSystem
+type TerrainState struct {
+	Weather string  `json:"weather,omitempty"`
+	Timer   float64 `json:"timer"` // seconds until the weather changes
+}
+
+// State captures the weather cycle
+func (s *TerrainSystem) State() TerrainState {
+	return TerrainState{Weather: s.weather, Timer: s.timer}
+}
+
+// Restore continues the weather cycle from a captured state, without notifying
+func (s *TerrainSystem) Restore(st TerrainState) {
+	s.weather = st.Weather
+	s.timer = st.Timer
+}
+
+// advanceWeather starts and stops rain on the configured schedule
+func (s *TerrainSystem) advanceWeather(dt float64) {
+	rules := s.config.Terrain.Weather
+	if rules.Every <= 0 && s.weather == "" {
+		return
+	}
+	s.timer -= dt
+	if s.timer > 0 {
+		return
+	}
+	if s.weather == "" {
+		s.weather = config.WeatherRain
+		s.timer = rules.Duration
+		s.notify(s.weather, true)
+		return
+	}
+	ended := s.weather
+	s.weather = ""
+	s.timer = rules.Every
+	s.notify(ended, false)
+}
+
+func (s *TerrainSystem) notify(weather string, started bool) {
+	if s.onWeather != nil {
+		s.onWeather(weather, started)
+	}
+}
+
+// inZone reports whether pos lies in a zone of the given type on the current map
+func (s *TerrainSystem) inZone(zoneType string, pos ecs.Position) bool {
+	for _, z := range s.config.Map.Zones {
+		if z.Type == zoneType && z.Contains(pos.X, pos.Y) {
+			return true
+		}
+	}
+	return false
+}
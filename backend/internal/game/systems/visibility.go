@@ -0,0 +1,57 @@
+package systems
+
+import (
+	"math"
+	"slices"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+// VisibilitySystem records which players see each enemy while fog of war is on
+type VisibilitySystem struct {
+	config *config.GameConfig
+}
+
+// NewVisibilitySystem creates a new visibility system
+func NewVisibilitySystem(cfg *config.GameConfig) *VisibilitySystem {
+	return &VisibilitySystem{config: cfg}
+}
+
+// Update recomputes the VisibleTo list of every enemy from the towers and walls
+// in sight of it. Nothing is tracked while fog of war is off.
+func (s *VisibilitySystem) Update(world *ecs.World, dt float64) {
+	rules := s.config.Visibility
+	if !rules.Enabled {
+		return
+	}
+
+	towers := world.GetTowers()
+	walls := world.GetWalls()
+	for _, enemy := range world.GetEnemies() {
+		// A fresh slice each tick: snapshots may still hold the previous one
+		var viewers []string
+		for _, tower := range towers {
+			if within(enemy.Position, tower.Position, tower.EffectiveRange()*rules.TowerSight) {
+				viewers = addViewer(viewers, tower.OwnerID)
+			}
+		}
+		for _, wall := range walls {
+			if within(enemy.Position, wall.Position, rules.WallSight) {
+				viewers = addViewer(viewers, wall.OwnerID)
+			}
+		}
+		enemy.VisibleTo = viewers
+	}
+}
+
+func within(a, b ecs.Position, radius float64) bool {
+	return math.Hypot(a.X-b.X, a.Y-b.Y) <= radius
+}
+
+func addViewer(viewers []string, playerID string) []string {
+	if slices.Contains(viewers, playerID) {
+		return viewers
+	}
+	return append(viewers, playerID)
+}
@@ -0,0 +1,43 @@
+package systems
+
+import (
+	"tower-defense/internal/game/ecs"
+)
+
+// WallSystem lets enemies stopped by a wall attack it and publishes WallDestroyed when it breaks
+type WallSystem struct {
+	bus *Bus
+}
+
+// NewWallSystem creates a new wall system
+func NewWallSystem(bus *Bus) *WallSystem {
+	return &WallSystem{bus: bus}
+}
+
+// Update applies the wall damage of every blocked enemy
+func (s *WallSystem) Update(world *ecs.World, dt float64) {
+	walls := world.GetWalls()
+	if len(walls) == 0 {
+		return
+	}
+	byID := make(map[string]*ecs.WallEntity, len(walls))
+	for _, wall := range walls {
+		byID[wall.ID] = wall
+	}
+
+	for _, enemy := range world.GetEnemies() {
+		if !enemy.Alive || enemy.BlockedBy == "" {
+			continue
+		}
+		wall, ok := byID[enemy.BlockedBy]
+		if !ok || !wall.Alive {
+			continue
+		}
+		wall.HP -= enemy.WallDamage * dt
+		if wall.HP <= 0 {
+			wall.HP = 0
+			wall.Alive = false
+			s.bus.Publish(Message{Topic: WallDestroyed, Wall: wall, Enemy: enemy})
+		}
+	}
+}
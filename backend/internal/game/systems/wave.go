@@ -1,146 +1,487 @@
 package systems
 
 import (
+	"math"
 	"math/rand"
 	"time"
 
 	"tower-defense/internal/game/config"
 	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/rng"
 	"tower-defense/internal/logging"
 )
 
+// defaultWaveInterval applies when the pacing has no interval
+const defaultWaveInterval = 10 * time.Second
+
 // WaveSystem handles wave spawning and enemy creation
 type WaveSystem struct {
-	config          *config.GameConfig
-	factory         *ecs.EntityFactory
-	startPos        ecs.Position
-	currentWave     int
-	remainingInWave int
-	nextEnemySpawn  time.Time
-	lastWaveTime    time.Time
-	waveInterval    time.Duration
-	rng             *rand.Rand
-}
-
-// NewWaveSystem creates a new wave system
-func NewWaveSystem(cfg *config.GameConfig, factory *ecs.EntityFactory, startPos ecs.Position) *WaveSystem {
+	config         *config.GameConfig
+	factory        *ecs.EntityFactory
+	entrances      []ecs.Position // spawn point of each map path
+	currentWave    int
+	spawnQueue     []*ecs.EnemyEntity
+	spawnIndex     int // enemies spawned so far in the current wave
+	pattern        config.SpawnPattern
+	nextEnemySpawn time.Time
+	lastWaveTime   time.Time
+	clearedAt      time.Time // when the last wave was cleared, or the system created
+	pacing         config.WavePacing
+	spawnCap       int // hold spawns while this many enemies are alive, 0 = no cap
+	maxEnemies     int // hard cap on live enemies, 0 = none; see SetMaxEnemies
+	capHolds       int // updates a due spawn was held at maxEnemies, see TakeCapHolds
+	rngs           *rng.Service
+	spawnRNG       *rand.Rand // wave order, entrances and jitter
+	affixRNG       *rand.Rand // elite rolls
+
+	lastCompletedWave int
+	bus               *Bus
+
+	composition map[string]int // enemies of the current wave by type, as queued
+
+	held bool // no new waves start, see SetHeld
+
+	modifier string // wave modifier of the current wave, "" for none
+}
+
+// NewWaveSystem creates a new wave system; entrances holds the start position of every map path.
+// Cleared waves are published on bus. Spawns and elites draw from the streams of rngs.
+// Waves are paced as the balance and the map of cfg say, see SetPacing.
+func NewWaveSystem(cfg *config.GameConfig, factory *ecs.EntityFactory, entrances []ecs.Position, bus *Bus, rngs *rng.Service) *WaveSystem {
+	now := time.Now()
 	return &WaveSystem{
 		config:       cfg,
 		factory:      factory,
-		startPos:     startPos,
+		entrances:    entrances,
 		currentWave:  0,
-		waveInterval: 10 * time.Second,
-		lastWaveTime: time.Now(),
-		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		lastWaveTime: now,
+		clearedAt:    now,
+		pacing:       cfg.WavePacingFor(cfg.Map),
+		rngs:         rngs,
+		spawnRNG:     rngs.Stream(rng.StreamSpawning),
+		affixRNG:     rngs.Stream(rng.StreamAffixes),
+		bus:          bus,
 	}
 }
 
 // Update processes wave spawning
 func (s *WaveSystem) Update(world *ecs.World, dt float64) {
-	now := time.Now()
+	now := world.Now()
 
-	// Check if it's time to spawn a new wave
-	if s.remainingInWave == 0 && now.Sub(s.lastWaveTime) > s.waveInterval {
-		s.spawnWave(world)
+	// Check if the current wave has been cleared
+	if s.currentWave > s.lastCompletedWave && len(s.spawnQueue) == 0 && len(world.GetEnemies()) == 0 {
+		s.lastCompletedWave = s.currentWave
+		s.clearedAt = now
+		logging.Infow("wave_completed", "wave", s.currentWave)
+		s.bus.Publish(Message{Topic: WaveCompleted, Wave: s.currentWave})
+	}
+
+	// Check if it's time to spawn a new wave; none follows the victory wave
+	last := s.config.Game.VictoryWave
+	if !s.held && (last == 0 || s.currentWave < last) && s.waveDue(now) {
+		s.spawnWave()
 		s.lastWaveTime = now
 	}
 
 	// Spawn enemies from current wave
-	for s.remainingInWave > 0 && now.After(s.nextEnemySpawn) {
+	for len(s.spawnQueue) > 0 && now.After(s.nextEnemySpawn) {
+		if s.spawnCap > 0 && world.EnemyCount() >= s.spawnCap {
+			break
+		}
+		if s.maxEnemies > 0 && world.EnemyCount() >= s.maxEnemies {
+			s.capHolds++
+			break
+		}
 		s.spawnNextEnemy(world)
 		s.nextEnemySpawn = now.Add(s.nextSpawnDelay())
 	}
 }
 
-// spawnWave starts a new wave
-func (s *WaveSystem) spawnWave(world *ecs.World) {
+// waveDue reports whether the pacing lets the next wave start
+func (s *WaveSystem) waveDue(now time.Time) bool {
+	if len(s.spawnQueue) > 0 {
+		return false
+	}
+	if s.pacing.Mode == config.PacingAfterClear || s.pacing.Mode == config.PacingHybrid {
+		return s.Cleared() && now.Sub(s.clearedAt) >= s.afterClear()
+	}
+	if s.inIntermission() && now.Sub(s.clearedAt) < s.intermission() {
+		return false
+	}
+	return now.Sub(s.lastWaveTime) > s.interval()
+}
+
+// inIntermission reports whether a wave was cleared and the next hasn't started
+func (s *WaveSystem) inIntermission() bool {
+	return s.currentWave > 0 && s.Cleared()
+}
+
+// intermission returns the build time after a cleared wave
+func (s *WaveSystem) intermission() time.Duration {
+	return time.Duration(s.pacing.IntermissionMs) * time.Millisecond
+}
+
+// interval returns the time between waves of the pacing
+func (s *WaveSystem) interval() time.Duration {
+	if s.pacing.IntervalMs <= 0 {
+		return defaultWaveInterval
+	}
+	return time.Duration(s.pacing.IntervalMs) * time.Millisecond
+}
+
+// afterClear returns how long the next wave waits once the last one is
+// cleared, in the after_clear and hybrid modes: the intermission, and in
+// hybrid mode at least the interval. The game starts out cleared, so the first
+// wave always waits the interval.
+func (s *WaveSystem) afterClear() time.Duration {
+	switch {
+	case s.currentWave == 0:
+		return s.interval()
+	case s.pacing.Mode == config.PacingHybrid:
+		return max(s.interval(), s.intermission())
+	default:
+		return s.intermission()
+	}
+}
+
+// spawnWave starts a new wave by queueing its enemies
+func (s *WaveSystem) spawnWave() {
 	s.currentWave++
-	
+
 	// Create enemies for this wave
-	enemies, err := s.factory.CreateEnemiesForWave(s.currentWave, s.startPos)
+	enemies, err := s.factory.CreateEnemiesForWave(s.currentWave, s.entrances[0])
 	if err != nil {
 		logging.Errorw("wave_spawn_error", "wave", s.currentWave, "error", err)
 		return
 	}
 
-	s.remainingInWave = len(enemies)
-	logging.Infow("wave_started", "wave", s.currentWave, "enemy_count", len(enemies))
+	s.modifier = s.ModifierFor(s.currentWave)
+	enemies = s.applyModifier(enemies)
 
-	// Spawn first enemy immediately
-	if len(enemies) > 0 {
-		world.AddEntity(enemies[0])
-		s.remainingInWave--
-		s.nextEnemySpawn = time.Now().Add(s.nextSpawnDelay())
+	// Mix enemy types so the wave doesn't arrive sorted by type
+	s.spawnRNG.Shuffle(len(enemies), func(i, j int) {
+		enemies[i], enemies[j] = enemies[j], enemies[i]
+	})
+	s.rollAffixes(enemies)
+
+	s.spawnQueue = enemies
+	s.spawnIndex = 0
+	s.composition = make(map[string]int)
+	for _, e := range enemies {
+		s.composition[e.EnemyType]++
 	}
+	s.pattern = s.config.GetSpawnPattern(s.currentWave)
+	s.nextEnemySpawn = time.Time{} // first enemy spawns immediately
+	logging.Infow("wave_started", "wave", s.currentWave, "enemy_count", len(enemies), "pattern", s.pattern.Type, "modifier", s.modifier)
 }
 
-// spawnNextEnemy spawns the next enemy in the current wave
-func (s *WaveSystem) spawnNextEnemy(world *ecs.World) {
-	if s.remainingInWave <= 0 {
-		return
+// ModifierFor returns the modifier of a wave, or "" for a plain wave. It is
+// derived from the wave number and the game seed, so it can be announced early.
+func (s *WaveSystem) ModifierFor(wave int) string {
+	return s.config.WaveModifierFor(wave, s.rngs.Seed())
+}
+
+// CurrentModifier returns the modifier of the current wave, or "" for none
+func (s *WaveSystem) CurrentModifier() string {
+	return s.modifier
+}
+
+// applyModifier scales the enemies of the current wave by its modifier
+func (s *WaveSystem) applyModifier(enemies []*ecs.EnemyEntity) []*ecs.EnemyEntity {
+	mod, ok := s.config.Waves.Modifiers.Registry[s.modifier]
+	if !ok {
+		return enemies
+	}
+	if mod.Count != 0 {
+		enemies = s.scaleCount(enemies, mod.Count)
+	}
+	for _, e := range enemies {
+		e.Speed *= config.Factor(mod.Speed)
+		e.MaxHP = max(int(math.Round(float64(e.MaxHP)*config.Factor(mod.HP))), 1)
+		e.HP = e.MaxHP
+		e.GoldReward = int(math.Round(float64(e.GoldReward) * config.Factor(mod.Gold)))
 	}
+	return enemies
+}
 
-	// Determine enemy type based on wave composition
-	composition := s.config.GetWaveComposition(s.currentWave)
-	enemyType := s.selectEnemyType(composition)
+// scaleCount grows or shrinks the number of enemies of each type in a wave
+func (s *WaveSystem) scaleCount(enemies []*ecs.EnemyEntity, multiplier float64) []*ecs.EnemyEntity {
+	var types []string
+	byType := make(map[string][]*ecs.EnemyEntity)
+	for _, e := range enemies {
+		if _, ok := byType[e.EnemyType]; !ok {
+			types = append(types, e.EnemyType)
+		}
+		byType[e.EnemyType] = append(byType[e.EnemyType], e)
+	}
 
-	enemy, err := s.factory.CreateEnemy(enemyType, s.startPos, s.currentWave)
-	if err != nil {
-		logging.Errorw("enemy_spawn_error", "type", enemyType, "error", err)
+	scaled := make([]*ecs.EnemyEntity, 0, len(enemies))
+	for _, enemyType := range types {
+		group := byType[enemyType]
+		want := config.ScaleCount(len(group), multiplier)
+		if want <= len(group) {
+			scaled = append(scaled, group[:want]...)
+			continue
+		}
+		scaled = append(scaled, group...)
+		for i := len(group); i < want; i++ {
+			extra, err := s.factory.CreateEnemy(enemyType, s.entrances[0], s.currentWave)
+			if err != nil {
+				logging.Errorw("wave_modifier_spawn_error", "wave", s.currentWave, "type", enemyType, "error", err)
+				break
+			}
+			scaled = append(scaled, extra)
+		}
+	}
+	return scaled
+}
+
+// rollAffixes turns some enemies of an endless late-game wave into elites
+func (s *WaveSystem) rollAffixes(enemies []*ecs.EnemyEntity) {
+	elites := s.config.Elites
+	if elites.StartWave <= 0 || s.currentWave < elites.StartWave || len(elites.Affixes) == 0 {
 		return
 	}
+	count := 0
+	for _, enemy := range enemies {
+		if s.affixRNG.Float64() >= elites.Chance {
+			continue
+		}
+		affix := elites.PickAffix(s.affixRNG.Float64())
+		enemy.Affixes = append(enemy.Affixes, affix)
+		switch rules := elites.Affixes[affix]; affix {
+		case config.AffixSplashResistant:
+			enemy.SplashResist = rules.SplashResist
+		case config.AffixPhasing:
+			enemy.PhaseTimer = rules.PhaseEvery
+		}
+		count++
+	}
+	if count > 0 {
+		logging.Debugw("elites_rolled", "wave", s.currentWave, "elites", count)
+	}
+}
+
+// spawnNextEnemy places the next queued enemy at its entrance
+func (s *WaveSystem) spawnNextEnemy(world *ecs.World) {
+	if len(s.spawnQueue) == 0 {
+		return
+	}
+
+	enemy := s.spawnQueue[0]
+	s.spawnQueue = s.spawnQueue[1:]
+
+	entrance := s.selectEntrance()
+	enemy.PathID = entrance
+	enemy.SetPosition(s.entrances[entrance])
 
 	world.AddEntity(enemy)
-	s.remainingInWave--
+	s.spawnIndex++
 }
 
-// selectEnemyType selects a random enemy type based on wave composition
-func (s *WaveSystem) selectEnemyType(comp config.WaveComposition) string {
-	total := comp.Basic + comp.Fast + comp.Tank + comp.Boss
-	if total == 0 {
-		return "basic"
+// selectEntrance picks the entrance for the next enemy according to the spawn pattern
+func (s *WaveSystem) selectEntrance() int {
+	n := len(s.entrances)
+	if n <= 1 {
+		return 0
 	}
 
-	roll := s.rng.Intn(total)
-	
-	if roll < comp.Basic {
-		return "basic"
+	switch s.pattern.EntranceMode() {
+	case config.EntranceAlternate:
+		// Squads stay together; everything else alternates per enemy
+		if s.pattern.Type == config.SpawnSquads && s.pattern.SquadSize > 0 {
+			return (s.spawnIndex / s.pattern.SquadSize) % n
+		}
+		return s.spawnIndex % n
+	case config.EntranceRandom:
+		return s.spawnRNG.Intn(n)
+	default:
+		return 0
 	}
-	roll -= comp.Basic
-	
-	if roll < comp.Fast {
-		return "fast"
-	}
-	roll -= comp.Fast
-	
-	if roll < comp.Tank {
-		return "tank"
-	}
-	
-	return "boss"
 }
 
-// nextSpawnDelay returns a random delay for next enemy spawn
+// nextSpawnDelay returns the delay before the next enemy spawn according to the spawn pattern
 func (s *WaveSystem) nextSpawnDelay() time.Duration {
-	baseDelay := 120
-	variance := 181
-	delay := baseDelay + s.rng.Intn(variance)
+	p := s.pattern
+	delay := p.IntervalMs
+	if p.JitterMs > 0 {
+		delay += s.spawnRNG.Intn(p.JitterMs + 1)
+	}
+	// spawnIndex already counts the enemy just spawned
+	if p.Type == config.SpawnSquads && p.SquadSize > 0 && s.spawnIndex%p.SquadSize == 0 {
+		delay = p.SquadGapMs
+	}
 	return time.Duration(delay) * time.Millisecond
 }
 
+// SetHeld stops new waves from starting while held. On release at now the next
+// wave starts right away, or with after_clear and hybrid pacing once the current one is cleared.
+func (s *WaveSystem) SetHeld(held bool, now time.Time) {
+	if s.held && !held {
+		s.lastWaveTime = now.Add(-s.interval())
+		s.clearedAt = now.Add(-max(s.interval(), s.intermission()))
+	}
+	s.held = held
+}
+
+// SetPacing changes when the next waves start; the countdown in progress
+// carries over
+func (s *WaveSystem) SetPacing(p config.WavePacing) {
+	s.pacing = p
+}
+
+// Pacing returns when the next waves start, see SetPacing
+func (s *WaveSystem) Pacing() config.WavePacing {
+	return s.pacing
+}
+
+// Held reports whether new waves are held back, see SetHeld
+func (s *WaveSystem) Held() bool {
+	return s.held
+}
+
 // GetCurrentWave returns the current wave number
 func (s *WaveSystem) GetCurrentWave() int {
 	return s.currentWave
 }
 
-// SetCurrentWave sets the current wave number (for loading saved games)
+// RemainingInWave returns the number of enemies of the current wave still waiting to spawn
+func (s *WaveSystem) RemainingInWave() int {
+	return len(s.spawnQueue)
+}
+
+// NextWaveIn returns how long until the next wave starts. With timer pacing the
+// countdown runs from the start of the current wave and the next one also waits
+// for its spawns to finish and for the intermission once it is cleared. With
+// after_clear and hybrid pacing it runs from the moment the current wave is
+// cleared; until then the full wait is returned.
+func (s *WaveSystem) NextWaveIn(now time.Time) time.Duration {
+	if s.pacing.Mode == config.PacingAfterClear || s.pacing.Mode == config.PacingHybrid {
+		if !s.Cleared() {
+			return s.afterClear()
+		}
+		return max(s.afterClear()-now.Sub(s.clearedAt), 0)
+	}
+	wait := s.interval() - now.Sub(s.lastWaveTime)
+	if s.inIntermission() {
+		wait = max(wait, s.intermission()-now.Sub(s.clearedAt))
+	}
+	return max(wait, 0)
+}
+
+// Cleared reports whether the current wave has fully spawned and left the
+// field, i.e. the game is between waves. True before the first wave.
+func (s *WaveSystem) Cleared() bool {
+	return s.currentWave == s.lastCompletedWave && len(s.spawnQueue) == 0
+}
+
+// LastCompletedWave returns the last wave that was cleared, 0 before any
+func (s *WaveSystem) LastCompletedWave() int {
+	return s.lastCompletedWave
+}
+
+// Composition returns the enemies of the current wave by type
+func (s *WaveSystem) Composition() map[string]int {
+	c := make(map[string]int, len(s.composition))
+	for t, n := range s.composition {
+		c[t] = n
+	}
+	return c
+}
+
+// SetCurrentWave sets the current wave number (for loading saved games).
+// The loaded wave is treated as completed so WaveCompleted is not published twice.
 func (s *WaveSystem) SetCurrentWave(wave int) {
 	s.currentWave = wave
+	s.lastCompletedWave = wave
+}
+
+// SetSpawnCap holds queued spawns while at least n enemies are alive; 0 removes the cap.
+// Held enemies spawn once enough of the field is cleared, so waves only get slower.
+func (s *WaveSystem) SetSpawnCap(n int) {
+	s.spawnCap = n
+}
+
+// SetMaxEnemies sets the most enemies the game may have alive at once, 0 for
+// no limit. Like the spawn cap, due spawns wait for room rather than being
+// dropped, but the limit always applies.
+func (s *WaveSystem) SetMaxEnemies(n int) {
+	s.maxEnemies = n
 }
 
-// Reset resets the wave system
-func (s *WaveSystem) Reset() {
+// TakeCapHolds returns in how many updates since the last call a due spawn
+// was held at the enemy limit, and starts counting again
+func (s *WaveSystem) TakeCapHolds() int {
+	n := s.capHolds
+	s.capHolds = 0
+	return n
+}
+
+// Reset resets the wave system; the first wave is due an interval after now
+func (s *WaveSystem) Reset(now time.Time) {
 	s.currentWave = 0
-	s.remainingInWave = 0
-	s.lastWaveTime = time.Now()
+	s.spawnQueue = nil
+	s.spawnIndex = 0
+	s.lastCompletedWave = 0
+	s.lastWaveTime = now
+	s.clearedAt = now
+	s.composition = nil
+	s.modifier = ""
+}
+
+// WaveState is the serializable internal state of a WaveSystem.
+// Timers are stored relative to the save time.
+type WaveState struct {
+	CurrentWave       int               `json:"currentWave"`
+	LastCompletedWave int               `json:"lastCompletedWave"`
+	SpawnIndex        int               `json:"spawnIndex"`
+	SpawnQueue        []ecs.EnemyRecord `json:"spawnQueue"`
+	NextSpawnInMs     int64             `json:"nextSpawnInMs"`
+	SinceLastWaveMs   int64             `json:"sinceLastWaveMs"`
+	SinceClearMs      int64             `json:"sinceClearMs,omitempty"` // absent in older saves
+	RNG               *rng.State        `json:"rng,omitempty"`          // single RNG of older saves, now the game's rng.ServiceState
+
+	Composition map[string]int `json:"composition,omitempty"` // absent in older saves
+	Modifier    string         `json:"modifier,omitempty"`
+}
+
+// State captures the wave system internals relative to now
+func (s *WaveSystem) State(now time.Time) WaveState {
+	queue := make([]ecs.EnemyRecord, len(s.spawnQueue))
+	for i, e := range s.spawnQueue {
+		queue[i] = e.Record()
+	}
+	nextSpawnIn := s.nextEnemySpawn.Sub(now)
+	if s.nextEnemySpawn.IsZero() || nextSpawnIn < 0 {
+		nextSpawnIn = 0
+	}
+	return WaveState{
+		CurrentWave:       s.currentWave,
+		LastCompletedWave: s.lastCompletedWave,
+		SpawnIndex:        s.spawnIndex,
+		SpawnQueue:        queue,
+		NextSpawnInMs:     nextSpawnIn.Milliseconds(),
+		SinceLastWaveMs:   now.Sub(s.lastWaveTime).Milliseconds(),
+		SinceClearMs:      now.Sub(s.clearedAt).Milliseconds(),
+
+		Composition: s.Composition(),
+		Modifier:    s.modifier,
+	}
+}
+
+// Restore replaces the wave system internals with a saved state relative to now
+func (s *WaveSystem) Restore(st WaveState, now time.Time) {
+	s.currentWave = st.CurrentWave
+	s.lastCompletedWave = st.LastCompletedWave
+	s.spawnIndex = st.SpawnIndex
+	s.spawnQueue = make([]*ecs.EnemyEntity, len(st.SpawnQueue))
+	for i, r := range st.SpawnQueue {
+		s.spawnQueue[i] = r.Entity()
+	}
+	s.pattern = s.config.GetSpawnPattern(st.CurrentWave)
+	s.nextEnemySpawn = now.Add(time.Duration(st.NextSpawnInMs) * time.Millisecond)
+	s.lastWaveTime = now.Add(-time.Duration(st.SinceLastWaveMs) * time.Millisecond)
+	s.clearedAt = now.Add(-time.Duration(st.SinceClearMs) * time.Millisecond)
+	s.composition = st.Composition
+	s.modifier = st.Modifier
 }
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+)
+
+// Limits of saved build templates
+const (
+	MaxTemplates       = 20 // templates per player
+	MaxTemplateTowers  = 50 // towers per template
+	maxTemplateNameLen = 40
+)
+
+var (
+	ErrTemplateNotFound = errors.New("build template not found")
+	ErrInvalidTemplate  = errors.New("invalid build template")
+	ErrTooManyTemplates = errors.New("too many build templates")
+)
+
+// ValidateTemplate checks the shape of a build template before it is saved.
+// Tower types are checked when the template is placed, since games can run
+// different configs.
+func ValidateTemplate(t repository.BuildTemplate) error {
+	switch {
+	case t.Name == "" || len(t.Name) > maxTemplateNameLen:
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidTemplate, maxTemplateNameLen)
+	case len(t.Towers) == 0 || len(t.Towers) > MaxTemplateTowers:
+		return fmt.Errorf("%w: a template holds 1-%d towers", ErrInvalidTemplate, MaxTemplateTowers)
+	}
+	for i, tower := range t.Towers {
+		if tower.TowerType == "" {
+			return fmt.Errorf("%w: tower %d has no type", ErrInvalidTemplate, i)
+		}
+	}
+	return nil
+}
+
+// TemplatePlacement is the outcome of one tower of a placed build template;
+// Err says why it was not placed
+type TemplatePlacement struct {
+	TowerType string  `json:"towerType"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Placed    bool    `json:"placed"`
+	Err       error   `json:"-"`
+}
+
+// TemplateResult is the outcome of placing a build template
+type TemplateResult struct {
+	Placed     int                 `json:"placed"` // towers placed
+	Gold       int                 `json:"gold"`   // gold spent
+	Placements []TemplatePlacement `json:"placements"`
+}
+
+// ApplyTemplate places the towers of a build template for playerID, in order,
+// with the template's origin cell at (x, y). Every tower is validated like a
+// single placement; towers that can't be placed, e.g. because the gold ran out
+// or the spot is taken, are skipped so that cheaper towers further down the
+// list still go in. All towers are placed within one tick.
+func (g *Game) ApplyTemplate(playerID string, t repository.BuildTemplate, x, y float64) TemplateResult {
+	mods, joinErr := g.member(playerID)
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	ruleErr := joinErr
+	if ruleErr == nil {
+		ruleErr = g.checkRules(config.ActionBuild)
+	}
+	cell := g.config.Placement.GridSize
+	gold := g.gold(playerID)
+	result := TemplateResult{Placements: make([]TemplatePlacement, 0, len(t.Towers))}
+	for _, tower := range t.Towers {
+		p := TemplatePlacement{
+			TowerType: tower.TowerType,
+			X:         x + float64(tower.DX)*cell,
+			Y:         y + float64(tower.DY)*cell,
+		}
+		p.Err = ruleErr
+		if p.Err == nil {
+			p.Err = g.placeTower(playerID, mods, p.TowerType, p.X, p.Y)
+		}
+		if p.Placed = p.Err == nil; p.Placed {
+			result.Placed++
+		}
+		result.Placements = append(result.Placements, p)
+	}
+	result.Gold = gold - g.gold(playerID)
+
+	logging.Infow("template_applied",
+		"game_id", g.id,
+		"player_id", playerID,
+		"template", t.Name,
+		"placed", result.Placed,
+		"towers", len(t.Towers),
+		"gold_spent", result.Gold)
+	return result
+}
@@ -0,0 +1,489 @@
+// Package tournament runs single-elimination brackets. Every match puts its
+// two players in rooms of their own that share a seed, so both face the same
+// waves; whoever gets further wins. Brackets live in memory and are lost on
+// restart.
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Bounds of a bracket
+const (
+	MinPlayers = 2
+	MaxPlayers = 32
+
+	DefaultVictoryWave = 10 // waves a match room is won at, unless chosen otherwise
+)
+
+// Tournament statuses
+const (
+	StatusRunning  = "running"
+	StatusFinished = "finished"
+)
+
+// Match statuses
+const (
+	MatchPending  = "pending" // waiting for the winners of earlier matches
+	MatchPlaying  = "playing"
+	MatchFinished = "finished"
+)
+
+// How a match was decided
+const (
+	ReasonResult    = "result"    // by the players' games
+	ReasonBye       = "bye"       // the player had no opponent
+	ReasonForfeit   = "forfeit"   // an admin forfeited the loser
+	ReasonClosed    = "closed"    // the loser's room was closed before their game ended
+	ReasonSurrender = "surrender" // the loser gave up
+	ReasonInactive  = "inactive"  // the loser didn't act for the inactivity timeout
+)
+
+var (
+	ErrNotFound      = errors.New("tournament not found")
+	ErrInvalid       = errors.New("invalid tournament")
+	ErrNotPlaying    = errors.New("player has no match in progress")
+	ErrMatchNotFound = errors.New("match not found")
+	ErrMatchOver     = errors.New("match is not in progress")
+	ErrNotInMatch    = errors.New("player is not in the match")
+)
+
+// Options describe a new bracket
+type Options struct {
+	Name        string
+	Players     []string // in seed order, the strongest first; top seeds get the byes
+	VictoryWave int      // 0 = DefaultVictoryWave
+	Seed        *int64   // seed of every match room; nil = a random one per match
+}
+
+// Slot is one player's side of a match
+type Slot struct {
+	PlayerID string `json:"playerId,omitempty"` // "" while the feeding match is undecided, or for a bye
+	GameID   string `json:"gameId,omitempty"`   // the player's room, once the match is on
+	Code     string `json:"code,omitempty"`
+
+	Done    bool   `json:"done"` // the player's game is over
+	Wave    int    `json:"wave"`
+	Score   int    `json:"score"`
+	Outcome string `json:"outcome,omitempty"` // "won" or "lost"
+}
+
+// Match pits the players of two slots against each other
+type Match struct {
+	ID     string  `json:"id"`   // unique across tournaments, e.g. for POST /match/:id/surrender
+	Name   string  `json:"name"` // e.g. "r2m1", the first match of round 2
+	Round  int     `json:"round"`
+	Status string  `json:"status"`
+	Slots  [2]Slot `json:"slots"`
+	Seed   int64   `json:"seed,omitempty"` // shared by both rooms
+
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Winner    string     `json:"winner,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// Result is a decided match between two players, e.g. for ratings. Byes
+// have none.
+type Result struct {
+	TournamentID string
+	MatchID      string
+	Winner       string
+	Loser        string
+	Reason       string
+}
+
+// State is a bracket as shown by the API
+type State struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Status      string     `json:"status"`
+	Players     []string   `json:"players"` // in seed order
+	VictoryWave int        `json:"victoryWave"`
+	Rounds      [][]Match  `json:"rounds"` // the first round first; each round has half the matches of the one before
+	Winner      string     `json:"winner,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+func (s *State) copy() State {
+	c := *s
+	c.Players = slices.Clone(s.Players)
+	c.Rounds = make([][]Match, len(s.Rounds))
+	for i, round := range s.Rounds {
+		c.Rounds[i] = slices.Clone(round)
+	}
+	return c
+}
+
+// roomRef locates the slot a room plays for
+type roomRef struct {
+	tournament   string
+	round, match int
+	slot         int
+}
+
+// Registry runs the brackets of a game manager
+type Registry struct {
+	manager *game.Manager
+
+	mu          sync.Mutex
+	tournaments map[string]*State
+	seeds       map[string]*int64  // tournament ID -> Options.Seed
+	rooms       map[string]roomRef // game ID -> slot, while the match is on
+	matches     map[string]roomRef // match ID -> match; slot unused
+	inactivity  time.Duration      // see SetInactivityTimeout
+	onResult    func(Result)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry(manager *game.Manager) *Registry {
+	return &Registry{
+		manager:     manager,
+		tournaments: make(map[string]*State),
+		seeds:       make(map[string]*int64),
+		rooms:       make(map[string]roomRef),
+		matches:     make(map[string]roomRef),
+	}
+}
+
+// SetInactivityTimeout sets how long a player may go without acting in a
+// match before SweepInactive forfeits them, 0 = never
+func (r *Registry) SetInactivityTimeout(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inactivity = d
+}
+
+// OnResult sets a function called with every match decided between two
+// players; it runs on its own goroutine
+func (r *Registry) OnResult(fn func(Result)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onResult = fn
+}
+
+// Create sets up a bracket and starts its first round. Players with a bye
+// go straight to the second round.
+func (r *Registry) Create(opts Options) (State, error) {
+	if n := len(opts.Players); n < MinPlayers || n > MaxPlayers {
+		return State{}, fmt.Errorf("%w: %d players, must be between %d and %d", ErrInvalid, n, MinPlayers, MaxPlayers)
+	}
+	seen := make(map[string]bool, len(opts.Players))
+	for _, p := range opts.Players {
+		if p == "" || seen[p] {
+			return State{}, fmt.Errorf("%w: player IDs must be unique and not empty", ErrInvalid)
+		}
+		seen[p] = true
+	}
+	if opts.VictoryWave < 0 {
+		return State{}, fmt.Errorf("%w: victory wave %d", ErrInvalid, opts.VictoryWave)
+	}
+	if opts.VictoryWave == 0 {
+		opts.VictoryWave = DefaultVictoryWave
+	}
+
+	size := 2
+	for size < len(opts.Players) {
+		size *= 2
+	}
+	t := &State{
+		ID:          uuid.New().String(),
+		Name:        opts.Name,
+		Status:      StatusRunning,
+		Players:     slices.Clone(opts.Players),
+		VictoryWave: opts.VictoryWave,
+		CreatedAt:   time.Now(),
+	}
+	for round, matches := 1, size/2; matches >= 1; round, matches = round+1, matches/2 {
+		ms := make([]Match, matches)
+		for i := range ms {
+			ms[i] = Match{ID: uuid.New().String(), Name: fmt.Sprintf("r%dm%d", round, i+1), Round: round, Status: MatchPending}
+		}
+		t.Rounds = append(t.Rounds, ms)
+	}
+	order := bracketOrder(size)
+	for i := range t.Rounds[0] {
+		for s := range 2 {
+			if seed := order[2*i+s]; seed <= len(t.Players) {
+				t.Rounds[0][i].Slots[s].PlayerID = t.Players[seed-1]
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tournaments[t.ID] = t
+	r.seeds[t.ID] = opts.Seed
+	for round := range t.Rounds {
+		for i, m := range t.Rounds[round] {
+			r.matches[m.ID] = roomRef{tournament: t.ID, round: round, match: i}
+		}
+	}
+	for i := range t.Rounds[0] {
+		r.ready(t, 0, i)
+	}
+	logging.Infow("tournament_created", "tournament_id", t.ID, "name", t.Name, "players", len(t.Players), "rounds", len(t.Rounds))
+	return t.copy(), nil
+}
+
+// bracketOrder returns the seeds of a bracket of size players in the order
+// they are paired, so that the top seeds meet last, e.g. 1 8 4 5 2 7 3 6
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// Get returns a bracket
+func (r *Registry) Get(id string) (State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tournaments[id]
+	if !ok {
+		return State{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return t.copy(), nil
+}
+
+// Forfeit makes a player lose the match they are playing; their opponent advances
+func (r *Registry) Forfeit(id, playerID string) (State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tournaments[id]
+	if !ok {
+		return State{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	for round := range t.Rounds {
+		for i, m := range t.Rounds[round] {
+			if m.Status != MatchPlaying {
+				continue
+			}
+			for s := range 2 {
+				if m.Slots[s].PlayerID == playerID {
+					r.decide(t, round, i, 1-s, ReasonForfeit)
+					return t.copy(), nil
+				}
+			}
+		}
+	}
+	return State{}, fmt.Errorf("%w: %s", ErrNotPlaying, playerID)
+}
+
+// Surrender makes a player give up a match they are playing; their opponent advances
+func (r *Registry) Surrender(matchID, playerID string) (State, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref, ok := r.matches[matchID]
+	if !ok {
+		return State{}, fmt.Errorf("%w: %s", ErrMatchNotFound, matchID)
+	}
+	t := r.tournaments[ref.tournament]
+	m := t.Rounds[ref.round][ref.match]
+	for s := range 2 {
+		if playerID != "" && m.Slots[s].PlayerID == playerID {
+			if m.Status != MatchPlaying {
+				return State{}, fmt.Errorf("%w: %s is %s", ErrMatchOver, m.Name, m.Status)
+			}
+			r.decide(t, ref.round, ref.match, 1-s, ReasonSurrender)
+			return t.copy(), nil
+		}
+	}
+	return State{}, fmt.Errorf("%w: %s", ErrNotInMatch, playerID)
+}
+
+// SweepInactive forfeits the players of running matches who haven't acted in
+// their room for the inactivity timeout, counted from the start of the match.
+// Players whose game is over are waiting for their opponent and never time out.
+// When both players are inactive, the one idle for longer loses.
+func (r *Registry) SweepInactive(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inactivity <= 0 {
+		return
+	}
+	for _, t := range r.tournaments {
+		for round := range t.Rounds {
+			for i, m := range t.Rounds[round] {
+				if m.Status != MatchPlaying || m.StartedAt == nil {
+					continue
+				}
+				loser, longest := -1, r.inactivity
+				for s, slot := range m.Slots {
+					if slot.Done {
+						continue
+					}
+					last := *m.StartedAt
+					if g, err := r.manager.GetGame(slot.GameID); err == nil {
+						if at, ok := g.LastActive(slot.PlayerID); ok && at.After(last) {
+							last = at
+						}
+					}
+					if idle := now.Sub(last); idle > longest {
+						loser, longest = s, idle
+					}
+				}
+				if loser >= 0 {
+					logging.Infow("tournament_player_inactive", "tournament_id", t.ID, "match", m.Name,
+						"player_id", m.Slots[loser].PlayerID, "idle_s", longest.Seconds())
+					r.decide(t, round, i, 1-loser, ReasonInactive)
+				}
+			}
+		}
+	}
+}
+
+// HandleEvent records the results of match rooms; it is safe to register as
+// an events.Listener
+func (r *Registry) HandleEvent(ev events.Event) {
+	if ev.Type != events.GameOver {
+		return
+	}
+	// Listeners run on the game's loop; starting the next round creates rooms,
+	// which must not wait on it
+	go r.record(ev)
+}
+
+func (r *Registry) record(ev events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref, ok := r.rooms[ev.GameID]
+	if !ok {
+		return
+	}
+	t := r.tournaments[ref.tournament]
+	m := &t.Rounds[ref.round][ref.match]
+	slot := &m.Slots[ref.slot]
+	slot.Done, slot.Wave, slot.Score, slot.Outcome = true, ev.Wave, ev.Score, ev.Outcome
+	delete(r.rooms, ev.GameID)
+	logging.Infow("tournament_result", "tournament_id", t.ID, "match", m.Name, "player_id", slot.PlayerID,
+		"wave", ev.Wave, "score", ev.Score, "outcome", ev.Outcome)
+
+	if m.Slots[0].Done && m.Slots[1].Done {
+		r.decide(t, ref.round, ref.match, better(m.Slots), ReasonResult)
+	}
+}
+
+// RoomsClosed forfeits the players whose rooms were removed before their game
+// ended, e.g. for being idle, as they can no longer finish the match
+func (r *Registry) RoomsClosed(gameIDs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range gameIDs {
+		ref, ok := r.rooms[id]
+		if !ok {
+			continue
+		}
+		t := r.tournaments[ref.tournament]
+		if t.Rounds[ref.round][ref.match].Status == MatchPlaying {
+			r.decide(t, ref.round, ref.match, 1-ref.slot, ReasonClosed)
+		}
+	}
+}
+
+// better returns the slot that did better: the later wave, then the higher
+// score, then a won game; the higher seed wins a tie
+func better(slots [2]Slot) int {
+	a, b := slots[0], slots[1]
+	switch {
+	case a.Wave != b.Wave:
+		return boolSlot(b.Wave > a.Wave)
+	case a.Score != b.Score:
+		return boolSlot(b.Score > a.Score)
+	default:
+		return boolSlot(b.Outcome == game.OutcomeWon && a.Outcome != game.OutcomeWon)
+	}
+}
+
+func boolSlot(second bool) int {
+	if second {
+		return 1
+	}
+	return 0
+}
+
+// ready starts a match once both of its players are known, or passes a player
+// without an opponent through (caller must hold r.mu)
+func (r *Registry) ready(t *State, round, i int) {
+	m := &t.Rounds[round][i]
+	a, b := m.Slots[0].PlayerID, m.Slots[1].PlayerID
+	switch {
+	case round == 0 && (a == "" || b == ""):
+		r.decide(t, round, i, boolSlot(a == ""), ReasonBye)
+	case a != "" && b != "":
+		r.start(t, round, i)
+	}
+}
+
+// start creates the rooms of a match (caller must hold r.mu)
+func (r *Registry) start(t *State, round, i int) {
+	m := &t.Rounds[round][i]
+	m.Seed = rand.Int64()
+	if seed := r.seeds[t.ID]; seed != nil {
+		m.Seed = *seed
+	}
+	victory := t.VictoryWave
+	opts := game.GameOptions{VictoryWave: &victory, Seed: &m.Seed, Private: true, Lobby: true, MaxPlayers: 1}
+	now := time.Now()
+	m.Status, m.StartedAt = MatchPlaying, &now
+	for s := range 2 {
+		slot := &m.Slots[s]
+		g, err := r.manager.CreateGameWithOptions(opts)
+		if err != nil {
+			// The match stays on without this room; an admin can forfeit the player
+			logging.Errorw("tournament_room_failed", "tournament_id", t.ID, "match", m.ID, "player_id", slot.PlayerID, "error", err)
+			continue
+		}
+		g.Join(slot.PlayerID)
+		g.Start()
+		slot.GameID, slot.Code = g.GetID(), g.Code()
+		r.rooms[slot.GameID] = roomRef{tournament: t.ID, round: round, match: i, slot: s}
+	}
+	logging.Infow("tournament_match_started", "tournament_id", t.ID, "match", m.Name,
+		"players", []string{m.Slots[0].PlayerID, m.Slots[1].PlayerID}, "seed", m.Seed)
+}
+
+// decide ends a match with the player of slot winner advancing (caller must hold r.mu)
+func (r *Registry) decide(t *State, round, i, winner int, reason string) {
+	m := &t.Rounds[round][i]
+	m.Status, m.Winner, m.Reason = MatchFinished, m.Slots[winner].PlayerID, reason
+	for _, slot := range m.Slots {
+		delete(r.rooms, slot.GameID)
+	}
+	loser := m.Slots[1-winner]
+	// The loser's game may still run when they didn't play the match out
+	if g, err := r.manager.GetGame(loser.GameID); err == nil && loser.GameID != "" {
+		g.ForceEnd(reason)
+	}
+	logging.Infow("tournament_match_decided", "tournament_id", t.ID, "match", m.Name, "winner", m.Winner, "reason", reason)
+	if reason != ReasonBye && r.onResult != nil {
+		go r.onResult(Result{TournamentID: t.ID, MatchID: m.ID, Winner: m.Winner, Loser: loser.PlayerID, Reason: reason})
+	}
+
+	if round == len(t.Rounds)-1 {
+		now := time.Now()
+		t.Status, t.Winner, t.FinishedAt = StatusFinished, m.Winner, &now
+		delete(r.seeds, t.ID)
+		logging.Infow("tournament_finished", "tournament_id", t.ID, "winner", t.Winner)
+		return
+	}
+	next := &t.Rounds[round+1][i/2]
+	next.Slots[i%2].PlayerID = m.Winner
+	r.ready(t, round+1, i/2)
+}
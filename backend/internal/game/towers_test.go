@@ -0,0 +1,40 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+)
+
+func TestTowerLimit(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Placement.MaxTowers = 2
+	g := NewGame("towers", cfg)
+	g.state.Gold = 1 << 20
+
+	placed := 0
+	for x := 20.0; x < float64(cfg.Map.Width) && placed < 3; x += 60 {
+		for y := 20.0; y < float64(cfg.Map.Height) && placed < 3; y += 60 {
+			if !g.isValidPlacement(ecs.Position{X: x, Y: y}) {
+				continue
+			}
+			err := g.AddTower("basic", x, y)
+			if placed < 2 {
+				if err != nil {
+					t.Fatalf("tower %d: %v", placed+1, err)
+				}
+			} else if !errors.Is(err, ErrMaxTowers) {
+				t.Fatalf("tower past the limit: %v, want ErrMaxTowers", err)
+			}
+			placed++
+		}
+	}
+	if placed < 3 {
+		t.Fatalf("found room for %d towers only", placed)
+	}
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/logging"
+)
+
+// maxBalanceChanges bounds the balance changes a manager remembers
+const maxBalanceChanges = 100
+
+// ErrNoOverrides is returned for a balance change that changes nothing
+var ErrNoOverrides = errors.New("no balance overrides given")
+
+// BalanceChange is one entry of the balance audit trail
+type BalanceChange struct {
+	ID        uint64                  `json:"id"`
+	Time      time.Time               `json:"time"`
+	GameID    string                  `json:"gameId,omitempty"` // "" for the balance of new rooms
+	Overrides config.BalanceOverrides `json:"overrides"`
+	RequestID string                  `json:"requestId,omitempty"` // of the request in the audit log
+	ClientIP  string                  `json:"clientIp,omitempty"`
+}
+
+// TuneBalance applies balance overrides to the running game on top of what
+// it was tuned to before. Tower types change for towers already built too,
+// in proportion so research and other bonuses are kept; enemy and wave
+// changes apply to enemies spawned from now on. Reloading the config keeps
+// them.
+func (g *Game) TuneBalance(o config.BalanceOverrides) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tuned, err := g.config.WithOverrides(o)
+	if err != nil {
+		return err
+	}
+	for _, t := range g.world.GetTowers() {
+		before, after := g.config.Towers[t.TowerType], tuned.Towers[t.TowerType]
+		t.Damage = int(math.Round(rescale(float64(t.Damage), float64(before.Damage), float64(after.Damage))))
+		t.Range = rescale(t.Range, before.Range, after.Range)
+		t.FireRate = rescale(t.FireRate, before.FireRate, after.FireRate)
+	}
+	// Systems share the game's config, so it is changed in place
+	for name, tc := range tuned.Towers {
+		g.config.Towers[name] = tc
+	}
+	for name, ec := range tuned.Enemies {
+		g.config.Enemies[name] = ec
+	}
+	g.config.Waves.HPScalePerWave = tuned.Waves.HPScalePerWave
+	g.overrides = g.overrides.Merge(o)
+	g.replay.rebalanced = true
+	g.markChanged()
+
+	logging.Infow("game_balance_tuned", "game_id", g.id, "towers", len(o.Towers), "enemies", len(o.Enemies))
+	return nil
+}
+
+// rescale changes a stat derived from base in proportion to base becoming
+// updated; with no base to compare to it becomes updated
+func rescale(stat, base, updated float64) float64 {
+	if base == 0 {
+		return updated
+	}
+	return stat * updated / base
+}
+
+// Balance returns a copy of the config the game runs on and the overrides
+// it was tuned with
+func (g *Game) Balance() (*config.GameConfig, config.BalanceOverrides) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.config.Clone(), g.overrides.Merge(config.BalanceOverrides{})
+}
+
+// TuneBalance applies balance overrides to a running game, or with an empty
+// GameID to the config of rooms created from now on, and adds the change to
+// the audit trail. Overrides for new rooms are kept when the config is
+// reloaded.
+func (m *Manager) TuneBalance(change BalanceChange) (BalanceChange, error) {
+	if change.Overrides.Empty() {
+		return BalanceChange{}, ErrNoOverrides
+	}
+	if change.GameID != "" {
+		game, err := m.GetGame(change.GameID)
+		if err != nil {
+			return BalanceChange{}, err
+		}
+		if err := game.TuneBalance(change.Overrides); err != nil {
+			return BalanceChange{}, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if change.GameID == "" {
+		tuned, err := m.config.WithOverrides(change.Overrides)
+		if err != nil {
+			return BalanceChange{}, err
+		}
+		m.config = tuned
+		m.overrides = m.overrides.Merge(change.Overrides)
+		logging.Infow("balance_tuned", "towers", len(change.Overrides.Towers), "enemies", len(change.Overrides.Enemies))
+	}
+	m.balanceSeq++
+	change.ID = m.balanceSeq
+	change.Time = time.Now()
+	m.balanceLog = append(m.balanceLog, change)
+	if len(m.balanceLog) > maxBalanceChanges {
+		m.balanceLog = slices.Delete(m.balanceLog, 0, len(m.balanceLog)-maxBalanceChanges)
+	}
+	return change, nil
+}
+
+// Balance returns the config of a running game, or with an empty gameID of
+// new rooms, with the overrides applied to it and their changes, newest first
+func (m *Manager) Balance(gameID string) (*config.GameConfig, config.BalanceOverrides, []BalanceChange, error) {
+	var cfg *config.GameConfig
+	var overrides config.BalanceOverrides
+	if gameID != "" {
+		game, err := m.GetGame(gameID)
+		if err != nil {
+			return nil, config.BalanceOverrides{}, nil, err
+		}
+		cfg, overrides = game.Balance()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if gameID == "" {
+		cfg, overrides = m.config, m.overrides.Merge(config.BalanceOverrides{})
+	}
+	history := []BalanceChange{}
+	for _, change := range slices.Backward(m.balanceLog) {
+		if change.GameID == gameID {
+			history = append(history, change)
+		}
+	}
+	return cfg, overrides, history, nil
+}
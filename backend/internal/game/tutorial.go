@@ -0,0 +1,147 @@
+package game
+
+import (
+	"errors"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/i18n"
+	"tower-defense/internal/logging"
+)
+
+// Tutorial steps, in order. Each step only allows its prompted action.
+const (
+	TutorialPlaceTower  = "place_tower"
+	TutorialBuildWall   = "build_wall"
+	TutorialSurviveWave = "survive_wave"
+	TutorialDone        = "done"
+)
+
+// ErrTutorialStep is returned for an action the current tutorial step doesn't prompt
+var ErrTutorialStep = errors.New("action not allowed at this tutorial step")
+
+// tutorialSteps are the steps of the guided tutorial, each prompted with the
+// message "tutorial.<step>"
+var tutorialSteps = []string{TutorialPlaceTower, TutorialBuildWall, TutorialSurviveWave}
+
+// TutorialState is the progress of a tutorial game, included in its snapshots
+type TutorialState struct {
+	PlayerID  string `json:"playerId"`
+	Step      string `json:"step"` // one of the Tutorial* steps
+	Index     int    `json:"index"`
+	Total     int    `json:"total"`
+	Prompt    string `json:"prompt,omitempty"`    // in English in snapshots, see Localized
+	PromptKey string `json:"promptKey,omitempty"` // message key of the prompt, for clients that translate it
+	Completed bool   `json:"completed"`
+}
+
+// Localized returns the state with the prompt in lang
+func (s TutorialState) Localized(lang string) TutorialState {
+	if s.PromptKey != "" {
+		s.Prompt = i18n.T(lang, s.PromptKey)
+	}
+	return s
+}
+
+// tutorial tracks the guided tutorial of a game
+type tutorial struct {
+	playerID string
+	step     int // index into tutorialSteps; len(tutorialSteps) once completed
+}
+
+func (t *tutorial) current() string {
+	if t.step >= len(tutorialSteps) {
+		return TutorialDone
+	}
+	return tutorialSteps[t.step]
+}
+
+func (t *tutorial) state() *TutorialState {
+	s := &TutorialState{PlayerID: t.playerID, Step: t.current(), Index: t.step, Total: len(tutorialSteps)}
+	if t.step < len(tutorialSteps) {
+		s.PromptKey = "tutorial." + tutorialSteps[t.step]
+		s.Prompt = i18n.T(i18n.Default, s.PromptKey)
+	} else {
+		s.Completed = true
+	}
+	return s
+}
+
+// StartTutorial turns the game into a guided tutorial for playerID: waves are
+// held and building is gated until the player completes each prompted step.
+// A reset starts the tutorial over.
+func (g *Game) StartTutorial(playerID string) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+
+	g.tutorial = &tutorial{playerID: playerID}
+	g.waveSystem.SetHeld(true, g.world.Now())
+	g.emitTutorialStep()
+	g.markChanged()
+}
+
+// Tutorial returns the tutorial progress, or nil if the game is not a tutorial
+func (g *Game) Tutorial() *TutorialState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.tutorialState()
+}
+
+// tutorialState returns the tutorial progress or nil (caller must hold g.mu)
+func (g *Game) tutorialState() *TutorialState {
+	if g.tutorial == nil {
+		return nil
+	}
+	return g.tutorial.state()
+}
+
+// checkTutorial rejects an action the current tutorial step doesn't prompt (caller must hold g.mu)
+func (g *Game) checkTutorial(step string) error {
+	if g.tutorial == nil || g.tutorial.current() == TutorialDone || g.tutorial.current() == step {
+		return nil
+	}
+	return ErrTutorialStep
+}
+
+// advanceTutorial moves past step if it is the current one (caller must hold g.mu)
+func (g *Game) advanceTutorial(step string) {
+	if g.tutorial == nil || g.tutorial.current() != step {
+		return
+	}
+	g.tutorial.step++
+	switch g.tutorial.current() {
+	case TutorialSurviveWave, TutorialDone:
+		// Let the first wave in; after the tutorial waves run as usual
+		g.waveSystem.SetHeld(false, g.world.Now())
+	}
+	g.emitTutorialStep()
+}
+
+// tutorialWaveStarted holds further waves until the player clears the tutorial's
+// first one (caller must hold g.mu)
+func (g *Game) tutorialWaveStarted() {
+	if g.tutorial != nil && g.tutorial.current() == TutorialSurviveWave {
+		g.waveSystem.SetHeld(true, g.world.Now())
+	}
+}
+
+// emitTutorialStep announces the current tutorial step (caller must hold g.mu)
+func (g *Game) emitTutorialStep() {
+	t := g.tutorial
+	if t.current() == TutorialDone {
+		g.emit(events.Event{Type: events.TutorialCompleted, Wave: g.state.Wave, PlayerID: t.playerID})
+		logging.Infow("tutorial_completed", "game_id", g.id, "player_id", t.playerID)
+		return
+	}
+	g.emit(events.Event{Type: events.TutorialStep, Wave: g.state.Wave, PlayerID: t.playerID, Detail: t.current()})
+}
+
+// resetTutorial starts a tutorial over after a game reset (caller must hold g.mu)
+func (g *Game) resetTutorial() {
+	if g.tutorial == nil {
+		return
+	}
+	g.tutorial.step = 0
+	g.waveSystem.SetHeld(true, g.world.Now())
+	g.emitTutorialStep()
+}
@@ -0,0 +1,35 @@
+package game
+
+import "context"
+
+// markChanged advances the state version and wakes WaitForChange callers (caller must hold g.mu)
+func (g *Game) markChanged() {
+	g.version++
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// Version returns the state version, which increases every time the game state changes
+func (g *Game) Version() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.version
+}
+
+// WaitForChange blocks until the state version is past since or ctx is done.
+// It returns the current version and whether it advanced.
+func (g *Game) WaitForChange(ctx context.Context, since uint64) (uint64, bool) {
+	for {
+		g.mu.RLock()
+		version, changed := g.version, g.changed
+		g.mu.RUnlock()
+		if version > since {
+			return version, true
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return version, false
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+var (
+	ErrNoWallets         = errors.New("room has no player wallets")
+	ErrTransfersDisabled = errors.New("gold transfers are disabled")
+	ErrInvalidTransfer   = errors.New("invalid gold transfer")
+)
+
+// Transfer is a gold transfer between the wallets of two players
+type Transfer struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   int    `json:"amount"`   // taken from the sender
+	Tax      int    `json:"tax"`      // lost on the way, see economy.wallets.transfer_tax
+	Received int    `json:"received"` // added to the recipient
+}
+
+// enableWallets gives every player who joins a wallet of their own, filled
+// with the map's starting gold. The shared pool starts empty and only collects
+// gold nobody owns, e.g. kills by unowned towers (caller must hold g.mu).
+func (g *Game) enableWallets() {
+	g.wallets = make(map[string]int)
+	for id := range g.players {
+		g.wallets[id] = g.startingGold
+	}
+	g.state.Gold = 0
+	g.markChanged()
+}
+
+// Wallets reports whether players of the game have wallets of their own
+func (g *Game) Wallets() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.wallets != nil
+}
+
+// openWallet gives a player who joined their starting gold, once (caller must hold g.mu)
+func (g *Game) openWallet(playerID string) {
+	if g.wallets == nil || playerID == "" {
+		return
+	}
+	if _, ok := g.wallets[playerID]; !ok {
+		g.wallets[playerID] = g.startingGold
+		g.markChanged()
+	}
+}
+
+// walletBalances copies the wallets, nil in games without them (caller must hold g.mu)
+func (g *Game) walletBalances() map[string]int {
+	return maps.Clone(g.wallets)
+}
+
+// restoreWallets puts back the wallets of a save; players who joined since
+// keep a wallet with the starting gold. Saves of games without wallets
+// restore nothing (caller must hold g.mu).
+func (g *Game) restoreWallets(saved map[string]int) {
+	if g.wallets == nil {
+		return
+	}
+	clear(g.wallets)
+	maps.Copy(g.wallets, saved)
+	for id := range g.players {
+		g.openWallet(id)
+	}
+}
+
+// payer returns whose gold a player spends and earns: their own wallet, or ""
+// for the shared pool in games without wallets (caller must hold g.mu)
+func (g *Game) payer(playerID string) string {
+	if _, ok := g.wallets[playerID]; ok {
+		return playerID
+	}
+	return ""
+}
+
+// gold returns the gold a player can spend (caller must hold g.mu)
+func (g *Game) gold(playerID string) int {
+	if p := g.payer(playerID); p != "" {
+		return g.wallets[p]
+	}
+	return g.state.Gold
+}
+
+// addGold credits a player with gold, or takes it away when negative (caller must hold g.mu)
+func (g *Game) addGold(playerID string, amount int) {
+	if p := g.payer(playerID); p != "" {
+		g.wallets[p] += amount
+		return
+	}
+	g.state.Gold += amount
+}
+
+// towerOwner returns the player who built a tower, "" if unowned or gone (caller must hold g.mu)
+func (g *Game) towerOwner(towerID string) string {
+	if entity, ok := g.world.GetEntity(towerID); ok {
+		if tower, ok := entity.(*ecs.TowerEntity); ok {
+			return tower.OwnerID
+		}
+	}
+	return ""
+}
+
+// payInterest pays interest on the shared pool and on every wallet (caller must hold g.mu)
+func (g *Game) payInterest() {
+	g.economySystem.PayInterest(g.state.Gold)
+	for _, id := range slices.Sorted(maps.Keys(g.wallets)) {
+		interest := g.economySystem.ProjectedInterest(g.wallets[id])
+		if interest <= 0 {
+			continue
+		}
+		g.wallets[id] += interest
+		g.emit(events.Event{Type: events.InterestPaid, Wave: g.state.Wave, PlayerID: id, Gold: interest})
+	}
+}
+
+// payWaveBonus splits a wave bonus evenly between the wallets; what doesn't
+// split evenly, or all of it without wallets, goes to the shared pool
+// (caller must hold g.mu)
+func (g *Game) payWaveBonus(gold int) {
+	pool := gold
+	if len(g.wallets) > 0 {
+		share := gold / len(g.wallets)
+		pool = gold % len(g.wallets)
+		for _, id := range slices.Sorted(maps.Keys(g.wallets)) {
+			if share <= 0 {
+				break
+			}
+			g.wallets[id] += share
+			g.emit(events.Event{Type: events.WaveBonus, Wave: g.state.Wave, PlayerID: id, Gold: share, Lives: g.state.Lives})
+		}
+	}
+	if pool > 0 {
+		g.state.Gold += pool
+		g.emit(events.Event{Type: events.WaveBonus, Wave: g.state.Wave, Gold: pool, Lives: g.state.Lives})
+	}
+}
+
+// TransferGold gives amount of the sender's gold to another player of the
+// room, minus the transfer tax. Both players need a wallet; it fails with
+// ErrNoWallets in games without them.
+func (g *Game) TransferGold(from, to string, amount int) (*Transfer, error) {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(from)
+
+	if g.wallets == nil {
+		return nil, ErrNoWallets
+	}
+	if !g.config.Economy.Wallets.Transfers {
+		return nil, ErrTransfersDisabled
+	}
+	if g.state.GameOver {
+		return nil, ErrGameFinished
+	}
+	if err := g.checkRules(config.ActionTransfer); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive, got %d", ErrInvalidTransfer, amount)
+	}
+	if _, ok := g.wallets[from]; !ok {
+		return nil, ErrNotJoined
+	}
+	if _, ok := g.wallets[to]; !ok || to == from {
+		return nil, fmt.Errorf("%w: %q is not another player of the room", ErrInvalidTransfer, to)
+	}
+	if g.wallets[from] < amount {
+		return nil, ErrNotEnoughGold
+	}
+
+	tax := int(math.Floor(float64(amount) * g.config.Economy.Wallets.TransferTax))
+	t := &Transfer{From: from, To: to, Amount: amount, Tax: tax, Received: amount - tax}
+	g.wallets[from] -= t.Amount
+	g.wallets[to] += t.Received
+	g.emit(events.Event{Type: events.GoldTransferred, Wave: g.state.Wave, PlayerID: from, Gold: t.Received, Detail: to})
+	g.markChanged()
+
+	logging.Infow("gold_transferred",
+		"game_id", g.id,
+		"from", from,
+		"to", to,
+		"amount", amount,
+		"tax", tax)
+	return t, nil
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"math"
+
+	"tower-defense/internal/game/config"
+	"tower-defense/internal/game/ecs"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// AddWallForPlayer builds a wall on the path. Enemies that reach it stop and attack
+// it until it breaks. An empty player ID builds an unowned wall.
+func (g *Game) AddWallForPlayer(playerID string, x, y float64) error {
+	g.mu.Lock()
+	defer g.flushEvents()
+	defer g.mu.Unlock()
+	g.touch(playerID)
+
+	if g.state.GameOver {
+		return ErrGameFinished
+	}
+	if err := g.checkRules(config.ActionWall); err != nil {
+		return err
+	}
+	if err := g.validateCoordinates(x, y); err != nil {
+		return err
+	}
+
+	if err := g.checkTutorial(TutorialBuildWall); err != nil {
+		return err
+	}
+
+	wallCfg := g.config.Walls
+	if g.gold(playerID) < wallCfg.Cost {
+		return ErrNotEnoughGold
+	}
+
+	pos := ecs.Position{X: x, Y: y}
+	if !g.isValidWallPlacement(pos) {
+		return ErrInvalidPlacement
+	}
+
+	wall := g.factory.CreateWall(pos)
+	wall.OwnerID = playerID
+	g.world.AddEntity(wall)
+	g.addGold(playerID, -wallCfg.Cost)
+	g.emit(events.Event{
+		Type:     events.WallPlaced,
+		Wave:     g.state.Wave,
+		PlayerID: playerID,
+		EntityID: wall.ID,
+		Gold:     wallCfg.Cost,
+	})
+	g.advanceTutorial(TutorialBuildWall)
+	g.markChanged()
+
+	logging.Infow("wall_placed",
+		"game_id", g.id,
+		"player_id", playerID,
+		"x", x, "y", y,
+		"gold_remaining", g.gold(playerID))
+
+	return nil
+}
+
+// isValidWallPlacement checks that a wall sits on a path, away from other walls (caller must hold g.mu)
+func (g *Game) isValidWallPlacement(pos ecs.Position) bool {
+	wallCfg := g.config.Walls
+	if g.world.WallCount() >= wallCfg.MaxWalls {
+		return false
+	}
+
+	onPath := false
+	halfWidth := g.config.Map.PathHalfWidth
+	for _, path := range g.movementSystem.GetPaths() {
+		for i := 0; i < len(path)-1 && !onPath; i++ {
+			// distanceToSegment returns the squared distance
+			onPath = distanceToSegment(pos, path[i], path[i+1]) <= halfWidth*halfWidth
+		}
+	}
+	if !onPath {
+		return false
+	}
+
+	for _, wall := range g.world.GetWalls() {
+		if math.Hypot(pos.X-wall.Position.X, pos.Y-wall.Position.Y) < wallCfg.MinSpacing {
+			return false
+		}
+	}
+	return true
+}
+
+// convertWalls converts wall entities to DTOs (caller must hold g.mu)
+func (g *Game) convertWalls() []WallDTO {
+	walls := g.world.GetWalls()
+	dtos := make([]WallDTO, 0, len(walls))
+	for _, w := range walls {
+		dtos = append(dtos, WallDTO{
+			ID:       w.ID,
+			Position: PosDTO{X: w.Position.X, Y: w.Position.Y},
+			HP:       w.HP,
+			MaxHP:    w.MaxHP,
+			Radius:   w.Radius,
+			OwnerID:  w.OwnerID,
+		})
+	}
+	return dtos
+}
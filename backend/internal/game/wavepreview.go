@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math"
+
+	"tower-defense/internal/game/config"
+)
+
+// Limits of the upcoming waves preview
+const (
+	DefaultWavePreviewCount = 5
+	MaxWavePreviewCount     = 20
+)
+
+// WavePreview describes a wave that hasn't started yet, computed from the config
+type WavePreview struct {
+	Wave    int                `json:"wave"`
+	Total   int                `json:"total"`   // enemies in the wave
+	TotalHP int                `json:"totalHp"` // HP of all of them, shields excluded
+	Boss    bool               `json:"boss,omitempty"`
+	Pattern string             `json:"pattern"` // spawn pattern: burst, trickle, alternate or squads
+	Enemies []WaveEnemyPreview `json:"enemies"`
+
+	Modifier *WaveModifierInfo `json:"modifier,omitempty"` // already applied to counts and HP
+}
+
+// WaveModifierInfo is the modifier a wave gets, e.g. frenzy
+type WaveModifierInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// WaveEnemyPreview is one enemy type of a previewed wave
+type WaveEnemyPreview struct {
+	EnemyType string `json:"enemyType"`
+	Count     int    `json:"count"`
+	HP        int    `json:"hp"` // per enemy, scaled for the wave
+}
+
+// previewWave computes the preview of a wave (caller must hold g.mu)
+func (g *Game) previewWave(wave int) WavePreview {
+	p := WavePreview{Wave: wave, Pattern: g.config.GetSpawnPattern(wave).Type, Enemies: []WaveEnemyPreview{}}
+	id := g.waveSystem.ModifierFor(wave)
+	mod := g.config.Waves.Modifiers.Registry[id]
+	p.Modifier = g.waveModifierInfo(id)
+	for _, tc := range g.config.WaveEnemyCounts(wave) {
+		ec, err := g.config.GetEnemyConfig(tc.EnemyType)
+		if err != nil {
+			continue
+		}
+		hp := g.config.ScaleEnemyHP(ec.HP, wave)
+		count := tc.Count
+		if id != "" {
+			hp = max(int(math.Round(float64(hp)*config.Factor(mod.HP))), 1)
+			count = config.ScaleCount(count, mod.Count)
+		}
+		p.Enemies = append(p.Enemies, WaveEnemyPreview{EnemyType: tc.EnemyType, Count: count, HP: hp})
+		p.Total += count
+		p.TotalHP += count * hp
+		if _, isBoss := g.config.GetBossConfig(tc.EnemyType); isBoss {
+			p.Boss = true
+		}
+	}
+	return p
+}
+
+// waveModifierInfo describes a wave modifier for clients, nil for "" (caller must hold g.mu)
+func (g *Game) waveModifierInfo(id string) *WaveModifierInfo {
+	if id == "" {
+		return nil
+	}
+	return &WaveModifierInfo{ID: id, Description: g.config.Waves.Modifiers.Registry[id].Description}
+}
+
+// UpcomingWaves previews the next count waves after the current one
+func (g *Game) UpcomingWaves(count int) []WavePreview {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	current := g.waveSystem.GetCurrentWave()
+	previews := make([]WavePreview, 0, count)
+	for i := 1; i <= count; i++ {
+		previews = append(previews, g.previewWave(current+i))
+	}
+	return previews
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+)
+
+// Limits of a wave dry run
+const (
+	dryRunStep    = 0.05  // simulated seconds per tick, the longest tick a live game takes
+	dryRunTimeout = 300.0 // simulated seconds before a dry run gives up on the wave
+)
+
+// ErrNoNextWave is returned when there is no wave left to simulate
+var ErrNoNextWave = errors.New("no wave left to simulate")
+
+// WaveSimulation is the projected outcome of the next wave, from a dry run of
+// a copy of the game. The run starts from the current field, so enemies still
+// alive from the current wave count too.
+type WaveSimulation struct {
+	Wave              int            `json:"wave"`    // the wave simulated
+	Enemies           int            `json:"enemies"` // enemies the wave spawns
+	Killed            int            `json:"killed"`
+	Leaked            int            `json:"leaked"`
+	LeaksByEnemyType  map[string]int `json:"leaksByEnemyType"`
+	LivesLost         int            `json:"livesLost"`
+	Lives             int            `json:"lives"`    // lives left when the wave is over
+	Holds             bool           `json:"holds"`    // the wave is beaten without losing a life
+	GameOver          bool           `json:"gameOver"` // the wave would end the game
+	Damage            []TowerDamage  `json:"damage"`   // by tower, most first
+	DamageByTowerType map[string]int `json:"damageByTowerType"`
+	Duration          float64        `json:"duration"`           // simulated seconds until the wave was over
+	TimedOut          bool           `json:"timedOut,omitempty"` // not over after dryRunTimeout seconds
+}
+
+// TowerDamage is what one tower did during a dry run
+type TowerDamage struct {
+	TowerID   string `json:"towerId"`
+	TowerType string `json:"towerType"`
+	Damage    int    `json:"damage"`
+	Kills     int    `json:"kills"`
+}
+
+// SimulateNextWave runs a fork of the game through the next wave as fast as
+// it can and reports how it went. The game itself is not touched; the fork
+// continues from the current field and RNG state, so the projection is exact
+// until players act.
+func (g *Game) SimulateNextWave() (*WaveSimulation, error) {
+	g.mu.RLock()
+	now := g.world.Now()
+	if g.state.GameOver {
+		g.mu.RUnlock()
+		return nil, ErrGameFinished
+	}
+	if last := g.config.Game.VictoryWave; last > 0 && g.state.Wave >= last {
+		g.mu.RUnlock()
+		return nil, ErrNoNextWave
+	}
+	sim := g.fork(g.id+":dry-run", now)
+	g.mu.RUnlock()
+
+	started := time.Now()
+	result := sim.runNextWave(now)
+	logging.Infow("wave_simulated", "game_id", g.id, "wave", result.Wave, "leaked", result.Leaked,
+		"lives_lost", result.LivesLost, "simulated_s", result.Duration, "took_ms", time.Since(started).Milliseconds())
+	return result, nil
+}
+
+// runNextWave steps a dry run fork with a simulated clock until the wave after the
+// current one has spawned and left the field, the game ends or the run times out
+func (g *Game) runNextWave(start time.Time) *WaveSimulation {
+	clock := start
+	g.world.SetClock(func() time.Time { return clock })
+
+	type baseline struct{ damage, kills int }
+	before := make(map[string]baseline)
+	for _, t := range g.world.GetTowers() {
+		before[t.ID] = baseline{t.Stats.Damage, t.Stats.Kills}
+	}
+	livesBefore := g.state.Lives
+
+	result := &WaveSimulation{
+		Wave:              g.state.Wave + 1,
+		LeaksByEnemyType:  make(map[string]int),
+		DamageByTowerType: make(map[string]int),
+		Damage:            []TowerDamage{},
+	}
+	step := time.Duration(dryRunStep * float64(time.Second))
+	started := false
+	for elapsed := 0.0; ; elapsed += dryRunStep {
+		if elapsed >= dryRunTimeout {
+			result.TimedOut = true
+			break
+		}
+		clock = clock.Add(step)
+		g.systemManager.Update(g.world, dryRunStep)
+		g.trackWaveProgress()
+		result.Duration = elapsed + dryRunStep
+
+		if !started && g.state.Wave >= result.Wave {
+			// Only this wave is simulated; hold the ones after it
+			started = true
+			g.waveSystem.SetHeld(true, g.world.Now())
+			for _, n := range g.waveSystem.Composition() {
+				result.Enemies += n
+			}
+		}
+		if g.state.GameOver || started && g.waveSystem.Cleared() {
+			break
+		}
+	}
+
+	for _, ev := range g.pendingEvents {
+		switch ev.Type {
+		case events.EnemyKilled:
+			result.Killed++
+		case events.EnemyLeaked:
+			result.Leaked++
+			result.LeaksByEnemyType[ev.EnemyType]++
+		}
+	}
+	for _, t := range g.world.GetTowers() {
+		b := before[t.ID]
+		d := TowerDamage{TowerID: t.ID, TowerType: t.TowerType, Damage: t.Stats.Damage - b.damage, Kills: t.Stats.Kills - b.kills}
+		if d.Damage > 0 || d.Kills > 0 {
+			result.Damage = append(result.Damage, d)
+			result.DamageByTowerType[t.TowerType] += d.Damage
+		}
+	}
+	sort.SliceStable(result.Damage, func(i, j int) bool { return result.Damage[i].Damage > result.Damage[j].Damage })
+
+	result.Lives = max(g.state.Lives, 0)
+	result.LivesLost = max(livesBefore-g.state.Lives, 0)
+	result.GameOver = g.state.GameOver
+	result.Holds = started && !result.TimedOut && !result.GameOver && result.LivesLost == 0
+	return result
+}
@@ -0,0 +1,1126 @@
+// gRPC API of the tower defense server. Served on GRPC_PORT next to the HTTP API.
+// After a change, regenerate internal/grpcapi with go generate ./internal/grpcapi
+// (needs protoc, protoc-gen-go and protoc-gen-go-grpc).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: game.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreateGameRequest takes the room options of POST /api/v1/games, except the
+// webhook URL, which only HTTP clients can set
+type CreateGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mutators      []string               `protobuf:"bytes,1,rep,name=mutators,proto3" json:"mutators,omitempty"`                                    // custom rules by ID, e.g. "half_tower_cost"
+	TickRateMs    int32                  `protobuf:"varint,2,opt,name=tick_rate_ms,json=tickRateMs,proto3" json:"tick_rate_ms,omitempty"`           // simulation tick interval, 10-50 ms; 0 = the balance default
+	MaxRollbacks  *int32                 `protobuf:"varint,3,opt,name=max_rollbacks,json=maxRollbacks,proto3,oneof" json:"max_rollbacks,omitempty"` // waves the game may retry, -1 = unlimited; unset = the balance default
+	VictoryWave   *int32                 `protobuf:"varint,4,opt,name=victory_wave,json=victoryWave,proto3,oneof" json:"victory_wave,omitempty"`    // clearing this wave wins, 0 = endless; unset = the balance default
+	WavePacing    string                 `protobuf:"bytes,5,opt,name=wave_pacing,json=wavePacing,proto3" json:"wave_pacing,omitempty"`              // timer, after_clear or hybrid; empty = the map's pacing
+	Seed          *int64                 `protobuf:"varint,6,opt,name=seed,proto3,oneof" json:"seed,omitempty"`                                     // replays the randomness of an earlier game; unset = random
+	Private       bool                   `protobuf:"varint,7,opt,name=private,proto3" json:"private,omitempty"`                                     // left out of the room list; friends join with the room code
+	Lobby         bool                   `protobuf:"varint,8,opt,name=lobby,proto3" json:"lobby,omitempty"`                                         // hold the first wave until every player who joined is ready
+	Wallets       bool                   `protobuf:"varint,9,opt,name=wallets,proto3" json:"wallets,omitempty"`                                     // every player gets gold of their own instead of a shared pool
+	MaxPlayers    int32                  `protobuf:"varint,10,opt,name=max_players,json=maxPlayers,proto3" json:"max_players,omitempty"`            // seats, 1-16; 0 = no limit
+	Password      string                 `protobuf:"bytes,11,opt,name=password,proto3" json:"password,omitempty"`                                   // players joining need it; the creator is in already
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGameRequest) Reset() {
+	*x = CreateGameRequest{}
+	mi := &file_game_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGameRequest) ProtoMessage() {}
+
+func (x *CreateGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGameRequest.ProtoReflect.Descriptor instead.
+func (*CreateGameRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateGameRequest) GetMutators() []string {
+	if x != nil {
+		return x.Mutators
+	}
+	return nil
+}
+
+func (x *CreateGameRequest) GetTickRateMs() int32 {
+	if x != nil {
+		return x.TickRateMs
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetMaxRollbacks() int32 {
+	if x != nil && x.MaxRollbacks != nil {
+		return *x.MaxRollbacks
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetVictoryWave() int32 {
+	if x != nil && x.VictoryWave != nil {
+		return *x.VictoryWave
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetWavePacing() string {
+	if x != nil {
+		return x.WavePacing
+	}
+	return ""
+}
+
+func (x *CreateGameRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *CreateGameRequest) GetLobby() bool {
+	if x != nil {
+		return x.Lobby
+	}
+	return false
+}
+
+func (x *CreateGameRequest) GetWallets() bool {
+	if x != nil {
+		return x.Wallets
+	}
+	return false
+}
+
+func (x *CreateGameRequest) GetMaxPlayers() int32 {
+	if x != nil {
+		return x.MaxPlayers
+	}
+	return 0
+}
+
+func (x *CreateGameRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type CreateGameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Mutators      []string               `protobuf:"bytes,2,rep,name=mutators,proto3" json:"mutators,omitempty"` // applied mutators, in application order
+	TickRateMs    int32                  `protobuf:"varint,3,opt,name=tick_rate_ms,json=tickRateMs,proto3" json:"tick_rate_ms,omitempty"`
+	Seed          int64                  `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	Code          string                 `protobuf:"bytes,5,opt,name=code,proto3" json:"code,omitempty"` // room code to join with
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGameResponse) Reset() {
+	*x = CreateGameResponse{}
+	mi := &file_game_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGameResponse) ProtoMessage() {}
+
+func (x *CreateGameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGameResponse.ProtoReflect.Descriptor instead.
+func (*CreateGameResponse) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateGameResponse) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *CreateGameResponse) GetMutators() []string {
+	if x != nil {
+		return x.Mutators
+	}
+	return nil
+}
+
+func (x *CreateGameResponse) GetTickRateMs() int32 {
+	if x != nil {
+		return x.TickRateMs
+	}
+	return 0
+}
+
+func (x *CreateGameResponse) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *CreateGameResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type AddTowerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`          // empty = default game
+	TowerType     string                 `protobuf:"bytes,2,opt,name=tower_type,json=towerType,proto3" json:"tower_type,omitempty"` // empty = "basic"
+	X             float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTowerRequest) Reset() {
+	*x = AddTowerRequest{}
+	mi := &file_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTowerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTowerRequest) ProtoMessage() {}
+
+func (x *AddTowerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTowerRequest.ProtoReflect.Descriptor instead.
+func (*AddTowerRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddTowerRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *AddTowerRequest) GetTowerType() string {
+	if x != nil {
+		return x.TowerType
+	}
+	return ""
+}
+
+func (x *AddTowerRequest) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *AddTowerRequest) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type AddTowerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTowerResponse) Reset() {
+	*x = AddTowerResponse{}
+	mi := &file_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTowerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTowerResponse) ProtoMessage() {}
+
+func (x *AddTowerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTowerResponse.ProtoReflect.Descriptor instead.
+func (*AddTowerResponse) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{3}
+}
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // empty = default game
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	mi := &file_game_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStateRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+type StateUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GameId        string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`              // empty = default game
+	IntervalMs    int32                  `protobuf:"varint,2,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // 0 = 100ms, minimum 50ms
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateUpdatesRequest) Reset() {
+	*x = StateUpdatesRequest{}
+	mi := &file_game_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateUpdatesRequest) ProtoMessage() {}
+
+func (x *StateUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*StateUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StateUpdatesRequest) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *StateUpdatesRequest) GetIntervalMs() int32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_game_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Position) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Position) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type Tower struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TowerType     string                 `protobuf:"bytes,2,opt,name=tower_type,json=towerType,proto3" json:"tower_type,omitempty"`
+	Position      *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Range         float64                `protobuf:"fixed64,4,opt,name=range,proto3" json:"range,omitempty"`
+	Damage        int32                  `protobuf:"varint,5,opt,name=damage,proto3" json:"damage,omitempty"`
+	FireRate      float64                `protobuf:"fixed64,6,opt,name=fire_rate,json=fireRate,proto3" json:"fire_rate,omitempty"`
+	SplashRadius  float64                `protobuf:"fixed64,7,opt,name=splash_radius,json=splashRadius,proto3" json:"splash_radius,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,8,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tower) Reset() {
+	*x = Tower{}
+	mi := &file_game_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tower) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tower) ProtoMessage() {}
+
+func (x *Tower) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tower.ProtoReflect.Descriptor instead.
+func (*Tower) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Tower) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Tower) GetTowerType() string {
+	if x != nil {
+		return x.TowerType
+	}
+	return ""
+}
+
+func (x *Tower) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Tower) GetRange() float64 {
+	if x != nil {
+		return x.Range
+	}
+	return 0
+}
+
+func (x *Tower) GetDamage() int32 {
+	if x != nil {
+		return x.Damage
+	}
+	return 0
+}
+
+func (x *Tower) GetFireRate() float64 {
+	if x != nil {
+		return x.FireRate
+	}
+	return 0
+}
+
+func (x *Tower) GetSplashRadius() float64 {
+	if x != nil {
+		return x.SplashRadius
+	}
+	return 0
+}
+
+func (x *Tower) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+type Enemy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	EnemyType     string                 `protobuf:"bytes,2,opt,name=enemy_type,json=enemyType,proto3" json:"enemy_type,omitempty"`
+	Position      *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Hp            int32                  `protobuf:"varint,4,opt,name=hp,proto3" json:"hp,omitempty"`
+	MaxHp         int32                  `protobuf:"varint,5,opt,name=max_hp,json=maxHp,proto3" json:"max_hp,omitempty"`
+	Speed         float64                `protobuf:"fixed64,6,opt,name=speed,proto3" json:"speed,omitempty"`
+	PathIndex     int32                  `protobuf:"varint,7,opt,name=path_index,json=pathIndex,proto3" json:"path_index,omitempty"`
+	PathId        int32                  `protobuf:"varint,8,opt,name=path_id,json=pathId,proto3" json:"path_id,omitempty"`
+	IsBoss        bool                   `protobuf:"varint,9,opt,name=is_boss,json=isBoss,proto3" json:"is_boss,omitempty"`
+	BossPhase     int32                  `protobuf:"varint,10,opt,name=boss_phase,json=bossPhase,proto3" json:"boss_phase,omitempty"`
+	Shield        int32                  `protobuf:"varint,11,opt,name=shield,proto3" json:"shield,omitempty"`
+	SpeedBurst    bool                   `protobuf:"varint,12,opt,name=speed_burst,json=speedBurst,proto3" json:"speed_burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Enemy) Reset() {
+	*x = Enemy{}
+	mi := &file_game_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Enemy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Enemy) ProtoMessage() {}
+
+func (x *Enemy) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Enemy.ProtoReflect.Descriptor instead.
+func (*Enemy) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Enemy) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Enemy) GetEnemyType() string {
+	if x != nil {
+		return x.EnemyType
+	}
+	return ""
+}
+
+func (x *Enemy) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Enemy) GetHp() int32 {
+	if x != nil {
+		return x.Hp
+	}
+	return 0
+}
+
+func (x *Enemy) GetMaxHp() int32 {
+	if x != nil {
+		return x.MaxHp
+	}
+	return 0
+}
+
+func (x *Enemy) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Enemy) GetPathIndex() int32 {
+	if x != nil {
+		return x.PathIndex
+	}
+	return 0
+}
+
+func (x *Enemy) GetPathId() int32 {
+	if x != nil {
+		return x.PathId
+	}
+	return 0
+}
+
+func (x *Enemy) GetIsBoss() bool {
+	if x != nil {
+		return x.IsBoss
+	}
+	return false
+}
+
+func (x *Enemy) GetBossPhase() int32 {
+	if x != nil {
+		return x.BossPhase
+	}
+	return 0
+}
+
+func (x *Enemy) GetShield() int32 {
+	if x != nil {
+		return x.Shield
+	}
+	return 0
+}
+
+func (x *Enemy) GetSpeedBurst() bool {
+	if x != nil {
+		return x.SpeedBurst
+	}
+	return false
+}
+
+type Projectile struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectileType string                 `protobuf:"bytes,2,opt,name=projectile_type,json=projectileType,proto3" json:"projectile_type,omitempty"`
+	Position       *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Target         string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Speed          float64                `protobuf:"fixed64,5,opt,name=speed,proto3" json:"speed,omitempty"`
+	Damage         int32                  `protobuf:"varint,6,opt,name=damage,proto3" json:"damage,omitempty"`
+	SplashRadius   float64                `protobuf:"fixed64,7,opt,name=splash_radius,json=splashRadius,proto3" json:"splash_radius,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Projectile) Reset() {
+	*x = Projectile{}
+	mi := &file_game_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Projectile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Projectile) ProtoMessage() {}
+
+func (x *Projectile) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Projectile.ProtoReflect.Descriptor instead.
+func (*Projectile) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Projectile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Projectile) GetProjectileType() string {
+	if x != nil {
+		return x.ProjectileType
+	}
+	return ""
+}
+
+func (x *Projectile) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Projectile) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Projectile) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Projectile) GetDamage() int32 {
+	if x != nil {
+		return x.Damage
+	}
+	return 0
+}
+
+func (x *Projectile) GetSplashRadius() float64 {
+	if x != nil {
+		return x.SplashRadius
+	}
+	return 0
+}
+
+type GameState struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GameId            string                 `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Wave              int32                  `protobuf:"varint,2,opt,name=wave,proto3" json:"wave,omitempty"`
+	Gold              int32                  `protobuf:"varint,3,opt,name=gold,proto3" json:"gold,omitempty"`
+	Lives             int32                  `protobuf:"varint,4,opt,name=lives,proto3" json:"lives,omitempty"`
+	Score             int32                  `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	GameOver          bool                   `protobuf:"varint,6,opt,name=game_over,json=gameOver,proto3" json:"game_over,omitempty"`
+	ProjectedInterest int32                  `protobuf:"varint,7,opt,name=projected_interest,json=projectedInterest,proto3" json:"projected_interest,omitempty"`
+	Towers            []*Tower               `protobuf:"bytes,8,rep,name=towers,proto3" json:"towers,omitempty"`
+	Enemies           []*Enemy               `protobuf:"bytes,9,rep,name=enemies,proto3" json:"enemies,omitempty"`
+	Projectiles       []*Projectile          `protobuf:"bytes,10,rep,name=projectiles,proto3" json:"projectiles,omitempty"`
+	Path              []*Position            `protobuf:"bytes,11,rep,name=path,proto3" json:"path,omitempty"`
+	MapWidth          int32                  `protobuf:"varint,12,opt,name=map_width,json=mapWidth,proto3" json:"map_width,omitempty"`
+	MapHeight         int32                  `protobuf:"varint,13,opt,name=map_height,json=mapHeight,proto3" json:"map_height,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GameState) Reset() {
+	*x = GameState{}
+	mi := &file_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameState) ProtoMessage() {}
+
+func (x *GameState) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameState.ProtoReflect.Descriptor instead.
+func (*GameState) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GameState) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GameState) GetWave() int32 {
+	if x != nil {
+		return x.Wave
+	}
+	return 0
+}
+
+func (x *GameState) GetGold() int32 {
+	if x != nil {
+		return x.Gold
+	}
+	return 0
+}
+
+func (x *GameState) GetLives() int32 {
+	if x != nil {
+		return x.Lives
+	}
+	return 0
+}
+
+func (x *GameState) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *GameState) GetGameOver() bool {
+	if x != nil {
+		return x.GameOver
+	}
+	return false
+}
+
+func (x *GameState) GetProjectedInterest() int32 {
+	if x != nil {
+		return x.ProjectedInterest
+	}
+	return 0
+}
+
+func (x *GameState) GetTowers() []*Tower {
+	if x != nil {
+		return x.Towers
+	}
+	return nil
+}
+
+func (x *GameState) GetEnemies() []*Enemy {
+	if x != nil {
+		return x.Enemies
+	}
+	return nil
+}
+
+func (x *GameState) GetProjectiles() []*Projectile {
+	if x != nil {
+		return x.Projectiles
+	}
+	return nil
+}
+
+func (x *GameState) GetPath() []*Position {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+func (x *GameState) GetMapWidth() int32 {
+	if x != nil {
+		return x.MapWidth
+	}
+	return 0
+}
+
+func (x *GameState) GetMapHeight() int32 {
+	if x != nil {
+		return x.MapHeight
+	}
+	return 0
+}
+
+var File_game_proto protoreflect.FileDescriptor
+
+const file_game_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"game.proto\x12\x0ftowerdefense.v1\"\x90\x03\n" +
+	"\x11CreateGameRequest\x12\x1a\n" +
+	"\bmutators\x18\x01 \x03(\tR\bmutators\x12 \n" +
+	"\ftick_rate_ms\x18\x02 \x01(\x05R\n" +
+	"tickRateMs\x12(\n" +
+	"\rmax_rollbacks\x18\x03 \x01(\x05H\x00R\fmaxRollbacks\x88\x01\x01\x12&\n" +
+	"\fvictory_wave\x18\x04 \x01(\x05H\x01R\vvictoryWave\x88\x01\x01\x12\x1f\n" +
+	"\vwave_pacing\x18\x05 \x01(\tR\n" +
+	"wavePacing\x12\x17\n" +
+	"\x04seed\x18\x06 \x01(\x03H\x02R\x04seed\x88\x01\x01\x12\x18\n" +
+	"\aprivate\x18\a \x01(\bR\aprivate\x12\x14\n" +
+	"\x05lobby\x18\b \x01(\bR\x05lobby\x12\x18\n" +
+	"\awallets\x18\t \x01(\bR\awallets\x12\x1f\n" +
+	"\vmax_players\x18\n" +
+	" \x01(\x05R\n" +
+	"maxPlayers\x12\x1a\n" +
+	"\bpassword\x18\v \x01(\tR\bpasswordB\x10\n" +
+	"\x0e_max_rollbacksB\x0f\n" +
+	"\r_victory_waveB\a\n" +
+	"\x05_seed\"\x93\x01\n" +
+	"\x12CreateGameResponse\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x1a\n" +
+	"\bmutators\x18\x02 \x03(\tR\bmutators\x12 \n" +
+	"\ftick_rate_ms\x18\x03 \x01(\x05R\n" +
+	"tickRateMs\x12\x12\n" +
+	"\x04seed\x18\x04 \x01(\x03R\x04seed\x12\x12\n" +
+	"\x04code\x18\x05 \x01(\tR\x04code\"e\n" +
+	"\x0fAddTowerRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x1d\n" +
+	"\n" +
+	"tower_type\x18\x02 \x01(\tR\ttowerType\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x01R\x01y\"\x12\n" +
+	"\x10AddTowerResponse\"*\n" +
+	"\x0fGetStateRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\"O\n" +
+	"\x13StateUpdatesRequest\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x1f\n" +
+	"\vinterval_ms\x18\x02 \x01(\x05R\n" +
+	"intervalMs\"&\n" +
+	"\bPosition\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\"\xf8\x01\n" +
+	"\x05Tower\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"tower_type\x18\x02 \x01(\tR\ttowerType\x125\n" +
+	"\bposition\x18\x03 \x01(\v2\x19.towerdefense.v1.PositionR\bposition\x12\x14\n" +
+	"\x05range\x18\x04 \x01(\x01R\x05range\x12\x16\n" +
+	"\x06damage\x18\x05 \x01(\x05R\x06damage\x12\x1b\n" +
+	"\tfire_rate\x18\x06 \x01(\x01R\bfireRate\x12#\n" +
+	"\rsplash_radius\x18\a \x01(\x01R\fsplashRadius\x12\x19\n" +
+	"\bowner_id\x18\b \x01(\tR\aownerId\"\xd3\x02\n" +
+	"\x05Enemy\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"enemy_type\x18\x02 \x01(\tR\tenemyType\x125\n" +
+	"\bposition\x18\x03 \x01(\v2\x19.towerdefense.v1.PositionR\bposition\x12\x0e\n" +
+	"\x02hp\x18\x04 \x01(\x05R\x02hp\x12\x15\n" +
+	"\x06max_hp\x18\x05 \x01(\x05R\x05maxHp\x12\x14\n" +
+	"\x05speed\x18\x06 \x01(\x01R\x05speed\x12\x1d\n" +
+	"\n" +
+	"path_index\x18\a \x01(\x05R\tpathIndex\x12\x17\n" +
+	"\apath_id\x18\b \x01(\x05R\x06pathId\x12\x17\n" +
+	"\ais_boss\x18\t \x01(\bR\x06isBoss\x12\x1d\n" +
+	"\n" +
+	"boss_phase\x18\n" +
+	" \x01(\x05R\tbossPhase\x12\x16\n" +
+	"\x06shield\x18\v \x01(\x05R\x06shield\x12\x1f\n" +
+	"\vspeed_burst\x18\f \x01(\bR\n" +
+	"speedBurst\"\xe7\x01\n" +
+	"\n" +
+	"Projectile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fprojectile_type\x18\x02 \x01(\tR\x0eprojectileType\x125\n" +
+	"\bposition\x18\x03 \x01(\v2\x19.towerdefense.v1.PositionR\bposition\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x14\n" +
+	"\x05speed\x18\x05 \x01(\x01R\x05speed\x12\x16\n" +
+	"\x06damage\x18\x06 \x01(\x05R\x06damage\x12#\n" +
+	"\rsplash_radius\x18\a \x01(\x01R\fsplashRadius\"\xd0\x03\n" +
+	"\tGameState\x12\x17\n" +
+	"\agame_id\x18\x01 \x01(\tR\x06gameId\x12\x12\n" +
+	"\x04wave\x18\x02 \x01(\x05R\x04wave\x12\x12\n" +
+	"\x04gold\x18\x03 \x01(\x05R\x04gold\x12\x14\n" +
+	"\x05lives\x18\x04 \x01(\x05R\x05lives\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x05R\x05score\x12\x1b\n" +
+	"\tgame_over\x18\x06 \x01(\bR\bgameOver\x12-\n" +
+	"\x12projected_interest\x18\a \x01(\x05R\x11projectedInterest\x12.\n" +
+	"\x06towers\x18\b \x03(\v2\x16.towerdefense.v1.TowerR\x06towers\x120\n" +
+	"\aenemies\x18\t \x03(\v2\x16.towerdefense.v1.EnemyR\aenemies\x12=\n" +
+	"\vprojectiles\x18\n" +
+	" \x03(\v2\x1b.towerdefense.v1.ProjectileR\vprojectiles\x12-\n" +
+	"\x04path\x18\v \x03(\v2\x19.towerdefense.v1.PositionR\x04path\x12\x1b\n" +
+	"\tmap_width\x18\f \x01(\x05R\bmapWidth\x12\x1d\n" +
+	"\n" +
+	"map_height\x18\r \x01(\x05R\tmapHeight2\xd3\x02\n" +
+	"\vGameService\x12U\n" +
+	"\n" +
+	"CreateGame\x12\".towerdefense.v1.CreateGameRequest\x1a#.towerdefense.v1.CreateGameResponse\x12O\n" +
+	"\bAddTower\x12 .towerdefense.v1.AddTowerRequest\x1a!.towerdefense.v1.AddTowerResponse\x12H\n" +
+	"\bGetState\x12 .towerdefense.v1.GetStateRequest\x1a\x1a.towerdefense.v1.GameState\x12R\n" +
+	"\fStateUpdates\x12$.towerdefense.v1.StateUpdatesRequest\x1a\x1a.towerdefense.v1.GameState0\x01B Z\x1etower-defense/internal/grpcapib\x06proto3"
+
+var (
+	file_game_proto_rawDescOnce sync.Once
+	file_game_proto_rawDescData []byte
+)
+
+func file_game_proto_rawDescGZIP() []byte {
+	file_game_proto_rawDescOnce.Do(func() {
+		file_game_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)))
+	})
+	return file_game_proto_rawDescData
+}
+
+var file_game_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_game_proto_goTypes = []any{
+	(*CreateGameRequest)(nil),   // 0: towerdefense.v1.CreateGameRequest
+	(*CreateGameResponse)(nil),  // 1: towerdefense.v1.CreateGameResponse
+	(*AddTowerRequest)(nil),     // 2: towerdefense.v1.AddTowerRequest
+	(*AddTowerResponse)(nil),    // 3: towerdefense.v1.AddTowerResponse
+	(*GetStateRequest)(nil),     // 4: towerdefense.v1.GetStateRequest
+	(*StateUpdatesRequest)(nil), // 5: towerdefense.v1.StateUpdatesRequest
+	(*Position)(nil),            // 6: towerdefense.v1.Position
+	(*Tower)(nil),               // 7: towerdefense.v1.Tower
+	(*Enemy)(nil),               // 8: towerdefense.v1.Enemy
+	(*Projectile)(nil),          // 9: towerdefense.v1.Projectile
+	(*GameState)(nil),           // 10: towerdefense.v1.GameState
+}
+var file_game_proto_depIdxs = []int32{
+	6,  // 0: towerdefense.v1.Tower.position:type_name -> towerdefense.v1.Position
+	6,  // 1: towerdefense.v1.Enemy.position:type_name -> towerdefense.v1.Position
+	6,  // 2: towerdefense.v1.Projectile.position:type_name -> towerdefense.v1.Position
+	7,  // 3: towerdefense.v1.GameState.towers:type_name -> towerdefense.v1.Tower
+	8,  // 4: towerdefense.v1.GameState.enemies:type_name -> towerdefense.v1.Enemy
+	9,  // 5: towerdefense.v1.GameState.projectiles:type_name -> towerdefense.v1.Projectile
+	6,  // 6: towerdefense.v1.GameState.path:type_name -> towerdefense.v1.Position
+	0,  // 7: towerdefense.v1.GameService.CreateGame:input_type -> towerdefense.v1.CreateGameRequest
+	2,  // 8: towerdefense.v1.GameService.AddTower:input_type -> towerdefense.v1.AddTowerRequest
+	4,  // 9: towerdefense.v1.GameService.GetState:input_type -> towerdefense.v1.GetStateRequest
+	5,  // 10: towerdefense.v1.GameService.StateUpdates:input_type -> towerdefense.v1.StateUpdatesRequest
+	1,  // 11: towerdefense.v1.GameService.CreateGame:output_type -> towerdefense.v1.CreateGameResponse
+	3,  // 12: towerdefense.v1.GameService.AddTower:output_type -> towerdefense.v1.AddTowerResponse
+	10, // 13: towerdefense.v1.GameService.GetState:output_type -> towerdefense.v1.GameState
+	10, // 14: towerdefense.v1.GameService.StateUpdates:output_type -> towerdefense.v1.GameState
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_game_proto_init() }
+func file_game_proto_init() {
+	if File_game_proto != nil {
+		return
+	}
+	file_game_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_game_proto_goTypes,
+		DependencyIndexes: file_game_proto_depIdxs,
+		MessageInfos:      file_game_proto_msgTypes,
+	}.Build()
+	File_game_proto = out.File
+	file_game_proto_goTypes = nil
+	file_game_proto_depIdxs = nil
+}
@@ -0,0 +1,65 @@
+package grpcapi
+
+import "tower-defense/internal/game"
+
+// stateFromSnapshot converts the game's state DTOs to the gRPC message
+func stateFromSnapshot(gameID string, s game.GameStateSnapshot) *GameState {
+	out := &GameState{
+		GameId:            gameID,
+		Wave:              int32(s.Wave),
+		Gold:              int32(s.Gold),
+		Lives:             int32(s.Lives),
+		Score:             int32(s.Score),
+		GameOver:          s.GameOver,
+		ProjectedInterest: int32(s.ProjectedInterest),
+		Towers:            make([]*Tower, 0, len(s.Towers)),
+		Enemies:           make([]*Enemy, 0, len(s.Enemies)),
+		Projectiles:       make([]*Projectile, 0, len(s.Projectiles)),
+		Path:              make([]*Position, 0, len(s.Path)),
+		MapWidth:          int32(s.MapWidth),
+		MapHeight:         int32(s.MapHeight),
+	}
+	for _, t := range s.Towers {
+		out.Towers = append(out.Towers, &Tower{
+			Id:           t.ID,
+			TowerType:    t.Type,
+			Position:     &Position{X: t.Position.X, Y: t.Position.Y},
+			Range:        t.Range,
+			Damage:       int32(t.Damage),
+			FireRate:     t.FireRate,
+			SplashRadius: t.SplashRadius,
+			OwnerId:      t.OwnerID,
+		})
+	}
+	for _, e := range s.Enemies {
+		out.Enemies = append(out.Enemies, &Enemy{
+			Id:         e.ID,
+			EnemyType:  e.Type,
+			Position:   &Position{X: e.Position.X, Y: e.Position.Y},
+			Hp:         int32(e.HP),
+			MaxHp:      int32(e.MaxHP),
+			Speed:      e.Speed,
+			PathIndex:  int32(e.PathIndex),
+			PathId:     int32(e.PathID),
+			IsBoss:     e.IsBoss,
+			BossPhase:  int32(e.BossPhase),
+			Shield:     int32(e.Shield),
+			SpeedBurst: e.SpeedBurst,
+		})
+	}
+	for _, p := range s.Projectiles {
+		out.Projectiles = append(out.Projectiles, &Projectile{
+			Id:             p.ID,
+			ProjectileType: p.Type,
+			Position:       &Position{X: p.Position.X, Y: p.Position.Y},
+			Target:         p.Target,
+			Speed:          p.Speed,
+			Damage:         int32(p.Damage),
+			SplashRadius:   p.SplashRadius,
+		})
+	}
+	for _, p := range s.Path {
+		out.Path = append(out.Path, &Position{X: p.X, Y: p.Y})
+	}
+	return out
+}
@@ -0,0 +1,265 @@
+// Package grpcapi serves the game management API of api/game.proto over gRPC
+// for non-browser clients such as bots and analysis tools. The messages and
+// the service are generated from api/game.proto.
+package grpcapi
+
+//go:generate protoc --proto_path=../../api --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative game.proto
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"tower-defense/internal/game"
+	gameconfig "tower-defense/internal/game/config"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// PlayerIDKey is the metadata key identifying the player, like the X-Player-ID header
+const PlayerIDKey = "x-player-id"
+
+// RoomTokenKey is the metadata key of the room token, like the X-Room-Token header
+const RoomTokenKey = "x-room-token"
+
+const (
+	defaultStreamInterval = 100 * time.Millisecond
+	minStreamInterval     = 50 * time.Millisecond
+)
+
+// Server implements GameService on top of the game manager
+type Server struct {
+	UnimplementedGameServiceServer
+
+	manager *game.Manager
+	guard   *server.CommandGuard
+	limiter *server.RateLimiter
+	quota   *server.RoomQuota
+}
+
+// NewServer creates the service. guard, limiter and quota apply the same
+// anti-cheat, rate limits and room creation quota as the HTTP API; limiter and
+// quota may be nil.
+func NewServer(manager *game.Manager, guard *server.CommandGuard, limiter *server.RateLimiter, quota *server.RoomQuota) *Server {
+	return &Server{manager: manager, guard: guard, limiter: limiter, quota: quota}
+}
+
+// NewGRPCServer creates a gRPC server with GameService registered
+func NewGRPCServer(s *Server) *grpc.Server {
+	gs := grpc.NewServer()
+	RegisterGameServiceServer(gs, s)
+	return gs
+}
+
+func (s *Server) CreateGame(ctx context.Context, req *CreateGameRequest) (*CreateGameResponse, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, err
+	}
+	release, err := s.reserveRoom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.manager.CreateGameWithOptions(gameOptions(req))
+	if err != nil {
+		release()
+		return nil, toStatus(err)
+	}
+	g.Join(playerID(ctx))
+	g.Start()
+	return &CreateGameResponse{GameId: g.GetID(), Mutators: g.Mutators(), TickRateMs: int32(g.TickRateMs()), Seed: g.Seed(), Code: g.Code()}, nil
+}
+
+// gameOptions converts a CreateGame request to the room options
+func gameOptions(req *CreateGameRequest) game.GameOptions {
+	opts := game.GameOptions{Mutators: req.Mutators, TickRateMs: int(req.TickRateMs), WavePacing: req.WavePacing, Seed: req.Seed,
+		Private: req.Private, Lobby: req.Lobby, Wallets: req.Wallets, MaxPlayers: int(req.MaxPlayers), Password: req.Password}
+	if req.MaxRollbacks != nil {
+		n := int(*req.MaxRollbacks)
+		opts.MaxRollbacks = &n
+	}
+	if req.VictoryWave != nil {
+		n := int(*req.VictoryWave)
+		opts.VictoryWave = &n
+	}
+	return opts
+}
+
+func (s *Server) AddTower(ctx context.Context, req *AddTowerRequest) (*AddTowerResponse, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, err
+	}
+	client := clientKey(ctx)
+	if !s.guard.Check(client) {
+		return nil, status.Error(codes.ResourceExhausted, "commands sent too fast")
+	}
+	g, err := s.game(req.GameId)
+	if err != nil {
+		return nil, err
+	}
+	towerType := req.TowerType
+	if towerType == "" {
+		towerType = "basic"
+	}
+	if err := g.AddTowerForPlayer(playerID(ctx), towerType, req.X, req.Y); err != nil {
+		if errors.Is(err, game.ErrInvalidCoordinates) || errors.Is(err, game.ErrOutOfBounds) {
+			s.guard.Violation(client, server.ViolationInvalidInput, "x", req.X, "y", req.Y)
+		}
+		return nil, toStatus(err)
+	}
+	return &AddTowerResponse{}, nil
+}
+
+func (s *Server) GetState(ctx context.Context, req *GetStateRequest) (*GameState, error) {
+	g, err := s.game(req.GameId)
+	if err != nil {
+		return nil, err
+	}
+	return stateFromSnapshot(g.GetID(), g.GetState().VisibleTo(playerID(ctx))), nil
+}
+
+// StateUpdates sends the game state every interval until the client goes away
+// or the game is removed
+func (s *Server) StateUpdates(req *StateUpdatesRequest, stream grpc.ServerStreamingServer[GameState]) error {
+	interval := defaultStreamInterval
+	if req.IntervalMs > 0 {
+		interval = time.Duration(req.IntervalMs) * time.Millisecond
+		if interval < minStreamInterval {
+			interval = minStreamInterval
+		}
+	}
+	g, err := s.game(req.GameId)
+	if err != nil {
+		return err
+	}
+	gameID := g.GetID()
+	viewer := playerID(stream.Context())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := stream.Send(stateFromSnapshot(gameID, g.GetState().VisibleTo(viewer))); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+		// follow the default game across loads, stop when a room is removed
+		if g, err = s.game(req.GameId); err != nil {
+			return err
+		}
+	}
+}
+
+// game resolves a game ID, where "" means the default game
+func (s *Server) game(gameID string) (*game.Game, error) {
+	if gameID == "" {
+		return s.manager.GetOrCreateDefault(), nil
+	}
+	g, err := s.manager.GetGame(gameID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return g, nil
+}
+
+// allow applies the HTTP rate limits to gRPC calls, keyed by client address and player
+func (s *Server) allow(ctx context.Context) error {
+	for _, key := range []string{"ip:" + peerHost(ctx), "player:" + playerID(ctx)} {
+		if key == "player:" {
+			continue
+		}
+		if ok, wait := s.limiter.Allow(key); !ok {
+			server.RateLimitedTotal.WithLabelValues("grpc").Inc()
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %v", wait.Round(time.Millisecond))
+		}
+	}
+	return nil
+}
+
+// reserveRoom applies the room creation quota of the HTTP API to a gRPC
+// caller; the room token comes from the x-room-token metadata
+func (s *Server) reserveRoom(ctx context.Context) (release func(), err error) {
+	if s.quota == nil {
+		return func() {}, nil
+	}
+	release, err = s.quota.Reserve(ctx, peerHost(ctx), playerID(ctx), metadataValue(ctx, RoomTokenKey))
+	var denial *server.RoomDenial
+	if !errors.As(err, &denial) {
+		return release, err
+	}
+	switch denial.Status {
+	case http.StatusTooManyRequests:
+		return nil, status.Error(codes.ResourceExhausted, denial.Err.Message)
+	case http.StatusForbidden:
+		return nil, status.Error(codes.PermissionDenied, denial.Err.Message)
+	default:
+		return nil, status.Error(codes.Unavailable, denial.Err.Message)
+	}
+}
+
+// toStatus maps game errors to gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, game.ErrGameNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, game.ErrNotEnoughGold), errors.Is(err, game.ErrInvalidPlacement), errors.Is(err, game.ErrTowerLocked),
+		errors.Is(err, game.ErrTutorialStep):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, game.ErrInvalidCoordinates), errors.Is(err, game.ErrOutOfBounds),
+		errors.Is(err, gameconfig.ErrUnknownTowerType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, gameconfig.ErrUnknownMutator), errors.Is(err, game.ErrInvalidTickRate),
+		errors.Is(err, game.ErrInvalidRollbackLimit), errors.Is(err, game.ErrInvalidVictoryWave),
+		errors.Is(err, game.ErrInvalidWavePacing), errors.Is(err, game.ErrInvalidMaxPlayers),
+		errors.Is(err, game.ErrInvalidPassword):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, game.ErrMemoryBudget), errors.Is(err, game.ErrTooManyRooms):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		logging.Errorw("grpc_internal_error", "error", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func playerID(ctx context.Context) string {
+	return metadataValue(ctx, PlayerIDKey)
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// clientKey identifies a caller for anti-cheat bookkeeping, matching server.ClientKey
+func clientKey(ctx context.Context) string {
+	if id := playerID(ctx); id != "" {
+		return "player:" + id
+	}
+	return "ip:" + peerHost(ctx)
+}
@@ -0,0 +1,112 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"tower-defense/internal/game"
+	gameconfig "tower-defense/internal/game/config"
+	"tower-defense/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// dial serves GameService in memory and returns a client of it
+func dial(t *testing.T, manager *game.Manager) GameServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := NewGRPCServer(NewServer(manager, server.NewCommandGuard(0), nil, nil))
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return NewGameServiceClient(cc)
+}
+
+func TestCreateGameOptions(t *testing.T) {
+	cfg, err := gameconfig.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := game.NewManager(cfg)
+	client := dial(t, manager)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), PlayerIDKey, "alice")
+
+	resp, err := client.CreateGame(ctx, &CreateGameRequest{
+		Mutators:    []string{"half_tower_cost"},
+		TickRateMs:  20,
+		VictoryWave: proto.Int32(3),
+		Seed:        proto.Int64(42),
+		Private:     true,
+		MaxPlayers:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := manager.GetGame(resp.GameId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(g.Stop)
+	if !slices.Equal(resp.Mutators, []string{"half_tower_cost"}) || resp.TickRateMs != 20 || resp.Seed != 42 || resp.Code != g.Code() {
+		t.Errorf("response = %v", resp)
+	}
+	if !g.Private() || g.Seed() != 42 || g.TickRateMs() != 20 {
+		t.Errorf("game private %v seed %d tick rate %d, want the requested options", g.Private(), g.Seed(), g.TickRateMs())
+	}
+
+	_, err = client.CreateGame(ctx, &CreateGameRequest{TickRateMs: 1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("tick rate of 1 ms: %v, want InvalidArgument", err)
+	}
+}
+
+func TestStateUpdatesDecode(t *testing.T) {
+	cfg, err := gameconfig.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := game.NewManager(cfg)
+	client := dial(t, manager)
+	g, err := manager.CreateGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddTower("basic", 150, 200); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.StateUpdates(ctx, &StateUpdatesRequest{GameId: g.GetID(), IntervalMs: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := g.GetState()
+	if state.GameId != g.GetID() || int(state.Gold) != want.Gold || len(state.Towers) != 1 || len(state.Path) != len(want.Path) {
+		t.Fatalf("state = game %q gold %d towers %d path %d, want game %q gold %d towers 1 path %d",
+			state.GameId, state.Gold, len(state.Towers), len(state.Path), g.GetID(), want.Gold, len(want.Path))
+	}
+	if tower := state.Towers[0]; tower.TowerType != "basic" || tower.Position.GetX() != 150 || tower.Position.GetY() != 200 {
+		t.Errorf("tower = %v", tower)
+	}
+}
@@ -0,0 +1,113 @@
+// Package i18n translates the human-readable strings the server emits, such as
+// tutorial prompts and achievement names. Each language is a flat JSON bundle of
+// message keys embedded from locales/; keys missing from a bundle fall back to
+// English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language used when no supported one is asked for
+const Default = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// bundles maps each language to its messages by key
+var bundles = load()
+
+func load() map[string]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	loaded := make(map[string]map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := files.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			panic("i18n: " + err.Error())
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("i18n: invalid bundle " + e.Name() + ": " + err.Error())
+		}
+		loaded[strings.TrimSuffix(e.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// Supported returns the languages with a bundle, sorted
+func Supported() []string {
+	langs := make([]string, 0, len(bundles))
+	for lang := range bundles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Match returns the supported language of a tag such as "pl" or "de-AT", or ""
+func Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	if _, ok := bundles[base]; ok {
+		return base
+	}
+	return ""
+}
+
+// Negotiate picks the language of a response: preferred (e.g. a player's
+// setting) if supported, otherwise the best supported language of an
+// Accept-Language header, otherwise Default
+func Negotiate(preferred, acceptLanguage string) string {
+	if lang := Match(preferred); lang != "" {
+		return lang
+	}
+	type option struct {
+		lang string
+		q    float64
+	}
+	var options []option
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if lang := Match(tag); lang != "" && q > 0 {
+			options = append(options, option{lang, q})
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].q > options[j].q })
+	if len(options) > 0 {
+		return options[0].lang
+	}
+	return Default
+}
+
+// Lookup returns the message of key in lang, or in English if lang's bundle
+// lacks it; ok is false if neither has the key
+func Lookup(lang, key string) (string, bool) {
+	if msg, ok := bundles[lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := bundles[Default][key]
+	return msg, ok
+}
+
+// T returns the message of key in lang, falling back to English and then to
+// the key itself
+func T(lang, key string) string {
+	if msg, ok := Lookup(lang, key); ok {
+		return msg
+	}
+	return key
+}
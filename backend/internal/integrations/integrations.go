@@ -0,0 +1,210 @@
+// Package integrations announces open rooms and match results in Discord and
+// Slack channels through their incoming webhooks. It only formats messages:
+// the webhook notifier delivers them, with its retries and dead-letter log.
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/logging"
+	"tower-defense/internal/webhook"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Services messages can be posted to
+const (
+	Discord = "discord"
+	Slack   = "slack"
+)
+
+// discordMaxContent is the longest message Discord accepts
+const discordMaxContent = 2000
+
+// DefaultEvents are the payload kinds announced when none are configured
+var DefaultEvents = []string{webhook.KindGameCreated, webhook.KindGameOver, webhook.KindHighScore}
+
+// DefaultTemplates are the messages of each payload kind, as text/template
+// executed with a Message
+var DefaultTemplates = map[string]string{
+	webhook.KindGameCreated: `🎮 A room is open on {{.MapID}}` +
+		`{{if .Mutators}} with {{join .Mutators ", "}}{{end}}.` +
+		`{{if .JoinURL}} Join: {{.JoinURL}}{{end}}`,
+	webhook.KindGameOver: `{{if eq .Outcome "won"}}🏆 Victory{{else}}💀 Defeat{{end}} on {{.MapID}}: ` +
+		`score {{.Score}} after {{.WavesSurvived}} waves` +
+		`{{if .Reason}} (ended: {{.Reason}}){{end}}.`,
+	webhook.KindHighScore: `⭐ New high score on {{.MapID}}: {{.Score}} (previous best {{.Previous}}).`,
+}
+
+// Config selects where and what to announce
+type Config struct {
+	DiscordURL string            // incoming webhook of a Discord channel, "" = off
+	SlackURL   string            // incoming webhook of a Slack channel, "" = off
+	Events     []string          // payload kinds to announce, nil = DefaultEvents
+	Templates  map[string]string // replace DefaultTemplates by payload kind
+	JoinURL    string            // link to a room, "{gameId}" is replaced by its ID; "" = no link
+}
+
+// Message is what templates are executed with
+type Message struct {
+	Event   string
+	GameID  string
+	MapID   string
+	JoinURL string
+
+	// game_created
+	Mutators   []string
+	TickRateMs int
+	Seed       int64
+
+	// game_over and high_score
+	Outcome       string
+	Reason        string
+	Score         int
+	WavesSurvived int
+	Previous      int
+}
+
+var funcs = template.FuncMap{"join": strings.Join}
+
+// LoadTemplates reads template overrides from a YAML file mapping payload
+// kinds to templates
+func LoadTemplates(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var templates map[string]string
+	if err := yaml.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return templates, nil
+}
+
+// Targets returns the webhook targets of the configured services, none if no
+// service is set up. It fails on unknown payload kinds and broken templates.
+func Targets(cfg Config) ([]webhook.Target, error) {
+	events := cfg.Events
+	if events == nil {
+		events = DefaultEvents
+	}
+	for _, kind := range events {
+		if _, ok := DefaultTemplates[kind]; !ok {
+			return nil, fmt.Errorf("unknown integration event %q", kind)
+		}
+	}
+	templates := make(map[string]*template.Template, len(events))
+	for _, kind := range events {
+		text := DefaultTemplates[kind]
+		if t, ok := cfg.Templates[kind]; ok {
+			text = t
+		}
+		tmpl, err := template.New(kind).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", kind, err)
+		}
+		templates[kind] = tmpl
+	}
+	for kind := range cfg.Templates {
+		if _, ok := DefaultTemplates[kind]; !ok {
+			return nil, fmt.Errorf("template for unknown event %q", kind)
+		}
+	}
+
+	f := &formatter{templates: templates, joinURL: cfg.JoinURL}
+	var targets []webhook.Target
+	if cfg.DiscordURL != "" {
+		targets = append(targets, webhook.Target{Name: Discord, URL: cfg.DiscordURL, Format: f.discord})
+	}
+	if cfg.SlackURL != "" {
+		targets = append(targets, webhook.Target{Name: Slack, URL: cfg.SlackURL, Format: f.slack})
+	}
+	return targets, nil
+}
+
+type formatter struct {
+	templates map[string]*template.Template
+	joinURL   string
+}
+
+func (f *formatter) discord(p webhook.Payload) ([]byte, bool) {
+	text, ok := f.render(p)
+	if !ok {
+		return nil, false
+	}
+	if len(text) > discordMaxContent {
+		text = truncate(text, discordMaxContent)
+	}
+	return marshal(map[string]any{"content": text, "allowed_mentions": map[string]any{"parse": []string{}}})
+}
+
+func (f *formatter) slack(p webhook.Payload) ([]byte, bool) {
+	text, ok := f.render(p)
+	if !ok {
+		return nil, false
+	}
+	return marshal(map[string]any{"text": text})
+}
+
+// render executes the template of a payload's kind; payloads whose kind is
+// switched off, and tutorial rooms, which nobody else can join, are skipped
+func (f *formatter) render(p webhook.Payload) (string, bool) {
+	tmpl, ok := f.templates[p.Kind]
+	if !ok {
+		return "", false
+	}
+	msg := Message{Event: p.Kind, GameID: p.GameID}
+	switch data := p.Data.(type) {
+	case webhook.GameCreated:
+		if data.Tutorial {
+			return "", false
+		}
+		msg.MapID = data.MapID
+		msg.Mutators = data.Mutators
+		msg.TickRateMs = data.TickRateMs
+		msg.Seed = data.Seed
+		if f.joinURL != "" {
+			msg.JoinURL = strings.ReplaceAll(f.joinURL, "{gameId}", p.GameID)
+		}
+	case *game.Summary:
+		msg.MapID = data.MapID
+		msg.Outcome = data.Outcome
+		msg.Reason = data.Reason
+		msg.Score = data.Score
+		msg.WavesSurvived = data.WavesSurvived
+		msg.Seed = data.Seed
+	case webhook.HighScore:
+		msg.MapID = data.MapID
+		msg.Score = data.Score
+		msg.Previous = data.Previous
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, msg); err != nil {
+		logging.Warnw("integration_template_failed", "event", p.Kind, "game_id", p.GameID, "error", err)
+		return "", false
+	}
+	text := strings.TrimSpace(buf.String())
+	return text, text != ""
+}
+
+func marshal(v any) ([]byte, bool) {
+	body, err := json.Marshal(v)
+	return body, err == nil
+}
+
+// truncate shortens text to at most n bytes without splitting a character
+func truncate(text string, n int) string {
+	n -= len("…")
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n] + "…"
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader is an alternative to "Authorization: Bearer <token>" for admin requests
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdmin rejects requests that do not carry the admin token.
+// With an empty token the admin API is disabled and every request is refused.
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			api.Respond(c, http.StatusForbidden, api.NewError(api.CodeForbidden, "admin API disabled"))
+			return
+		}
+		provided := c.GetHeader(AdminTokenHeader)
+		if auth := c.GetHeader("Authorization"); provided == "" && strings.HasPrefix(auth, "Bearer ") {
+			provided = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logging.Warnw("admin_auth_failed", "path", c.FullPath(), "ip", c.ClientIP())
+			api.Respond(c, http.StatusUnauthorized, api.NewError(api.CodeUnauthorized, "invalid admin token"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// MountAdmin registers administrative endpoints behind RequireAdmin
+func MountAdmin(r *gin.Engine, token string, reloadConfig, endGame, adjustResources, dumpWorld, listCrashes, setVerbose, listClients, kickClient, saveStats, memory, audit, listConnections, deadLetters, listFlags, setFlag, forfeit, getBalance, patchBalance gin.HandlerFunc) {
+	a := r.Group("/api/v1/admin", RequireAdmin(token))
+	{
+		a.POST("/reload-config", reloadConfig)
+		a.GET("/balance", getBalance)
+		a.PATCH("/balance", patchBalance)
+		a.POST("/games/:id/end", endGame)
+		a.POST("/games/:id/resources", adjustResources)
+		a.GET("/games/:id/world", dumpWorld)
+		a.GET("/games/:id/crashes", listCrashes)
+		a.POST("/games/:id/verbose", setVerbose)
+		a.GET("/clients", listClients)
+		a.DELETE("/clients/:id", kickClient)
+		a.GET("/saves/stats", saveStats)
+		a.GET("/memory", memory)
+		a.GET("/audit", audit)
+		a.GET("/connections", listConnections)
+		a.GET("/webhooks/dead-letters", deadLetters)
+		a.GET("/flags", listFlags)
+		a.POST("/flags/:name", setFlag)
+		a.POST("/tournaments/:id/forfeit", forfeit)
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountAnalytics registers per-game analytics endpoints
+func MountAnalytics(r *gin.Engine, heatmap gin.HandlerFunc) {
+	a := r.Group("/api/v1/games/:id/analytics")
+	{
+		a.GET("/heatmap", heatmap)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var AntiCheatViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "td_anticheat_violations_total",
+	Help: "Commands rejected as implausible, by kind",
+}, []string{"kind"})
+
+func init() {
+	prometheus.MustRegister(AntiCheatViolations)
+}
+
+// Violation kinds
+const (
+	ViolationTooFast      = "too_fast"
+	ViolationInvalidInput = "invalid_input"
+)
+
+// CommandGuard rejects game commands that arrive faster than a human could plausibly
+// issue them and keeps a per-client tally of violations for later inspection.
+// Unlike RateLimiter it allows no bursts: consecutive commands must be minInterval apart.
+type CommandGuard struct {
+	mu          sync.Mutex
+	minInterval time.Duration
+	last        map[string]time.Time
+	violations  map[string]int
+	lastSweep   time.Time
+}
+
+// NewCommandGuard creates a guard; a zero interval disables the pacing check
+func NewCommandGuard(minInterval time.Duration) *CommandGuard {
+	return &CommandGuard{
+		minInterval: minInterval,
+		last:        make(map[string]time.Time),
+		violations:  make(map[string]int),
+		lastSweep:   time.Now(),
+	}
+}
+
+// Check records a command from client and reports whether it is plausibly paced
+func (g *CommandGuard) Check(client string) bool {
+	g.mu.Lock()
+	now := time.Now()
+	if now.Sub(g.lastSweep) > rateLimiterIdleTTL {
+		for k, t := range g.last {
+			if now.Sub(t) > rateLimiterIdleTTL {
+				delete(g.last, k)
+			}
+		}
+		g.lastSweep = now
+	}
+	last, seen := g.last[client]
+	g.last[client] = now
+	g.mu.Unlock()
+
+	if g.minInterval > 0 && seen && now.Sub(last) < g.minInterval {
+		g.Violation(client, ViolationTooFast, "interval_ms", now.Sub(last).Milliseconds())
+		return false
+	}
+	return true
+}
+
+// Violation logs a suspicious command from client and increments its tally
+func (g *CommandGuard) Violation(client, kind string, details ...interface{}) {
+	g.mu.Lock()
+	g.violations[client]++
+	count := g.violations[client]
+	g.mu.Unlock()
+
+	AntiCheatViolations.WithLabelValues(kind).Inc()
+	logging.Warnw("anticheat_violation", append([]interface{}{"client", client, "kind", kind, "count", count}, details...)...)
+}
+
+// Violations returns the number of violations recorded for client
+func (g *CommandGuard) Violations(client string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.violations[client]
+}
+
+// ClientKey identifies the sender of a request: the player ID when present, else the IP
+func ClientKey(c *gin.Context) string {
+	if playerID := PlayerID(c); playerID != "" {
+		return "player:" + playerID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// Guarded wraps h so that implausibly fast commands are rejected with 429
+func Guarded(g *CommandGuard, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !g.Check(ClientKey(c)) {
+			api.Respond(c, http.StatusTooManyRequests, api.NewError(api.CodeTooFast, "commands sent too fast"))
+			return
+		}
+		h(c)
+	}
+}
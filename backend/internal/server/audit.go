@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/repository"
+	"tower-defense/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultAuditLogSize is the number of entries the audit log keeps in memory
+const DefaultAuditLogSize = 1000
+
+const (
+	maxAuditBodyBytes = 4 << 10 // request params and response bodies kept per entry
+	auditQueueSize    = 256     // entries waiting to be written to the repository
+	auditRepoKey      = "audit" // repository key all persisted entries are saved under
+)
+
+// auditSecret is the request parameter the audit log never keeps, e.g. room passwords
+const auditSecret = "password"
+
+// legacyRoomRoutes are the mutating routes acting on the default game
+var legacyRoomRoutes = map[string]bool{"/tower": true, "/wall": true, "/reset": true, "/save": true, "/load": true, "/map": true}
+
+// AuditFilter selects entries of the audit log; zero fields match everything
+type AuditFilter struct {
+	GameID   string
+	PlayerID string
+	Since    time.Time
+	Limit    int
+}
+
+func (f AuditFilter) match(e *api.AuditEntry) bool {
+	return (f.GameID == "" || e.GameID == f.GameID) &&
+		(f.PlayerID == "" || e.PlayerID == f.PlayerID) &&
+		!e.Time.Before(f.Since)
+}
+
+// AuditLog keeps the last state-mutating requests in a ring buffer, to settle
+// disputes in multiplayer games. With a repository every entry is also saved
+// there, in the background. A nil log records nothing.
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []api.AuditEntry // ring buffer, oldest at next once full
+	next    int
+	full    bool
+	seq     uint64
+
+	persist chan api.AuditEntry // nil without a repository, guarded by mu once closed
+	closed  bool
+	done    chan struct{}
+}
+
+// NewAuditLog returns a log of the last capacity entries, DefaultAuditLogSize
+// for capacity <= 0. repo may be nil.
+func NewAuditLog(capacity int, repo repository.Repository) *AuditLog {
+	if capacity <= 0 {
+		capacity = DefaultAuditLogSize
+	}
+	l := &AuditLog{entries: make([]api.AuditEntry, capacity)}
+	if repo != nil {
+		l.persist = make(chan api.AuditEntry, auditQueueSize)
+		l.done = make(chan struct{})
+		go l.save(repo)
+	}
+	return l
+}
+
+func (l *AuditLog) save(repo repository.Repository) {
+	defer close(l.done)
+	for e := range l.persist {
+		data, err := json.Marshal(e)
+		if err == nil {
+			_, err = repo.Save(auditRepoKey, data)
+		}
+		if err != nil {
+			logging.Warnw("audit_persist_failed", "entry_id", e.ID, "error", err)
+		}
+	}
+}
+
+// Close writes the queued entries to the repository; later entries are only
+// kept in memory
+func (l *AuditLog) Close() {
+	if l == nil || l.persist == nil {
+		return
+	}
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.persist)
+	}
+	l.mu.Unlock()
+	<-l.done
+}
+
+// Record assigns the entry an ID and adds it to the log
+func (l *AuditLog) Record(e api.AuditEntry) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.seq++
+	e.ID = l.seq
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % len(l.entries)
+	l.full = l.full || l.next == 0
+	if l.persist != nil && !l.closed {
+		select {
+		case l.persist <- e:
+		default:
+			logging.Warnw("audit_persist_dropped", "entry_id", e.ID)
+		}
+	}
+	l.mu.Unlock()
+}
+
+// Query returns the entries matching f, newest first
+func (l *AuditLog) Query(f AuditFilter) []api.AuditEntry {
+	result := []api.AuditEntry{}
+	if l == nil {
+		return result
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.entries)
+	}
+	for i := 1; i <= n; i++ {
+		e := &l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if !f.match(e) {
+			continue
+		}
+		result = append(result, *e)
+		if f.Limit > 0 && len(result) == f.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// RecordCommand adds a WebSocket command of c to the log; code is empty when
+// the command was applied
+func (l *AuditLog) RecordCommand(c *Client, msg InboundMessage, start time.Time, code string) {
+	if l == nil {
+		return
+	}
+	e := api.AuditEntry{
+		Time:       start,
+		RequestID:  msg.RequestID,
+		PlayerID:   c.playerID,
+		ClientIP:   c.remoteAddr,
+		GameID:     c.gameID,
+		Method:     "WS",
+		Path:       msg.Type,
+		Code:       code,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if host, _, err := net.SplitHostPort(c.remoteAddr); err == nil {
+		e.ClientIP = host
+	}
+	e.Params, e.Truncated = auditParams(msg.Payload)
+	l.Record(e)
+}
+
+// Audit returns a gin middleware adding every POST, PUT, PATCH and DELETE
+// request to log, with its body and outcome
+func Audit(log *AuditLog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		if log == nil {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodyBytes+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		}
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		e := api.AuditEntry{
+			Time:       start,
+			RequestID:  c.Writer.Header().Get("X-Request-ID"),
+			PlayerID:   PlayerID(c),
+			ClientIP:   c.ClientIP(),
+			GameID:     auditGameID(c),
+			Method:     c.Request.Method,
+			Path:       c.FullPath(),
+			Query:      auditQuery(c.Request.URL.RawQuery),
+			Status:     c.Writer.Status(),
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if e.Path == "" {
+			e.Path = c.Request.URL.Path // no route matched
+		}
+		e.Params, e.Truncated = auditParams(body)
+
+		var resp struct {
+			Code   string `json:"code"`
+			GameID string `json:"game_id"` // of a created game
+		}
+		if json.Unmarshal(w.body.Bytes(), &resp) == nil {
+			if e.Status >= http.StatusBadRequest {
+				e.Code = resp.Code
+			} else if e.GameID == "" {
+				e.GameID = resp.GameID
+			}
+		}
+		log.Record(e)
+	}
+}
+
+// auditGameID is the game a request acts on: the :id of /games/:id routes,
+// the default game for the legacy single-room endpoints, or the gameId query
+// parameter of admin endpoints like /admin/balance
+func auditGameID(c *gin.Context) string {
+	path := strings.TrimPrefix(c.FullPath(), "/api/v1")
+	switch {
+	case strings.Contains(path, "/games/:id"):
+		return c.Param("id")
+	case legacyRoomRoutes[path]:
+		return game.DefaultGameID
+	}
+	return c.Query("gameId")
+}
+
+// auditParams returns body for an audit entry, or reports it truncated when
+// it is too large or not JSON
+func auditParams(body []byte) (json.RawMessage, bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, false
+	}
+	if len(body) > maxAuditBodyBytes || !json.Valid(body) {
+		return nil, true
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) == nil && fields[auditSecret] != nil {
+		fields[auditSecret] = json.RawMessage(`"redacted"`)
+		redacted, _ := json.Marshal(fields)
+		return redacted, false
+	}
+	return json.RawMessage(bytes.Clone(body)), false
+}
+
+// auditQuery returns a query string with the secret parameter redacted
+func auditQuery(raw string) string {
+	q, err := url.ParseQuery(raw)
+	if err != nil || !q.Has(auditSecret) {
+		return raw
+	}
+	q.Set(auditSecret, "redacted")
+	return q.Encode()
+}
+
+// auditWriter keeps the start of the response body, e.g. to read the error code
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditWriter) keep(b []byte) {
+	if room := maxAuditBodyBytes - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	}
+}
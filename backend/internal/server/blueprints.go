@@ -0,0 +1,15 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountBlueprints registers the endpoints of a game's queue of towers waiting for gold
+func MountBlueprints(r *gin.Engine, list, queue, cancel gin.HandlerFunc) {
+	b := r.Group("/api/v1/games/:id/blueprints")
+	{
+		b.GET("", list)
+		b.POST("", queue)
+		b.DELETE("/:blueprintId", cancel)
+	}
+}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountBots registers endpoints that attach computer players to games
+func MountBots(r *gin.Engine, addBot, listBots, removeBot gin.HandlerFunc) {
+	g := r.Group("/api/v1/games/:id")
+	{
+		g.POST("/bot", addBot)
+		g.GET("/bots", listBots)
+		g.DELETE("/bot/:playerId", removeBot)
+	}
+}
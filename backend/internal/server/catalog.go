@@ -0,0 +1,10 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountCatalog registers the endpoint describing the rules clients build their UI from
+func MountCatalog(r *gin.Engine, gameConfig gin.HandlerFunc) {
+	r.GET("/api/v1/game-config", gameConfig)
+}
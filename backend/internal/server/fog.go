@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// fogMarker is how an encoded snapshot under fog of war is recognized without decoding it
+var fogMarker = []byte(`"fogOfWar":true`)
+
+// fogFilter cuts an encoded fog-of-war snapshot down to what each player sees.
+// Enemies carry the players who see them in visibleTo, see game.EnemyDTO, and
+// so do the removals of enemies, see game.RemovalDTO.
+type fogFilter struct {
+	state   map[string]json.RawMessage
+	enemies []json.RawMessage
+	viewers [][]string // visibleTo of each enemy
+	removed []json.RawMessage
+	removal []removalView // of each removal
+}
+
+// removalView is what the filter needs to know about one removal
+type removalView struct {
+	Kind      string   `json:"kind"`
+	VisibleTo []string `json:"visibleTo"`
+}
+
+// newFogFilter decodes a snapshot for filtering; it returns nil for snapshots
+// without fog of war
+func newFogFilter(payload []byte) *fogFilter {
+	if !bytes.Contains(payload, fogMarker) {
+		return nil
+	}
+	f := &fogFilter{}
+	if err := json.Unmarshal(payload, &f.state); err != nil {
+		// Not a snapshot object; send nothing rather than leak hidden enemies
+		f.state = map[string]json.RawMessage{}
+		return f
+	}
+	if err := json.Unmarshal(f.state["enemies"], &f.enemies); err != nil {
+		f.enemies = nil
+	}
+	f.viewers = make([][]string, len(f.enemies))
+	for i, e := range f.enemies {
+		var enemy struct {
+			VisibleTo []string `json:"visibleTo"`
+		}
+		if err := json.Unmarshal(e, &enemy); err == nil {
+			f.viewers[i] = enemy.VisibleTo
+		}
+	}
+	if removed, ok := f.state["removed"]; ok {
+		if err := json.Unmarshal(removed, &f.removed); err != nil {
+			f.removed = nil
+		}
+		f.removal = make([]removalView, len(f.removed))
+		for i, r := range f.removed {
+			if err := json.Unmarshal(r, &f.removal[i]); err != nil {
+				f.removal[i].Kind = "enemy" // hide what can't be checked
+			}
+		}
+	}
+	return f
+}
+
+// visibleTo encodes the snapshot with only the enemies playerID sees
+func (f *fogFilter) visibleTo(playerID string) []byte {
+	visible := make([]json.RawMessage, 0, len(f.enemies))
+	for i, e := range f.enemies {
+		if slices.Contains(f.viewers[i], playerID) {
+			visible = append(visible, e)
+		}
+	}
+	state := maps.Clone(f.state)
+	state["enemies"], _ = json.Marshal(visible)
+	if f.removal != nil {
+		removed := make([]json.RawMessage, 0, len(f.removed))
+		for i, r := range f.removed {
+			if f.removal[i].Kind != "enemy" || slices.Contains(f.removal[i].VisibleTo, playerID) {
+				removed = append(removed, r)
+			}
+		}
+		state["removed"], _ = json.Marshal(removed)
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return []byte("{}")
+	}
+	return payload
+}
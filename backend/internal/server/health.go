@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"tower-defense/internal/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds the time all readiness checks may take together
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck probes one part of the server for readiness
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error // nil error means healthy
+}
+
+// Liveness answers as long as the HTTP server is serving
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, api.HealthResponse{Status: "ok"})
+}
+
+// Readiness runs every check concurrently and answers 503 if any of them fails
+func Readiness(checks []HealthCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		results := make(map[string]api.CheckResult, len(checks))
+		ready := true
+		for _, hc := range checks {
+			wg.Add(1)
+			go func(hc HealthCheck) {
+				defer wg.Done()
+				start := time.Now()
+				err := hc.Check(ctx)
+				res := api.CheckResult{Status: "ok", DurationMs: float64(time.Since(start).Microseconds()) / 1000}
+				if err != nil {
+					res.Status, res.Error = "fail", err.Error()
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				results[hc.Name] = res
+				ready = ready && err == nil
+			}(hc)
+		}
+		wg.Wait()
+
+		if !ready {
+			api.Respond(c, http.StatusServiceUnavailable, api.NewError(api.CodeUnavailable, "not ready").WithDetails(results))
+			return
+		}
+		c.JSON(http.StatusOK, api.ReadinessResponse{Status: "ready", Checks: results})
+	}
+}
+
+// MountHealth registers the liveness and readiness probes at the root and under /api/v1
+func MountHealth(r *gin.Engine, checks ...HealthCheck) {
+	ready := Readiness(checks)
+	r.GET("/healthz", Liveness)
+	r.GET("/readyz", ready)
+	r.GET("/api/v1/healthz", Liveness)
+	r.GET("/api/v1/readyz", ready)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often clients are pinged to measure their latency
+const DefaultHeartbeatInterval = 5 * time.Second
+
+// rttSmoothing is the weight of a new sample in the moving average round trip
+const rttSmoothing = 0.2
+
+// SetHeartbeat sets the interval of application-level pings (0 = off) and
+// whether each room is told the latency of its players. Call it before Run.
+func (h *Hub) SetHeartbeat(interval time.Duration, shareLatency bool) {
+	h.heartbeat = interval
+	h.shareRTT = shareLatency
+}
+
+// sendHeartbeat pings every client and, when enabled, sends each room the
+// latency of its players as measured so far (called by Run only)
+func (h *Hub) sendHeartbeat(now time.Time) {
+	h.pingSeq++
+	ping, _ := json.Marshal(PingPayload{ID: h.pingSeq, ServerTime: now.UnixMilli()})
+
+	h.mu.RLock()
+	rooms := make(map[string][]*Client, len(h.rooms))
+	for gameID, r := range h.rooms {
+		for c := range r {
+			rooms[gameID] = append(rooms[gameID], c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for gameID, clients := range rooms {
+		for _, c := range clients {
+			c.mu.Lock()
+			c.pingID, c.pingSent = h.pingSeq, now
+			h.enqueue(c, outbound{typ: MsgPing, gameID: gameID, payload: ping})
+			c.mu.Unlock()
+		}
+		if h.shareRTT {
+			h.shareLatency(gameID, clients)
+		}
+	}
+}
+
+// shareLatency sends the room the smoothed round trip of each player in it,
+// the best one for players with several connections
+func (h *Hub) shareLatency(gameID string, clients []*Client) {
+	best := map[string]time.Duration{}
+	for _, c := range clients {
+		c.mu.Lock()
+		rttAvg := c.rttAvg
+		c.mu.Unlock()
+		if c.playerID == "" || rttAvg == 0 {
+			continue
+		}
+		if rtt, ok := best[c.playerID]; !ok || rttAvg < rtt {
+			best[c.playerID] = rttAvg
+		}
+	}
+	if len(best) == 0 {
+		return
+	}
+	var latency LatencyPayload
+	for playerID, rtt := range best {
+		latency.Players = append(latency.Players, PlayerLatency{PlayerID: playerID, RttMs: durationMs(rtt)})
+	}
+	sort.Slice(latency.Players, func(i, j int) bool { return latency.Players[i].PlayerID < latency.Players[j].PlayerID })
+	payload, _ := json.Marshal(latency)
+	for _, c := range clients {
+		h.deliver(c, outbound{typ: MsgLatency, gameID: gameID, payload: payload})
+	}
+}
+
+// handlePong records the round trip of the heartbeat the client answered.
+// Pongs for older heartbeats and repeated pongs are ignored.
+func (h *Hub) handlePong(c *Client, msg InboundMessage) {
+	var pong PingPayload
+	if err := json.Unmarshal(msg.Payload, &pong); err != nil {
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "invalid pong payload", RequestID: msg.RequestID})
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pong.ID == 0 || pong.ID != c.pingID {
+		return
+	}
+	rtt := time.Since(c.pingSent)
+	c.pingID = 0
+	c.rtt = rtt
+	if c.rttAvg == 0 {
+		c.rttAvg = rtt
+	} else {
+		c.rttAvg += time.Duration(rttSmoothing * float64(rtt-c.rttAvg))
+	}
+	WsRTTSeconds.Observe(rtt.Seconds())
+}
+
+// handlePing answers a client's ping right away with the server time
+func (h *Hub) handlePing(c *Client, msg InboundMessage) {
+	var ping ClientPingPayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &ping); err != nil {
+			h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "invalid ping payload", RequestID: msg.RequestID})
+			return
+		}
+	}
+	h.Send(c, MsgPong, PongPayload{ClientTime: ping.ClientTime, ServerTime: time.Now().UnixMilli()})
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
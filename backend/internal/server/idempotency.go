@@ -0,0 +1,33 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader carries a client-chosen key that makes a command safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses that repeat the result of an earlier request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength bounds the keys games keep in memory
+const maxIdempotencyKeyLength = 128
+
+// IdempotencyKey returns the caller's idempotency key scoped to its client key,
+// so two players can't collide, or "" if the request has none
+func IdempotencyKey(c *gin.Context) (string, error) {
+	return ScopedIdempotencyKey(ClientKey(c), c.GetHeader(IdempotencyKeyHeader))
+}
+
+// ScopedIdempotencyKey scopes a command key sent by client; an empty key stays empty
+func ScopedIdempotencyKey(client, key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", fmt.Errorf("idempotency key longer than %d characters", maxIdempotencyKeyLength)
+	}
+	return client + "|" + key, nil
+}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"tower-defense/internal/buildinfo"
+	"tower-defense/internal/game"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -8,6 +11,7 @@ import (
 
 var (
 	WsConnections      = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_ws_connections", Help: "Number of active WS connections"})
+	WsMessagesDropped  = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_ws_messages_dropped_total", Help: "WS messages skipped for clients that fell behind"})
 	TicksTotal         = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_engine_ticks_total", Help: "Total engine ticks"})
 	EngineEnemies      = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_engine_enemies", Help: "Current number of enemies"})
 	EngineProjectiles  = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_engine_projectiles", Help: "Current number of projectiles"})
@@ -17,12 +21,127 @@ var (
 		Help:    "Engine tick delta time in seconds",
 		Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
 	})
+	EngineTickDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "td_engine_tick_duration_seconds",
+		Help:    "Time spent computing one engine tick",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 2, 14),
+	})
+	EngineSystemSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "td_engine_system_seconds",
+		Help:    "Time spent in each ECS system per tick",
+		Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"system"})
+	EngineOverloaded = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_engine_overloaded", Help: "1 while engine ticks run over budget"})
+	EnginePanics     = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_engine_panics_total", Help: "Recovered panics of game loops"})
+	EngineGameTime   = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_engine_game_time_seconds", Help: "Simulated seconds of the default game's clock"})
+
+	EngineSaturated   = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_engine_saturated", Help: "1 while the default game is at an entity limit"})
+	EngineSpawnsHeld  = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_engine_spawns_held_total", Help: "Ticks the default game held a wave spawn at its enemy limit"})
+	EngineHitscans    = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_engine_hitscan_shots_total", Help: "Shots of the default game resolved on the spot at its projectile limit"})
+	EntityCapsReached = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "td_entity_cap_reached_total", Help: "Times a game ran into an entity limit, by kind: enemies or projectiles"}, []string{"kind"})
+
+	RoomsMemoryBytes   = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "td_rooms_memory_bytes", Help: "Approximate memory held by all rooms, by part: entities, events, snapshot, replay or other"}, []string{"part"})
+	RoomMemoryMaxBytes = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_room_memory_max_bytes", Help: "Approximate memory held by the largest room"})
+	MemoryBudgetBytes  = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_rooms_memory_budget_bytes", Help: "Memory all rooms may hold before new rooms are refused, 0 = no budget"})
+	RoomsRefused       = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "td_room_creations_refused_total", Help: "Room creations refused, by reason: memory_budget, max_rooms, ip_quota, player_quota or challenge"}, []string{"reason"})
+
+	WsRTTSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "td_ws_rtt_seconds",
+		Help:    "Round trip time of WS heartbeats",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 10),
+	})
+
+	BroadcastDegradedRooms = prometheus.NewGauge(prometheus.GaugeOpts{Name: "td_broadcast_degraded_rooms", Help: "Rooms broadcast less often because their game is overloaded"})
+	BroadcastDegradations  = prometheus.NewCounter(prometheus.CounterOpts{Name: "td_broadcast_degradations_total", Help: "Times a room's broadcast rate was lowered for overload"})
+
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "td_webhook_deliveries_total", Help: "Webhook delivery attempts by result"}, []string{"result"})
+	AnalyticsEvents   = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "td_analytics_events_total", Help: "Analytics events by result: sent, sampled_out, dropped or failed"}, []string{"result"})
+	StreamMessages    = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "td_eventstream_messages_total", Help: "Game events for the message broker by result: published, retried, spilled, dropped or failed"}, []string{"result"})
+
+	WsBroadcastBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "td_ws_broadcast_bytes",
+		Help:    "Size of broadcast WS payloads by message type",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 8),
+	}, []string{"type"})
+	WsBroadcastLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "td_ws_broadcast_latency_seconds",
+		Help:    "Time from broadcasting a WS message to writing it to a client, by message type",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 9),
+	}, []string{"type"})
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "http_requests_total", Help: "HTTP requests by method, route and status"},
+		[]string{"method", "route", "status"})
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request duration by method and route",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	BuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "td_build_info", Help: "Always 1; labels name the running build"},
+		[]string{"version", "commit", "go_version"})
 )
 
 func init() {
-	prometheus.MustRegister(WsConnections, TicksTotal, EngineEnemies, EngineProjectiles, EngineTowers, EngineTickSeconds)
+	prometheus.MustRegister(WsConnections, WsMessagesDropped, TicksTotal, EngineEnemies, EngineProjectiles, EngineTowers, EngineTickSeconds,
+		EngineTickDuration, EngineSystemSeconds, EngineOverloaded, EnginePanics, EngineGameTime,
+		EngineSaturated, EngineSpawnsHeld, EngineHitscans, EntityCapsReached,
+		RoomsMemoryBytes, RoomMemoryMaxBytes, MemoryBudgetBytes, RoomsRefused,
+		WsRTTSeconds, BroadcastDegradedRooms, BroadcastDegradations, WebhookDeliveries, AnalyticsEvents, StreamMessages,
+		WsBroadcastBytes, WsBroadcastLatency, HTTPRequests, HTTPRequestDuration, BuildInfo)
+	BuildInfo.WithLabelValues(buildinfo.Version, buildinfo.Commit, buildinfo.GoVersion).Set(1)
+}
+
+// ObserveTick records engine metrics; install it with Game.SetOnTick
+func ObserveTick(st game.TickStats) {
+	TicksTotal.Inc()
+	EngineEnemies.Set(float64(st.Enemies))
+	EngineProjectiles.Set(float64(st.Projectiles))
+	EngineTowers.Set(float64(st.Towers))
+	EngineTickSeconds.Observe(st.Dt)
+	EngineTickDuration.Observe(st.Duration.Seconds())
+	EngineGameTime.Set(st.GameTime)
+	for _, s := range st.Systems {
+		EngineSystemSeconds.WithLabelValues(s.Name).Observe(s.Duration.Seconds())
+	}
+	if st.Overloaded {
+		EngineOverloaded.Set(1)
+	} else {
+		EngineOverloaded.Set(0)
+	}
+	EngineSpawnsHeld.Add(float64(st.SpawnsHeld))
+	EngineHitscans.Add(float64(st.Hitscans))
+	if st.Saturated {
+		EngineSaturated.Set(1)
+	} else {
+		EngineSaturated.Set(0)
+	}
+}
+
+// ObserveMemory records the memory report of the game manager; call it
+// periodically from one goroutine
+func ObserveMemory(r game.MemoryReport) {
+	var entities, evs, snapshot, replay, other, largest int64
+	for _, u := range r.Rooms {
+		entities += u.EntityBytes
+		evs += u.EventBytes
+		snapshot += u.SnapshotBytes
+		replay += u.ReplayBytes
+		other += u.OtherBytes
+		largest = max(largest, u.TotalBytes)
+	}
+	RoomsMemoryBytes.WithLabelValues("entities").Set(float64(entities))
+	RoomsMemoryBytes.WithLabelValues("events").Set(float64(evs))
+	RoomsMemoryBytes.WithLabelValues("snapshot").Set(float64(snapshot))
+	RoomsMemoryBytes.WithLabelValues("replay").Set(float64(replay))
+	RoomsMemoryBytes.WithLabelValues("other").Set(float64(other))
+	RoomMemoryMaxBytes.Set(float64(largest))
+	MemoryBudgetBytes.Set(float64(r.BudgetBytes))
 }
 
+// MountMetrics serves /metrics, in the OpenMetrics format to scrapers that ask
+// for it so they also get the exemplars of http_request_duration_seconds
 func MountMetrics(r *gin.Engine) {
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	handler := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	r.GET("/metrics", gin.WrapH(handler))
 }
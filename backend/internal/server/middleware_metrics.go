@@ -0,0 +1,38 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxExemplarID keeps exemplar labels within the 128 characters Prometheus allows
+const maxExemplarID = 64
+
+// HTTPMetrics counts requests and times them per route. Routes are labelled
+// with their pattern, e.g. /api/v1/games/:id, and requests no route matched
+// with "unmatched". Durations carry the request ID as an exemplar, so a slow
+// bucket on a dashboard leads to the request's log line.
+func HTTPMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+
+		observer := HTTPRequestDuration.WithLabelValues(method, route)
+		seconds := time.Since(start).Seconds()
+		reqID := c.Writer.Header().Get("X-Request-ID")
+		if eo, ok := observer.(prometheus.ExemplarObserver); ok && reqID != "" && len(reqID) <= maxExemplarID {
+			eo.ObserveWithExemplar(seconds, prometheus.Labels{"request_id": reqID})
+			return
+		}
+		observer.Observe(seconds)
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// PlayerIDHeader carries the caller's player identity on HTTP requests
+const PlayerIDHeader = "X-Player-ID"
+
+// PlayerID returns the player identity attached to the request, if any
+func PlayerID(c *gin.Context) string {
+	return c.GetHeader(PlayerIDHeader)
+}
+
+// MountPlayers registers per-player profile, research and build template endpoints
+func MountPlayers(r *gin.Engine, achievements, locale, stats, research, purchase, templates, saveTemplate, deleteTemplate gin.HandlerFunc) {
+	p := r.Group("/api/v1/players")
+	{
+		p.GET("/:id/achievements", achievements)
+		p.PUT("/:id/locale", locale)
+		p.GET("/:id/stats", stats)
+		p.GET("/:id/research", research)
+		p.POST("/:id/research/:node", purchase)
+		p.GET("/:id/templates", templates)
+		p.PUT("/:id/templates/:name", saveTemplate)
+		p.DELETE("/:id/templates/:name", deleteTemplate)
+	}
+}
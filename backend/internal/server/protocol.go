@@ -0,0 +1,245 @@
+package server
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"tower-defense/internal/api"
+)
+
+// MessageType tags every outbound WebSocket message so clients can dispatch on it
+type MessageType string
+
+const (
+	MsgSnapshot MessageType = "snapshot" // full game state
+	MsgDelta    MessageType = "delta"    // incremental state update relative to the last snapshot
+	MsgEvent    MessageType = "event"    // gameplay event (events.Event)
+	MsgChat     MessageType = "chat"     // chat line relayed to everyone in the game
+	MsgError    MessageType = "error"    // problem with a client message
+	MsgAck      MessageType = "ack"      // client command was accepted
+	MsgNack     MessageType = "nack"     // client command was rejected by the game rules
+	MsgSummary  MessageType = "summary"  // end-of-game report (game.Summary), sent when a game ends
+	MsgShutdown MessageType = "shutdown" // the server is going down (ShutdownPayload)
+	MsgPing     MessageType = "ping"     // heartbeat the client answers with CmdPong (PingPayload)
+	MsgPong     MessageType = "pong"     // answer to CmdPing (PongPayload)
+	MsgLatency  MessageType = "latency"  // round trip times of the players in the room (LatencyPayload)
+)
+
+// Envelope is the wire format of every outbound WebSocket message.
+// Seq increases by one per message on each connection, so gaps reveal dropped messages.
+type Envelope struct {
+	Type    MessageType     `json:"type"`
+	Seq     uint64          `json:"seq"`
+	GameID  string          `json:"gameId"`
+	Payload json.RawMessage `json:"payload"`
+
+	// Resync marks the snapshot sent once a client that fell behind caught up;
+	// it replaces whatever the skipped messages would have told the client
+	Resync bool `json:"resync,omitempty"`
+
+	// Keyframe marks the full-detail snapshot every client of a room gets
+	// periodically, whatever rate and detail it subscribed to, to reconcile
+	// predicted state against; see Hub.SetKeyframeInterval
+	Keyframe bool `json:"keyframe,omitempty"`
+}
+
+// ChatPayload is the payload of MsgChat
+type ChatPayload struct {
+	From string    `json:"from"`
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}
+
+// ErrorPayload is the payload of MsgError
+type ErrorPayload struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+	CommandSeq
+}
+
+// AckPayload is the payload of MsgAck
+type AckPayload struct {
+	RequestID string `json:"requestId"`
+	Command   string `json:"command"`
+	CommandSeq
+}
+
+// CommandSeq is set in the ack, nack or error of a command sent with a seq. Snapshots
+// list under processed the highest seq of each client, keyed by Client;
+// commands after it are still to be applied on top of the snapshot.
+type CommandSeq struct {
+	Seq    uint64 `json:"seq,omitempty"`
+	Client string `json:"client,omitempty"` // key of the sender in snapshots' processed
+	Tick   uint64 `json:"tick,omitempty"`   // tick after which the command took effect
+}
+
+// NackPayload is the payload of MsgNack. Code is stable and meant for programs,
+// Message is human readable.
+type NackPayload struct {
+	RequestID string `json:"requestId"`
+	Command   string `json:"command"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	CommandSeq
+}
+
+// ShutdownPayload is the payload of MsgShutdown
+type ShutdownPayload struct {
+	Message  string `json:"message"`
+	Restored bool   `json:"restored"` // running games are saved and resume when the server is back
+}
+
+// PingPayload is the payload of MsgPing; the client echoes it in CmdPong
+type PingPayload struct {
+	ID         uint64 `json:"id"`
+	ServerTime int64  `json:"serverTime"` // unix milliseconds
+}
+
+// ClientPingPayload is the payload of CmdPing
+type ClientPingPayload struct {
+	ClientTime int64 `json:"clientTime"` // any client timestamp, echoed back
+}
+
+// PongPayload is the payload of MsgPong. Comparing ServerTime with the
+// midpoint of the round trip estimates the offset of the server clock.
+type PongPayload struct {
+	ClientTime int64 `json:"clientTime"`
+	ServerTime int64 `json:"serverTime"` // unix milliseconds
+}
+
+// LatencyPayload is the payload of MsgLatency
+type LatencyPayload struct {
+	Players []PlayerLatency `json:"players"`
+}
+
+// PlayerLatency is the smoothed round trip time of a player's connection
+type PlayerLatency struct {
+	PlayerID string  `json:"playerId"`
+	RttMs    float64 `json:"rttMs"`
+}
+
+// Client commands
+const (
+	CmdPlaceTower = "place_tower"
+	CmdPlaceWall  = "place_wall"
+	CmdReady      = "ready"     // lobby ready-up, see ReadyPayload
+	CmdTransfer   = "transfer"  // give gold to another player, see TransferPayload
+	CmdSubscribe  = "subscribe" // handled by the hub, see SubscribePayload
+	CmdPing       = "ping"      // handled by the hub, answered with MsgPong
+	CmdPong       = "pong"      // answer to MsgPing, handled by the hub
+)
+
+// SubscribePayload is the payload of CmdSubscribe. It sets how often and in how
+// much detail the client receives snapshots; omitted fields keep their value.
+type SubscribePayload struct {
+	Rate   *float64 `json:"rate,omitempty"`   // snapshots per second, 0 = every broadcast
+	Detail string   `json:"detail,omitempty"` // DetailFull or DetailLite
+}
+
+// Snapshot detail levels
+const (
+	DetailFull = "full" // the complete game state (default)
+	DetailLite = "lite" // without projectiles, for clients on slow links
+)
+
+// maxSnapshotRate bounds the snapshot rate a client may request; the broadcaster
+// never sends faster than its own interval anyway
+const maxSnapshotRate = 20
+
+// PlaceTowerPayload is the payload of CmdPlaceTower
+type PlaceTowerPayload struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	TowerType string  `json:"towerType"`
+}
+
+// PlaceWallPayload is the payload of CmdPlaceWall
+type PlaceWallPayload struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ReadyPayload is the payload of CmdReady. Once every player who joined the
+// room is ready, the first wave comes after a short countdown.
+type ReadyPayload struct {
+	Ready bool `json:"ready"`
+}
+
+// TransferPayload is the payload of CmdTransfer, for rooms where every player
+// has a wallet; the recipient gets Amount minus the transfer tax
+type TransferPayload struct {
+	To     string `json:"to"`
+	Amount int    `json:"amount"`
+}
+
+// Error codes sent in ErrorPayload and NackPayload, shared with HTTP error bodies
+const (
+	ErrCodeBadRequest     = api.CodeBadRequest
+	ErrCodeUnknownCommand = api.CodeUnknownCommand
+	ErrCodeRateLimited    = api.CodeRateLimited
+
+	// command rejections (MsgNack)
+	ErrCodeNotEnoughGold      = api.CodeNotEnoughGold
+	ErrCodeInvalidPlacement   = api.CodeInvalidPlacement
+	ErrCodeInvalidCoordinates = api.CodeInvalidCoordinates
+	ErrCodeOutOfBounds        = api.CodeOutOfBounds
+	ErrCodeUnknownTowerType   = api.CodeUnknownTowerType
+	ErrCodeGameNotFound       = api.CodeGameNotFound
+	ErrCodeTooFast            = api.CodeTooFast
+	ErrCodeUnavailable        = api.CodeUnavailable // the server instance owning the game did not answer
+	ErrCodeInternal           = api.CodeInternal
+)
+
+// InboundMessage is the wire format of client-to-server WebSocket messages
+type InboundMessage struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"requestId,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+
+	// CommandID makes a game command safe to resend: a repeated ID gets the
+	// original ack or nack without the command running again
+	CommandID string `json:"commandId,omitempty"`
+
+	// Seq numbers the game commands of a client that predicts their outcome,
+	// increasing by one per command; see CommandSeq
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// maxChatLength bounds relayed chat lines
+const maxChatLength = 200
+
+// outbound is a message queued for a client; the envelope is completed by the
+// client's write pump so each connection gets its own sequence, gapless unless
+// messages were skipped.
+type outbound struct {
+	typ      MessageType
+	gameID   string
+	payload  []byte    // pre-encoded JSON, shared between clients
+	skipped  uint64    // messages skipped right before this resync snapshot
+	keyframe bool      // a keyframe snapshot, see Envelope.Keyframe
+	sentAt   time.Time // when a broadcast was handed to the hub, zero for direct sends
+}
+
+// encode renders the envelope without re-encoding the (possibly large) payload
+func (m outbound) encode(seq uint64) []byte {
+	gameID, _ := json.Marshal(m.gameID)
+	buf := make([]byte, 0, len(m.payload)+len(gameID)+64)
+	buf = append(buf, `{"type":"`...)
+	buf = append(buf, m.typ...)
+	buf = append(buf, `","seq":`...)
+	buf = strconv.AppendUint(buf, seq, 10)
+	if m.skipped > 0 {
+		buf = append(buf, `,"resync":true`...)
+	}
+	if m.keyframe {
+		buf = append(buf, `,"keyframe":true`...)
+	}
+	buf = append(buf, `,"gameId":`...)
+	buf = append(buf, gameID...)
+	buf = append(buf, `,"payload":`...)
+	buf = append(buf, m.payload...)
+	buf = append(buf, '}')
+	return buf
+}
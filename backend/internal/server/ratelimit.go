@@ -0,0 +1,116 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var RateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "td_rate_limited_total",
+	Help: "Requests and WS commands rejected by rate limiting",
+}, []string{"scope"})
+
+func init() {
+	prometheus.MustRegister(RateLimitedTotal)
+}
+
+// tokenBucket refills at rate tokens per second up to burst
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take consumes one token, or returns how long until one is available
+func (b *tokenBucket) take(now time.Time, rate, burst float64) (bool, time.Duration) {
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// RateLimiter keeps a token bucket per key (client IP, player ID, ...).
+// A zero rate disables limiting.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// rateLimiterIdleTTL is how long an unused bucket is kept before being swept
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// NewRateLimiter creates a limiter allowing rate requests per second with the given burst
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consumes a token for key. When denied it returns the time until a retry can succeed.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.rate <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > rateLimiterIdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > rateLimiterIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	return b.take(now, l.rate, l.burst)
+}
+
+// RateLimited wraps h so that it is limited per client IP and, when the request
+// carries a player ID, per player. Rejected requests get 429 with Retry-After.
+func RateLimited(l *RateLimiter, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.Allow("ip:" + c.ClientIP())
+		if ok {
+			if playerID := PlayerID(c); playerID != "" {
+				ok, wait = l.Allow("player:" + playerID)
+			}
+		}
+		if !ok {
+			RateLimitedTotal.WithLabelValues("http").Inc()
+			logging.Warnw("rate_limited", "path", c.FullPath(), "ip", c.ClientIP(), "player_id", PlayerID(c))
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			api.Respond(c, http.StatusTooManyRequests, api.NewError(api.CodeRateLimited, "rate limit exceeded").
+				WithDetails(gin.H{"retryAfterSeconds": retryAfter}))
+			return
+		}
+		h(c)
+	}
+}
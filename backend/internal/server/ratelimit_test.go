@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// towerRouter serves POST /tower rate limited to one request per client
+func towerRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	addTower := RateLimited(NewRateLimiter(0.001, 1), ok)
+	r, err := NewRouter(ok, addTower, ok, ok, ok, ok, ok, ok, ok, ok, []string{"*"}, trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func postTower(r *gin.Engine, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, "/tower", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	r := towerRouter(t, nil)
+	if code := postTower(r, "1.1.1.1"); code != http.StatusOK {
+		t.Fatalf("first request = %d", code)
+	}
+	if code := postTower(r, "2.2.2.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("request from the same peer with another X-Forwarded-For = %d, want 429", code)
+	}
+}
+
+func TestRateLimitTrustsConfiguredProxy(t *testing.T) {
+	r := towerRouter(t, []string{"10.0.0.0/8"})
+	if code := postTower(r, "1.1.1.1"); code != http.StatusOK {
+		t.Fatalf("first client = %d", code)
+	}
+	if code := postTower(r, "2.2.2.2"); code != http.StatusOK {
+		t.Fatalf("second client behind the proxy = %d, want 200", code)
+	}
+	if code := postTower(r, "1.1.1.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("first client again = %d, want 429", code)
+	}
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tower-defense/internal/api"
+	"tower-defense/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoomTokenHeader carries a CAPTCHA response or creation token once a client
+// has created RoomQuotaOptions.ChallengeAfter rooms within the window
+const RoomTokenHeader = "X-Room-Token"
+
+// Reasons of refused room creations, next to those of the game manager
+const (
+	RefusedIPQuota     = "ip_quota"
+	RefusedPlayerQuota = "player_quota"
+	RefusedChallenge   = "challenge"
+)
+
+// challengeTimeout bounds one call to a CAPTCHA provider
+const challengeTimeout = 5 * time.Second
+
+var (
+	ErrChallengeMissing = errors.New("room token missing")
+	ErrChallengeInvalid = errors.New("room token rejected")
+)
+
+// ChallengeVerifier checks the room token of a client that created many rooms
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// RoomQuotaOptions configure a RoomQuota; zero limits are off
+type RoomQuotaOptions struct {
+	PerIP          int           // rooms one client IP may create per Window
+	PerPlayer      int           // rooms one X-Player-ID may create per Window
+	Window         time.Duration // sliding window the limits count in
+	ChallengeAfter int           // rooms per Window after which a client needs a valid X-Room-Token
+	Verifier       ChallengeVerifier
+}
+
+// RoomQuota limits how many rooms each client IP and player creates within a
+// sliding window, and asks those past a threshold to prove they are human
+type RoomQuota struct {
+	opts RoomQuotaOptions
+
+	mu        sync.Mutex
+	created   map[string][]time.Time // creation times by "ip:" or "player:" key, oldest first
+	lastSweep time.Time
+}
+
+// NewRoomQuota creates a quota; a ChallengeAfter without a Verifier is an error
+func NewRoomQuota(opts RoomQuotaOptions) (*RoomQuota, error) {
+	if opts.ChallengeAfter > 0 && opts.Verifier == nil {
+		return nil, errors.New("room challenges need a CAPTCHA provider or creation tokens")
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Hour
+	}
+	return &RoomQuota{opts: opts, created: make(map[string][]time.Time), lastSweep: time.Now()}, nil
+}
+
+// recent returns the creations of key within the window, dropping older ones
+// (caller must hold q.mu)
+func (q *RoomQuota) recent(key string, now time.Time) []time.Time {
+	times := q.created[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= q.opts.Window {
+		i++
+	}
+	if i == len(times) {
+		delete(q.created, key)
+		return nil
+	}
+	times = times[i:]
+	q.created[key] = times
+	return times
+}
+
+// sweep drops keys without creations in the window (caller must hold q.mu)
+func (q *RoomQuota) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < q.opts.Window {
+		return
+	}
+	for key := range q.created {
+		q.recent(key, now)
+	}
+	q.lastSweep = now
+}
+
+// RoomDenial describes why a room creation was refused
+type RoomDenial struct {
+	Status     int // HTTP status: 429, 403 or 503
+	Err        *api.Error
+	Reason     string
+	RetryAfter int // seconds, of a 429
+}
+
+func (d *RoomDenial) Error() string { return d.Err.Message }
+
+// reserve counts a creation for the keys, or returns why it is not allowed.
+// A RefusedChallenge denial without a status means the creation may go ahead
+// once the client's token checks out; nothing is counted for it yet. Once it
+// has, reserve is called again with challenged set, which checks the limits
+// afresh but skips the challenge threshold.
+func (q *RoomQuota) reserve(ipKey, playerKey string, now time.Time, challenged bool) *RoomDenial {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sweep(now)
+
+	type limit struct {
+		key, reason string
+		max         int
+	}
+	limits := []limit{{ipKey, RefusedIPQuota, q.opts.PerIP}}
+	if playerKey != "" {
+		limits = append(limits, limit{playerKey, RefusedPlayerQuota, q.opts.PerPlayer})
+	}
+	most := 0
+	for _, l := range limits {
+		times := q.recent(l.key, now)
+		most = max(most, len(times))
+		if l.max > 0 && len(times) >= l.max {
+			retryAfter := int(math.Ceil(times[len(times)-l.max].Add(q.opts.Window).Sub(now).Seconds()))
+			return &RoomDenial{Status: http.StatusTooManyRequests, Reason: l.reason, RetryAfter: retryAfter,
+				Err: api.NewError(api.CodeRoomQuota, "room creation quota exceeded").WithDetails(gin.H{
+					"scope": strings.TrimSuffix(l.reason, "_quota"), "limit": l.max,
+					"windowSeconds": int(q.opts.Window.Seconds()), "retryAfterSeconds": retryAfter,
+				})}
+		}
+	}
+	if !challenged && q.opts.ChallengeAfter > 0 && most >= q.opts.ChallengeAfter {
+		return &RoomDenial{Reason: RefusedChallenge}
+	}
+	for _, l := range limits {
+		q.created[l.key] = append(q.created[l.key], now)
+	}
+	return nil
+}
+
+// release takes back a reserved creation whose request failed
+func (q *RoomQuota) release(keys []string, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, key := range keys {
+		times := q.created[key]
+		if i := slices.Index(times, at); i >= 0 {
+			q.created[key] = slices.Delete(times, i, i+1)
+		}
+	}
+}
+
+// Reserve counts a room creation for the client at ip, and for playerID if
+// set, or returns a *RoomDenial saying why it is refused. token is the
+// client's room token, checked once it is past the challenge threshold.
+// Every path that creates rooms for clients goes through here; release
+// takes back a creation that failed afterwards.
+func (q *RoomQuota) Reserve(ctx context.Context, ip, playerID, token string) (release func(), err error) {
+	now := time.Now()
+	keys := []string{"ip:" + ip}
+	playerKey := ""
+	if playerID != "" {
+		playerKey = "player:" + playerID
+		keys = append(keys, playerKey)
+	}
+
+	denial := q.reserve(keys[0], playerKey, now, false)
+	if denial != nil && denial.Reason == RefusedChallenge {
+		// Other creations may have been counted during the slow check
+		denial = q.challenge(ctx, ip, strings.TrimSpace(token))
+		if denial == nil {
+			denial = q.reserve(keys[0], playerKey, now, true)
+		}
+	}
+	if denial != nil {
+		RoomsRefused.WithLabelValues(denial.Reason).Inc()
+		logging.Warnw("room_creation_refused", "reason", denial.Reason, "ip", ip, "player_id", playerID)
+		return nil, denial
+	}
+	return func() { q.release(keys, now) }, nil
+}
+
+// RoomQuotaGuarded wraps the room creation handler h with the quota. Refused
+// requests get 429 room_quota with Retry-After, or 403 challenge_required
+// when the client must send a valid X-Room-Token first. Failed creations
+// don't count.
+func RoomQuotaGuarded(q *RoomQuota, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, err := q.Reserve(c.Request.Context(), c.ClientIP(), PlayerID(c), c.GetHeader(RoomTokenHeader))
+		if err != nil {
+			denial := err.(*RoomDenial)
+			if denial.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(denial.RetryAfter))
+			}
+			api.Respond(c, denial.Status, denial.Err)
+			return
+		}
+
+		h(c)
+		if c.Writer.Status() >= http.StatusBadRequest {
+			release()
+		}
+	}
+}
+
+// challenge checks the room token of a client past the challenge threshold
+func (q *RoomQuota) challenge(ctx context.Context, ip, token string) *RoomDenial {
+	details := gin.H{"header": RoomTokenHeader, "threshold": q.opts.ChallengeAfter}
+	if p, ok := q.opts.Verifier.(interface{ Provider() string }); ok {
+		details["provider"] = p.Provider()
+	}
+	err := ErrChallengeMissing
+	if token != "" {
+		ctx, cancel := context.WithTimeout(ctx, challengeTimeout)
+		err = q.opts.Verifier.Verify(ctx, token, ip)
+		cancel()
+	}
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrChallengeMissing) && !errors.Is(err, ErrChallengeInvalid) {
+		logging.Warnw("room_challenge_failed", "ip", ip, "error", err)
+		return &RoomDenial{Status: http.StatusServiceUnavailable, Reason: RefusedChallenge,
+			Err: api.NewError(api.CodeUnavailable, "room token could not be verified, try again")}
+	}
+	return &RoomDenial{Status: http.StatusForbidden, Reason: RefusedChallenge,
+		Err: api.NewError(api.CodeChallengeRequired, err.Error()).WithDetails(details)}
+}
+
+// TokenVerifier accepts a fixed set of creation tokens, e.g. handed out to
+// trusted clients or tools
+type TokenVerifier map[string]bool
+
+// NewTokenVerifier returns a verifier of the comma separated tokens
+func NewTokenVerifier(tokens string) TokenVerifier {
+	v := make(TokenVerifier)
+	for _, t := range strings.Split(tokens, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			v[t] = true
+		}
+	}
+	return v
+}
+
+func (v TokenVerifier) Verify(_ context.Context, token, _ string) error {
+	if !v[token] {
+		return ErrChallengeInvalid
+	}
+	return nil
+}
+
+// CaptchaVerifier checks CAPTCHA responses with a provider's siteverify
+// endpoint. Cloudflare Turnstile, hCaptcha and reCAPTCHA share its form:
+// secret, response and remoteip in, {"success": bool} out.
+type CaptchaVerifier struct {
+	VerifyURL string
+	Secret    string
+	Client    *http.Client
+
+	// Tokens are accepted too, without a call to the provider
+	Tokens TokenVerifier
+}
+
+// Provider names the CAPTCHA provider for clients, by the host of VerifyURL
+func (v *CaptchaVerifier) Provider() string {
+	u, err := url.Parse(v.VerifyURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func (v *CaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if v.Tokens[token] {
+		return nil
+	}
+	form := url.Values{"secret": {v.Secret}, "response": {token}, "remoteip": {remoteIP}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CAPTCHA provider answered %s", resp.Status)
+	}
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unreadable CAPTCHA verification: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrChallengeInvalid, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
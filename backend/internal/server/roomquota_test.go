@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func post(r *gin.Engine, path, playerID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if playerID != "" {
+		req.Header.Set("X-Player-ID", playerID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestRoomQuotaSharedByCreationPaths counts rooms made through every guarded
+// handler and Reserve against the same client
+func TestRoomQuotaSharedByCreationPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	q, err := NewRoomQuota(RoomQuotaOptions{PerIP: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := func(c *gin.Context) { c.Status(http.StatusOK) }
+	failed := func(c *gin.Context) { c.Status(http.StatusInternalServerError) }
+
+	r := gin.New()
+	r.POST("/games", RoomQuotaGuarded(q, created))
+	r.POST("/tutorial", RoomQuotaGuarded(q, created))
+	r.POST("/broken", RoomQuotaGuarded(q, failed))
+
+	if w := post(r, "/broken", ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("broken = %d", w.Code)
+	}
+	if w := post(r, "/games", ""); w.Code != http.StatusOK {
+		t.Fatalf("games = %d, failed creations must not count", w.Code)
+	}
+	if w := post(r, "/tutorial", "alice"); w.Code != http.StatusOK {
+		t.Fatalf("tutorial = %d", w.Code)
+	}
+	w := post(r, "/games", "bob")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("games past the quota = %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	_, err = q.Reserve(context.Background(), "10.0.0.1", "carol", "")
+	var denial *RoomDenial
+	if !errors.As(err, &denial) || denial.Reason != RefusedIPQuota {
+		t.Fatalf("Reserve past the quota = %v", err)
+	}
+	release, err := q.Reserve(context.Background(), "10.0.0.2", "", "")
+	if err != nil {
+		t.Fatalf("Reserve of another IP = %v", err)
+	}
+	release()
+}
+
+// slowVerifier accepts every token after a delay, like a CAPTCHA provider
+type slowVerifier struct{}
+
+func (slowVerifier) Verify(context.Context, string, string) error {
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+// TestRoomQuotaChallengedStaysWithinLimit sends many creations past the
+// challenge threshold at once; passing the challenge must not lift the quota
+func TestRoomQuotaChallengedStaysWithinLimit(t *testing.T) {
+	q, err := NewRoomQuota(RoomQuotaOptions{PerIP: 3, ChallengeAfter: 1, Verifier: slowVerifier{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.Reserve(context.Background(), "10.0.0.1", "", "token"); err == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := created.Load(); n != 3 {
+		t.Fatalf("%d rooms created, want the quota of 3", n)
+	}
+}
@@ -0,0 +1,11 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountRooms registers the room code and wallet endpoints
+func MountRooms(r *gin.Engine, join, transfer gin.HandlerFunc) {
+	r.GET("/api/v1/rooms/:code/join", join)
+	r.POST("/api/v1/games/:id/transfer", transfer)
+}
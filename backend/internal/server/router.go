@@ -3,6 +3,8 @@ package server
 import (
 	"net/http"
 
+	"tower-defense/internal/api"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,7 +29,8 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 		}
 
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+PlayerIDHeader+", "+AdminTokenHeader+", "+IdempotencyKeyHeader+", "+RoomTokenHeader)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", IdempotentReplayedHeader)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == http.MethodOptions {
@@ -38,19 +41,27 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 	}
 }
 
-// NewRouter wires up the HTTP routes.
-func NewRouter(wsHandler gin.HandlerFunc, addTower gin.HandlerFunc, getState gin.HandlerFunc, reset gin.HandlerFunc, saveGame gin.HandlerFunc, loadGame gin.HandlerFunc, createGame gin.HandlerFunc, listGames gin.HandlerFunc, listMaps gin.HandlerFunc, changeMap gin.HandlerFunc, allowedOrigins []string) *gin.Engine {
+// NewRouter wires up the HTTP routes. middleware runs after CORS for every
+// route, including the ones mounted later. Only trustedProxies may name the
+// client in X-Forwarded-For; with none, c.ClientIP() is the peer address, so
+// clients can't pick the IP that rate limits and quotas key on.
+func NewRouter(wsHandler gin.HandlerFunc, addTower gin.HandlerFunc, getState gin.HandlerFunc, reset gin.HandlerFunc, saveGame gin.HandlerFunc, loadGame gin.HandlerFunc, createGame gin.HandlerFunc, listGames gin.HandlerFunc, listMaps gin.HandlerFunc, changeMap gin.HandlerFunc, allowedOrigins []string, trustedProxies []string, middleware ...gin.HandlerFunc) (*gin.Engine, error) {
 	r := gin.New()
-	// logging + recovery
-	r.Use(RequestLogger(), gin.Recovery())
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	// logging + metrics + recovery
+	r.Use(RequestLogger(), HTTPMetrics(), gin.Recovery())
 
 	// CORS
 	r.Use(CORS(allowedOrigins))
+	r.Use(middleware...)
 
 	// Versioned API group
 	v1 := r.Group("/api/v1")
 	{
-		v1.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+		v1.GET("/health", Liveness) // superseded by /healthz, see MountHealth
+		v1.GET("/openapi.json", api.OpenAPIHandler())
 		v1.GET("/state", getState)
 		v1.POST("/tower", addTower)
 		v1.POST("/reset", reset)
@@ -63,7 +74,7 @@ func NewRouter(wsHandler gin.HandlerFunc, addTower gin.HandlerFunc, getState gin
 	}
 
 	// Legacy routes (backward compatibility)
-	r.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	r.GET("/health", Liveness)
 	r.GET("/state", getState)
 	r.POST("/tower", addTower)
 	r.POST("/reset", reset)
@@ -78,5 +89,5 @@ func NewRouter(wsHandler gin.HandlerFunc, addTower gin.HandlerFunc, getState gin
 	// metrics mount (optional)
 	MountMetrics(r)
 
-	return r
+	return r, nil
 }
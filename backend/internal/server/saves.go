@@ -0,0 +1,10 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountSaves registers the save slot listing of the default game
+func MountSaves(r *gin.Engine, listSlots gin.HandlerFunc) {
+	r.GET("/api/v1/saves", listSlots)
+}
@@ -0,0 +1,12 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountSummaries registers the end-of-game report and replay endpoints
+func MountSummaries(r *gin.Engine, getSummary, getReplay, replayFrames gin.HandlerFunc) {
+	r.GET("/api/v1/games/:id/summary", getSummary)
+	r.GET("/api/v1/replays/:id", getReplay)
+	r.GET("/api/v1/replays/:id/frames", replayFrames)
+}
@@ -0,0 +1,12 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountTournaments registers the tournament bracket endpoints
+func MountTournaments(r *gin.Engine, create, get, surrender gin.HandlerFunc) {
+	r.POST("/api/v1/tournaments", create)
+	r.GET("/api/v1/tournaments/:id", get)
+	r.POST("/api/v1/match/:id/surrender", surrender)
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountTowers registers endpoints acting on the towers of a game
+func MountTowers(r *gin.Engine, resupply, sell, sellAll, applyTemplate gin.HandlerFunc) {
+	r.POST("/api/v1/games/:id/towers/:towerId/resupply", resupply)
+	r.POST("/api/v1/games/:id/towers/:towerId/sell", sell)
+	r.POST("/api/v1/games/:id/sell-all", sellAll)
+	r.POST("/api/v1/games/:id/apply-template", applyTemplate)
+}
@@ -0,0 +1,12 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountTutorial registers the guided tutorial endpoints
+func MountTutorial(r *gin.Engine, start, progress, completion gin.HandlerFunc) {
+	r.POST("/api/v1/tutorial", start)
+	r.GET("/api/v1/games/:id/tutorial", progress)
+	r.GET("/api/v1/players/:id/tutorial", completion)
+}
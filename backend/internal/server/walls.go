@@ -0,0 +1,10 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountWalls registers the wall building endpoint of the default game
+func MountWalls(r *gin.Engine, addWall gin.HandlerFunc) {
+	r.POST("/api/v1/wall", addWall)
+}
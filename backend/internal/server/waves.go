@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// MountWaves registers the upcoming waves preview, wave rollback and wave dry
+// run endpoints
+func MountWaves(r *gin.Engine, preview, rollback, simulate gin.HandlerFunc) {
+	r.GET("/api/v1/games/:id/waves/preview", preview)
+	r.POST("/api/v1/games/:id/rollback", rollback)
+	r.POST("/api/v1/games/:id/simulate-wave", simulate)
+}
@@ -1,76 +1,602 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"tower-defense/internal/api"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
-	conn *websocket.Conn
-	send chan []byte
+	id          string
+	gameID      string
+	playerID    string
+	remoteAddr  string
+	connectedAt time.Time
+	conn        *websocket.Conn
+	send        chan outbound
+	seq         uint64 // owned by writePump
+
+	// mu guards the fields below and closing send; see Hub for the lock order
+	mu     sync.Mutex
+	closed bool // send is closed, nothing may be queued anymore
+
+	// Snapshot subscription
+	interval     time.Duration // minimum time between snapshots, 0 = every broadcast
+	detail       string        // DetailFull or DetailLite
+	lastSnapshot time.Time
+
+	// Backpressure, see Hub.enqueue
+	stalledSince time.Time // when the send queue overflowed, zero while the client keeps up
+	skipped      uint64    // messages skipped since then
+
+	// Latency, see heartbeat.go
+	pingID   uint64        // heartbeat awaiting a pong, 0 = none
+	pingSent time.Time     // when it was queued
+	rtt      time.Duration // last round trip, 0 = not measured yet
+	rttAvg   time.Duration // moving average of the round trips
+
+	// Connection stats, see Hub.Connections
+	sent     atomic.Uint64 // messages written to the socket
+	dropped  atomic.Uint64 // messages skipped because the client fell behind
+	lastPong atomic.Int64  // unix nanoseconds of the last pong, 0 = none yet
 }
 
+// ID returns the connection ID
+func (c *Client) ID() string { return c.id }
+
+// GameID returns the game the client is watching
+func (c *Client) GameID() string { return c.gameID }
+
+// PlayerID returns the player identity supplied at connect time, if any
+func (c *Client) PlayerID() string { return c.playerID }
+
+// CommandHandler processes client messages that the hub does not handle itself
+type CommandHandler func(c *Client, msg InboundMessage)
+
+// Relay receives every message broadcast by this hub, e.g. to forward it to other server instances
+type Relay func(typ MessageType, gameID string, payload []byte)
+
+// room is the set of clients watching one game
+type room map[*Client]struct{}
+
+// Hub tracks WebSocket clients by the game they watch and fans messages out to them.
+//
+// Locking: mu only guards room membership. Fan-out takes the read lock just
+// long enough to copy the clients of a room, so rooms filter and queue their
+// snapshots in parallel. Each Client's mu guards its subscription, backpressure
+// and latency fields and the closing of its send channel, so no one can
+// enqueue to a closed channel; it is taken after mu, never before. Each
+// client's seq and conn writes are owned by its write pump.
 type Hub struct {
-	clients    map[*Client]bool
-	register   chan *Client
+	mu         sync.RWMutex
+	rooms      map[string]room // game ID -> clients
 	unregister chan *Client
+	ping       chan chan struct{}
+	commands   *RateLimiter  // per-connection limit on inbound messages, nil = unlimited
+	stall      time.Duration // how long a client may stay behind before it is dropped
+	heartbeat  time.Duration // interval of application-level pings, 0 = off
+	shareRTT   bool          // tell each room the latency of its players
+	pingSeq    uint64        // ID of the last heartbeat, owned by Run
+	onCommand  CommandHandler
+	relay      Relay
+
+	// Keyframes, see SetKeyframeInterval
+	keyframes    time.Duration        // 0 = off
+	keyframeMu   sync.Mutex           // taken after mu, never before
+	lastKeyframe map[string]time.Time // by game ID, guarded by keyframeMu
+
+	// Shutdown
+	closing atomic.Bool    // set by Shutdown under mu; new connections are refused
+	pumps   sync.WaitGroup // running write pumps
+	done    chan struct{}  // closed when Shutdown has finished, stops Run
+	endOnce sync.Once      // closes done, as Shutdown may be called again
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		register:   make(chan *Client),
+		rooms:      make(map[string]room),
 		unregister: make(chan *Client),
+		ping:       make(chan chan struct{}),
+		done:       make(chan struct{}),
+		stall:      DefaultStallTimeout,
+		heartbeat:  DefaultHeartbeatInterval,
+
+		keyframes:    DefaultKeyframeInterval,
+		lastKeyframe: make(map[string]time.Time),
 	}
 }
 
+// Run processes unregistrations and sends heartbeats until Shutdown completes
 func (h *Hub) Run() {
+	var beat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		beat = ticker.C
+	}
 	for {
 		select {
-		case c := <-h.register:
-			h.clients[c] = true
+		case <-h.done:
+			return
+		case now := <-beat:
+			h.sendHeartbeat(now)
 		case c := <-h.unregister:
-			if _, ok := h.clients[c]; ok {
-				delete(h.clients, c)
-				close(c.send)
+			h.mu.Lock()
+			h.removeClient(c)
+			h.mu.Unlock()
+			c.closeSend()
+		case reply := <-h.ping:
+			close(reply)
+		}
+	}
+}
+
+// addClient registers c in the room of its game (caller must hold h.mu for writing)
+func (h *Hub) addClient(c *Client) {
+	r := h.rooms[c.gameID]
+	if r == nil {
+		r = make(room)
+		h.rooms[c.gameID] = r
+	}
+	r[c] = struct{}{}
+}
+
+// removeClient unregisters c, dropping its room when it was the last client.
+// It reports whether c was registered (caller must hold h.mu for writing).
+func (h *Hub) removeClient(c *Client) bool {
+	r := h.rooms[c.gameID]
+	if _, ok := r[c]; !ok {
+		return false
+	}
+	delete(r, c)
+	if len(r) == 0 {
+		delete(h.rooms, c.gameID)
+		h.keyframeMu.Lock()
+		delete(h.lastKeyframe, c.gameID)
+		h.keyframeMu.Unlock()
+	}
+	return true
+}
+
+// closeSend closes the client's send channel, which ends its write pump, unless
+// it is closed already. It reports whether it closed it.
+func (c *Client) closeSend() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	c.closed = true
+	close(c.send)
+	return true
+}
+
+// roomClients returns a copy of the clients watching gameID
+func (h *Hub) roomClients(gameID string) []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := make([]*Client, 0, len(h.rooms[gameID]))
+	for c := range h.rooms[gameID] {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// Rooms returns the IDs of the games that have at least one client connected
+func (h *Hub) Rooms() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]string, 0, len(h.rooms))
+	for id := range h.rooms {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// allClients returns every connected client (caller must hold h.mu)
+func (h *Hub) allClients() []*Client {
+	var clients []*Client
+	for _, r := range h.rooms {
+		for c := range r {
+			clients = append(clients, c)
+		}
+	}
+	return clients
+}
+
+// Ping checks that the Run loop is alive and not blocked
+func (h *Hub) Ping(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case h.ping <- reply:
+	case <-ctx.Done():
+		return errors.New("hub loop not responding")
+	case <-h.done:
+		return errors.New("hub shut down")
+	}
+	<-reply
+	return nil
+}
+
+// Shutdown refuses new connections and closes every client with a going-away
+// close frame after the messages already queued for it. It waits for the write
+// pumps to finish until ctx is done, then drops the remaining connections.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing.Store(true)
+	clients := h.allClients()
+	h.rooms = make(map[string]room)
+	h.mu.Unlock()
+	for _, c := range clients {
+		c.closeSend()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = fmt.Errorf("%d clients not drained: %w", len(clients), ctx.Err())
+		for _, c := range clients {
+			c.conn.Close()
+		}
+	}
+	h.endOnce.Do(func() { close(h.done) })
+	logging.Infow("ws_hub_shutdown", "clients", len(clients), "drained", err == nil)
+	return err
+}
+
+// Broadcast sends a pre-encoded JSON payload to every client watching gameID
+func (h *Hub) Broadcast(typ MessageType, gameID string, payload []byte) {
+	h.DeliverRemote(typ, gameID, payload)
+	if h.relay != nil {
+		h.relay(typ, gameID, payload)
+	}
+}
+
+// DeliverRemote broadcasts a message that originated on another server instance.
+// Unlike Broadcast it is not passed to the relay again.
+func (h *Hub) DeliverRemote(typ MessageType, gameID string, payload []byte) {
+	msg := outbound{typ: typ, gameID: gameID, payload: payload, sentAt: time.Now()}
+	WsBroadcastBytes.WithLabelValues(string(typ)).Observe(float64(len(payload)))
+	clients := h.roomClients(gameID)
+	if typ == MsgSnapshot {
+		h.deliverSnapshot(msg, clients)
+		return
+	}
+	for _, c := range clients {
+		h.deliver(c, msg)
+	}
+}
+
+// deliver queues msg for c unless c is closed
+func (h *Hub) deliver(c *Client, msg outbound) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h.enqueue(c, msg)
+}
+
+// snapshotSlack lets a snapshot through slightly early, so broadcaster jitter
+// doesn't halve the rate of a client subscribed at the broadcast rate
+const snapshotSlack = 20 * time.Millisecond
+
+// deliverSnapshot sends a snapshot to the clients of its game that are due one,
+// in the detail they subscribed to and, under fog of war, with only the enemies
+// their player sees. A keyframe goes to every client in full detail.
+func (h *Hub) deliverSnapshot(msg outbound, clients []*Client) {
+	now := time.Now()
+	if h.keyframes > 0 {
+		h.keyframeMu.Lock()
+		if now.Sub(h.lastKeyframe[msg.gameID]) >= h.keyframes-snapshotSlack {
+			msg.keyframe = true
+			h.lastKeyframe[msg.gameID] = now
+		}
+		h.keyframeMu.Unlock()
+	}
+	fog := newFogFilter(msg.payload)
+	views := map[string][]byte{} // filtered payloads by player ID, then detail
+	for _, c := range clients {
+		c.mu.Lock()
+		due := now.Sub(c.lastSnapshot) >= c.interval-snapshotSlack || !c.stalledSince.IsZero() || msg.keyframe
+		if due {
+			c.lastSnapshot = now
+		}
+		detail := c.detail
+		c.mu.Unlock()
+		if !due {
+			continue
+		}
+		if msg.keyframe {
+			detail = DetailFull
+		}
+		if fog == nil && detail != DetailLite {
+			h.deliver(c, msg)
+			continue
+		}
+		viewer := ""
+		if fog != nil {
+			viewer = c.playerID
+		}
+		key := viewer + "|" + detail
+		payload, ok := views[key]
+		if !ok {
+			payload = msg.payload
+			if fog != nil {
+				payload = fog.visibleTo(viewer)
 			}
+			if detail == DetailLite {
+				payload = stripProjectiles(payload)
+			}
+			views[key] = payload
 		}
+		h.deliver(c, outbound{typ: msg.typ, gameID: msg.gameID, payload: payload, keyframe: msg.keyframe, sentAt: msg.sentAt})
+	}
+}
+
+// stripProjectiles removes the projectiles from an encoded snapshot. A payload
+// that is not a JSON object is returned unchanged.
+func stripProjectiles(payload []byte) []byte {
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return payload
+	}
+	delete(state, "projectiles")
+	lite, err := json.Marshal(state)
+	if err != nil {
+		return payload
+	}
+	return lite
+}
+
+// SetRelay registers a relay for locally originated broadcasts
+func (h *Hub) SetRelay(r Relay) {
+	h.relay = r
+}
+
+// BroadcastJSON encodes v once and broadcasts it to every client watching gameID
+func (h *Hub) BroadcastJSON(typ MessageType, gameID string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.Broadcast(typ, gameID, payload)
+	return nil
+}
+
+// NotifyAll delivers a message to every client connected to this instance,
+// whatever game it watches. It is not relayed to other instances.
+func (h *Hub) NotifyAll(typ MessageType, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.mu.RLock()
+	clients := h.allClients()
+	h.mu.RUnlock()
+	for _, c := range clients {
+		h.deliver(c, outbound{typ: typ, gameID: c.gameID, payload: payload})
 	}
+	return nil
 }
 
-func (h *Hub) Broadcast(msg []byte) {
-	for c := range h.clients {
+// Send delivers a message to a single client
+func (h *Hub) Send(c *Client, typ MessageType, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.deliver(c, outbound{typ: typ, gameID: c.gameID, payload: payload})
+	return nil
+}
+
+// DefaultStallTimeout is how long a client may fall behind before it is dropped
+const DefaultStallTimeout = 10 * time.Second
+
+// DefaultKeyframeInterval is how often every client of a room gets a keyframe
+const DefaultKeyframeInterval = time.Second
+
+// enqueue queues msg for c (caller must hold c.mu). When the send queue of a
+// client is full its messages are skipped until the queue has drained; the
+// next snapshot then resyncs it. A client that stays behind for longer than
+// the stall timeout is dropped: its send channel and socket are closed, and
+// its read pump then unregisters it.
+func (h *Hub) enqueue(c *Client, msg outbound) {
+	if c.closed {
+		return
+	}
+	if c.stalledSince.IsZero() {
 		select {
 		case c.send <- msg:
-			// ok
+			return
 		default:
-			// backpressure: drop client if it can't keep up
-			log.Println("dropping slow client")
-			delete(h.clients, c)
-			close(c.send)
+			c.stalledSince = time.Now()
+			logging.Warnw("ws_client_stalled", "client_id", c.id, "game_id", c.gameID)
+		}
+	} else if msg.typ == MsgSnapshot && len(c.send) == 0 {
+		msg.skipped = c.skipped
+		c.send <- msg
+		logging.Infow("ws_client_resynced", "client_id", c.id, "game_id", c.gameID,
+			"skipped", c.skipped, "stalled_ms", time.Since(c.stalledSince).Milliseconds())
+		c.stalledSince, c.skipped = time.Time{}, 0
+		return
+	}
+
+	if time.Since(c.stalledSince) > h.stall {
+		logging.Warnw("ws_client_dropped", "client_id", c.id, "game_id", c.gameID, "skipped", c.skipped)
+		c.closed = true
+		close(c.send)
+		c.conn.Close()
+		return
+	}
+	c.skipped++
+	c.dropped.Add(1)
+	WsMessagesDropped.Inc()
+}
+
+// SetCommandHandler routes client commands other than chat to f
+func (h *Hub) SetCommandHandler(f CommandHandler) {
+	h.onCommand = f
+}
+
+// SetKeyframeInterval sets how often every client of a room gets a snapshot
+// marked as keyframe in full detail, even when it subscribed to fewer or lite
+// snapshots, so clients predicting their commands have authoritative state to
+// reconcile with; 0 = off. Call it before clients connect.
+func (h *Hub) SetKeyframeInterval(d time.Duration) {
+	h.keyframes = d
+}
+
+// SetStallTimeout sets how long a client may fall behind before it is dropped;
+// 0 drops it as soon as its send queue is full
+func (h *Hub) SetStallTimeout(d time.Duration) {
+	h.stall = d
+}
+
+// SetCommandLimiter limits inbound WS messages per connection
+func (h *Hub) SetCommandLimiter(l *RateLimiter) {
+	h.commands = l
+}
+
+// ClientCount returns the number of clients connected to gameID
+func (h *Hub) ClientCount(gameID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[gameID])
+}
+
+// Connections returns the metadata of the connected clients, of every game
+// for an empty gameID, sorted by connect time
+func (h *Hub) Connections(gameID string) []api.ConnectionInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var clients []*Client
+	if gameID == "" {
+		clients = h.allClients()
+	} else {
+		for c := range h.rooms[gameID] {
+			clients = append(clients, c)
+		}
+	}
+	infos := make([]api.ConnectionInfo, 0, len(clients))
+	for _, c := range clients {
+		c.mu.Lock()
+		info := api.ConnectionInfo{
+			ID:              c.id,
+			GameID:          c.gameID,
+			PlayerID:        c.playerID,
+			RemoteAddr:      c.remoteAddr,
+			ConnectedAt:     c.connectedAt,
+			Detail:          c.detail,
+			MessagesSent:    c.sent.Load(),
+			MessagesDropped: c.dropped.Load(),
+			Queued:          len(c.send),
+			Stalled:         !c.stalledSince.IsZero(),
+		}
+		if c.interval > 0 {
+			info.SnapshotRate = float64(time.Second) / float64(c.interval)
+		}
+		if c.rtt > 0 {
+			info.RttMs = durationMs(c.rtt)
+			info.RttAvgMs = durationMs(c.rttAvg)
+		}
+		c.mu.Unlock()
+		if pong := c.lastPong.Load(); pong != 0 {
+			t := time.Unix(0, pong)
+			info.LastPong = &t
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ConnectedAt.Before(infos[j].ConnectedAt) })
+	return infos
+}
+
+// Clients returns the currently connected clients
+func (h *Hub) Clients() []api.ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := h.allClients()
+	infos := make([]api.ClientInfo, 0, len(clients))
+	for _, c := range clients {
+		infos = append(infos, api.ClientInfo{ID: c.id, RemoteAddr: c.remoteAddr, ConnectedAt: c.connectedAt})
+	}
+	return infos
+}
+
+// Kick closes the connection of the client with the given ID.
+// The read pump notices the closed socket and unregisters the client.
+func (h *Hub) Kick(id string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, c := range h.allClients() {
+		if c.id == id {
 			c.conn.Close()
+			logging.Infow("client_kicked", "client_id", id)
+			return true
 		}
 	}
+	return false
 }
 
-// ServeWS upgrades connection and attaches client to the hub with heartbeat and write pump
-func (h *Hub) ServeWS(upgrader websocket.Upgrader) func(w http.ResponseWriter, r *http.Request) {
+// ServeWS upgrades connection and attaches client to the hub with heartbeat and write pump.
+// The client receives messages for gameID; an optional playerId query parameter identifies it.
+func (h *Hub) ServeWS(upgrader websocket.Upgrader, gameID string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if h.closing.Load() {
+			http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Println("WebSocket upgrade error:", err)
 			return
 		}
-		client := &Client{conn: conn, send: make(chan []byte, 8)}
-		h.register <- client
+		client := &Client{
+			id:          uuid.New().String(),
+			gameID:      gameID,
+			playerID:    r.URL.Query().Get("playerId"),
+			remoteAddr:  r.RemoteAddr,
+			connectedAt: time.Now(),
+			conn:        conn,
+			send:        make(chan outbound, 8),
+			detail:      DetailFull,
+		}
+		// register synchronously so replies to the client's first message are not lost
+		h.mu.Lock()
+		if h.closing.Load() {
+			// Shutdown started during the upgrade
+			h.mu.Unlock()
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			conn.Close()
+			return
+		}
+		h.addClient(client)
+		h.pumps.Add(1)
+		h.mu.Unlock()
 		log.Println("✅ WS client connected")
 
 		conn.SetReadLimit(512)
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		conn.SetPongHandler(func(string) error {
+			client.lastPong.Store(time.Now().UnixNano())
 			conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 			return nil
 		})
@@ -82,13 +608,108 @@ func (h *Hub) ServeWS(upgrader websocket.Upgrader) func(w http.ResponseWriter, r
 
 func (c *Client) readPump(h *Hub) {
 	defer func() {
-		h.unregister <- c
+		select {
+		case h.unregister <- c:
+		case <-h.done:
+		}
 		c.conn.Close()
 	}()
 	for {
-		if _, _, err := c.conn.ReadMessage(); err != nil {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
 			break
 		}
+		var msg InboundMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+			h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "expected {type, requestId, payload}"})
+			continue
+		}
+		if ok, wait := h.commands.Allow(c.id); !ok {
+			// drop the command; the client keeps its connection
+			RateLimitedTotal.WithLabelValues("ws").Inc()
+			logging.Warnw("ws_rate_limited", "client_id", c.id, "remote_addr", c.remoteAddr)
+			h.Send(c, MsgError, ErrorPayload{
+				Code:      ErrCodeRateLimited,
+				Message:   "rate limit exceeded, retry in " + wait.Round(time.Millisecond).String(),
+				RequestID: msg.RequestID,
+			})
+			continue
+		}
+		h.handleMessage(c, msg)
+	}
+}
+
+// handleMessage dispatches a decoded client message
+func (h *Hub) handleMessage(c *Client, msg InboundMessage) {
+	switch {
+	case msg.Type == string(MsgChat):
+		h.handleChat(c, msg)
+	case msg.Type == CmdSubscribe:
+		h.handleSubscribe(c, msg)
+	case msg.Type == CmdPing:
+		h.handlePing(c, msg)
+	case msg.Type == CmdPong:
+		h.handlePong(c, msg)
+	case h.onCommand != nil:
+		h.onCommand(c, msg)
+	default:
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeUnknownCommand, Message: "unknown message type: " + msg.Type, RequestID: msg.RequestID})
+	}
+}
+
+// handleChat relays a chat line to everyone watching the same game
+func (h *Hub) handleChat(c *Client, msg InboundMessage) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(msg.Payload, &req); err != nil || strings.TrimSpace(req.Text) == "" {
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "chat needs a non-empty text", RequestID: msg.RequestID})
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if runes := []rune(text); len(runes) > maxChatLength {
+		text = string(runes[:maxChatLength])
+	}
+	from := c.playerID
+	if from == "" {
+		from = "guest-" + c.id[:8]
+	}
+	h.BroadcastJSON(MsgChat, c.gameID, ChatPayload{From: from, Text: text, Time: time.Now()})
+	if msg.RequestID != "" {
+		h.Send(c, MsgAck, AckPayload{RequestID: msg.RequestID, Command: msg.Type})
+	}
+}
+
+// handleSubscribe changes the snapshot rate and detail level of a client
+func (h *Hub) handleSubscribe(c *Client, msg InboundMessage) {
+	var req SubscribePayload
+	if err := json.Unmarshal(msg.Payload, &req); err != nil {
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "invalid subscribe payload", RequestID: msg.RequestID})
+		return
+	}
+	if req.Rate != nil && (*req.Rate < 0 || *req.Rate > maxSnapshotRate) {
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: fmt.Sprintf("rate must be between 0 and %d", maxSnapshotRate), RequestID: msg.RequestID})
+		return
+	}
+	if req.Detail != "" && req.Detail != DetailFull && req.Detail != DetailLite {
+		h.Send(c, MsgError, ErrorPayload{Code: ErrCodeBadRequest, Message: "detail must be full or lite", RequestID: msg.RequestID})
+		return
+	}
+
+	c.mu.Lock()
+	if req.Rate != nil {
+		c.interval = 0
+		if *req.Rate > 0 {
+			c.interval = time.Duration(float64(time.Second) / *req.Rate)
+		}
+	}
+	if req.Detail != "" {
+		c.detail = req.Detail
+	}
+	c.mu.Unlock()
+
+	if msg.RequestID != "" {
+		h.Send(c, MsgAck, AckPayload{RequestID: msg.RequestID, Command: msg.Type})
 	}
 }
 
@@ -97,18 +718,29 @@ func (c *Client) writePump(h *Hub) {
 	defer func() {
 		pingTicker.Stop()
 		c.conn.Close()
+		h.pumps.Done()
 	}()
 	for {
 		select {
 		case msg, ok := <-c.send:
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMsg := []byte{}
+				if h.closing.Load() {
+					closeMsg = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+				}
+				c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+				c.conn.WriteMessage(websocket.CloseMessage, closeMsg)
 				return
 			}
+			c.seq += 1 + msg.skipped
 			c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg.encode(c.seq)); err != nil {
 				return
 			}
+			c.sent.Add(1)
+			if !msg.sentAt.IsZero() {
+				WsBroadcastLatency.WithLabelValues(string(msg.typ)).Observe(time.Since(msg.sentAt).Seconds())
+			}
 		case <-pingTicker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
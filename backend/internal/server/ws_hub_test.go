@@ -0,0 +1,256 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startHub serves a hub for every game ID in the request path under /ws/
+func startHub(t *testing.T, h *Hub) *httptest.Server {
+	t.Helper()
+	go h.Run()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeWS(websocket.Upgrader{}, strings.TrimPrefix(r.URL.Path, "/ws/"))(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, gameID string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/"+gameID, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", gameID, err)
+	}
+	return conn
+}
+
+// drain reads from conn until it is closed
+func drain(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHubConcurrentBroadcast(t *testing.T) {
+	h := NewHub()
+	h.SetKeyframeInterval(10 * time.Millisecond)
+	h.SetHeartbeat(2*time.Millisecond, true)
+	srv := startHub(t, h)
+	games := []string{"g1", "g2", "g3"}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, gameID := range games {
+		gameID := gameID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				h.Broadcast(MsgSnapshot, gameID, []byte(fmt.Sprintf(`{"tick":%d,"projectiles":[]}`, i)))
+				h.BroadcastJSON(MsgEvent, gameID, map[string]int{"tick": i})
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			h.NotifyAll(MsgEvent, map[string]string{"notice": "hello"})
+			h.Connections("")
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// clients come and go while the rooms broadcast
+	var clients sync.WaitGroup
+	for i := 0; i < 24; i++ {
+		clients.Add(1)
+		go func(i int) {
+			defer clients.Done()
+			conn := dial(t, srv, games[i%len(games)])
+			done := make(chan struct{})
+			go func() {
+				drain(conn)
+				close(done)
+			}()
+			rate := float64(i % 4 * 10)
+			sub, _ := json.Marshal(SubscribePayload{Rate: &rate, Detail: []string{DetailFull, DetailLite}[i%2]})
+			conn.WriteJSON(InboundMessage{Type: CmdSubscribe, RequestID: "sub", Payload: sub})
+			time.Sleep(time.Duration(10+i) * time.Millisecond)
+			conn.Close()
+			<-done
+		}(i)
+	}
+	clients.Wait()
+	close(stop)
+	wg.Wait()
+
+	for _, gameID := range games {
+		waitFor(t, "clients of "+gameID+" to unregister", func() bool { return h.ClientCount(gameID) == 0 })
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
+
+func TestHubDropsStalledClient(t *testing.T) {
+	h := NewHub()
+	h.SetStallTimeout(200 * time.Millisecond)
+	srv := startHub(t, h)
+
+	// the stalled client never reads, so its socket buffers fill up
+	stalled := dial(t, srv, "g1")
+	defer stalled.Close()
+	reader := dial(t, srv, "g1")
+	defer reader.Close()
+	go drain(reader)
+	waitFor(t, "clients to register", func() bool { return h.ClientCount("g1") == 2 })
+
+	payload := []byte(`{"blob":"` + strings.Repeat("x", 64<<10) + `"}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h.ClientCount("g1") == 2 {
+				h.Broadcast(MsgEvent, "g1", payload)
+				time.Sleep(2 * time.Millisecond)
+			}
+		}()
+	}
+	waitFor(t, "the stalled client to be dropped", func() bool { return h.ClientCount("g1") < 2 })
+	wg.Wait()
+
+	// the client that kept up still gets messages
+	h.Broadcast(MsgEvent, "g1", []byte(`{}`))
+	if conns := h.Connections("g1"); len(conns) != 1 || conns[0].Stalled {
+		t.Fatalf("connections after drop = %+v", conns)
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	h := NewHub()
+	srv := startHub(t, h)
+
+	conns := make([]*websocket.Conn, 8)
+	for i := range conns {
+		conns[i] = dial(t, srv, fmt.Sprintf("g%d", i%2))
+		defer conns[i].Close()
+	}
+	waitFor(t, "clients to register", func() bool { return h.ClientCount("g0")+h.ClientCount("g1") == len(conns) })
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(gameID string) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				h.Broadcast(MsgSnapshot, gameID, []byte(`{"tick":1}`))
+			}
+		}(fmt.Sprintf("g%d", i%2))
+	}
+
+	results := make(chan error, len(conns))
+	for _, conn := range conns {
+		go func(conn *websocket.Conn) {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					results <- err
+					return
+				}
+			}
+		}(conn)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	for range conns {
+		if err := <-results; !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("client closed with %v, want going away", err)
+		}
+	}
+	if n := len(h.Rooms()); n != 0 {
+		t.Errorf("rooms after shutdown = %d, want 0", n)
+	}
+
+	resp, err := http.Get(srv.URL + "/ws/g0")
+	if err != nil {
+		t.Fatalf("get after shutdown: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status after shutdown = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+// TestHubShutdownTwice shuts a hub down from two callers at once and again
+// afterwards, e.g. a signal handler racing a failing server
+func TestHubShutdownTwice(t *testing.T) {
+	h := NewHub()
+	srv := startHub(t, h)
+	conn := dial(t, srv, "g1")
+	defer conn.Close()
+	waitFor(t, "client to register", func() bool { return h.ClientCount("g1") == 1 })
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.Shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown of a stopped hub: %v", err)
+	}
+}
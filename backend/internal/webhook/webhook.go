@@ -0,0 +1,391 @@
+// Package webhook POSTs significant game events to outside services: a global
+// URL set with WEBHOOK_URL and, where allowed, a URL chosen per room.
+// Deliveries are signed, retried with exponential backoff and, once they give
+// up, kept in a dead-letter log.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"tower-defense/internal/game"
+	"tower-defense/internal/game/events"
+	"tower-defense/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of webhook payloads
+const (
+	KindGameCreated = "game_created"
+	KindGameOver    = "game_over"  // Data is the game's summary
+	KindHighScore   = "high_score" // Data is a HighScore
+)
+
+// Headers of every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix seconds, signed along with the body
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+)
+
+// Defaults of Options
+const (
+	DefaultMaxAttempts = 5
+	DefaultTimeout     = 5 * time.Second
+	DefaultBackoff     = time.Second
+	maxBackoff         = time.Minute
+	queueSize          = 256
+	deadLetterSize     = 100 // kept in memory for GET /admin/webhooks/dead-letters
+	workers            = 2
+)
+
+// Delivery results, see Options.OnDelivery
+const (
+	ResultDelivered = "delivered"
+	ResultRetried   = "retried"
+	ResultFailed    = "failed"  // moved to the dead-letter log
+	ResultDropped   = "dropped" // the queue was full
+)
+
+var ErrInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+
+// Options configure a Notifier
+type Options struct {
+	URL            string        // receives every payload, "" = only rooms' own URLs
+	Secret         string        // HMAC key of the signature header, "" = unsigned
+	MaxAttempts    int           // tries per delivery, 0 = DefaultMaxAttempts
+	Timeout        time.Duration // per try, 0 = DefaultTimeout
+	Backoff        time.Duration // wait before the first retry, doubled after each one; 0 = DefaultBackoff
+	DeadLetterPath string        // JSON lines file failed deliveries are appended to, "" = memory only
+	Targets        []Target      // services that take payloads in their own format, e.g. chat integrations
+
+	OnDelivery func(result string) // called for every try's outcome, e.g. for metrics
+}
+
+// Target is a service that takes payloads in a format of its own. Its
+// deliveries are queued, retried and dead-lettered like any other.
+type Target struct {
+	Name string
+	URL  string
+
+	// Format returns the body to send for a payload; ok = false skips it
+	Format func(p Payload) (body []byte, ok bool)
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	ID     string    `json:"id"`
+	Kind   string    `json:"event"`
+	GameID string    `json:"gameId"`
+	Time   time.Time `json:"time"`
+	Data   any       `json:"data"`
+}
+
+// GameCreated is the data of a game_created payload
+type GameCreated struct {
+	MapID      string   `json:"mapId"`
+	Mutators   []string `json:"mutators,omitempty"`
+	TickRateMs int      `json:"tickRateMs"`
+	Seed       int64    `json:"seed"`
+	Tutorial   bool     `json:"tutorial,omitempty"` // a single player tutorial room
+}
+
+// HighScore is the data of a high_score payload: a finished game beat the best
+// score on its map since the server started
+type HighScore struct {
+	MapID    string `json:"mapId"`
+	Score    int    `json:"score"`
+	Previous int    `json:"previous"`
+}
+
+// DeadLetter is a delivery that gave up
+type DeadLetter struct {
+	URL      string    `json:"url"`
+	Target   string    `json:"target,omitempty"` // name of the Target, if it was one
+	Payload  Payload   `json:"payload"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+type delivery struct {
+	url     string
+	target  string // name of the Target, "" for plain webhooks
+	payload Payload
+	body    []byte // preformatted by the target, nil = the payload as JSON
+}
+
+// Notifier turns game events into webhook deliveries. Deliveries run on
+// background workers, so HandleEvent never blocks the game.
+type Notifier struct {
+	opts   Options
+	games  func(gameID string) (*game.Game, error)
+	client *http.Client
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.Mutex
+	highScores  map[string]int // best score per map
+	deadLetters []DeadLetter   // oldest first
+}
+
+// ValidateURL checks that a webhook URL can be delivered to
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, raw)
+	}
+	return nil
+}
+
+// NewNotifier starts a notifier; games looks up the game of an event for its
+// room URL, settings and summary
+func NewNotifier(opts Options, games func(gameID string) (*game.Game, error)) *Notifier {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		opts:       opts,
+		games:      games,
+		client:     &http.Client{Timeout: opts.Timeout},
+		queue:      make(chan delivery, queueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		highScores: make(map[string]int),
+	}
+	for range workers {
+		n.wg.Add(1)
+		go n.work()
+	}
+	return n
+}
+
+// Close stops the workers; deliveries still queued or waiting for a retry are dropped
+func (n *Notifier) Close() {
+	n.cancel()
+	n.wg.Wait()
+}
+
+// HandleEvent is a game event listener that sends game_created, game_over and
+// high_score payloads
+func (n *Notifier) HandleEvent(ev events.Event) {
+	switch ev.Type {
+	case events.GameCreated:
+		g, err := n.games(ev.GameID)
+		if err != nil {
+			return
+		}
+		data := GameCreated{MapID: g.MapID(), Mutators: g.Mutators(), TickRateMs: g.TickRateMs(), Seed: g.Seed(), Tutorial: g.Tutorial() != nil}
+		n.send(g, KindGameCreated, ev, data)
+	case events.GameOver:
+		g, err := n.games(ev.GameID)
+		if err != nil {
+			return
+		}
+		summary := g.Summary()
+		if summary == nil {
+			return
+		}
+		n.send(g, KindGameOver, ev, summary)
+		if previous, ok := n.recordScore(summary.MapID, summary.Score); ok {
+			n.send(g, KindHighScore, ev, HighScore{MapID: summary.MapID, Score: summary.Score, Previous: previous})
+		}
+	}
+}
+
+// recordScore keeps the best score of a map and reports whether score beat it
+func (n *Notifier) recordScore(mapID string, score int) (previous int, beaten bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	previous = n.highScores[mapID]
+	if score <= previous {
+		return previous, false
+	}
+	n.highScores[mapID] = score
+	return previous, true
+}
+
+// send queues a payload for the global URL, the game's own one and the targets
+func (n *Notifier) send(g *game.Game, kind string, ev events.Event, data any) {
+	p := Payload{ID: uuid.NewString(), Kind: kind, GameID: ev.GameID, Time: ev.Time, Data: data}
+	for _, u := range []string{n.opts.URL, g.WebhookURL()} {
+		if u != "" {
+			n.enqueue(delivery{url: u, payload: p})
+		}
+	}
+	for _, t := range n.opts.Targets {
+		if body, ok := t.Format(p); ok {
+			n.enqueue(delivery{url: t.URL, target: t.Name, payload: p, body: body})
+		}
+	}
+}
+
+func (n *Notifier) enqueue(d delivery) {
+	select {
+	case n.queue <- d:
+	default:
+		n.report(ResultDropped)
+		n.deadLetter(d, 0, errors.New("webhook queue full"))
+	}
+}
+
+func (n *Notifier) work() {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case d := <-n.queue:
+			n.deliver(d)
+		}
+	}
+}
+
+// deliver POSTs a payload, retrying network errors, 429s and 5xx responses
+// with exponential backoff
+func (n *Notifier) deliver(d delivery) {
+	body := d.body
+	if body == nil {
+		var err error
+		if body, err = json.Marshal(d.payload); err != nil {
+			n.report(ResultFailed)
+			n.deadLetter(d, 0, err)
+			return
+		}
+	}
+	backoff := n.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(d, body)
+		if err == nil {
+			n.report(ResultDelivered)
+			return
+		}
+		if !retry || attempt >= n.opts.MaxAttempts {
+			n.report(ResultFailed)
+			n.deadLetter(d, attempt, err)
+			return
+		}
+		n.report(ResultRetried)
+		logging.Debugw("webhook_retry", "url", d.url, "target", d.target, "event", d.payload.Kind, "attempt", attempt, "error", err)
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (n *Notifier) post(d delivery, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(n.ctx, n.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.payload.Kind)
+	req.Header.Set(HeaderID, d.payload.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	if n.opts.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(n.opts.Secret, ts, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the signature header of a body sent at timestamp ts; receivers
+// recompute it with the shared secret to check a delivery
+func Sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deadLetter records a delivery that gave up, in memory and in the dead-letter file
+func (n *Notifier) deadLetter(d delivery, attempts int, err error) {
+	dl := DeadLetter{URL: d.url, Target: d.target, Payload: d.payload, Attempts: attempts, Error: err.Error(), FailedAt: time.Now()}
+	logging.Warnw("webhook_failed", "url", d.url, "target", d.target, "event", d.payload.Kind, "game_id", d.payload.GameID, "attempts", attempts, "error", err)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.deadLetters = append(n.deadLetters, dl)
+	if len(n.deadLetters) > deadLetterSize {
+		n.deadLetters = n.deadLetters[len(n.deadLetters)-deadLetterSize:]
+	}
+	if n.opts.DeadLetterPath == "" {
+		return
+	}
+	line, err := json.Marshal(dl)
+	if err == nil {
+		err = appendLine(n.opts.DeadLetterPath, line)
+	}
+	if err != nil {
+		logging.Errorw("webhook_dead_letter_write_failed", "path", n.opts.DeadLetterPath, "error", err)
+	}
+}
+
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// DeadLetters returns the most recent failed deliveries, oldest first
+func (n *Notifier) DeadLetters() []DeadLetter {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]DeadLetter{}, n.deadLetters...)
+}
+
+func (n *Notifier) report(result string) {
+	if n.opts.OnDelivery != nil {
+		n.opts.OnDelivery(result)
+	}
+}
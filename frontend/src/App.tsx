@@ -1,6 +1,17 @@
 import { useEffect, useRef, useState } from 'react';
 import { API_URL, WS_URL } from './config';
-import type { GameState, TowerType } from './types';
+import type {
+  ApiError,
+  CommandAck,
+  CommandNack,
+  ErrorCode,
+  GameEvent,
+  GameState,
+  PendingTower,
+  ServerError,
+  ServerMessage,
+  TowerType,
+} from './types';
 import GameCanvas, { GameCanvasHandle } from './components/GameCanvas';
 import HUD from './components/HUD';
 import ConnectionStatus from './components/ConnectionStatus';
@@ -18,6 +29,35 @@ export default function App() {
   const [connected, setConnected] = useState(false);
   const [selectedTower, setSelectedTower] = useState<TowerType>('basic');
   const { toasts, removeToast, showError, showSuccess, showWarning, showInfo } = useToast();
+  const wsRef = useRef<WebSocket | null>(null);
+  const pendingTowersRef = useRef(new Map<string, PendingTower>());
+  const requestCounterRef = useRef(0);
+
+  // Removes an optimistic tower once the server has answered (or timed out)
+  const settlePendingTower = (requestId: string): PendingTower | undefined => {
+    const pending = pendingTowersRef.current.get(requestId);
+    pendingTowersRef.current.delete(requestId);
+    canvasRef.current?.setPendingTowers([...pendingTowersRef.current.values()]);
+    return pending;
+  };
+
+  const towerLabel = (towerType: string) => towerType.charAt(0).toUpperCase() + towerType.slice(1);
+
+  const placementErrorMessage = (code: ErrorCode, fallback: string) => {
+    switch (code) {
+      case 'not_enough_gold':
+        return 'Not enough gold!';
+      case 'invalid_placement':
+      case 'out_of_bounds':
+        return 'Cannot place tower here!';
+      case 'max_towers':
+        return 'Tower limit reached!';
+      case 'game_over':
+        return 'The game is over!';
+      default:
+        return fallback;
+    }
+  };
 
   useEffect(() => {
     let ws: WebSocket | null = null;
@@ -32,6 +72,7 @@ export default function App() {
       }
 
       ws = new WebSocket(WS_URL);
+      wsRef.current = ws;
 
       ws.onopen = () => {
         if (!isComponentMounted) {
@@ -47,16 +88,58 @@ export default function App() {
         if (!isComponentMounted) return;
 
         try {
-          const raw = JSON.parse(event.data);
-          const state: GameState = {
-            ...raw,
-            towers: raw.towers ?? [],
-            enemies: raw.enemies ?? [],
-            projectiles: raw.projectiles ?? [],
-          };
-
-          canvasRef.current?.pushState(state);
-          setHudState(state);
+          const message: ServerMessage = JSON.parse(event.data);
+
+          switch (message.type) {
+            case 'snapshot': {
+              const raw = message.payload as GameState;
+              const state: GameState = {
+                ...raw,
+                towers: raw.towers ?? [],
+                enemies: raw.enemies ?? [],
+                projectiles: raw.projectiles ?? [],
+              };
+
+              canvasRef.current?.pushState(state);
+              setHudState(state);
+              break;
+            }
+            case 'event': {
+              const gameEvent = message.payload as GameEvent;
+              if (gameEvent.type === 'boss_phase') {
+                showWarning(`Boss: ${gameEvent.detail?.replace(/_/g, ' ')}!`);
+              }
+              break;
+            }
+            case 'ack': {
+              const pending = settlePendingTower((message.payload as CommandAck).requestId);
+              if (pending) {
+                showSuccess(`${towerLabel(pending.towerType)} tower placed!`);
+              }
+              break;
+            }
+            case 'nack': {
+              const nack = message.payload as CommandNack;
+              settlePendingTower(nack.requestId);
+              showError(placementErrorMessage(nack.code, nack.message));
+              break;
+            }
+            case 'error': {
+              const serverError = message.payload as ServerError;
+              if (serverError.requestId) {
+                settlePendingTower(serverError.requestId);
+              }
+              showError(serverError.message);
+              break;
+            }
+            case 'ping':
+              // heartbeat: the server measures our latency from the echo
+              ws?.send(JSON.stringify({ type: 'pong', payload: message.payload }));
+              break;
+            default:
+              // chat, deltas, summaries and latencies are not rendered yet
+              break;
+          }
         } catch (error) {
           console.error('Error parsing game state:', error);
           showError('Failed to parse game data');
@@ -105,6 +188,22 @@ export default function App() {
   const handleCanvasClick = async (x: number, y: number) => {
     if (!hudState || hudState.gameOver) return;
 
+    // Over WebSocket the tower is drawn immediately and rolled back if the server rejects it
+    const ws = wsRef.current;
+    if (ws && ws.readyState === WebSocket.OPEN) {
+      const requestId = `place-${++requestCounterRef.current}`;
+      pendingTowersRef.current.set(requestId, { requestId, x, y, towerType: selectedTower });
+      canvasRef.current?.setPendingTowers([...pendingTowersRef.current.values()]);
+      ws.send(JSON.stringify({ type: 'place_tower', requestId, payload: { x, y, towerType: selectedTower } }));
+
+      window.setTimeout(() => {
+        if (settlePendingTower(requestId)) {
+          showWarning('No response from server, tower placement undone');
+        }
+      }, 5000);
+      return;
+    }
+
     try {
       const response = await fetch(`${API_URL}/tower`, {
         method: 'POST',
@@ -113,18 +212,10 @@ export default function App() {
       });
 
       if (!response.ok) {
-        const data = await response.json();
-        const errorMsg = data.error || 'Failed to place tower';
-        
-        if (errorMsg.includes('not enough gold')) {
-          showError('Not enough gold!');
-        } else if (errorMsg.includes('invalid')) {
-          showError('Cannot place tower here!');
-        } else {
-          showError(errorMsg);
-        }
+        const data: ApiError = await response.json();
+        showError(placementErrorMessage(data.code, data.message || 'Failed to place tower'));
       } else {
-        showSuccess(`${selectedTower.charAt(0).toUpperCase() + selectedTower.slice(1)} tower placed!`);
+        showSuccess(`${towerLabel(selectedTower)} tower placed!`);
       }
     } catch (error) {
       console.error('Error placing tower:', error);
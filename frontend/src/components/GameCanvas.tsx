@@ -1,5 +1,5 @@
 import { useEffect, useRef, useImperativeHandle, forwardRef } from 'react';
-import type { GameState } from '../types';
+import type { GameState, PendingTower } from '../types';
 import './GameCanvas.css';
 
 const CANVAS_WIDTH = 800;
@@ -12,6 +12,7 @@ interface GameCanvasProps {
 
 export interface GameCanvasHandle {
   pushState: (state: GameState) => void;
+  setPendingTowers: (towers: PendingTower[]) => void;
 }
 
 type TimedState = { time: number; state: GameState };
@@ -22,6 +23,7 @@ const GameCanvas = forwardRef<GameCanvasHandle, GameCanvasProps>(
     const bufferRef = useRef<TimedState[]>([]);
     const rafIdRef = useRef<number | null>(null);
     const hudStateRef = useRef<GameState | null>(null);
+    const pendingTowersRef = useRef<PendingTower[]>([]);
 
     useImperativeHandle(ref, () => ({
       pushState: (state: GameState) => {
@@ -30,6 +32,9 @@ const GameCanvas = forwardRef<GameCanvasHandle, GameCanvasProps>(
         if (buf.length > 120) buf.shift();
         hudStateRef.current = state;
       },
+      setPendingTowers: (towers: PendingTower[]) => {
+        pendingTowersRef.current = towers;
+      },
     }));
 
     useEffect(() => {
@@ -253,6 +258,31 @@ const GameCanvas = forwardRef<GameCanvasHandle, GameCanvasProps>(
           }
         });
 
+        // Optimistically placed towers awaiting server confirmation
+        pendingTowersRef.current.forEach((tower) => {
+          ctx.globalAlpha = 0.5;
+          ctx.setLineDash([3, 3]);
+          ctx.strokeStyle = '#ffd700';
+          ctx.lineWidth = 2;
+          ctx.strokeRect(tower.x - 12, tower.y - 12, 24, 24);
+          ctx.setLineDash([]);
+          ctx.globalAlpha = 1;
+        });
+
+        // Blueprints queued on the server until the gold covers them
+        (next.state.blueprints ?? []).forEach((bp, i) => {
+          ctx.globalAlpha = 0.35;
+          ctx.setLineDash([2, 4]);
+          ctx.strokeStyle = '#87ceeb';
+          ctx.lineWidth = 2;
+          ctx.strokeRect(bp.position.x - 12, bp.position.y - 12, 24, 24);
+          ctx.setLineDash([]);
+          ctx.fillStyle = '#87ceeb';
+          ctx.font = '10px monospace';
+          ctx.fillText(String(i + 1), bp.position.x - 3, bp.position.y + 4);
+          ctx.globalAlpha = 1;
+        });
+
         ctx.shadowBlur = 0;
         ctx.shadowOffsetY = 0;
 
@@ -439,17 +469,18 @@ const GameCanvas = forwardRef<GameCanvasHandle, GameCanvasProps>(
           ctx.fillStyle = 'rgba(0, 0, 0, 0.9)';
           ctx.fillRect(0, 0, CANVAS_WIDTH, CANVAS_HEIGHT);
 
-          // Retro "GAME OVER" text with pixel font effect
-          ctx.fillStyle = '#ff0000';
+          // Retro "GAME OVER" (or "VICTORY") text with pixel font effect
+          const won = hudStateRef.current.outcome === 'won';
+          const title = won ? 'VICTORY' : 'GAME OVER';
           ctx.font = '48px "Press Start 2P", monospace';
           ctx.textAlign = 'center';
           
           // Text shadow for depth
           ctx.fillStyle = '#000';
-          ctx.fillText('GAME OVER', CANVAS_WIDTH / 2 + 4, CANVAS_HEIGHT / 2 - 16);
+          ctx.fillText(title, CANVAS_WIDTH / 2 + 4, CANVAS_HEIGHT / 2 - 16);
           
-          ctx.fillStyle = '#ff0000';
-          ctx.fillText('GAME OVER', CANVAS_WIDTH / 2, CANVAS_HEIGHT / 2 - 20);
+          ctx.fillStyle = won ? '#00ff66' : '#ff0000';
+          ctx.fillText(title, CANVAS_WIDTH / 2, CANVAS_HEIGHT / 2 - 20);
 
           // Score with retro styling
           ctx.fillStyle = '#000';
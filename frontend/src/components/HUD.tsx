@@ -53,6 +53,20 @@ export default function HUD({ state }: HUDProps) {
           <span className="stat-detail-label">Towers:</span>
           <span className="stat-detail-value">{state.towers.length}</span>
         </div>
+        {state.waveProgress && (
+          <>
+            <div className="stat-detail">
+              <span className="stat-detail-icon">⏱️</span>
+              <span className="stat-detail-label">Next wave:</span>
+              <span className="stat-detail-value">{Math.ceil(state.waveProgress.nextWaveIn)}s</span>
+            </div>
+            <div className="stat-detail">
+              <span className="stat-detail-icon">🚪</span>
+              <span className="stat-detail-label">Incoming:</span>
+              <span className="stat-detail-value">{state.waveProgress.remainingToSpawn}</span>
+            </div>
+          </>
+        )}
       </div>
     </div>
   );
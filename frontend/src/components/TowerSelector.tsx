@@ -1,4 +1,6 @@
-import { TowerType, TowerInfo } from '../types';
+import { useEffect, useState } from 'react';
+import { TowerType, TowerInfo, GameConfig } from '../types';
+import { API_URL } from '../config';
 import './TowerSelector.css';
 
 interface TowerSelectorProps {
@@ -44,7 +46,41 @@ export const TOWER_CONFIGS: Record<TowerType, TowerInfo> = {
   }
 };
 
+// Overlays the server's balance data on the local display data, so cost and stats
+// follow balance changes without a frontend release
+function useTowerConfigs(): Record<TowerType, TowerInfo> {
+  const [configs, setConfigs] = useState(TOWER_CONFIGS);
+
+  useEffect(() => {
+    fetch(`${API_URL}/api/v1/game-config`)
+      .then((res) => (res.ok ? res.json() : Promise.reject(res.status)))
+      .then((cfg: GameConfig) => {
+        const merged = { ...TOWER_CONFIGS };
+        for (const type of Object.keys(merged) as TowerType[]) {
+          const server = cfg.towers[type];
+          if (server) {
+            merged[type] = {
+              ...merged[type],
+              cost: server.cost,
+              damage: server.damage,
+              range: server.range,
+              fireRate: server.fireRate,
+              splashRadius: server.splashRadius,
+            };
+          }
+        }
+        setConfigs(merged);
+      })
+      .catch(() => {
+        // keep the built-in values
+      });
+  }, []);
+
+  return configs;
+}
+
 export default function TowerSelector({ selectedTower, onSelectTower, currentGold }: TowerSelectorProps) {
+  const towerConfigs = useTowerConfigs();
   return (
     <div className="tower-selector">
       <h3 className="tower-selector-title">
@@ -52,8 +88,8 @@ export default function TowerSelector({ selectedTower, onSelectTower, currentGol
         Select Tower
       </h3>
       <div className="tower-grid">
-        {(Object.keys(TOWER_CONFIGS) as TowerType[]).map((type) => {
-          const tower = TOWER_CONFIGS[type];
+        {(Object.keys(towerConfigs) as TowerType[]).map((type) => {
+          const tower = towerConfigs[type];
           const canAfford = currentGold >= tower.cost;
           const isSelected = selectedTower === type;
           
@@ -12,6 +12,28 @@ export interface Tower {
   damage: number;
   fireRate: number;
   splashRadius?: number;
+  ownerId?: string;
+  auraRadius?: number; // support towers only
+  buff?: TowerBuff;
+  stats: TowerStats;
+  ammo?: { rounds: number; capacity: number }; // only with the ammo rule
+}
+
+// What a tower has cost and achieved so far
+export interface TowerStats {
+  value: number; // gold paid
+  shots: number;
+  damage: number; // damage landed, overkill excluded
+  kills: number;
+  goldEarned: number;
+}
+
+// Aura bonuses a tower receives, as fractions of its base stats (0.25 = +25%)
+export interface TowerBuff {
+  damage?: number;
+  fireRate?: number;
+  range?: number;
+  sources: string[];
 }
 
 export interface Enemy {
@@ -22,6 +44,39 @@ export interface Enemy {
   maxHp: number;
   speed: number;
   pathIndex: number;
+  blocked?: boolean; // stopped by a wall
+  shield?: number;
+  abilities?: ('heal' | 'shield' | 'spawn_on_death')[];
+  affixes?: ('regenerating' | 'splash_resistant' | 'phasing')[]; // elite affixes of the endless late game
+  phased?: boolean; // towers can't target it right now
+  velocity?: Position; // units per second, zero while blocked
+  waypoint?: Position; // next turn of the path; stop extrapolating there
+  visibleTo?: string[]; // fog of war: players whose towers or walls see the enemy
+}
+
+export interface Wall {
+  id: string;
+  position: Position;
+  hp: number;
+  maxHp: number;
+  radius: number;
+  ownerId?: string;
+}
+
+// Returned by POST /api/v1/games/:id/towers/:towerId/sell and /sell-all
+export interface Sale {
+  towerIds: string[];
+  refund: number;
+  fullRefund: boolean; // sold between waves with free rebuilds
+}
+
+// A tower queued until the game can afford it, see POST /api/v1/games/:id/blueprints
+export interface Blueprint {
+  id: string;
+  towerType: string;
+  position: Position;
+  ownerId?: string;
+  cost: number;
 }
 
 export interface Projectile {
@@ -32,24 +87,307 @@ export interface Projectile {
   speed: number;
   damage: number;
   splashRadius?: number;
+  behavior?: 'homing' | 'beam' | 'arc' | 'pierce' | 'chain';
+  origin?: Position;
+  impact?: Position;
+  progress?: number;
+  chain?: Position[];
+  velocity?: Position; // units per second, zero for beams and chains
 }
 
 export interface GameState {
   towers: Tower[];
   enemies: Enemy[];
   projectiles: Projectile[];
+  walls?: Wall[];
+  blueprints?: Blueprint[]; // queued towers in build order, drawn as ghosts
   wave: number;
-  gold: number;
+  gold: number; // the shared pool in rooms with wallets
   lives: number;
   score: number;
   gameOver: boolean;
+  rollbacks?: number; // waves retried from a checkpoint
+  outcome?: 'won' | 'lost'; // once gameOver
+  errored?: boolean; // the server stopped this game after repeated crashes
+  refundWindow?: number; // seconds left to sell towers for their full price
   path?: Position[];
   mapWidth?: number;
   mapHeight?: number;
+  version?: number; // increases with every change; pass as ?since= to long-poll /state
+  tick?: number; // simulation tick the snapshot was taken after; never goes back
+  gameTime?: number; // simulated seconds at that tick, for interpolation
+  mutators?: string[]; // custom rules chosen when the room was created
+  tutorial?: TutorialState;
+  code?: string; // room code friends join with via /rooms/:code/join
+  lobby?: LobbyState; // rooms created with "lobby": true
+  fogOfWar?: boolean; // enemies are filtered to what this player sees
+  removed?: Removal[]; // entities removed within game.corpse_grace_ms
+  restricted?: Array<'build' | 'sell' | 'wall' | 'resupply' | 'blueprint' | 'transfer'>; // actions the room's rules forbid right now
+  wallets?: Record<string, number>; // gold of each player by ID, rooms created with "wallets": true
+  processed?: Record<string, number>; // highest command seq processed per client key, see CommandSeq
+  zones?: TerrainZone[];
+  obstacles?: Obstacle[];
+  weather?: { type: 'rain'; remaining: number }; // absent while the skies are clear
+  waveProgress?: WaveProgress;
+}
+
+// An entity that left the world, kept in snapshots briefly for death animations
+export interface Removal {
+  id: string;
+  kind: 'tower' | 'enemy' | 'projectile' | 'wall';
+  reason: 'killed' | 'leaked' | 'expired';
+  position: Position; // where it was last
+  enemyType?: string;
+  tick: number; // tick the entity was removed in
+  gameTime: number;
+}
+
+// Rectangular terrain area; x and y are its top-left corner
+export interface TerrainZone {
+  type: 'mud' | 'high_ground'; // mud slows enemies, high ground extends tower range
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+}
+
+// Rectangular blocking terrain towers can't be built on or shoot through
+export interface Obstacle {
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+}
+
+export interface WaveProgress {
+  nextWaveIn: number; // seconds; the next wave also waits for the current one to finish spawning
+  remainingToSpawn: number;
+  enemiesAlive: number;
+  composition: Record<string, number>; // enemies of the current wave by type
+  modifier?: WaveModifier; // modifier of the current wave
+  next: WavePreview;
+}
+
+// A rule that changes a whole wave, e.g. frenzy; announced one wave ahead
+export interface WaveModifier {
+  id: string;
+  description: string;
+}
+
+// A wave that hasn't started; also served at GET /games/:id/waves/preview
+export interface WavePreview {
+  wave: number;
+  total: number;
+  totalHp: number;
+  boss?: boolean;
+  pattern: string;
+  enemies: { enemyType: string; count: number; hp: number }[];
+  modifier?: WaveModifier; // already applied to counts and HP
+}
+
+// Projected outcome of the next wave, from POST /games/:id/simulate-wave
+export interface WaveSimulation {
+  wave: number;
+  enemies: number;
+  killed: number;
+  leaked: number;
+  leaksByEnemyType: Record<string, number>;
+  livesLost: number;
+  lives: number;
+  holds: boolean; // beaten without losing a life
+  gameOver: boolean;
+  damage: { towerId: string; towerType: string; damage: number; kills: number }[]; // most first
+  damageByTowerType: Record<string, number>;
+  duration: number; // simulated seconds
+  timedOut?: boolean;
+}
+
+// WebSocket envelope wrapping every server message
+export type ServerMessageType =
+  | 'snapshot' | 'delta' | 'event' | 'chat' | 'error' | 'ack' | 'nack' | 'summary' | 'shutdown'
+  | 'ping' | 'pong' | 'latency';
+
+export interface ServerMessage<T = unknown> {
+  type: ServerMessageType;
+  seq: number;
+  gameId: string;
+  payload: T;
+  resync?: boolean; // snapshot after skipped messages; seq jumps past them
+  keyframe?: boolean; // full-detail snapshot sent to every client every WS_KEYFRAME_MS
+}
+
+// Payload of a "ping" server message; echo it back as a "pong" client message
+export interface HeartbeatPing {
+  id: number;
+  serverTime: number; // unix ms
+}
+
+// Payload of a "latency" server message
+export interface RoomLatency {
+  players: { playerId: string; rttMs: number }[];
+}
+
+// Payload of a "subscribe" client message; "lite" snapshots leave out projectiles
+export interface SubscribeRequest {
+  rate?: number; // snapshots per second, 0 = every broadcast
+  detail?: 'full' | 'lite';
+}
+
+export interface GameEvent {
+  type: string;
+  gameId: string;
+  time: string;
+  wave?: number;
+  playerId?: string;
+  enemyType?: string;
+  towerType?: string;
+  damage?: number; // "hit" events
+  detail?: string; // e.g. "crit" or "miss" on "hit" events
+  outcome?: 'won' | 'lost'; // "game_over" events
+  tick?: number; // simulation tick the event happened in
+  gameTime?: number;
+}
+
+// Sent before the server goes down; restored games resume when it is back
+export interface ShutdownNotice {
+  message: string;
+  restored: boolean;
+}
+
+// End-of-game report, pushed as a "summary" message and served at GET /games/:id/summary
+export interface GameSummary {
+  gameId: string;
+  mapId: string;
+  startedAt: string;
+  endedAt: string;
+  reason?: string;
+  outcome?: 'won' | 'lost';
+  seed: number; // create a game with it to replay the run's randomness
+  wavesSurvived: number;
+  score: number;
+  goldEarned: number;
+  goldSpent: number;
+  damageByTowerType: Record<string, number>;
+  killsByEnemyType: Record<string, number>;
+  placements: Placement[];
+}
+
+// Tick range of a finished game's replay, served at GET /replays/:id
+export interface ReplayInfo {
+  gameId: string;
+  mapId: string;
+  mutators?: string[];
+  seed: number;
+  firstTick: number;
+  lastTick: number;
+  keyframes: number;
+}
+
+// Served at GET /replays/:id/frames?from_tick=&to_tick=
+export interface ReplayFrames {
+  gameId: string;
+  fromTick: number;
+  toTick: number;
+  frames: GameState[]; // one per tick, in order
+}
+
+export interface Placement {
+  time: string;
+  wave: number;
+  kind: 'tower' | 'wall';
+  towerType?: string;
+  playerId?: string;
+  x: number;
+  y: number;
+  cost: number;
+}
+
+export interface ChatMessage {
+  from: string;
+  text: string;
+  time: string;
+}
+
+// Stable error codes shared by HTTP error bodies and WebSocket errors and nacks
+// (api.Codes on the server); switch on them instead of parsing messages
+export type ErrorCode =
+  | 'bad_request' | 'unauthorized' | 'forbidden' | 'not_found' | 'conflict' | 'rate_limited'
+  | 'too_fast' | 'invalid_config' | 'internal_error' | 'unavailable' | 'unknown_command'
+  | 'not_enough_gold' | 'invalid_placement' | 'invalid_coordinates' | 'out_of_bounds' | 'unknown_tower_type'
+  | 'game_not_found' | 'tower_locked' | 'not_enough_points' | 'unknown_mutator' | 'tutorial_step'
+  | 'ammo_disabled' | 'ammo_full' | 'corrupt_save' | 'invalid_slot' | 'invalid_tick_rate'
+  | 'no_checkpoint' | 'rollback_limit' | 'game_over' | 'max_towers' | 'room_full' | 'sell_closed'
+  | 'wrong_password' | 'rule_violation';
+
+// Body of every non-2xx HTTP response
+export interface ApiError {
+  code: ErrorCode;
+  message: string;
+  details?: unknown;
+}
+
+// Echoed in the answer to a command sent with a seq, for reconciling predictions
+export interface CommandSeq {
+  seq?: number;
+  client?: string; // key of this client in snapshots' processed
+  tick?: number; // tick after which the command took effect
+}
+
+export interface ServerError extends CommandSeq {
+  code: ErrorCode;
+  message: string;
+  requestId?: string;
+}
+
+export interface CommandAck extends CommandSeq {
+  requestId: string;
+  command: string;
+}
+
+export interface CommandNack extends CommandAck {
+  code: ErrorCode;
+  message: string;
+}
+
+// Tower placed optimistically, shown until the server acks or nacks it
+export interface PendingTower {
+  requestId: string;
+  x: number;
+  y: number;
+  towerType: TowerType;
 }
 
 export type TowerType = 'basic' | 'sniper' | 'splash';
 
+// Rules of the default game, served at GET /api/v1/game-config
+export interface GameConfig {
+  towers: Record<string, {
+    cost: number;
+    range: number;
+    damage: number;
+    fireRate: number;
+    splashRadius?: number;
+    projectile?: 'homing' | 'beam' | 'arc' | 'pierce' | 'chain';
+    aura?: { radius: number; damage?: number; fireRate?: number; range?: number };
+    ammo?: number; // magazine size under the ammo rule
+  }>;
+  enemies: Record<string, { hp: number; speed: number; goldReward: number; scoreReward: number }>;
+  map: {
+    id: string;
+    name: string;
+    width: number;
+    height: number;
+    path: Position[];
+    pathHalfWidth: number;
+    entrances?: Position[][];
+    zones?: TerrainZone[];
+    obstacles?: Obstacle[];
+  };
+  placement: { minDistanceFromPath: number; minTowerSpacing: number; maxTowers: number; gridSize: number };
+  walls: { cost: number; hp: number; maxWalls: number; minSpacing: number };
+  ammo?: { roundCost: number; autoResupply: boolean };
+}
+
 export interface TowerInfo {
   type: TowerType;
   name: string;
@@ -62,3 +400,70 @@ export interface TowerInfo {
   icon: string;
   color: string;
 }
+
+// A player's tech tree, served at GET /api/v1/players/:id/research
+export interface ResearchProgress {
+  playerId: string;
+  points: number;
+  nodes: Array<{
+    id: string;
+    name: string;
+    description: string;
+    cost: number;
+    requires?: string[];
+    unlockTower?: string;
+    damageBonus?: number;
+    unlocked: boolean;
+    available: boolean;
+  }>;
+}
+
+// A saved tower layout, from GET /api/v1/players/:id/templates; dx/dy are grid
+// cells of the game config's placement.gridSize
+export interface BuildTemplate {
+  name: string;
+  towers: Array<{ towerType: string; dx: number; dy: number }>;
+}
+
+// Returned by POST /api/v1/games/:id/apply-template
+export interface ApplyTemplateResult {
+  placed: number;
+  gold: number;
+  placements: Array<{ towerType: string; x: number; y: number; placed: boolean; error?: ApiError }>;
+}
+
+// Lobby of a room, carried by its snapshots; the first wave waits for everyone to be ready
+export interface LobbyState {
+  phase: 'waiting' | 'starting' | 'in_progress';
+  players: string[];
+  ready: string[];
+  startsIn?: number; // seconds until the first wave while starting
+}
+
+// Progress of a tutorial room, carried by its snapshots
+export interface TutorialState {
+  playerId: string;
+  step: 'place_tower' | 'build_wall' | 'survive_wave' | 'done';
+  index: number;
+  total: number;
+  prompt?: string;
+  promptKey?: string;
+  completed: boolean;
+}
+
+// A named save of the default game, listed at GET /api/v1/saves
+export interface SaveSlot {
+  id: string;
+  gameId: string;
+  mapId: string;
+  name: string;
+  metadata: {
+    wave: number;
+    gold: number;
+    lives: number;
+    score: number;
+    game_over: boolean;
+    saved_at: string;
+  };
+  thumbnail?: string;
+}